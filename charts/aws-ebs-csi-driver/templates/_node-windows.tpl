@@ -76,6 +76,9 @@ spec:
             {{- with .Values.node.reservedVolumeAttachments }}
             - --reserved-volume-attachments={{ . }}
             {{- end }}
+            {{- with .Values.node.reservedAttachments }}
+            - --reserved-attachments={{ . }}
+            {{- end }}
             {{- with .Values.node.metadataSources }}
             - --metadata-sources={{ . }}
             {{- end }}
@@ -74,15 +74,31 @@ spec:
             {{- with .Values.node.reservedVolumeAttachments }}
             - --reserved-volume-attachments={{ . }}
             {{- end }}
+            {{- with .Values.node.reservedAttachments }}
+            - --reserved-attachments={{ . }}
+            {{- end }}
             {{- if .Values.node.enableMetrics }}
             - --http-endpoint=0.0.0.0:3302
             {{- end}}
+            {{- if .Values.node.enableNodeAPI }}
+            - --node-api-endpoint=0.0.0.0:3310
+            {{- end}}
             {{- with .Values.node.kubeletPath }}
             - --csi-mount-point-prefix={{ . }}/plugins/kubernetes.io/csi/ebs.csi.aws.com/
             {{- end}}
             {{- with .Values.node.volumeAttachLimit }}
             - --volume-attach-limit={{ . }}
             {{- end }}
+            {{- with .Values.node.allocatableReconcileInterval }}
+            - --allocatable-reconcile-interval={{ . }}
+            - --registration-dir={{ printf "%s/plugins_registry" (trimSuffix "/" $.Values.node.kubeletPath) }}
+            {{- end }}
+            {{- with .Values.node.volumeWarmUpRate }}
+            - --volume-warm-up-rate={{ . }}
+            {{- end }}
+            {{- with .Values.node.maxConcurrentNodeOperations }}
+            - --max-concurrent-node-operations={{ . }}
+            {{- end }}
             {{- with .Values.node.metadataSources }}
             - --metadata-sources={{ . }}
             {{- end }}
@@ -158,6 +174,11 @@ spec:
             - name: healthz
               containerPort: 9808
               protocol: TCP
+            {{- if .Values.node.enableNodeAPI }}
+            - name: node-api
+              containerPort: 3310
+              protocol: TCP
+            {{- end }}
             {{- if .Values.node.enableMetrics }}
             - name: metrics
               containerPort: 3302
@@ -64,6 +64,8 @@ func main() {
 			string(driver.NodeMode):            {},
 			string(driver.AllMode):             {},
 			string(driver.MetadataLabelerMode): {},
+			string(driver.AdoptMode):           {},
+			string(driver.MigrateMode):         {},
 		}
 	)
 
@@ -139,6 +141,7 @@ func main() {
 	if options.HTTPEndpoint != "" {
 		r, registry = metrics.InitializeRecorder(options.DeprecatedMetrics)
 		r.InitializeMetricsHandler(options.HTTPEndpoint, "/metrics", options.MetricsCertFile, options.MetricsKeyFile)
+		driver.RecordBuildInfo(featureGate)
 	}
 
 	var cloud cloudPkg.Cloud
@@ -166,7 +169,7 @@ func main() {
 
 		if region != "" {
 			klog.InfoS("Region provided via AWS_REGION environment variable", "region", region)
-			if options.Mode != driver.ControllerMode {
+			if options.Mode != driver.ControllerMode && options.Mode != driver.AdoptMode && options.Mode != driver.MigrateMode {
 				klog.InfoS("Node service requires metadata even if AWS_REGION provided, initializing metadata")
 				md, metadataErr = metadata.NewMetadataService(cfg, region)
 			}
@@ -177,7 +180,7 @@ func main() {
 
 		if metadataErr != nil {
 			klog.ErrorS(metadataErr, "Failed to initialize metadata when it is required")
-			if options.Mode == driver.ControllerMode {
+			if options.Mode == driver.ControllerMode || options.Mode == driver.AdoptMode || options.Mode == driver.MigrateMode {
 				klog.InfoS("The region can be manually supplied via the AWS_REGION environment variable")
 			}
 			klog.FlushAndExit(klog.ExitFlushTimeout, 1)
@@ -200,7 +203,23 @@ func main() {
 				userAgentExtra = string(driver.MetadataLabelerMode)
 			}
 		}
-		cloud = cloudPkg.NewCloud(region, options.AwsSdkDebugLog, userAgentExtra, options.Batching, options.DeprecatedMetrics)
+		cloud = cloudPkg.NewCloud(region, cloudPkg.CloudOptions{
+			AwsSdkDebugLog:          options.AwsSdkDebugLog,
+			UserAgentExtra:          userAgentExtra,
+			Batching:                options.Batching,
+			BatchMinDelay:           options.BatchMinDelay,
+			BatchMaxDelay:           options.BatchMaxDelay,
+			DeprecatedMetrics:       options.DeprecatedMetrics,
+			CorrelationIDUserAgent:  options.CorrelationIDUserAgent,
+			SubsystemUserAgent:      options.SubsystemUserAgent,
+			SnapshotsPerRegionQuota: options.SnapshotsPerRegionQuota,
+			ClientTokenStrategy:     options.ClientTokenStrategy,
+			APIBudgetRate:           options.APIBudgetRate,
+			APIBudgetWeights:        options.APIBudgetWeights,
+			RetryPolicy:             options.RetryPolicy,
+			AttachmentHistoryLength: options.AttachmentHistoryLength,
+			AttachmentHistoryLog:    options.AttachmentHistoryLog,
+		})
 	}
 
 	k8sClient, err = cfg.K8sAPIClient()
@@ -223,13 +242,32 @@ func main() {
 		klog.FlushAndExit(klog.ExitFlushTimeout, 0)
 	case string(driver.ControllerMode), string(driver.NodeMode), string(driver.AllMode):
 	case string(driver.MetadataLabelerMode):
-		err := metadata.ContinuousUpdateLabelsLeaderElection(k8sClient, cloud, metadata.ControllerMetadataLabelerInterval)
+		err := metadata.ContinuousUpdateLabelsLeaderElection(k8sClient, cloud, metadata.ControllerMetadataLabelerInterval, metadata.NodeAPIOptions{
+			Port:             options.NodeAPIPort,
+			NodePodsSelector: options.NodePluginSelector,
+		})
 		if err != nil {
 			klog.ErrorS(err, "failed to patch volume/ENI count on node labels")
 			klog.FlushAndExit(klog.ExitFlushTimeout, 0)
 		}
+	case string(driver.AdoptMode):
+		if err := driver.AdoptVolumes(context.Background(), cloud, &options, os.Stdout); err != nil {
+			klog.ErrorS(err, "failed to adopt volumes")
+			klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+		}
+		klog.FlushAndExit(klog.ExitFlushTimeout, 0)
+	case string(driver.MigrateMode):
+		if k8sClient == nil {
+			klog.ErrorS(err, "unable to communicate with k8s API")
+			klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+		}
+		if err := driver.MigrateVolume(context.Background(), cloud, k8sClient, &options, os.Stdout); err != nil {
+			klog.ErrorS(err, "failed to migrate volume")
+			klog.FlushAndExit(klog.ExitFlushTimeout, 1)
+		}
+		klog.FlushAndExit(klog.ExitFlushTimeout, 0)
 	default:
-		klog.Errorf("Unknown driver mode %s: Expected %s, %s, %s, %s, or pre-stop-hook", cmd, driver.ControllerMode, driver.NodeMode, driver.AllMode, driver.MetadataLabelerMode)
+		klog.Errorf("Unknown driver mode %s: Expected %s, %s, %s, %s, %s, %s, or pre-stop-hook", cmd, driver.ControllerMode, driver.NodeMode, driver.AllMode, driver.MetadataLabelerMode, driver.AdoptMode, driver.MigrateMode)
 		klog.FlushAndExit(klog.ExitFlushTimeout, 0)
 	}
 
@@ -248,7 +286,16 @@ func main() {
 		}
 	}
 
-	m, err := mounter.NewNodeMounter(options.WindowsHostProcess)
+	m, err := mounter.NewNodeMounter(options.WindowsHostProcess, mounter.NodeMounterOptions{
+		MountNamespace: mounter.MountNamespace(options.MountNamespace),
+		HostRootfsPath: options.HostRootfsPath,
+		KubeletPath:    options.CsiMountPointPath,
+		DeviceResolution: mounter.DeviceResolutionOptions{
+			UdevSettleStrategy: mounter.UdevSettleStrategy(options.UdevSettleStrategy),
+			Timeout:            options.UdevSettleTimeout,
+			PollInterval:       options.UdevPollInterval,
+		},
+	})
 	if err != nil {
 		klog.ErrorS(err, "failed to create node mounter")
 		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
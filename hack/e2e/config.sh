@@ -34,6 +34,8 @@ FIRST_ZONE=$(echo "${ZONES}" | cut -d, -f1)
 NODE_COUNT=${NODE_COUNT:-3}
 INSTANCE_TYPE=${INSTANCE_TYPE:-c5.large}
 WINDOWS=${WINDOWS:-"false"}
+# IPv4 or IPv6 (eksctl clusters only)
+IP_FAMILY=${IP_FAMILY:-"IPv4"}
 AMI_FAMILY=${AMI_FAMILY:-"AmazonLinux2023"}
 WINDOWS_HOSTPROCESS=${WINDOWS_HOSTPROCESS:-"false"}
 OUTPOST_ARN=${OUTPOST_ARN:-}
@@ -73,5 +75,6 @@ COLLECT_METRICS=${COLLECT_METRICS:-"false"}
 
 TEST_PATH=${TEST_PATH:-"./tests/e2e-kubernetes/..."}
 GINKGO_FOCUS=${GINKGO_FOCUS:-"External.Storage"}
-GINKGO_SKIP=${GINKGO_SKIP:-"\[Disruptive\]|\[Serial\]|\[Flaky\]|should provision storage with pvc data source in parallel"}
+GINKGO_SKIP=${GINKGO_SKIP:-"\[Disruptive\]|\[Serial\]|\[Flaky\]|\[long-running\]|should provision storage with pvc data source in parallel"}
 GINKGO_PARALLEL=${GINKGO_PARALLEL:-25}
+GINKGO_TIMEOUT=${GINKGO_TIMEOUT:-"1h"}
@@ -34,6 +34,11 @@ if [[ "${WINDOWS}" == "true" ]] && [[ "${CLUSTER_TYPE}" == "kops" ]]; then
   exit 1
 fi
 
+if [[ "${IP_FAMILY}" == "IPv6" ]] && { [[ "${CLUSTER_TYPE}" != "eksctl" ]] || [[ "${WINDOWS}" == "true" ]]; }; then
+  echo "Error: IPv6 clusters are only supported with CLUSTER_TYPE=eksctl and without Windows nodes" >&2
+  exit 1
+fi
+
 if [[ "${CLUSTER_TYPE}" == "kops" ]]; then
   BUCKET_CHECK=$("${BIN}/aws" s3api head-bucket --region us-east-1 --bucket "${KOPS_BUCKET}" 2>&1 || true)
   if grep -q "Forbidden" <<<"${BUCKET_CHECK}"; then
@@ -79,7 +84,8 @@ elif [[ "${CLUSTER_TYPE}" == "eksctl" ]]; then
     "${OUTPOST_INSTANCE_TYPE}" \
     "${AMI_FAMILY}" \
     "${LINUX_AMI}" \
-    "${WINDOWS_AMI}"
+    "${WINDOWS_AMI}" \
+    "${IP_FAMILY}"
 else
   echo "Cluster type ${CLUSTER_TYPE} is invalid, must be kops or eksctl" >&2
   exit 1
@@ -37,6 +37,7 @@ function eksctl_create_cluster() {
   AMI_FAMILY=${15}
   LINUX_AMI=${16}
   WINDOWS_AMI=${17}
+  IP_FAMILY=${18}
 
   CLUSTER_NAME="${CLUSTER_NAME//./-}"
 
@@ -48,6 +49,7 @@ function eksctl_create_cluster() {
     INSTANCE_TYPE="${INSTANCE_TYPE}" \
     AMI_FAMILY="${AMI_FAMILY}" \
     WINDOWS="${WINDOWS}" \
+    IP_FAMILY="${IP_FAMILY}" \
     OUTPOST_ARN="" \
     ${GOMPLATE_BIN} -f "${TEMPLATE_FILE}" -o "${CLUSTER_FILE}"
 
@@ -76,6 +78,7 @@ function eksctl_create_cluster() {
       ZONES="${ZONES}" \
       INSTANCE_TYPE="${INSTANCE_TYPE}" \
       WINDOWS="${WINDOWS}" \
+      IP_FAMILY="${IP_FAMILY}" \
       OUTPOST_ARN="${OUTPOST_ARN}" \
       OUTPOST_INSTANCE_TYPE="${OUTPOST_INSTANCE_TYPE}" \
       LINUX_AMI="${LINUX_AMI}" \
@@ -83,6 +86,17 @@ function eksctl_create_cluster() {
       ${GOMPLATE_BIN} -f "${TEMPLATE_FILE}" -o "${CLUSTER_FILE}"
     ${EKSCTL_BIN} create nodegroup -f "${CLUSTER_FILE}"
   fi
+
+  if [[ "${IP_FAMILY}" == "IPv6" ]]; then
+    # The driver reaches IMDS at [fd00:ec2::254] on IPv6-only clusters, which is disabled by default
+    loudecho "Enabling the IPv6 IMDS endpoint on nodes"
+    INSTANCE_IDS=$(aws ec2 describe-instances --region "${REGION}" \
+      --filters "Name=tag:eks:cluster-name,Values=${CLUSTER_NAME}" "Name=instance-state-name,Values=pending,running" \
+      --query 'Reservations[].Instances[].InstanceId' --output text)
+    for INSTANCE_ID in ${INSTANCE_IDS}; do
+      aws ec2 modify-instance-metadata-options --region "${REGION}" --instance-id "${INSTANCE_ID}" --http-protocol-ipv6 enabled >/dev/null
+    done
+  fi
 }
 
 function eksctl_cluster_exists() {
@@ -30,13 +30,14 @@
 #   node-component-only - Deploys only node DaemonSet without controller
 #   fips                - Builds FIPS image then validates it is deployed
 #   legacy-compat       - legacyXFS behavior
+#   logging             - sdkDebugLog and loggingFormat, asserted on the logs of the driver
 
 set -euo pipefail
 
 BASE_DIR="$(dirname "$(realpath "${BASH_SOURCE[0]}")")"
 VALUES_DIR="${BASE_DIR}/../../tests/helm-template/testdata"
 
-PARAM_SETS_ALL="standard miscellaneous node-component-only fips legacy-compat"
+PARAM_SETS_ALL="standard miscellaneous node-component-only fips legacy-compat logging"
 
 param_set_standard() {
   GINKGO_FOCUS="\[param:(extraCreateMetadata|k8sTagClusterId|extraVolumeTags|defaultFsType)\]"
@@ -60,6 +61,10 @@ param_set_legacy-compat() {
   HELM_EXTRA_FLAGS="--set=node.legacyXFS=true"
 }
 
+param_set_logging() {
+  GINKGO_FOCUS="\[param:(sdkDebugLog|loggingFormat)\]"
+}
+
 param_set_fips() {
   GINKGO_FOCUS="\[param:fips\]"
   # Single flag; inlined instead of maintaining a one-line values file.
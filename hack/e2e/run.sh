@@ -203,6 +203,7 @@ else
     "${BIN}/ginkgo" -p -nodes="${GINKGO_PARALLEL}" \
       --focus="${GINKGO_FOCUS}" \
       --skip="${GINKGO_SKIP}" \
+      --timeout="${GINKGO_TIMEOUT}" \
       --junit-report="${JUNIT_REPORT:-${REPORT_DIR}/junit.xml}" \
       "${TEST_PATH}" \
       -- \
@@ -32,6 +32,11 @@ test-e2e-multi-az)
 test-e2e-disruptive)
   TEST="disruptive"
   ;;
+test-e2e-ipv6)
+  TEST="ipv6"
+  export CLUSTER_TYPE="eksctl"
+  export IP_FAMILY="IPv6"
+  ;;
 test-e2e-external)
   TEST="external"
   ;;
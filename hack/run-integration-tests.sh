@@ -0,0 +1,52 @@
+#!/bin/bash
+
+# Copyright 2025 The Kubernetes Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Runs the integration suite in tests/integration against a LocalStack-compatible EC2 emulator.
+# Starts LocalStack in a container unless AWS_EC2_ENDPOINT points at a running emulator.
+
+set -euo pipefail
+
+LOCALSTACK_IMAGE=${LOCALSTACK_IMAGE:-localstack/localstack:latest}
+LOCALSTACK_PORT=${LOCALSTACK_PORT:-4566}
+LOCALSTACK_CONTAINER=${LOCALSTACK_CONTAINER:-ebs-csi-localstack}
+AWS_REGION=${AWS_REGION:-us-east-1}
+INTEGRATION_TEST_TIMEOUT=${INTEGRATION_TEST_TIMEOUT:-10m}
+
+cleanup() {
+  if [[ "${STARTED_LOCALSTACK:-false}" == "true" ]]; then
+    echo "Stopping ${LOCALSTACK_CONTAINER}"
+    docker rm -f "${LOCALSTACK_CONTAINER}" >/dev/null
+  fi
+}
+trap cleanup EXIT
+
+if [[ -z "${AWS_EC2_ENDPOINT:-}" ]]; then
+  echo "Starting ${LOCALSTACK_IMAGE} as ${LOCALSTACK_CONTAINER}"
+  docker run -d --rm --name "${LOCALSTACK_CONTAINER}" -p "${LOCALSTACK_PORT}:4566" -e SERVICES=ec2,sts "${LOCALSTACK_IMAGE}" >/dev/null
+  STARTED_LOCALSTACK=true
+  AWS_EC2_ENDPOINT="http://localhost:${LOCALSTACK_PORT}"
+
+  echo "Waiting for ${AWS_EC2_ENDPOINT} to be ready"
+  for _ in $(seq 1 60); do
+    if curl -sf "${AWS_EC2_ENDPOINT}/_localstack/health" | grep -q '"ec2": "\(available\|running\)"'; then
+      break
+    fi
+    sleep 2
+  done
+fi
+
+AWS_EC2_ENDPOINT="${AWS_EC2_ENDPOINT}" AWS_EC2_EMULATOR=true AWS_REGION="${AWS_REGION}" \
+  go test -tags integration -count=1 -timeout "${INTEGRATION_TEST_TIMEOUT}" -v ./tests/integration/...
@@ -24,6 +24,7 @@ BIN="$(dirname "$(realpath "${BASH_SOURCE[0]}")")/../bin"
 "${BIN}/mockgen" -package mounter -destination=./pkg/mounter/mock_mount.go -source pkg/mounter/mount.go &
 "${BIN}/mockgen" -package cloud -destination=./pkg/cloud/mock_ec2.go -source pkg/util/ec2_interface.go EC2API &
 "${BIN}/mockgen" -package cloud -destination=./pkg/cloud/mock_sm.go -source pkg/util/sagemaker_interface.go SageMakerAPI &
+"${BIN}/mockgen" -package cloud -destination=./pkg/cloud/mock_kms.go -source pkg/util/kms_interface.go KMSAPI &
 
 # Wait for all mockgen processes to finish
 wait
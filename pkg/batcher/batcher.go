@@ -24,10 +24,14 @@
 //
 //	`b := batcher.New(10, 5*time.Second, execFunc)`
 //
+// Or let the wait adapt between 100ms when idle and 5 seconds when saturated:
+//
+//	`b := batcher.NewAdaptive(10, 100*time.Millisecond, 5*time.Second, execFunc)`
+//
 // Add a task and receive its result:
 //
 //	resultChan := make(chan batcher.BatchResult)
-//	b.AddTask(myTask, resultChan)
+//	b.AddTask(ctx, myTask, resultChan)
 //	result := <-resultChan
 //
 // Key Components:
@@ -38,11 +42,19 @@
 // Task Duplication:
 // Batcher identifies tasks by content. For multiple identical tasks, each has a unique result channel.
 // This distinction ensures that identical tasks return their results to the appropriate callers.
+//
+// Cancellation:
+// Each task carries the context of its caller. Tasks whose context is done by the time their batch executes
+// are dropped from the batch, and the context passed to execFunc is cancelled once every caller in the batch
+// has gone away, so abandoned requests do not consume batch slots or API quota.
 package batcher
 
 import (
+	"context"
+	"sync/atomic"
 	"time"
 
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
 	"k8s.io/klog/v2"
 )
 
@@ -53,11 +65,12 @@ import (
 type Batcher[InputType comparable, ResultType any] struct {
 	// execFunc is the function responsible for executing a batch of tasks.
 	// It returns a map associating each task with its result.
-	execFunc func(inputs []InputType) (map[InputType]ResultType, error)
+	// The context is cancelled when every caller waiting on the batch has cancelled.
+	execFunc func(ctx context.Context, inputs []InputType) (map[InputType]ResultType, error)
 
 	// pendingTasks holds the tasks that are waiting to be executed in a batch.
-	// Each task is associated with one or more result channels to account for duplicates.
-	pendingTasks map[InputType][]chan BatchResult[ResultType]
+	// Each task is associated with one or more entries to account for duplicates.
+	pendingTasks map[InputType][]taskEntry[InputType, ResultType]
 
 	// taskChan is the channel through which new tasks are added to the Batcher.
 	taskChan chan taskEntry[InputType, ResultType]
@@ -68,8 +81,25 @@ type Batcher[InputType comparable, ResultType any] struct {
 	// maxDelay is the maximum duration the Batcher waits before executing a batch operation,
 	// regardless of how many tasks are in the batch.
 	maxDelay time.Duration
+
+	// minDelay is the duration the Batcher waits when idle. The wait grows towards maxDelay as load increases.
+	minDelay time.Duration
+
+	// load is a moving average of how full recent batches were (0 = single task, 1 = maxEntries).
+	// It is only accessed by taskManager.
+	load float64
+
+	// queued is the number of tasks that have been added but whose batch has not finished executing.
+	queued atomic.Int64
+
+	// observe, if set, is called before each batch executes with the number of distinct tasks in the batch
+	// and the number of callers waiting on them.
+	observe func(batchSize, requests int)
 }
 
+// loadSmoothing is the weight of the most recent batch in the load moving average.
+const loadSmoothing = 0.5
+
 // BatchResult encapsulates the response of a batched task.
 // A task will either have a result or an error, but not both.
 type BatchResult[ResultType any] struct {
@@ -78,8 +108,10 @@ type BatchResult[ResultType any] struct {
 }
 
 // taskEntry represents a single task waiting to be batched and its associated result channel.
-// The result channel is used to communicate the task's result back to the caller.
+// The result channel is used to communicate the task's result back to the caller, as long as
+// the caller's context is not done.
 type taskEntry[InputType comparable, ResultType any] struct {
+	ctx        context.Context
 	task       InputType
 	resultChan chan BatchResult[ResultType]
 }
@@ -87,25 +119,56 @@ type taskEntry[InputType comparable, ResultType any] struct {
 // New creates and returns a Batcher configured with the specified maxEntries and maxDelay parameters.
 // Upon instantiation, it immediately launches the internal task manager as a goroutine to oversee batch operations.
 // The provided execFunc is used to execute batch requests.
-func New[InputType comparable, ResultType any](entries int, delay time.Duration, fn func(inputs []InputType) (map[InputType]ResultType, error)) *Batcher[InputType, ResultType] {
-	klog.V(7).InfoS("New: initializing Batcher", "maxEntries", entries, "maxDelay", delay)
+func New[InputType comparable, ResultType any](entries int, delay time.Duration, fn func(ctx context.Context, inputs []InputType) (map[InputType]ResultType, error)) *Batcher[InputType, ResultType] {
+	return NewAdaptive(entries, delay, delay, fn)
+}
+
+// NewAdaptive creates and returns a Batcher whose wait adapts to load, within the bounds of minDelay and maxDelay.
+// When idle, batches execute after minDelay to keep latency low. As recent batches fill up, the wait grows
+// towards maxDelay so that more tasks can be combined into each batch.
+func NewAdaptive[InputType comparable, ResultType any](entries int, minDelay, maxDelay time.Duration, fn func(ctx context.Context, inputs []InputType) (map[InputType]ResultType, error)) *Batcher[InputType, ResultType] {
+	klog.V(7).InfoS("New: initializing Batcher", "maxEntries", entries, "minDelay", minDelay, "maxDelay", maxDelay)
 
 	b := &Batcher[InputType, ResultType]{
 		execFunc:     fn,
-		pendingTasks: make(map[InputType][]chan BatchResult[ResultType]),
+		pendingTasks: make(map[InputType][]taskEntry[InputType, ResultType]),
 		taskChan:     make(chan taskEntry[InputType, ResultType], entries),
 		maxEntries:   entries,
-		maxDelay:     delay,
+		maxDelay:     maxDelay,
+		minDelay:     min(minDelay, maxDelay),
 	}
 
 	go b.taskManager()
 	return b
 }
 
+// WithObserver registers fn to be called before each batch executes with the number of distinct tasks in the
+// batch and the number of callers waiting on them (which is larger when callers request the same task).
+// It must be called before any task is added.
+func (b *Batcher[InputType, ResultType]) WithObserver(fn func(batchSize, requests int)) *Batcher[InputType, ResultType] {
+	b.observe = fn
+	return b
+}
+
 // AddTask adds a new task to the Batcher's queue.
-func (b *Batcher[InputType, ResultType]) AddTask(t InputType, resultChan chan BatchResult[ResultType]) {
+// If ctx is done before the batch executes, the task is dropped and no result is sent on resultChan.
+func (b *Batcher[InputType, ResultType]) AddTask(ctx context.Context, t InputType, resultChan chan BatchResult[ResultType]) {
 	klog.V(7).InfoS("AddTask: queueing task", "task", t)
-	b.taskChan <- taskEntry[InputType, ResultType]{task: t, resultChan: resultChan}
+	b.queued.Add(1)
+	select {
+	case b.taskChan <- taskEntry[InputType, ResultType]{ctx: ctx, task: t, resultChan: resultChan}:
+	case <-ctx.Done():
+		b.queued.Add(-1)
+		klog.V(7).InfoS("AddTask: context done before task was queued", "task", t)
+	}
+}
+
+// Len returns the number of tasks that have been added to the Batcher and are waiting for their batch to finish.
+func (b *Batcher[InputType, ResultType]) Len() int {
+	if b == nil {
+		return 0
+	}
+	return int(b.queued.Load())
 }
 
 // taskManager runs as a goroutine, continuously managing the Batcher's internal state.
@@ -116,8 +179,9 @@ func (b *Batcher[InputType, ResultType]) taskManager() {
 
 	exec := func() {
 		timerCh = nil
+		b.observeLoad(len(b.pendingTasks))
 		go b.execute(b.pendingTasks)
-		b.pendingTasks = make(map[InputType][]chan BatchResult[ResultType])
+		b.pendingTasks = make(map[InputType][]taskEntry[InputType, ResultType])
 	}
 
 	for {
@@ -129,14 +193,13 @@ func (b *Batcher[InputType, ResultType]) taskManager() {
 		case t := <-b.taskChan:
 			if _, exists := b.pendingTasks[t.task]; exists {
 				klog.InfoS("taskManager: duplicate task detected", "task", t.task)
-			} else {
-				b.pendingTasks[t.task] = make([]chan BatchResult[ResultType], 0)
 			}
-			b.pendingTasks[t.task] = append(b.pendingTasks[t.task], t.resultChan)
+			b.pendingTasks[t.task] = append(b.pendingTasks[t.task], t)
 
 			if len(b.pendingTasks) == 1 {
-				klog.V(7).InfoS("taskManager: starting maxDelay timer")
-				timerCh = time.After(b.maxDelay)
+				delay := b.delay()
+				klog.V(7).InfoS("taskManager: starting delay timer", "delay", delay)
+				timerCh = time.After(delay)
 			}
 
 			if len(b.pendingTasks) == b.maxEntries {
@@ -147,25 +210,96 @@ func (b *Batcher[InputType, ResultType]) taskManager() {
 	}
 }
 
+// delay returns how long to wait for more tasks before executing a batch, based on recent load.
+func (b *Batcher[InputType, ResultType]) delay() time.Duration {
+	return b.minDelay + time.Duration(float64(b.maxDelay-b.minDelay)*b.load)
+}
+
+// observeLoad updates the load moving average with the size of a batch about to be executed.
+func (b *Batcher[InputType, ResultType]) observeLoad(batchSize int) {
+	fill := 0.0
+	if b.maxEntries > 1 {
+		fill = float64(batchSize-1) / float64(b.maxEntries-1)
+	}
+	b.load = loadSmoothing*min(max(fill, 0), 1) + (1-loadSmoothing)*b.load
+}
+
 // execute is called by taskManager to execute a batch of tasks.
-// It calls the Batcher's internal execFunc and then sends the results of each task to its corresponding result channels.
-func (b *Batcher[InputType, ResultType]) execute(pendingTasks map[InputType][]chan BatchResult[ResultType]) {
+// Tasks whose callers have all gone away are dropped before calling the Batcher's internal execFunc,
+// and the results of the remaining tasks are sent to the result channels of callers that are still waiting.
+func (b *Batcher[InputType, ResultType]) execute(pendingTasks map[InputType][]taskEntry[InputType, ResultType]) {
 	batch := make([]InputType, 0, len(pendingTasks))
-	for task := range pendingTasks {
-		batch = append(batch, task)
+	live := make(map[InputType][]taskEntry[InputType, ResultType], len(pendingTasks))
+	var queued int64
+	for _, entries := range pendingTasks {
+		queued += int64(len(entries))
+	}
+	defer b.queued.Add(-queued)
+	for task, entries := range pendingTasks {
+		for _, e := range entries {
+			if e.ctx.Err() == nil {
+				live[task] = append(live[task], e)
+			}
+		}
+		if len(live[task]) > 0 {
+			batch = append(batch, task)
+		}
 	}
 
-	klog.V(7).InfoS("execute: calling execFunc", "batchSize", len(batch))
-	resultsMap, err := b.execFunc(batch)
+	if dropped := len(pendingTasks) - len(batch); dropped > 0 {
+		klog.V(7).InfoS("execute: dropped tasks with cancelled callers", "dropped", dropped)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	// Cancel the batch once the last waiting caller has gone away
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var remaining atomic.Int64
+	callerCtxs := make([]context.Context, 0, len(live))
+	for _, entries := range live {
+		remaining.Add(int64(len(entries)))
+		for _, e := range entries {
+			callerCtxs = append(callerCtxs, e.ctx)
+		}
+	}
+	correlationIDs := util.MergeCorrelationIDs(callerCtxs...)
+	if len(correlationIDs) > 0 {
+		ctx = util.WithCorrelationIDs(ctx, correlationIDs...)
+	}
+	if subsystem := util.MergeSubsystems(callerCtxs...); subsystem != "" {
+		ctx = util.WithSubsystem(ctx, subsystem)
+	}
+	for _, entries := range live {
+		for _, e := range entries {
+			stop := context.AfterFunc(e.ctx, func() {
+				if remaining.Add(-1) == 0 {
+					cancel()
+				}
+			})
+			defer stop()
+		}
+	}
+
+	if b.observe != nil {
+		b.observe(len(batch), int(remaining.Load()))
+	}
+
+	klog.V(7).InfoS("execute: calling execFunc", "batchSize", len(batch), "correlationIDs", correlationIDs)
+	resultsMap, err := b.execFunc(ctx, batch)
 	if err != nil {
-		klog.ErrorS(err, "execute: error executing batch")
+		klog.ErrorS(err, "execute: error executing batch", "correlationIDs", correlationIDs)
 	}
 
 	klog.V(7).InfoS("execute: sending batch results", "batch", batch)
 	for _, task := range batch {
 		r := resultsMap[task]
-		for _, ch := range pendingTasks[task] {
-			ch <- BatchResult[ResultType]{Result: r, Err: err}
+		for _, e := range live[task] {
+			select {
+			case e.resultChan <- BatchResult[ResultType]{Result: r, Err: err}:
+			case <-e.ctx.Done():
+			}
 		}
 	}
 	klog.V(7).InfoS("execute: finished execution", "batchSize", len(batch))
@@ -15,11 +15,15 @@
 package batcher
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
 )
 
 const (
@@ -27,7 +31,7 @@ const (
 	slowMaxDelay    = 5 * defaultMaxDelay
 )
 
-func mockExecution(inputs []string) (map[string]string, error) {
+func mockExecution(_ context.Context, inputs []string) (map[string]string, error) {
 	results := make(map[string]string)
 	for _, input := range inputs {
 		results[input] = input
@@ -35,7 +39,7 @@ func mockExecution(inputs []string) (map[string]string, error) {
 	return results, nil
 }
 
-func mockExecutionWithError(inputs []string) (map[string]string, error) {
+func mockExecutionWithError(_ context.Context, inputs []string) (map[string]string, error) {
 	results := make(map[string]string)
 	for _, input := range inputs {
 		results[input] = input
@@ -47,7 +51,7 @@ func TestBatcher(t *testing.T) {
 	t.Parallel()
 	type testCase struct {
 		name         string
-		mockFunc     func(inputs []string) (map[string]string, error)
+		mockFunc     func(ctx context.Context, inputs []string) (map[string]string, error)
 		maxEntries   int
 		maxDelay     time.Duration
 		tasks        []string
@@ -143,7 +147,7 @@ func TestBatcher(t *testing.T) {
 					defer wg.Done()
 					task := fmt.Sprintf("task%d", taskNum)
 					resultChans[taskNum] = make(chan BatchResult[string], 1)
-					b.AddTask(task, resultChans[taskNum])
+					b.AddTask(t.Context(), task, resultChans[taskNum])
 				}(i)
 			}
 
@@ -182,7 +186,7 @@ func TestBatcherConcurrentTaskAdditions(t *testing.T) {
 			defer wg.Done()
 			task := fmt.Sprintf("task%d", taskNum)
 			resultChans[taskNum] = make(chan BatchResult[string], 1)
-			b.AddTask(task, resultChans[taskNum])
+			b.AddTask(t.Context(), task, resultChans[taskNum])
 		}(i)
 	}
 
@@ -199,3 +203,192 @@ func TestBatcherConcurrentTaskAdditions(t *testing.T) {
 		}
 	}
 }
+
+func TestBatcherCancelledTasks(t *testing.T) {
+	t.Parallel()
+
+	executed := make(chan []string, 1)
+	b := New(10, defaultMaxDelay, func(ctx context.Context, inputs []string) (map[string]string, error) {
+		executed <- inputs
+		return mockExecution(ctx, inputs)
+	})
+
+	cancelledCtx, cancel := context.WithCancel(t.Context())
+	cancelledChan := make(chan BatchResult[string], 1)
+	b.AddTask(cancelledCtx, "cancelled", cancelledChan)
+	cancel()
+
+	liveChan := make(chan BatchResult[string], 1)
+	b.AddTask(t.Context(), "live", liveChan)
+
+	r := <-liveChan
+	if r.Err != nil || r.Result != "live" {
+		t.Errorf("Expected result live for live task, but got %v", r)
+	}
+	if inputs := <-executed; len(inputs) != 1 || inputs[0] != "live" {
+		t.Errorf("Expected only the live task to be executed, but got %v", inputs)
+	}
+	select {
+	case r := <-cancelledChan:
+		t.Errorf("Expected no result for cancelled task, but got %v", r)
+	default:
+	}
+}
+
+func TestBatcherAllTasksCancelled(t *testing.T) {
+	t.Parallel()
+
+	executed := make(chan struct{}, 1)
+	b := New(10, defaultMaxDelay, func(ctx context.Context, inputs []string) (map[string]string, error) {
+		executed <- struct{}{}
+		return mockExecution(ctx, inputs)
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	b.AddTask(ctx, "task1", make(chan BatchResult[string], 1))
+	cancel()
+
+	select {
+	case <-executed:
+		t.Error("Expected batch with only cancelled tasks not to be executed")
+	case <-time.After(slowMaxDelay):
+	}
+}
+
+func TestBatcherExecutionCancelledWithCallers(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	execErr := make(chan error, 1)
+	b := New(10, 0, func(ctx context.Context, inputs []string) (map[string]string, error) {
+		close(started)
+		<-ctx.Done()
+		execErr <- ctx.Err()
+		return nil, ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	b.AddTask(ctx, "task1", make(chan BatchResult[string]))
+	<-started
+	cancel()
+
+	select {
+	case err := <-execErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected execution context to be cancelled, but got %v", err)
+		}
+	case <-time.After(slowMaxDelay):
+		t.Error("Timed out waiting for execution context to be cancelled")
+	}
+}
+
+func TestBatcherAdaptiveDelay(t *testing.T) {
+	t.Parallel()
+
+	b := &Batcher[string, string]{maxEntries: 11, minDelay: 10 * time.Millisecond, maxDelay: 110 * time.Millisecond}
+	if d := b.delay(); d != b.minDelay {
+		t.Fatalf("Expected idle delay %v, but got %v", b.minDelay, d)
+	}
+
+	b.observeLoad(11)
+	if d := b.delay(); d != 60*time.Millisecond {
+		t.Fatalf("Expected delay 60ms after one full batch, but got %v", d)
+	}
+
+	for range 20 {
+		b.observeLoad(11)
+	}
+	if d := b.delay(); d < 109*time.Millisecond || d > b.maxDelay {
+		t.Fatalf("Expected delay close to %v when saturated, but got %v", b.maxDelay, d)
+	}
+
+	for range 20 {
+		b.observeLoad(1)
+	}
+	if d := b.delay(); d > 11*time.Millisecond {
+		t.Fatalf("Expected delay close to %v when idle again, but got %v", b.minDelay, d)
+	}
+}
+
+func TestBatcherFixedDelay(t *testing.T) {
+	t.Parallel()
+
+	b := New(10, defaultMaxDelay, mockExecution)
+	b.observeLoad(10)
+	if d := b.delay(); d != defaultMaxDelay {
+		t.Fatalf("Expected fixed delay %v, but got %v", defaultMaxDelay, d)
+	}
+}
+
+func TestBatcherLen(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	b := New(10, 0, func(ctx context.Context, inputs []string) (map[string]string, error) {
+		<-release
+		return mockExecution(ctx, inputs)
+	})
+
+	resultChans := []chan BatchResult[string]{make(chan BatchResult[string], 1), make(chan BatchResult[string], 1)}
+	b.AddTask(t.Context(), "task1", resultChans[0])
+	b.AddTask(t.Context(), "task2", resultChans[1])
+	if l := b.Len(); l != 2 {
+		t.Fatalf("Expected 2 queued tasks, but got %d", l)
+	}
+
+	close(release)
+	for _, ch := range resultChans {
+		<-ch
+	}
+	deadline := time.Now().Add(slowMaxDelay)
+	for b.Len() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if l := b.Len(); l != 0 {
+		t.Fatalf("Expected no queued tasks after execution, but got %d", l)
+	}
+}
+
+func TestBatcherCorrelationIDs(t *testing.T) {
+	t.Parallel()
+
+	idsChan := make(chan []string, 1)
+	b := New(2, slowMaxDelay, func(ctx context.Context, inputs []string) (map[string]string, error) {
+		idsChan <- util.CorrelationIDs(ctx)
+		return mockExecution(ctx, inputs)
+	})
+
+	resultChans := []chan BatchResult[string]{make(chan BatchResult[string], 1), make(chan BatchResult[string], 1)}
+	b.AddTask(util.WithCorrelationIDs(t.Context(), "id-1"), "task1", resultChans[0])
+	b.AddTask(util.WithCorrelationIDs(t.Context(), "id-2"), "task2", resultChans[1])
+
+	ids := <-idsChan
+	if len(ids) != 2 || !slices.Contains(ids, "id-1") || !slices.Contains(ids, "id-2") {
+		t.Fatalf("Expected batch context to carry both correlation IDs, but got %v", ids)
+	}
+}
+
+func TestBatcherObserver(t *testing.T) {
+	t.Parallel()
+
+	type observation struct{ batchSize, requests int }
+	observed := make(chan observation, 1)
+	b := New(3, slowMaxDelay, mockExecution).WithObserver(func(batchSize, requests int) {
+		observed <- observation{batchSize, requests}
+	})
+
+	// task1 is requested twice, so 3 callers are served by a batch of 2 distinct tasks
+	resultChans := make([]chan BatchResult[string], 0, 3)
+	for _, task := range []string{"task1", "task1", "task2"} {
+		ch := make(chan BatchResult[string], 1)
+		resultChans = append(resultChans, ch)
+		b.AddTask(t.Context(), task, ch)
+	}
+
+	if o := <-observed; o.batchSize != 2 || o.requests != 3 {
+		t.Fatalf("Expected batch size 2 serving 3 requests, but got %+v", o)
+	}
+	for _, ch := range resultChans {
+		<-ch
+	}
+}
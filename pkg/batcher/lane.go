@@ -0,0 +1,43 @@
+// Copyright 2025 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the 'License');
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an 'AS IS' BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batcher
+
+import "context"
+
+// Lane is the priority tier of a batched task. Callers that own several Batchers for the same API
+// can dedicate one to each Lane so that latency-sensitive tasks are not queued behind large bulk batches.
+type Lane string
+
+const (
+	// LaneBulk is the default lane, for background work such as describes and deletes.
+	LaneBulk Lane = "bulk"
+	// LaneInteractive is for latency-sensitive work such as attach and detach.
+	LaneInteractive Lane = "interactive"
+)
+
+type laneKey struct{}
+
+// WithLane returns a copy of ctx that routes batched tasks to the given lane.
+func WithLane(ctx context.Context, lane Lane) context.Context {
+	return context.WithValue(ctx, laneKey{}, lane)
+}
+
+// LaneFromContext returns the lane set by WithLane, or LaneBulk if none was set.
+func LaneFromContext(ctx context.Context) Lane {
+	if lane, ok := ctx.Value(laneKey{}).(Lane); ok {
+		return lane
+	}
+	return LaneBulk
+}
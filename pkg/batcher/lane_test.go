@@ -0,0 +1,30 @@
+// Copyright 2025 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the 'License');
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an 'AS IS' BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batcher
+
+import (
+	"testing"
+)
+
+func TestLaneFromContext(t *testing.T) {
+	t.Parallel()
+
+	if lane := LaneFromContext(t.Context()); lane != LaneBulk {
+		t.Errorf("Expected default lane %v, but got %v", LaneBulk, lane)
+	}
+	if lane := LaneFromContext(WithLane(t.Context(), LaneInteractive)); lane != LaneInteractive {
+		t.Errorf("Expected lane %v, but got %v", LaneInteractive, lane)
+	}
+}
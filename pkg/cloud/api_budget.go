@@ -0,0 +1,95 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"golang.org/x/time/rate"
+)
+
+// DefaultAPIBudgetClass is the EC2 API budget class of the calls made without a class, or with an unknown class.
+// It has a weight of 1 unless --api-budget-weights sets another.
+const DefaultAPIBudgetClass = "default"
+
+// apiBudget splits a rate of mutating EC2 calls between budget classes in proportion to their weights, so that the
+// volumes of a class cannot use up the EC2 request tokens of the others. The share of a class is reserved for it:
+// calls of a class wait for its own share even while the other classes are idle.
+type apiBudget struct {
+	limiters map[string]*rate.Limiter
+}
+
+func newAPIBudget(requestsPerSecond float64, weights map[string]int) *apiBudget {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	total := 0
+	if _, ok := weights[DefaultAPIBudgetClass]; !ok {
+		total = 1
+	}
+	for _, weight := range weights {
+		total += weight
+	}
+
+	b := &apiBudget{limiters: map[string]*rate.Limiter{}}
+	share := func(weight int) *rate.Limiter {
+		r := requestsPerSecond * float64(weight) / float64(total)
+		return rate.NewLimiter(rate.Limit(r), max(1, int(math.Ceil(r))))
+	}
+	b.limiters[DefaultAPIBudgetClass] = share(1)
+	for class, weight := range weights {
+		b.limiters[class] = share(weight)
+	}
+	return b
+}
+
+// limiter returns the limiter of a class, or the one of the default class if the class is unknown.
+func (b *apiBudget) limiter(class string) (string, *rate.Limiter) {
+	if l, ok := b.limiters[class]; ok {
+		return class, l
+	}
+	return DefaultAPIBudgetClass, b.limiters[DefaultAPIBudgetClass]
+}
+
+// APIBudgetMiddleware makes each mutating EC2 call wait for the share of the API budget class carried by its context.
+// Describe calls, which EC2 throttles separately from mutating calls, are not limited.
+func APIBudgetMiddleware(b *apiBudget) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Build.Add(middleware.BuildMiddlewareFunc("APIBudgetMiddleware", func(ctx context.Context, input middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+			if strings.HasPrefix(awsmiddleware.GetOperationName(ctx), "Describe") {
+				return next.HandleBuild(ctx, input)
+			}
+			class, l := b.limiter(util.APIBudgetClassFromContext(ctx))
+			start := time.Now()
+			restoreStep := util.SetOperationStep(ctx, "waiting for API budget class "+class)
+			err := l.Wait(ctx)
+			restoreStep()
+			if err != nil {
+				return middleware.BuildOutput{}, middleware.Metadata{}, err
+			}
+			metrics.Recorder().ObserveHistogram(metrics.APIBudgetWaitDuration, metrics.APIBudgetWaitDurationHelpText, time.Since(start).Seconds(), map[string]string{"class": class}, nil)
+			return next.HandleBuild(ctx, input)
+		}), middleware.After)
+	}
+}
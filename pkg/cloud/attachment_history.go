@@ -0,0 +1,155 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"k8s.io/klog/v2"
+)
+
+// AttachmentHistoryPath is the path of the debug endpoint that serves the attachment history of volumes.
+const AttachmentHistoryPath = "/debug/attachments"
+
+// attachmentHistoryForgetDelay is how long the attachment history of a volume is kept after its last transition.
+const attachmentHistoryForgetDelay = 24 * time.Hour
+
+// States of AttachmentEvent.
+const (
+	AttachmentStateRequested = "requested"
+	AttachmentStateAttached  = "attached"
+	AttachmentStateDetached  = "detached"
+	AttachmentStateFailed    = "failed"
+)
+
+// AttachmentEvent is a transition of the attachment of a volume to a node.
+type AttachmentEvent struct {
+	Time time.Time `json:"time"`
+	// Operation is the EC2 operation that caused the transition, AttachVolume or DetachVolume.
+	Operation string `json:"operation"`
+	NodeID    string `json:"nodeID"`
+	Device    string `json:"device,omitempty"`
+	State     string `json:"state"`
+	// Reason is why the driver started the operation, when not requested by the CO.
+	Reason string `json:"reason,omitempty"`
+	// RequestID is the AWS request ID of the operation, for the events that called EC2.
+	RequestID string `json:"requestID,omitempty"`
+	// CorrelationIDs are the IDs of the CSI requests that caused the operation.
+	CorrelationIDs []string `json:"correlationIDs,omitempty"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// attachmentHistory keeps the last attachment events of each volume, for the volumes attached or detached in the
+// last attachmentHistoryForgetDelay. It serves them as JSON on AttachmentHistoryPath.
+type attachmentHistory struct {
+	length int
+	log    bool
+
+	mu        sync.Mutex
+	volumes   map[string][]AttachmentEvent
+	lastPrune time.Time
+}
+
+// newAttachmentHistory returns an attachment history keeping length events per volume, or nil if length is 0.
+// With log, each event is also logged.
+func newAttachmentHistory(length int, log bool) *attachmentHistory {
+	if length <= 0 {
+		return nil
+	}
+	return &attachmentHistory{
+		length:    length,
+		log:       log,
+		volumes:   map[string][]AttachmentEvent{},
+		lastPrune: time.Now(),
+	}
+}
+
+// record adds an event to the history of the volume. The request ID is read from the output metadata of the
+// operation or from its error.
+func (h *attachmentHistory) record(ctx context.Context, volumeID string, event AttachmentEvent, metadata *middleware.Metadata, err error) {
+	if h == nil {
+		return
+	}
+	event.Time = time.Now()
+	event.CorrelationIDs = util.CorrelationIDs(ctx)
+	if metadata != nil {
+		event.RequestID, _ = awsmiddleware.GetRequestIDMetadata(*metadata)
+	}
+	if err != nil {
+		event.Error = err.Error()
+		var respErr *awshttp.ResponseError
+		if errors.As(err, &respErr) && respErr.ServiceRequestID() != "" {
+			event.RequestID = respErr.ServiceRequestID()
+		}
+	}
+	if h.log {
+		klog.InfoS("Attachment transition", "volumeID", volumeID, "operation", event.Operation, "nodeID", event.NodeID, "device", event.Device, "state", event.State, "reason", event.Reason, "requestID", event.RequestID, "correlationIDs", event.CorrelationIDs, "err", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	events := append(h.volumes[volumeID], event)
+	if len(events) > h.length {
+		events = slices.Clone(events[len(events)-h.length:])
+	}
+	h.volumes[volumeID] = events
+	h.prune(event.Time)
+}
+
+// prune forgets the volumes without events in the last attachmentHistoryForgetDelay, at most once per hour.
+func (h *attachmentHistory) prune(now time.Time) {
+	if now.Sub(h.lastPrune) < time.Hour {
+		return
+	}
+	h.lastPrune = now
+	for volumeID, events := range h.volumes {
+		if now.Sub(events[len(events)-1].Time) > attachmentHistoryForgetDelay {
+			delete(h.volumes, volumeID)
+		}
+	}
+}
+
+// events returns the history of the volume, or of all volumes if volumeID is empty.
+func (h *attachmentHistory) events(volumeID string) map[string][]AttachmentEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	result := map[string][]AttachmentEvent{}
+	for id, events := range h.volumes {
+		if volumeID == "" || id == volumeID {
+			result[id] = slices.Clone(events)
+		}
+	}
+	return result
+}
+
+// ServeHTTP serves the history of the volume passed in the volumeID query parameter, or of all volumes.
+func (h *attachmentHistory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.events(r.URL.Query().Get("volumeID"))); err != nil {
+		klog.ErrorS(err, "Could not write attachment history")
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// capacityExhaustionTTL is how long an availability zone is considered out of capacity for a volume type after EC2
+// returned InsufficientVolumeCapacity for it.
+const capacityExhaustionTTL = 10 * time.Minute
+
+// ZoneCapacity is the EBS capacity left for a volume type in an availability zone, as tracked by the controller.
+type ZoneCapacity struct {
+	// UsedGiB is the total size of the volumes of the type in the zone.
+	UsedGiB int64
+	// Exhausted is whether EC2 recently returned InsufficientVolumeCapacity for the type in the zone.
+	Exhausted bool
+}
+
+type capacityKey struct {
+	zone       string
+	volumeType string
+}
+
+// capacityTracker tracks the EBS capacity used in each availability zone: the usage listed by GetStorageUsage, plus
+// the volumes created since, so that the volumes created until the next listing do not all go to the same zone.
+type capacityTracker struct {
+	mu        sync.Mutex
+	listed    map[capacityKey]int64
+	created   map[capacityKey]int64
+	exhausted map[capacityKey]time.Time
+	now       func() time.Time
+}
+
+func (t *capacityTracker) time() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+// setListed replaces the usage of every zone with the usage of a listing of the volumes of the account.
+func (t *capacityTracker) setListed(listed map[capacityKey]int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.listed = listed
+	t.created = nil
+}
+
+// recordCreated adds a volume created in a zone to the usage of the zone until the next listing.
+func (t *capacityTracker) recordCreated(zone, volumeType string, sizeGiB int32) {
+	if zone == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.created == nil {
+		t.created = map[capacityKey]int64{}
+	}
+	t.created[capacityKey{zone, volumeType}] += int64(sizeGiB)
+}
+
+// recordExhausted marks a zone as out of capacity for a volume type for capacityExhaustionTTL.
+func (t *capacityTracker) recordExhausted(zone, volumeType string) {
+	if zone == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.exhausted == nil {
+		t.exhausted = map[capacityKey]time.Time{}
+	}
+	t.exhausted[capacityKey{zone, volumeType}] = t.time()
+}
+
+// zones returns the capacity of a volume type in every zone the tracker knows of.
+func (t *capacityTracker) zones(volumeType string) map[string]ZoneCapacity {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	capacities := map[string]ZoneCapacity{}
+	for _, used := range []map[capacityKey]int64{t.listed, t.created} {
+		for key, sizeGiB := range used {
+			if key.volumeType == volumeType {
+				capacity := capacities[key.zone]
+				capacity.UsedGiB += sizeGiB
+				capacities[key.zone] = capacity
+			}
+		}
+	}
+	for key, at := range t.exhausted {
+		switch {
+		case t.time().Sub(at) >= capacityExhaustionTTL:
+			delete(t.exhausted, key)
+		case key.volumeType == volumeType:
+			capacity := capacities[key.zone]
+			capacity.Exhausted = true
+			capacities[key.zone] = capacity
+		}
+	}
+	return capacities
+}
+
+// GetZoneCapacity returns the EBS capacity used by a volume type in each availability zone the account has volumes
+// in, refreshed along with the storage usage of GetStorageUsage, and whether EC2 recently ran out of capacity for the
+// type in the zone. Zones without volumes of the type and without recent capacity failures are not returned.
+func (c *cloud) GetZoneCapacity(ctx context.Context, volumeType string) (map[string]ZoneCapacity, error) {
+	if _, err := c.GetStorageUsage(ctx); err != nil {
+		return nil, err
+	}
+	return c.capacity.zones(volumeType), nil
+}
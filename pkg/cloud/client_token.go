@@ -0,0 +1,223 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ClientTokenStrategyVolumeName derives the client token of CreateVolume from the name of the volume only, so
+	// that a retry with different parameters fails with IdempotentParameterMismatch instead of creating a new volume.
+	ClientTokenStrategyVolumeName = "volume-name"
+	// ClientTokenStrategyRequestHash derives the client token of CreateVolume from the name of the volume and the
+	// parameters of the request, so that a retry with different parameters does not collide with the previous token.
+	// CreateDisk returns the volume created by a previous attempt instead of creating a second one.
+	ClientTokenStrategyRequestHash = "request-hash"
+)
+
+// ClientTokenStrategies are the valid values of --client-token-strategy.
+var ClientTokenStrategies = []string{ClientTokenStrategyVolumeName, ClientTokenStrategyRequestHash}
+
+// ClientTokenStore persists the numbers appended to client token bases after IdempotentParameterMismatch errors, so
+// that a restarted controller keeps creating a volume with its latest client token instead of going back to a token
+// that EC2 already used for a volume that failed to create.
+type ClientTokenStore interface {
+	// Load returns the persisted token numbers, by token base.
+	Load(ctx context.Context) (map[string]int, error)
+	// Save persists the latest token number of a token base.
+	Save(ctx context.Context, tokenBase string, number int) error
+}
+
+// SetClientTokenStore loads the token numbers persisted in store, and persists the next ones in it. It must be called
+// before the first CreateDisk.
+func (c *cloud) SetClientTokenStore(ctx context.Context, store ClientTokenStore) error {
+	numbers, err := store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("could not load client tokens: %w", err)
+	}
+	for tokenBase, number := range numbers {
+		c.latestClientTokens.Set(tokenBase, &number)
+	}
+	klog.V(4).InfoS("Loaded client tokens", "count", len(numbers))
+	c.clientTokenStore = store
+	return nil
+}
+
+// clientTokenBase returns the string hashed into the client token of the CreateVolume or CopyVolumes call of a volume,
+// before any suffix appended after an IdempotentParameterMismatch.
+func (c *cloud) clientTokenBase(volumeName, volumeType string, capacityGiB int32, zone, zoneID string, diskOptions *DiskOptions) string {
+	if c.clientTokenStrategy != ClientTokenStrategyRequestHash {
+		return volumeName
+	}
+	request := fmt.Sprintf("%s/%d/%d/%d/%d/%t/%t/%s/%s/%s/%s/%s/%s/%d", volumeType, capacityGiB,
+		diskOptions.IOPS, diskOptions.IOPSPerGB, diskOptions.Throughput,
+		diskOptions.Encrypted, diskOptions.MultiAttachEnabled, diskOptions.KmsKeyID,
+		diskOptions.SnapshotID, diskOptions.SourceVolumeID, zone, zoneID, diskOptions.OutpostArn,
+		diskOptions.VolumeInitializationRate)
+	requestHash := sha256.Sum256([]byte(request))
+	return volumeName + "-" + hex.EncodeToString(requestHash[:8])
+}
+
+// clientToken returns the client token of the CreateVolume or CopyVolumes call of a volume.
+//
+// The first client token used for a given token base is its hash. However, if a volume fails to create
+// asyncronously (that is, the CreateVolume call succeeds but the volume ultimately fails to create), the client
+// token is burned until EC2 forgets about its use (measured as 12 hours under normal conditions)
+//
+// To prevent becoming stuck for 12 hours when this occurs, we sequentially append "-2", "-3", "-4", etc to the
+// token base before hashing on the subsequent attempt after a volume fails to create because of an
+// IdempotentParameterMismatch AWS error. The most recent appended value is stored in an expiring cache to prevent
+// memory leaks.
+func (c *cloud) clientToken(tokenBase string) string {
+	if tokenNumber, ok := c.latestClientTokens.Get(tokenBase); ok {
+		tokenBase += "-" + strconv.Itoa(*tokenNumber)
+	}
+	// We use a sha256 hash to guarantee the token that is less than or equal to 64 characters
+	clientToken := sha256.Sum256([]byte(tokenBase))
+	return hex.EncodeToString(clientToken[:])
+}
+
+// handleClientTokenConflict is called when CreateVolume or CopyVolumes fails with IdempotentParameterMismatch. It
+// returns the volume with the name that matches the request, created by a previous request with the same client
+// token, and keeps the token. Otherwise the client token is retired, so that the next attempt creates the volume
+// with a new one once no other volume with the name exists.
+func (c *cloud) handleClientTokenConflict(ctx context.Context, volumeName, tokenBase string, request *diskRequest) *types.Volume {
+	outcome := "new_token"
+	defer func() {
+		labels := map[string]string{"strategy": c.clientTokenStrategyOrDefault(), "outcome": outcome}
+		metrics.Recorder().IncreaseCount(metrics.ClientTokenConflicts, metrics.ClientTokenConflictsHelpText, labels)
+	}()
+
+	// Call DescribeVolumes directly as there is a high chance no volume exists and would poison a batch call
+	volumes, err := describeVolumes(ctx, c.ec2, &ec2.DescribeVolumesInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("tag:" + VolumeNameTagKey),
+				Values: []string{volumeName},
+			},
+		},
+	})
+	if err != nil && !isAWSErrorVolumeNotFound(err) {
+		klog.ErrorS(err, "Could not look up volume after client token conflict, keeping client token", "volumeName", volumeName)
+		outcome = "unknown"
+		return nil
+	}
+	for i := range volumes {
+		v := &volumes[i]
+		if v.State == types.VolumeStateError {
+			continue
+		}
+		if mismatch := request.mismatch(v); mismatch != "" {
+			klog.InfoS("Volume already exists with different parameters", "volumeName", volumeName, "volumeID", aws.ToString(v.VolumeId), "mismatch", mismatch)
+			continue
+		}
+		klog.InfoS("Volume already exists with the parameters of the request, keeping client token", "volumeName", volumeName, "volumeID", aws.ToString(v.VolumeId))
+		outcome = "existing_volume"
+		return v
+	}
+
+	nextTokenNumber := 2
+	if tokenNumber, ok := c.latestClientTokens.Get(tokenBase); ok {
+		nextTokenNumber = *tokenNumber + 1
+	}
+	c.latestClientTokens.Set(tokenBase, &nextTokenNumber)
+	if c.clientTokenStore != nil {
+		if err := c.clientTokenStore.Save(ctx, tokenBase, nextTokenNumber); err != nil {
+			klog.ErrorS(err, "Could not persist client token, it will be lost if the controller restarts", "volumeName", volumeName)
+		}
+	}
+	return nil
+}
+
+// clientTokenRetired returns whether the first client token of tokenBase was retired after a conflict, after which
+// CreateDisk looks up the volume by name before creating it.
+func (c *cloud) clientTokenRetired(tokenBase string) bool {
+	_, ok := c.latestClientTokens.Get(tokenBase)
+	return ok
+}
+
+// diskRequest holds the parameters of a CreateDisk call that a volume created by a previous call with the same name
+// must have to be returned in its place.
+type diskRequest struct {
+	volumeType     string
+	capacityGiB    int32
+	iops           int32
+	throughput     int32
+	encrypted      bool
+	kmsKeyID       string
+	multiAttach    bool
+	snapshotID     string
+	sourceVolumeID string
+}
+
+// mismatch describes the first parameter of the request that volume does not have, or returns an empty string if
+// volume matches the request. IOPS, throughput and the KMS key are only compared when the request sets them, as EC2
+// picks defaults for them otherwise.
+func (r *diskRequest) mismatch(v *types.Volume) string {
+	switch {
+	case string(v.VolumeType) != r.volumeType:
+		return fmt.Sprintf("volume type %s instead of %s", v.VolumeType, r.volumeType)
+	case aws.ToInt32(v.Size) != r.capacityGiB:
+		return fmt.Sprintf("size %d GiB instead of %d GiB", aws.ToInt32(v.Size), r.capacityGiB)
+	case r.iops > 0 && aws.ToInt32(v.Iops) != r.iops:
+		return fmt.Sprintf("%d IOPS instead of %d", aws.ToInt32(v.Iops), r.iops)
+	case r.throughput > 0 && aws.ToInt32(v.Throughput) != r.throughput:
+		return fmt.Sprintf("throughput %d MiB/s instead of %d MiB/s", aws.ToInt32(v.Throughput), r.throughput)
+	case r.encrypted && !aws.ToBool(v.Encrypted):
+		return "no encryption"
+	case r.kmsKeyID != "" && !kmsKeyMatches(aws.ToString(v.KmsKeyId), r.kmsKeyID):
+		return fmt.Sprintf("KMS key %s instead of %s", aws.ToString(v.KmsKeyId), r.kmsKeyID)
+	case aws.ToBool(v.MultiAttachEnabled) != r.multiAttach:
+		return fmt.Sprintf("multi-attach %t instead of %t", aws.ToBool(v.MultiAttachEnabled), r.multiAttach)
+	case r.snapshotID != "" && aws.ToString(v.SnapshotId) != r.snapshotID:
+		return fmt.Sprintf("snapshot %s instead of %s", aws.ToString(v.SnapshotId), r.snapshotID)
+	case r.sourceVolumeID != "" && aws.ToString(v.SourceVolumeId) != r.sourceVolumeID:
+		return fmt.Sprintf("source volume %s instead of %s", aws.ToString(v.SourceVolumeId), r.sourceVolumeID)
+	}
+	return ""
+}
+
+// kmsKeyMatches returns whether the key ARN of a volume is the key requested, which is an ARN when it could be
+// resolved and can otherwise be a key ID. Aliases that could not be resolved are assumed to match.
+func kmsKeyMatches(volumeKeyARN, keyID string) bool {
+	switch {
+	case volumeKeyARN == keyID, strings.HasSuffix(volumeKeyARN, ":key/"+keyID):
+		return true
+	case strings.HasPrefix(keyID, "alias/"), strings.Contains(keyID, ":alias/"):
+		return true
+	}
+	return false
+}
+
+func (c *cloud) clientTokenStrategyOrDefault() string {
+	if c.clientTokenStrategy == "" {
+		return ClientTokenStrategyVolumeName
+	}
+	return c.clientTokenStrategy
+}
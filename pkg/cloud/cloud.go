@@ -18,8 +18,6 @@ package cloud
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"math"
@@ -36,9 +34,11 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/batcher"
 	dm "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud/devicemanager"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud/limits"
@@ -77,11 +77,31 @@ const (
 	io2MinTotalIOPS    = 100
 	io2FallbackMaxIOPS = 256000
 	io2MaxIOPSPerGB    = 1000
+	io2FallbackMaxGiB  = 65536
 	gp3FallbackMaxIOPS = 16000
 	gp3MinTotalIOPS    = 3000
 	gp3MaxIOPSPerGB    = 500
 )
 
+// minVolumeSizesGiB are the minimum sizes of the HDD volume types, which are well above the 1 GiB granularity
+// of requests. Smaller requests fail before calling EC2 with an error explaining the minimum.
+var minVolumeSizesGiB = map[string]int32{
+	VolumeTypeSC1: 125,
+	VolumeTypeST1: 125,
+}
+
+// MinVolumeSizeGiB returns the minimum size of volumeType, or 0 when any size can be requested.
+func MinVolumeSizeGiB(volumeType string) int32 {
+	return minVolumeSizesGiB[volumeType]
+}
+
+// io2 volumes larger than io2BlockExpressMinGiB or with more than io2BlockExpressMinIOPS need Block Express, which is
+// only supported by instances built on the Nitro System.
+const (
+	io2BlockExpressMinGiB  = 16384
+	io2BlockExpressMinIOPS = 64000
+)
+
 var (
 	ValidVolumeTypes = []string{
 		VolumeTypeIO1,
@@ -92,6 +112,12 @@ var (
 		VolumeTypeST1,
 		VolumeTypeStandard,
 	}
+
+	// OutpostVolumeTypes are the volume types that can be created on Outposts racks. Requests for other types on an
+	// Outpost fail before calling EC2.
+	OutpostVolumeTypes = []string{
+		VolumeTypeGP2,
+	}
 )
 
 const (
@@ -138,6 +164,11 @@ var (
 	AllowAutoIOPSIncreaseOnModifyKey string
 	// IOPSPerGBKey represents the tag key for IOPS per GB.
 	IOPSPerGBKey string
+	// PendingDeletionTagKey is the tag key holding the time (RFC 3339) after which a soft-deleted volume is deleted.
+	PendingDeletionTagKey string
+	// VolumeInitializationRateTagKey is the tag key holding the initialization rate, in MiB/s, of the volumes restored
+	// from a snapshot whose StorageClass does not set one.
+	VolumeInitializationRateTagKey string
 )
 
 // Batcher.
@@ -149,11 +180,15 @@ const (
 	snapshotTagBatcher
 )
 
+// DefaultBatchDelay is the default delay of the describe batchers. Minimizes RPC latency and EC2 API calls. Tuned via
+// scalability tests.
+const DefaultBatchDelay = 500 * time.Millisecond
+
 const (
 	batchDescribeTimeout = 30 * time.Second
 
-	// Minimizes RPC latency and EC2 API calls. Tuned via scalability tests.
-	batchMaxDelay = 500 * time.Millisecond
+	// Used by the interactive lane (attach/detach), which favors latency over batch size.
+	interactiveBatchMaxDelay = 50 * time.Millisecond
 
 	// Tuned for EC2 DescribeVolumeStatus -- as of July 2025 it takes up to 5 min for initialization info to be updated.
 	slowVolumeStatusBatchMaxDelay = 2 * time.Minute
@@ -193,6 +228,9 @@ var (
 	// ErrInvalidMaxResults is returned when a MaxResults pagination parameter is between 1 and 4.
 	ErrInvalidMaxResults = errors.New("maxResults parameter must be 0 or greater than or equal to 5")
 
+	// ErrInvalidNextToken is returned when EC2 rejects a nextToken pagination parameter.
+	ErrInvalidNextToken = errors.New("nextToken parameter is not valid")
+
 	// ErrVolumeNotBeingModified is returned if volume being described is not being modified.
 	ErrVolumeNotBeingModified = errors.New("volume is not being modified")
 
@@ -204,6 +242,33 @@ var (
 
 	// ErrLimitExceeded is returned if a user exceeds a quota.
 	ErrLimitExceeded = errors.New("limit exceeded")
+
+	// ErrMultiAttachNotSupported is returned if a multi-attach volume cannot be attached to an instance.
+	ErrMultiAttachNotSupported = errors.New("multi-attach is not supported by instance")
+
+	// ErrBlockExpressNotSupported is returned if an io2 volume that needs Block Express cannot be attached to an instance.
+	ErrBlockExpressNotSupported = errors.New("io2 Block Express is not supported by instance")
+
+	// ErrZoneMismatch is returned if a volume cannot be attached to an instance in another Availability Zone.
+	ErrZoneMismatch = errors.New("volume and instance are in different Availability Zones")
+
+	// ErrThrottled is returned if EC2 throttled a request.
+	ErrThrottled = errors.New("request was throttled")
+
+	// ErrServiceUnavailable is returned if EC2 failed to handle a request because of a transient error.
+	ErrServiceUnavailable = errors.New("service unavailable")
+
+	// ErrInsufficientCapacity is returned if EC2 is out of capacity for a volume in an availability zone.
+	ErrInsufficientCapacity = errors.New("insufficient capacity")
+
+	// ErrUnauthorized is returned if the credentials of the driver are not allowed to perform an operation.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrIncorrectState is returned if a resource is in the wrong state for a request.
+	ErrIncorrectState = errors.New("resource is in an incorrect state")
+
+	// ErrUnsupported is returned if a request is not supported, for example by its availability zone.
+	ErrUnsupported = errors.New("unsupported")
 )
 
 // Set during build time via -ldflags.
@@ -224,6 +289,9 @@ var (
 	// Error example it is used for: "An error occurred (InvalidParameterCombination) when calling the CreateVolume operation: io2 volumes configured with greater than 64 TiB or 256K IOPS or 1000:1 IOPS:GB ratio are not supported".
 	io2ErrRegex = regexp.MustCompile(`(?i)(\d+)K IOPS`)
 
+	// For getting the size limit from the same io2 error.
+	io2SizeErrRegex = regexp.MustCompile(`(?i)(\d+) TiB`)
+
 	volumeIDRegex   = regexp.MustCompile(util.VolumeIDRegex)
 	snapshotIDRegex = regexp.MustCompile(util.SnapshotIDRegex)
 	instanceIDRegex = regexp.MustCompile(util.InstanceIDRegex)
@@ -249,8 +317,23 @@ type Disk struct {
 	SourceVolumeID     string
 	SnapshotID         string
 	OutpostArn         string
+	Encrypted          bool
 	KmsKeyID           string
 	Attachments        []string
+	// VolumeType, IOPS and Throughput are only populated by CreateDisk, ListDisks and ListDisksPage. CreateDisk sets
+	// the IOPS and throughput the volume was requested with, 0 for the default of its type.
+	VolumeType string
+	IOPS       int32
+	Throughput int32
+	// RequiresBlockExpress is set by CreateDisk for io2 volumes whose size or IOPS need an instance that supports
+	// Block Express.
+	RequiresBlockExpress bool
+	// FastRestored is set by CreateDisk for volumes restored from a snapshot with fast snapshot restore, which are
+	// initialized at creation.
+	FastRestored bool
+	// PendingDeletionAt is set by GetDiskByID, ListDisks and ListDisksPage for soft-deleted volumes to the time after which they may be deleted.
+	// It is zero for volumes without a valid PendingDeletionTagKey tag.
+	PendingDeletionAt time.Time
 }
 
 // DiskOptions represents parameters to create an EBS volume.
@@ -284,11 +367,13 @@ type ModifyDiskOptions struct {
 	AllowIopsIncreaseOnResize bool
 }
 
-// iopsLimits represents the IOPS limits set by EBS of a volume dependent on the volume type.
+// iopsLimits represents the IOPS limits set by EBS of a volume dependent on the volume type. maxSizeGiB is only
+// known for io2 volumes, whose limits depend on the availability zone.
 type iopsLimits struct {
 	maxIops      int32
 	minIops      int32
 	maxIopsPerGb int32
+	maxSizeGiB   int32
 }
 
 // getVolumeLimitsParams represents the AZ parameters that getVolumeLimits will use to make the DryRun CreateVolume call.
@@ -311,6 +396,15 @@ type Snapshot struct {
 	Size           int32
 	CreationTime   time.Time
 	ReadyToUse     bool
+	// Failed is set for snapshots in the error state, which never become ready to use.
+	Failed bool
+	// Name is the name of the snapshot, read from SnapshotNameTagKey. It is only populated by ListSnapshotsByTags.
+	Name string
+	// OutpostArn is set for snapshots stored on an Outpost, which can only be restored to that Outpost.
+	OutpostArn string
+	// VolumeInitializationRate is the initialization rate, in MiB/s, of the volumes restored from the snapshot whose
+	// StorageClass does not set one, read from VolumeInitializationRateTagKey.
+	VolumeInitializationRate int32
 }
 
 // ListSnapshotsResponse is the container for our snapshots along with a pagination token to pass back to the caller.
@@ -319,6 +413,12 @@ type ListSnapshotsResponse struct {
 	NextToken string
 }
 
+// ListDisksResponse is a page of volumes returned by ListDisksPage.
+type ListDisksResponse struct {
+	Disks     []*Disk
+	NextToken string
+}
+
 // SnapshotOptions represents parameters to create an EBS snapshot.
 type SnapshotOptions struct {
 	Tags       map[string]string
@@ -347,6 +447,8 @@ type volumeWaitParameters struct {
 	creationBackoff      wait.Backoff
 	modificationBackoff  wait.Backoff
 	attachmentBackoff    wait.Backoff
+	// dependencyViolationBackoff is how deletions of volumes and snapshots that are in use are retried.
+	dependencyViolationBackoff wait.Backoff
 }
 
 var (
@@ -377,6 +479,13 @@ var (
 			Factor:   1.7,
 			Steps:    10,
 		},
+
+		// Deleting a volume or snapshot that is in use is not retried unless a retry policy sets it.
+		dependencyViolationBackoff: wait.Backoff{
+			Duration: 5 * time.Second,
+			Factor:   2,
+			Steps:    1,
+		},
 	}
 )
 
@@ -388,14 +497,20 @@ type snapshotBatcherType int
 
 // batcherManager maintains a collection of batchers for different types of tasks.
 type batcherManager struct {
-	volumeIDBatcher             *batcher.Batcher[string, *types.Volume]
-	volumeTagBatcher            *batcher.Batcher[string, *types.Volume]
-	instanceIDBatcher           *batcher.Batcher[string, *types.Instance]
-	snapshotIDBatcher           *batcher.Batcher[string, *types.Snapshot]
-	snapshotTagBatcher          *batcher.Batcher[string, *types.Snapshot]
-	volumeModificationIDBatcher *batcher.Batcher[string, *types.VolumeModification]
-	volumeStatusIDBatcherSlow   *batcher.Batcher[string, *types.VolumeStatusItem]
-	volumeStatusIDBatcherFast   *batcher.Batcher[string, *types.VolumeStatusItem]
+	volumeIDBatcher              *batcher.Batcher[string, *types.Volume]
+	volumeIDBatcherInteractive   *batcher.Batcher[string, *types.Volume]
+	volumeTagBatcher             *batcher.Batcher[string, *types.Volume]
+	instanceIDBatcher            *batcher.Batcher[string, *types.Instance]
+	instanceIDBatcherInteractive *batcher.Batcher[string, *types.Instance]
+	snapshotIDBatcher            *batcher.Batcher[string, *types.Snapshot]
+	snapshotTagBatcher           *batcher.Batcher[string, *types.Snapshot]
+	volumeModificationIDBatcher  *batcher.Batcher[string, *types.VolumeModification]
+	volumeStatusIDBatcherSlow    *batcher.Batcher[string, *types.VolumeStatusItem]
+	volumeStatusIDBatcherFast    *batcher.Batcher[string, *types.VolumeStatusItem]
+
+	// snapshotIDsByName remembers the ID of snapshots looked up or created by name, so that readiness
+	// polling (repeated CreateSnapshot calls for the same name) is batched by snapshot ID instead of by tag.
+	snapshotIDsByName expiringcache.ExpiringCache[string, string]
 }
 
 type cloud struct {
@@ -403,18 +518,29 @@ type cloud struct {
 	region                string
 	ec2                   util.EC2API
 	sm                    util.SageMakerAPI
+	kms                   util.KMSAPI
 	dm                    dm.DeviceManager
+	restoreDevicesOnce    sync.Once
+	attachmentHistory     *attachmentHistory
 	bm                    *batcherManager
 	rm                    *retryManager
 	vwp                   volumeWaitParameters
 	likelyBadDeviceNames  expiringcache.ExpiringCache[string, sync.Map]
 	latestClientTokens    expiringcache.ExpiringCache[string, int]
+	clientTokenStore      ClientTokenStore
 	volumeInitializations expiringcache.ExpiringCache[string, volumeInitialization]
 	latestIOPSLimits      expiringcache.ExpiringCache[string, iopsLimits]
 	cardCountCache        expiringcache.ExpiringCache[string, int]
+	ebsThroughputCache    expiringcache.ExpiringCache[string, int32]
+	kmsKeys               expiringcache.ExpiringCache[string, kmsKeyResolution]
+	clientTokenStrategy   string
+	snapshotQuota         *snapshotQuota
+	storageUsage          storageUsageCache
+	capacity              capacityTracker
 	accountID             string
 	accountIDOnce         sync.Once
 	attemptDryRun         atomic.Bool
+	throttles             *atomic.Int64
 }
 
 var _ Cloud = &cloud{}
@@ -425,23 +551,66 @@ func initVariables() {
 	AwsEbsDriverTagKey = util.GetDriverName() + "/cluster"
 	AllowAutoIOPSIncreaseOnModifyKey = util.GetDriverName() + "/AllowAutoIOPSIncreaseOnModify"
 	IOPSPerGBKey = util.GetDriverName() + "/IOPSPerGb"
+	PendingDeletionTagKey = util.GetDriverName() + "/pending-deletion-at"
+	VolumeInitializationRateTagKey = util.GetDriverName() + "/volume-initialization-rate"
+}
+
+// CloudOptions are the options of the cloud returned by NewCloud. The zero value of each option keeps the default
+// behavior.
+type CloudOptions struct {
+	// AwsSdkDebugLog logs the requests and responses of the AWS SDK.
+	AwsSdkDebugLog bool
+	// UserAgentExtra is appended to the user agent of the AWS requests.
+	UserAgentExtra string
+	// Batching batches the EC2 describe calls.
+	Batching bool
+	// BatchMinDelay and BatchMaxDelay bound the delay of the describe batchers, which grows from BatchMinDelay when
+	// idle to BatchMaxDelay under load. 0 uses DefaultBatchDelay.
+	BatchMinDelay time.Duration
+	BatchMaxDelay time.Duration
+	// DeprecatedMetrics enables the deprecated metrics.
+	DeprecatedMetrics bool
+	// CorrelationIDUserAgent appends the correlation ID of the CSI request to the user agent of EC2 calls.
+	CorrelationIDUserAgent bool
+	// SubsystemUserAgent appends the driver subsystem that caused an EC2 call to its user agent.
+	SubsystemUserAgent bool
+	// SnapshotsPerRegionQuota is the snapshots per Region quota of the account, 0 if it is not checked.
+	SnapshotsPerRegionQuota int
+	// ClientTokenStrategy is how the client token of CreateVolume is derived: from the volume name only
+	// (volume-name) or from the volume name and the parameters of the request (request-hash).
+	ClientTokenStrategy string
+	// APIBudgetRate is the rate of mutating EC2 calls per second split between the API budget classes. 0 disables
+	// API budgets.
+	APIBudgetRate float64
+	// APIBudgetWeights are the relative weights of the API budget classes.
+	APIBudgetWeights map[string]int
+	// RetryPolicy overrides how the driver waits for and retries EC2 operations, nil for the defaults.
+	RetryPolicy *RetryPolicy
+	// AttachmentHistoryLength is the number of attach and detach transitions remembered per volume. 0 disables the
+	// history.
+	AttachmentHistoryLength int
+	// AttachmentHistoryLog also logs each attach and detach transition.
+	AttachmentHistoryLog bool
 }
 
 // NewCloud returns a new instance of AWS cloud
 // It panics if session is invalid.
-func NewCloud(region string, awsSdkDebugLog bool, userAgentExtra string, batchingEnabled bool, deprecatedMetrics bool) Cloud {
-	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
+func NewCloud(region string, opts CloudOptions) Cloud {
+	if emulatorMode() {
+		klog.InfoS("Using an AWS emulator, this is only meant for testing", "endpoint", ec2Endpoint())
+	}
+	cfg, err := config.LoadDefaultConfig(context.Background(), loadOptions(region)...)
 	if err != nil {
 		panic(err)
 	}
 
-	if awsSdkDebugLog {
+	if opts.AwsSdkDebugLog {
 		cfg.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
 	}
 
 	// Set the env var so that the session appends custom user agent string
-	if userAgentExtra != "" {
-		if err := os.Setenv("AWS_EXECUTION_ENV", "aws-ebs-csi-driver-"+driverVersion+"-"+userAgentExtra); err != nil {
+	if opts.UserAgentExtra != "" {
+		if err := os.Setenv("AWS_EXECUTION_ENV", "aws-ebs-csi-driver-"+driverVersion+"-"+opts.UserAgentExtra); err != nil {
 			klog.ErrorS(err, "Failed to set AWS_EXECUTION_ENV")
 		}
 	} else {
@@ -450,13 +619,26 @@ func NewCloud(region string, awsSdkDebugLog bool, userAgentExtra string, batchin
 		}
 	}
 
+	throttles := new(atomic.Int64)
 	ec2Options := func(o *ec2.Options) {
 		o.APIOptions = append(o.APIOptions,
-			RecordRequestsMiddleware(deprecatedMetrics),
+			ClassifyErrorsMiddleware(),
+			OperationStepMiddleware(),
+			RecordRequestsMiddleware(opts.DeprecatedMetrics),
+			CountThrottlesMiddleware(throttles),
 			LogServerErrorsMiddleware(), // This middlware should always be last so it sees an unmangled error
 		)
+		if opts.CorrelationIDUserAgent {
+			o.APIOptions = append(o.APIOptions, CorrelationIDUserAgentMiddleware())
+		}
+		if opts.SubsystemUserAgent {
+			o.APIOptions = append(o.APIOptions, SubsystemUserAgentMiddleware())
+		}
+		if b := newAPIBudget(opts.APIBudgetRate, opts.APIBudgetWeights); b != nil {
+			o.APIOptions = append(o.APIOptions, APIBudgetMiddleware(b))
+		}
 
-		endpoint := os.Getenv("AWS_EC2_ENDPOINT")
+		endpoint := ec2Endpoint()
 		if endpoint != "" {
 			o.BaseEndpoint = &endpoint
 		}
@@ -467,7 +649,16 @@ func NewCloud(region string, awsSdkDebugLog bool, userAgentExtra string, batchin
 		o.RetryMaxAttempts = retryMaxAttempt
 
 		// Allow custom SageMaker endpoint for testing
-		endpoint := os.Getenv("AWS_SAGEMAKER_ENDPOINT")
+		endpoint := serviceEndpoint("AWS_SAGEMAKER_ENDPOINT")
+		if endpoint != "" {
+			o.BaseEndpoint = &endpoint
+		}
+	}
+
+	kmsOptions := func(o *kms.Options) {
+		o.RetryMaxAttempts = retryMaxAttempt
+
+		endpoint := serviceEndpoint("AWS_KMS_ENDPOINT")
 		if endpoint != "" {
 			o.BaseEndpoint = &endpoint
 		}
@@ -488,10 +679,18 @@ func NewCloud(region string, awsSdkDebugLog bool, userAgentExtra string, batchin
 		smClient = sagemaker.NewFromConfig(cfg, smOptions)
 	}
 
+	rm := newRetryManager()
 	var bm *batcherManager
-	if batchingEnabled {
+	if opts.Batching {
 		klog.V(4).InfoS("NewCloud: batching enabled")
-		bm = newBatcherManager(ec2Client)
+		minDelay, maxDelay := opts.BatchMinDelay, opts.BatchMaxDelay
+		if minDelay == 0 {
+			minDelay = DefaultBatchDelay
+		}
+		if maxDelay == 0 {
+			maxDelay = DefaultBatchDelay
+		}
+		bm = newBatcherManager(ec2Client, rm, minDelay, maxDelay)
 	}
 	c := &cloud{
 		awsConfig:             cfg,
@@ -499,14 +698,24 @@ func NewCloud(region string, awsSdkDebugLog bool, userAgentExtra string, batchin
 		dm:                    dm.NewDeviceManager(),
 		ec2:                   ec2Client,
 		sm:                    smClient,
+		kms:                   kms.NewFromConfig(cfg, kmsOptions),
 		bm:                    bm,
-		rm:                    newRetryManager(),
-		vwp:                   vwp,
-		likelyBadDeviceNames:  expiringcache.New[string, sync.Map](cacheForgetDelay),
-		latestClientTokens:    expiringcache.New[string, int](cacheForgetDelay),
-		volumeInitializations: expiringcache.New[string, volumeInitialization](volInitCacheForgetDelay),
-		latestIOPSLimits:      expiringcache.New[string, iopsLimits](iopsLimitCacheForgetDelay),
-		cardCountCache:        expiringcache.New[string, int](cacheForgetDelay),
+		rm:                    rm,
+		vwp:                   vwp.withRetryPolicy(opts.RetryPolicy),
+		likelyBadDeviceNames:  newObservedCache[string, sync.Map]("likely_bad_device_names", cacheForgetDelay),
+		latestClientTokens:    newObservedCache[string, int]("latest_client_tokens", cacheForgetDelay),
+		volumeInitializations: newObservedCache[string, volumeInitialization]("volume_initializations", volInitCacheForgetDelay),
+		latestIOPSLimits:      newObservedCache[string, iopsLimits]("latest_iops_limits", iopsLimitCacheForgetDelay),
+		cardCountCache:        newObservedCache[string, int]("card_counts", cacheForgetDelay),
+		ebsThroughputCache:    newObservedCache[string, int32]("ebs_throughputs", cacheForgetDelay),
+		kmsKeys:               newObservedCache[string, kmsKeyResolution]("kms_keys", kmsKeyCacheForgetDelay),
+		snapshotQuota:         newSnapshotQuota(opts.SnapshotsPerRegionQuota),
+		clientTokenStrategy:   opts.ClientTokenStrategy,
+		attachmentHistory:     newAttachmentHistory(opts.AttachmentHistoryLength, opts.AttachmentHistoryLog),
+		throttles:             throttles,
+	}
+	if c.attachmentHistory != nil {
+		metrics.Recorder().HandleDebug(AttachmentHistoryPath, c.attachmentHistory)
 	}
 
 	// Ensure an EC2 Dry-run API call is made on startup and every dryRunInterval
@@ -524,36 +733,45 @@ func NewCloud(region string, awsSdkDebugLog bool, userAgentExtra string, batchin
 // newBatcherManager initializes a new instance of batcherManager.
 // Each batcher's `entries` set to maximum results returned by relevant EC2 API call without pagination.
 // Each batcher's `delay` minimizes RPC latency and EC2 API calls. Tuned via scalability tests.
-func newBatcherManager(svc util.EC2API) *batcherManager {
-	likelyNotFoundInstanceIDs := expiringcache.New[string, struct{}](cacheForgetDelay)
-	likelyNotFoundVolumeIDs := expiringcache.New[string, struct{}](cacheForgetDelay)
-	likelyNotFoundSnapshotIDs := expiringcache.New[string, struct{}](cacheForgetDelay)
+// Describe batchers adapt their delay between minDelay and maxDelay depending on load.
+// Batchers used by attach/detach have an interactive lane counterpart with a shorter delay, see batcher.Lane.
+func newBatcherManager(svc util.EC2API, rm *retryManager, minDelay, maxDelay time.Duration) *batcherManager {
+	likelyNotFoundInstanceIDs := newObservedCache[string, struct{}]("likely_not_found_instance_ids", cacheForgetDelay)
+	likelyNotFoundVolumeIDs := newObservedCache[string, struct{}]("likely_not_found_volume_ids", cacheForgetDelay)
+	likelyNotFoundSnapshotIDs := newObservedCache[string, struct{}]("likely_not_found_snapshot_ids", cacheForgetDelay)
 
 	return &batcherManager{
-		volumeIDBatcher: batcher.New(500, batchMaxDelay, func(ids []string) (map[string]*types.Volume, error) {
-			return execBatchDescribeVolumes(svc, ids, volumeIDBatcher, likelyNotFoundVolumeIDs)
-		}),
-		volumeTagBatcher: batcher.New(500, batchMaxDelay, func(names []string) (map[string]*types.Volume, error) {
-			return execBatchDescribeVolumes(svc, names, volumeTagBatcher, likelyNotFoundVolumeIDs)
-		}),
-		instanceIDBatcher: batcher.New(50, batchMaxDelay, func(ids []string) (map[string]*types.Instance, error) {
-			return execBatchDescribeInstances(svc, ids, likelyNotFoundInstanceIDs)
-		}),
-		snapshotIDBatcher: batcher.New(1000, batchMaxDelay, func(ids []string) (map[string]*types.Snapshot, error) {
-			return execBatchDescribeSnapshots(svc, ids, snapshotIDBatcher, likelyNotFoundSnapshotIDs)
-		}),
-		snapshotTagBatcher: batcher.New(1000, batchMaxDelay, func(names []string) (map[string]*types.Snapshot, error) {
-			return execBatchDescribeSnapshots(svc, names, snapshotTagBatcher, likelyNotFoundSnapshotIDs)
-		}),
-		volumeModificationIDBatcher: batcher.New(500, batchMaxDelay, func(names []string) (map[string]*types.VolumeModification, error) {
-			return execBatchDescribeVolumesModifications(svc, names)
-		}),
-		volumeStatusIDBatcherSlow: batcher.New(1000, slowVolumeStatusBatchMaxDelay, func(ids []string) (map[string]*types.VolumeStatusItem, error) {
-			return execBatchDescribeVolumeStatus(svc, ids)
-		}),
-		volumeStatusIDBatcherFast: batcher.New(1000, fastVolumeStatusBatchMaxDelay, func(ids []string) (map[string]*types.VolumeStatusItem, error) {
-			return execBatchDescribeVolumeStatus(svc, ids)
-		}),
+		volumeIDBatcher: batcher.NewAdaptive(500, minDelay, maxDelay, func(ctx context.Context, ids []string) (map[string]*types.Volume, error) {
+			return execBatchDescribeVolumes(ctx, svc, rm.batchDescribeVolumesRetryer, ids, volumeIDBatcher, likelyNotFoundVolumeIDs)
+		}).WithObserver(observeBatch("DescribeVolumes", batcher.LaneBulk)),
+		volumeIDBatcherInteractive: batcher.New(500, interactiveBatchMaxDelay, func(ctx context.Context, ids []string) (map[string]*types.Volume, error) {
+			return execBatchDescribeVolumes(ctx, svc, rm.batchDescribeVolumesRetryer, ids, volumeIDBatcher, likelyNotFoundVolumeIDs)
+		}).WithObserver(observeBatch("DescribeVolumes", batcher.LaneInteractive)),
+		volumeTagBatcher: batcher.NewAdaptive(500, minDelay, maxDelay, func(ctx context.Context, names []string) (map[string]*types.Volume, error) {
+			return execBatchDescribeVolumes(ctx, svc, rm.batchDescribeVolumesRetryer, names, volumeTagBatcher, likelyNotFoundVolumeIDs)
+		}).WithObserver(observeBatch("DescribeVolumes", batcher.LaneBulk)),
+		instanceIDBatcher: batcher.NewAdaptive(50, minDelay, maxDelay, func(ctx context.Context, ids []string) (map[string]*types.Instance, error) {
+			return execBatchDescribeInstances(ctx, svc, rm.batchDescribeInstancesRetryer, ids, likelyNotFoundInstanceIDs)
+		}).WithObserver(observeBatch("DescribeInstances", batcher.LaneBulk)),
+		instanceIDBatcherInteractive: batcher.New(50, interactiveBatchMaxDelay, func(ctx context.Context, ids []string) (map[string]*types.Instance, error) {
+			return execBatchDescribeInstances(ctx, svc, rm.batchDescribeInstancesRetryer, ids, likelyNotFoundInstanceIDs)
+		}).WithObserver(observeBatch("DescribeInstances", batcher.LaneInteractive)),
+		snapshotIDBatcher: batcher.NewAdaptive(1000, minDelay, maxDelay, func(ctx context.Context, ids []string) (map[string]*types.Snapshot, error) {
+			return execBatchDescribeSnapshots(ctx, svc, rm.batchDescribeSnapshotsRetryer, ids, snapshotIDBatcher, likelyNotFoundSnapshotIDs)
+		}).WithObserver(observeBatch("DescribeSnapshots", batcher.LaneBulk)),
+		snapshotTagBatcher: batcher.NewAdaptive(1000, minDelay, maxDelay, func(ctx context.Context, names []string) (map[string]*types.Snapshot, error) {
+			return execBatchDescribeSnapshots(ctx, svc, rm.batchDescribeSnapshotsRetryer, names, snapshotTagBatcher, likelyNotFoundSnapshotIDs)
+		}).WithObserver(observeBatch("DescribeSnapshots", batcher.LaneBulk)),
+		volumeModificationIDBatcher: batcher.NewAdaptive(500, minDelay, maxDelay, func(ctx context.Context, names []string) (map[string]*types.VolumeModification, error) {
+			return execBatchDescribeVolumesModifications(ctx, svc, rm.batchDescribeVolumesModificationsRetryer, names)
+		}).WithObserver(observeBatch("DescribeVolumesModifications", batcher.LaneBulk)),
+		volumeStatusIDBatcherSlow: batcher.New(1000, slowVolumeStatusBatchMaxDelay, func(ctx context.Context, ids []string) (map[string]*types.VolumeStatusItem, error) {
+			return execBatchDescribeVolumeStatus(ctx, svc, rm.batchDescribeVolumeStatusRetryer, ids)
+		}).WithObserver(observeBatch("DescribeVolumeStatus", batcher.LaneBulk)),
+		volumeStatusIDBatcherFast: batcher.New(1000, fastVolumeStatusBatchMaxDelay, func(ctx context.Context, ids []string) (map[string]*types.VolumeStatusItem, error) {
+			return execBatchDescribeVolumeStatus(ctx, svc, rm.batchDescribeVolumeStatusRetryer, ids)
+		}).WithObserver(observeBatch("DescribeVolumeStatus", batcher.LaneBulk)),
+		snapshotIDsByName: newObservedCache[string, string]("snapshot_ids_by_name", cacheForgetDelay),
 	}
 }
 
@@ -571,8 +789,72 @@ func removeLikelyBadIds(cache expiringcache.ExpiringCache[string, struct{}], inp
 	return goodIds, likelyBadIds
 }
 
+// Throttles returns the number of attempts of EC2 calls throttled since the driver started.
+func (c *cloud) Throttles() int64 {
+	if c.throttles == nil {
+		return 0
+	}
+	return c.throttles.Load()
+}
+
+// BatchQueueLen returns the number of requests waiting on a batched EC2 call, or 0 if batching is disabled.
+func (c *cloud) BatchQueueLen() int {
+	if c.bm == nil {
+		return 0
+	}
+	bm := c.bm
+	return bm.volumeIDBatcher.Len() + bm.volumeIDBatcherInteractive.Len() + bm.volumeTagBatcher.Len() +
+		bm.instanceIDBatcher.Len() + bm.instanceIDBatcherInteractive.Len() +
+		bm.snapshotIDBatcher.Len() + bm.snapshotTagBatcher.Len() +
+		bm.volumeModificationIDBatcher.Len() + bm.volumeStatusIDBatcherSlow.Len() + bm.volumeStatusIDBatcherFast.Len()
+}
+
+// withInteractiveLane routes batched requests made with the returned context to the interactive lane.
+// It is a no-op when batching is disabled.
+func (c *cloud) withInteractiveLane(ctx context.Context) context.Context {
+	if c.bm == nil {
+		return ctx
+	}
+	return batcher.WithLane(ctx, batcher.LaneInteractive)
+}
+
+// observeBatchWait records how long a caller waited on a batched request, from queueing to result.
+func observeBatchWait(request string, lane batcher.Lane, start time.Time) {
+	labels := map[string]string{"request": request, "lane": string(lane)}
+	metrics.Recorder().ObserveHistogram(metrics.BatchWaitDuration, metrics.BatchWaitDurationHelpText, time.Since(start).Seconds(), labels, nil)
+}
+
+// batchSizeBuckets covers batch sizes from a single task up to the largest batcher's maxEntries.
+var batchSizeBuckets = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000}
+
+// observeBatch returns a batcher observer recording the size of, and number of callers served by, each batch.
+func observeBatch(request string, lane batcher.Lane) func(batchSize, requests int) {
+	labels := map[string]string{"request": request, "lane": string(lane)}
+	return func(batchSize, requests int) {
+		metrics.Recorder().ObserveHistogram(metrics.BatchSize, metrics.BatchSizeHelpText, float64(batchSize), labels, batchSizeBuckets)
+		metrics.Recorder().ObserveHistogram(metrics.BatchRequests, metrics.BatchRequestsHelpText, float64(requests), labels, batchSizeBuckets)
+	}
+}
+
+// newObservedCache returns an expiring cache recording its hits, misses and evictions under name.
+func newObservedCache[KeyType comparable, ValueType any](name string, expirationDelay time.Duration) expiringcache.ExpiringCache[KeyType, ValueType] {
+	hitLabels := map[string]string{"cache": name, "result": string(expiringcache.EventHit)}
+	missLabels := map[string]string{"cache": name, "result": string(expiringcache.EventMiss)}
+	evictionLabels := map[string]string{"cache": name}
+	return expiringcache.New[KeyType, ValueType](expirationDelay).WithObserver(func(event expiringcache.Event) {
+		switch event {
+		case expiringcache.EventHit:
+			metrics.Recorder().IncreaseCount(metrics.ExpiringCacheRequests, metrics.ExpiringCacheRequestsHelpText, hitLabels)
+		case expiringcache.EventMiss:
+			metrics.Recorder().IncreaseCount(metrics.ExpiringCacheRequests, metrics.ExpiringCacheRequestsHelpText, missLabels)
+		case expiringcache.EventEviction:
+			metrics.Recorder().IncreaseCount(metrics.ExpiringCacheEvictions, metrics.ExpiringCacheEvictionsHelpText, evictionLabels)
+		}
+	})
+}
+
 // execBatchDescribeVolumes executes a batched DescribeVolumes API call depending on the type of batcher.
-func execBatchDescribeVolumes(svc util.EC2API, input []string, batcher volumeBatcherType, cache expiringcache.ExpiringCache[string, struct{}]) (map[string]*types.Volume, error) {
+func execBatchDescribeVolumes(ctx context.Context, svc util.EC2API, retryer aws.Retryer, input []string, batcher volumeBatcherType, cache expiringcache.ExpiringCache[string, struct{}]) (map[string]*types.Volume, error) {
 	goodVolumes, badVolumes := removeLikelyBadIds(cache, input)
 
 	var request *ec2.DescribeVolumesInput
@@ -600,14 +882,17 @@ func execBatchDescribeVolumes(svc util.EC2API, input []string, batcher volumeBat
 		return nil, errors.New("execBatchDescribeVolumes: unsupported request type")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), batchDescribeTimeout)
+	ctx, cancel := context.WithTimeout(ctx, batchDescribeTimeout)
 	defer cancel()
+	retryBudget := func(o *ec2.Options) {
+		o.Retryer = retryer
+	}
 
 	var resp []types.Volume
 	var err error
 
 	if len(goodVolumes) >= 1 {
-		resp, err = describeVolumes(ctx, svc, request)
+		resp, err = describeVolumes(ctx, svc, request, retryBudget)
 		if err != nil {
 			knownErrorVols := volumeIDRegex.FindAllString(err.Error(), -1)
 			for _, volID := range knownErrorVols {
@@ -621,7 +906,7 @@ func execBatchDescribeVolumes(svc util.EC2API, input []string, batcher volumeBat
 		request := &ec2.DescribeVolumesInput{
 			VolumeIds: []string{vol},
 		}
-		retryResp, err := describeVolumes(ctx, svc, request)
+		retryResp, err := describeVolumes(ctx, svc, request, retryBudget)
 		if err == nil && len(retryResp) > 0 {
 			cache.Remove(*retryResp[0].VolumeId)
 			resp = append(resp, retryResp...)
@@ -648,13 +933,18 @@ func execBatchDescribeVolumes(svc util.EC2API, input []string, batcher volumeBat
 
 // batchDescribeVolumes processes a DescribeVolumes request. Depending on the request,
 // it determines the appropriate batcher to use, queues the task, and waits for the result.
-func (c *cloud) batchDescribeVolumes(request *ec2.DescribeVolumesInput) (*types.Volume, error) {
+func (c *cloud) batchDescribeVolumes(ctx context.Context, request *ec2.DescribeVolumesInput) (*types.Volume, error) {
 	var b *batcher.Batcher[string, *types.Volume]
 	var task string
+	lane := batcher.LaneBulk
 
 	switch {
 	case len(request.VolumeIds) == 1 && request.VolumeIds[0] != "":
 		b = c.bm.volumeIDBatcher
+		if batcher.LaneFromContext(ctx) == batcher.LaneInteractive {
+			b = c.bm.volumeIDBatcherInteractive
+			lane = batcher.LaneInteractive
+		}
 		task = request.VolumeIds[0]
 
 	case len(request.Filters) == 1 && *request.Filters[0].Name == "tag:"+VolumeNameTagKey && len(request.Filters[0].Values) == 1:
@@ -667,9 +957,16 @@ func (c *cloud) batchDescribeVolumes(request *ec2.DescribeVolumesInput) (*types.
 
 	ch := make(chan batcher.BatchResult[*types.Volume])
 
-	b.AddTask(task, ch)
+	defer observeBatchWait("DescribeVolumes", lane, time.Now())
+	defer util.SetOperationStep(ctx, "batched EC2 DescribeVolumes")()
+	b.AddTask(ctx, task, ch)
 
-	r := <-ch
+	var r batcher.BatchResult[*types.Volume]
+	select {
+	case r = <-ch:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 
 	if r.Err != nil {
 		return nil, r.Err
@@ -723,8 +1020,12 @@ func (c *cloud) CreateDisk(ctx context.Context, volumeName string, diskOptions *
 	}
 
 	createType = diskOptions.VolumeType
-	// If no volume type is specified, GP3 is used as default for newly created volumes.
-	if createType == "" {
+	// If no volume type is specified, GP3 is used as default for newly created volumes, or GP2 on Outposts.
+	switch {
+	case createType != "":
+	case diskOptions.OutpostArn != "":
+		createType = VolumeTypeGP2
+	default:
 		createType = VolumeTypeGP3
 	}
 
@@ -732,6 +1033,24 @@ func (c *cloud) CreateDisk(ctx context.Context, volumeName string, diskOptions *
 		return nil, errors.New("CreateDisk: multi-attach is only supported for io2 volumes")
 	}
 
+	if err = c.checkPartitionARN("Outpost", diskOptions.OutpostArn); err != nil {
+		return nil, err
+	}
+
+	if diskOptions.OutpostArn != "" && !slices.Contains(OutpostVolumeTypes, createType) {
+		return nil, fmt.Errorf("%w: %s volumes are not supported on Outposts, use one of %s", ErrInvalidArgument, createType, strings.Join(OutpostVolumeTypes, ", "))
+	}
+
+	if minSize, ok := minVolumeSizesGiB[createType]; ok && capacityGiB < minSize {
+		return nil, fmt.Errorf("%w: %s volumes must be at least %d GiB, requested %d GiB", ErrInvalidArgument, createType, minSize, capacityGiB)
+	}
+
+	// Resolve the KMS key before creating the volume: EC2 accepts unusable keys and deletes the volume afterwards.
+	kmsKeyID, err := c.ResolveKMSKey(ctx, diskOptions.KmsKeyID)
+	if err != nil {
+		return nil, err
+	}
+
 	tags := make([]types.Tag, 0, len(diskOptions.Tags))
 	for key, value := range diskOptions.Tags {
 		tags = append(tags, types.Tag{Key: aws.String(key), Value: aws.String(value)})
@@ -751,22 +1070,24 @@ func (c *cloud) CreateDisk(ctx context.Context, volumeName string, diskOptions *
 		}
 	}
 
-	// The first client token used for any volume is the volume name as provided via CSI
-	// However, if a volume fails to create asyncronously (that is, the CreateVolume call
-	// succeeds but the volume ultimately fails to create), the client token is burned until
-	// EC2 forgets about its use (measured as 12 hours under normal conditions)
-	//
-	// To prevent becoming stuck for 12 hours when this occurs, we sequentially append "-2",
-	// "-3", "-4", etc to the volume name before hashing on the subsequent attempt after a
-	// volume fails to create because of an IdempotentParameterMismatch AWS error
-	// The most recent appended value is stored in an expiring cache to prevent memory leaks
-	tokenBase := volumeName
-	if tokenNumber, ok := c.latestClientTokens.Get(volumeName); ok {
-		tokenBase += "-" + strconv.Itoa(*tokenNumber)
+	// Local Zones and Wavelength Zones only offer some volume types: default to gp2 there, which all of them offer,
+	// and reject the other types before calling EC2.
+	if supported := ZoneVolumeTypes(zone, zoneID); supported != nil && diskOptions.OutpostArn == "" {
+		location := zone
+		if location == "" {
+			location = zoneID
+		}
+		switch {
+		case diskOptions.VolumeType == "":
+			klog.V(4).InfoS("CreateDisk: defaulting volume type for zone", "volumeName", volumeName, "zone", location, "volumeType", VolumeTypeGP2)
+			createType = VolumeTypeGP2
+		case !slices.Contains(supported, createType):
+			return nil, fmt.Errorf("%w: %s volumes are not supported in %s %s, use one of %s", ErrInvalidArgument, createType, ZoneType(zone, zoneID), location, strings.Join(supported, ", "))
+		}
 	}
 
-	// We use a sha256 hash to guarantee the token that is less than or equal to 64 characters
-	clientToken := sha256.Sum256([]byte(tokenBase))
+	tokenBase := c.clientTokenBase(volumeName, createType, capacityGiB, zone, zoneID, diskOptions)
+	clientToken := c.clientToken(tokenBase)
 
 	azParams := getVolumeLimitsParams{
 		availabilityZone:   zone,
@@ -775,6 +1096,13 @@ func (c *cloud) CreateDisk(ctx context.Context, volumeName string, diskOptions *
 	}
 
 	iopsLimits := c.getVolumeLimits(ctx, createType, azParams)
+	if iopsLimits.maxSizeGiB > 0 && capacityGiB > iopsLimits.maxSizeGiB {
+		location := zone
+		if location == "" {
+			location = zoneID
+		}
+		return nil, fmt.Errorf("%w: %s volumes in %s must be at most %d GiB, requested %d GiB", ErrInvalidArgument, createType, location, iopsLimits.maxSizeGiB, capacityGiB)
+	}
 
 	if diskOptions.IOPS > 0 {
 		iops = diskOptions.IOPS
@@ -785,10 +1113,44 @@ func (c *cloud) CreateDisk(ctx context.Context, volumeName string, diskOptions *
 		iops = capIOPS(createType, capacityGiB, iops, iopsLimits, diskOptions.AllowIOPSPerGBIncrease)
 	}
 
+	request := &diskRequest{
+		volumeType:     createType,
+		capacityGiB:    capacityGiB,
+		iops:           iops,
+		throughput:     diskOptions.Throughput,
+		encrypted:      diskOptions.Encrypted,
+		kmsKeyID:       kmsKeyID,
+		multiAttach:    diskOptions.MultiAttachEnabled,
+		snapshotID:     diskOptions.SnapshotID,
+		sourceVolumeID: diskOptions.SourceVolumeID,
+	}
+	if isClone {
+		// Clones inherit the encryption of their source volume
+		request.encrypted, request.kmsKeyID = false, ""
+	}
+
+	// With request-hash, a retry whose parameters or zone changed gets a new client token, which EC2 would honor by
+	// creating a second volume, and so would any retry once the client token was retired. Return the volume created
+	// by the previous attempt instead if it matches the request, or fail like EC2 does for a reused client token.
+	if c.clientTokenStrategy == ClientTokenStrategyRequestHash || c.clientTokenRetired(tokenBase) {
+		existing, err := c.getVolumeByName(ctx, volumeName)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			if mismatch := request.mismatch(existing); mismatch != "" {
+				klog.InfoS("CreateDisk: volume already exists with different parameters", "volumeName", volumeName, "volumeID", aws.ToString(existing.VolumeId), "mismatch", mismatch)
+				return nil, fmt.Errorf("%w: volume %s has %s", ErrIdempotentParameterMismatch, aws.ToString(existing.VolumeId), mismatch)
+			}
+			klog.InfoS("CreateDisk: volume already exists, returning it instead of creating another one", "volumeName", volumeName, "volumeID", aws.ToString(existing.VolumeId))
+			return c.existingDisk(ctx, existing)
+		}
+	}
+
 	if isClone {
 		copyRequestInput := &ec2.CopyVolumesInput{
 			SourceVolumeId:     aws.String(diskOptions.SourceVolumeID),
-			ClientToken:        aws.String(hex.EncodeToString(clientToken[:])),
+			ClientToken:        aws.String(clientToken),
 			Size:               aws.Int32(capacityGiB),
 			VolumeType:         types.VolumeType(createType),
 			MultiAttachEnabled: aws.Bool(diskOptions.MultiAttachEnabled),
@@ -797,27 +1159,34 @@ func (c *cloud) CreateDisk(ctx context.Context, volumeName string, diskOptions *
 		size, outpostArn, volumeID, err = c.createCloneHelper(ctx, copyRequestInput, iops, diskOptions.Throughput)
 	} else {
 		createRequestInput := &ec2.CreateVolumeInput{
-			ClientToken:        aws.String(hex.EncodeToString(clientToken[:])),
+			ClientToken:        aws.String(clientToken),
 			Size:               aws.Int32(capacityGiB),
 			VolumeType:         types.VolumeType(createType),
 			Encrypted:          aws.Bool(diskOptions.Encrypted),
 			MultiAttachEnabled: aws.Bool(diskOptions.MultiAttachEnabled),
 			TagSpecifications:  []types.TagSpecification{tagSpec},
 		}
+		if kmsKeyID != diskOptions.KmsKeyID {
+			// The client token is computed from the key of the StorageClass, the volume is created with its ARN
+			resolved := *diskOptions
+			resolved.KmsKeyID = kmsKeyID
+			diskOptions = &resolved
+		}
 		size, outpostArn, volumeID, err = c.createVolumeHelper(ctx, diskOptions, createRequestInput, iops, diskOptions.Throughput, zone, zoneID)
 	}
 	if err != nil {
+		if errors.Is(ClassifyError(err), ErrInsufficientCapacity) {
+			c.capacity.recordExhausted(zone, createType)
+		}
 		switch {
 		case isAWSErrorSnapshotNotFound(err):
 			return nil, ErrSourceNotFound
 		case isAWSErrorVolumeNotFound(err):
 			return nil, ErrSourceNotFound
 		case isAWSErrorIdempotentParameterMismatch(err):
-			nextTokenNumber := 2
-			if tokenNumber, ok := c.latestClientTokens.Get(volumeName); ok {
-				nextTokenNumber = *tokenNumber + 1
+			if existing := c.handleClientTokenConflict(ctx, volumeName, tokenBase, request); existing != nil {
+				return c.existingDisk(ctx, existing)
 			}
-			c.latestClientTokens.Set(volumeName, &nextTokenNumber)
 			return nil, ErrIdempotentParameterMismatch
 		case isAWSErrorInvalidParameterCombination(err):
 			return nil, fmt.Errorf("%w: %w", ErrInvalidArgument, err)
@@ -839,7 +1208,7 @@ func (c *cloud) CreateDisk(ctx context.Context, volumeName string, diskOptions *
 			volumes, describeErr := describeVolumes(ctx, c.ec2, request)
 			if describeErr != nil {
 				if isAWSErrorVolumeNotFound(describeErr) {
-					return nil, fmt.Errorf("%w: %w", ErrLimitExceeded, err)
+					return nil, limitExceededError(err, createType)
 				} else {
 					return nil, describeErr
 				}
@@ -851,13 +1220,13 @@ func (c *cloud) CreateDisk(ctx context.Context, volumeName string, diskOptions *
 			} else if l < 1 {
 				// This should in theory be impossible, but if the API
 				// changes or breaks it would cause a panic, so handle it
-				return nil, fmt.Errorf("%w: %w", ErrLimitExceeded, err)
+				return nil, limitExceededError(err, createType)
 			}
 			volumeID = aws.ToString(volumes[0].VolumeId)
 			size = aws.ToInt32(volumes[0].Size)
 			outpostArn = aws.ToString(volumes[0].OutpostArn)
 		case isAwsErrorMaxIOPSLimitExceeded(err):
-			return nil, fmt.Errorf("%w: %w", ErrLimitExceeded, err)
+			return nil, limitExceededError(err, createType)
 		default:
 			return nil, fmt.Errorf("could not create volume in EC2: %w", err)
 		}
@@ -877,8 +1246,66 @@ func (c *cloud) CreateDisk(ctx context.Context, volumeName string, diskOptions *
 	}
 
 	klog.V(7).InfoS("CreateDisk: volume created successfully", "volumeName", volumeName, "volume", volume)
+	c.capacity.recordCreated(zone, createType, size)
+
+	return &Disk{
+		CapacityGiB:          size,
+		VolumeID:             volumeID,
+		AvailabilityZone:     zone,
+		SnapshotID:           diskOptions.SnapshotID,
+		SourceVolumeID:       diskOptions.SourceVolumeID,
+		OutpostArn:           outpostArn,
+		VolumeType:           createType,
+		IOPS:                 iops,
+		Throughput:           diskOptions.Throughput,
+		RequiresBlockExpress: requiresBlockExpress(createType, size, iops),
+		FastRestored:         aws.ToBool(volume.FastRestored),
+	}, nil
+}
 
-	return &Disk{CapacityGiB: size, VolumeID: volumeID, AvailabilityZone: zone, SnapshotID: diskOptions.SnapshotID, SourceVolumeID: diskOptions.SourceVolumeID, OutpostArn: outpostArn}, nil
+// getVolumeByName returns the volume tagged with volumeName that is not in the error state, or nil if there is none.
+func (c *cloud) getVolumeByName(ctx context.Context, volumeName string) (*types.Volume, error) {
+	// Call DescribeVolumes directly as there is a high chance no volume exists and would poison a batch call
+	volumes, err := describeVolumes(ctx, c.ec2, &ec2.DescribeVolumesInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("tag:" + VolumeNameTagKey),
+				Values: []string{volumeName},
+			},
+		},
+	})
+	if err != nil && !isAWSErrorVolumeNotFound(err) {
+		return nil, fmt.Errorf("could not look up volume %s: %w", volumeName, err)
+	}
+	volumes = slices.DeleteFunc(volumes, func(v types.Volume) bool { return v.State == types.VolumeStateError })
+	switch len(volumes) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &volumes[0], nil
+	default:
+		return nil, ErrMultiDisks
+	}
+}
+
+// existingDisk waits for a volume found by getVolumeByName to be available and returns its Disk.
+func (c *cloud) existingDisk(ctx context.Context, volume *types.Volume) (*Disk, error) {
+	volumeID := aws.ToString(volume.VolumeId)
+	available, err := c.waitForVolume(ctx, volumeID)
+	if err != nil {
+		return nil, fmt.Errorf("timed out waiting for volume to create: %w", err)
+	}
+	disk := listedDisk(*available)
+	disk.SnapshotID = aws.ToString(available.SnapshotId)
+	disk.SourceVolumeID = aws.ToString(available.SourceVolumeId)
+	disk.RequiresBlockExpress = requiresBlockExpress(disk.VolumeType, disk.CapacityGiB, disk.IOPS)
+	disk.FastRestored = aws.ToBool(available.FastRestored)
+	return disk, nil
+}
+
+// requiresBlockExpress returns whether an io2 volume needs an instance that supports Block Express.
+func requiresBlockExpress(volumeType string, sizeGiB int32, iops int32) bool {
+	return volumeType == VolumeTypeIO2 && (sizeGiB > io2BlockExpressMinGiB || iops > io2BlockExpressMinIOPS)
 }
 
 func (c *cloud) createCloneHelper(ctx context.Context, input *ec2.CopyVolumesInput, iops int32, throughput int32) (int32, string, string, error) {
@@ -941,16 +1368,18 @@ func (c *cloud) createVolumeHelper(ctx context.Context, diskOptions *DiskOptions
 }
 
 // execBatchDescribeVolumesModifications executes a batched DescribeVolumesModifications API call.
-func execBatchDescribeVolumesModifications(svc util.EC2API, input []string) (map[string]*types.VolumeModification, error) {
+func execBatchDescribeVolumesModifications(ctx context.Context, svc util.EC2API, retryer aws.Retryer, input []string) (map[string]*types.VolumeModification, error) {
 	klog.V(7).InfoS("execBatchDescribeVolumeModifications", "volumeIds", input)
 	request := &ec2.DescribeVolumesModificationsInput{
 		VolumeIds: input,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), batchDescribeTimeout)
+	ctx, cancel := context.WithTimeout(ctx, batchDescribeTimeout)
 	defer cancel()
 
-	resp, err := describeVolumesModifications(ctx, svc, request)
+	resp, err := describeVolumesModifications(ctx, svc, request, func(o *ec2.Options) {
+		o.Retryer = retryer
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -967,7 +1396,7 @@ func execBatchDescribeVolumesModifications(svc util.EC2API, input []string) (map
 }
 
 // batchDescribeVolumesModifications processes a DescribeVolumesModifications request by queuing the task and waiting for the result.
-func (c *cloud) batchDescribeVolumesModifications(request *ec2.DescribeVolumesModificationsInput) (*types.VolumeModification, error) {
+func (c *cloud) batchDescribeVolumesModifications(ctx context.Context, request *ec2.DescribeVolumesModificationsInput) (*types.VolumeModification, error) {
 	var task string
 
 	if len(request.VolumeIds) == 1 && request.VolumeIds[0] != "" {
@@ -979,9 +1408,16 @@ func (c *cloud) batchDescribeVolumesModifications(request *ec2.DescribeVolumesMo
 	ch := make(chan batcher.BatchResult[*types.VolumeModification])
 
 	b := c.bm.volumeModificationIDBatcher
-	b.AddTask(task, ch)
+	defer observeBatchWait("DescribeVolumesModifications", batcher.LaneBulk, time.Now())
+	defer util.SetOperationStep(ctx, "batched EC2 DescribeVolumesModifications")()
+	b.AddTask(ctx, task, ch)
 
-	r := <-ch
+	var r batcher.BatchResult[*types.VolumeModification]
+	select {
+	case r = <-ch:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 
 	if r.Err != nil {
 		return nil, r.Err
@@ -1002,6 +1438,9 @@ func (c *cloud) ModifyTags(ctx context.Context, volumeID string, tagOptions Modi
 		_, deleteErr := c.ec2.DeleteTags(ctx, deleteTagsInput)
 		if deleteErr != nil {
 			klog.ErrorS(deleteErr, "failed to delete tags", "volumeID", volumeID)
+			if isAWSErrorVolumeNotFound(deleteErr) {
+				return fmt.Errorf("%w: %w", ErrNotFound, deleteErr)
+			}
 			return deleteErr
 		}
 	}
@@ -1019,6 +1458,9 @@ func (c *cloud) ModifyTags(ctx context.Context, volumeID string, tagOptions Modi
 		_, addErr := c.ec2.CreateTags(ctx, createTagsInput)
 		if addErr != nil {
 			klog.ErrorS(addErr, "failed to create tags", "volumeID", volumeID)
+			if isAWSErrorVolumeNotFound(addErr) {
+				return fmt.Errorf("%w: %w", ErrNotFound, addErr)
+			}
 			return addErr
 		}
 	}
@@ -1123,11 +1565,8 @@ func (c *cloud) ResizeOrModifyDisk(ctx context.Context, volumeID string, newSize
 			// Wrap error to preserve original message from AWS as to why this was an invalid argument
 			return 0, fmt.Errorf("%w: %w", ErrInvalidArgument, err)
 		}
-		if isAWSErrorVolumeModificationSizeLimitExceeded(err) {
-			return 0, fmt.Errorf("%w: %w", ErrLimitExceeded, err)
-		}
-		if isAwsErrorMaxIOPSLimitExceeded(err) {
-			return 0, fmt.Errorf("%w: %w", ErrLimitExceeded, err)
+		if isAWSErrorVolumeModificationSizeLimitExceeded(err) || isAwsErrorMaxIOPSLimitExceeded(err) {
+			return 0, limitExceededError(err, string(volTypeToUse))
 		}
 		return 0, err
 	}
@@ -1145,19 +1584,197 @@ func (c *cloud) ResizeOrModifyDisk(ctx context.Context, volumeID string, newSize
 
 func (c *cloud) DeleteDisk(ctx context.Context, volumeID string) (bool, error) {
 	request := &ec2.DeleteVolumeInput{VolumeId: &volumeID}
-	if _, err := c.ec2.DeleteVolume(ctx, request, func(o *ec2.Options) {
-		o.Retryer = c.rm.deleteVolumeRetryer
-	}); err != nil {
-		if isAWSErrorVolumeNotFound(err) {
+	err := c.retryDependencyViolation(ctx, volumeID, func() error {
+		_, err := c.ec2.DeleteVolume(ctx, request, func(o *ec2.Options) {
+			o.Retryer = c.rm.deleteVolumeRetryer
+		})
+		return err
+	})
+	switch {
+	case err == nil:
+		return true, nil
+	case isAWSErrorVolumeNotFound(err):
+		return false, ErrNotFound
+	case isAWSErrorIncorrectState(err):
+		// During cleanup storms the same volume is often deleted repeatedly by sidecar retries, and the calls
+		// that race with the deletion fail. Their state checks go through the batched DescribeVolumes path.
+		volume, describeErr := c.getVolume(ctx, &ec2.DescribeVolumesInput{VolumeIds: []string{volumeID}})
+		if errors.Is(describeErr, ErrNotFound) {
 			return false, ErrNotFound
 		}
-		return false, fmt.Errorf("DeleteDisk could not delete volume: %w", err)
+		if describeErr == nil && (volume.State == types.VolumeStateDeleting || volume.State == types.VolumeStateDeleted) {
+			klog.V(4).InfoS("DeleteDisk: volume is already being deleted", "volumeID", volumeID, "state", volume.State)
+			return true, nil
+		}
 	}
-	return true, nil
+	return false, fmt.Errorf("DeleteDisk could not delete volume: %w", err)
+}
+
+// ListPendingDeletionDisks returns the IDs of available volumes that were soft-deleted, mapped to the time
+// after which they may be deleted. Only the volumes created by the driver that also carry every tag in tags are
+// returned, so that volumes of other clusters or tools are never deleted. Volumes whose PendingDeletionTagKey tag
+// cannot be parsed are skipped.
+func (c *cloud) ListPendingDeletionDisks(ctx context.Context, tags map[string]string) (map[string]time.Time, error) {
+	request := &ec2.DescribeVolumesInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("tag-key"),
+				Values: []string{PendingDeletionTagKey},
+			},
+			{
+				Name:   aws.String("status"),
+				Values: []string{string(types.VolumeStateAvailable)},
+			},
+			{
+				Name:   aws.String("tag:" + AwsEbsDriverTagKey),
+				Values: []string{"true"},
+			},
+		},
+	}
+	for key, value := range tags {
+		request.Filters = append(request.Filters, types.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: []string{value},
+		})
+	}
+	volumes, err := describeVolumes(ctx, c.ec2, request)
+	if err != nil {
+		return nil, fmt.Errorf("could not list volumes pending deletion: %w", err)
+	}
+
+	pending := make(map[string]time.Time, len(volumes))
+	for _, volume := range volumes {
+		deleteAfter, ok := pendingDeletionTime(volume.Tags)
+		if !ok {
+			klog.InfoS("ListPendingDeletionDisks: ignoring volume with invalid pending deletion tag", "volumeID", aws.ToString(volume.VolumeId))
+			continue
+		}
+		pending[aws.ToString(volume.VolumeId)] = deleteAfter
+	}
+	return pending, nil
+}
+
+// pendingDeletionTime returns the time stored in the PendingDeletionTagKey tag, and false if the tag is missing or
+// cannot be parsed.
+func pendingDeletionTime(tags []types.Tag) (time.Time, bool) {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) != PendingDeletionTagKey {
+			continue
+		}
+		deleteAfter, err := time.Parse(time.RFC3339, aws.ToString(tag.Value))
+		if err != nil {
+			return time.Time{}, false
+		}
+		return deleteAfter, true
+	}
+	return time.Time{}, false
+}
+
+// ListDisks returns the volumes with the given IDs that also carry every tag in tags.
+// At least one of volumeIDs and tags must be non-empty.
+func (c *cloud) ListDisks(ctx context.Context, volumeIDs []string, tags map[string]string) ([]*Disk, error) {
+	if len(volumeIDs) == 0 && len(tags) == 0 {
+		return nil, fmt.Errorf("ListDisks requires volume IDs or tags: %w", ErrInvalidRequest)
+	}
+
+	request := &ec2.DescribeVolumesInput{
+		VolumeIds: volumeIDs,
+	}
+	for key, value := range tags {
+		request.Filters = append(request.Filters, types.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: []string{value},
+		})
+	}
+	volumes, err := describeVolumes(ctx, c.ec2, request)
+	if err != nil {
+		if isAWSErrorVolumeNotFound(err) {
+			return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+		}
+		return nil, fmt.Errorf("could not list volumes: %w", err)
+	}
+
+	disks := make([]*Disk, 0, len(volumes))
+	for _, volume := range volumes {
+		disks = append(disks, listedDisk(volume))
+	}
+	return disks, nil
+}
+
+// ListDisksPage returns a page of at most maxResults volumes that carry every tag in tags, starting at nextToken.
+// maxResults 0 returns all the volumes.
+func (c *cloud) ListDisksPage(ctx context.Context, tags map[string]string, maxResults int32, nextToken string) (*ListDisksResponse, error) {
+	if maxResults > 0 && maxResults < 5 {
+		return nil, ErrInvalidMaxResults
+	}
+
+	request := &ec2.DescribeVolumesInput{}
+	for key, value := range tags {
+		request.Filters = append(request.Filters, types.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: []string{value},
+		})
+	}
+	if len(nextToken) != 0 {
+		request.NextToken = aws.String(nextToken)
+	}
+
+	var volumes []types.Volume
+	var responseToken string
+	if maxResults == 0 {
+		var err error
+		volumes, err = describeVolumes(ctx, c.ec2, request)
+		if err != nil {
+			return nil, listDisksPageError(err)
+		}
+	} else {
+		// DescribeVolumes returns at most 500 volumes per page.
+		request.MaxResults = aws.Int32(min(maxResults, 500))
+		response, err := c.ec2.DescribeVolumes(ctx, request)
+		if err != nil {
+			return nil, listDisksPageError(err)
+		}
+		volumes = response.Volumes
+		responseToken = aws.ToString(response.NextToken)
+	}
+
+	disks := make([]*Disk, 0, len(volumes))
+	for _, volume := range volumes {
+		disks = append(disks, listedDisk(volume))
+	}
+	return &ListDisksResponse{Disks: disks, NextToken: responseToken}, nil
+}
+
+func listDisksPageError(err error) error {
+	if isAWSError(err, "InvalidPaginationToken") {
+		return fmt.Errorf("%w: %w", ErrInvalidNextToken, err)
+	}
+	return fmt.Errorf("could not list volumes: %w", err)
+}
+
+// listedDisk returns the Disk of a volume returned by DescribeVolumes.
+func listedDisk(volume types.Volume) *Disk {
+	disk := &Disk{
+		VolumeID:           aws.ToString(volume.VolumeId),
+		CapacityGiB:        aws.ToInt32(volume.Size),
+		AvailabilityZone:   aws.ToString(volume.AvailabilityZone),
+		AvailabilityZoneID: aws.ToString(volume.AvailabilityZoneId),
+		OutpostArn:         aws.ToString(volume.OutpostArn),
+		Attachments:        getVolumeAttachmentsList(volume),
+		Encrypted:          aws.ToBool(volume.Encrypted),
+		KmsKeyID:           aws.ToString(volume.KmsKeyId),
+		VolumeType:         string(volume.VolumeType),
+		IOPS:               aws.ToInt32(volume.Iops),
+		Throughput:         aws.ToInt32(volume.Throughput),
+	}
+	if deleteAfter, ok := pendingDeletionTime(volume.Tags); ok {
+		disk.PendingDeletionAt = deleteAfter
+	}
+	return disk
 }
 
 // execBatchDescribeInstances executes a batched DescribeInstances API call.
-func execBatchDescribeInstances(svc util.EC2API, input []string, cache expiringcache.ExpiringCache[string, struct{}]) (map[string]*types.Instance, error) {
+func execBatchDescribeInstances(ctx context.Context, svc util.EC2API, retryer aws.Retryer, input []string, cache expiringcache.ExpiringCache[string, struct{}]) (map[string]*types.Instance, error) {
 	goodInstances, badInstances := removeLikelyBadIds(cache, input)
 
 	klog.V(7).InfoS("execBatchDescribeInstances", "instanceIds", goodInstances)
@@ -1165,14 +1782,17 @@ func execBatchDescribeInstances(svc util.EC2API, input []string, cache expiringc
 		InstanceIds: goodInstances,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), batchDescribeTimeout)
+	ctx, cancel := context.WithTimeout(ctx, batchDescribeTimeout)
 	defer cancel()
+	retryBudget := func(o *ec2.Options) {
+		o.Retryer = retryer
+	}
 
 	var resp []types.Instance
 	var err error
 
 	if len(goodInstances) >= 1 {
-		resp, err = describeInstances(ctx, svc, request)
+		resp, err = describeInstances(ctx, svc, request, retryBudget)
 		if err != nil {
 			knownErrorInstances := instanceIDRegex.FindAllString(err.Error(), -1)
 			for _, instanceID := range knownErrorInstances {
@@ -1186,7 +1806,7 @@ func execBatchDescribeInstances(svc util.EC2API, input []string, cache expiringc
 		request := &ec2.DescribeInstancesInput{
 			InstanceIds: []string{instance},
 		}
-		retryResp, err := describeInstances(ctx, svc, request)
+		retryResp, err := describeInstances(ctx, svc, request, retryBudget)
 		if err == nil && len(retryResp) > 0 {
 			cache.Remove(*retryResp[0].InstanceId)
 			resp = append(resp, retryResp...)
@@ -1211,7 +1831,7 @@ func execBatchDescribeInstances(svc util.EC2API, input []string, cache expiringc
 }
 
 // batchDescribeInstances processes a DescribeInstances request by queuing the task and waiting for the result.
-func (c *cloud) batchDescribeInstances(request *ec2.DescribeInstancesInput) (*types.Instance, error) {
+func (c *cloud) batchDescribeInstances(ctx context.Context, request *ec2.DescribeInstancesInput) (*types.Instance, error) {
 	var task string
 
 	if len(request.InstanceIds) == 1 && request.InstanceIds[0] != "" {
@@ -1222,10 +1842,22 @@ func (c *cloud) batchDescribeInstances(request *ec2.DescribeInstancesInput) (*ty
 
 	ch := make(chan batcher.BatchResult[*types.Instance])
 
+	lane := batcher.LaneBulk
 	b := c.bm.instanceIDBatcher
-	b.AddTask(task, ch)
+	if batcher.LaneFromContext(ctx) == batcher.LaneInteractive {
+		b = c.bm.instanceIDBatcherInteractive
+		lane = batcher.LaneInteractive
+	}
+	defer observeBatchWait("DescribeInstances", lane, time.Now())
+	defer util.SetOperationStep(ctx, "batched EC2 DescribeInstances")()
+	b.AddTask(ctx, task, ch)
 
-	r := <-ch
+	var r batcher.BatchResult[*types.Instance]
+	select {
+	case r = <-ch:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 
 	if r.Err != nil {
 		return nil, r.Err
@@ -1267,11 +1899,71 @@ func (c *cloud) getCardCount(ctx context.Context, instanceType string) int {
 	return cards
 }
 
+// CheckMultiAttachSupport returns ErrMultiAttachNotSupported if multi-attach volumes cannot be attached to the
+// instance, which requires it to be built on the Nitro System.
+func (c *cloud) CheckMultiAttachSupport(ctx context.Context, nodeID string) error {
+	if util.IsHyperPodNode(nodeID) {
+		return nil
+	}
+	instance, err := c.getInstance(ctx, nodeID)
+	if err != nil {
+		return err
+	}
+	if instanceType := string(instance.InstanceType); !limits.IsNitro(instanceType) {
+		return fmt.Errorf("%w: instance type %s of node %s is not built on the Nitro System", ErrMultiAttachNotSupported, instanceType, nodeID)
+	}
+	return nil
+}
+
+// CheckBlockExpressSupport returns ErrBlockExpressNotSupported if io2 volumes that need Block Express cannot be
+// attached to the instance, which requires it to be built on the Nitro System.
+func (c *cloud) CheckBlockExpressSupport(ctx context.Context, nodeID string) error {
+	if util.IsHyperPodNode(nodeID) {
+		return nil
+	}
+	instance, err := c.getInstance(ctx, nodeID)
+	if err != nil {
+		return err
+	}
+	if instanceType := string(instance.InstanceType); !limits.IsNitro(instanceType) {
+		return fmt.Errorf("%w: instance type %s of node %s is not built on the Nitro System", ErrBlockExpressNotSupported, instanceType, nodeID)
+	}
+	return nil
+}
+
+// CheckAttachmentZone returns ErrZoneMismatch if the volume is not in the Availability Zone of the instance. EBS
+// volumes, including multi-attach ones, can only be attached to instances in their Availability Zone.
+func (c *cloud) CheckAttachmentZone(ctx context.Context, volumeID, nodeID string) error {
+	if util.IsHyperPodNode(nodeID) {
+		return nil
+	}
+	disk, err := c.GetDiskByID(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+	instance, err := c.getInstance(ctx, nodeID)
+	if err != nil {
+		return err
+	}
+	if instance.Placement == nil {
+		return nil
+	}
+	if zone := aws.ToString(instance.Placement.AvailabilityZone); zone != disk.AvailabilityZone {
+		return fmt.Errorf("%w: volume %s is in %s but instance %s is in %s", ErrZoneMismatch, volumeID, disk.AvailabilityZone, nodeID, zone)
+	}
+	return nil
+}
+
 func (c *cloud) AttachDisk(ctx context.Context, volumeID, nodeID string) (string, error) {
+	ctx = c.withInteractiveLane(ctx)
 	if util.IsHyperPodNode(nodeID) {
 		return c.attachDiskHyperPod(ctx, volumeID, nodeID)
 	}
 
+	c.restoreDevicesOnce.Do(func() {
+		c.restoreAttachingDevices(ctx)
+	})
+
 	instance, err := c.getInstance(ctx, nodeID)
 	if err != nil {
 		return "", err
@@ -1301,6 +1993,7 @@ func (c *cloud) AttachDisk(ctx context.Context, volumeID, nodeID string) (string
 		resp, attachErr := c.ec2.AttachVolume(ctx, request, func(o *ec2.Options) {
 			o.Retryer = c.rm.attachVolumeRetryer
 		})
+		c.recordAttachment(ctx, volumeID, nodeID, device.Path, "", resp, attachErr)
 		if attachErr != nil {
 			if isAWSErrorBlockDeviceInUse(attachErr) {
 				// If block device is "in use", that likely indicates a bad name that is in use by a block
@@ -1320,6 +2013,7 @@ func (c *cloud) AttachDisk(ctx context.Context, volumeID, nodeID string) (string
 	}
 
 	_, err = c.WaitForAttachmentState(ctx, types.VolumeAttachmentStateAttached, volumeID, *instance.InstanceId, device.Path, device.IsAlreadyAssigned, device.CardIndex)
+	c.attachmentHistory.record(ctx, volumeID, AttachmentEvent{Operation: "AttachVolume", NodeID: nodeID, Device: device.Path, State: waitedAttachmentState(AttachmentStateAttached, err)}, nil, err)
 
 	// This is the only situation where we taint the device
 	if err != nil {
@@ -1333,6 +2027,68 @@ func (c *cloud) AttachDisk(ctx context.Context, volumeID, nodeID string) (string
 	return device.Path, nil
 }
 
+// recordAttachment records an AttachVolume call in the attachment history.
+func (c *cloud) recordAttachment(ctx context.Context, volumeID, nodeID, device, reason string, resp *ec2.AttachVolumeOutput, err error) {
+	event := AttachmentEvent{Operation: "AttachVolume", NodeID: nodeID, Device: device, State: AttachmentStateRequested, Reason: reason}
+	var metadata *middleware.Metadata
+	if resp != nil {
+		metadata = &resp.ResultMetadata
+	}
+	if err != nil {
+		event.State = AttachmentStateFailed
+	}
+	c.attachmentHistory.record(ctx, volumeID, event, metadata, err)
+}
+
+// recordDetachment records a DetachVolume call in the attachment history.
+func (c *cloud) recordDetachment(ctx context.Context, volumeID, nodeID, reason string, resp *ec2.DetachVolumeOutput, err error) {
+	event := AttachmentEvent{Operation: "DetachVolume", NodeID: nodeID, State: AttachmentStateRequested, Reason: reason}
+	var metadata *middleware.Metadata
+	if resp != nil {
+		event.Device = aws.ToString(resp.Device)
+		metadata = &resp.ResultMetadata
+	}
+	if err != nil {
+		event.State = AttachmentStateFailed
+	}
+	c.attachmentHistory.record(ctx, volumeID, event, metadata, err)
+}
+
+// waitedAttachmentState returns the state recorded in the attachment history after waiting for the expected state.
+func waitedAttachmentState(expected string, err error) string {
+	if err != nil {
+		return AttachmentStateFailed
+	}
+	return expected
+}
+
+// restoreAttachingDevices restores the device names of the volumes being attached to instances in the device
+// manager, so that the attachments started before the controller restarted are not assigned the same device name
+// again while DescribeInstances does not report them yet. It is called once, before the first attachment.
+func (c *cloud) restoreAttachingDevices(ctx context.Context) {
+	request := &ec2.DescribeVolumesInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("attachment.status"),
+				Values: []string{string(types.VolumeAttachmentStateAttaching)},
+			},
+		},
+	}
+	volumes, err := describeVolumes(ctx, c.ec2, request)
+	if err != nil {
+		klog.ErrorS(err, "Could not restore the device names of the attachments in progress")
+		return
+	}
+	for _, volume := range volumes {
+		for _, attachment := range volume.Attachments {
+			if attachment.State != types.VolumeAttachmentStateAttaching || attachment.InstanceId == nil || attachment.Device == nil {
+				continue
+			}
+			c.dm.RestoreDevice(aws.ToString(attachment.InstanceId), aws.ToString(volume.VolumeId), aws.ToString(attachment.Device), attachment.EbsCardIndex)
+		}
+	}
+}
+
 func (c *cloud) attachDiskHyperPod(ctx context.Context, volumeID, nodeID string) (string, error) {
 	klog.V(2).InfoS("AttachDisk: HyperPod node detected", "volumeID", volumeID, "nodeID", nodeID)
 
@@ -1391,6 +2147,7 @@ func (c *cloud) attachDiskHyperPod(ctx context.Context, volumeID, nodeID string)
 }
 
 func (c *cloud) DetachDisk(ctx context.Context, volumeID, nodeID string) error {
+	ctx = c.withInteractiveLane(ctx)
 	if util.IsHyperPodNode(nodeID) {
 		return c.detachDiskHyperPod(ctx, volumeID, nodeID)
 	}
@@ -1414,9 +2171,10 @@ func (c *cloud) DetachDisk(ctx context.Context, volumeID, nodeID string) error {
 		VolumeId:   aws.String(volumeID),
 	}
 
-	_, err = c.ec2.DetachVolume(ctx, request, func(o *ec2.Options) {
+	resp, err := c.ec2.DetachVolume(ctx, request, func(o *ec2.Options) {
 		o.Retryer = c.rm.detachVolumeRetryer
 	})
+	c.recordDetachment(ctx, volumeID, nodeID, "", resp, err)
 	if err != nil {
 		if isAWSErrorIncorrectState(err) ||
 			isAWSErrorInvalidAttachmentNotFound(err) ||
@@ -1428,6 +2186,7 @@ func (c *cloud) DetachDisk(ctx context.Context, volumeID, nodeID string) error {
 	}
 
 	attachment, err := c.WaitForAttachmentState(ctx, types.VolumeAttachmentStateDetached, volumeID, *instance.InstanceId, "", false, nil)
+	c.attachmentHistory.record(ctx, volumeID, AttachmentEvent{Operation: "DetachVolume", NodeID: nodeID, State: waitedAttachmentState(AttachmentStateDetached, err)}, nil, err)
 	if err != nil {
 		return err
 	}
@@ -1520,20 +2279,20 @@ func (c *cloud) IsVolumeInitialized(ctx context.Context, volumeID string) (bool,
 	switch {
 	// Case 1: We've never called DVS for volume. Call DVS ASAP.
 	case !ok:
-		volumeStatusItem, err = c.describeVolumeStatus(volumeID, true /* callASAP */)
+		volumeStatusItem, err = c.describeVolumeStatus(ctx, volumeID, true /* callASAP */)
 	// Case 2: We already know volume is initialized. Don't call DVS.
 	case volInit.initialized:
 		return true, nil
 	// Case 3: We know volume is initializing, but there is no SLA. Call DVS eventually during next slow batch.
 	case volInit.estimatedInitializationTime.IsZero():
-		volumeStatusItem, err = c.describeVolumeStatus(volumeID, false /* callASAP */)
+		volumeStatusItem, err = c.describeVolumeStatus(ctx, volumeID, false /* callASAP */)
 	// Case 4: We have an estimated time for initialization. Wait to call DVS again until then unless RPC ctx is done.
 	case !volInit.initialized:
 		util.WaitUntilTimeOrContext(ctx, volInit.estimatedInitializationTime)
 		if err := ctx.Err(); err != nil {
 			return false, err
 		}
-		volumeStatusItem, err = c.describeVolumeStatus(volumeID, true /* callASAP */)
+		volumeStatusItem, err = c.describeVolumeStatus(ctx, volumeID, true /* callASAP */)
 	}
 	if err != nil {
 		return false, err
@@ -1576,19 +2335,23 @@ func isVolumeStatusInitializing(vsi types.VolumeStatusItem) bool {
 	return false
 }
 
-func execBatchDescribeVolumeStatus(svc util.EC2API, input []string) (map[string]*types.VolumeStatusItem, error) {
+func execBatchDescribeVolumeStatus(ctx context.Context, svc util.EC2API, retryer aws.Retryer, input []string) (map[string]*types.VolumeStatusItem, error) {
 	klog.V(7).InfoS("execBatchDescribeVolumeStatus", "volumeIds", input)
 	request := &ec2.DescribeVolumeStatusInput{
 		VolumeIds: input,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), batchDescribeTimeout)
+	ctx, cancel := context.WithTimeout(ctx, batchDescribeTimeout)
 	defer cancel()
 
+	retryBudget := func(o *ec2.Options) {
+		o.Retryer = retryer
+	}
+
 	var volumeStatusItems []types.VolumeStatusItem
 	var nextToken *string
 	for {
-		response, err := svc.DescribeVolumeStatus(ctx, request)
+		response, err := svc.DescribeVolumeStatus(ctx, request, retryBudget)
 		if err != nil {
 			return nil, err
 		}
@@ -1613,7 +2376,7 @@ func execBatchDescribeVolumeStatus(svc util.EC2API, input []string) (map[string]
 
 // describeVolumeStatus will return the VolumeStatusItem associated with volumeID from EC2 DescribeVolumeStatus
 // Set callASAP to true if you need status within seconds (Otherwise it may take minutes).
-func (c *cloud) describeVolumeStatus(volumeID string, callASAP bool) (*types.VolumeStatusItem, error) {
+func (c *cloud) describeVolumeStatus(ctx context.Context, volumeID string, callASAP bool) (*types.VolumeStatusItem, error) {
 	ch := make(chan batcher.BatchResult[*types.VolumeStatusItem])
 
 	var b *batcher.Batcher[string, *types.VolumeStatusItem]
@@ -1622,9 +2385,16 @@ func (c *cloud) describeVolumeStatus(volumeID string, callASAP bool) (*types.Vol
 	} else {
 		b = c.bm.volumeStatusIDBatcherSlow
 	}
-	b.AddTask(volumeID, ch)
+	defer observeBatchWait("DescribeVolumeStatus", batcher.LaneBulk, time.Now())
+	defer util.SetOperationStep(ctx, "batched EC2 DescribeVolumeStatus")()
+	b.AddTask(ctx, volumeID, ch)
 
-	r := <-ch
+	var r batcher.BatchResult[*types.VolumeStatusItem]
+	select {
+	case r = <-ch:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 
 	if r.Err != nil {
 		return nil, r.Err
@@ -1634,6 +2404,7 @@ func (c *cloud) describeVolumeStatus(volumeID string, callASAP bool) (*types.Vol
 
 // WaitForAttachmentState polls until the attachment status is the expected value.
 func (c *cloud) WaitForAttachmentState(ctx context.Context, expectedState types.VolumeAttachmentState, volumeID string, expectedInstance string, expectedDevice string, alreadyAssigned bool, expectedCardIndex *int32) (*types.VolumeAttachment, error) {
+	ctx = c.withInteractiveLane(ctx)
 	var attachment *types.VolumeAttachment
 	isHyperPod := util.IsHyperPodNode(expectedInstance)
 
@@ -1698,10 +2469,11 @@ func (c *cloud) WaitForAttachmentState(ctx context.Context, expectedState types.
 			// force a retry to occur with a fresh slate.
 			if attachmentState == types.VolumeAttachmentStateAttaching && attachment.AttachTime != nil && time.Since(*attachment.AttachTime) > stuckAttachingTimeout {
 				klog.InfoS("WaitForAttachmentState: attachment stuck in attaching state, detaching", "volumeID", volumeID, "instanceID", expectedInstance, "attachTime", attachment.AttachTime)
-				_, err := c.ec2.DetachVolume(ctx, &ec2.DetachVolumeInput{
+				resp, err := c.ec2.DetachVolume(ctx, &ec2.DetachVolumeInput{
 					VolumeId:   aws.String(volumeID),
 					InstanceId: aws.String(expectedInstance),
 				})
+				c.recordDetachment(ctx, volumeID, expectedInstance, "attachment stuck attaching", resp, err)
 				if err != nil {
 					klog.ErrorS(err, "WaitForAttachmentState: failed to detach stuck volume", "volumeID", volumeID, "instanceID", expectedInstance)
 					return false, err
@@ -1734,7 +2506,8 @@ func (c *cloud) WaitForAttachmentState(ctx context.Context, expectedState types.
 				VolumeId:     aws.String(volumeID),
 				EbsCardIndex: expectedCardIndex,
 			}
-			_, err := c.ec2.AttachVolume(ctx, request)
+			resp, err := c.ec2.AttachVolume(ctx, request)
+			c.recordAttachment(ctx, volumeID, expectedInstance, expectedDevice, "volume detached while assigned a device", resp, err)
 			if err != nil {
 				return false, fmt.Errorf("WaitForAttachmentState AttachVolume error, expected device to be attached but was %s, volumeID=%q, instanceID=%q, Device=%q, err=%w", attachmentState, volumeID, expectedInstance, expectedDevice, err)
 			}
@@ -1759,6 +2532,7 @@ func (c *cloud) WaitForAttachmentState(ctx context.Context, expectedState types.
 		return false, nil
 	}
 
+	defer util.SetOperationStep(ctx, "waiting for volume to be "+string(expectedState))()
 	return attachment, wait.ExponentialBackoffWithContext(ctx, c.vwp.attachmentBackoff, verifyVolumeFunc)
 }
 
@@ -1806,12 +2580,16 @@ func (c *cloud) GetDiskByID(ctx context.Context, volumeID string) (*Disk, error)
 		AvailabilityZone: aws.ToString(volume.AvailabilityZone),
 		OutpostArn:       aws.ToString(volume.OutpostArn),
 		Attachments:      getVolumeAttachmentsList(*volume),
+		Encrypted:        aws.ToBool(volume.Encrypted),
 		KmsKeyID:         aws.ToString(volume.KmsKeyId),
 	}
 
 	if volume.Size != nil {
 		disk.CapacityGiB = *volume.Size
 	}
+	if deleteAfter, ok := pendingDeletionTime(volume.Tags); ok {
+		disk.PendingDeletionAt = deleteAfter
+	}
 
 	return disk, nil
 }
@@ -1843,10 +2621,10 @@ func getInstanceIDFromHyperPodNode(nodeID string) string {
 	return parts[2]
 }
 
-// Only for hyperpod node, buildHyperPodClusterArn: arn:aws:sagemaker:region:account:cluster/clusterID.
+// Only for hyperpod node, buildHyperPodClusterArn: arn:partition:sagemaker:region:account:cluster/clusterID.
 func buildHyperPodClusterArn(nodeID string, region string, accountID string) string {
 	parts := strings.Split(nodeID, "-")
-	return fmt.Sprintf("arn:aws:sagemaker:%s:%s:cluster/%s", region, accountID, parts[1])
+	return fmt.Sprintf("arn:%s:sagemaker:%s:%s:cluster/%s", Partition(region), region, accountID, parts[1])
 }
 
 // For hyperpod node, AssociatedResource is in arn:aws:sagemaker:region:account:cluster/clusterID-instanceId format.
@@ -1864,7 +2642,7 @@ func getInstanceIDFromAssociatedResource(arn string) (string, error) {
 }
 
 // execBatchDescribeSnapshots executes a batched DescribeSnapshots API call depending on the type of batcher.
-func execBatchDescribeSnapshots(svc util.EC2API, input []string, batcher snapshotBatcherType, cache expiringcache.ExpiringCache[string, struct{}]) (map[string]*types.Snapshot, error) {
+func execBatchDescribeSnapshots(ctx context.Context, svc util.EC2API, retryer aws.Retryer, input []string, batcher snapshotBatcherType, cache expiringcache.ExpiringCache[string, struct{}]) (map[string]*types.Snapshot, error) {
 	goodSnapshots, badSnapshots := removeLikelyBadIds(cache, input)
 
 	var request *ec2.DescribeSnapshotsInput
@@ -1892,14 +2670,17 @@ func execBatchDescribeSnapshots(svc util.EC2API, input []string, batcher snapsho
 		return nil, errors.New("execBatchDescribeSnapshots: unsupported request type")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), batchDescribeTimeout)
+	ctx, cancel := context.WithTimeout(ctx, batchDescribeTimeout)
 	defer cancel()
+	retryBudget := func(o *ec2.Options) {
+		o.Retryer = retryer
+	}
 
 	var resp []types.Snapshot
 	var err error
 
 	if len(goodSnapshots) >= 1 {
-		resp, err = describeSnapshots(ctx, svc, request)
+		resp, err = describeSnapshots(ctx, svc, request, retryBudget)
 		if err != nil {
 			knownErrorSnapshots := snapshotIDRegex.FindAllString(err.Error(), -1)
 			for _, snapID := range knownErrorSnapshots {
@@ -1913,7 +2694,7 @@ func execBatchDescribeSnapshots(svc util.EC2API, input []string, batcher snapsho
 		request := &ec2.DescribeSnapshotsInput{
 			SnapshotIds: []string{snap},
 		}
-		retryResp, err := describeSnapshots(ctx, svc, request)
+		retryResp, err := describeSnapshots(ctx, svc, request, retryBudget)
 		if err == nil && len(retryResp) > 0 {
 			cache.Remove(*retryResp[0].SnapshotId)
 			resp = append(resp, retryResp...)
@@ -1939,7 +2720,7 @@ func execBatchDescribeSnapshots(svc util.EC2API, input []string, batcher snapsho
 
 // batchDescribeSnapshots processes a DescribeSnapshots request. Depending on the request,
 // it determines the appropriate batcher to use, queues the task, and waits for the result.
-func (c *cloud) batchDescribeSnapshots(request *ec2.DescribeSnapshotsInput) (*types.Snapshot, error) {
+func (c *cloud) batchDescribeSnapshots(ctx context.Context, request *ec2.DescribeSnapshotsInput) (*types.Snapshot, error) {
 	var b *batcher.Batcher[string, *types.Snapshot]
 	var task string
 
@@ -1958,9 +2739,16 @@ func (c *cloud) batchDescribeSnapshots(request *ec2.DescribeSnapshotsInput) (*ty
 
 	ch := make(chan batcher.BatchResult[*types.Snapshot])
 
-	b.AddTask(task, ch)
+	defer observeBatchWait("DescribeSnapshots", batcher.LaneBulk, time.Now())
+	defer util.SetOperationStep(ctx, "batched EC2 DescribeSnapshots")()
+	b.AddTask(ctx, task, ch)
 
-	r := <-ch
+	var r batcher.BatchResult[*types.Snapshot]
+	select {
+	case r = <-ch:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 
 	if r.Err != nil {
 		return nil, r.Err
@@ -2016,12 +2804,15 @@ func (c *cloud) CreateSnapshot(ctx context.Context, volumeID string, snapshotOpt
 	if snapshotOptions.OutpostArn != "" {
 		request.OutpostArn = aws.String(snapshotOptions.OutpostArn)
 	}
+	if err := c.checkSnapshotQuota(ctx, volumeID); err != nil {
+		return nil, err
+	}
 	res, err := c.ec2.CreateSnapshot(ctx, request, func(o *ec2.Options) {
 		o.Retryer = c.rm.createSnapshotRetryer
 	})
 	if err != nil {
 		if isAwsErrorSnapshotLimitExceeded(err) {
-			return nil, fmt.Errorf("%w: %w", ErrLimitExceeded, err)
+			return nil, limitExceededError(err, "")
 		}
 		return nil, fmt.Errorf("error creating snapshot of volume %s: %w", volumeID, err)
 	}
@@ -2029,12 +2820,16 @@ func (c *cloud) CreateSnapshot(ctx context.Context, volumeID string, snapshotOpt
 		return nil, errors.New("nil CreateSnapshotResponse")
 	}
 
+	c.cacheSnapshotID(snapshotOptions.Tags[SnapshotNameTagKey], res.SnapshotId)
+	c.recordSnapshotCreated(volumeID)
+
 	return &Snapshot{
 		SnapshotID:     aws.ToString(res.SnapshotId),
 		SourceVolumeID: aws.ToString(res.VolumeId),
 		Size:           *res.VolumeSize,
 		CreationTime:   aws.ToTime(res.StartTime),
 		ReadyToUse:     res.State == types.SnapshotStateCompleted,
+		Failed:         res.State == types.SnapshotStateError,
 	}, nil
 }
 
@@ -2057,18 +2852,26 @@ func (c *cloud) DeleteSnapshot(ctx context.Context, snapshotID string) (success
 	request := &ec2.DeleteSnapshotInput{}
 	request.SnapshotId = aws.String(snapshotID)
 	request.DryRun = aws.Bool(false)
-	if _, err := c.ec2.DeleteSnapshot(ctx, request, func(o *ec2.Options) {
-		o.Retryer = c.rm.deleteSnapshotRetryer
+	if err := c.retryDependencyViolation(ctx, snapshotID, func() error {
+		_, err := c.ec2.DeleteSnapshot(ctx, request, func(o *ec2.Options) {
+			o.Retryer = c.rm.deleteSnapshotRetryer
+		})
+		return err
 	}); err != nil {
 		if isAWSErrorSnapshotNotFound(err) {
 			return false, ErrNotFound
 		}
 		return false, fmt.Errorf("DeleteSnapshot could not delete snapshot: %w", err)
 	}
+	c.recordSnapshotDeleted()
 	return true, nil
 }
 
 func (c *cloud) GetSnapshotByName(ctx context.Context, name string) (snapshot *Snapshot, err error) {
+	if ec2snapshot := c.getSnapshotByCachedID(ctx, name); ec2snapshot != nil {
+		return c.ec2SnapshotResponseToStruct(*ec2snapshot), nil
+	}
+
 	request := &ec2.DescribeSnapshotsInput{
 		Filters: []types.Filter{
 			{
@@ -2083,9 +2886,43 @@ func (c *cloud) GetSnapshotByName(ctx context.Context, name string) (snapshot *S
 		return nil, err
 	}
 
+	c.cacheSnapshotID(name, ec2snapshot.SnapshotId)
 	return c.ec2SnapshotResponseToStruct(*ec2snapshot), nil
 }
 
+// getSnapshotByCachedID looks up a snapshot by the ID previously seen for its name.
+// It returns nil if batching is disabled, the ID is not known, or the snapshot no longer matches the name.
+func (c *cloud) getSnapshotByCachedID(ctx context.Context, name string) *types.Snapshot {
+	if c.bm == nil {
+		return nil
+	}
+	snapshotID, ok := c.bm.snapshotIDsByName.Get(name)
+	if !ok {
+		return nil
+	}
+	ec2snapshot, err := c.batchDescribeSnapshots(ctx, &ec2.DescribeSnapshotsInput{SnapshotIds: []string{*snapshotID}})
+	if err != nil {
+		klog.V(5).InfoS("getSnapshotByCachedID: falling back to lookup by name", "snapshotName", name, "snapshotID", *snapshotID, "err", err)
+		c.bm.snapshotIDsByName.Remove(name)
+		return nil
+	}
+	for _, tag := range ec2snapshot.Tags {
+		if aws.ToString(tag.Key) == SnapshotNameTagKey && aws.ToString(tag.Value) == name {
+			return ec2snapshot
+		}
+	}
+	c.bm.snapshotIDsByName.Remove(name)
+	return nil
+}
+
+// cacheSnapshotID remembers the snapshot ID for a snapshot name when batching is enabled.
+func (c *cloud) cacheSnapshotID(name string, snapshotID *string) {
+	if c.bm == nil || name == "" || aws.ToString(snapshotID) == "" {
+		return
+	}
+	c.bm.snapshotIDsByName.Set(name, snapshotID)
+}
+
 func (c *cloud) GetSnapshotByID(ctx context.Context, snapshotID string) (snapshot *Snapshot, err error) {
 	request := &ec2.DescribeSnapshotsInput{
 		SnapshotIds: []string{snapshotID},
@@ -2143,6 +2980,39 @@ func (c *cloud) ListSnapshots(ctx context.Context, volumeID string, maxResults i
 	}, nil
 }
 
+// ListSnapshotsByTags returns the snapshots owned by the account that carry every tag in tags. Tag values may use
+// the * and ? wildcards of EC2 filters.
+func (c *cloud) ListSnapshotsByTags(ctx context.Context, tags map[string]string) ([]*Snapshot, error) {
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("ListSnapshotsByTags requires tags: %w", ErrInvalidRequest)
+	}
+	request := &ec2.DescribeSnapshotsInput{
+		OwnerIds: []string{"self"},
+	}
+	for key, value := range tags {
+		request.Filters = append(request.Filters, types.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: []string{value},
+		})
+	}
+	ec2Snapshots, err := describeSnapshots(ctx, c.ec2, request)
+	if err != nil {
+		return nil, fmt.Errorf("could not list snapshots: %w", err)
+	}
+
+	snapshots := make([]*Snapshot, 0, len(ec2Snapshots))
+	for _, ec2Snapshot := range ec2Snapshots {
+		snapshot := c.ec2SnapshotResponseToStruct(ec2Snapshot)
+		for _, tag := range ec2Snapshot.Tags {
+			if aws.ToString(tag.Key) == SnapshotNameTagKey {
+				snapshot.Name = aws.ToString(tag.Value)
+			}
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
 // Helper method converting EC2 snapshot type to the internal struct.
 func (c *cloud) ec2SnapshotResponseToStruct(ec2Snapshot types.Snapshot) *Snapshot {
 	snapshotSize := *ec2Snapshot.VolumeSize
@@ -2151,12 +3021,22 @@ func (c *cloud) ec2SnapshotResponseToStruct(ec2Snapshot types.Snapshot) *Snapsho
 		SourceVolumeID: aws.ToString(ec2Snapshot.VolumeId),
 		Size:           snapshotSize,
 		CreationTime:   *ec2Snapshot.StartTime,
+		OutpostArn:     aws.ToString(ec2Snapshot.OutpostArn),
+	}
+	for _, tag := range ec2Snapshot.Tags {
+		if aws.ToString(tag.Key) != VolumeInitializationRateTagKey {
+			continue
+		}
+		if rate, err := strconv.ParseInt(aws.ToString(tag.Value), 10, 32); err == nil {
+			snapshot.VolumeInitializationRate = int32(rate)
+		}
 	}
 	if ec2Snapshot.State == types.SnapshotStateCompleted {
 		snapshot.ReadyToUse = true
 	} else {
 		snapshot.ReadyToUse = false
 	}
+	snapshot.Failed = ec2Snapshot.State == types.SnapshotStateError
 
 	return snapshot
 }
@@ -2223,11 +3103,11 @@ func (c *cloud) DryRun(ctx context.Context) error {
 	return nil
 }
 
-func describeVolumes(ctx context.Context, svc util.EC2API, request *ec2.DescribeVolumesInput) ([]types.Volume, error) {
+func describeVolumes(ctx context.Context, svc util.EC2API, request *ec2.DescribeVolumesInput, optFns ...func(*ec2.Options)) ([]types.Volume, error) {
 	var volumes []types.Volume
 	var nextToken *string
 	for {
-		response, err := svc.DescribeVolumes(ctx, request)
+		response, err := svc.DescribeVolumes(ctx, request, optFns...)
 		if err != nil {
 			return nil, err
 		}
@@ -2254,15 +3134,15 @@ func (c *cloud) getVolume(ctx context.Context, request *ec2.DescribeVolumesInput
 		}
 		return &volumes[0], nil
 	} else {
-		return c.batchDescribeVolumes(request)
+		return c.batchDescribeVolumes(ctx, request)
 	}
 }
 
-func describeInstances(ctx context.Context, svc util.EC2API, request *ec2.DescribeInstancesInput) ([]types.Instance, error) {
+func describeInstances(ctx context.Context, svc util.EC2API, request *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) ([]types.Instance, error) {
 	instances := []types.Instance{}
 	var nextToken *string
 	for {
-		response, err := svc.DescribeInstances(ctx, request)
+		response, err := svc.DescribeInstances(ctx, request, optFns...)
 		if err != nil {
 			return nil, fmt.Errorf("error listing AWS instances: %w", err)
 		}
@@ -2302,7 +3182,7 @@ func (c *cloud) getInstance(ctx context.Context, nodeID string) (*types.Instance
 
 		return &instances[0], nil
 	} else {
-		return c.batchDescribeInstances(request)
+		return c.batchDescribeInstances(ctx, request)
 	}
 }
 
@@ -2359,11 +3239,11 @@ func (c *cloud) getInstancesPatchingBatch(ctx context.Context, nodeIDs []string)
 	return instances, nil
 }
 
-func describeSnapshots(ctx context.Context, svc util.EC2API, request *ec2.DescribeSnapshotsInput) ([]types.Snapshot, error) {
+func describeSnapshots(ctx context.Context, svc util.EC2API, request *ec2.DescribeSnapshotsInput, optFns ...func(*ec2.Options)) ([]types.Snapshot, error) {
 	var snapshots []types.Snapshot
 	var nextToken *string
 	for {
-		response, err := svc.DescribeSnapshots(ctx, request)
+		response, err := svc.DescribeSnapshots(ctx, request, optFns...)
 		if err != nil {
 			return nil, err
 		}
@@ -2392,7 +3272,7 @@ func (c *cloud) getSnapshot(ctx context.Context, request *ec2.DescribeSnapshotsI
 		}
 		return &snapshots[0], nil
 	} else {
-		return c.batchDescribeSnapshots(request)
+		return c.batchDescribeSnapshots(ctx, request)
 	}
 }
 
@@ -2420,6 +3300,7 @@ func (c *cloud) listSnapshots(ctx context.Context, request *ec2.DescribeSnapshot
 
 // waitForVolume waits for volume to be in the "available" state.
 func (c *cloud) waitForVolume(ctx context.Context, volumeID string) (*types.Volume, error) {
+	defer util.SetOperationStep(ctx, "waiting for volume to be available")()
 	time.Sleep(c.vwp.creationInitialDelay)
 
 	request := &ec2.DescribeVolumesInput{
@@ -2459,12 +3340,12 @@ func (c *cloud) getAccountID(ctx context.Context) (string, error) {
 	go func() {
 		c.accountIDOnce.Do(func() {
 			for c.accountID == "" {
-				cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(c.region))
+				cfg, err := config.LoadDefaultConfig(context.Background(), loadOptions(c.region)...)
 				if err != nil {
 					klog.ErrorS(err, "Failed to create AWS config for account ID retrieval")
 				}
 
-				stsClient := sts.NewFromConfig(cfg)
+				stsClient := sts.NewFromConfig(cfg, stsOptions)
 				resp, err := stsClient.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
 				if err != nil {
 					klog.ErrorS(err, "Failed to get AWS account ID, required for HyperPod operations, will retry")
@@ -2544,6 +3425,13 @@ func isAWSErrorSnapshotNotFound(err error) bool {
 	return isAWSError(err, "InvalidSnapshot.NotFound")
 }
 
+// isAWSErrorDependencyViolation returns a boolean indicating whether the
+// given error reports that a volume or snapshot cannot be deleted because
+// it is in use, by an instance or by an AMI.
+func isAWSErrorDependencyViolation(err error) bool {
+	return isAWSError(err, "VolumeInUse") || isAWSError(err, "InvalidSnapshot.InUse") || isAWSError(err, "DependencyViolation")
+}
+
 // isAWSErrorIdempotentParameterMismatch returns a boolean indicating whether the
 // given error is an AWS IdempotentParameterMismatch error.
 // This error is reported when the two request contains same client-token but different parameters.
@@ -2649,10 +3537,11 @@ func isAwsErrorMaxIOPSLimitExceeded(err error) bool {
 	return isAWSError(err, "MaxIOPSLimitExceeded")
 }
 
-// isAwsErrorSnapshotLimitExceeded checks if the error is a SnapshotLimitExceeded error.
-// This error is reported when the limit on the number of snapshots that can be created is exceeded.
+// isAwsErrorSnapshotLimitExceeded checks if the error is a SnapshotLimitExceeded or ConcurrentSnapshotLimitExceeded
+// error. These errors are reported when the limit on the number of snapshots, or of pending snapshots of a volume,
+// that can be created is exceeded.
 func isAwsErrorSnapshotLimitExceeded(err error) bool {
-	return isAWSError(err, "SnapshotLimitExceeded")
+	return isAWSError(err, "SnapshotLimitExceeded") || isAWSError(err, "ConcurrentSnapshotLimitExceeded")
 }
 
 // isAWSErrorInvalidParameter returns a boolean indicating whether the
@@ -2699,6 +3588,7 @@ func (c *cloud) checkDesiredState(ctx context.Context, volumeID string, desiredS
 
 // waitForVolumeModification waits for a volume modification to finish.
 func (c *cloud) waitForVolumeModification(ctx context.Context, volumeID string) error {
+	defer util.SetOperationStep(ctx, "waiting for volume modification")()
 	waitErr := wait.ExponentialBackoff(c.vwp.modificationBackoff, func() (bool, error) {
 		m, err := c.getLatestVolumeModification(ctx, volumeID, true)
 		// Consider volumes that have never been modified as done
@@ -2723,11 +3613,11 @@ func (c *cloud) waitForVolumeModification(ctx context.Context, volumeID string)
 	return nil
 }
 
-func describeVolumesModifications(ctx context.Context, svc util.EC2API, request *ec2.DescribeVolumesModificationsInput) ([]types.VolumeModification, error) {
+func describeVolumesModifications(ctx context.Context, svc util.EC2API, request *ec2.DescribeVolumesModificationsInput, optFns ...func(*ec2.Options)) ([]types.VolumeModification, error) {
 	volumeModifications := []types.VolumeModification{}
 	var nextToken *string
 	for {
-		response, err := svc.DescribeVolumesModifications(ctx, request)
+		response, err := svc.DescribeVolumesModifications(ctx, request, optFns...)
 		if err != nil {
 			if isAWSErrorModificationNotFound(err) {
 				return nil, ErrVolumeNotBeingModified
@@ -2770,7 +3660,7 @@ func (c *cloud) getLatestVolumeModification(ctx context.Context, volumeID string
 
 		return &volumeMods[len(volumeMods)-1], nil
 	} else {
-		return c.batchDescribeVolumesModifications(request)
+		return c.batchDescribeVolumesModifications(ctx, request)
 	}
 }
 
@@ -2993,7 +3883,8 @@ func (c *cloud) getVolumeLimits(ctx context.Context, volumeType string, azParams
 	useFallBackLimits := (err == nil) // If DryRun unexpectedly succeeds, we use fallback values.
 
 	if err != nil {
-		maxIops, err := extractMaxIOPSFromError(err.Error(), volType)
+		errorMsg := err.Error()
+		maxIops, err := extractMaxIOPSFromError(errorMsg, volType)
 		// Default To Hardcoded Limits if we can't get the max IOPS from the error message.
 		if err != nil {
 			klog.V(5).InfoS("[Debug] error getting IOPS limit, defaulting to hardcoded values", "volumeType", volumeType, "error", err.Error())
@@ -3001,6 +3892,9 @@ func (c *cloud) getVolumeLimits(ctx context.Context, volumeType string, azParams
 		} else {
 			iopsLimits.maxIops = maxIops
 		}
+		if volType == VolumeTypeIO2 {
+			iopsLimits.maxSizeGiB = extractMaxSizeGiBFromError(errorMsg)
+		}
 	}
 
 	if useFallBackLimits {
@@ -3009,6 +3903,7 @@ func (c *cloud) getVolumeLimits(ctx context.Context, volumeType string, azParams
 			iopsLimits.maxIops = io1FallbackMaxIOPS
 		case VolumeTypeIO2:
 			iopsLimits.maxIops = io2FallbackMaxIOPS
+			iopsLimits.maxSizeGiB = io2FallbackMaxGiB
 		case VolumeTypeGP3:
 			iopsLimits.maxIops = gp3FallbackMaxIOPS
 		}
@@ -3060,3 +3955,13 @@ func extractMaxIOPSFromError(errorMsg string, volumeType string) (int32, error)
 
 	return 0, fmt.Errorf("error getting IOPS limit, defaulting to hardcoded values for volume type %s", volumeType)
 }
+
+// Get what the max size of io2 volumes is from DryRun error message, defaulting to io2FallbackMaxGiB.
+func extractMaxSizeGiBFromError(errorMsg string) int32 {
+	if matches := io2SizeErrRegex.FindStringSubmatch(errorMsg); len(matches) > 1 {
+		if val, err := strconv.ParseInt(matches[1], 10, 32); err == nil && val > 0 && val*1024 <= math.MaxInt32 {
+			return int32(val * 1024)
+		}
+	}
+	return io2FallbackMaxGiB
+}
@@ -18,30 +18,46 @@ package cloud
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
 	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
 	smtypes "github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
 	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/aws/smithy-go/ptr"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/golang/mock/gomock"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/batcher"
 	dm "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud/devicemanager"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/expiringcache"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/testutil"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
@@ -96,12 +112,14 @@ func extractVolumeIdentifiers(volumes []types.Volume) (volumeIDs []string, volum
 
 func TestNewCloud(t *testing.T) {
 	testCases := []struct {
-		name              string
-		region            string
-		awsSdkDebugLog    bool
-		userAgentExtra    string
-		batchingEnabled   bool
-		deprecatedMetrics bool
+		name                   string
+		region                 string
+		awsSdkDebugLog         bool
+		userAgentExtra         string
+		batchingEnabled        bool
+		deprecatedMetrics      bool
+		correlationIDUserAgent bool
+		subsystemUserAgent     bool
 	}{
 		{
 			name:            "success: with awsSdkDebugLog, userAgentExtra, and batchingEnabled",
@@ -116,13 +134,30 @@ func TestNewCloud(t *testing.T) {
 			awsSdkDebugLog: true,
 			userAgentExtra: "example_user_agent_extra",
 		},
+		{
+			name:                   "success: with correlationIDUserAgent",
+			region:                 "us-east-1",
+			correlationIDUserAgent: true,
+		},
+		{
+			name:               "success: with subsystemUserAgent",
+			region:             "us-east-1",
+			subsystemUserAgent: true,
+		},
 		{
 			name:   "success: with only region",
 			region: "us-east-1",
 		},
 	}
 	for _, tc := range testCases {
-		ec2Cloud := NewCloud(tc.region, tc.awsSdkDebugLog, tc.userAgentExtra, tc.batchingEnabled, tc.deprecatedMetrics)
+		ec2Cloud := NewCloud(tc.region, CloudOptions{
+			AwsSdkDebugLog:         tc.awsSdkDebugLog,
+			UserAgentExtra:         tc.userAgentExtra,
+			Batching:               tc.batchingEnabled,
+			DeprecatedMetrics:      tc.deprecatedMetrics,
+			CorrelationIDUserAgent: tc.correlationIDUserAgent,
+			SubsystemUserAgent:     tc.subsystemUserAgent,
+		})
 		ec2CloudAscloud, ok := ec2Cloud.(*cloud)
 		if !ok {
 			t.Fatalf("could not assert object ec2Cloud as cloud type, %v", ec2Cloud)
@@ -148,7 +183,7 @@ func TestBatchDescribeVolumes(t *testing.T) {
 			volumes: generateVolumes(10, 0),
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, volumes []types.Volume) {
 				volumeOutput := &ec2.DescribeVolumesOutput{Volumes: volumes}
-				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(volumeOutput, expErr).Times(1)
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(volumeOutput, expErr).Times(1)
 			},
 			expErr: nil,
 		},
@@ -157,7 +192,7 @@ func TestBatchDescribeVolumes(t *testing.T) {
 			volumes: generateVolumes(0, 10),
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, volumes []types.Volume) {
 				volumeOutput := &ec2.DescribeVolumesOutput{Volumes: volumes}
-				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(volumeOutput, expErr).Times(1)
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(volumeOutput, expErr).Times(1)
 			},
 			expErr: nil,
 		},
@@ -166,7 +201,7 @@ func TestBatchDescribeVolumes(t *testing.T) {
 			volumes: generateVolumes(10, 10),
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, volumes []types.Volume) {
 				volumeOutput := &ec2.DescribeVolumesOutput{Volumes: volumes}
-				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(volumeOutput, expErr).Times(2)
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(volumeOutput, expErr).Times(2)
 			},
 			expErr: nil,
 		},
@@ -175,7 +210,7 @@ func TestBatchDescribeVolumes(t *testing.T) {
 			volumes: generateVolumes(500, 0),
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, volumes []types.Volume) {
 				volumeOutput := &ec2.DescribeVolumesOutput{Volumes: volumes}
-				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(volumeOutput, expErr).Times(1)
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(volumeOutput, expErr).Times(1)
 			},
 			expErr: nil,
 		},
@@ -184,7 +219,7 @@ func TestBatchDescribeVolumes(t *testing.T) {
 			volumes: generateVolumes(550, 0),
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, volumes []types.Volume) {
 				volumeOutput := &ec2.DescribeVolumesOutput{Volumes: volumes}
-				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(volumeOutput, expErr).Times(2)
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(volumeOutput, expErr).Times(2)
 			},
 			expErr: nil,
 		},
@@ -192,7 +227,7 @@ func TestBatchDescribeVolumes(t *testing.T) {
 			name:    "fail: EC2 API generic error",
 			volumes: generateVolumes(4, 0),
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, volumes []types.Volume) {
-				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(nil, expErr).Times(1)
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(nil, expErr).Times(1)
 			},
 			expErr: errors.New("Generic EC2 API error"),
 		},
@@ -200,7 +235,7 @@ func TestBatchDescribeVolumes(t *testing.T) {
 			name:    "fail: volume not found",
 			volumes: generateVolumes(1, 0),
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, volumes []types.Volume) {
-				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(nil, expErr).Times(1)
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(nil, expErr).Times(1)
 			},
 			expErr: errors.New("volume not found"),
 		},
@@ -238,7 +273,7 @@ func TestBatchDescribeVolumes(t *testing.T) {
 			if !ok {
 				t.Fatalf("could not assert cloudInstance as type cloud, %v", cloudInstance)
 			}
-			cloudInstance.bm = newBatcherManager(cloudInstance.ec2)
+			cloudInstance.bm = newBatcherManager(cloudInstance.ec2, cloudInstance.rm, DefaultBatchDelay, DefaultBatchDelay)
 
 			tc.mockFunc(mockEC2, tc.expErr, tc.volumes)
 			volumeIDs, volumeNames := extractVolumeIdentifiers(tc.volumes)
@@ -246,6 +281,27 @@ func TestBatchDescribeVolumes(t *testing.T) {
 		})
 	}
 }
+func TestBatchDescribeVolumesInteractiveLane(t *testing.T) {
+	t.Parallel()
+	volumeID := "vol-test-1234"
+	c := &cloud{
+		bm: &batcherManager{
+			volumeIDBatcher: batcher.New(500, DefaultBatchDelay, func(_ context.Context, ids []string) (map[string]*types.Volume, error) {
+				t.Errorf("Expected interactive request not to use the bulk lane, got %v", ids)
+				return nil, nil
+			}),
+			volumeIDBatcherInteractive: batcher.New(500, 0, func(_ context.Context, ids []string) (map[string]*types.Volume, error) {
+				return map[string]*types.Volume{volumeID: {VolumeId: aws.String(volumeID)}}, nil
+			}),
+		},
+	}
+
+	ctx := batcher.WithLane(t.Context(), batcher.LaneInteractive)
+	volume, err := c.batchDescribeVolumes(ctx, &ec2.DescribeVolumesInput{VolumeIds: []string{volumeID}})
+	require.NoError(t, err)
+	assert.Equal(t, volumeID, aws.ToString(volume.VolumeId))
+}
+
 func executeDescribeVolumesTest(t *testing.T, c *cloud, volumeIDs, volumeNames []string, expErr error) {
 	t.Helper()
 	var wg sync.WaitGroup
@@ -282,7 +338,7 @@ func executeDescribeVolumesTest(t *testing.T, c *cloud, volumeIDs, volumeNames [
 		e[i] = make(chan error, 1)
 		go func(resultCh chan *types.Volume, errCh chan error) {
 			defer wg.Done()
-			volume, err := c.batchDescribeVolumes(request)
+			volume, err := c.batchDescribeVolumes(t.Context(), request)
 			if err != nil {
 				errCh <- err
 				return
@@ -325,7 +381,7 @@ func TestBatchDescribeInstances(t *testing.T) {
 			instanceIDs: []string{"i-001", "i-002", "i-003"},
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, reservations []types.Reservation) {
 				reservationOutput := &ec2.DescribeInstancesOutput{Reservations: reservations}
-				mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeInstancesInput{})).Return(reservationOutput, expErr).Times(1)
+				mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeInstancesInput{}), testutil.EC2Options()).Return(reservationOutput, expErr).Times(1)
 			},
 			expErr: nil,
 		},
@@ -333,7 +389,7 @@ func TestBatchDescribeInstances(t *testing.T) {
 			name:        "fail: EC2 API generic error",
 			instanceIDs: []string{"i-001", "i-002", "i-003"},
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, reservations []types.Reservation) {
-				mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeInstancesInput{})).Return(nil, expErr).Times(1)
+				mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeInstancesInput{}), testutil.EC2Options()).Return(nil, expErr).Times(1)
 			},
 			expErr: errors.New("generic EC2 API error"),
 		},
@@ -358,7 +414,7 @@ func TestBatchDescribeInstances(t *testing.T) {
 			if !ok {
 				t.Fatalf("could not assert cloudInstance as type cloud, %v", cloudInstance)
 			}
-			cloudInstance.bm = newBatcherManager(cloudInstance.ec2)
+			cloudInstance.bm = newBatcherManager(cloudInstance.ec2, cloudInstance.rm, DefaultBatchDelay, DefaultBatchDelay)
 
 			// Setup mocks
 			var instances []types.Instance
@@ -397,7 +453,7 @@ func executeDescribeInstancesTest(t *testing.T, c *cloud, instanceIDs []string,
 
 		go func(resultCh chan types.Instance, errCh chan error) {
 			defer wg.Done()
-			instance, err := c.batchDescribeInstances(request)
+			instance, err := c.batchDescribeInstances(t.Context(), request)
 			if err != nil {
 				errCh <- err
 				return
@@ -470,7 +526,7 @@ func TestBatchDescribeSnapshots(t *testing.T) {
 			snapshots: generateSnapshots(3, 0),
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, snapshots []types.Snapshot) {
 				snapshotOutput := &ec2.DescribeSnapshotsOutput{Snapshots: snapshots}
-				mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{})).Return(snapshotOutput, expErr).Times(1)
+				mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{}), testutil.EC2Options()).Return(snapshotOutput, expErr).Times(1)
 			},
 		},
 		{
@@ -478,7 +534,7 @@ func TestBatchDescribeSnapshots(t *testing.T) {
 			snapshots: generateSnapshots(0, 3),
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, snapshots []types.Snapshot) {
 				snapshotOutput := &ec2.DescribeSnapshotsOutput{Snapshots: snapshots}
-				mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{})).Return(snapshotOutput, expErr).Times(1)
+				mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{}), testutil.EC2Options()).Return(snapshotOutput, expErr).Times(1)
 			},
 		},
 		{
@@ -486,14 +542,14 @@ func TestBatchDescribeSnapshots(t *testing.T) {
 			snapshots: generateSnapshots(3, 4),
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, snapshots []types.Snapshot) {
 				snapshotOutput := &ec2.DescribeSnapshotsOutput{Snapshots: snapshots}
-				mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{})).Return(snapshotOutput, expErr).Times(2)
+				mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{}), testutil.EC2Options()).Return(snapshotOutput, expErr).Times(2)
 			},
 		},
 		{
 			name:      "fail: EC2 API generic error",
 			snapshots: generateSnapshots(3, 2),
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, snapshots []types.Snapshot) {
-				mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{})).Return(nil, expErr).Times(2)
+				mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{}), testutil.EC2Options()).Return(nil, expErr).Times(2)
 			},
 			expErr: errors.New("generic EC2 API error"),
 		},
@@ -502,7 +558,7 @@ func TestBatchDescribeSnapshots(t *testing.T) {
 			snapshots: generateSnapshots(3, 0),
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, snapshots []types.Snapshot) {
 				snapshotOutput := &ec2.DescribeSnapshotsOutput{Snapshots: snapshots[1:]} // Leave out first snapshot
-				mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{})).Return(snapshotOutput, nil).Times(1)
+				mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{}), testutil.EC2Options()).Return(snapshotOutput, nil).Times(1)
 			},
 			expErr: ErrNotFound,
 		},
@@ -511,7 +567,7 @@ func TestBatchDescribeSnapshots(t *testing.T) {
 			snapshots: generateSnapshots(0, 2),
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, snapshots []types.Snapshot) {
 				snapshotOutput := &ec2.DescribeSnapshotsOutput{Snapshots: snapshots[1:]} // Leave out first snapshot
-				mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{})).Return(snapshotOutput, nil).Times(1)
+				mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{}), testutil.EC2Options()).Return(snapshotOutput, nil).Times(1)
 			},
 			expErr: ErrNotFound,
 		},
@@ -536,7 +592,7 @@ func TestBatchDescribeSnapshots(t *testing.T) {
 			if !ok {
 				t.Fatalf("could not assert cloudInstance as type cloud, %v", cloudInstance)
 			}
-			cloudInstance.bm = newBatcherManager(cloudInstance.ec2)
+			cloudInstance.bm = newBatcherManager(cloudInstance.ec2, cloudInstance.rm, DefaultBatchDelay, DefaultBatchDelay)
 
 			tc.mockFunc(mockEC2, tc.expErr, tc.snapshots)
 			snapshotIDs, snapshotNames := extractSnapshotIdentifiers(tc.snapshots)
@@ -582,7 +638,7 @@ func executeDescribeSnapshotsTest(t *testing.T, c *cloud, snapshotIDs, snapshotN
 
 		go func(resultCh chan *types.Snapshot, errCh chan error) {
 			defer wg.Done()
-			snapshot, err := c.batchDescribeSnapshots(request)
+			snapshot, err := c.batchDescribeSnapshots(t.Context(), request)
 			if err != nil {
 				errCh <- err
 				return
@@ -723,7 +779,7 @@ func TestBatchDescribeVolumesModifications(t *testing.T) {
 			volumeIDs: []string{"vol-001", "vol-002", "vol-003"},
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, volumeModifications []types.VolumeModification) {
 				volumeModificationsOutput := &ec2.DescribeVolumesModificationsOutput{VolumesModifications: volumeModifications}
-				mockEC2.EXPECT().DescribeVolumesModifications(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesModificationsInput{})).Return(volumeModificationsOutput, expErr).Times(1)
+				mockEC2.EXPECT().DescribeVolumesModifications(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesModificationsInput{}), testutil.EC2Options()).Return(volumeModificationsOutput, expErr).Times(1)
 			},
 			expErr: nil,
 		},
@@ -731,7 +787,7 @@ func TestBatchDescribeVolumesModifications(t *testing.T) {
 			name:      "fail: EC2 API generic error",
 			volumeIDs: []string{"vol-001", "vol-002", "vol-003"},
 			mockFunc: func(mockEC2 *MockEC2API, expErr error, volumeModifications []types.VolumeModification) {
-				mockEC2.EXPECT().DescribeVolumesModifications(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesModificationsInput{})).Return(nil, expErr).Times(1)
+				mockEC2.EXPECT().DescribeVolumesModifications(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesModificationsInput{}), testutil.EC2Options()).Return(nil, expErr).Times(1)
 			},
 			expErr: errors.New("generic EC2 API error"),
 		},
@@ -756,7 +812,7 @@ func TestBatchDescribeVolumesModifications(t *testing.T) {
 			if !ok {
 				t.Fatalf("could not assert cloudInstance as type cloud, %v", cloudInstance)
 			}
-			cloudInstance.bm = newBatcherManager(cloudInstance.ec2)
+			cloudInstance.bm = newBatcherManager(cloudInstance.ec2, cloudInstance.rm, DefaultBatchDelay, DefaultBatchDelay)
 
 			// Setup mocks
 			var volumeModifications []types.VolumeModification
@@ -793,7 +849,7 @@ func executeDescribeVolumesModificationsTest(t *testing.T, c *cloud, volumeIDs [
 
 		go func(resultCh chan types.VolumeModification, errCh chan error) {
 			defer wg.Done()
-			volumeModification, err := c.batchDescribeVolumesModifications(request)
+			volumeModification, err := c.batchDescribeVolumesModifications(t.Context(), request)
 			if err != nil {
 				errCh <- err
 				return
@@ -999,6 +1055,32 @@ func TestCreateDisk(t *testing.T) {
 			},
 			expErr: nil,
 		},
+		{
+			name:       "success: io2 larger than 16 TiB requires Block Express",
+			volumeName: "vol-test-name",
+			diskOptions: &DiskOptions{
+				CapacityBytes: util.GiBToBytes(20000),
+				Tags:          map[string]string{VolumeNameTagKey: "vol-test", AwsEbsDriverTagKey: "true"},
+				VolumeType:    VolumeTypeIO2,
+			},
+			expDisk: &Disk{
+				VolumeID:             "vol-test",
+				CapacityGiB:          20000,
+				AvailabilityZone:     defaultZone,
+				RequiresBlockExpress: true,
+			},
+			expCreateVolumeInput: &ec2.CreateVolumeInput{},
+		},
+		{
+			name:       "fail: io2 larger than the max size of the zone",
+			volumeName: "vol-test-name",
+			diskOptions: &DiskOptions{
+				CapacityBytes: util.GiBToBytes(70000),
+				Tags:          map[string]string{VolumeNameTagKey: "vol-test", AwsEbsDriverTagKey: "true"},
+				VolumeType:    VolumeTypeIO2,
+			},
+			expErr: fmt.Errorf("%w: io2 volumes in %s must be at most 65536 GiB, requested 70000 GiB", ErrInvalidArgument, defaultZone),
+		},
 		{
 			name:       "success: io1 with IOPS parameter",
 			volumeName: "vol-test-name",
@@ -1593,6 +1675,48 @@ func TestCreateDisk(t *testing.T) {
 			},
 			expErr: nil,
 		},
+		{
+			name:       "success: gp3 with too low iopsPerGB and AllowIOPSPerGBIncrease",
+			volumeName: "vol-test-name",
+			diskOptions: &DiskOptions{
+				CapacityBytes:          util.GiBToBytes(4),
+				Tags:                   map[string]string{VolumeNameTagKey: "vol-test", AwsEbsDriverTagKey: "true"},
+				VolumeType:             VolumeTypeGP3,
+				IOPSPerGB:              100,
+				AllowIOPSPerGBIncrease: true,
+			},
+			expDisk: &Disk{
+				VolumeID:         "vol-test",
+				CapacityGiB:      4,
+				AvailabilityZone: defaultZone,
+			},
+			expCreateVolumeInput: &ec2.CreateVolumeInput{
+				Iops: aws.Int32(gp3MinTotalIOPS),
+			},
+			expErr: nil,
+		},
+		{
+			name:       "success: clone gp3 with too low iopsPerGB and AllowIOPSPerGBIncrease",
+			volumeName: "vol-test-name",
+			diskOptions: &DiskOptions{
+				CapacityBytes:          util.GiBToBytes(4),
+				Tags:                   map[string]string{VolumeNameTagKey: "vol-test", AwsEbsDriverTagKey: "true"},
+				VolumeType:             VolumeTypeGP3,
+				IOPSPerGB:              100,
+				AllowIOPSPerGBIncrease: true,
+				AvailabilityZone:       defaultZone,
+				SourceVolumeID:         "test-vol-id",
+			},
+			expDisk: &Disk{
+				VolumeID:         "vol-test",
+				CapacityGiB:      4,
+				AvailabilityZone: defaultZone,
+			},
+			expCopyVolumesInput: &ec2.CopyVolumesInput{
+				Iops: aws.Int32(gp3MinTotalIOPS),
+			},
+			expErr: nil,
+		},
 		{
 			name:       "success: small io2 with too high iopsPerGB",
 			volumeName: "vol-test-name",
@@ -1824,6 +1948,52 @@ func TestCreateDisk(t *testing.T) {
 			},
 			expErr: errors.New("CreateDisk: multi-attach is only supported for io2 volumes"),
 		},
+		{
+			name:       "failure: st1 below minimum size",
+			volumeName: "vol-test-name",
+			diskOptions: &DiskOptions{
+				CapacityBytes: util.GiBToBytes(100),
+				Tags:          map[string]string{VolumeNameTagKey: "vol-test", AwsEbsDriverTagKey: "true"},
+				VolumeType:    VolumeTypeST1,
+			},
+			expErr: fmt.Errorf("%w: st1 volumes must be at least 125 GiB, requested 100 GiB", ErrInvalidArgument),
+		},
+		{
+			name:       "failure: gp3 on outpost",
+			volumeName: "vol-test-name",
+			diskOptions: &DiskOptions{
+				CapacityBytes:    util.GiBToBytes(1),
+				Tags:             map[string]string{VolumeNameTagKey: "vol-test", AwsEbsDriverTagKey: "true"},
+				VolumeType:       VolumeTypeGP3,
+				AvailabilityZone: expZone,
+				OutpostArn:       "arn:aws:outposts:us-west-2:111111111111:outpost/op-0aaa000a0aaaa00a0",
+			},
+			expErr: fmt.Errorf("%w: gp3 volumes are not supported on Outposts, use one of gp2", ErrInvalidArgument),
+		},
+		{
+			name:       "failure: io2 in local zone",
+			volumeName: "vol-test-name",
+			diskOptions: &DiskOptions{
+				CapacityBytes:    util.GiBToBytes(4),
+				Tags:             map[string]string{VolumeNameTagKey: "vol-test", AwsEbsDriverTagKey: "true"},
+				VolumeType:       VolumeTypeIO2,
+				IOPS:             100,
+				AvailabilityZone: "us-west-2-lax-1a",
+			},
+			expErr: fmt.Errorf("%w: io2 volumes are not supported in local-zone us-west-2-lax-1a, use one of gp2, gp3, io1, st1, sc1", ErrInvalidArgument),
+		},
+		{
+			name:       "failure: KMS key of another partition",
+			volumeName: "vol-test-name",
+			diskOptions: &DiskOptions{
+				CapacityBytes:    util.GiBToBytes(4),
+				Tags:             map[string]string{VolumeNameTagKey: "vol-test", AwsEbsDriverTagKey: "true"},
+				Encrypted:        true,
+				KmsKeyID:         "arn:aws-us-gov:kms:us-gov-west-1:012345678910:key/abcd1234-a123-456a-a12b-a123b4cd56ef",
+				AvailabilityZone: defaultZone,
+			},
+			expErr: fmt.Errorf("%w: KMS key \"arn:aws-us-gov:kms:us-gov-west-1:012345678910:key/abcd1234-a123-456a-a12b-a123b4cd56ef\" is in partition aws-us-gov, volumes of region test-region are in partition aws", ErrInvalidArgument),
+		},
 		{
 			name:       "success: create volume returned volume limit exceeded error, but volume exists",
 			volumeName: "vol-test-name",
@@ -1858,10 +2028,10 @@ func TestCreateDisk(t *testing.T) {
 				Code:    "InvalidVolume.NotFound",
 				Message: "Volume not found",
 			},
-			expErr: fmt.Errorf("%w: %w", ErrLimitExceeded, &smithy.GenericAPIError{
+			expErr: &QuotaExceededError{Quota: QuotaStorage, VolumeType: VolumeTypeGP3, Err: &smithy.GenericAPIError{
 				Code:    "VolumeLimitExceeded",
 				Message: "Volume limit exceeded",
-			}),
+			}},
 		},
 		{
 			name:       "failure: create volume returned volume limit exceeded error, describe returns existing volume",
@@ -2012,6 +2182,9 @@ func TestCreateDisk(t *testing.T) {
 					if tc.expDisk.OutpostArn != disk.OutpostArn {
 						t.Fatalf("CreateDisk() failed: expected outpoustArn %q, got %q", tc.expDisk.OutpostArn, disk.OutpostArn)
 					}
+					if tc.expDisk.RequiresBlockExpress != disk.RequiresBlockExpress {
+						t.Fatalf("CreateDisk() failed: expected requiresBlockExpress %t, got %t", tc.expDisk.RequiresBlockExpress, disk.RequiresBlockExpress)
+					}
 				}
 			}
 
@@ -2020,6 +2193,270 @@ func TestCreateDisk(t *testing.T) {
 	}
 }
 
+func TestCreateDiskZoneVolumeType(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name          string
+		zone          string
+		zoneID        string
+		volumeType    string
+		expVolumeType types.VolumeType
+	}{
+		{
+			name:          "availability zone defaults to gp3",
+			zone:          "us-west-2a",
+			expVolumeType: types.VolumeTypeGp3,
+		},
+		{
+			name:          "local zone defaults to gp2",
+			zone:          "us-west-2-lax-1a",
+			expVolumeType: types.VolumeTypeGp2,
+		},
+		{
+			name:          "wavelength zone ID defaults to gp2",
+			zoneID:        "use1-wl1-bos-wlz1",
+			expVolumeType: types.VolumeTypeGp2,
+		},
+		{
+			name:          "local zone keeps supported type",
+			zone:          "us-west-2-lax-1a",
+			volumeType:    VolumeTypeST1,
+			expVolumeType: types.VolumeTypeSt1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			mockEC2 := NewMockEC2API(mockCtrl)
+			c := newCloud(mockEC2)
+
+			location := tc.zone
+			if location == "" {
+				location = tc.zoneID
+			}
+			mockEC2.EXPECT().CreateVolume(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, input *ec2.CreateVolumeInput, _ ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error) {
+					if input.DryRun != nil && *input.DryRun {
+						return nil, errors.New("dry run")
+					}
+					assert.Equal(t, tc.expVolumeType, input.VolumeType)
+					return &ec2.CreateVolumeOutput{VolumeId: aws.String("vol-test"), Size: aws.Int32(500)}, nil
+				}).MinTimes(1)
+			mockEC2.EXPECT().DescribeVolumes(gomock.Any(), gomock.Any(), gomock.Any()).Return(&ec2.DescribeVolumesOutput{
+				Volumes: []types.Volume{{VolumeId: aws.String("vol-test"), Size: aws.Int32(500), State: types.VolumeStateAvailable, AvailabilityZone: aws.String(location)}},
+			}, nil).AnyTimes()
+
+			_, err := c.CreateDisk(t.Context(), "vol-test-name", &DiskOptions{
+				CapacityBytes:      util.GiBToBytes(500),
+				Tags:               map[string]string{VolumeNameTagKey: "vol-test", AwsEbsDriverTagKey: "true"},
+				VolumeType:         tc.volumeType,
+				AvailabilityZone:   tc.zone,
+				AvailabilityZoneID: tc.zoneID,
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestZoneType(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		zone    string
+		zoneID  string
+		expType string
+	}{
+		{zone: "us-west-2a", expType: ZoneTypeAvailabilityZone},
+		{zone: "us-gov-west-1a", expType: ZoneTypeAvailabilityZone},
+		{zoneID: "usw2-az1", expType: ZoneTypeAvailabilityZone},
+		{zone: "us-west-2-lax-1a", expType: ZoneTypeLocalZone},
+		{zoneID: "usw2-lax1-az1", expType: ZoneTypeLocalZone},
+		{zone: "us-east-1-wl1-bos-wlz-1", expType: ZoneTypeWavelengthZone},
+		{zoneID: "use1-wl1-bos-wlz1", expType: ZoneTypeWavelengthZone},
+		{expType: ZoneTypeAvailabilityZone},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expType, ZoneType(tc.zone, tc.zoneID), "zone %q, zone ID %q", tc.zone, tc.zoneID)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	testCases := []struct {
+		region       string
+		expPartition string
+	}{
+		{region: "us-east-1", expPartition: PartitionAWS},
+		{region: "eu-west-1", expPartition: PartitionAWS},
+		{region: "cn-north-1", expPartition: PartitionChina},
+		{region: "cn-northwest-1", expPartition: PartitionChina},
+		{region: "us-gov-west-1", expPartition: PartitionGovCloud},
+		{region: "us-gov-east-1", expPartition: PartitionGovCloud},
+		{region: "us-iso-east-1", expPartition: PartitionISO},
+		{region: "us-isob-east-1", expPartition: PartitionISOB},
+		{region: "eu-isoe-west-1", expPartition: PartitionISOE},
+		{region: "us-isof-south-1", expPartition: PartitionISOF},
+		{region: "eusc-de-east-1", expPartition: PartitionEUSC},
+		{region: "", expPartition: PartitionAWS},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expPartition, Partition(tc.region), "region %q", tc.region)
+	}
+}
+
+func TestCheckPartitionARN(t *testing.T) {
+	testCases := []struct {
+		name     string
+		region   string
+		resource string
+		expErr   bool
+	}{
+		{
+			name:     "aws key in aws region",
+			region:   "us-east-1",
+			resource: "arn:aws:kms:us-east-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab",
+		},
+		{
+			name:     "GovCloud key in GovCloud region",
+			region:   "us-gov-west-1",
+			resource: "arn:aws-us-gov:kms:us-gov-west-1:111122223333:alias/ebs",
+		},
+		{
+			name:     "China Outpost in China region",
+			region:   "cn-north-1",
+			resource: "arn:aws-cn:outposts:cn-north-1:111122223333:outpost/op-0aaa000a0aaaa00a0",
+		},
+		{
+			name:     "aws key in GovCloud region",
+			region:   "us-gov-west-1",
+			resource: "arn:aws:kms:us-east-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab",
+			expErr:   true,
+		},
+		{
+			name:     "aws key in China region",
+			region:   "cn-northwest-1",
+			resource: "arn:aws:kms:us-east-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab",
+			expErr:   true,
+		},
+		{
+			name:     "China key in aws region",
+			region:   "us-east-1",
+			resource: "arn:aws-cn:kms:cn-north-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab",
+			expErr:   true,
+		},
+		{
+			name:     "alias name",
+			region:   "cn-north-1",
+			resource: "alias/ebs",
+		},
+		{
+			name:     "unknown region",
+			resource: "arn:aws-cn:kms:cn-north-1:111122223333:alias/ebs",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &cloud{region: tc.region}
+			err := c.checkPartitionARN("KMS key", tc.resource)
+			if tc.expErr {
+				require.ErrorIs(t, err, ErrInvalidArgument)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestResolveKMSKey(t *testing.T) {
+	keyARN := "arn:aws:kms:us-west-2:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	enabledKey := &kms.DescribeKeyOutput{KeyMetadata: &kmstypes.KeyMetadata{
+		Arn:      aws.String(keyARN),
+		KeyState: kmstypes.KeyStateEnabled,
+		KeyUsage: kmstypes.KeyUsageTypeEncryptDecrypt,
+		KeySpec:  kmstypes.KeySpecSymmetricDefault,
+	}}
+
+	testCases := []struct {
+		name     string
+		keyID    string
+		response *kms.DescribeKeyOutput
+		err      error
+		expARN   string
+		expErr   error
+	}{
+		{
+			name:     "success: alias",
+			keyID:    "alias/ebs",
+			response: enabledKey,
+			expARN:   keyARN,
+		},
+		{
+			name:  "success: not allowed to describe the key",
+			keyID: keyARN,
+			err: &smithy.GenericAPIError{
+				Code: "AccessDeniedException",
+			},
+			expARN: keyARN,
+		},
+		{
+			name:  "failure: key does not exist",
+			keyID: "alias/missing",
+			err: &smithy.GenericAPIError{
+				Code: "NotFoundException",
+			},
+			expErr: ErrInvalidArgument,
+		},
+		{
+			name:  "failure: disabled key",
+			keyID: "alias/ebs",
+			response: &kms.DescribeKeyOutput{KeyMetadata: &kmstypes.KeyMetadata{
+				Arn:      aws.String(keyARN),
+				KeyState: kmstypes.KeyStateDisabled,
+			}},
+			expErr: ErrInvalidArgument,
+		},
+		{
+			name:  "failure: asymmetric key",
+			keyID: "alias/ebs",
+			response: &kms.DescribeKeyOutput{KeyMetadata: &kmstypes.KeyMetadata{
+				Arn:      aws.String(keyARN),
+				KeyState: kmstypes.KeyStateEnabled,
+				KeyUsage: kmstypes.KeyUsageTypeEncryptDecrypt,
+				KeySpec:  kmstypes.KeySpecRsa2048,
+			}},
+			expErr: ErrInvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockKMS := NewMockKMSAPI(mockCtrl)
+			c := &cloud{
+				kms:     mockKMS,
+				kmsKeys: expiringcache.New[string, kmsKeyResolution](kmsKeyCacheForgetDelay),
+			}
+			mockKMS.EXPECT().DescribeKey(gomock.Any(), &kms.DescribeKeyInput{KeyId: aws.String(tc.keyID)}).Return(tc.response, tc.err).Times(1)
+
+			for range 2 {
+				arn, err := c.ResolveKMSKey(t.Context(), tc.keyID)
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+					// Errors are not cached, the key is described again
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tc.expARN, arn)
+			}
+		})
+	}
+
+	t.Run("no KMS client", func(t *testing.T) {
+		arn, err := (&cloud{}).ResolveKMSKey(t.Context(), "alias/ebs")
+		require.NoError(t, err)
+		assert.Equal(t, "alias/ebs", arn)
+	})
+}
+
 // Test client error IdempotentParameterMismatch by forcing it to progress twice.
 func TestCreateDiskClientToken(t *testing.T) {
 	t.Parallel()
@@ -2056,11 +2493,21 @@ func TestCreateDiskClientToken(t *testing.T) {
 				assert.Equal(t, expectedClientToken1, *input.ClientToken)
 				return nil, &smithy.GenericAPIError{Code: "IdempotentParameterMismatch"}
 			}),
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(&ec2.DescribeVolumesOutput{}, nil),
+		// Once the client token is retired, the volume is looked up before it is created
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(&ec2.DescribeVolumesOutput{}, nil),
 		mockEC2.EXPECT().CreateVolume(testutil.AnyContext(), testutil.EC2Input(&ec2.CreateVolumeInput{}), testutil.EC2Options()).DoAndReturn(
 			func(_ context.Context, input *ec2.CreateVolumeInput, _ ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error) {
 				assert.Equal(t, expectedClientToken2, *input.ClientToken)
 				return nil, &smithy.GenericAPIError{Code: "IdempotentParameterMismatch"}
 			}),
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(&ec2.DescribeVolumesOutput{
+			// A volume that failed to create does not prevent moving to the next client token
+			Volumes: []types.Volume{{VolumeId: aws.String("vol-failed"), State: types.VolumeStateError}},
+		}, nil),
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(&ec2.DescribeVolumesOutput{
+			Volumes: []types.Volume{{VolumeId: aws.String("vol-failed"), State: types.VolumeStateError}},
+		}, nil),
 		mockEC2.EXPECT().CreateVolume(testutil.AnyContext(), testutil.EC2Input(&ec2.CreateVolumeInput{}), testutil.EC2Options()).DoAndReturn(
 			func(_ context.Context, input *ec2.CreateVolumeInput, _ ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error) {
 				assert.Equal(t, expectedClientToken3, *input.ClientToken)
@@ -2093,38 +2540,269 @@ func TestCreateDiskClientToken(t *testing.T) {
 	}
 }
 
-func TestDeleteDisk(t *testing.T) {
-	testCases := []struct {
-		name     string
-		volumeID string
-		expResp  bool
-		expErr   error
-	}{
-		{
-			name:     "success: normal",
-			volumeID: "vol-test-1234",
-			expResp:  true,
-			expErr:   nil,
-		},
-		{
-			name:     "fail: DeleteVolume returned generic error",
-			volumeID: "vol-test-1234",
-			expResp:  false,
-			expErr:   errors.New("DeleteVolume generic error"),
-		},
-		{
-			name:     "fail: DeleteVolume returned not found error",
-			volumeID: "vol-test-1234",
-			expResp:  false,
-			expErr:   errors.New("InvalidVolume.NotFound"),
-		},
-	}
+func TestCreateDiskClientTokenExistingVolume(t *testing.T) {
+	t.Parallel()
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			mockCtrl := gomock.NewController(t)
-			mockEC2 := NewMockEC2API(mockCtrl)
-			c := newCloud(mockEC2)
+	const volumeName = "test-vol-client-token-existing"
+	diskOptions := &DiskOptions{
+		CapacityBytes:    util.GiBToBytes(1),
+		Tags:             map[string]string{VolumeNameTagKey: volumeName, AwsEbsDriverTagKey: "true"},
+		AvailabilityZone: defaultZone,
+	}
+
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2)
+
+	var clientTokens []string
+	mockEC2.EXPECT().CreateVolume(testutil.AnyContext(), testutil.EC2Input(&ec2.CreateVolumeInput{}), testutil.EC2Options()).DoAndReturn(
+		func(_ context.Context, input *ec2.CreateVolumeInput, _ ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error) {
+			if input.DryRun != nil && *input.DryRun {
+				return nil, errors.New("Volume iops of 2147483647 is too high; maximum is 16000.")
+			}
+			clientTokens = append(clientTokens, *input.ClientToken)
+			return nil, &smithy.GenericAPIError{Code: "IdempotentParameterMismatch"}
+		}).Times(2)
+	// The volume created by a previous request with different parameters exists
+	mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(&ec2.DescribeVolumesOutput{
+		Volumes: []types.Volume{{VolumeId: aws.String("vol-existing"), State: types.VolumeStateAvailable, VolumeType: types.VolumeTypeGp3, Size: aws.Int32(2)}},
+	}, nil).Times(2)
+
+	for range 2 {
+		_, err := c.CreateDisk(t.Context(), volumeName, diskOptions)
+		require.ErrorIs(t, err, ErrIdempotentParameterMismatch)
+	}
+	require.Len(t, clientTokens, 1, "no volume must be created while a volume with different parameters exists")
+}
+
+func TestCreateDiskClientTokenConflictMatchingVolume(t *testing.T) {
+	t.Parallel()
+
+	const volumeName = "test-vol-client-token-matching"
+	diskOptions := &DiskOptions{
+		CapacityBytes:    util.GiBToBytes(1),
+		Tags:             map[string]string{VolumeNameTagKey: volumeName, AwsEbsDriverTagKey: "true"},
+		AvailabilityZone: defaultZone,
+	}
+	existing := types.Volume{
+		VolumeId:         aws.String("vol-existing"),
+		Size:             aws.Int32(1),
+		AvailabilityZone: aws.String(defaultZone),
+		VolumeType:       types.VolumeTypeGp3,
+		State:            types.VolumeStateAvailable,
+	}
+
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2)
+
+	mockEC2.EXPECT().CreateVolume(testutil.AnyContext(), testutil.EC2Input(&ec2.CreateVolumeInput{}), testutil.EC2Options()).DoAndReturn(
+		func(_ context.Context, input *ec2.CreateVolumeInput, _ ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error) {
+			if input.DryRun != nil && *input.DryRun {
+				return nil, errors.New("Volume iops of 2147483647 is too high; maximum is 16000.")
+			}
+			return nil, &smithy.GenericAPIError{Code: "IdempotentParameterMismatch"}
+		}).Times(2)
+	mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(&ec2.DescribeVolumesOutput{
+		Volumes: []types.Volume{existing},
+	}, nil).MinTimes(1)
+
+	disk, err := c.CreateDisk(t.Context(), volumeName, diskOptions)
+	require.NoError(t, err)
+	assert.Equal(t, "vol-existing", disk.VolumeID)
+	assert.False(t, c.(*cloud).clientTokenRetired(volumeName), "client token must be kept for the volume it created")
+}
+
+// fakeClientTokenStore is a ClientTokenStore kept in memory.
+type fakeClientTokenStore struct {
+	numbers map[string]int
+}
+
+func (s *fakeClientTokenStore) Load(_ context.Context) (map[string]int, error) {
+	return maps.Clone(s.numbers), nil
+}
+
+func (s *fakeClientTokenStore) Save(_ context.Context, tokenBase string, number int) error {
+	s.numbers[tokenBase] = number
+	return nil
+}
+
+func TestCreateDiskClientTokenStore(t *testing.T) {
+	t.Parallel()
+
+	const volumeName = "test-vol-client-token"
+	diskOptions := &DiskOptions{
+		CapacityBytes:    util.GiBToBytes(1),
+		Tags:             map[string]string{VolumeNameTagKey: volumeName, AwsEbsDriverTagKey: "true"},
+		AvailabilityZone: defaultZone,
+	}
+	// Hash of "test-vol-client-token-2"
+	const expectedClientToken2 = "21465f5586388bb8804d0cec2df13c00f9a975c8cddec4bc35e964cdce59015b"
+
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2)
+	// A previous controller retired the first client token of the volume
+	store := &fakeClientTokenStore{numbers: map[string]int{volumeName: 2}}
+	require.NoError(t, c.SetClientTokenStore(t.Context(), store))
+
+	gomock.InOrder(
+		mockEC2.EXPECT().CreateVolume(testutil.AnyContext(), testutil.EC2Input(&ec2.CreateVolumeInput{}), testutil.EC2Options()).DoAndReturn(
+			func(_ context.Context, input *ec2.CreateVolumeInput, _ ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error) {
+				if input.DryRun != nil && *input.DryRun {
+					return nil, errors.New("Volume iops of 2147483647 is too high; maximum is 16000.")
+				}
+				return nil, errors.New("unexpected non-dry-run call")
+			}),
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(&ec2.DescribeVolumesOutput{}, nil),
+		mockEC2.EXPECT().CreateVolume(testutil.AnyContext(), testutil.EC2Input(&ec2.CreateVolumeInput{}), testutil.EC2Options()).DoAndReturn(
+			func(_ context.Context, input *ec2.CreateVolumeInput, _ ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error) {
+				assert.Equal(t, expectedClientToken2, *input.ClientToken)
+				return nil, &smithy.GenericAPIError{Code: "IdempotentParameterMismatch"}
+			}),
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(&ec2.DescribeVolumesOutput{}, nil),
+	)
+
+	_, err := c.CreateDisk(t.Context(), volumeName, diskOptions)
+	require.ErrorIs(t, err, ErrIdempotentParameterMismatch)
+	assert.Equal(t, 3, store.numbers[volumeName], "the next client token must be persisted")
+}
+
+func TestCreateDiskRequestHashExistingVolume(t *testing.T) {
+	t.Parallel()
+
+	const volumeName = "test-vol-request-hash"
+	diskOptions := &DiskOptions{
+		CapacityBytes:    util.GiBToBytes(10),
+		Tags:             map[string]string{VolumeNameTagKey: volumeName, AwsEbsDriverTagKey: "true"},
+		AvailabilityZone: defaultZone,
+		VolumeType:       VolumeTypeGP3,
+		IOPS:             4000,
+	}
+	existing := types.Volume{
+		VolumeId:         aws.String("vol-existing"),
+		Size:             aws.Int32(10),
+		AvailabilityZone: aws.String("us-east-1b"),
+		VolumeType:       types.VolumeTypeGp3,
+		Iops:             aws.Int32(4000),
+		State:            types.VolumeStateAvailable,
+	}
+	drifted := existing
+	drifted.Iops = aws.Int32(3000)
+
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2)
+	c.(*cloud).clientTokenStrategy = ClientTokenStrategyRequestHash
+
+	gomock.InOrder(
+		// A previous attempt created the volume in another zone, so the client token differs
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(
+			&ec2.DescribeVolumesOutput{Volumes: []types.Volume{{VolumeId: aws.String("vol-failed"), State: types.VolumeStateError}, existing}}, nil),
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(
+			&ec2.DescribeVolumesOutput{Volumes: []types.Volume{existing}}, nil),
+		// A previous attempt created the volume with other IOPS
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(
+			&ec2.DescribeVolumesOutput{Volumes: []types.Volume{drifted}}, nil),
+	)
+	mockEC2.EXPECT().CreateVolume(testutil.AnyContext(), testutil.EC2Input(&ec2.CreateVolumeInput{}), testutil.EC2Options()).DoAndReturn(
+		func(_ context.Context, input *ec2.CreateVolumeInput, _ ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error) {
+			assert.True(t, aws.ToBool(input.DryRun), "no volume must be created")
+			return nil, errors.New("Volume iops of 2147483647 is too high; maximum is 16000.")
+		}).AnyTimes()
+
+	disk, err := c.CreateDisk(t.Context(), volumeName, diskOptions)
+	require.NoError(t, err)
+	assert.Equal(t, "vol-existing", disk.VolumeID)
+	assert.Equal(t, "us-east-1b", disk.AvailabilityZone)
+	assert.Equal(t, int32(4000), disk.IOPS)
+
+	_, err = c.CreateDisk(t.Context(), volumeName, diskOptions)
+	require.ErrorIs(t, err, ErrIdempotentParameterMismatch)
+}
+
+func TestDiskRequestMismatch(t *testing.T) {
+	request := &diskRequest{volumeType: VolumeTypeGP3, capacityGiB: 10, encrypted: true, kmsKeyID: "arn:aws:kms:us-east-1:123456789012:key/abcd"}
+	volume := types.Volume{
+		VolumeType: types.VolumeTypeGp3,
+		Size:       aws.Int32(10),
+		Iops:       aws.Int32(3000),
+		Throughput: aws.Int32(125),
+		Encrypted:  aws.Bool(true),
+		KmsKeyId:   aws.String("arn:aws:kms:us-east-1:123456789012:key/abcd"),
+	}
+	assert.Empty(t, request.mismatch(&volume), "IOPS and throughput defaults must not be compared")
+
+	smaller := volume
+	smaller.Size = aws.Int32(5)
+	assert.Equal(t, "size 5 GiB instead of 10 GiB", request.mismatch(&smaller))
+
+	otherKey := volume
+	otherKey.KmsKeyId = aws.String("arn:aws:kms:us-east-1:123456789012:key/efgh")
+	assert.Contains(t, request.mismatch(&otherKey), "KMS key")
+
+	byKeyID := *request
+	byKeyID.kmsKeyID = "abcd"
+	assert.Empty(t, byKeyID.mismatch(&volume))
+
+	unencrypted := volume
+	unencrypted.Encrypted = aws.Bool(false)
+	unencrypted.KmsKeyId = nil
+	assert.Equal(t, "no encryption", request.mismatch(&unencrypted))
+
+	io2 := volume
+	io2.VolumeType = types.VolumeTypeIo2
+	assert.Equal(t, "volume type io2 instead of gp3", request.mismatch(&io2))
+}
+
+func TestClientTokenBase(t *testing.T) {
+	diskOptions := &DiskOptions{IOPS: 3000, Throughput: 125}
+	drifted := &DiskOptions{IOPS: 4000, Throughput: 125}
+
+	c := &cloud{}
+	assert.Equal(t, "pvc-1", c.clientTokenBase("pvc-1", VolumeTypeGP3, 10, defaultZone, "", diskOptions))
+	assert.Equal(t, "pvc-1", c.clientTokenBase("pvc-1", VolumeTypeGP3, 10, defaultZone, "", drifted))
+
+	c.clientTokenStrategy = ClientTokenStrategyRequestHash
+	base := c.clientTokenBase("pvc-1", VolumeTypeGP3, 10, defaultZone, "", diskOptions)
+	assert.True(t, strings.HasPrefix(base, "pvc-1-"))
+	assert.Equal(t, base, c.clientTokenBase("pvc-1", VolumeTypeGP3, 10, defaultZone, "", diskOptions))
+	assert.NotEqual(t, base, c.clientTokenBase("pvc-1", VolumeTypeGP3, 10, defaultZone, "", drifted))
+	assert.NotEqual(t, base, c.clientTokenBase("pvc-1", VolumeTypeGP3, 20, defaultZone, "", diskOptions))
+}
+
+func TestDeleteDisk(t *testing.T) {
+	testCases := []struct {
+		name     string
+		volumeID string
+		expResp  bool
+		expErr   error
+	}{
+		{
+			name:     "success: normal",
+			volumeID: "vol-test-1234",
+			expResp:  true,
+			expErr:   nil,
+		},
+		{
+			name:     "fail: DeleteVolume returned generic error",
+			volumeID: "vol-test-1234",
+			expResp:  false,
+			expErr:   errors.New("DeleteVolume generic error"),
+		},
+		{
+			name:     "fail: DeleteVolume returned not found error",
+			volumeID: "vol-test-1234",
+			expResp:  false,
+			expErr:   errors.New("InvalidVolume.NotFound"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockEC2 := NewMockEC2API(mockCtrl)
+			c := newCloud(mockEC2)
 
 			ctx := t.Context()
 			mockEC2.EXPECT().DeleteVolume(testutil.AnyContext(), gomock.Eq(&ec2.DeleteVolumeInput{VolumeId: &tc.volumeID}), testutil.EC2Options()).Return(&ec2.DeleteVolumeOutput{}, tc.expErr)
@@ -2147,6 +2825,139 @@ func TestDeleteDisk(t *testing.T) {
 	}
 }
 
+func TestDeleteDiskWithBatching(t *testing.T) {
+	volumeID := "vol-test-1234"
+	incorrectStateErr := &smithy.GenericAPIError{Code: "IncorrectState", Message: "The volume 'vol-test-1234' is 'deleting'"}
+	testCases := []struct {
+		name     string
+		mockFunc func(mockEC2 *MockEC2API)
+		expResp  bool
+		expErr   error
+	}{
+		{
+			name: "success: available volume is deleted without describing it",
+			mockFunc: func(mockEC2 *MockEC2API) {
+				mockEC2.EXPECT().DeleteVolume(testutil.AnyContext(), gomock.Eq(&ec2.DeleteVolumeInput{VolumeId: &volumeID}), testutil.EC2Options()).Return(&ec2.DeleteVolumeOutput{}, nil)
+			},
+			expResp: true,
+		},
+		{
+			name: "fail: missing volume is not described",
+			mockFunc: func(mockEC2 *MockEC2API) {
+				mockEC2.EXPECT().DeleteVolume(testutil.AnyContext(), gomock.Eq(&ec2.DeleteVolumeInput{VolumeId: &volumeID}), testutil.EC2Options()).Return(nil, &smithy.GenericAPIError{Code: "InvalidVolume.NotFound"})
+			},
+			expErr: ErrNotFound,
+		},
+		{
+			name: "success: incorrect state of a deleting volume",
+			mockFunc: func(mockEC2 *MockEC2API) {
+				mockEC2.EXPECT().DeleteVolume(testutil.AnyContext(), gomock.Eq(&ec2.DeleteVolumeInput{VolumeId: &volumeID}), testutil.EC2Options()).Return(nil, incorrectStateErr)
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), gomock.Any(), testutil.EC2Options()).Return(&ec2.DescribeVolumesOutput{Volumes: []types.Volume{{VolumeId: aws.String(volumeID), State: types.VolumeStateDeleting}}}, nil)
+			},
+			expResp: true,
+		},
+		{
+			name: "fail: incorrect state of a volume deleted meanwhile",
+			mockFunc: func(mockEC2 *MockEC2API) {
+				mockEC2.EXPECT().DeleteVolume(testutil.AnyContext(), gomock.Eq(&ec2.DeleteVolumeInput{VolumeId: &volumeID}), testutil.EC2Options()).Return(nil, incorrectStateErr)
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), gomock.Any(), testutil.EC2Options()).Return(&ec2.DescribeVolumesOutput{}, nil)
+			},
+			expErr: ErrNotFound,
+		},
+		{
+			name: "fail: incorrect state of an attached volume",
+			mockFunc: func(mockEC2 *MockEC2API) {
+				mockEC2.EXPECT().DeleteVolume(testutil.AnyContext(), gomock.Eq(&ec2.DeleteVolumeInput{VolumeId: &volumeID}), testutil.EC2Options()).Return(nil, incorrectStateErr)
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), gomock.Any(), testutil.EC2Options()).Return(&ec2.DescribeVolumesOutput{Volumes: []types.Volume{{VolumeId: aws.String(volumeID), State: types.VolumeStateInUse}}}, nil)
+			},
+			expErr: incorrectStateErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockEC2 := NewMockEC2API(mockCtrl)
+			c := newCloud(mockEC2).(*cloud)
+			c.bm = newBatcherManager(c.ec2, c.rm, DefaultBatchDelay, DefaultBatchDelay)
+			tc.mockFunc(mockEC2)
+
+			ok, err := c.DeleteDisk(t.Context(), volumeID)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expResp, ok)
+		})
+	}
+}
+
+func TestDeleteSnapshotWithBatching(t *testing.T) {
+	snapshotID := "snap-test-1234"
+	testCases := []struct {
+		name     string
+		mockFunc func(mockEC2 *MockEC2API)
+		expResp  bool
+		expErr   error
+	}{
+		{
+			name: "success: existing snapshot is deleted without describing it",
+			mockFunc: func(mockEC2 *MockEC2API) {
+				mockEC2.EXPECT().DeleteSnapshot(testutil.AnyContext(), gomock.Any(), testutil.EC2Options()).Return(&ec2.DeleteSnapshotOutput{}, nil)
+			},
+			expResp: true,
+		},
+		{
+			name: "fail: missing snapshot is not described",
+			mockFunc: func(mockEC2 *MockEC2API) {
+				mockEC2.EXPECT().DeleteSnapshot(testutil.AnyContext(), gomock.Any(), testutil.EC2Options()).Return(nil, &smithy.GenericAPIError{Code: "InvalidSnapshot.NotFound"})
+			},
+			expErr: ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockEC2 := NewMockEC2API(mockCtrl)
+			c := newCloud(mockEC2).(*cloud)
+			c.bm = newBatcherManager(c.ec2, c.rm, DefaultBatchDelay, DefaultBatchDelay)
+			tc.mockFunc(mockEC2)
+
+			ok, err := c.DeleteSnapshot(t.Context(), snapshotID)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expResp, ok)
+		})
+	}
+}
+
+func TestDeleteRetryerIsShared(t *testing.T) {
+	rm := newRetryManager()
+	assert.Same(t, rm.deleteVolumeRetryer, rm.deleteSnapshotRetryer)
+	assert.NotSame(t, rm.createVolumeRetryer, rm.deleteVolumeRetryer)
+}
+
+func TestBatchRetryBudgetIsShared(t *testing.T) {
+	rm := newRetryManager()
+	assert.NotSame(t, rm.batchDescribeVolumesRetryer, rm.batchDescribeInstancesRetryer)
+
+	throttleErr := &smithy.GenericAPIError{Code: "RequestLimitExceeded"}
+	for range batchRetryBudget / retry.DefaultRetryCost {
+		_, err := rm.batchDescribeVolumesRetryer.(aws.RetryerV2).GetRetryToken(t.Context(), throttleErr)
+		require.NoError(t, err)
+	}
+	_, err := rm.batchDescribeVolumesRetryer.(aws.RetryerV2).GetRetryToken(t.Context(), throttleErr)
+	require.Error(t, err, "expected retry budget to be exhausted")
+
+	_, err = rm.batchDescribeInstancesRetryer.(aws.RetryerV2).GetRetryToken(t.Context(), throttleErr)
+	require.NoError(t, err, "expected other APIs to keep their own retry budget")
+}
+
 func TestAttachDisk(t *testing.T) {
 	blockDeviceInUseErr := &smithy.GenericAPIError{
 		Code:    "InvalidParameterValue",
@@ -2575,6 +3386,104 @@ func TestAttachDisk(t *testing.T) {
 	}
 }
 
+func TestAttachDiskRestoresAttachingDevices(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	mockEC2.EXPECT().DescribeInstanceTypes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeInstanceTypesInput{})).Return(&ec2.DescribeInstanceTypesOutput{
+		InstanceTypes: []types.InstanceTypeInfo{{EbsInfo: &types.EbsInfo{}}},
+	}, nil).AnyTimes()
+	c := newCloud(mockEC2).(*cloud)
+	c.restoreDevicesOnce = sync.Once{}
+
+	// The previous controller was attaching another volume to the node with the first device name
+	restoreRequest := &ec2.DescribeVolumesInput{
+		Filters: []types.Filter{{Name: aws.String("attachment.status"), Values: []string{string(types.VolumeAttachmentStateAttaching)}}},
+	}
+	path := "/dev/xvdab"
+	gomock.InOrder(
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), gomock.Eq(restoreRequest), testutil.EC2Options()).Return(createDescribeVolumesOutput([]*string{aws.String("vol-attaching")}, defaultNodeID, defaultPath, "attaching"), nil),
+		mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), gomock.Eq(createInstanceRequest(defaultNodeID)), testutil.EC2Options()).Return(newDescribeInstancesOutput(defaultNodeID), nil),
+		mockEC2.EXPECT().AttachVolume(testutil.AnyContext(), gomock.Eq(createAttachRequest(defaultVolumeID, defaultNodeID, path)), testutil.EC2Options()).Return(&ec2.AttachVolumeOutput{}, nil),
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), createVolumeRequest(defaultVolumeID), testutil.EC2Options()).Return(createDescribeVolumesOutput([]*string{aws.String(defaultVolumeID)}, defaultNodeID, path, "attached"), nil),
+		// Device names are only restored once
+		mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), gomock.Eq(createInstanceRequest(defaultNodeID)), testutil.EC2Options()).Return(nil, errors.New("DescribeInstances error")),
+	)
+
+	devicePath, err := c.AttachDisk(t.Context(), defaultVolumeID, defaultNodeID)
+	require.NoError(t, err)
+	assert.Equal(t, path, devicePath)
+
+	devicePath, err = c.AttachDisk(t.Context(), defaultVolumeID, defaultNodeID)
+	require.Error(t, err)
+	assert.Empty(t, devicePath)
+}
+
+func TestAttachmentHistory(t *testing.T) {
+	assert.Nil(t, newAttachmentHistory(0, false))
+
+	h := newAttachmentHistory(2, false)
+	ctx := util.WithCorrelationIDs(t.Context(), "correlation-1")
+	metadata := middleware.Metadata{}
+	awsmiddleware.SetRequestIDMetadata(&metadata, "request-1")
+	h.record(ctx, "vol-1", AttachmentEvent{Operation: "AttachVolume", NodeID: "i-1", State: AttachmentStateRequested}, &metadata, nil)
+	h.record(ctx, "vol-1", AttachmentEvent{Operation: "AttachVolume", NodeID: "i-1", State: AttachmentStateAttached}, nil, nil)
+	h.record(ctx, "vol-1", AttachmentEvent{Operation: "DetachVolume", NodeID: "i-1", State: AttachmentStateFailed}, nil, &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusBadRequest}},
+			Err:      errors.New("DetachVolume error"),
+		},
+		RequestID: "request-2",
+	})
+	h.record(ctx, "vol-2", AttachmentEvent{Operation: "AttachVolume", NodeID: "i-2", State: AttachmentStateRequested}, nil, nil)
+
+	// Only the last events of each volume are kept
+	events := h.events("vol-1")["vol-1"]
+	require.Len(t, events, 2)
+	assert.Equal(t, AttachmentStateAttached, events[0].State)
+	assert.Equal(t, []string{"correlation-1"}, events[0].CorrelationIDs)
+	assert.Equal(t, AttachmentStateFailed, events[1].State)
+	assert.Equal(t, "request-2", events[1].RequestID)
+	assert.NotEmpty(t, events[1].Error)
+	assert.Len(t, h.events(""), 2)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, AttachmentHistoryPath+"?volumeID=vol-2", nil))
+	served := map[string][]AttachmentEvent{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &served))
+	require.Len(t, served["vol-2"], 1)
+	assert.Equal(t, "i-2", served["vol-2"][0].NodeID)
+	assert.NotContains(t, served, "vol-1")
+
+	// Volumes without events in the last day are forgotten
+	h.lastPrune = time.Now().Add(-2 * time.Hour)
+	h.volumes["vol-2"][0].Time = time.Now().Add(-25 * time.Hour)
+	h.record(ctx, "vol-1", AttachmentEvent{Operation: "DetachVolume", NodeID: "i-1", State: AttachmentStateDetached}, nil, nil)
+	assert.NotContains(t, h.events(""), "vol-2")
+}
+
+func TestDetachDiskRecordsAttachmentHistory(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2).(*cloud)
+	c.attachmentHistory = newAttachmentHistory(10, false)
+
+	metadata := middleware.Metadata{}
+	awsmiddleware.SetRequestIDMetadata(&metadata, "request-1")
+	gomock.InOrder(
+		mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), createInstanceRequest(defaultNodeID), testutil.EC2Options()).Return(newDescribeInstancesOutput(defaultNodeID, defaultVolumeID), nil),
+		mockEC2.EXPECT().DetachVolume(testutil.AnyContext(), createDetachRequest(defaultVolumeID, defaultNodeID), testutil.EC2Options()).Return(&ec2.DetachVolumeOutput{Device: aws.String(defaultPath), ResultMetadata: metadata}, nil),
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), createVolumeRequest(defaultVolumeID), testutil.EC2Options()).Return(createDescribeVolumesOutput([]*string{aws.String(defaultVolumeID)}, defaultNodeID, "", "detached"), nil),
+	)
+	require.NoError(t, c.DetachDisk(t.Context(), defaultVolumeID, defaultNodeID))
+
+	events := c.attachmentHistory.events(defaultVolumeID)[defaultVolumeID]
+	require.Len(t, events, 2)
+	assert.Equal(t, AttachmentEvent{Operation: "DetachVolume", NodeID: defaultNodeID, Device: defaultPath, State: AttachmentStateRequested, RequestID: "request-1"}, AttachmentEvent{
+		Operation: events[0].Operation, NodeID: events[0].NodeID, Device: events[0].Device, State: events[0].State, RequestID: events[0].RequestID,
+	})
+	assert.Equal(t, AttachmentStateDetached, events[1].State)
+}
+
 func TestDetachDisk(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -2924,6 +3833,7 @@ func TestGetDiskByID(t *testing.T) {
 		availabilityZone string
 		outpostArn       string
 		attachments      []types.VolumeAttachment
+		tags             []types.Tag
 		expDisk          *Disk
 		expErr           error
 	}{
@@ -2970,6 +3880,18 @@ func TestGetDiskByID(t *testing.T) {
 			},
 			expErr: nil,
 		},
+		{
+			name:             "success: soft-deleted volume",
+			volumeID:         "vol-test-1234",
+			availabilityZone: expZone,
+			tags:             []types.Tag{{Key: aws.String(PendingDeletionTagKey), Value: aws.String("2025-01-02T03:04:05Z")}},
+			expDisk: &Disk{
+				VolumeID:          "vol-test-1234",
+				AvailabilityZone:  expZone,
+				PendingDeletionAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+			expErr: nil,
+		},
 		{
 			name:     "fail: DescribeVolumes returned generic error",
 			volumeID: "vol-test-1234",
@@ -2993,6 +3915,7 @@ func TestGetDiskByID(t *testing.T) {
 							AvailabilityZone: aws.String(tc.availabilityZone),
 							OutpostArn:       aws.String(tc.outpostArn),
 							Attachments:      tc.attachments,
+							Tags:             tc.tags,
 						},
 					},
 				},
@@ -3023,6 +3946,9 @@ func TestGetDiskByID(t *testing.T) {
 				if len(disk.Attachments) != len(tc.expDisk.Attachments) {
 					t.Fatalf("GetDiskByID() failed: expected attachments length %d, got %d", len(tc.expDisk.Attachments), len(disk.Attachments))
 				}
+				if !disk.PendingDeletionAt.Equal(tc.expDisk.PendingDeletionAt) {
+					t.Fatalf("GetDiskByID() failed: expected pending deletion at %v, got %v", tc.expDisk.PendingDeletionAt, disk.PendingDeletionAt)
+				}
 			}
 
 			mockCtrl.Finish()
@@ -3066,6 +3992,20 @@ func TestBuildHyperPodClusterArn(t *testing.T) {
 			accountID:   "123456789012",
 			expectedArn: "arn:aws:sagemaker:test-region:123456789012:cluster/abc123",
 		},
+		{
+			name:        "success: GovCloud",
+			nodeID:      "hyperpod-abc123-i-1234567890abcdef0",
+			region:      "us-gov-west-1",
+			accountID:   "123456789012",
+			expectedArn: "arn:aws-us-gov:sagemaker:us-gov-west-1:123456789012:cluster/abc123",
+		},
+		{
+			name:        "success: China",
+			nodeID:      "hyperpod-abc123-i-1234567890abcdef0",
+			region:      "cn-north-1",
+			accountID:   "123456789012",
+			expectedArn: "arn:aws-cn:sagemaker:cn-north-1:123456789012:cluster/abc123",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -3241,34 +4181,238 @@ func TestCreateSnapshot(t *testing.T) {
 	}
 }
 
-func TestEnableFastSnapshotRestores(t *testing.T) {
+func TestCreateSnapshotQuota(t *testing.T) {
+	const volumeID = "vol-test"
+	snapshots := func(n int) []types.Snapshot { return make([]types.Snapshot, n) }
 	testCases := []struct {
-		name              string
-		snapshotID        string
-		availabilityZones []string
-		expOutput         *ec2.EnableFastSnapshotRestoresOutput
-		expErr            error
+		name            string
+		perRegionQuota  int
+		pendingCount    int
+		pendingErr      error
+		volumeType      types.VolumeType
+		regionPages     [][]types.Snapshot
+		expCreated      bool
+		expErrContained string
 	}{
 		{
-			name:              "success: normal",
-			snapshotID:        "snap-test-id",
-			availabilityZones: []string{"us-west-2a", "us-west-2b"},
-			expOutput: &ec2.EnableFastSnapshotRestoresOutput{
-				Successful: []types.EnableFastSnapshotRestoreSuccessItem{{
-					AvailabilityZone: aws.String("us-west-2a,us-west-2b"),
-					SnapshotId:       aws.String("snap-test-id")}},
-				Unsuccessful: []types.EnableFastSnapshotRestoreErrorItem{},
-			},
-			expErr: nil,
+			name:           "success: under limits",
+			perRegionQuota: 10,
+			pendingCount:   2,
+			volumeType:     types.VolumeTypeGp3,
+			regionPages:    [][]types.Snapshot{snapshots(5)},
+			expCreated:     true,
 		},
 		{
-			name:              "fail: unsuccessful response",
-			snapshotID:        "snap-test-id",
-			availabilityZones: []string{"us-west-2a", "invalid-zone"},
-			expOutput: &ec2.EnableFastSnapshotRestoresOutput{
-				Unsuccessful: []types.EnableFastSnapshotRestoreErrorItem{{
-					SnapshotId: aws.String("snap-test-id"),
-					FastSnapshotRestoreStateErrors: []types.EnableFastSnapshotRestoreStateErrorItem{
+			name:         "success: region quota not checked",
+			pendingCount: 0,
+			expCreated:   true,
+		},
+		{
+			name:       "success: snapshots cannot be counted",
+			pendingErr: errors.New("UnauthorizedOperation"),
+			expCreated: true,
+		},
+		{
+			name:            "fail: pending snapshots per volume limit",
+			pendingCount:    5,
+			volumeType:      types.VolumeTypeGp3,
+			expErrContained: "volume vol-test already has 5 pending snapshots, the EC2 limit of pending snapshots per volume is 5",
+		},
+		{
+			name:            "fail: pending snapshots per st1 volume limit",
+			pendingCount:    1,
+			volumeType:      types.VolumeTypeSt1,
+			expErrContained: "the EC2 limit of pending snapshots per volume is 1",
+		},
+		{
+			name:            "fail: snapshots per region quota",
+			perRegionQuota:  3,
+			regionPages:     [][]types.Snapshot{snapshots(2), snapshots(1)},
+			expErrContained: "account already has 3 snapshots in region test-region, the Snapshots per Region quota is 3",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockEC2 := NewMockEC2API(mockCtrl)
+			c := newCloud(mockEC2).(*cloud)
+			c.snapshotQuota = newSnapshotQuota(tc.perRegionQuota)
+
+			mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{}), testutil.EC2Options()).DoAndReturn(
+				func(_ context.Context, input *ec2.DescribeSnapshotsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error) {
+					if len(input.Filters) > 0 {
+						return &ec2.DescribeSnapshotsOutput{Snapshots: snapshots(tc.pendingCount)}, tc.pendingErr
+					}
+					page := 0
+					if input.NextToken != nil {
+						page, _ = strconv.Atoi(*input.NextToken)
+					}
+					output := &ec2.DescribeSnapshotsOutput{Snapshots: tc.regionPages[page]}
+					if page+1 < len(tc.regionPages) {
+						output.NextToken = aws.String(strconv.Itoa(page + 1))
+					}
+					return output, nil
+				}).AnyTimes()
+			if tc.volumeType != "" {
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(&ec2.DescribeVolumesOutput{
+					Volumes: []types.Volume{{VolumeId: aws.String(volumeID), VolumeType: tc.volumeType}},
+				}, nil)
+			}
+			if tc.expCreated {
+				mockEC2.EXPECT().CreateSnapshot(testutil.AnyContext(), testutil.EC2Input(&ec2.CreateSnapshotInput{}), testutil.EC2Options()).Return(&ec2.CreateSnapshotOutput{
+					SnapshotId: aws.String("snap-test"),
+					VolumeId:   aws.String(volumeID),
+					VolumeSize: aws.Int32(10),
+				}, nil)
+			}
+
+			_, err := c.CreateSnapshot(t.Context(), volumeID, &SnapshotOptions{})
+			if tc.expErrContained != "" {
+				require.ErrorIs(t, err, ErrLimitExceeded)
+				assert.Contains(t, err.Error(), tc.expErrContained)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCreateSnapshotQuotaCountsCreatedSnapshots(t *testing.T) {
+	const volumeID = "vol-test"
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2).(*cloud)
+	c.snapshotQuota = newSnapshotQuota(0)
+
+	// The pending snapshot created by the first call is counted without describing snapshots again
+	mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{}), testutil.EC2Options()).Return(&ec2.DescribeSnapshotsOutput{}, nil).Times(1)
+	mockEC2.EXPECT().CreateSnapshot(testutil.AnyContext(), testutil.EC2Input(&ec2.CreateSnapshotInput{}), testutil.EC2Options()).Return(&ec2.CreateSnapshotOutput{
+		SnapshotId: aws.String("snap-test"),
+		VolumeId:   aws.String(volumeID),
+		VolumeSize: aws.Int32(10),
+		State:      types.SnapshotStatePending,
+	}, nil).Times(1)
+	mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(&ec2.DescribeVolumesOutput{
+		Volumes: []types.Volume{{VolumeId: aws.String(volumeID), VolumeType: types.VolumeTypeSc1}},
+	}, nil).Times(1)
+
+	_, err := c.CreateSnapshot(t.Context(), volumeID, &SnapshotOptions{})
+	require.NoError(t, err)
+	_, err = c.CreateSnapshot(t.Context(), volumeID, &SnapshotOptions{})
+	require.ErrorIs(t, err, ErrLimitExceeded)
+}
+
+func TestGetStorageUsage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2)
+
+	// The volumes of all pages are counted, and the usage is cached
+	gomock.InOrder(
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(&ec2.DescribeVolumesOutput{
+			Volumes: []types.Volume{
+				{VolumeId: aws.String("vol-1"), VolumeType: types.VolumeTypeGp3, Size: aws.Int32(100)},
+				{VolumeId: aws.String("vol-2"), VolumeType: types.VolumeTypeIo2, Size: aws.Int32(500)},
+			},
+			NextToken: aws.String("token"),
+		}, nil),
+		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(&ec2.DescribeVolumesOutput{
+			Volumes: []types.Volume{
+				{VolumeId: aws.String("vol-3"), VolumeType: types.VolumeTypeGp3, Size: aws.Int32(50)},
+			},
+		}, nil),
+	)
+
+	expected := &StorageUsage{SizeGiB: map[string]int64{VolumeTypeGP3: 150, VolumeTypeIO2: 500}, Volumes: 3}
+	usage, err := c.GetStorageUsage(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, expected, usage)
+
+	usage.SizeGiB[VolumeTypeGP3] = 0
+	usage, err = c.GetStorageUsage(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, expected, usage)
+}
+
+func TestGetStorageUsageError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2)
+
+	// Errors are not cached
+	mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(nil, errors.New("DescribeVolumes error")).Times(2)
+	_, err := c.GetStorageUsage(t.Context())
+	require.Error(t, err)
+	_, err = c.GetStorageUsage(t.Context())
+	require.Error(t, err)
+}
+
+func TestGetZoneCapacity(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2).(*cloud)
+	now := time.Now()
+	c.capacity.now = func() time.Time { return now }
+
+	mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(&ec2.DescribeVolumesOutput{
+		Volumes: []types.Volume{
+			{VolumeId: aws.String("vol-1"), VolumeType: types.VolumeTypeGp3, Size: aws.Int32(100), AvailabilityZone: aws.String("us-east-1a")},
+			{VolumeId: aws.String("vol-2"), VolumeType: types.VolumeTypeGp3, Size: aws.Int32(50), AvailabilityZone: aws.String("us-east-1a")},
+			{VolumeId: aws.String("vol-3"), VolumeType: types.VolumeTypeIo2, Size: aws.Int32(500), AvailabilityZone: aws.String("us-east-1b")},
+		},
+	}, nil)
+
+	// Created volumes and capacity failures count until the next listing and for capacityExhaustionTTL
+	c.capacity.recordCreated("us-east-1b", VolumeTypeGP3, 20)
+	c.capacity.recordExhausted("us-east-1c", VolumeTypeGP3)
+	c.capacity.recordExhausted("us-east-1a", VolumeTypeIO2)
+	capacities, err := c.GetZoneCapacity(t.Context(), VolumeTypeGP3)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ZoneCapacity{
+		"us-east-1a": {UsedGiB: 150},
+		"us-east-1c": {Exhausted: true},
+	}, capacities, "volumes created before the listing are counted by the listing")
+
+	c.capacity.recordCreated("us-east-1b", VolumeTypeGP3, 20)
+	now = now.Add(capacityExhaustionTTL)
+	capacities, err = c.GetZoneCapacity(t.Context(), VolumeTypeGP3)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ZoneCapacity{
+		"us-east-1a": {UsedGiB: 150},
+		"us-east-1b": {UsedGiB: 20},
+	}, capacities)
+	assert.Empty(t, c.capacity.exhausted)
+}
+
+func TestEnableFastSnapshotRestores(t *testing.T) {
+	testCases := []struct {
+		name              string
+		snapshotID        string
+		availabilityZones []string
+		expOutput         *ec2.EnableFastSnapshotRestoresOutput
+		expErr            error
+	}{
+		{
+			name:              "success: normal",
+			snapshotID:        "snap-test-id",
+			availabilityZones: []string{"us-west-2a", "us-west-2b"},
+			expOutput: &ec2.EnableFastSnapshotRestoresOutput{
+				Successful: []types.EnableFastSnapshotRestoreSuccessItem{{
+					AvailabilityZone: aws.String("us-west-2a,us-west-2b"),
+					SnapshotId:       aws.String("snap-test-id")}},
+				Unsuccessful: []types.EnableFastSnapshotRestoreErrorItem{},
+			},
+			expErr: nil,
+		},
+		{
+			name:              "fail: unsuccessful response",
+			snapshotID:        "snap-test-id",
+			availabilityZones: []string{"us-west-2a", "invalid-zone"},
+			expOutput: &ec2.EnableFastSnapshotRestoresOutput{
+				Unsuccessful: []types.EnableFastSnapshotRestoreErrorItem{{
+					SnapshotId: aws.String("snap-test-id"),
+					FastSnapshotRestoreStateErrors: []types.EnableFastSnapshotRestoreStateErrorItem{
 						{AvailabilityZone: aws.String("us-west-2a,invalid-zone"),
 							Error: &types.EnableFastSnapshotRestoreStateError{
 								Message: aws.String("failed to create fast snapshot restore")}},
@@ -3710,67 +4854,609 @@ func TestResizeOrModifyDisk(t *testing.T) {
 			mockEC2 := NewMockEC2API(mockCtrl)
 			c := newCloud(mockEC2)
 
-			ctx := t.Context()
-			if tc.existingVolume != nil || tc.existingVolumeError != nil {
-				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(
-					&ec2.DescribeVolumesOutput{
-						Volumes: []types.Volume{
-							*tc.existingVolume,
-						},
-					}, tc.existingVolumeError)
+			ctx := t.Context()
+			if tc.existingVolume != nil || tc.existingVolumeError != nil {
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(
+					&ec2.DescribeVolumesOutput{
+						Volumes: []types.Volume{
+							*tc.existingVolume,
+						},
+					}, tc.existingVolumeError)
+
+				if tc.shouldCallDescribe {
+					newVolume := tc.existingVolume
+					if tc.reqSizeGiB != 0 {
+						newVolume.Size = aws.Int32(tc.reqSizeGiB)
+					}
+					if tc.modifyDiskOptions != nil {
+						if tc.modifyDiskOptions.IOPS != 0 {
+							newVolume.Iops = aws.Int32(tc.modifyDiskOptions.IOPS)
+						}
+						if tc.modifyDiskOptions.Throughput != 0 {
+							newVolume.Throughput = aws.Int32(tc.modifyDiskOptions.Throughput)
+						}
+						if tc.modifyDiskOptions.VolumeType != "" {
+							newVolume.VolumeType = types.VolumeType(tc.modifyDiskOptions.VolumeType)
+						}
+					}
+					mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(
+						&ec2.DescribeVolumesOutput{
+							Volumes: []types.Volume{
+								*newVolume,
+							},
+						}, tc.existingVolumeError)
+				}
+			}
+
+			mockEC2.EXPECT().DescribeTags(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeTagsInput{})).Return(&ec2.DescribeTagsOutput{}, nil).AnyTimes()
+
+			// Mock CreateVolume for getVolumeLimits dry-run calls
+			mockEC2.EXPECT().CreateVolume(testutil.AnyContext(), testutil.EC2Input(&ec2.CreateVolumeInput{}), testutil.EC2Options()).Return(nil, &smithy.GenericAPIError{Code: "DryRunOperation"}).AnyTimes()
+
+			if tc.modifiedVolume != nil || tc.modifiedVolumeError != nil {
+				mockEC2.EXPECT().ModifyVolume(testutil.AnyContext(), testutil.EC2Input(&ec2.ModifyVolumeInput{}), testutil.EC2Options()).Return(tc.modifiedVolume, tc.modifiedVolumeError).MinTimes(1)
+			}
+			if tc.descModVolume != nil {
+				mockEC2.EXPECT().DescribeVolumesModifications(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesModificationsInput{}), testutil.EC2Options()).Return(tc.descModVolume, nil).MinTimes(1)
+			} else {
+				emptyOutput := &ec2.DescribeVolumesModificationsOutput{}
+				mockEC2.EXPECT().DescribeVolumesModifications(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesModificationsInput{}), testutil.EC2Options()).Return(emptyOutput, nil).AnyTimes()
+			}
+
+			newSize, err := c.ResizeOrModifyDisk(ctx, tc.volumeID, util.GiBToBytes(tc.reqSizeGiB), tc.modifyDiskOptions)
+			switch {
+			case errors.Is(tc.expErr, ErrInvalidArgument):
+				require.ErrorIs(t, err, ErrInvalidArgument, "ResizeOrModifyDisk() should return ErrInvalidArgument")
+			case tc.expErr != nil:
+				require.Error(t, err, "ResizeOrModifyDisk() should return error")
+			default:
+				require.NoError(t, err, "ResizeOrModifyDisk() should not return error")
+				assert.Equal(t, tc.reqSizeGiB, newSize, "ResizeOrModifyDisk() returned unexpected capacity")
+			}
+
+			mockCtrl.Finish()
+		})
+	}
+}
+
+func TestListPendingDeletionDisks(t *testing.T) {
+	deleteAfter := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	volumes := []types.Volume{
+		{
+			VolumeId: aws.String("vol-pending"),
+			Tags:     []types.Tag{{Key: aws.String(PendingDeletionTagKey), Value: aws.String(deleteAfter.Format(time.RFC3339))}},
+		},
+		{
+			VolumeId: aws.String("vol-invalid"),
+			Tags:     []types.Tag{{Key: aws.String(PendingDeletionTagKey), Value: aws.String("soon")}},
+		},
+	}
+
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2)
+	mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).DoAndReturn(
+		func(_ context.Context, input *ec2.DescribeVolumesInput, _ ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+			require.Len(t, input.Filters, 4)
+			assert.Equal(t, []string{PendingDeletionTagKey}, input.Filters[0].Values)
+			assert.Equal(t, "tag:"+AwsEbsDriverTagKey, *input.Filters[2].Name)
+			assert.Equal(t, "tag:kubernetes.io/cluster/test", *input.Filters[3].Name)
+			assert.Equal(t, []string{"owned"}, input.Filters[3].Values)
+			return &ec2.DescribeVolumesOutput{Volumes: volumes}, nil
+		})
+
+	pending, err := c.ListPendingDeletionDisks(t.Context(), map[string]string{"kubernetes.io/cluster/test": "owned"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{"vol-pending": deleteAfter}, pending)
+}
+
+func TestListDisks(t *testing.T) {
+	testCases := []struct {
+		name        string
+		volumeIDs   []string
+		tags        map[string]string
+		volumes     []types.Volume
+		describeErr error
+		expDisks    []*Disk
+		expErr      error
+	}{
+		{
+			name:      "success: by volume IDs",
+			volumeIDs: []string{"vol-test"},
+			volumes: []types.Volume{{
+				VolumeId:         aws.String("vol-test"),
+				Size:             aws.Int32(10),
+				AvailabilityZone: aws.String(defaultZone),
+			}},
+			expDisks: []*Disk{{VolumeID: "vol-test", CapacityGiB: 10, AvailabilityZone: defaultZone}},
+		},
+		{
+			name: "success: by tags",
+			tags: map[string]string{"team": "storage"},
+			volumes: []types.Volume{{
+				VolumeId:         aws.String("vol-test"),
+				Size:             aws.Int32(10),
+				AvailabilityZone: aws.String(defaultZone),
+			}},
+			expDisks: []*Disk{{VolumeID: "vol-test", CapacityGiB: 10, AvailabilityZone: defaultZone}},
+		},
+		{
+			name: "success: soft-deleted volume",
+			tags: map[string]string{"team": "storage"},
+			volumes: []types.Volume{{
+				VolumeId:         aws.String("vol-test"),
+				Size:             aws.Int32(10),
+				AvailabilityZone: aws.String(defaultZone),
+				Tags:             []types.Tag{{Key: aws.String(PendingDeletionTagKey), Value: aws.String("2025-01-02T03:04:05Z")}},
+			}},
+			expDisks: []*Disk{{VolumeID: "vol-test", CapacityGiB: 10, AvailabilityZone: defaultZone, PendingDeletionAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}},
+		},
+		{
+			name:   "fail: no volume IDs or tags",
+			expErr: ErrInvalidRequest,
+		},
+		{
+			name:        "fail: volume not found",
+			volumeIDs:   []string{"vol-test"},
+			describeErr: &smithy.GenericAPIError{Code: "InvalidVolume.NotFound"},
+			expErr:      ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockEC2 := NewMockEC2API(mockCtrl)
+			c := newCloud(mockEC2)
+			if tc.expErr != ErrInvalidRequest {
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).DoAndReturn(
+					func(_ context.Context, input *ec2.DescribeVolumesInput, _ ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+						assert.Equal(t, tc.volumeIDs, input.VolumeIds)
+						assert.Len(t, input.Filters, len(tc.tags))
+						return &ec2.DescribeVolumesOutput{Volumes: tc.volumes}, tc.describeErr
+					})
+			}
+
+			disks, err := c.ListDisks(t.Context(), tc.volumeIDs, tc.tags)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expDisks, disks)
+		})
+	}
+}
+
+func TestListDisksPage(t *testing.T) {
+	volume := types.Volume{
+		VolumeId:         aws.String("vol-test"),
+		Size:             aws.Int32(10),
+		AvailabilityZone: aws.String(defaultZone),
+		Attachments: []types.VolumeAttachment{
+			{InstanceId: aws.String("i-attached"), State: types.VolumeAttachmentStateAttached},
+			{InstanceId: aws.String("i-detaching"), State: types.VolumeAttachmentStateDetaching},
+		},
+	}
+	disk := &Disk{VolumeID: "vol-test", CapacityGiB: 10, AvailabilityZone: defaultZone, Attachments: []string{"i-attached"}}
+	testCases := []struct {
+		name          string
+		maxResults    int32
+		nextToken     string
+		expMaxResults *int32
+		responseToken *string
+		describeErr   error
+		expResponse   *ListDisksResponse
+		expErr        error
+	}{
+		{
+			name:          "success: page",
+			maxResults:    10,
+			nextToken:     "token-1",
+			expMaxResults: aws.Int32(10),
+			responseToken: aws.String("token-2"),
+			expResponse:   &ListDisksResponse{Disks: []*Disk{disk}, NextToken: "token-2"},
+		},
+		{
+			name:          "success: max results above the EC2 limit",
+			maxResults:    1000,
+			expMaxResults: aws.Int32(500),
+			expResponse:   &ListDisksResponse{Disks: []*Disk{disk}},
+		},
+		{
+			name:        "success: all volumes",
+			expResponse: &ListDisksResponse{Disks: []*Disk{disk}},
+		},
+		{
+			name:       "fail: max results below the EC2 limit",
+			maxResults: 4,
+			expErr:     ErrInvalidMaxResults,
+		},
+		{
+			name:          "fail: invalid token",
+			maxResults:    10,
+			nextToken:     "invalid-token",
+			expMaxResults: aws.Int32(10),
+			describeErr:   &smithy.GenericAPIError{Code: "InvalidPaginationToken"},
+			expErr:        ErrInvalidNextToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockEC2 := NewMockEC2API(mockCtrl)
+			c := newCloud(mockEC2)
+			if tc.expErr != ErrInvalidMaxResults {
+				mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).DoAndReturn(
+					func(_ context.Context, input *ec2.DescribeVolumesInput, _ ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+						assert.Equal(t, tc.expMaxResults, input.MaxResults)
+						if tc.nextToken != "" {
+							assert.Equal(t, tc.nextToken, aws.ToString(input.NextToken))
+						}
+						assert.Equal(t, []types.Filter{{Name: aws.String("tag:team"), Values: []string{"storage"}}}, input.Filters)
+						if tc.describeErr != nil {
+							return nil, tc.describeErr
+						}
+						return &ec2.DescribeVolumesOutput{Volumes: []types.Volume{volume}, NextToken: tc.responseToken}, nil
+					})
+			}
+
+			response, err := c.ListDisksPage(t.Context(), map[string]string{"team": "storage"}, tc.maxResults, tc.nextToken)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expResponse, response)
+		})
+	}
+}
+
+func TestCheckMultiAttachSupport(t *testing.T) {
+	testCases := []struct {
+		name         string
+		instanceType types.InstanceType
+		expErr       error
+	}{
+		{
+			name:         "success: Nitro instance",
+			instanceType: types.InstanceTypeM5Large,
+		},
+		{
+			name:         "fail: non-Nitro instance",
+			instanceType: types.InstanceTypeM4Large,
+			expErr:       ErrMultiAttachNotSupported,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockEC2 := NewMockEC2API(mockCtrl)
+			c := newCloud(mockEC2)
+
+			output := newDescribeInstancesOutput(defaultNodeID)
+			output.Reservations[0].Instances[0].InstanceType = tc.instanceType
+			mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeInstancesInput{}), testutil.EC2Options()).Return(output, nil)
+
+			err := c.CheckMultiAttachSupport(t.Context(), defaultNodeID)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckBlockExpressSupport(t *testing.T) {
+	testCases := []struct {
+		name         string
+		instanceType types.InstanceType
+		expErr       error
+	}{
+		{
+			name:         "success: Nitro instance",
+			instanceType: types.InstanceTypeR5bLarge,
+		},
+		{
+			name:         "fail: non-Nitro instance",
+			instanceType: types.InstanceTypeR4Large,
+			expErr:       ErrBlockExpressNotSupported,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockEC2 := NewMockEC2API(mockCtrl)
+			c := newCloud(mockEC2)
+
+			output := newDescribeInstancesOutput(defaultNodeID)
+			output.Reservations[0].Instances[0].InstanceType = tc.instanceType
+			mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeInstancesInput{}), testutil.EC2Options()).Return(output, nil)
+
+			err := c.CheckBlockExpressSupport(t.Context(), defaultNodeID)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckAttachmentZone(t *testing.T) {
+	testCases := []struct {
+		name         string
+		instanceZone string
+		expErr       error
+	}{
+		{
+			name:         "success: same zone",
+			instanceZone: defaultZone,
+		},
+		{
+			name:         "fail: different zone",
+			instanceZone: "test-az-2",
+			expErr:       ErrZoneMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockEC2 := NewMockEC2API(mockCtrl)
+			c := newCloud(mockEC2)
+
+			volumeOutput := &ec2.DescribeVolumesOutput{Volumes: []types.Volume{{VolumeId: aws.String(defaultVolumeID), AvailabilityZone: aws.String(defaultZone)}}}
+			mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(volumeOutput, nil)
+			instanceOutput := newDescribeInstancesOutput(defaultNodeID)
+			instanceOutput.Reservations[0].Instances[0].Placement = &types.Placement{AvailabilityZone: aws.String(tc.instanceZone)}
+			mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeInstancesInput{}), testutil.EC2Options()).Return(instanceOutput, nil)
 
-				if tc.shouldCallDescribe {
-					newVolume := tc.existingVolume
-					if tc.reqSizeGiB != 0 {
-						newVolume.Size = aws.Int32(tc.reqSizeGiB)
-					}
-					if tc.modifyDiskOptions != nil {
-						if tc.modifyDiskOptions.IOPS != 0 {
-							newVolume.Iops = aws.Int32(tc.modifyDiskOptions.IOPS)
-						}
-						if tc.modifyDiskOptions.Throughput != 0 {
-							newVolume.Throughput = aws.Int32(tc.modifyDiskOptions.Throughput)
-						}
-						if tc.modifyDiskOptions.VolumeType != "" {
-							newVolume.VolumeType = types.VolumeType(tc.modifyDiskOptions.VolumeType)
-						}
-					}
-					mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{})).Return(
-						&ec2.DescribeVolumesOutput{
-							Volumes: []types.Volume{
-								*newVolume,
-							},
-						}, tc.existingVolumeError)
-				}
+			err := c.CheckAttachmentZone(t.Context(), defaultVolumeID, defaultNodeID)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
 			}
+			require.NoError(t, err)
+		})
+	}
+}
 
-			mockEC2.EXPECT().DescribeTags(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeTagsInput{})).Return(&ec2.DescribeTagsOutput{}, nil).AnyTimes()
+func TestNewAPIBudget(t *testing.T) {
+	assert.Nil(t, newAPIBudget(0, map[string]int{"database": 10}))
+
+	b := newAPIBudget(12, map[string]int{"database": 10, "batch": 1})
+	require.NotNil(t, b)
+	class, l := b.limiter("database")
+	assert.Equal(t, "database", class)
+	assert.InDelta(t, 10, float64(l.Limit()), 0.001)
+	assert.Equal(t, 10, l.Burst())
+	class, l = b.limiter("batch")
+	assert.Equal(t, "batch", class)
+	assert.InDelta(t, 1, float64(l.Limit()), 0.001)
+	// Unknown classes share the default class, which has a weight of 1 unless set
+	class, l = b.limiter("unknown")
+	assert.Equal(t, DefaultAPIBudgetClass, class)
+	assert.InDelta(t, 1, float64(l.Limit()), 0.001)
+	class, _ = b.limiter("")
+	assert.Equal(t, DefaultAPIBudgetClass, class)
+
+	b = newAPIBudget(10, map[string]int{DefaultAPIBudgetClass: 3, "database": 2})
+	_, l = b.limiter("")
+	assert.InDelta(t, 6, float64(l.Limit()), 0.001)
+}
 
-			// Mock CreateVolume for getVolumeLimits dry-run calls
-			mockEC2.EXPECT().CreateVolume(testutil.AnyContext(), testutil.EC2Input(&ec2.CreateVolumeInput{}), testutil.EC2Options()).Return(nil, &smithy.GenericAPIError{Code: "DryRunOperation"}).AnyTimes()
+func TestRetryPolicy(t *testing.T) {
+	policy := &RetryPolicy{
+		VolumeCreation:      &Backoff{InitialDelay: metav1.Duration{Duration: 2 * time.Second}, Steps: 20},
+		VolumeAttachment:    &Backoff{Interval: metav1.Duration{Duration: 3 * time.Second}, Factor: 1.2, Cap: metav1.Duration{Duration: time.Minute}},
+		DependencyViolation: &Backoff{Steps: 3},
+	}
+	require.NoError(t, policy.Validate())
+
+	p := vwp.withRetryPolicy(policy)
+	assert.Equal(t, 2*time.Second, p.creationInitialDelay)
+	assert.Equal(t, wait.Backoff{Duration: 500 * time.Millisecond, Factor: 1.5, Steps: 20}, p.creationBackoff)
+	assert.Equal(t, wait.Backoff{Duration: 3 * time.Second, Factor: 1.2, Steps: 13, Cap: time.Minute}, p.attachmentBackoff)
+	assert.Equal(t, vwp.modificationBackoff, p.modificationBackoff)
+	assert.Equal(t, wait.Backoff{Duration: 5 * time.Second, Factor: 2, Steps: 3}, p.dependencyViolationBackoff)
+	assert.Equal(t, vwp, vwp.withRetryPolicy(nil))
+
+	invalid := []*RetryPolicy{
+		{VolumeModification: &Backoff{Factor: 0.5}},
+		{VolumeAttachment: &Backoff{Steps: -1}},
+		{DependencyViolation: &Backoff{Interval: metav1.Duration{Duration: -time.Second}}},
+		{VolumeAttachment: &Backoff{InitialDelay: metav1.Duration{Duration: time.Second}}},
+		{SnapshotPollInterval: metav1.Duration{Duration: -time.Second}},
+	}
+	for _, policy := range invalid {
+		assert.Error(t, policy.Validate(), "%+v", policy)
+	}
+}
 
-			if tc.modifiedVolume != nil || tc.modifiedVolumeError != nil {
-				mockEC2.EXPECT().ModifyVolume(testutil.AnyContext(), testutil.EC2Input(&ec2.ModifyVolumeInput{}), testutil.EC2Options()).Return(tc.modifiedVolume, tc.modifiedVolumeError).MinTimes(1)
-			}
-			if tc.descModVolume != nil {
-				mockEC2.EXPECT().DescribeVolumesModifications(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesModificationsInput{}), testutil.EC2Options()).Return(tc.descModVolume, nil).MinTimes(1)
+func TestDeleteDiskDependencyViolation(t *testing.T) {
+	volumeID := "vol-test-1234"
+	inUse := &smithy.GenericAPIError{Code: "VolumeInUse"}
+	testCases := []struct {
+		name     string
+		steps    int
+		errs     []error
+		expErr   bool
+		expCalls int
+	}{
+		{
+			name:     "not retried by default",
+			steps:    1,
+			errs:     []error{inUse},
+			expErr:   true,
+			expCalls: 1,
+		},
+		{
+			name:     "retried until the volume is deleted",
+			steps:    3,
+			errs:     []error{inUse, inUse, nil},
+			expCalls: 3,
+		},
+		{
+			name:     "retried until steps are used up",
+			steps:    2,
+			errs:     []error{inUse, inUse},
+			expErr:   true,
+			expCalls: 2,
+		},
+		{
+			name:     "other errors are not retried",
+			steps:    3,
+			errs:     []error{errors.New("DeleteVolume generic error")},
+			expErr:   true,
+			expCalls: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			mockEC2 := NewMockEC2API(mockCtrl)
+			c := newCloud(mockEC2).(*cloud)
+			c.vwp.dependencyViolationBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: tc.steps}
+
+			calls := 0
+			mockEC2.EXPECT().DeleteVolume(testutil.AnyContext(), gomock.Eq(&ec2.DeleteVolumeInput{VolumeId: &volumeID}), testutil.EC2Options()).DoAndReturn(
+				func(_ context.Context, _ *ec2.DeleteVolumeInput, _ ...func(*ec2.Options)) (*ec2.DeleteVolumeOutput, error) {
+					calls++
+					return &ec2.DeleteVolumeOutput{}, tc.errs[calls-1]
+				}).Times(tc.expCalls)
+
+			ok, err := c.DeleteDisk(t.Context(), volumeID)
+			if tc.expErr {
+				require.Error(t, err)
+				assert.False(t, ok)
 			} else {
-				emptyOutput := &ec2.DescribeVolumesModificationsOutput{}
-				mockEC2.EXPECT().DescribeVolumesModifications(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesModificationsInput{}), testutil.EC2Options()).Return(emptyOutput, nil).AnyTimes()
+				require.NoError(t, err)
+				assert.True(t, ok)
 			}
+		})
+	}
+}
 
-			newSize, err := c.ResizeOrModifyDisk(ctx, tc.volumeID, util.GiBToBytes(tc.reqSizeGiB), tc.modifyDiskOptions)
-			switch {
-			case errors.Is(tc.expErr, ErrInvalidArgument):
-				require.ErrorIs(t, err, ErrInvalidArgument, "ResizeOrModifyDisk() should return ErrInvalidArgument")
-			case tc.expErr != nil:
-				require.Error(t, err, "ResizeOrModifyDisk() should return error")
-			default:
-				require.NoError(t, err, "ResizeOrModifyDisk() should not return error")
-				assert.Equal(t, tc.reqSizeGiB, newSize, "ResizeOrModifyDisk() returned unexpected capacity")
-			}
+func TestGetVolumeHealth(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2)
 
-			mockCtrl.Finish()
+	mockEC2.EXPECT().DescribeVolumeStatus(gomock.Any(), gomock.Any(), gomock.Any()).Return(&ec2.DescribeVolumeStatusOutput{
+		VolumeStatuses: []types.VolumeStatusItem{
+			{
+				VolumeId: aws.String("vol-ok"),
+				VolumeStatus: &types.VolumeStatusInfo{
+					Status:  types.VolumeStatusInfoStatusOk,
+					Details: []types.VolumeStatusDetails{{Name: types.VolumeStatusNameIoEnabled, Status: aws.String("passed")}},
+				},
+			},
+			{
+				VolumeId: aws.String("vol-io-disabled"),
+				VolumeStatus: &types.VolumeStatusInfo{
+					Status:  types.VolumeStatusInfoStatusImpaired,
+					Details: []types.VolumeStatusDetails{{Name: types.VolumeStatusNameIoEnabled, Status: aws.String("failed")}},
+				},
+				Events: []types.VolumeStatusEvent{{EventType: aws.String("potential-data-inconsistency"), Description: aws.String("THIS IS AN AUTOMATED EVENT")}},
+			},
+			{
+				VolumeId: aws.String("vol-initializing"),
+				VolumeStatus: &types.VolumeStatusInfo{
+					Status:  types.VolumeStatusInfoStatusOk,
+					Details: []types.VolumeStatusDetails{{Name: types.VolumeStatusNameInitializationState, Status: aws.String(VolumeStatusInitializingState)}},
+				},
+				InitializationStatusDetails: &types.InitializationStatusDetails{
+					Progress:                         aws.Int64(42),
+					EstimatedTimeToCompleteInSeconds: aws.Int64(600),
+				},
+			},
+		},
+	}, nil)
+
+	health, err := c.GetVolumeHealth(t.Context(), []string{"vol-ok", "vol-io-disabled", "vol-initializing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*VolumeHealth{
+		"vol-ok": {Status: types.VolumeStatusInfoStatusOk, IOEnabled: true},
+		"vol-io-disabled": {
+			Status:    types.VolumeStatusInfoStatusImpaired,
+			IOEnabled: false,
+			Events:    "potential-data-inconsistency: THIS IS AN AUTOMATED EVENT",
+		},
+		"vol-initializing": {
+			Status:                 types.VolumeStatusInfoStatusOk,
+			IOEnabled:              true,
+			Initializing:           true,
+			InitializationProgress: 42,
+			InitializationTimeLeft: 10 * time.Minute,
+		},
+	}, health)
+	assert.False(t, health["vol-ok"].Impaired())
+	assert.True(t, health["vol-io-disabled"].Impaired())
+}
+
+func TestEnableVolumeIO(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2)
+
+	mockEC2.EXPECT().EnableVolumeIO(gomock.Any(), &ec2.EnableVolumeIOInput{VolumeId: aws.String("vol-test")}, gomock.Any()).Return(&ec2.EnableVolumeIOOutput{}, nil)
+	require.NoError(t, c.EnableVolumeIO(t.Context(), "vol-test"))
+
+	mockEC2.EXPECT().EnableVolumeIO(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, &smithy.GenericAPIError{Code: "InvalidVolume.NotFound"})
+	require.ErrorIs(t, c.EnableVolumeIO(t.Context(), "vol-missing"), ErrNotFound)
+}
+
+func TestGetEBSBandwidth(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2)
+
+	output := newDescribeInstancesOutput(defaultNodeID, "vol-gp3")
+	instance := &output.Reservations[0].Instances[0]
+	instance.InstanceType = types.InstanceTypeM5Large
+	instance.BlockDeviceMappings = append(instance.BlockDeviceMappings, types.InstanceBlockDeviceMapping{
+		Ebs: &types.EbsInstanceBlockDevice{VolumeId: aws.String("vol-io2")},
+	})
+	mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeInstancesInput{}), testutil.EC2Options()).Return(output, nil)
+	mockEC2.EXPECT().DescribeInstanceTypes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeInstanceTypesInput{})).Return(&ec2.DescribeInstanceTypesOutput{
+		InstanceTypes: []types.InstanceTypeInfo{{
+			EbsInfo: &types.EbsInfo{EbsOptimizedInfo: &types.EbsOptimizedInfo{MaximumThroughputInMBps: aws.Float64(593.75)}},
+		}},
+	}, nil)
+	mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumesInput{}), testutil.EC2Options()).Return(&ec2.DescribeVolumesOutput{
+		Volumes: []types.Volume{
+			{VolumeId: aws.String("vol-gp3"), VolumeType: VolumeTypeGP3, Throughput: aws.Int32(125)},
+			{VolumeId: aws.String("vol-io2"), VolumeType: VolumeTypeIO2, Iops: aws.Int32(4000)},
+		},
+	}, nil)
+
+	bandwidth, err := c.GetEBSBandwidth(t.Context(), defaultNodeID)
+	require.NoError(t, err)
+	assert.Equal(t, &EBSBandwidth{InstanceType: "m5.large", MaximumMBps: 593, AttachedMBps: 1125}, bandwidth)
+	assert.True(t, bandwidth.Oversubscribed())
+}
+
+func TestVolumeMaxThroughputMBps(t *testing.T) {
+	testCases := []struct {
+		disk     Disk
+		expected int32
+	}{
+		{disk: Disk{VolumeType: VolumeTypeGP3, Throughput: 1000}, expected: 1000},
+		{disk: Disk{VolumeType: VolumeTypeGP2}, expected: 250},
+		{disk: Disk{VolumeType: VolumeTypeIO1, IOPS: 64000}, expected: 1000},
+		{disk: Disk{VolumeType: VolumeTypeIO2, IOPS: 3000}, expected: 750},
+		{disk: Disk{VolumeType: VolumeTypeST1}, expected: 500},
+		{disk: Disk{VolumeType: "unknown"}, expected: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.disk.VolumeType, func(t *testing.T) {
+			assert.Equal(t, tc.expected, volumeMaxThroughputMBps(&tc.disk))
 		})
 	}
 }
@@ -3978,6 +5664,44 @@ func TestGetSnapshotByName(t *testing.T) {
 	}
 }
 
+func TestGetSnapshotByNameWithBatchingUsesCachedID(t *testing.T) {
+	snapshotName := "snap-test-name"
+	snapshotID := "snap-test-id"
+	ec2snapshot := types.Snapshot{
+		SnapshotId: aws.String(snapshotID),
+		VolumeId:   aws.String("vol-test"),
+		VolumeSize: aws.Int32(10),
+		StartTime:  aws.Time(time.Now()),
+		State:      types.SnapshotStatePending,
+		Tags:       []types.Tag{{Key: aws.String(SnapshotNameTagKey), Value: aws.String(snapshotName)}},
+	}
+
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2).(*cloud)
+	c.bm = newBatcherManager(c.ec2, c.rm, DefaultBatchDelay, DefaultBatchDelay)
+
+	// The first lookup goes by name, later lookups go by the ID learned from it
+	gomock.InOrder(
+		mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), gomock.Any(), testutil.EC2Options()).DoAndReturn(func(_ context.Context, input *ec2.DescribeSnapshotsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error) {
+			assert.Empty(t, input.SnapshotIds)
+			assert.Len(t, input.Filters, 1)
+			return &ec2.DescribeSnapshotsOutput{Snapshots: []types.Snapshot{ec2snapshot}}, nil
+		}),
+		mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), gomock.Any(), testutil.EC2Options()).DoAndReturn(func(_ context.Context, input *ec2.DescribeSnapshotsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error) {
+			assert.Equal(t, []string{snapshotID}, input.SnapshotIds)
+			assert.Empty(t, input.Filters)
+			return &ec2.DescribeSnapshotsOutput{Snapshots: []types.Snapshot{ec2snapshot}}, nil
+		}),
+	)
+
+	for range 2 {
+		snapshot, err := c.GetSnapshotByName(t.Context(), snapshotName)
+		require.NoError(t, err)
+		assert.Equal(t, snapshotID, snapshot.SnapshotID)
+	}
+}
+
 func TestGetSnapshotByID(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -3997,6 +5721,31 @@ func TestGetSnapshotByID(t *testing.T) {
 			},
 			expErr: nil,
 		},
+		{
+			name:       "success: volume initialization rate",
+			snapshotID: "snap-test-name",
+			expSnapshot: &Snapshot{
+				SnapshotID:               "snap-test-name",
+				SourceVolumeID:           "snap-test-volume",
+				Size:                     10,
+				CreationTime:             time.Now(),
+				ReadyToUse:               true,
+				VolumeInitializationRate: 200,
+			},
+			expErr: nil,
+		},
+		{
+			name:       "success: failed snapshot",
+			snapshotID: "snap-test-name",
+			expSnapshot: &Snapshot{
+				SnapshotID:     "snap-test-name",
+				SourceVolumeID: "snap-test-volume",
+				Size:           10,
+				CreationTime:   time.Now(),
+				Failed:         true,
+			},
+			expErr: nil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -4012,6 +5761,12 @@ func TestGetSnapshotByID(t *testing.T) {
 				StartTime:  aws.Time(tc.expSnapshot.CreationTime),
 				State:      types.SnapshotStateCompleted,
 			}
+			if tc.expSnapshot.Failed {
+				ec2snapshot.State = types.SnapshotStateError
+			}
+			if tc.expSnapshot.VolumeInitializationRate > 0 {
+				ec2snapshot.Tags = []types.Tag{{Key: aws.String(VolumeInitializationRateTagKey), Value: aws.String(strconv.Itoa(int(tc.expSnapshot.VolumeInitializationRate)))}}
+			}
 
 			ctx := t.Context()
 
@@ -4044,12 +5799,50 @@ func TestGetSnapshotByID(t *testing.T) {
 				if snapshot.ReadyToUse != tc.expSnapshot.ReadyToUse {
 					t.Fatalf("GetSnapshotByID() failed: expected ready to use %t, got %t", tc.expSnapshot.ReadyToUse, snapshot.ReadyToUse)
 				}
+				if snapshot.Failed != tc.expSnapshot.Failed {
+					t.Fatalf("GetSnapshotByID() failed: expected failed %t, got %t", tc.expSnapshot.Failed, snapshot.Failed)
+				}
+				if snapshot.VolumeInitializationRate != tc.expSnapshot.VolumeInitializationRate {
+					t.Fatalf("GetSnapshotByID() failed: expected volume initialization rate %d, got %d", tc.expSnapshot.VolumeInitializationRate, snapshot.VolumeInitializationRate)
+				}
 			}
 
 			mockCtrl.Finish()
 		})
 	}
 }
+func TestListSnapshotsByTags(t *testing.T) {
+	_, err := newCloud(nil).ListSnapshotsByTags(t.Context(), nil)
+	require.ErrorIs(t, err, ErrInvalidRequest)
+
+	mockCtrl := gomock.NewController(t)
+	mockEC2 := NewMockEC2API(mockCtrl)
+	c := newCloud(mockEC2)
+	creationTime := time.Now()
+	mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeSnapshotsInput{})).DoAndReturn(
+		func(_ context.Context, input *ec2.DescribeSnapshotsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error) {
+			assert.Equal(t, []string{"self"}, input.OwnerIds)
+			require.Len(t, input.Filters, 1)
+			assert.Equal(t, "tag:"+SnapshotNameTagKey, *input.Filters[0].Name)
+			assert.Equal(t, []string{"clone-*"}, input.Filters[0].Values)
+			return &ec2.DescribeSnapshotsOutput{Snapshots: []types.Snapshot{{
+				SnapshotId: aws.String("snap-test"),
+				VolumeId:   aws.String("vol-test"),
+				VolumeSize: aws.Int32(10),
+				StartTime:  aws.Time(creationTime),
+				State:      types.SnapshotStateCompleted,
+				Tags:       []types.Tag{{Key: aws.String(SnapshotNameTagKey), Value: aws.String("clone-pvc-1234")}},
+			}}}, nil
+		})
+
+	snapshots, err := c.ListSnapshotsByTags(t.Context(), map[string]string{SnapshotNameTagKey: "clone-*"})
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "snap-test", snapshots[0].SnapshotID)
+	assert.Equal(t, "clone-pvc-1234", snapshots[0].Name)
+	assert.True(t, snapshots[0].ReadyToUse)
+}
+
 func TestListSnapshots(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -4742,23 +6535,25 @@ func TestIsVolumeInitialized(t *testing.T) {
 
 			mockCtrl := gomock.NewController(t)
 			mockEC2 := NewMockEC2API(mockCtrl)
+			rm := newRetryManager()
 			c := &cloud{
 				region:                "test-region",
 				ec2:                   mockEC2,
+				rm:                    rm,
 				volumeInitializations: volInitCache,
 				bm: &batcherManager{
-					volumeStatusIDBatcherFast: batcher.New(500, 0, func(ids []string) (map[string]*types.VolumeStatusItem, error) {
-						return execBatchDescribeVolumeStatus(mockEC2, ids)
+					volumeStatusIDBatcherFast: batcher.New(500, 0, func(ctx context.Context, ids []string) (map[string]*types.VolumeStatusItem, error) {
+						return execBatchDescribeVolumeStatus(ctx, mockEC2, rm.batchDescribeVolumeStatusRetryer, ids)
 					}),
-					volumeStatusIDBatcherSlow: batcher.New(500, testInitializationSleep, func(ids []string) (map[string]*types.VolumeStatusItem, error) {
-						return execBatchDescribeVolumeStatus(mockEC2, ids) // TODO remove test sleeps once Go 1.25 releases with testing/synctest package
+					volumeStatusIDBatcherSlow: batcher.New(500, testInitializationSleep, func(ctx context.Context, ids []string) (map[string]*types.VolumeStatusItem, error) {
+						return execBatchDescribeVolumeStatus(ctx, mockEC2, rm.batchDescribeVolumeStatusRetryer, ids) // TODO remove test sleeps once Go 1.25 releases with testing/synctest package
 					}),
 				},
 			}
 
 			// If tc.dvsOutput nil, we should NOT expect a DVS call.
 			if tc.dvsOutput != nil {
-				mockEC2.EXPECT().DescribeVolumeStatus(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumeStatusInput{})).Return(&ec2.DescribeVolumeStatusOutput{VolumeStatuses: []types.VolumeStatusItem{*tc.dvsOutput}}, nil).MinTimes(1)
+				mockEC2.EXPECT().DescribeVolumeStatus(testutil.AnyContext(), testutil.EC2Input(&ec2.DescribeVolumeStatusInput{}), testutil.EC2Options()).Return(&ec2.DescribeVolumeStatusOutput{VolumeStatuses: []types.VolumeStatusItem{*tc.dvsOutput}}, nil).MinTimes(1)
 			}
 
 			startTime := time.Now()
@@ -4916,6 +6711,12 @@ func TestExtractMaxIOPSFromError(t *testing.T) {
 	}
 }
 
+func TestExtractMaxSizeGiBFromError(t *testing.T) {
+	assert.Equal(t, int32(65536), extractMaxSizeGiBFromError("io2 volumes configured with greater than 64 TiB or 256K IOPS or 1000:1 IOPS:GB ratio are not supported."))
+	assert.Equal(t, int32(16384), extractMaxSizeGiBFromError("io2 volumes configured with greater than 16 TiB or 64K IOPS or 500:1 IOPS:GB ratio are not supported."))
+	assert.Equal(t, int32(io2FallbackMaxGiB), extractMaxSizeGiBFromError("Volume iops of 300000 is too high; maximum is 256000."))
+}
+
 func TestGetVolumeLimits(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -4990,6 +6791,22 @@ func TestGetVolumeLimits(t *testing.T) {
 				maxIops:      256000,
 				minIops:      100,
 				maxIopsPerGb: 1000,
+				maxSizeGiB:   65536,
+			},
+			expectCaching: true,
+		},
+		{
+			name:       "cache miss: io2 dry run extracts limits of zone without Block Express",
+			volumeType: VolumeTypeIO2,
+			azParams: getVolumeLimitsParams{
+				availabilityZone: *aws.String("us-west-2d"),
+			},
+			createVolumeErr: errors.New("An error occurred (InvalidParameterCombination) when calling the CreateVolume operation: io2 volumes configured with greater than 16 TiB or 64K IOPS or 500:1 IOPS:GB ratio are not supported."),
+			expectedLimits: iopsLimits{
+				maxIops:      64000,
+				minIops:      100,
+				maxIopsPerGb: 1000,
+				maxSizeGiB:   16384,
 			},
 			expectCaching: true,
 		},
@@ -5185,7 +7002,10 @@ func newCloud(mockEC2 util.EC2API) Cloud {
 		volumeInitializations: expiringcache.New[string, volumeInitialization](cacheForgetDelay),
 		latestIOPSLimits:      expiringcache.New[string, iopsLimits](iopsLimitCacheForgetDelay),
 		cardCountCache:        expiringcache.New[string, int](cacheForgetDelay),
+		ebsThroughputCache:    expiringcache.New[string, int32](cacheForgetDelay),
 	}
+	// Tests of AttachDisk do not expect the device names of attachments in progress to be restored first
+	c.restoreDevicesOnce.Do(func() {})
 	return c
 }
 
@@ -5480,6 +7300,36 @@ func TestCheckIfIopsIncreaseOnExpansion(t *testing.T) {
 	}
 }
 
+func TestNewObservedCache(t *testing.T) {
+	_, registry := metrics.InitializeRecorder(false)
+	cache := newObservedCache[string, int]("test_cache", cacheForgetDelay)
+
+	value := 1
+	cache.Get("vol-1")
+	cache.Set("vol-1", &value)
+	cache.Get("vol-1")
+	cache.Get("vol-1")
+
+	counts := map[string]float64{}
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != metrics.ExpiringCacheRequests {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["cache"] == "test_cache" {
+				counts[labels["result"]] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{"hit": 2, "miss": 1}, counts)
+}
+
 func TestRemoveLikelyBadIds(t *testing.T) {
 	testCases := []struct {
 		name                 string
@@ -5558,9 +7408,9 @@ func TestExecBatchCaching(t *testing.T) {
 
 		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), gomock.Eq(&ec2.DescribeVolumesInput{
 			VolumeIds: []string{"vol-0c7e1a5f8b2d4c6939", "vol-0f8a2c4e6b9d1e5787"},
-		})).Return(nil, errors.New("InvalidVolume.NotFound: vol-0c7e1a5f8b2d4c6939")).Times(1)
+		}), testutil.EC2Options()).Return(nil, errors.New("InvalidVolume.NotFound: vol-0c7e1a5f8b2d4c6939")).Times(1)
 
-		_, err := execBatchDescribeVolumes(mockEC2, []string{"vol-0f8a2c4e6b9d1e5787", "vol-0c7e1a5f8b2d4c6939"}, volumeIDBatcher, cache)
+		_, err := execBatchDescribeVolumes(t.Context(), mockEC2, newRetryManager().batchDescribeVolumesRetryer, []string{"vol-0f8a2c4e6b9d1e5787", "vol-0c7e1a5f8b2d4c6939"}, volumeIDBatcher, cache)
 		require.Error(t, err)
 		_, exists := cache.Get("vol-0c7e1a5f8b2d4c6939")
 		assert.True(t, exists, "vol-0c7e1a5f8b2d4c6939 should be cached after error")
@@ -5575,16 +7425,16 @@ func TestExecBatchCaching(t *testing.T) {
 
 		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), gomock.Eq(&ec2.DescribeVolumesInput{
 			VolumeIds: []string{"vol-0f8a2c4e6b9d1e5787"},
-		})).Return(&ec2.DescribeVolumesOutput{
+		}), testutil.EC2Options()).Return(&ec2.DescribeVolumesOutput{
 			Volumes: []types.Volume{{VolumeId: aws.String("vol-0f8a2c4e6b9d1e5787")}},
 		}, nil).Times(1)
 		mockEC2.EXPECT().DescribeVolumes(testutil.AnyContext(), gomock.Eq(&ec2.DescribeVolumesInput{
 			VolumeIds: []string{"vol-0c7e1a5f8b2d4c6939"},
-		})).Return(&ec2.DescribeVolumesOutput{
+		}), testutil.EC2Options()).Return(&ec2.DescribeVolumesOutput{
 			Volumes: []types.Volume{{VolumeId: aws.String("vol-0c7e1a5f8b2d4c6939")}},
 		}, nil).Times(1)
 
-		result, err := execBatchDescribeVolumes(mockEC2, []string{"vol-0f8a2c4e6b9d1e5787", "vol-0c7e1a5f8b2d4c6939"}, volumeIDBatcher, cache)
+		result, err := execBatchDescribeVolumes(t.Context(), mockEC2, newRetryManager().batchDescribeVolumesRetryer, []string{"vol-0f8a2c4e6b9d1e5787", "vol-0c7e1a5f8b2d4c6939"}, volumeIDBatcher, cache)
 		require.NoError(t, err)
 		assert.Len(t, result, 2)
 		_, exists := cache.Get("vol-0c7e1a5f8b2d4c6939")
@@ -5599,9 +7449,9 @@ func TestExecBatchCaching(t *testing.T) {
 
 		mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), gomock.Eq(&ec2.DescribeInstancesInput{
 			InstanceIds: []string{"i-0c7e1a5f8b2d4c939", "i-0f8a2c4e6b9d1e787"},
-		})).Return(nil, errors.New("InvalidInstanceID.NotFound: i-0c7e1a5f8b2d4c939")).Times(1)
+		}), testutil.EC2Options()).Return(nil, errors.New("InvalidInstanceID.NotFound: i-0c7e1a5f8b2d4c939")).Times(1)
 
-		_, err := execBatchDescribeInstances(mockEC2, []string{"i-0f8a2c4e6b9d1e787", "i-0c7e1a5f8b2d4c939"}, cache)
+		_, err := execBatchDescribeInstances(t.Context(), mockEC2, newRetryManager().batchDescribeInstancesRetryer, []string{"i-0f8a2c4e6b9d1e787", "i-0c7e1a5f8b2d4c939"}, cache)
 		require.Error(t, err)
 		_, exists := cache.Get("i-0c7e1a5f8b2d4c939")
 		assert.True(t, exists, "i-0c7e1a5f8b2d4c939 should be cached after error")
@@ -5616,16 +7466,16 @@ func TestExecBatchCaching(t *testing.T) {
 
 		mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), gomock.Eq(&ec2.DescribeInstancesInput{
 			InstanceIds: []string{"i-0f8a2c4e6b9d1e787"},
-		})).Return(&ec2.DescribeInstancesOutput{
+		}), testutil.EC2Options()).Return(&ec2.DescribeInstancesOutput{
 			Reservations: []types.Reservation{{Instances: []types.Instance{{InstanceId: aws.String("i-0f8a2c4e6b9d1e787")}}}},
 		}, nil).Times(1)
 		mockEC2.EXPECT().DescribeInstances(testutil.AnyContext(), gomock.Eq(&ec2.DescribeInstancesInput{
 			InstanceIds: []string{"i-0c7e1a5f8b2d4c939"},
-		})).Return(&ec2.DescribeInstancesOutput{
+		}), testutil.EC2Options()).Return(&ec2.DescribeInstancesOutput{
 			Reservations: []types.Reservation{{Instances: []types.Instance{{InstanceId: aws.String("i-0c7e1a5f8b2d4c939")}}}},
 		}, nil).Times(1)
 
-		result, err := execBatchDescribeInstances(mockEC2, []string{"i-0f8a2c4e6b9d1e787", "i-0c7e1a5f8b2d4c939"}, cache)
+		result, err := execBatchDescribeInstances(t.Context(), mockEC2, newRetryManager().batchDescribeInstancesRetryer, []string{"i-0f8a2c4e6b9d1e787", "i-0c7e1a5f8b2d4c939"}, cache)
 		require.NoError(t, err)
 		assert.Len(t, result, 2)
 		_, exists := cache.Get("i-0c7e1a5f8b2d4c939")
@@ -5640,9 +7490,9 @@ func TestExecBatchCaching(t *testing.T) {
 
 		mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), gomock.Eq(&ec2.DescribeSnapshotsInput{
 			SnapshotIds: []string{"snap-0c7e1a5f8b2d4c939", "snap-0f8a2c4e6b9d1e787"},
-		})).Return(nil, errors.New("InvalidSnapshot.NotFound: snap-0c7e1a5f8b2d4c939")).Times(1)
+		}), testutil.EC2Options()).Return(nil, errors.New("InvalidSnapshot.NotFound: snap-0c7e1a5f8b2d4c939")).Times(1)
 
-		_, err := execBatchDescribeSnapshots(mockEC2, []string{"snap-0f8a2c4e6b9d1e787", "snap-0c7e1a5f8b2d4c939"}, snapshotIDBatcher, cache)
+		_, err := execBatchDescribeSnapshots(t.Context(), mockEC2, newRetryManager().batchDescribeSnapshotsRetryer, []string{"snap-0f8a2c4e6b9d1e787", "snap-0c7e1a5f8b2d4c939"}, snapshotIDBatcher, cache)
 		require.Error(t, err)
 		_, exists := cache.Get("snap-0c7e1a5f8b2d4c939")
 		assert.True(t, exists, "snap-0c7e1a5f8b2d4c939 should be cached after error")
@@ -5657,16 +7507,16 @@ func TestExecBatchCaching(t *testing.T) {
 
 		mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), gomock.Eq(&ec2.DescribeSnapshotsInput{
 			SnapshotIds: []string{"snap-0f8a2c4e6b9d1e787"},
-		})).Return(&ec2.DescribeSnapshotsOutput{
+		}), testutil.EC2Options()).Return(&ec2.DescribeSnapshotsOutput{
 			Snapshots: []types.Snapshot{{SnapshotId: aws.String("snap-0f8a2c4e6b9d1e787")}},
 		}, nil).Times(1)
 		mockEC2.EXPECT().DescribeSnapshots(testutil.AnyContext(), gomock.Eq(&ec2.DescribeSnapshotsInput{
 			SnapshotIds: []string{"snap-0c7e1a5f8b2d4c939"},
-		})).Return(&ec2.DescribeSnapshotsOutput{
+		}), testutil.EC2Options()).Return(&ec2.DescribeSnapshotsOutput{
 			Snapshots: []types.Snapshot{{SnapshotId: aws.String("snap-0c7e1a5f8b2d4c939")}},
 		}, nil).Times(1)
 
-		result, err := execBatchDescribeSnapshots(mockEC2, []string{"snap-0f8a2c4e6b9d1e787", "snap-0c7e1a5f8b2d4c939"}, snapshotIDBatcher, cache)
+		result, err := execBatchDescribeSnapshots(t.Context(), mockEC2, newRetryManager().batchDescribeSnapshotsRetryer, []string{"snap-0f8a2c4e6b9d1e787", "snap-0c7e1a5f8b2d4c939"}, snapshotIDBatcher, cache)
 		require.NoError(t, err)
 		assert.Len(t, result, 2)
 		_, exists := cache.Get("snap-0c7e1a5f8b2d4c939")
@@ -5734,3 +7584,197 @@ func TestLockSnapshot(t *testing.T) {
 		})
 	}
 }
+
+func TestCorrelationUserAgentSegment(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ids      []string
+		expected string
+	}{
+		{name: "no IDs", expected: ""},
+		{name: "single ID", ids: []string{"a"}, expected: "corr/a"},
+		{name: "batched IDs", ids: []string{"a", "b", "c"}, expected: "corr/a+2"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, correlationUserAgentSegment(tc.ids))
+		})
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{name: "not found", err: &smithy.GenericAPIError{Code: "InvalidVolume.NotFound"}, expected: ErrNotFound},
+		{name: "throttling", err: &smithy.GenericAPIError{Code: "RequestLimitExceeded"}, expected: ErrThrottled},
+		{name: "other throttling code", err: &smithy.GenericAPIError{Code: "ThrottlingException"}, expected: ErrThrottled},
+		{name: "idempotent parameter mismatch", err: &smithy.GenericAPIError{Code: "IdempotentParameterMismatch"}, expected: ErrIdempotentParameterMismatch},
+		{name: "invalid zone is an invalid argument", err: &smithy.GenericAPIError{Code: "InvalidZone.NotFound"}, expected: ErrInvalidArgument},
+		{name: "wrapped", err: fmt.Errorf("could not attach volume: %w", &smithy.GenericAPIError{Code: "AttachmentLimitExceeded"}), expected: ErrLimitExceeded},
+		{name: "unknown code", err: &smithy.GenericAPIError{Code: "SomethingNew"}},
+		{name: "not an API error", err: errors.New("test error")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ClassifyError(tc.err)
+			assert.Equal(t, tc.err.Error(), err.Error())
+			assert.ErrorIs(t, err, tc.err)
+			if tc.expected == nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.expected)
+			assert.NotErrorIs(t, err, ErrInvalidRequest)
+			var apiErr smithy.APIError
+			assert.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, err, ClassifyError(err))
+		})
+	}
+	assert.NoError(t, ClassifyError(nil))
+}
+
+func TestLimitExceededError(t *testing.T) {
+	testCases := []struct {
+		name          string
+		code          string
+		volumeType    string
+		expQuota      string
+		expVolumeType string
+		expQuotaName  string
+	}{
+		{name: "storage", code: "VolumeLimitExceeded", volumeType: VolumeTypeGP3, expQuota: QuotaStorage, expVolumeType: VolumeTypeGP3, expQuotaName: "Storage for General Purpose SSD (gp3) volumes, in TiB"},
+		{name: "iops", code: "MaxIOPSLimitExceeded", volumeType: VolumeTypeIO2, expQuota: QuotaIOPS, expVolumeType: VolumeTypeIO2, expQuotaName: "IOPS for Provisioned IOPS SSD (io2) volumes"},
+		{name: "storage modifications of unknown type", code: "VolumeModificationSizeLimitExceeded", expQuota: QuotaStorageModifications, expQuotaName: "Storage modifications for volumes, in TiB"},
+		{name: "snapshots", code: "SnapshotLimitExceeded", volumeType: VolumeTypeGP3, expQuota: QuotaSnapshots, expQuotaName: "Snapshots per Region"},
+		{name: "concurrent snapshots", code: "ConcurrentSnapshotLimitExceeded", expQuota: QuotaConcurrentSnapshots, expQuotaName: "Concurrent snapshots per volume"},
+		{name: "not a quota", code: "AttachmentLimitExceeded"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			apiErr := &smithy.GenericAPIError{Code: tc.code, Message: "limit exceeded"}
+			err := limitExceededError(apiErr, tc.volumeType)
+			require.ErrorIs(t, err, ErrLimitExceeded)
+			require.ErrorIs(t, err, apiErr)
+			var quotaErr *QuotaExceededError
+			if tc.expQuota == "" {
+				assert.False(t, errors.As(err, &quotaErr))
+				return
+			}
+			require.ErrorAs(t, err, &quotaErr)
+			assert.Equal(t, tc.expQuota, quotaErr.Quota)
+			assert.Equal(t, tc.expVolumeType, quotaErr.VolumeType)
+			assert.Equal(t, tc.expQuotaName, quotaErr.QuotaName())
+			assert.Equal(t, fmt.Sprintf("EC2 quota %q exceeded: %v", tc.expQuotaName, apiErr), err.Error())
+		})
+	}
+}
+
+func TestClassifyErrorsMiddleware(t *testing.T) {
+	stack := middleware.NewStack("test", smithyhttp.NewStackRequest)
+	require.NoError(t, ClassifyErrorsMiddleware()(stack))
+	handler := middleware.DecorateHandler(middleware.HandlerFunc(func(context.Context, interface{}) (interface{}, middleware.Metadata, error) {
+		return nil, middleware.Metadata{}, &smithy.GenericAPIError{Code: "InsufficientVolumeCapacity"}
+	}), stack)
+
+	_, _, err := handler.Handle(t.Context(), nil)
+	require.ErrorIs(t, err, ErrInsufficientCapacity)
+	assert.Equal(t, "api error InsufficientVolumeCapacity: ", err.Error())
+}
+
+func TestCountThrottlesMiddleware(t *testing.T) {
+	throttles := new(atomic.Int64)
+	stack := middleware.NewStack("test", smithyhttp.NewStackRequest)
+	require.NoError(t, CountThrottlesMiddleware(throttles)(stack))
+	var err error
+	handler := middleware.DecorateHandler(middleware.HandlerFunc(func(context.Context, interface{}) (interface{}, middleware.Metadata, error) {
+		return nil, middleware.Metadata{}, err
+	}), stack)
+
+	for _, err = range []error{
+		&smithy.GenericAPIError{Code: "RequestLimitExceeded"},
+		&smithy.GenericAPIError{Code: "InsufficientVolumeCapacity"},
+		errors.New("not an API error"),
+		nil,
+		&smithy.GenericAPIError{Code: "Throttling"},
+	} {
+		_, _, _ = handler.Handle(t.Context(), nil)
+	}
+	assert.Equal(t, int64(2), throttles.Load())
+}
+
+func TestOperationStepMiddleware(t *testing.T) {
+	stack := middleware.NewStack("test", smithyhttp.NewStackRequest)
+	require.NoError(t, stack.Initialize.Add(&awsmiddleware.RegisterServiceMetadata{OperationName: "AttachVolume"}, middleware.Before))
+	require.NoError(t, OperationStepMiddleware()(stack))
+	step := &util.OperationStep{}
+	handler := middleware.DecorateHandler(middleware.HandlerFunc(func(context.Context, interface{}) (interface{}, middleware.Metadata, error) {
+		assert.Equal(t, "EC2 AttachVolume", step.Get())
+		return nil, middleware.Metadata{}, nil
+	}), stack)
+
+	ctx := util.WithOperationStep(t.Context(), step)
+	defer util.SetOperationStep(ctx, "waiting for volume to be attached")()
+	_, _, err := handler.Handle(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "waiting for volume to be attached", step.Get())
+}
+
+func TestEmulatorEndpoints(t *testing.T) {
+	testCases := []struct {
+		name              string
+		env               map[string]string
+		expEmulator       bool
+		expEC2Endpoint    string
+		expSTSEndpoint    string
+		expStaticCredsSet bool
+	}{
+		{
+			name: "default endpoints",
+		},
+		{
+			name:           "custom EC2 endpoint is not an emulator",
+			env:            map[string]string{ec2EndpointEnv: "https://ec2.example.com/"},
+			expEC2Endpoint: "https://ec2.example.com",
+		},
+		{
+			name:              "emulator serves STS on the EC2 endpoint",
+			env:               map[string]string{ec2EndpointEnv: "http://localhost:4566/", emulatorEnv: "true"},
+			expEmulator:       true,
+			expEC2Endpoint:    "http://localhost:4566",
+			expSTSEndpoint:    "http://localhost:4566",
+			expStaticCredsSet: true,
+		},
+		{
+			name:           "emulator with own STS endpoint and credentials",
+			env:            map[string]string{ec2EndpointEnv: "http://localhost:4566", emulatorEnv: "true", stsEndpointEnv: "http://localhost:4567", "AWS_ACCESS_KEY_ID": "key"},
+			expEmulator:    true,
+			expEC2Endpoint: "http://localhost:4566",
+			expSTSEndpoint: "http://localhost:4567",
+		},
+		{
+			name: "emulator needs an EC2 endpoint",
+			env:  map[string]string{emulatorEnv: "true"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, env := range []string{ec2EndpointEnv, stsEndpointEnv, emulatorEnv, "AWS_ACCESS_KEY_ID"} {
+				t.Setenv(env, tc.env[env])
+			}
+			assert.Equal(t, tc.expEmulator, emulatorMode())
+			assert.Equal(t, tc.expEC2Endpoint, ec2Endpoint())
+			assert.Equal(t, tc.expSTSEndpoint, serviceEndpoint(stsEndpointEnv))
+
+			var opts config.LoadOptions
+			for _, opt := range loadOptions("us-west-2") {
+				require.NoError(t, opt(&opts))
+			}
+			assert.Equal(t, "us-west-2", opts.Region)
+			assert.Equal(t, tc.expStaticCredsSet, opts.Credentials != nil)
+		})
+	}
+}
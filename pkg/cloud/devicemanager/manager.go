@@ -22,6 +22,7 @@ import (
 	"maps"
 	"math"
 	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -61,8 +62,17 @@ type DeviceManager interface {
 
 	// GetDevice returns the device already assigned to the volume.
 	GetDevice(instance *types.Instance, volumeID string) (device *Device, err error)
+
+	// RestoreDevice marks a device name as being attached to the instance, for attachments started before the
+	// driver restarted. The name stays assigned until the instance reports the attachment or the
+	// attachment is retried and released, or for restoredDeviceTTL at most.
+	RestoreDevice(nodeID, volumeID, deviceName string, cardIndex *int32)
 }
 
+// restoredDeviceTTL is how long a device name restored by RestoreDevice stays assigned if the
+// instance never reports the attachment and the attachment is not retried.
+const restoredDeviceTTL = 5 * time.Minute
+
 type deviceManager struct {
 	// nameAllocator assigns new device name
 	nameAllocator NameAllocator
@@ -80,6 +90,8 @@ var _ DeviceManager = &deviceManager{}
 type inFlightEntry struct {
 	DeviceName string
 	CardIndex  *int32
+	// RestoredUntil is when an entry added by RestoreDevice expires. Zero for other entries.
+	RestoredUntil time.Time
 }
 
 // inFlightAttaching represents the volumes being currently attached to nodes.
@@ -95,6 +107,13 @@ func (i inFlightAttaching) Add(nodeID, volumeID, deviceName string, cardIndex *i
 	attaching[volumeID] = inFlightEntry{DeviceName: deviceName, CardIndex: cardIndex}
 }
 
+func (i inFlightAttaching) Restore(nodeID, volumeID, deviceName string, cardIndex *int32, until time.Time) {
+	i.Add(nodeID, volumeID, deviceName, cardIndex)
+	entry := i[nodeID][volumeID]
+	entry.RestoredUntil = until
+	i[nodeID][volumeID] = entry
+}
+
 func (i inFlightAttaching) Del(nodeID, volumeID string) {
 	delete(i[nodeID], volumeID)
 }
@@ -248,6 +267,41 @@ func (d *deviceManager) GetDevice(instance *types.Instance, volumeID string) (*D
 	return d.newBlockDevice(instance, volumeID, "", false, nil), nil
 }
 
+func (d *deviceManager) RestoreDevice(nodeID, volumeID, deviceName string, cardIndex *int32) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if _, exists := d.inFlight.GetEntry(nodeID, volumeID); exists {
+		return
+	}
+	klog.V(4).InfoS("Restoring device name of attachment in progress", "nodeID", nodeID, "volumeID", volumeID, "deviceName", deviceName)
+	d.inFlight.Restore(nodeID, volumeID, deviceName, cardIndex, time.Now().Add(restoredDeviceTTL))
+}
+
+// pruneRestoredDevices removes the restored entries of the instance that expired or whose attachment the instance
+// reports, which then keeps their device name assigned.
+func (d *deviceManager) pruneRestoredDevices(instance *types.Instance) {
+	nodeID := aws.ToString(instance.InstanceId)
+	now := time.Now()
+	for volumeID, entry := range d.inFlight.GetEntries(nodeID) {
+		if entry.RestoredUntil.IsZero() {
+			continue
+		}
+		if now.After(entry.RestoredUntil) || isMapped(instance, volumeID) {
+			d.inFlight.Del(nodeID, volumeID)
+		}
+	}
+}
+
+func isMapped(instance *types.Instance, volumeID string) bool {
+	for _, blockDevice := range instance.BlockDeviceMappings {
+		if blockDevice.Ebs != nil && aws.ToString(blockDevice.Ebs.VolumeId) == volumeID {
+			return true
+		}
+	}
+	return false
+}
+
 func (d *deviceManager) newBlockDevice(instance *types.Instance, volumeID string, path string, isAlreadyAssigned bool, cardIndex *int32) *Device {
 	device := &Device{
 		Instance:          instance,
@@ -296,6 +350,8 @@ func (d *deviceManager) release(device *Device) error {
 // getDeviceNamesInUse returns the device to volume ID mapping
 // the mapping includes both already attached and being attached volumes.
 func (d *deviceManager) getDeviceNamesInUse(instance *types.Instance) map[string]string {
+	d.pruneRestoredDevices(instance)
+
 	nodeID := aws.ToString(instance.InstanceId)
 	inUse := map[string]string{}
 	for _, blockDevice := range instance.BlockDeviceMappings {
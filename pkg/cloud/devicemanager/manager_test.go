@@ -19,6 +19,7 @@ package devicemanager
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -224,6 +225,46 @@ func TestReleaseDevice(t *testing.T) {
 	}
 }
 
+func TestRestoreDevice(t *testing.T) {
+	dm := NewDeviceManager()
+	fakeInstance := newFakeInstance("instance-1", "vol-1", "/dev/xvdbc")
+	dm.RestoreDevice("instance-1", "vol-restored", deviceNames[0], nil)
+
+	// Other volumes should not be assigned the restored device name
+	dev, err := dm.NewDevice(fakeInstance, "vol-2", new(sync.Map), 1)
+	assertDevice(t, dev, false /*IsAlreadyAssigned*/, err)
+	if dev.Path == deviceNames[0] {
+		t.Fatalf("Expected a device name other than the restored %v", deviceNames[0])
+	}
+	dev.Release(false)
+
+	// The restored volume should get its device name back, and release it
+	dev, err = dm.NewDevice(fakeInstance, "vol-restored", new(sync.Map), 1)
+	assertDevice(t, dev, true /*IsAlreadyAssigned*/, err)
+	if dev.Path != deviceNames[0] {
+		t.Fatalf("Expected restored path %v, got %v", deviceNames[0], dev.Path)
+	}
+	dev.Release(false)
+	dev, err = dm.GetDevice(fakeInstance, "vol-restored")
+	assertDevice(t, dev, false /*IsAlreadyAssigned*/, err)
+
+	// Restored device names should be dropped once the instance reports the attachment
+	dm.RestoreDevice("instance-1", "vol-1", "/dev/xvdbc", nil)
+	dm.RestoreDevice("instance-1", "vol-3", deviceNames[1], nil)
+	dev, err = dm.GetDevice(fakeInstance, "vol-1")
+	assertDevice(t, dev, true /*IsAlreadyAssigned*/, err)
+	d := dm.(*deviceManager)
+	if entries := d.inFlight.GetEntries("instance-1"); len(entries) != 1 {
+		t.Fatalf("Expected only the restored entry of vol-3, got %v", entries)
+	}
+
+	// And once they expire
+	entry, _ := d.inFlight.GetEntry("instance-1", "vol-3")
+	d.inFlight.Restore("instance-1", "vol-3", entry.DeviceName, nil, time.Now().Add(-time.Second))
+	dev, err = dm.GetDevice(fakeInstance, "vol-3")
+	assertDevice(t, dev, false /*IsAlreadyAssigned*/, err)
+}
+
 func newFakeInstance(instanceID, volumeID, devicePath string) *types.Instance {
 	return &types.Instance{
 		InstanceId: aws.String(instanceID),
@@ -0,0 +1,133 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"k8s.io/klog/v2"
+)
+
+// Maximum throughput of the volume types whose throughput is not provisioned, in MB/s.
+// Source: https://docs.aws.amazon.com/ebs/latest/userguide/ebs-volume-types.html
+const (
+	gp2MaxThroughputMBps      = 250
+	st1MaxThroughputMBps      = 500
+	sc1MaxThroughputMBps      = 250
+	standardMaxThroughputMBps = 90
+	io1MaxThroughputMBps      = 1000
+	io2MaxThroughputMBps      = 4000
+	// provisionedIOPSPerMBps is the number of IOPS of an io1 or io2 volume per MB/s of throughput,
+	// with the 256 KiB I/O size at which the volume types reach their maximum throughput.
+	provisionedIOPSPerMBps = 4
+)
+
+// EBSBandwidth compares the EBS-optimized bandwidth of an instance with the throughput of the volumes attached to it.
+type EBSBandwidth struct {
+	InstanceType string
+	// MaximumMBps is the maximum EBS-optimized throughput of the instance type, or 0 if it is unknown.
+	MaximumMBps int32
+	// AttachedMBps is the sum of the maximum throughput of the volumes attached to the instance.
+	AttachedMBps int32
+}
+
+// Oversubscribed returns true if the volumes attached to the instance can together exceed its EBS bandwidth.
+func (b *EBSBandwidth) Oversubscribed() bool {
+	return b.MaximumMBps > 0 && b.AttachedMBps > b.MaximumMBps
+}
+
+// GetEBSBandwidth returns the EBS-optimized bandwidth of an instance and the throughput of its attached volumes.
+func (c *cloud) GetEBSBandwidth(ctx context.Context, nodeID string) (*EBSBandwidth, error) {
+	instance, err := c.getInstance(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	bandwidth := &EBSBandwidth{
+		InstanceType: string(instance.InstanceType),
+		MaximumMBps:  c.getMaxEBSThroughput(ctx, string(instance.InstanceType)),
+	}
+
+	volumeIDs := make([]string, 0, len(instance.BlockDeviceMappings))
+	for _, m := range instance.BlockDeviceMappings {
+		if m.Ebs != nil && m.Ebs.VolumeId != nil {
+			volumeIDs = append(volumeIDs, *m.Ebs.VolumeId)
+		}
+	}
+	if len(volumeIDs) == 0 {
+		return bandwidth, nil
+	}
+	disks, err := c.ListDisks(ctx, volumeIDs, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not describe volumes attached to %s: %w", nodeID, err)
+	}
+	for _, disk := range disks {
+		bandwidth.AttachedMBps += volumeMaxThroughputMBps(disk)
+	}
+	return bandwidth, nil
+}
+
+// getMaxEBSThroughput returns the maximum EBS-optimized throughput of an instance type in MB/s, using a cache to
+// avoid repeated API calls. It returns 0 if the instance type is not EBS-optimized or could not be described.
+func (c *cloud) getMaxEBSThroughput(ctx context.Context, instanceType string) int32 {
+	if val, ok := c.ebsThroughputCache.Get(instanceType); ok {
+		return *val
+	}
+
+	resp, err := c.ec2.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []types.InstanceType{types.InstanceType(instanceType)},
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to describe instance type, EBS bandwidth unknown", "instanceType", instanceType)
+		return 0
+	}
+
+	var throughput int32
+	if len(resp.InstanceTypes) > 0 {
+		if info := resp.InstanceTypes[0].EbsInfo; info != nil && info.EbsOptimizedInfo != nil {
+			throughput = int32(aws.ToFloat64(info.EbsOptimizedInfo.MaximumThroughputInMBps))
+		}
+	}
+	c.ebsThroughputCache.Set(instanceType, &throughput)
+	return throughput
+}
+
+// volumeMaxThroughputMBps returns the maximum throughput of a volume in MB/s.
+func volumeMaxThroughputMBps(disk *Disk) int32 {
+	switch disk.VolumeType {
+	case VolumeTypeGP3:
+		return disk.Throughput
+	case VolumeTypeGP2:
+		return gp2MaxThroughputMBps
+	case VolumeTypeIO1:
+		return min(disk.IOPS/provisionedIOPSPerMBps, io1MaxThroughputMBps)
+	case VolumeTypeIO2:
+		return min(disk.IOPS/provisionedIOPSPerMBps, io2MaxThroughputMBps)
+	case VolumeTypeST1:
+		return st1MaxThroughputMBps
+	case VolumeTypeSC1:
+		return sc1MaxThroughputMBps
+	case VolumeTypeStandard:
+		return standardMaxThroughputMBps
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+)
+
+// awsErrorSentinels maps well-understood EC2 error codes to the sentinel errors that EC2 errors with these codes
+// wrap. Throttling error codes are not listed, they wrap ErrThrottled.
+// See https://docs.aws.amazon.com/AWSEC2/latest/APIReference/errors-overview.html
+var awsErrorSentinels = map[string]error{
+	// Transient service errors
+	"InternalError":      ErrServiceUnavailable,
+	"ServiceUnavailable": ErrServiceUnavailable,
+	"Unavailable":        ErrServiceUnavailable,
+
+	// Capacity and quota errors
+	"InsufficientVolumeCapacity":          ErrInsufficientCapacity,
+	"VolumeLimitExceeded":                 ErrLimitExceeded,
+	"SnapshotLimitExceeded":               ErrLimitExceeded,
+	"ConcurrentSnapshotLimitExceeded":     ErrLimitExceeded,
+	"AttachmentLimitExceeded":             ErrLimitExceeded,
+	"MaxIOPSLimitExceeded":                ErrLimitExceeded,
+	"ResourceLimitExceeded":               ErrLimitExceeded,
+	"VolumeModificationSizeLimitExceeded": ErrLimitExceeded,
+
+	// Credential and permission errors
+	"AuthFailure":           ErrUnauthorized,
+	"UnauthorizedOperation": ErrUnauthorized,
+	"OptInRequired":         ErrUnauthorized,
+	"Blocked":               ErrUnauthorized,
+
+	// Missing resources
+	"InvalidVolume.NotFound":             ErrNotFound,
+	"InvalidSnapshot.NotFound":           ErrNotFound,
+	"InvalidInstanceID.NotFound":         ErrNotFound,
+	"InvalidAttachment.NotFound":         ErrNotFound,
+	"InvalidVolumeModification.NotFound": ErrNotFound,
+
+	// Invalid requests
+	"InvalidParameter":            ErrInvalidArgument,
+	"InvalidParameterValue":       ErrInvalidArgument,
+	"InvalidParameterCombination": ErrInvalidArgument,
+	"MissingParameter":            ErrInvalidArgument,
+	"InvalidZone.NotFound":        ErrInvalidArgument,
+	"InvalidKMSKey.Id":            ErrInvalidArgument,
+	"Unsupported":                 ErrUnsupported,
+
+	// Resources in the wrong state for the request
+	"IncorrectState":             ErrIncorrectState,
+	"IncorrectModificationState": ErrIncorrectState,
+	"VolumeInUse":                ErrIncorrectState,
+	"InvalidVolume.ZoneMismatch": ErrIncorrectState,
+
+	"IdempotentParameterMismatch": ErrIdempotentParameterMismatch,
+}
+
+// classifiedError is an EC2 error that also wraps the sentinel error of its error code, so that callers can check
+// the kind of failure with errors.Is instead of matching error codes. Its message is the one of the EC2 error.
+type classifiedError struct {
+	err      error
+	sentinel error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() []error {
+	return []error{e.err, e.sentinel}
+}
+
+// ClassifyError returns err wrapped with the sentinel error of its EC2 error code, or err itself if it is not an EC2
+// error with a well-understood code or is already classified. Errors returned by Cloud are already classified.
+func ClassifyError(err error) error {
+	var classified *classifiedError
+	if err == nil || errors.As(err, &classified) {
+		return err
+	}
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	sentinel, ok := awsErrorSentinels[apiErr.ErrorCode()]
+	if _, isThrottleError := retry.DefaultThrottleErrorCodes[apiErr.ErrorCode()]; isThrottleError {
+		sentinel, ok = ErrThrottled, true
+	}
+	if !ok {
+		return err
+	}
+	return &classifiedError{err: err, sentinel: sentinel}
+}
+
+// Quotas of QuotaExceededError, as reported by the QuotaExceeded metric.
+const (
+	QuotaStorage              = "storage"
+	QuotaIOPS                 = "iops"
+	QuotaStorageModifications = "storage_modifications"
+	QuotaSnapshots            = "snapshots"
+	QuotaConcurrentSnapshots  = "concurrent_snapshots"
+)
+
+// awsErrorQuotas maps the EC2 error codes of exceeded account quotas to their quota.
+var awsErrorQuotas = map[string]string{
+	"VolumeLimitExceeded":                 QuotaStorage,
+	"MaxIOPSLimitExceeded":                QuotaIOPS,
+	"VolumeModificationSizeLimitExceeded": QuotaStorageModifications,
+	"SnapshotLimitExceeded":               QuotaSnapshots,
+	"ConcurrentSnapshotLimitExceeded":     QuotaConcurrentSnapshots,
+}
+
+// volumeTypeQuotaNames are the names of the volume types in the names of their quotas in Service Quotas.
+var volumeTypeQuotaNames = map[string]string{
+	VolumeTypeGP3:      "General Purpose SSD (gp3)",
+	VolumeTypeGP2:      "General Purpose SSD (gp2)",
+	VolumeTypeIO1:      "Provisioned IOPS SSD (io1)",
+	VolumeTypeIO2:      "Provisioned IOPS SSD (io2)",
+	VolumeTypeST1:      "Throughput Optimized HDD (st1)",
+	VolumeTypeSC1:      "Cold HDD (sc1)",
+	VolumeTypeStandard: "Magnetic (standard)",
+}
+
+// QuotaExceededError is an EC2 error caused by an exceeded account quota. It wraps ErrLimitExceeded.
+type QuotaExceededError struct {
+	// Quota is the exceeded quota, one of the Quota constants.
+	Quota string
+	// VolumeType is the type of the volume the request was for, empty for snapshot quotas.
+	VolumeType string
+	// Err is the EC2 error.
+	Err error
+}
+
+// limitExceededError returns err, an EC2 error for a request about a volume of volumeType that exceeded a limit, as a
+// QuotaExceededError counted by the QuotaExceeded metric, or wrapped in ErrLimitExceeded if it is not caused by an
+// account quota.
+func limitExceededError(err error, volumeType string) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("%w: %w", ErrLimitExceeded, err)
+	}
+	quota, ok := awsErrorQuotas[apiErr.ErrorCode()]
+	if !ok {
+		return fmt.Errorf("%w: %w", ErrLimitExceeded, err)
+	}
+	if quota == QuotaSnapshots || quota == QuotaConcurrentSnapshots {
+		volumeType = ""
+	}
+	metrics.Recorder().IncreaseCount(metrics.QuotaExceeded, metrics.QuotaExceededHelpText, map[string]string{"quota": quota, "volume_type": volumeType})
+	return &QuotaExceededError{Quota: quota, VolumeType: volumeType, Err: err}
+}
+
+// QuotaName returns the name of the exceeded quota in Service Quotas.
+func (e *QuotaExceededError) QuotaName() string {
+	volumes := "volumes"
+	if name, ok := volumeTypeQuotaNames[e.VolumeType]; ok {
+		volumes = name + " volumes"
+	}
+	switch e.Quota {
+	case QuotaStorage:
+		return "Storage for " + volumes + ", in TiB"
+	case QuotaIOPS:
+		return "IOPS for " + volumes
+	case QuotaStorageModifications:
+		return "Storage modifications for " + volumes + ", in TiB"
+	case QuotaSnapshots:
+		return "Snapshots per Region"
+	default:
+		return "Concurrent snapshots per volume"
+	}
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("EC2 quota %q exceeded: %v", e.QuotaName(), e.Err)
+}
+
+func (e *QuotaExceededError) Unwrap() []error {
+	return []error{e.Err, ErrLimitExceeded}
+}
@@ -19,16 +19,31 @@ package cloud
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/smithy-go"
 	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
 	"k8s.io/klog/v2"
 )
 
+// ClassifyErrorsMiddleware wraps the errors of EC2 calls with the sentinel error of their error code, see
+// ClassifyError. It is added before the other middlewares so that they see the unclassified error.
+func ClassifyErrorsMiddleware() func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("ClassifyErrorsMiddleware", func(ctx context.Context, input middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			output, metadata, err := next.HandleInitialize(ctx, input)
+			return output, metadata, ClassifyError(err)
+		}), middleware.Before)
+	}
+}
+
 // RecordRequestsMiddleware is added to the Complete chain; called after any request.
 func RecordRequestsMiddleware(deprecatedMetrics bool) func(*middleware.Stack) error {
 	return func(stack *middleware.Stack) error {
@@ -58,7 +73,7 @@ func RecordRequestsMiddleware(deprecatedMetrics bool) func(*middleware.Stack) er
 				}
 			} else {
 				duration := time.Since(start).Seconds()
-				metrics.Recorder().ObserveHistogram(metrics.APIRequestDuration, metrics.APIRequestDurationHelpText, duration, labels, nil)
+				metrics.Recorder().ObserveHistogramWithContext(ctx, metrics.APIRequestDuration, metrics.APIRequestDurationHelpText, duration, labels, nil)
 				if deprecatedMetrics {
 					metrics.Recorder().ObserveHistogram(metrics.DeprecatedAPIRequestDuration, metrics.DeprecatedAPIRequestDurationHelpText, duration, labels, nil)
 				}
@@ -68,6 +83,23 @@ func RecordRequestsMiddleware(deprecatedMetrics bool) func(*middleware.Stack) er
 	}
 }
 
+// CountThrottlesMiddleware counts the attempts of EC2 calls that were throttled in throttles, which the controller
+// reads to announce degraded provisioning while EC2 throttles it.
+func CountThrottlesMiddleware(throttles *atomic.Int64) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("CountThrottlesMiddleware", func(ctx context.Context, input middleware.FinalizeInput, next middleware.FinalizeHandler) (output middleware.FinalizeOutput, metadata middleware.Metadata, err error) {
+			output, metadata, err = next.HandleFinalize(ctx, input)
+			var apiErr smithy.APIError
+			if errors.As(err, &apiErr) {
+				if _, isThrottleError := retry.DefaultThrottleErrorCodes[apiErr.ErrorCode()]; isThrottleError {
+					throttles.Add(1)
+				}
+			}
+			return output, metadata, err
+		}), middleware.After)
+	}
+}
+
 // LogServerErrorsMiddleware is a middleware that logs server errors received when attempting to contact the AWS API
 // A specialized middleware is used instead of the SDK's built-in retry logging to allow for customizing the verbosity
 // of throttle errors vs server/unknown errors, to prevent flooding the logs with throttle error.
@@ -81,12 +113,12 @@ func LogServerErrorsMiddleware() func(*middleware.Stack) error {
 					if _, isThrottleError := retry.DefaultThrottleErrorCodes[apiErr.ErrorCode()]; isThrottleError {
 						// Only log throttle errors under a high verbosity as we expect to see many of them
 						// under normal bursty/high-TPS workloads
-						klog.V(4).ErrorS(apiErr, "Throttle error from AWS API")
+						klog.V(4).ErrorS(apiErr, "Throttle error from AWS API", "correlationIDs", util.CorrelationIDs(ctx))
 					} else {
-						klog.V(3).ErrorS(apiErr, "Error from AWS API")
+						klog.V(3).ErrorS(apiErr, "Error from AWS API", "correlationIDs", util.CorrelationIDs(ctx))
 					}
 				} else {
-					klog.ErrorS(err, "Unknown error attempting to contact AWS API")
+					klog.ErrorS(err, "Unknown error attempting to contact AWS API", "correlationIDs", util.CorrelationIDs(ctx))
 				}
 			}
 			return output, metadata, err
@@ -94,6 +126,59 @@ func LogServerErrorsMiddleware() func(*middleware.Stack) error {
 	}
 }
 
+// CorrelationIDUserAgentMiddleware appends the correlation ID of the CSI request(s) that caused an EC2 call to its
+// User-Agent header, so that CloudTrail entries can be matched up with driver logs. When a call serves a batch of
+// requests, only the first ID is sent (followed by the number of other requests) to keep the header short.
+func CorrelationIDUserAgentMiddleware() func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Build.Add(middleware.BuildMiddlewareFunc("CorrelationIDUserAgentMiddleware", func(ctx context.Context, input middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+			if req, ok := input.Request.(*smithyhttp.Request); ok {
+				if segment := correlationUserAgentSegment(util.CorrelationIDs(ctx)); segment != "" {
+					req.Header.Set("User-Agent", req.Header.Get("User-Agent")+" "+segment)
+				}
+			}
+			return next.HandleBuild(ctx, input)
+		}), middleware.After)
+	}
+}
+
+func correlationUserAgentSegment(ids []string) string {
+	switch len(ids) {
+	case 0:
+		return ""
+	case 1:
+		return "corr/" + ids[0]
+	default:
+		return fmt.Sprintf("corr/%s+%d", ids[0], len(ids)-1)
+	}
+}
+
+// SubsystemUserAgentMiddleware appends the subsystem of the driver that caused an EC2 call (provision, attach,
+// snapshot or modify) to its User-Agent header, so that API usage can be attributed to each subsystem in CloudTrail.
+func SubsystemUserAgentMiddleware() func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Build.Add(middleware.BuildMiddlewareFunc("SubsystemUserAgentMiddleware", func(ctx context.Context, input middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+			if req, ok := input.Request.(*smithyhttp.Request); ok {
+				if subsystem := util.SubsystemFromContext(ctx); subsystem != "" {
+					req.Header.Set("User-Agent", req.Header.Get("User-Agent")+" subsystem/"+string(subsystem))
+				}
+			}
+			return next.HandleBuild(ctx, input)
+		}), middleware.After)
+	}
+}
+
+// OperationStepMiddleware sets the step of the RPC that made an EC2 call to the call while it is in flight, including
+// its retries, so that the in-flight operations of the controller show the EC2 calls they are blocked on.
+func OperationStepMiddleware() func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("OperationStepMiddleware", func(ctx context.Context, input middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			defer util.SetOperationStep(ctx, "EC2 "+awsmiddleware.GetOperationName(ctx))()
+			return next.HandleFinalize(ctx, input)
+		}), middleware.Before)
+	}
+}
+
 func createLabels(ctx context.Context) map[string]string {
 	operationName := awsmiddleware.GetOperationName(ctx)
 	if operationName == "" {
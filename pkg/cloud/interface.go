@@ -16,6 +16,7 @@ package cloud
 
 import (
 	"context"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -24,23 +25,39 @@ import (
 type Cloud interface {
 	CreateDisk(ctx context.Context, volumeName string, diskOptions *DiskOptions) (disk *Disk, err error)
 	DeleteDisk(ctx context.Context, volumeID string) (success bool, err error)
+	ListPendingDeletionDisks(ctx context.Context, tags map[string]string) (map[string]time.Time, error)
 	AttachDisk(ctx context.Context, volumeID string, nodeID string) (devicePath string, err error)
 	DetachDisk(ctx context.Context, volumeID string, nodeID string) (err error)
+	CheckMultiAttachSupport(ctx context.Context, nodeID string) error
+	CheckBlockExpressSupport(ctx context.Context, nodeID string) error
+	CheckAttachmentZone(ctx context.Context, volumeID string, nodeID string) error
+	GetEBSBandwidth(ctx context.Context, nodeID string) (*EBSBandwidth, error)
 	ModifyTags(ctx context.Context, volumeID string, tagOptions ModifyTagsOptions) (err error)
 	ResizeOrModifyDisk(ctx context.Context, volumeID string, newSizeBytes int64, options *ModifyDiskOptions) (newSize int32, err error)
 	WaitForAttachmentState(ctx context.Context, expectedState types.VolumeAttachmentState, volumeID string, expectedInstance string, expectedDevice string, alreadyAssigned bool, expectedCardIndex *int32) (*types.VolumeAttachment, error)
 	IsVolumeInitialized(ctx context.Context, volumeID string) (bool, error)
+	GetVolumeHealth(ctx context.Context, volumeIDs []string) (map[string]*VolumeHealth, error)
+	EnableVolumeIO(ctx context.Context, volumeID string) error
 	GetDiskByName(ctx context.Context, name string, capacityBytes int64) (disk *Disk, err error)
 	GetDiskByID(ctx context.Context, volumeID string) (disk *Disk, err error)
+	ListDisks(ctx context.Context, volumeIDs []string, tags map[string]string) ([]*Disk, error)
+	ListDisksPage(ctx context.Context, tags map[string]string, maxResults int32, nextToken string) (*ListDisksResponse, error)
 	GetVolumeIDByNodeAndDevice(ctx context.Context, nodeID string, deviceName string) (volumeID string, err error)
 	CreateSnapshot(ctx context.Context, volumeID string, snapshotOptions *SnapshotOptions) (snapshot *Snapshot, err error)
 	DeleteSnapshot(ctx context.Context, snapshotID string) (success bool, err error)
 	GetSnapshotByName(ctx context.Context, name string) (snapshot *Snapshot, err error)
 	GetSnapshotByID(ctx context.Context, snapshotID string) (snapshot *Snapshot, err error)
 	ListSnapshots(ctx context.Context, volumeID string, maxResults int32, nextToken string) (listSnapshotsResponse *ListSnapshotsResponse, err error)
+	ListSnapshotsByTags(ctx context.Context, tags map[string]string) ([]*Snapshot, error)
 	EnableFastSnapshotRestores(ctx context.Context, availabilityZones []string, snapshotID string) (*ec2.EnableFastSnapshotRestoresOutput, error)
 	AvailabilityZones(ctx context.Context) (map[string]struct{}, error)
+	GetStorageUsage(ctx context.Context) (*StorageUsage, error)
+	GetZoneCapacity(ctx context.Context, volumeType string) (map[string]ZoneCapacity, error)
 	DryRun(ctx context.Context) error
 	GetInstancesPatching(ctx context.Context, nodeIDs []string) ([]*types.Instance, error)
 	LockSnapshot(ctx context.Context, lockOptions *SnapshotLockOptions) (err error)
+	ResolveKMSKey(ctx context.Context, keyID string) (arn string, err error)
+	SetClientTokenStore(ctx context.Context, store ClientTokenStore) error
+	BatchQueueLen() int
+	Throttles() int64
 }
@@ -0,0 +1,104 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// kmsKeyCacheForgetDelay is how long the resolution of a KMS key that is not used is kept.
+	kmsKeyCacheForgetDelay = 1 * time.Hour
+	// kmsKeyResolutionTTL is how long the resolution of a KMS key is trusted before the key is described again, so
+	// that aliases pointed to another key and disabled keys are noticed.
+	kmsKeyResolutionTTL = 10 * time.Minute
+)
+
+// kmsKeyResolution is the key ARN a KMS key ID, alias or ARN was resolved to.
+type kmsKeyResolution struct {
+	arn        string
+	resolvedAt time.Time
+}
+
+// ResolveKMSKey returns the ARN of the KMS key of the given key ID, key ARN, alias name or alias ARN, after checking
+// that the key can encrypt EBS volumes. EC2 only validates the key of a volume after CreateVolume returned, and
+// deletes the volume when the key is unusable, which the external-provisioner retries forever. Keys of other
+// accounts must be given by ARN, and keys the driver is not allowed to describe are returned unchanged.
+func (c *cloud) ResolveKMSKey(ctx context.Context, keyID string) (string, error) {
+	if keyID == "" {
+		return keyID, nil
+	}
+	if err := c.checkPartitionARN("KMS key", keyID); err != nil {
+		return "", err
+	}
+	if c.kms == nil {
+		return keyID, nil
+	}
+	if r, ok := c.kmsKeys.Get(keyID); ok && time.Since(r.resolvedAt) < kmsKeyResolutionTTL {
+		return r.arn, nil
+	}
+
+	response, err := c.kms.DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: aws.String(keyID)})
+	if err != nil {
+		switch {
+		case isAWSError(err, "NotFoundException"):
+			return "", fmt.Errorf("%w: KMS key %q does not exist", ErrInvalidArgument, keyID)
+		case isAWSError(err, "AccessDeniedException"):
+			// EC2 may still be allowed to use the key, grant kms:DescribeKey to the driver to validate it
+			klog.InfoS("Not allowed to describe KMS key, using it as is", "kmsKeyId", keyID, "err", err)
+			c.kmsKeys.Set(keyID, &kmsKeyResolution{arn: keyID, resolvedAt: time.Now()})
+			return keyID, nil
+		default:
+			return "", fmt.Errorf("could not describe KMS key %q: %w", keyID, err)
+		}
+	}
+
+	key := response.KeyMetadata
+	if key == nil || aws.ToString(key.Arn) == "" {
+		return "", fmt.Errorf("KMS key %q was described without its ARN", keyID)
+	}
+	if err := checkKMSKey(key); err != nil {
+		return "", fmt.Errorf("%w: KMS key %q cannot encrypt EBS volumes: %w", ErrInvalidArgument, keyID, err)
+	}
+	arn := aws.ToString(key.Arn)
+	if arn != keyID {
+		klog.V(4).InfoS("Resolved KMS key", "kmsKeyId", keyID, "arn", arn)
+	}
+	c.kmsKeys.Set(keyID, &kmsKeyResolution{arn: arn, resolvedAt: time.Now()})
+	return arn, nil
+}
+
+// checkKMSKey returns why the key cannot encrypt EBS volumes, which only support enabled symmetric encryption keys.
+func checkKMSKey(key *kmstypes.KeyMetadata) error {
+	if key.KeyState != kmstypes.KeyStateEnabled {
+		return fmt.Errorf("its state is %s", key.KeyState)
+	}
+	if key.KeyUsage != "" && key.KeyUsage != kmstypes.KeyUsageTypeEncryptDecrypt {
+		return fmt.Errorf("its usage is %s, not %s", key.KeyUsage, kmstypes.KeyUsageTypeEncryptDecrypt)
+	}
+	if key.KeySpec != "" && key.KeySpec != kmstypes.KeySpecSymmetricDefault {
+		return fmt.Errorf("its key spec is %s, not %s", key.KeySpec, kmstypes.KeySpecSymmetricDefault)
+	}
+	return nil
+}
@@ -111,3 +111,10 @@ func GetCardCount(instanceType string) int {
 	}
 	return 1
 }
+
+// IsNitro returns whether the given instance type is built on the Nitro System.
+// Instance types missing from the table of non-Nitro types are assumed to be Nitro-based.
+func IsNitro(instanceType string) bool {
+	_, nonNitro := nonNitroInstanceTypes[instanceType]
+	return !nonNitro
+}
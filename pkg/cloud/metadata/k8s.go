@@ -43,56 +43,65 @@ const (
 
 type KubernetesAPIClient func() (kubernetes.Interface, error)
 
-func DefaultKubernetesAPIClient(kubeconfig string) KubernetesAPIClient {
-	return func() (clientset kubernetes.Interface, err error) {
-		var config *rest.Config
-		if kubeconfig != "" {
-			config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-				&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
-				&clientcmd.ConfigOverrides{},
-			).ClientConfig()
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			// creates the in-cluster config
-			config, err = rest.InClusterConfig()
-			if err != nil {
-				if errors.Is(err, os.ErrNotExist) {
-					klog.InfoS("InClusterConfig failed to read token file, retrieving file from sandbox mount point")
-					// CONTAINER_SANDBOX_MOUNT_POINT env is set upon container creation in containerd v1.6+
-					// it provides the absolute host path to the container volume.
-					sandboxMountPoint := os.Getenv("CONTAINER_SANDBOX_MOUNT_POINT")
-					if sandboxMountPoint == "" {
-						return nil, errors.New("CONTAINER_SANDBOX_MOUNT_POINT environment variable is not set")
-					}
-
-					tokenFile := filepath.Join(sandboxMountPoint, "var", "run", "secrets", "kubernetes.io", "serviceaccount", "token")
-					rootCAFile := filepath.Join(sandboxMountPoint, "var", "run", "secrets", "kubernetes.io", "serviceaccount", "ca.crt")
-
-					token, tokenErr := os.ReadFile(tokenFile) // #nosec G703 -- tokenFile is built via filepath.Join with controlled path components
-					if tokenErr != nil {
-						return nil, tokenErr
-					}
-
-					tlsClientConfig := rest.TLSClientConfig{}
-					if _, certErr := cert.NewPool(rootCAFile); certErr != nil {
-						return nil, fmt.Errorf("expected to load root CA config from %s, but got err: %w", rootCAFile, certErr)
-					} else {
-						tlsClientConfig.CAFile = rootCAFile
-					}
-
-					config = &rest.Config{
-						Host:            "https://" + net.JoinHostPort(os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")),
-						TLSClientConfig: tlsClientConfig,
-						BearerToken:     string(token),
-						BearerTokenFile: tokenFile,
-					}
+// DefaultKubernetesAPIConfig returns the configuration of the clients of the Kubernetes API: the kubeconfig file if
+// set, the in-cluster configuration otherwise.
+func DefaultKubernetesAPIConfig(kubeconfig string) (config *rest.Config, err error) {
+	if kubeconfig != "" {
+		config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+			&clientcmd.ConfigOverrides{},
+		).ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// creates the in-cluster config
+		config, err = rest.InClusterConfig()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				klog.InfoS("InClusterConfig failed to read token file, retrieving file from sandbox mount point")
+				// CONTAINER_SANDBOX_MOUNT_POINT env is set upon container creation in containerd v1.6+
+				// it provides the absolute host path to the container volume.
+				sandboxMountPoint := os.Getenv("CONTAINER_SANDBOX_MOUNT_POINT")
+				if sandboxMountPoint == "" {
+					return nil, errors.New("CONTAINER_SANDBOX_MOUNT_POINT environment variable is not set")
+				}
+
+				tokenFile := filepath.Join(sandboxMountPoint, "var", "run", "secrets", "kubernetes.io", "serviceaccount", "token")
+				rootCAFile := filepath.Join(sandboxMountPoint, "var", "run", "secrets", "kubernetes.io", "serviceaccount", "ca.crt")
+
+				token, tokenErr := os.ReadFile(tokenFile) // #nosec G703 -- tokenFile is built via filepath.Join with controlled path components
+				if tokenErr != nil {
+					return nil, tokenErr
+				}
+
+				tlsClientConfig := rest.TLSClientConfig{}
+				if _, certErr := cert.NewPool(rootCAFile); certErr != nil {
+					return nil, fmt.Errorf("expected to load root CA config from %s, but got err: %w", rootCAFile, certErr)
 				} else {
-					return nil, err
+					tlsClientConfig.CAFile = rootCAFile
+				}
+
+				config = &rest.Config{
+					Host:            "https://" + net.JoinHostPort(os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")),
+					TLSClientConfig: tlsClientConfig,
+					BearerToken:     string(token),
+					BearerTokenFile: tokenFile,
 				}
+			} else {
+				return nil, err
 			}
 		}
+	}
+	return config, nil
+}
+
+func DefaultKubernetesAPIClient(kubeconfig string) KubernetesAPIClient {
+	return func() (clientset kubernetes.Interface, err error) {
+		config, err := DefaultKubernetesAPIConfig(kubeconfig)
+		if err != nil {
+			return nil, err
+		}
 		config.AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
 		config.ContentType = "application/vnd.kubernetes.protobuf"
 		// creates the clientset
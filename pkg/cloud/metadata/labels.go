@@ -17,6 +17,7 @@ package metadata
 import (
 	"context"
 	json "encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -26,6 +27,7 @@ import (
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/kubernetes-csi/csi-lib-utils/leaderelection"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/nodeapi"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -46,6 +48,12 @@ const (
 
 	// numWorkersPatchLabels is the number of worker threads patching node labels.
 	numWorkersPatchLabels = 10
+
+	// nodeAPITimeout bounds each call to the node API of a node plugin.
+	nodeAPITimeout = 5 * time.Second
+
+	// nodeNameIndex indexes the node plugin Pods by the name of their node.
+	nodeNameIndex = "nodeName"
 )
 
 // Initialized in ContinuousUpdateLabelsLeaderElection (depends on driver name).
@@ -74,14 +82,21 @@ func initVariables() {
 	})
 }
 
+// NodeAPIOptions locate the node API of the node plugin Pods, from which the volumes attached to each node are
+// counted. A Port of 0 counts them from EC2 only.
+type NodeAPIOptions struct {
+	Port             int
+	NodePodsSelector string
+}
+
 // ContinuousUpdateLabelsLeaderElection uses leader election so that only one controller pod calls continuousUpdateLabels().
-func ContinuousUpdateLabelsLeaderElection(clientset kubernetes.Interface, cloud cloud.Cloud, updateTime time.Duration) error {
+func ContinuousUpdateLabelsLeaderElection(clientset kubernetes.Interface, cloud cloud.Cloud, updateTime time.Duration, nodeAPI NodeAPIOptions) error {
 	initVariables()
 	var (
 		lockName = "metadata-labeler-" + util.GetDriverName()
 	)
 	le := leaderelection.NewLeaderElection(clientset, lockName, func(ctx context.Context) {
-		err := continuousUpdateLabels(ctx, clientset, cloud, updateTime)
+		err := continuousUpdateLabels(ctx, clientset, cloud, updateTime, nodeAPI)
 		if err != nil {
 			klog.ErrorS(err, "Failed to patch node labels with volume/ENI count")
 			return
@@ -99,7 +114,7 @@ func ContinuousUpdateLabelsLeaderElection(clientset kubernetes.Interface, cloud
 // `updateTime` minutes and uses an informer to update the labels of new nodes that join the cluster.
 // A PV informer is also used to keep track of CSI managed volumes when updating labels to avoid
 // double counting.
-func continuousUpdateLabels(ctx context.Context, k8sClient kubernetes.Interface, cloud cloud.Cloud, updateTime time.Duration) error {
+func continuousUpdateLabels(ctx context.Context, k8sClient kubernetes.Interface, cloud cloud.Cloud, updateTime time.Duration, nodeAPI NodeAPIOptions) error {
 	factory := informers.NewSharedInformerFactory(k8sClient, 0)
 	pvInformer := factory.Core().V1().PersistentVolumes().Informer()
 	err := pvInformer.AddIndexers(cache.Indexers{
@@ -109,8 +124,13 @@ func continuousUpdateLabels(ctx context.Context, k8sClient kubernetes.Interface,
 		klog.ErrorS(err, "Failed to add volume ID indexer")
 		return err
 	}
+	volumes, err := newNodeAPIVolumes(ctx, k8sClient, nodeAPI)
+	if err != nil {
+		klog.ErrorS(err, "Failed to watch the node plugin Pods")
+		return err
+	}
 	nodesInformer := factory.Core().V1().Nodes().Informer()
-	err = patchNewNodes(ctx, k8sClient, cloud, nodesInformer, pvInformer)
+	err = patchNewNodes(ctx, k8sClient, cloud, nodesInformer, pvInformer, volumes)
 	if err != nil {
 		klog.ErrorS(err, "Could not add event handler to informer to patch new nodes")
 		return err
@@ -118,13 +138,13 @@ func continuousUpdateLabels(ctx context.Context, k8sClient kubernetes.Interface,
 	factory.Start(ctx.Done())
 	factory.WaitForCacheSync(ctx.Done())
 
-	err = updateLabels(ctx, k8sClient, cloud, pvInformer)
+	err = updateLabels(ctx, k8sClient, cloud, pvInformer, volumes)
 	if err != nil {
 		klog.ErrorS(err, "Could not patch node labels with updated volume/ENI count")
 		return err
 	}
 	for range time.Tick(updateTime) {
-		err = updateLabels(ctx, k8sClient, cloud, pvInformer)
+		err = updateLabels(ctx, k8sClient, cloud, pvInformer, volumes)
 		if err != nil {
 			klog.ErrorS(err, "Could not patch node labels with updated volume/ENI count")
 			return err
@@ -155,6 +175,124 @@ func volumeIDIndexFunc(obj any) ([]string, error) {
 	return volumeIDs, nil
 }
 
+// nodeAPIVolumes lists the volumes attached to nodes from the node API of the node plugin Pod running on them.
+type nodeAPIVolumes struct {
+	client      *nodeapi.Client
+	podInformer cache.SharedIndexInformer
+}
+
+// newNodeAPIVolumes returns nil when the node API is disabled, otherwise it starts watching the node plugin Pods.
+func newNodeAPIVolumes(ctx context.Context, k8sClient kubernetes.Interface, o NodeAPIOptions) (*nodeAPIVolumes, error) {
+	if o.Port == 0 {
+		return nil, nil
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(k8sClient, 0, informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+		options.LabelSelector = o.NodePodsSelector
+	}))
+	podInformer := factory.Core().V1().Pods().Informer()
+	err := podInformer.AddIndexers(cache.Indexers{
+		nodeNameIndex: func(obj any) ([]string, error) {
+			if pod, ok := obj.(*v1.Pod); ok && pod.Spec.NodeName != "" {
+				return []string{pod.Spec.NodeName}, nil
+			}
+			return nil, nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	factory.Start(ctx.Done())
+	factory.WaitForCacheSync(ctx.Done())
+	return &nodeAPIVolumes{client: nodeapi.NewClient(o.Port, nodeAPITimeout), podInformer: podInformer}, nil
+}
+
+// attachedVolumes returns the IDs of the volumes attached to each node, by instance ID, for the nodes whose node
+// plugin is running and could list them. The other nodes are missing from the result.
+func (v *nodeAPIVolumes) attachedVolumes(ctx context.Context, nodes *v1.NodeList) map[string][]string {
+	if v == nil {
+		return nil
+	}
+	type result struct {
+		instanceID string
+		volumeIDs  []string
+	}
+	jobs := make(chan v1.Node, len(nodes.Items))
+	results := make(chan *result, len(nodes.Items))
+	for range min(len(nodes.Items), numWorkersPatchLabels) {
+		go func() {
+			for node := range jobs {
+				instanceID, err := parseProviderID(&node)
+				if err != nil {
+					results <- nil
+					continue
+				}
+				volumeIDs, err := v.nodeVolumes(ctx, node.Name)
+				if err != nil {
+					klog.V(4).InfoS("Could not get the volumes of the node from its node plugin, counting them from EC2", "node", node.Name, "err", err)
+					results <- nil
+					continue
+				}
+				results <- &result{instanceID: instanceID, volumeIDs: volumeIDs}
+			}
+		}()
+	}
+	for _, node := range nodes.Items {
+		jobs <- node
+	}
+	close(jobs)
+
+	attached := make(map[string][]string, len(nodes.Items))
+	for range len(nodes.Items) {
+		if r := <-results; r != nil {
+			attached[r.instanceID] = r.volumeIDs
+		}
+	}
+	return attached
+}
+
+// nodeVolumes returns the IDs of the volumes attached to the node from the node API of its running node plugin.
+func (v *nodeAPIVolumes) nodeVolumes(ctx context.Context, nodeName string) ([]string, error) {
+	pods, err := v.podInformer.GetIndexer().ByIndex(nodeNameIndex, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range pods {
+		pod, ok := obj.(*v1.Pod)
+		if !ok || pod.Status.Phase != v1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		list, err := v.client.Volumes(ctx, pod.Status.PodIP)
+		if err != nil {
+			return nil, err
+		}
+		if !list.AttachedVolumesListed {
+			return nil, fmt.Errorf("node plugin %s/%s cannot list the attached volumes", pod.Namespace, pod.Name)
+		}
+		volumeIDs := make([]string, 0, len(list.Volumes))
+		for _, volume := range list.Volumes {
+			volumeIDs = append(volumeIDs, volume.VolumeID)
+		}
+		return volumeIDs, nil
+	}
+	return nil, errors.New("no running node plugin Pod")
+}
+
+// getNonCSIManagedVolumeIDs returns the number of volumeIDs that are not the volume of a PV.
+func getNonCSIManagedVolumeIDs(pvInformer cache.SharedIndexInformer, volumeIDs []string) int {
+	nonCSIVolumes := len(volumeIDs)
+	for _, volumeID := range volumeIDs {
+		pvs, err := pvInformer.GetIndexer().ByIndex("volumeID", volumeID)
+		if err != nil {
+			klog.ErrorS(err, "Failed to query volume ID index", "volumeID", volumeID)
+			continue
+		}
+		if len(pvs) > 0 {
+			nonCSIVolumes -= 1
+		}
+	}
+	return nonCSIVolumes
+}
+
 func getNonCSIManagedVolumes(pvInformer cache.SharedIndexInformer, volumes []ec2types.InstanceBlockDeviceMapping) int {
 	nonCSIVolumes := len(volumes)
 	for _, vol := range volumes {
@@ -176,7 +314,7 @@ func getNonCSIManagedVolumes(pvInformer cache.SharedIndexInformer, volumes []ec2
 }
 
 // patchNewNodes patches metadata labels for new nodes that join the cluster.
-func patchNewNodes(ctx context.Context, clientset kubernetes.Interface, cloud cloud.Cloud, nodesInformer, pvInformer cache.SharedIndexInformer) error {
+func patchNewNodes(ctx context.Context, clientset kubernetes.Interface, cloud cloud.Cloud, nodesInformer, pvInformer cache.SharedIndexInformer, volumes *nodeAPIVolumes) error {
 	var handler cache.ResourceEventHandlerFuncs
 	handler.AddFunc = func(obj any) {
 		if nodeObj, ok := obj.(*v1.Node); ok {
@@ -184,7 +322,7 @@ func patchNewNodes(ctx context.Context, clientset kubernetes.Interface, cloud cl
 			node := &v1.NodeList{
 				Items: []v1.Node{*nodeObj},
 			}
-			err := updateMetadataEC2(ctx, clientset, cloud, node, pvInformer)
+			err := updateMetadataEC2(ctx, clientset, cloud, node, pvInformer, volumes)
 			if err != nil {
 				klog.ErrorS(err, "Unable to update ENI/Volume count on node labels", "node", node.Items[0].Name)
 			}
@@ -198,13 +336,13 @@ func patchNewNodes(ctx context.Context, clientset kubernetes.Interface, cloud cl
 	return nil
 }
 
-func updateLabels(ctx context.Context, k8sClient kubernetes.Interface, cloud cloud.Cloud, pvCache cache.SharedIndexInformer) error {
+func updateLabels(ctx context.Context, k8sClient kubernetes.Interface, cloud cloud.Cloud, pvCache cache.SharedIndexInformer, volumes *nodeAPIVolumes) error {
 	nodes, err := getNodes(ctx, k8sClient)
 	if err != nil {
 		klog.ErrorS(err, "Could not get nodes")
 		return err
 	}
-	err = updateMetadataEC2(ctx, k8sClient, cloud, nodes, pvCache)
+	err = updateMetadataEC2(ctx, k8sClient, cloud, nodes, pvCache, volumes)
 	if err != nil {
 		klog.ErrorS(err, "Unable to update ENI/Volume count on node labels")
 		return err
@@ -221,8 +359,8 @@ func getNodes(ctx context.Context, kubeclient kubernetes.Interface) (*v1.NodeLis
 	return nodes, nil
 }
 
-func updateMetadataEC2(ctx context.Context, kubeclient kubernetes.Interface, cloud cloud.Cloud, nodes *v1.NodeList, pvInformer cache.SharedIndexInformer) error {
-	enisVolumeMap, err := getMetadata(ctx, cloud, nodes, pvInformer)
+func updateMetadataEC2(ctx context.Context, kubeclient kubernetes.Interface, cloud cloud.Cloud, nodes *v1.NodeList, pvInformer cache.SharedIndexInformer, volumes *nodeAPIVolumes) error {
+	enisVolumeMap, err := getMetadata(ctx, cloud, nodes, pvInformer, volumes)
 	if err != nil {
 		klog.ErrorS(err, "Unable to get ENI/Volume count")
 		return err
@@ -235,8 +373,9 @@ func updateMetadataEC2(ctx context.Context, kubeclient kubernetes.Interface, clo
 	return nil
 }
 
-// getMetadata calls the EC2 API to get the number of ENIs and non-CSI managed volumes attached to each node.
-func getMetadata(ctx context.Context, cloud cloud.Cloud, nodes *v1.NodeList, pvInformer cache.SharedIndexInformer) (map[string]enisVolumes, error) {
+// getMetadata calls the EC2 API to get the number of ENIs and non-CSI managed volumes attached to each node. The
+// volumes of the nodes whose node plugin serves them on its node API are counted from it instead.
+func getMetadata(ctx context.Context, cloud cloud.Cloud, nodes *v1.NodeList, pvInformer cache.SharedIndexInformer, volumes *nodeAPIVolumes) (map[string]enisVolumes, error) {
 	nodeIds := make([]string, 0, len(nodes.Items))
 	for _, node := range nodes.Items {
 		id, err := parseProviderID(&node)
@@ -254,6 +393,7 @@ func getMetadata(ctx context.Context, cloud cloud.Cloud, nodes *v1.NodeList, pvI
 		return nil, err
 	}
 
+	attached := volumes.attachedVolumes(ctx, nodes)
 	enisVolumesMap := make(map[string]enisVolumes, len(respList))
 	for _, instance := range respList {
 		numAttachedENIs := 1
@@ -261,7 +401,10 @@ func getMetadata(ctx context.Context, cloud cloud.Cloud, nodes *v1.NodeList, pvI
 			numAttachedENIs = len(instance.NetworkInterfaces)
 		}
 		numBlockDeviceMappings := 0
-		if instance.BlockDeviceMappings != nil {
+		if volumeIDs, ok := attached[*instance.InstanceId]; ok {
+			// -1 for root volume, which the node plugin lists like the block device mappings
+			numBlockDeviceMappings = getNonCSIManagedVolumeIDs(pvInformer, volumeIDs) - 1
+		} else if instance.BlockDeviceMappings != nil {
 			// -1 for root volume because we eventually add this back in when calculating allocatable count in getVolumesLimit()
 			numBlockDeviceMappings = getNonCSIManagedVolumes(pvInformer, instance.BlockDeviceMappings) - 1
 		}
@@ -16,8 +16,12 @@ package metadata
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"slices"
 	"strconv"
 	"strings"
@@ -27,7 +31,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/golang/mock/gomock"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/nodeapi"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -141,7 +148,7 @@ func TestGetMetadata(t *testing.T) {
 
 			pvInformer := setupPVInformer(t, tt.pvs)
 
-			got, err := getMetadata(ctx, mockCloud, nodeList, pvInformer)
+			got, err := getMetadata(ctx, mockCloud, nodeList, pvInformer, nil)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getMetadata() error = %v, wantErr %v", err, tt.wantErr)
@@ -154,6 +161,53 @@ func TestGetMetadata(t *testing.T) {
 	}
 }
 
+func TestGetMetadataNodeAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, nodeapi.VolumesPath, r.URL.Path)
+		_ = json.NewEncoder(w).Encode(nodeapi.VolumeList{
+			AttachedVolumesListed: true,
+			Volumes:               []nodeapi.Volume{{VolumeID: "vol-root"}, {VolumeID: "vol-csi"}, {VolumeID: "vol-other"}, {VolumeID: "vol-new"}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	nodePluginPod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system", Labels: map[string]string{"app": "ebs-csi-node"}},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: host},
+		}
+	}
+	clientset := fake.NewClientset(nodePluginPod("ebs-csi-node-1", "i-001"))
+	volumes, err := newNodeAPIVolumes(t.Context(), clientset, NodeAPIOptions{Port: portNumber, NodePodsSelector: "app=ebs-csi-node"})
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(ctrl)
+	// The EC2 block device mappings of i-001 are stale, the node plugin reports a newly attached volume
+	mockCloud.EXPECT().GetInstancesPatching(gomock.Any(), []string{"i-001", "i-002"}).Return([]*types.Instance{
+		makeInstance("i-001", 1, []string{"vol-root", "vol-csi", "vol-other"}),
+		makeInstance("i-002", 2, []string{"vol-root-2", "vol-other-2"}),
+	}, nil)
+	pvInformer := setupPVInformer(t, []corev1.PersistentVolume{makeCSIPV("pv-001", "vol-csi")})
+
+	nodes := &corev1.NodeList{Items: []corev1.Node{
+		makeNode("i-001", "aws:///us-west-2a/i-001"),
+		// i-002 has no node plugin running, its volumes are counted from EC2
+		makeNode("i-002", "aws:///us-west-2a/i-002"),
+	}}
+	got, err := getMetadata(t.Context(), mockCloud, nodes, pvInformer, volumes)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]enisVolumes{
+		"i-001": {ENIs: 1, Volumes: 2},
+		"i-002": {ENIs: 2, Volumes: 1},
+	}, got)
+}
+
 func TestPatchSingleNode(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -397,7 +451,7 @@ func TestUpdateMetadataEC2(t *testing.T) {
 				mockCloud.EXPECT().GetInstancesPatching(ctx, []string{"i-001"}).Return(instances, nil)
 			}
 
-			err := updateMetadataEC2(ctx, clientset, mockCloud, nodeList, pvInformer)
+			err := updateMetadataEC2(ctx, clientset, mockCloud, nodeList, pvInformer, nil)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("updateMetadataEC2() error = %v, wantErr %v", err, tt.wantErr)
@@ -458,7 +512,7 @@ func TestPatchNewNodes(t *testing.T) {
 				return false, nil, nil
 			})
 
-			err := patchNewNodes(ctx, clientset, mockCloud, nodesInformer, pvInformer)
+			err := patchNewNodes(ctx, clientset, mockCloud, nodesInformer, pvInformer, nil)
 			if err != nil {
 				t.Fatalf("patchNewNodes() error = %v", err)
 			}
@@ -7,6 +7,7 @@ package cloud
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
 	types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -66,6 +67,62 @@ func (mr *MockCloudMockRecorder) AvailabilityZones(ctx interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvailabilityZones", reflect.TypeOf((*MockCloud)(nil).AvailabilityZones), ctx)
 }
 
+// BatchQueueLen mocks base method.
+func (m *MockCloud) BatchQueueLen() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchQueueLen")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// BatchQueueLen indicates an expected call of BatchQueueLen.
+func (mr *MockCloudMockRecorder) BatchQueueLen() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchQueueLen", reflect.TypeOf((*MockCloud)(nil).BatchQueueLen))
+}
+
+// CheckAttachmentZone mocks base method.
+func (m *MockCloud) CheckAttachmentZone(ctx context.Context, volumeID, nodeID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckAttachmentZone", ctx, volumeID, nodeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckAttachmentZone indicates an expected call of CheckAttachmentZone.
+func (mr *MockCloudMockRecorder) CheckAttachmentZone(ctx, volumeID, nodeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckAttachmentZone", reflect.TypeOf((*MockCloud)(nil).CheckAttachmentZone), ctx, volumeID, nodeID)
+}
+
+// CheckBlockExpressSupport mocks base method.
+func (m *MockCloud) CheckBlockExpressSupport(ctx context.Context, nodeID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckBlockExpressSupport", ctx, nodeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckBlockExpressSupport indicates an expected call of CheckBlockExpressSupport.
+func (mr *MockCloudMockRecorder) CheckBlockExpressSupport(ctx, nodeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckBlockExpressSupport", reflect.TypeOf((*MockCloud)(nil).CheckBlockExpressSupport), ctx, nodeID)
+}
+
+// CheckMultiAttachSupport mocks base method.
+func (m *MockCloud) CheckMultiAttachSupport(ctx context.Context, nodeID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckMultiAttachSupport", ctx, nodeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckMultiAttachSupport indicates an expected call of CheckMultiAttachSupport.
+func (mr *MockCloudMockRecorder) CheckMultiAttachSupport(ctx, nodeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckMultiAttachSupport", reflect.TypeOf((*MockCloud)(nil).CheckMultiAttachSupport), ctx, nodeID)
+}
+
 // CreateDisk mocks base method.
 func (m *MockCloud) CreateDisk(ctx context.Context, volumeName string, diskOptions *DiskOptions) (*Disk, error) {
 	m.ctrl.T.Helper()
@@ -169,6 +226,20 @@ func (mr *MockCloudMockRecorder) EnableFastSnapshotRestores(ctx, availabilityZon
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableFastSnapshotRestores", reflect.TypeOf((*MockCloud)(nil).EnableFastSnapshotRestores), ctx, availabilityZones, snapshotID)
 }
 
+// EnableVolumeIO mocks base method.
+func (m *MockCloud) EnableVolumeIO(ctx context.Context, volumeID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableVolumeIO", ctx, volumeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableVolumeIO indicates an expected call of EnableVolumeIO.
+func (mr *MockCloudMockRecorder) EnableVolumeIO(ctx, volumeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableVolumeIO", reflect.TypeOf((*MockCloud)(nil).EnableVolumeIO), ctx, volumeID)
+}
+
 // GetDiskByID mocks base method.
 func (m *MockCloud) GetDiskByID(ctx context.Context, volumeID string) (*Disk, error) {
 	m.ctrl.T.Helper()
@@ -199,6 +270,21 @@ func (mr *MockCloudMockRecorder) GetDiskByName(ctx, name, capacityBytes interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDiskByName", reflect.TypeOf((*MockCloud)(nil).GetDiskByName), ctx, name, capacityBytes)
 }
 
+// GetEBSBandwidth mocks base method.
+func (m *MockCloud) GetEBSBandwidth(ctx context.Context, nodeID string) (*EBSBandwidth, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEBSBandwidth", ctx, nodeID)
+	ret0, _ := ret[0].(*EBSBandwidth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEBSBandwidth indicates an expected call of GetEBSBandwidth.
+func (mr *MockCloudMockRecorder) GetEBSBandwidth(ctx, nodeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEBSBandwidth", reflect.TypeOf((*MockCloud)(nil).GetEBSBandwidth), ctx, nodeID)
+}
+
 // GetInstancesPatching mocks base method.
 func (m *MockCloud) GetInstancesPatching(ctx context.Context, nodeIDs []string) ([]*types.Instance, error) {
 	m.ctrl.T.Helper()
@@ -244,6 +330,21 @@ func (mr *MockCloudMockRecorder) GetSnapshotByName(ctx, name interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSnapshotByName", reflect.TypeOf((*MockCloud)(nil).GetSnapshotByName), ctx, name)
 }
 
+// GetStorageUsage mocks base method.
+func (m *MockCloud) GetStorageUsage(ctx context.Context) (*StorageUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStorageUsage", ctx)
+	ret0, _ := ret[0].(*StorageUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStorageUsage indicates an expected call of GetStorageUsage.
+func (mr *MockCloudMockRecorder) GetStorageUsage(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStorageUsage", reflect.TypeOf((*MockCloud)(nil).GetStorageUsage), ctx)
+}
+
 // GetVolumeIDByNodeAndDevice mocks base method.
 func (m *MockCloud) GetVolumeIDByNodeAndDevice(ctx context.Context, nodeID, deviceName string) (string, error) {
 	m.ctrl.T.Helper()
@@ -259,6 +360,36 @@ func (mr *MockCloudMockRecorder) GetVolumeIDByNodeAndDevice(ctx, nodeID, deviceN
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVolumeIDByNodeAndDevice", reflect.TypeOf((*MockCloud)(nil).GetVolumeIDByNodeAndDevice), ctx, nodeID, deviceName)
 }
 
+// GetVolumeHealth mocks base method.
+func (m *MockCloud) GetVolumeHealth(ctx context.Context, volumeIDs []string) (map[string]*VolumeHealth, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVolumeHealth", ctx, volumeIDs)
+	ret0, _ := ret[0].(map[string]*VolumeHealth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVolumeHealth indicates an expected call of GetVolumeHealth.
+func (mr *MockCloudMockRecorder) GetVolumeHealth(ctx, volumeIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVolumeHealth", reflect.TypeOf((*MockCloud)(nil).GetVolumeHealth), ctx, volumeIDs)
+}
+
+// GetZoneCapacity mocks base method.
+func (m *MockCloud) GetZoneCapacity(ctx context.Context, volumeType string) (map[string]ZoneCapacity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetZoneCapacity", ctx, volumeType)
+	ret0, _ := ret[0].(map[string]ZoneCapacity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetZoneCapacity indicates an expected call of GetZoneCapacity.
+func (mr *MockCloudMockRecorder) GetZoneCapacity(ctx, volumeType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetZoneCapacity", reflect.TypeOf((*MockCloud)(nil).GetZoneCapacity), ctx, volumeType)
+}
+
 // IsVolumeInitialized mocks base method.
 func (m *MockCloud) IsVolumeInitialized(ctx context.Context, volumeID string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -274,6 +405,51 @@ func (mr *MockCloudMockRecorder) IsVolumeInitialized(ctx, volumeID interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsVolumeInitialized", reflect.TypeOf((*MockCloud)(nil).IsVolumeInitialized), ctx, volumeID)
 }
 
+// ListDisks mocks base method.
+func (m *MockCloud) ListDisks(ctx context.Context, volumeIDs []string, tags map[string]string) ([]*Disk, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDisks", ctx, volumeIDs, tags)
+	ret0, _ := ret[0].([]*Disk)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDisks indicates an expected call of ListDisks.
+func (mr *MockCloudMockRecorder) ListDisks(ctx, volumeIDs, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDisks", reflect.TypeOf((*MockCloud)(nil).ListDisks), ctx, volumeIDs, tags)
+}
+
+// ListDisksPage mocks base method.
+func (m *MockCloud) ListDisksPage(ctx context.Context, tags map[string]string, maxResults int32, nextToken string) (*ListDisksResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDisksPage", ctx, tags, maxResults, nextToken)
+	ret0, _ := ret[0].(*ListDisksResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDisksPage indicates an expected call of ListDisksPage.
+func (mr *MockCloudMockRecorder) ListDisksPage(ctx, tags, maxResults, nextToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDisksPage", reflect.TypeOf((*MockCloud)(nil).ListDisksPage), ctx, tags, maxResults, nextToken)
+}
+
+// ListPendingDeletionDisks mocks base method.
+func (m *MockCloud) ListPendingDeletionDisks(ctx context.Context, tags map[string]string) (map[string]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingDeletionDisks", ctx, tags)
+	ret0, _ := ret[0].(map[string]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingDeletionDisks indicates an expected call of ListPendingDeletionDisks.
+func (mr *MockCloudMockRecorder) ListPendingDeletionDisks(ctx, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingDeletionDisks", reflect.TypeOf((*MockCloud)(nil).ListPendingDeletionDisks), ctx, tags)
+}
+
 // ListSnapshots mocks base method.
 func (m *MockCloud) ListSnapshots(ctx context.Context, volumeID string, maxResults int32, nextToken string) (*ListSnapshotsResponse, error) {
 	m.ctrl.T.Helper()
@@ -289,6 +465,21 @@ func (mr *MockCloudMockRecorder) ListSnapshots(ctx, volumeID, maxResults, nextTo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSnapshots", reflect.TypeOf((*MockCloud)(nil).ListSnapshots), ctx, volumeID, maxResults, nextToken)
 }
 
+// ListSnapshotsByTags mocks base method.
+func (m *MockCloud) ListSnapshotsByTags(ctx context.Context, tags map[string]string) ([]*Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSnapshotsByTags", ctx, tags)
+	ret0, _ := ret[0].([]*Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSnapshotsByTags indicates an expected call of ListSnapshotsByTags.
+func (mr *MockCloudMockRecorder) ListSnapshotsByTags(ctx, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSnapshotsByTags", reflect.TypeOf((*MockCloud)(nil).ListSnapshotsByTags), ctx, tags)
+}
+
 // LockSnapshot mocks base method.
 func (m *MockCloud) LockSnapshot(ctx context.Context, lockOptions *SnapshotLockOptions) error {
 	m.ctrl.T.Helper()
@@ -332,6 +523,49 @@ func (mr *MockCloudMockRecorder) ResizeOrModifyDisk(ctx, volumeID, newSizeBytes,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResizeOrModifyDisk", reflect.TypeOf((*MockCloud)(nil).ResizeOrModifyDisk), ctx, volumeID, newSizeBytes, options)
 }
 
+// ResolveKMSKey mocks base method.
+func (m *MockCloud) ResolveKMSKey(ctx context.Context, keyID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveKMSKey", ctx, keyID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveKMSKey indicates an expected call of ResolveKMSKey.
+func (mr *MockCloudMockRecorder) ResolveKMSKey(ctx, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveKMSKey", reflect.TypeOf((*MockCloud)(nil).ResolveKMSKey), ctx, keyID)
+}
+
+// SetClientTokenStore mocks base method.
+func (m *MockCloud) SetClientTokenStore(ctx context.Context, store ClientTokenStore) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetClientTokenStore", ctx, store)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetClientTokenStore indicates an expected call of SetClientTokenStore.
+func (mr *MockCloudMockRecorder) SetClientTokenStore(ctx, store interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetClientTokenStore", reflect.TypeOf((*MockCloud)(nil).SetClientTokenStore), ctx, store)
+}
+
+// Throttles mocks base method.
+func (m *MockCloud) Throttles() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Throttles")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// Throttles indicates an expected call of Throttles.
+func (mr *MockCloudMockRecorder) Throttles() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Throttles", reflect.TypeOf((*MockCloud)(nil).Throttles))
+}
+
 // WaitForAttachmentState mocks base method.
 func (m *MockCloud) WaitForAttachmentState(ctx context.Context, expectedState types.VolumeAttachmentState, volumeID, expectedInstance, expectedDevice string, alreadyAssigned bool, expectedCardIndex *int32) (*types.VolumeAttachment, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// Partitions, as they appear in ARNs.
+const (
+	PartitionAWS      = "aws"
+	PartitionChina    = "aws-cn"
+	PartitionGovCloud = "aws-us-gov"
+	PartitionISO      = "aws-iso"
+	PartitionISOB     = "aws-iso-b"
+	PartitionISOE     = "aws-iso-e"
+	PartitionISOF     = "aws-iso-f"
+	PartitionEUSC     = "aws-eusc"
+)
+
+// regionPartitions are the partitions of the regions whose name starts with the given prefix, longest prefixes
+// first. Regions without a listed prefix are in the aws partition.
+var regionPartitions = []struct {
+	prefix    string
+	partition string
+}{
+	{prefix: "us-isob-", partition: PartitionISOB},
+	{prefix: "us-isof-", partition: PartitionISOF},
+	{prefix: "eu-isoe-", partition: PartitionISOE},
+	{prefix: "us-iso-", partition: PartitionISO},
+	{prefix: "us-gov-", partition: PartitionGovCloud},
+	{prefix: "eusc-", partition: PartitionEUSC},
+	{prefix: "cn-", partition: PartitionChina},
+}
+
+// Partition returns the partition of the region, which the ARNs of its resources start with.
+func Partition(region string) string {
+	for _, p := range regionPartitions {
+		if strings.HasPrefix(region, p.prefix) {
+			return p.partition
+		}
+	}
+	return PartitionAWS
+}
+
+// checkPartitionARN returns an ErrInvalidArgument error if the ARN of a resource the driver passes to EC2, such as a
+// KMS key or an Outpost, is not in the partition of the region of the driver, such as an arn:aws: ARN copied from a
+// commercial cluster to a GovCloud or China one. EC2 would otherwise only reject it after creating the volume, or
+// report it as not found. Resources that are not ARNs, and any resource when the region is not known, are accepted.
+func (c *cloud) checkPartitionARN(kind, resource string) error {
+	if c.region == "" || !arn.IsARN(resource) {
+		return nil
+	}
+	parsed, err := arn.Parse(resource)
+	if err != nil {
+		return fmt.Errorf("%w: invalid %s ARN %q: %w", ErrInvalidArgument, kind, resource, err)
+	}
+	if partition := Partition(c.region); parsed.Partition != partition {
+		return fmt.Errorf("%w: %s %q is in partition %s, volumes of region %s are in partition %s", ErrInvalidArgument, kind, resource, parsed.Partition, c.region, partition)
+	}
+	return nil
+}
@@ -17,20 +17,32 @@ limitations under the License.
 package cloud
 
 import (
+	"context"
+	"errors"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
 	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
 )
 
 const (
 	// retryMaxAttempt sets max number of EC2 API call attempts.
 	// Set high enough to ensure default sidecar timeout will cancel context long before we stop retrying.
 	retryMaxAttempt = 50
+
+	// batchRetryBudget is the number of retry tokens shared by all EC2 calls made to serve the batches of one API.
+	// With the SDK's default retry cost of 5 tokens this allows 10 outstanding retries; successful calls refill it.
+	batchRetryBudget = 50
 )
 
 // retryManager dictates the retry strategies of EC2 API calls.
 // Each mutating EC2 API has its own retryer because the AWS SDK throttles on a retryer object level, not by API name.
 // While default AWS accounts share request tokens between mutating APIs, users can raise limits for individual APIs.
 // Separate retryers ensures that throttling one API doesn't unintentionally throttle others with separate token buckets.
+// The exception is DeleteVolume and DeleteSnapshot, which share a retryer: both are driven by bulk cleanup, and a
+// shared retry budget keeps a cleanup storm from throttling one delete API while retrying the other at full rate.
+// Batched describe APIs each have a budget retryer shared by all of their batches, see newBudgetRetryer.
 type retryManager struct {
 	createVolumeRetryer                            aws.Retryer
 	copyVolumeRetryer                              aws.Retryer
@@ -42,20 +54,31 @@ type retryManager struct {
 	deleteSnapshotRetryer                          aws.Retryer
 	enableFastSnapshotRestoresRetryer              aws.Retryer
 	unbatchableDescribeVolumesModificationsRetryer aws.Retryer
+	batchDescribeVolumesRetryer                    aws.Retryer
+	batchDescribeInstancesRetryer                  aws.Retryer
+	batchDescribeSnapshotsRetryer                  aws.Retryer
+	batchDescribeVolumesModificationsRetryer       aws.Retryer
+	batchDescribeVolumeStatusRetryer               aws.Retryer
 }
 
 func newRetryManager() *retryManager {
+	deleteRetryer := newAdaptiveRetryer()
 	return &retryManager{
 		createVolumeRetryer:                            newAdaptiveRetryer(),
 		copyVolumeRetryer:                              newAdaptiveRetryer(),
 		attachVolumeRetryer:                            newAdaptiveRetryer(),
-		deleteVolumeRetryer:                            newAdaptiveRetryer(),
+		deleteVolumeRetryer:                            deleteRetryer,
 		detachVolumeRetryer:                            newAdaptiveRetryer(),
 		modifyVolumeRetryer:                            newAdaptiveRetryer(),
 		createSnapshotRetryer:                          newAdaptiveRetryer(),
-		deleteSnapshotRetryer:                          newAdaptiveRetryer(),
+		deleteSnapshotRetryer:                          deleteRetryer,
 		enableFastSnapshotRestoresRetryer:              newAdaptiveRetryer(),
 		unbatchableDescribeVolumesModificationsRetryer: newAdaptiveRetryer(),
+		batchDescribeVolumesRetryer:                    newBudgetRetryer("DescribeVolumes"),
+		batchDescribeInstancesRetryer:                  newBudgetRetryer("DescribeInstances"),
+		batchDescribeSnapshotsRetryer:                  newBudgetRetryer("DescribeSnapshots"),
+		batchDescribeVolumesModificationsRetryer:       newBudgetRetryer("DescribeVolumesModifications"),
+		batchDescribeVolumeStatusRetryer:               newBudgetRetryer("DescribeVolumeStatus"),
 	}
 }
 
@@ -67,3 +90,31 @@ func newAdaptiveRetryer() *retry.AdaptiveMode {
 		})
 	})
 }
+
+// newBudgetRetryer returns a retryer that makes every EC2 call it is passed to draw retries from one shared budget,
+// so that throttled batches (including the per-member fallback calls for likely bad IDs) retry a bounded number of
+// times in total instead of once per member.
+func newBudgetRetryer(request string) *budgetRetryer {
+	return &budgetRetryer{
+		RetryerV2: retry.NewStandard(func(so *retry.StandardOptions) {
+			so.MaxAttempts = retryMaxAttempt
+			so.RateLimiter = ratelimit.NewTokenRateLimit(batchRetryBudget)
+		}),
+		request: request,
+	}
+}
+
+// budgetRetryer records when a retry is refused because its retry budget has been used up.
+type budgetRetryer struct {
+	aws.RetryerV2
+	request string
+}
+
+func (r *budgetRetryer) GetRetryToken(ctx context.Context, opErr error) (func(error) error, error) {
+	releaseToken, err := r.RetryerV2.GetRetryToken(ctx, opErr)
+	var quotaErr ratelimit.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		metrics.Recorder().IncreaseCount(metrics.BatchRetryBudgetExhausted, metrics.BatchRetryBudgetExhaustedHelpText, map[string]string{"request": r.request})
+	}
+	return releaseToken, err
+}
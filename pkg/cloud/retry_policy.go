@@ -0,0 +1,148 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// RetryPolicy overrides how the driver waits for and retries EC2 operations. Unset fields keep their defaults.
+type RetryPolicy struct {
+	// VolumeCreation is how new volumes are polled until they are available.
+	VolumeCreation *Backoff `json:"volumeCreation,omitempty"`
+	// VolumeAttachment is how volumes are polled until they are attached to or detached from an instance.
+	VolumeAttachment *Backoff `json:"volumeAttachment,omitempty"`
+	// VolumeModification is how volume modifications are polled until they are optimizing or completed.
+	VolumeModification *Backoff `json:"volumeModification,omitempty"`
+	// DependencyViolation is how DeleteVolume and DeleteSnapshot are retried while the volume or snapshot is
+	// still in use. They are not retried by default.
+	DependencyViolation *Backoff `json:"dependencyViolation,omitempty"`
+	// SnapshotPollInterval is how often the snapshots taken to clone volumes are polled until they are completed.
+	SnapshotPollInterval metav1.Duration `json:"snapshotPollInterval,omitempty"`
+}
+
+// Backoff is an exponential backoff: the first retry waits for Interval, and each following retry waits Factor
+// times longer than the previous one, up to Cap. The operation is attempted at most Steps times.
+type Backoff struct {
+	// InitialDelay is waited for before the first attempt. Only supported by VolumeCreation.
+	InitialDelay metav1.Duration `json:"initialDelay,omitempty"`
+	Interval     metav1.Duration `json:"interval,omitempty"`
+	Factor       float64         `json:"factor,omitempty"`
+	Steps        int             `json:"steps,omitempty"`
+	Cap          metav1.Duration `json:"cap,omitempty"`
+}
+
+// Validate returns an error if the policy has negative durations, factors below 1, or fields that its operation
+// does not support.
+func (p *RetryPolicy) Validate() error {
+	backoffs := []struct {
+		name    string
+		backoff *Backoff
+	}{
+		{"volumeCreation", p.VolumeCreation},
+		{"volumeAttachment", p.VolumeAttachment},
+		{"volumeModification", p.VolumeModification},
+		{"dependencyViolation", p.DependencyViolation},
+	}
+	for _, b := range backoffs {
+		if b.backoff == nil {
+			continue
+		}
+		if err := b.backoff.validate(); err != nil {
+			return fmt.Errorf("%s: %w", b.name, err)
+		}
+		if b.name != "volumeCreation" && b.backoff.InitialDelay.Duration != 0 {
+			return fmt.Errorf("%s: initialDelay is only supported by volumeCreation", b.name)
+		}
+	}
+	if p.SnapshotPollInterval.Duration < 0 {
+		return errors.New("snapshotPollInterval must not be negative")
+	}
+	return nil
+}
+
+func (b *Backoff) validate() error {
+	switch {
+	case b.InitialDelay.Duration < 0, b.Interval.Duration < 0, b.Cap.Duration < 0:
+		return errors.New("durations must not be negative")
+	case b.Factor != 0 && b.Factor < 1:
+		return errors.New("factor must be at least 1")
+	case b.Steps < 0:
+		return errors.New("steps must not be negative")
+	}
+	return nil
+}
+
+// apply overrides the fields of backoff that b sets.
+func (b *Backoff) apply(backoff *wait.Backoff) {
+	if b == nil {
+		return
+	}
+	if b.Interval.Duration != 0 {
+		backoff.Duration = b.Interval.Duration
+	}
+	if b.Factor != 0 {
+		backoff.Factor = b.Factor
+	}
+	if b.Steps != 0 {
+		backoff.Steps = b.Steps
+	}
+	if b.Cap.Duration != 0 {
+		backoff.Cap = b.Cap.Duration
+	}
+}
+
+// withRetryPolicy returns the wait parameters with the overrides of the retry policy.
+func (vwp volumeWaitParameters) withRetryPolicy(p *RetryPolicy) volumeWaitParameters {
+	if p == nil {
+		return vwp
+	}
+	if p.VolumeCreation != nil && p.VolumeCreation.InitialDelay.Duration != 0 {
+		vwp.creationInitialDelay = p.VolumeCreation.InitialDelay.Duration
+	}
+	p.VolumeCreation.apply(&vwp.creationBackoff)
+	p.VolumeAttachment.apply(&vwp.attachmentBackoff)
+	p.VolumeModification.apply(&vwp.modificationBackoff)
+	p.DependencyViolation.apply(&vwp.dependencyViolationBackoff)
+	return vwp
+}
+
+// retryDependencyViolation calls fn until it succeeds or fails for another reason than the volume or snapshot it
+// deletes being in use, within the dependencyViolation backoff. It returns the last error of fn.
+func (c *cloud) retryDependencyViolation(ctx context.Context, id string, fn func() error) error {
+	backoff := c.vwp.dependencyViolationBackoff
+	for {
+		err := fn()
+		if backoff.Steps <= 1 || !isAWSErrorDependencyViolation(err) {
+			return err
+		}
+		delay := backoff.Step()
+		klog.V(4).InfoS("Retrying deletion of resource in use", "id", id, "delay", delay, "err", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
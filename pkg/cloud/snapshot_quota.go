@@ -0,0 +1,220 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/expiringcache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// pendingSnapshotsPerVolumeLimit is the number of snapshots of a volume EC2 allows to be pending at once, and
+	// pendingSnapshotsPerHDDVolumeLimit the number for st1 and sc1 volumes.
+	// Source: https://docs.aws.amazon.com/ebs/latest/userguide/ebs-resource-quotas.html
+	pendingSnapshotsPerVolumeLimit    = 5
+	pendingSnapshotsPerHDDVolumeLimit = 1
+
+	// pendingSnapshotCountTTL and regionSnapshotCountTTL are how long snapshot counts are trusted before they are
+	// fetched again. Snapshots stop being pending within minutes, while counting every snapshot of the account is
+	// expensive and the count is kept up to date as the driver creates and deletes snapshots.
+	pendingSnapshotCountTTL = 1 * time.Minute
+	regionSnapshotCountTTL  = 1 * time.Hour
+	// snapshotCountRecheckAge is the age from which a count that reached its limit is fetched again before a
+	// snapshot is refused, in case snapshots completed or were deleted since.
+	snapshotCountRecheckAge = 10 * time.Second
+)
+
+// snapshotCount is a number of snapshots counted with DescribeSnapshots.
+type snapshotCount struct {
+	count     int
+	fetchedAt time.Time
+}
+
+// snapshotQuota checks snapshot creations against EC2 snapshot limits before they are made, so that they fail with
+// an error naming the limit instead of an opaque EC2 error.
+type snapshotQuota struct {
+	// perRegion is the Snapshots per Region quota of the account, or 0 to not check it.
+	perRegion int
+
+	mu            sync.Mutex
+	pendingCounts expiringcache.ExpiringCache[string, snapshotCount]
+	regionCount   *snapshotCount
+}
+
+func newSnapshotQuota(perRegion int) *snapshotQuota {
+	return &snapshotQuota{
+		perRegion:     perRegion,
+		pendingCounts: newObservedCache[string, snapshotCount]("pending_snapshot_counts", cacheForgetDelay),
+	}
+}
+
+// needsFetch reports whether a cached count must be fetched again: when it is missing or expired, or when it
+// reached limit and may be outdated.
+func (sc *snapshotCount) needsFetch(ttl time.Duration, limit int) bool {
+	if sc == nil {
+		return true
+	}
+	age := time.Since(sc.fetchedAt)
+	return age > ttl || (sc.count >= limit && age > snapshotCountRecheckAge)
+}
+
+// checkSnapshotQuota returns an error wrapping ErrLimitExceeded if creating a snapshot of volumeID would exceed the
+// pending snapshots per volume limit or the Snapshots per Region quota. Snapshots are not refused when their
+// counts cannot be fetched, EC2 enforces its limits anyway.
+func (c *cloud) checkSnapshotQuota(ctx context.Context, volumeID string) error {
+	if c.snapshotQuota == nil {
+		return nil
+	}
+	q := c.snapshotQuota
+
+	pending, err := c.pendingSnapshotCount(ctx, volumeID, pendingSnapshotsPerHDDVolumeLimit)
+	if err != nil {
+		klog.V(4).InfoS("Could not count pending snapshots, skipping quota check", "volumeID", volumeID, "err", err)
+	} else if pending >= pendingSnapshotsPerHDDVolumeLimit {
+		limit := c.pendingSnapshotLimit(ctx, volumeID)
+		if pending >= limit {
+			return fmt.Errorf("%w: volume %s already has %d pending snapshots, the EC2 limit of pending snapshots per volume is %d", ErrLimitExceeded, volumeID, pending, limit)
+		}
+	}
+
+	if q.perRegion > 0 {
+		total, err := c.regionSnapshotCount(ctx)
+		if err != nil {
+			klog.V(4).InfoS("Could not count snapshots, skipping quota check", "err", err)
+		} else if total >= q.perRegion {
+			return fmt.Errorf("%w: account already has %d snapshots in region %s, the Snapshots per Region quota is %d", ErrLimitExceeded, total, c.region, q.perRegion)
+		}
+	}
+	return nil
+}
+
+// pendingSnapshotCount returns the number of pending snapshots of volumeID, from the cache unless it must be
+// fetched again to be checked against limit.
+func (c *cloud) pendingSnapshotCount(ctx context.Context, volumeID string, limit int) (int, error) {
+	q := c.snapshotQuota
+	q.mu.Lock()
+	cached, _ := q.pendingCounts.Get(volumeID)
+	if !cached.needsFetch(pendingSnapshotCountTTL, limit) {
+		count := cached.count
+		q.mu.Unlock()
+		return count, nil
+	}
+	q.mu.Unlock()
+
+	count, err := c.countSnapshots(ctx, &ec2.DescribeSnapshotsInput{
+		OwnerIds: []string{"self"},
+		Filters: []types.Filter{
+			{Name: aws.String("volume-id"), Values: []string{volumeID}},
+			{Name: aws.String("status"), Values: []string{string(types.SnapshotStatePending)}},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	q.pendingCounts.Set(volumeID, &snapshotCount{count: count, fetchedAt: time.Now()})
+	return count, nil
+}
+
+// pendingSnapshotLimit returns the limit of pending snapshots of volumeID, which depends on its type.
+func (c *cloud) pendingSnapshotLimit(ctx context.Context, volumeID string) int {
+	volume, err := c.getVolume(ctx, &ec2.DescribeVolumesInput{VolumeIds: []string{volumeID}})
+	if err != nil {
+		klog.V(4).InfoS("Could not get volume type, assuming the pending snapshots limit of SSD volumes", "volumeID", volumeID, "err", err)
+		return pendingSnapshotsPerVolumeLimit
+	}
+	switch string(volume.VolumeType) {
+	case VolumeTypeST1, VolumeTypeSC1:
+		return pendingSnapshotsPerHDDVolumeLimit
+	default:
+		return pendingSnapshotsPerVolumeLimit
+	}
+}
+
+// regionSnapshotCount returns the number of snapshots the account owns in the region, from the cache unless it
+// must be fetched again to be checked against the quota.
+func (c *cloud) regionSnapshotCount(ctx context.Context) (int, error) {
+	q := c.snapshotQuota
+	q.mu.Lock()
+	if !q.regionCount.needsFetch(regionSnapshotCountTTL, q.perRegion) {
+		count := q.regionCount.count
+		q.mu.Unlock()
+		return count, nil
+	}
+	q.mu.Unlock()
+
+	count, err := c.countSnapshots(ctx, &ec2.DescribeSnapshotsInput{OwnerIds: []string{"self"}})
+	if err != nil {
+		return 0, err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.regionCount = &snapshotCount{count: count, fetchedAt: time.Now()}
+	return count, nil
+}
+
+// countSnapshots returns the number of snapshots matching request, without keeping them in memory.
+func (c *cloud) countSnapshots(ctx context.Context, request *ec2.DescribeSnapshotsInput) (int, error) {
+	count := 0
+	for {
+		response, err := c.ec2.DescribeSnapshots(ctx, request)
+		if err != nil {
+			return 0, err
+		}
+		count += len(response.Snapshots)
+		if aws.ToString(response.NextToken) == "" {
+			return count, nil
+		}
+		request.NextToken = response.NextToken
+	}
+}
+
+// recordSnapshotCreated counts a snapshot of volumeID the driver created in the cached counts.
+func (c *cloud) recordSnapshotCreated(volumeID string) {
+	if c.snapshotQuota == nil {
+		return
+	}
+	q := c.snapshotQuota
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if pending, ok := q.pendingCounts.Get(volumeID); ok {
+		pending.count++
+	}
+	if q.regionCount != nil {
+		q.regionCount.count++
+	}
+}
+
+// recordSnapshotDeleted removes a snapshot the driver deleted from the cached counts.
+func (c *cloud) recordSnapshotDeleted() {
+	if c.snapshotQuota == nil {
+		return
+	}
+	q := c.snapshotQuota
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.regionCount != nil && q.regionCount.count > 0 {
+		q.regionCount.count--
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// storageUsageTTL is how long the storage usage of the account is trusted before it is fetched again. GetCapacity
+// is called for every StorageClass and topology segment each time the external-provisioner refreshes capacities,
+// while listing every volume of the account is expensive.
+const storageUsageTTL = 1 * time.Minute
+
+// StorageUsage is the EBS storage the account uses in the region, which counts against the EBS quotas.
+type StorageUsage struct {
+	// SizeGiB is the total size of the volumes of each volume type.
+	SizeGiB map[string]int64
+	// Volumes is the number of volumes.
+	Volumes int
+}
+
+// storageUsageCache caches the StorageUsage returned by GetStorageUsage.
+type storageUsageCache struct {
+	mu        sync.Mutex
+	usage     *StorageUsage
+	fetchedAt time.Time
+}
+
+// GetStorageUsage returns the size and number of the volumes the account owns in the region, from the cache unless
+// it is older than storageUsageTTL. EBS quotas are per region, so the usage is the same in all availability zones.
+func (c *cloud) GetStorageUsage(ctx context.Context) (*StorageUsage, error) {
+	cache := &c.storageUsage
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.usage != nil && time.Since(cache.fetchedAt) < storageUsageTTL {
+		return copyStorageUsage(cache.usage), nil
+	}
+
+	usage := &StorageUsage{SizeGiB: map[string]int64{}}
+	zoneUsage := map[capacityKey]int64{}
+	request := &ec2.DescribeVolumesInput{MaxResults: aws.Int32(500)}
+	for {
+		response, err := c.ec2.DescribeVolumes(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		for _, volume := range response.Volumes {
+			usage.SizeGiB[string(volume.VolumeType)] += int64(aws.ToInt32(volume.Size))
+			usage.Volumes++
+			zoneUsage[capacityKey{aws.ToString(volume.AvailabilityZone), string(volume.VolumeType)}] += int64(aws.ToInt32(volume.Size))
+		}
+		if aws.ToString(response.NextToken) == "" {
+			break
+		}
+		request.NextToken = response.NextToken
+	}
+
+	c.capacity.setListed(zoneUsage)
+	cache.usage = usage
+	cache.fetchedAt = time.Now()
+	return copyStorageUsage(usage), nil
+}
+
+// copyStorageUsage copies usage so that callers cannot modify the cached usage.
+func copyStorageUsage(usage *StorageUsage) *StorageUsage {
+	return &StorageUsage{SizeGiB: maps.Clone(usage.SizeGiB), Volumes: usage.Volumes}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// describeVolumeStatusMaxIDs is the number of volume IDs passed to each DescribeVolumeStatus call by GetVolumeHealth.
+const describeVolumeStatusMaxIDs = 1000
+
+// VolumeHealth is the status of a volume reported by EC2 DescribeVolumeStatus.
+type VolumeHealth struct {
+	// Status is the overall status of the volume: ok, impaired, warning or insufficient-data.
+	Status types.VolumeStatusInfoStatus
+	// IOEnabled is false when EBS disabled I/O to the volume because its data is potentially inconsistent.
+	IOEnabled bool
+	// Events describes the scheduled or ongoing events of the volume, if any.
+	Events string
+	// Initializing is true while the blocks of a volume created from a snapshot are being downloaded from it.
+	Initializing bool
+	// InitializationProgress is the percentage of the blocks of an initializing volume already downloaded.
+	InitializationProgress int64
+	// InitializationTimeLeft is the estimated time until an initializing volume is initialized, or 0 if unknown.
+	InitializationTimeLeft time.Duration
+}
+
+// Impaired returns true if EBS reports the volume as impaired or disabled I/O to it.
+func (h *VolumeHealth) Impaired() bool {
+	return h.Status == types.VolumeStatusInfoStatusImpaired || !h.IOEnabled
+}
+
+// GetVolumeHealth returns the status of volumes, keyed by volume ID. Volumes that EC2 does not report are omitted.
+func (c *cloud) GetVolumeHealth(ctx context.Context, volumeIDs []string) (map[string]*VolumeHealth, error) {
+	health := make(map[string]*VolumeHealth, len(volumeIDs))
+	for ids := range slices.Chunk(volumeIDs, describeVolumeStatusMaxIDs) {
+		items, err := execBatchDescribeVolumeStatus(ctx, c.ec2, c.rm.batchDescribeVolumeStatusRetryer, ids)
+		if err != nil {
+			return nil, fmt.Errorf("could not describe volume status: %w", err)
+		}
+		for volumeID, item := range items {
+			health[volumeID] = newVolumeHealth(item)
+		}
+	}
+	return health, nil
+}
+
+func newVolumeHealth(item *types.VolumeStatusItem) *VolumeHealth {
+	h := &VolumeHealth{IOEnabled: true}
+	if item.VolumeStatus != nil {
+		h.Status = item.VolumeStatus.Status
+		for _, detail := range item.VolumeStatus.Details {
+			if detail.Name == types.VolumeStatusNameIoEnabled && aws.ToString(detail.Status) == "failed" {
+				h.IOEnabled = false
+			}
+		}
+		h.Initializing = isVolumeStatusInitializing(*item)
+	}
+	if details := item.InitializationStatusDetails; h.Initializing && details != nil {
+		h.InitializationProgress = aws.ToInt64(details.Progress)
+		h.InitializationTimeLeft = time.Duration(aws.ToInt64(details.EstimatedTimeToCompleteInSeconds)) * time.Second
+	}
+	events := make([]string, 0, len(item.Events))
+	for _, event := range item.Events {
+		events = append(events, fmt.Sprintf("%s: %s", aws.ToString(event.EventType), aws.ToString(event.Description)))
+	}
+	h.Events = strings.Join(events, "; ")
+	return h
+}
+
+// EnableVolumeIO re-enables I/O to a volume after EBS disabled it because its data was potentially inconsistent.
+func (c *cloud) EnableVolumeIO(ctx context.Context, volumeID string) error {
+	_, err := c.ec2.EnableVolumeIO(ctx, &ec2.EnableVolumeIOInput{VolumeId: aws.String(volumeID)})
+	if err != nil {
+		if isAWSErrorVolumeNotFound(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("could not enable I/O of volume %q: %w", volumeID, err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloud
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Zone types, as reported by the ZoneType field of EC2 DescribeAvailabilityZones.
+const (
+	ZoneTypeAvailabilityZone = "availability-zone"
+	ZoneTypeLocalZone        = "local-zone"
+	ZoneTypeWavelengthZone   = "wavelength-zone"
+)
+
+var (
+	// LocalZoneVolumeTypes are the volume types that can be created in Local Zones. Some Local Zones do not offer
+	// every one of them, see https://aws.amazon.com/about-aws/global-infrastructure/localzones/features/.
+	LocalZoneVolumeTypes = []string{
+		VolumeTypeGP2,
+		VolumeTypeGP3,
+		VolumeTypeIO1,
+		VolumeTypeST1,
+		VolumeTypeSC1,
+	}
+
+	// WavelengthZoneVolumeTypes are the volume types that can be created in Wavelength Zones.
+	WavelengthZoneVolumeTypes = []string{
+		VolumeTypeGP2,
+	}
+
+	// regionalZoneNamePrefix matches the region of the names of Local Zones and Wavelength Zones, which are followed
+	// by a location (us-west-2-lax-1a, us-east-1-wl1-bos-wlz-1), unlike the names of Availability Zones (us-west-2a).
+	regionalZoneNamePrefix = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-\d+-`)
+)
+
+// ZoneType returns the type of the zone of the given name or ID, detected from its format so that it does not cost
+// an EC2 call. Either argument may be empty.
+func ZoneType(zone, zoneID string) string {
+	switch {
+	case strings.Contains(zone, "-wlz-") || strings.Contains(zoneID, "-wlz"):
+		return ZoneTypeWavelengthZone
+	case regionalZoneNamePrefix.MatchString(zone):
+		return ZoneTypeLocalZone
+	case strings.Count(zoneID, "-") > 1:
+		// Availability Zone IDs have two parts (use1-az1), Local Zone IDs three (usw2-lax1-az1)
+		return ZoneTypeLocalZone
+	default:
+		return ZoneTypeAvailabilityZone
+	}
+}
+
+// ZoneVolumeTypes returns the volume types that can be created in the zone of the given name or ID, or nil if the
+// zone does not restrict them.
+func ZoneVolumeTypes(zone, zoneID string) []string {
+	switch ZoneType(zone, zoneID) {
+	case ZoneTypeLocalZone:
+		return LocalZoneVolumeTypes
+	case ZoneTypeWavelengthZone:
+		return WavelengthZoneVolumeTypes
+	default:
+		return nil
+	}
+}
+
+// ZoneSupportsVolumeType returns whether volumes of the given type can be created in the zone of the given name or
+// ID.
+func ZoneSupportsVolumeType(zone, zoneID, volumeType string) bool {
+	supported := ZoneVolumeTypes(zone, zoneID)
+	return supported == nil || slices.Contains(supported, strings.ToLower(volumeType))
+}
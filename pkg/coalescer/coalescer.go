@@ -18,8 +18,11 @@ limitations under the License.
 package coalescer
 
 import (
+	"context"
+	"sync/atomic"
 	"time"
 
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
 	"k8s.io/klog/v2"
 )
 
@@ -31,13 +34,36 @@ import (
 // When the delay on the request expires (determined by the time the first request comes in), the merged
 // input is passed to the execution function, and the result to all waiting callers (those that were
 // not rejected during the merge step).
+//
+// Executions for the same key never overlap, and inputs are merged in the order they are received. Inputs
+// received while a key is executing are coalesced into the next execution for that key, which starts once
+// both its delay has expired and the in-flight execution has finished. This lets dependent operations on the
+// same resource (such as a modification followed by a tag change) be merged into the minimal sequence of calls
+// without a later request ever overtaking an earlier one.
+//
+// Callers that give up (their context is done) stop waiting immediately. If every caller waiting on a key
+// has given up by the time the delay expires, the execution is skipped; otherwise the context passed to
+// the execution function is cancelled once the last remaining caller gives up.
 type Coalescer[InputType any, ResultType any] interface {
 	// Coalesce is a function to coalesce a given input
+	// ctx = context of the caller, returns ctx.Err() if done before a result is available
 	// key = only requests with this same key will be coalesced (such as volume ID)
 	// input = input to merge with other inputs
 	// It is NOT guaranteed all callers receive the same result (for example, if
 	// an input fails to merge, only that caller will receive an error)
-	Coalesce(key string, input InputType) (ResultType, error)
+	Coalesce(ctx context.Context, key string, input InputType) (ResultType, error)
+
+	// Len returns the number of callers currently waiting on a result
+	Len() int
+
+	// WithObserver registers fn to be called before each execution with the number of requests merged into it
+	// and how long its first request waited for it, which is the delay plus any wait for a previous execution.
+	// It must be called before Coalesce is first called.
+	WithObserver(fn func(requests int, wait time.Duration)) Coalescer[InputType, ResultType]
+
+	// WithConflictObserver registers fn to be called with the error of each request that could not be merged.
+	// It must be called before Coalesce is first called.
+	WithConflictObserver(fn func(err error)) Coalescer[InputType, ResultType]
 }
 
 // New is a function to creates a new coalescer and immediately begin processing requests
@@ -45,10 +71,11 @@ type Coalescer[InputType any, ResultType any] interface {
 // mergeFunction = a function to merge a new input with the existing inputs
 // (should return an error if the new input cannot be combined with the existing inputs,
 // otherwise return the new merged input)
-// executeFunction = the function to call when the delay expires.
+// executeFunction = the function to call when the delay expires
+// (the context is cancelled once every caller waiting on the result has given up).
 func New[InputType any, ResultType any](delay time.Duration,
 	mergeFunction func(input InputType, existing InputType) (InputType, error),
-	executeFunction func(key string, input InputType) (ResultType, error),
+	executeFunction func(ctx context.Context, key string, input InputType) (ResultType, error),
 ) Coalescer[InputType, ResultType] {
 	c := coalescer[InputType, ResultType]{
 		delay:           delay,
@@ -56,7 +83,9 @@ func New[InputType any, ResultType any](delay time.Duration,
 		executeFunction: executeFunction,
 		inputChannel:    make(chan newInput[InputType, ResultType]),
 		timerChannel:    make(chan string),
+		doneChannel:     make(chan string),
 		pendingInputs:   make(map[string]pendingInput[InputType, ResultType]),
+		executing:       make(map[string]struct{}),
 	}
 
 	go c.coalescerThread()
@@ -70,39 +99,76 @@ type result[ResultType any] struct {
 }
 
 // Type to send inputs from Coalesce() to coalescerThread() via channel
-// Includes a return channel for the result.
+// Includes a return channel for the result and the caller's context.
 type newInput[InputType any, ResultType any] struct {
+	ctx           context.Context
 	key           string
 	input         InputType
 	resultChannel chan result[ResultType]
 }
 
+// Type to store a caller waiting on a pending input.
+type waiter[ResultType any] struct {
+	ctx           context.Context
+	resultChannel chan result[ResultType]
+}
+
 // Type to store pending inputs in the input map.
+// ready is set once the delay has expired while a previous execution for the key was still in flight.
 type pendingInput[InputType any, ResultType any] struct {
-	input          InputType
-	resultChannels []chan result[ResultType]
+	input    InputType
+	waiters  []waiter[ResultType]
+	ready    bool
+	received time.Time
 }
 
 type coalescer[InputType any, ResultType any] struct {
 	delay           time.Duration
 	mergeFunction   func(input InputType, existing InputType) (InputType, error)
-	executeFunction func(key string, input InputType) (ResultType, error)
+	executeFunction func(ctx context.Context, key string, input InputType) (ResultType, error)
 
 	inputChannel chan newInput[InputType, ResultType]
 	timerChannel chan string
+	doneChannel  chan string
 
 	pendingInputs map[string]pendingInput[InputType, ResultType]
+	// executing is the set of keys with an in-flight execution
+	executing map[string]struct{}
+
+	// waiting is the number of callers that have not yet received a result
+	waiting atomic.Int64
+
+	// observe, if set, is called before each execution with the number of requests merged into it
+	// and how long the first of them waited
+	observe func(requests int, wait time.Duration)
+	// observeConflict, if set, is called with the error of each request that could not be merged
+	observeConflict func(err error)
 }
 
-func (c *coalescer[InputType, ResultType]) Coalesce(key string, input InputType) (ResultType, error) {
-	resultChannel := make(chan result[ResultType])
+func (c *coalescer[InputType, ResultType]) Coalesce(ctx context.Context, key string, input InputType) (ResultType, error) {
+	// Buffered so that coalescerThread never blocks on a caller that has given up
+	resultChannel := make(chan result[ResultType], 1)
+
+	c.waiting.Add(1)
+	defer c.waiting.Add(-1)
 
-	c.inputChannel <- newInput[InputType, ResultType]{
+	select {
+	case c.inputChannel <- newInput[InputType, ResultType]{
+		ctx:           ctx,
 		key:           key,
 		input:         input,
 		resultChannel: resultChannel,
+	}:
+	case <-ctx.Done():
+		return *new(ResultType), ctx.Err()
+	}
+
+	var result result[ResultType]
+	select {
+	case result = <-resultChannel:
+	case <-ctx.Done():
+		return *new(ResultType), ctx.Err()
 	}
-	result := <-resultChannel
 
 	if result.err != nil {
 		return *new(ResultType), result.err
@@ -111,6 +177,20 @@ func (c *coalescer[InputType, ResultType]) Coalesce(key string, input InputType)
 	}
 }
 
+func (c *coalescer[InputType, ResultType]) Len() int {
+	return int(c.waiting.Load())
+}
+
+func (c *coalescer[InputType, ResultType]) WithObserver(fn func(requests int, wait time.Duration)) Coalescer[InputType, ResultType] {
+	c.observe = fn
+	return c
+}
+
+func (c *coalescer[InputType, ResultType]) WithConflictObserver(fn func(err error)) Coalescer[InputType, ResultType] {
+	c.observeConflict = fn
+	return c
+}
+
 func (c *coalescer[InputType, ResultType]) coalescerThread() {
 	for {
 		select {
@@ -123,10 +203,13 @@ func (c *coalescer[InputType, ResultType]) coalescerThread() {
 				if err == nil {
 					klog.V(7).InfoS("coalescerThread: Merged input into existing inputs", "key", i.key)
 					pending.input = newInput
-					pending.resultChannels = append(pending.resultChannels, i.resultChannel)
+					pending.waiters = append(pending.waiters, waiter[ResultType]{ctx: i.ctx, resultChannel: i.resultChannel})
 					c.pendingInputs[i.key] = pending
 				} else {
-					klog.V(7).InfoS("coalescerThread: Failed to merge inputs into existing inputs", "key", i.key)
+					klog.V(4).InfoS("coalescerThread: Rejected input conflicting with pending inputs", "key", i.key, "requests", len(pending.waiters), "err", err, "correlationIDs", util.CorrelationIDs(i.ctx))
+					if c.observeConflict != nil {
+						c.observeConflict(err)
+					}
 					i.resultChannel <- result[ResultType]{
 						err: err,
 					}
@@ -135,9 +218,10 @@ func (c *coalescer[InputType, ResultType]) coalescerThread() {
 				klog.V(7).InfoS("coalescerThread: New input, setting up fresh coalesce operation", "key", i.key)
 				c.pendingInputs[i.key] = pendingInput[InputType, ResultType]{
 					input: i.input,
-					resultChannels: []chan result[ResultType]{
-						i.resultChannel,
+					waiters: []waiter[ResultType]{
+						{ctx: i.ctx, resultChannel: i.resultChannel},
 					},
+					received: time.Now(),
 				}
 				time.AfterFunc(c.delay, func() {
 					c.timerChannel <- i.key
@@ -145,21 +229,96 @@ func (c *coalescer[InputType, ResultType]) coalescerThread() {
 			}
 
 		case k := <-c.timerChannel:
-			klog.V(7).InfoS("coalescerThread: Coalescing delay reached, spawning execution thread", "key", k)
-			pending := c.pendingInputs[k]
-			delete(c.pendingInputs, k)
-
-			go func() {
-				r, err := c.executeFunction(k, pending.input)
-				klog.V(7).InfoS("coalescerThread: Finished executing", "key", k, "result", r, "error", err)
-				result := result[ResultType]{
-					result: r,
-					err:    err,
-				}
-				for _, c := range pending.resultChannels {
-					c <- result
-				}
-			}()
+			if _, ok := c.executing[k]; ok {
+				klog.V(7).InfoS("coalescerThread: Coalescing delay reached, waiting for in-flight execution", "key", k)
+				pending := c.pendingInputs[k]
+				pending.ready = true
+				c.pendingInputs[k] = pending
+				continue
+			}
+			c.execute(k)
+
+		case k := <-c.doneChannel:
+			delete(c.executing, k)
+			if pending, ok := c.pendingInputs[k]; ok && pending.ready {
+				c.execute(k)
+			}
+		}
+	}
+}
+
+// execute spawns the execution of the pending input for key k. It must only be called from coalescerThread.
+func (c *coalescer[InputType, ResultType]) execute(k string) {
+	klog.V(7).InfoS("coalescerThread: Coalescing delay reached, spawning execution thread", "key", k)
+	pending := c.pendingInputs[k]
+	delete(c.pendingInputs, k)
+	c.executing[k] = struct{}{}
+	wait := time.Since(pending.received)
+	klog.V(4).InfoS("coalescerThread: Executing coalesced requests", "key", k, "requests", len(pending.waiters), "wait", wait)
+	if c.observe != nil {
+		c.observe(len(pending.waiters), wait)
+	}
+
+	go func() {
+		defer func() {
+			c.doneChannel <- k
+		}()
+
+		ctx, cancel, ok := waitersContext(pending.waiters)
+		if !ok {
+			klog.V(7).InfoS("coalescerThread: All callers gave up, skipping execution", "key", k)
+			return
+		}
+		defer cancel()
+
+		correlationIDs := util.CorrelationIDs(ctx)
+		klog.V(7).InfoS("coalescerThread: Executing", "key", k, "correlationIDs", correlationIDs)
+		r, err := c.executeFunction(ctx, k, pending.input)
+		klog.V(7).InfoS("coalescerThread: Finished executing", "key", k, "result", r, "error", err, "correlationIDs", correlationIDs)
+		result := result[ResultType]{
+			result: r,
+			err:    err,
 		}
+		for _, w := range pending.waiters {
+			w.resultChannel <- result
+		}
+	}()
+}
+
+// waitersContext returns a context that is cancelled once every waiter's context is done
+// and carries the correlation IDs and subsystem of all of the waiters. It returns false if all waiters have already given up.
+func waitersContext[ResultType any](waiters []waiter[ResultType]) (context.Context, context.CancelFunc, bool) {
+	live := make([]context.Context, 0, len(waiters))
+	for _, w := range waiters {
+		if w.ctx.Err() == nil {
+			live = append(live, w.ctx)
+		}
+	}
+	if len(live) == 0 {
+		return nil, nil, false
 	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if correlationIDs := util.MergeCorrelationIDs(live...); len(correlationIDs) > 0 {
+		ctx = util.WithCorrelationIDs(ctx, correlationIDs...)
+	}
+	if subsystem := util.MergeSubsystems(live...); subsystem != "" {
+		ctx = util.WithSubsystem(ctx, subsystem)
+	}
+	var remaining atomic.Int64
+	remaining.Store(int64(len(live)))
+	stops := make([]func() bool, 0, len(live))
+	for _, waiterCtx := range live {
+		stops = append(stops, context.AfterFunc(waiterCtx, func() {
+			if remaining.Add(-1) == 0 {
+				cancel()
+			}
+		}))
+	}
+	return ctx, func() {
+		for _, stop := range stops {
+			stop()
+		}
+		cancel()
+	}, true
 }
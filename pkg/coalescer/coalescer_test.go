@@ -17,6 +17,7 @@ limitations under the License.
 package coalescer
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -40,7 +41,7 @@ func mockMerge(input int, existing int) (int, error) {
 // Execute function used to test the coalescer
 // For testing purposes, small numbers (numbers less than 100) successfully execute,
 // and large numbers (numbers 100 or greater) fail to execute.
-func mockExecute(_ string, input int) (string, error) {
+func mockExecute(_ context.Context, _ string, input int) (string, error) {
 	if input < 100 {
 		return "success", nil
 	}
@@ -83,7 +84,7 @@ func TestCoalescer(t *testing.T) {
 
 			for _, i := range tc.inputs {
 				go func() {
-					_, err := c.Coalesce("testKey", i)
+					_, err := c.Coalesce(t.Context(), "testKey", i)
 					testChannel <- err
 				}()
 			}
@@ -121,3 +122,227 @@ func TestCoalescer(t *testing.T) {
 		})
 	}
 }
+
+func TestCoalescerCancelledCallers(t *testing.T) {
+	t.Parallel()
+
+	executed := make(chan int, 1)
+	c := New[int, string](50*time.Millisecond, mockMerge, func(ctx context.Context, key string, input int) (string, error) {
+		executed <- input
+		return mockExecute(ctx, key, input)
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := c.Coalesce(ctx, "testKey", 1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	select {
+	case input := <-executed:
+		t.Fatalf("Expected no execution when all callers gave up, got input %d", input)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestCoalescerPartiallyCancelledCallers(t *testing.T) {
+	t.Parallel()
+
+	c := New[int, string](50*time.Millisecond, mockMerge, mockExecute)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancelledErr := make(chan error, 1)
+	go func() {
+		_, err := c.Coalesce(ctx, "testKey", 1)
+		cancelledErr <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	if err := <-cancelledErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	result, err := c.Coalesce(t.Context(), "testKey", 2)
+	if err != nil || result != "success" {
+		t.Fatalf("Expected success for remaining caller, got %q, %v", result, err)
+	}
+}
+
+func TestCoalescerLen(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	c := New[int, string](0, mockMerge, func(ctx context.Context, key string, input int) (string, error) {
+		<-release
+		return mockExecute(ctx, key, input)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = c.Coalesce(t.Context(), "testKey", 1)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for c.Len() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if l := c.Len(); l != 1 {
+		t.Fatalf("Expected 1 waiting caller, got %d", l)
+	}
+
+	close(release)
+	<-done
+	if l := c.Len(); l != 0 {
+		t.Fatalf("Expected no waiting callers, got %d", l)
+	}
+}
+
+func TestCoalescerMergesInArrivalOrder(t *testing.T) {
+	t.Parallel()
+
+	executed := make(chan string, 1)
+	c := New[string, string](50*time.Millisecond, func(input string, existing string) (string, error) {
+		return existing + input, nil
+	}, func(_ context.Context, _ string, input string) (string, error) {
+		executed <- input
+		return input, nil
+	})
+
+	results := make(chan string, 3)
+	for _, input := range []string{"a", "b", "c"} {
+		go func() {
+			r, _ := c.Coalesce(t.Context(), "testKey", input)
+			results <- r
+		}()
+		// Give each caller time to reach the coalescer before the next one
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if input := <-executed; input != "abc" {
+		t.Fatalf("Expected inputs to be merged in arrival order, got %q", input)
+	}
+	for range 3 {
+		if r := <-results; r != "abc" {
+			t.Fatalf("Expected every caller to receive the merged result, got %q", r)
+		}
+	}
+}
+
+func TestCoalescerSerializesExecutionsPerKey(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	started := make(chan int, 2)
+	c := New[int, string](10*time.Millisecond, mockMerge, func(_ context.Context, _ string, input int) (string, error) {
+		started <- input
+		if input == 1 {
+			<-release
+		}
+		return "success", nil
+	})
+
+	results := make(chan error, 3)
+	go func() {
+		_, err := c.Coalesce(t.Context(), "testKey", 1)
+		results <- err
+	}()
+	if input := <-started; input != 1 {
+		t.Fatalf("Expected first execution with input 1, got %d", input)
+	}
+
+	// Both of these arrive while the first execution is in flight, so they must be merged
+	// into a single execution that only starts after the first one finishes
+	for _, input := range []int{2, 3} {
+		go func() {
+			_, err := c.Coalesce(t.Context(), "testKey", input)
+			results <- err
+		}()
+	}
+	select {
+	case input := <-started:
+		t.Fatalf("Execution with input %d started while a previous execution for the same key was in flight", input)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if input := <-started; input != 5 {
+		t.Fatalf("Expected second execution with merged input 5, got %d", input)
+	}
+	for range 3 {
+		if err := <-results; err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+	}
+}
+
+func TestCoalescerDoesNotSerializeDifferentKeys(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan string, 2)
+	c := New[int, string](0, mockMerge, func(_ context.Context, key string, _ int) (string, error) {
+		started <- key
+		<-release
+		return "success", nil
+	})
+
+	for _, key := range []string{"key1", "key2"} {
+		go func() {
+			_, _ = c.Coalesce(t.Context(), key, 1)
+		}()
+	}
+	for range 2 {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("Expected executions for different keys to run concurrently")
+		}
+	}
+}
+
+func TestCoalescerObserver(t *testing.T) {
+	t.Parallel()
+
+	type observation struct {
+		requests int
+		wait     time.Duration
+	}
+	observed := make(chan observation, 1)
+	conflicts := make(chan error, 1)
+	c := New[int, string](100*time.Millisecond, mockMerge, mockExecute).WithObserver(func(requests int, wait time.Duration) {
+		observed <- observation{requests: requests, wait: wait}
+	}).WithConflictObserver(func(err error) {
+		conflicts <- err
+	})
+
+	results := make(chan error, 3)
+	for _, input := range []int{1, 2, 3} {
+		go func() {
+			_, err := c.Coalesce(t.Context(), "testKey", input)
+			results <- err
+		}()
+	}
+	// Give the requests time to be received so that the failing one is merged into them rather than executed
+	time.Sleep(20 * time.Millisecond)
+	if _, err := c.Coalesce(t.Context(), "testKey", -1); !errors.Is(err, errFailedToMerge) {
+		t.Fatalf("Expected error %v, got %v", errFailedToMerge, err)
+	}
+	if err := <-conflicts; !errors.Is(err, errFailedToMerge) {
+		t.Fatalf("Expected conflict observer to receive error %v, got %v", errFailedToMerge, err)
+	}
+
+	o := <-observed
+	if o.requests != 3 {
+		t.Fatalf("Expected 3 merged requests, got %d", o.requests)
+	}
+	if o.wait < 100*time.Millisecond {
+		t.Fatalf("Expected the first request to wait at least the delay, waited %s", o.wait)
+	}
+	for range 3 {
+		if err := <-results; err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// AdoptVolumes brings pre-existing volumes under management of the driver. It tags the volumes selected by
+// --volume-ids and --tag-filter the same way CreateVolume tags the volumes it provisions, and writes a statically
+// provisioned PV and a PVC bound to it for each volume to w.
+//
+// The PVs are named after the volume ID and use the Retain reclaim policy, so that deleting the PVC never deletes
+// a volume that was not provisioned by the driver. The Name tag is left untouched even with --k8s-tag-cluster-id.
+func AdoptVolumes(ctx context.Context, c cloud.Cloud, o *Options, w io.Writer) error {
+	disks, err := c.ListDisks(ctx, o.AdoptVolumeIDs, o.AdoptTagFilter)
+	if err != nil {
+		return fmt.Errorf("could not list volumes to adopt: %w", err)
+	}
+	if len(disks) == 0 {
+		klog.InfoS("AdoptVolumes: no volumes matched", "volumeIDs", o.AdoptVolumeIDs, "tagFilter", o.AdoptTagFilter)
+		return nil
+	}
+
+	for _, disk := range disks {
+		if len(disk.Attachments) > 0 {
+			klog.InfoS("AdoptVolumes: volume is attached, it must be detached before it can be used by a pod", "volumeID", disk.VolumeID, "attachments", disk.Attachments)
+		}
+
+		pv, pvc := adoptedVolumeManifests(disk, o)
+		if !o.AdoptDryRun {
+			tagOptions := cloud.ModifyTagsOptions{TagsToAdd: adoptedVolumeTags(pv, pvc, o.KubernetesClusterID)}
+			if err := c.ModifyTags(ctx, disk.VolumeID, tagOptions); err != nil {
+				return fmt.Errorf("could not tag volume %s: %w", disk.VolumeID, err)
+			}
+			klog.InfoS("AdoptVolumes: volume adopted", "volumeID", disk.VolumeID, "pv", pv.Name, "pvc", klog.KObj(pvc))
+		}
+
+		for _, obj := range []any{pv, pvc} {
+			manifest, err := yaml.Marshal(obj)
+			if err != nil {
+				return fmt.Errorf("could not marshal manifest for volume %s: %w", disk.VolumeID, err)
+			}
+			if _, err := fmt.Fprintf(w, "---\n%s", manifest); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// adoptedVolumeTags returns the tags CreateVolume would have set on a volume provisioned for pvc.
+func adoptedVolumeTags(pv *corev1.PersistentVolume, pvc *corev1.PersistentVolumeClaim, clusterID string) map[string]string {
+	tags := map[string]string{
+		cloud.VolumeNameTagKey:   pv.Name,
+		cloud.AwsEbsDriverTagKey: isManagedByDriver,
+		PVNameTag:                pv.Name,
+		PVCNameTag:               pvc.Name,
+		PVCNamespaceTag:          pvc.Namespace,
+	}
+	if clusterID != "" {
+		tags[ResourceLifecycleTagPrefix+clusterID] = ResourceLifecycleOwned
+		tags[KubernetesClusterTag] = clusterID
+		tags[ClusterNameTagKey] = clusterID
+	}
+	return tags
+}
+
+func adoptedVolumeManifests(disk *cloud.Disk, o *Options) (*corev1.PersistentVolume, *corev1.PersistentVolumeClaim) {
+	name := disk.VolumeID
+	capacity := corev1.ResourceList{
+		corev1.ResourceStorage: *resource.NewQuantity(util.GiBToBytes(disk.CapacityGiB), resource.BinarySI),
+	}
+	accessModes := []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+
+	pvc := &corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: o.AdoptNamespace,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      accessModes,
+			StorageClassName: &o.AdoptStorageClass,
+			VolumeName:       name,
+			Resources:        corev1.VolumeResourceRequirements{Requests: capacity},
+		},
+	}
+
+	pv := &corev1.PersistentVolume{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolume"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:                      capacity,
+			AccessModes:                   accessModes,
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+			StorageClassName:              o.AdoptStorageClass,
+			ClaimRef: &corev1.ObjectReference{
+				APIVersion: "v1",
+				Kind:       "PersistentVolumeClaim",
+				Name:       pvc.Name,
+				Namespace:  pvc.Namespace,
+			},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{
+					Driver:       util.GetDriverName(),
+					VolumeHandle: disk.VolumeID,
+					FSType:       o.AdoptFSType,
+				},
+			},
+			NodeAffinity: &corev1.VolumeNodeAffinity{
+				Required: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{
+							Key:      WellKnownZoneTopologyKey,
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{disk.AvailabilityZone},
+						}},
+					}},
+				},
+			},
+		},
+	}
+	return pv, pvc
+}
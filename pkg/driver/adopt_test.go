@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestAdoptVolumes(t *testing.T) {
+	disk := &cloud.Disk{VolumeID: "vol-test", CapacityGiB: 10, AvailabilityZone: "us-east-1a"}
+	testCases := []struct {
+		name        string
+		options     *Options
+		disks       []*cloud.Disk
+		listErr     error
+		modifyErr   error
+		expectedTag map[string]string
+		expectedErr bool
+	}{
+		{
+			name:    "success: volume tagged and manifests written",
+			options: &Options{AdoptVolumeIDs: []string{"vol-test"}, AdoptNamespace: "apps", AdoptFSType: FSTypeXfs},
+			disks:   []*cloud.Disk{disk},
+			expectedTag: map[string]string{
+				cloud.VolumeNameTagKey:   "vol-test",
+				cloud.AwsEbsDriverTagKey: isManagedByDriver,
+				PVNameTag:                "vol-test",
+				PVCNameTag:               "vol-test",
+				PVCNamespaceTag:          "apps",
+			},
+		},
+		{
+			name:    "success: cluster tags added",
+			options: &Options{AdoptTagFilter: map[string]string{"team": "storage"}, AdoptNamespace: "apps", KubernetesClusterID: "cluster"},
+			disks:   []*cloud.Disk{disk},
+			expectedTag: map[string]string{
+				cloud.VolumeNameTagKey:                 "vol-test",
+				cloud.AwsEbsDriverTagKey:               isManagedByDriver,
+				PVNameTag:                              "vol-test",
+				PVCNameTag:                             "vol-test",
+				PVCNamespaceTag:                        "apps",
+				ResourceLifecycleTagPrefix + "cluster": ResourceLifecycleOwned,
+				KubernetesClusterTag:                   "cluster",
+				ClusterNameTagKey:                      "cluster",
+			},
+		},
+		{
+			name:    "success: dry run does not tag",
+			options: &Options{AdoptVolumeIDs: []string{"vol-test"}, AdoptNamespace: "apps", AdoptDryRun: true},
+			disks:   []*cloud.Disk{disk},
+		},
+		{
+			name:    "success: no volumes matched",
+			options: &Options{AdoptTagFilter: map[string]string{"team": "storage"}},
+		},
+		{
+			name:        "fail: list error",
+			options:     &Options{AdoptVolumeIDs: []string{"vol-test"}},
+			listErr:     errors.New("list error"),
+			expectedErr: true,
+		},
+		{
+			name:        "fail: tagging error",
+			options:     &Options{AdoptVolumeIDs: []string{"vol-test"}, AdoptNamespace: "apps"},
+			disks:       []*cloud.Disk{disk},
+			modifyErr:   errors.New("tagging error"),
+			expectedTag: map[string]string{},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			mockCloud.EXPECT().ListDisks(gomock.Any(), tc.options.AdoptVolumeIDs, tc.options.AdoptTagFilter).Return(tc.disks, tc.listErr)
+			if tc.expectedTag != nil {
+				mockCloud.EXPECT().ModifyTags(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+					func(_ any, _ string, options cloud.ModifyTagsOptions) error {
+						if tc.modifyErr == nil {
+							assert.Equal(t, tc.expectedTag, options.TagsToAdd)
+						}
+						return tc.modifyErr
+					})
+			}
+
+			var out bytes.Buffer
+			err := AdoptVolumes(t.Context(), mockCloud, tc.options, &out)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if len(tc.disks) == 0 {
+				assert.Empty(t, out.String())
+				return
+			}
+
+			docs := strings.Split(strings.TrimPrefix(out.String(), "---\n"), "---\n")
+			require.Len(t, docs, 2)
+			var pv corev1.PersistentVolume
+			require.NoError(t, yaml.Unmarshal([]byte(docs[0]), &pv))
+			var pvc corev1.PersistentVolumeClaim
+			require.NoError(t, yaml.Unmarshal([]byte(docs[1]), &pvc))
+
+			assert.Equal(t, "vol-test", pv.Spec.CSI.VolumeHandle)
+			assert.Equal(t, tc.options.AdoptFSType, pv.Spec.CSI.FSType)
+			assert.Equal(t, corev1.PersistentVolumeReclaimRetain, pv.Spec.PersistentVolumeReclaimPolicy)
+			assert.Equal(t, "10Gi", pv.Spec.Capacity.Storage().String())
+			assert.Equal(t, []string{"us-east-1a"}, pv.Spec.NodeAffinity.Required.NodeSelectorTerms[0].MatchExpressions[0].Values)
+			assert.Equal(t, pvc.Name, pv.Spec.ClaimRef.Name)
+			assert.Equal(t, "apps", pvc.Namespace)
+			assert.Equal(t, pv.Name, pvc.Spec.VolumeName)
+		})
+	}
+}
@@ -48,6 +48,9 @@ const (
 	// AllowAutoIOPSPerGBIncreaseKey represents key for allowing automatic increase of IOPS.
 	AllowAutoIOPSPerGBIncreaseKey = "allowautoiopspergbincrease"
 
+	// RoundUpToMinimumSizeKey represents key for rounding requested sizes up to the minimum size of the volume type.
+	RoundUpToMinimumSizeKey = "rounduptominimumsize"
+
 	// AllowAutoIOPSIncreaseOnModifyKey represents key for allowing IOPS increase on resizing if IopsPerGB is set to ensure desired ratio is maintained.
 	AllowAutoIOPSIncreaseOnModifyKey = "allowautoiopsincreaseonmodify"
 
@@ -60,6 +63,9 @@ const (
 	// ThroughputKey represents key for throughput.
 	ThroughputKey = "throughput"
 
+	// ThroughputPerGiBKey represents key for gp3 throughput in MiB/s per GiB.
+	ThroughputPerGiBKey = "throughputpergib"
+
 	// EncryptedKey represents key for whether filesystem is encrypted.
 	EncryptedKey = "encrypted"
 
@@ -114,6 +120,19 @@ const (
 	// Ext4EncryptionSupportKey enables the encrypt option when formatting an ext4 volume.
 	Ext4EncryptionSupportKey = "ext4encryptionsupport"
 
+	// Ext4FscryptKey encrypts the files of an ext4 volume with fscrypt, using a per-volume key from the node stage secret.
+	Ext4FscryptKey = "ext4fscrypt"
+
+	// FscryptSecretKey is the key of the node stage secret that holds the fscrypt key of a volume created with Ext4FscryptKey.
+	FscryptSecretKey = "fscryptKey"
+
+	// FscryptDataDir is the directory of an Ext4FscryptKey volume that is encrypted and published to pods. The root
+	// directory of an ext4 filesystem always contains lost+found, so it cannot be encrypted itself.
+	FscryptDataDir = "fscrypt-data"
+
+	// XfsProjectQuotaKey enables project quota accounting and enforcement when mounting an xfs volume.
+	XfsProjectQuotaKey = "xfsprojectquota"
+
 	// TagKeyPrefix contains the prefix of a volume parameter that designates it as
 	// a tag to be attached to the resource.
 	TagKeyPrefix = "tagSpecification"
@@ -121,8 +140,28 @@ const (
 	// OutpostArnKey represents key for outpost's arn.
 	OutpostArnKey = "outpostarn"
 
+	// FallbackVolumeTypesKey lists the volume types, separated by commas, to create the volume with in order when
+	// EC2 cannot create it with its volume type in the chosen Availability Zone.
+	FallbackVolumeTypesKey = "fallbackvolumetypes"
+
 	// BlockAttachUntilInitializedKey will prevent restored volume from being attached until it is fully initialized.
 	BlockAttachUntilInitializedKey = "blockattachuntilinitialized"
+
+	// MultiAttachKey explicitly enables or disables multi-attach for io2 volumes. It is also set in the volume
+	// context of multi-attach volumes so that ControllerPublishVolume can validate the target instance.
+	MultiAttachKey = "multiattach"
+
+	// RequiresBlockExpressKey is set in the volume context of io2 volumes whose size or IOPS need Block Express, so
+	// that ControllerPublishVolume can validate the target instance.
+	RequiresBlockExpressKey = "requiresblockexpress"
+
+	// LazilyLoadedKey is set in the volume context of volumes restored from a snapshot without fast snapshot
+	// restore, whose blocks are downloaded from the snapshot when first read, so that the node can warm them up.
+	LazilyLoadedKey = "lazilyloaded"
+
+	// APIBudgetClassKey selects the EC2 API budget class of the calls made for a volume. It is also set in the
+	// volume context so that ControllerPublishVolume draws from the same class.
+	APIBudgetClassKey = "apibudgetclass"
 )
 
 // constants of keys in snapshot parameters.
@@ -226,10 +265,14 @@ var (
 				Ext4BigAllocKey:          {},
 				Ext4ClusterSizeKey:       {},
 				Ext4EncryptionSupportKey: {},
+				Ext4FscryptKey:           {},
+				XfsProjectQuotaKey:       {},
 			},
 		},
 		FSTypeExt4: {
-			NotSupportedParams: map[string]struct{}{},
+			NotSupportedParams: map[string]struct{}{
+				XfsProjectQuotaKey: {},
+			},
 		},
 		FSTypeXfs: {
 			NotSupportedParams: map[string]struct{}{
@@ -238,6 +281,7 @@ var (
 				Ext4BigAllocKey:          {},
 				Ext4ClusterSizeKey:       {},
 				Ext4EncryptionSupportKey: {},
+				Ext4FscryptKey:           {},
 			},
 		},
 		FSTypeNtfs: {
@@ -249,6 +293,8 @@ var (
 				Ext4BigAllocKey:          {},
 				Ext4ClusterSizeKey:       {},
 				Ext4EncryptionSupportKey: {},
+				Ext4FscryptKey:           {},
+				XfsProjectQuotaKey:       {},
 			},
 		},
 	}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -39,6 +40,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 )
 
@@ -56,32 +58,106 @@ var (
 		csi.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME,
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
 		csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES_PUBLISHED_NODES,
 		csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
 		csi.ControllerServiceCapability_RPC_MODIFY_VOLUME,
+		csi.ControllerServiceCapability_RPC_GET_VOLUME,
+		csi.ControllerServiceCapability_RPC_VOLUME_CONDITION,
 	}
 )
 
 const trueStr = "true"
 const isManagedByDriver = trueStr
 
+// Throughput in MiB/s supported by gp3 volumes, which throughputPerGiB is clamped to.
+const (
+	gp3MinThroughput = 125
+	gp3MaxThroughput = 1000
+)
+
+const (
+	// gp3BaselineIOPS is the IOPS of gp3 volumes created without IOPS.
+	gp3BaselineIOPS = 3000
+	// gp3IOPSPerThroughput is the IOPS gp3 volumes need for each MiB/s of throughput.
+	gp3IOPSPerThroughput = 4
+)
+
 // ControllerService represents the controller service of CSI driver.
 type ControllerService struct {
 	cloud                 cloud.Cloud
 	inFlight              *internal.InFlight
 	options               *Options
 	modifyVolumeCoalescer coalescer.Coalescer[modifyVolumeRequest, int32]
+	namespaceQuotas       *namespaceQuotaEnforcer
+	handoff               *handoffStore
+	restoreProgress       *restoreProgressTracker
+	parameters            *parameterReporter
+	zonePicker            *zonePicker
+	tagPolicies           *volumeTagPolicies
+	snapshotLimiter       *snapshotLimiter
+	operations            *operationTracker
+	pvcMetadata           *pvcMetadataReader
+	deletionGuard         *deletionGuard
+	zoneMismatch          *zoneMismatchReporter
+	bandwidth             *bandwidthReporter
+	volumeTypeFallback    *volumeTypeFallbackReporter
+	costs                 *costEstimator
+	policyWebhook         *policyWebhook
+	quotaBackoff          *quotaBackoff
+	shardPeers            *shardPeers
 	rpc.UnimplementedModifyServer
 	csi.UnimplementedControllerServer
 }
 
 // NewControllerService creates a new controller service.
-func NewControllerService(c cloud.Cloud, o *Options) *ControllerService {
-	return &ControllerService{
+func NewControllerService(c cloud.Cloud, o *Options, k kubernetes.Interface) *ControllerService {
+	pvs := newPVIndex(k)
+	d := &ControllerService{
 		cloud:                 c,
 		options:               o,
 		inFlight:              internal.NewInFlight(),
 		modifyVolumeCoalescer: newModifyVolumeCoalescer(c, o),
+		namespaceQuotas:       newNamespaceQuotaEnforcer(c, k, o),
+		handoff:               newHandoffStore(k, o),
+		restoreProgress:       newRestoreProgressTracker(c, k, o),
+		parameters:            newParameterReporter(k),
+		zonePicker:            newZonePicker(k, c, o),
+		tagPolicies:           newVolumeTagPolicies(k, o),
+		snapshotLimiter:       newSnapshotLimiter(o),
+		operations:            newOperationTracker(),
+		pvcMetadata:           newPVCMetadataReader(k, o),
+		deletionGuard:         newDeletionGuard(pvs, o),
+		zoneMismatch:          newZoneMismatchReporter(k, pvs),
+		bandwidth:             newBandwidthReporter(k, pvs),
+		volumeTypeFallback:    newVolumeTypeFallbackReporter(k),
+		costs:                 newCostEstimator(o),
+		policyWebhook:         newPolicyWebhook(o),
+		quotaBackoff:          newQuotaBackoff(o),
+		shardPeers:            newShardPeers(o),
+	}
+	if s := newClientTokenConfigMap(k, o); s != nil {
+		if err := c.SetClientTokenStore(context.Background(), s); err != nil {
+			// CreateVolume still works, with the client tokens of this replica only
+			klog.ErrorS(err, "Could not load persisted client tokens", "configMap", o.ClientTokenConfigMap)
+		}
 	}
+	if d.handoff != nil {
+		d.handoff.start()
+	}
+	if o.SoftDeleteRetention > 0 {
+		d.startSoftDeleteReaper(k)
+	}
+	if o.CloneViaSnapshot {
+		d.startCloneSnapshotSweeper(k)
+	}
+	if m := newVolumeHealthMonitor(c, k, o); m != nil {
+		m.start()
+	}
+	if a := newDegradedAnnouncer(c, k, o); a != nil {
+		a.start()
+	}
+	return d
 }
 
 func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest) (*csi.CreateVolumeResponse, error) {
@@ -115,8 +191,10 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 		volumeType               string
 		iopsPerGB                int32
 		allowIOPSPerGBIncrease   bool
+		roundUpToMinimumSize     bool
 		iops                     int32
 		throughput               int32
+		throughputPerGiB         float64
 		volumeInitializationRate int32
 		isEncrypted              bool
 		encryptedKey             string
@@ -133,15 +211,32 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 		ext4BigAlloc                bool
 		ext4ClusterSize             string
 		ext4EncryptionSupport       bool
+		ext4Fscrypt                 bool
+		xfsProjectQuota             bool
 		blockAttachUntilInitialized bool
+		multiAttachParam            string
+		apiBudgetClass              string
+		outpostArnParam             string
+		fallbackVolumeTypes         []string
 	)
 
 	tProps := new(template.PVProps)
 
-	for key, value := range req.GetParameters() {
+	deprecated := append(storageClassParameters.deprecatedIn(req.GetParameters()), volumeAttributesClassParameters.deprecatedIn(req.GetMutableParameters())...)
+	d.parameters.reportDeprecated(ctx, "CreateVolume", deprecated, req.GetParameters())
+	parameters, err := d.policyWebhook.review(ctx, req, d.withDefaultParameters(req.GetParameters()), d.pvcMetadata)
+	if err != nil {
+		return nil, err
+	}
+	if err = d.checkParameterValues(ctx, "CreateVolume", storageClassParameters, parameters, req.GetParameters()); err != nil {
+		return nil, err
+	}
+	if err = d.checkParameterValues(ctx, "CreateVolume", volumeAttributesClassParameters, req.GetMutableParameters(), req.GetParameters()); err != nil {
+		return nil, err
+	}
+
+	for key, value := range storageClassParameters.resolve(parameters) {
 		switch strings.ToLower(key) {
-		case "fstype":
-			klog.InfoS("\"fstype\" is deprecated, please use \"csi.storage.k8s.io/fstype\" instead")
 		case VolumeTypeKey:
 			volumeType = value
 		case IopsPerGBKey:
@@ -155,6 +250,8 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 			volumeTags[cloud.AllowAutoIOPSIncreaseOnModifyKey] = strconv.FormatBool(isTrue(value))
 		case AllowAutoIOPSPerGBIncreaseKey:
 			allowIOPSPerGBIncrease = isTrue(value)
+		case RoundUpToMinimumSizeKey:
+			roundUpToMinimumSize = isTrue(value)
 		case IopsKey:
 			parseIopsKey, parseIopsKeyErr := strconv.ParseInt(value, 10, 32)
 			if parseIopsKeyErr != nil {
@@ -162,17 +259,22 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 			}
 			iops = int32(parseIopsKey)
 		case VolumeInitializationRateKey:
-			parseInitRate, parseInitRateErr := strconv.ParseInt(value, 10, 32)
-			if parseInitRateErr != nil {
-				return nil, status.Errorf(codes.InvalidArgument, "Could not parse invalid volumeInitializationRate: %v", parseInitRateErr)
+			volumeInitializationRate, err = parseVolumeInitializationRate(value)
+			if err != nil {
+				return nil, err
 			}
-			volumeInitializationRate = int32(parseInitRate)
 		case ThroughputKey:
 			parseThroughput, parseThroughputErr := strconv.ParseInt(value, 10, 32)
 			if parseThroughputErr != nil {
 				return nil, status.Errorf(codes.InvalidArgument, "Could not parse invalid throughput: %v", parseThroughputErr)
 			}
 			throughput = int32(parseThroughput)
+		case ThroughputPerGiBKey:
+			parseThroughputPerGiB, parseThroughputPerGiBErr := strconv.ParseFloat(value, 64)
+			if parseThroughputPerGiBErr != nil || parseThroughputPerGiB <= 0 {
+				return nil, status.Errorf(codes.InvalidArgument, "Could not parse invalid throughputPerGiB: %q", value)
+			}
+			throughputPerGiB = parseThroughputPerGiB
 		case EncryptedKey:
 			isEncrypted = isTrue(value)
 			encryptedKey = value
@@ -187,8 +289,6 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 		case PVNameKey:
 			volumeTags[PVNameTag] = value
 			tProps.PVName = value
-		case DeprecatedBlockExpressKey:
-			klog.V(2).InfoS("blockExpress key is deprecated and has no effect, all io2 volumes are now Block Express and share the same IOPS cap")
 		case BlockSizeKey:
 			if isAlphanumeric := util.StringIsAlphanumeric(value); !isAlphanumeric {
 				return nil, status.Errorf(codes.InvalidArgument, "Could not parse blockSize (%s): %v", value, err)
@@ -218,8 +318,29 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 			ext4ClusterSize = value
 		case Ext4EncryptionSupportKey:
 			ext4EncryptionSupport = isTrue(value)
+		case Ext4FscryptKey:
+			ext4Fscrypt = isTrue(value)
+		case XfsProjectQuotaKey:
+			xfsProjectQuota = isTrue(value)
 		case BlockAttachUntilInitializedKey:
 			blockAttachUntilInitialized = isTrue(value)
+		case MultiAttachKey:
+			multiAttachParam = value
+		case APIBudgetClassKey:
+			if _, ok := d.options.APIBudgetWeights[value]; d.options.APIBudgetRate > 0 && !ok && value != cloud.DefaultAPIBudgetClass {
+				return nil, status.Errorf(codes.InvalidArgument, "Unknown API budget class %q, must be set in --api-budget-weights", value)
+			}
+			apiBudgetClass = value
+		case OutpostArnKey:
+			if parsed, parseErr := arn.Parse(value); parseErr != nil || parsed.Service != "outposts" {
+				return nil, status.Errorf(codes.InvalidArgument, "Invalid parameter value %s is not a valid Outpost arn", value)
+			}
+			outpostArnParam = value
+		case FallbackVolumeTypesKey:
+			fallbackVolumeTypes, err = parseFallbackVolumeTypes(value)
+			if err != nil {
+				return nil, err
+			}
 		default:
 			if strings.HasPrefix(key, TagKeyPrefix) {
 				tagsToEvaluate = append(tagsToEvaluate, value)
@@ -229,7 +350,17 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 		}
 	}
 
-	mutableParameters := req.GetMutableParameters()
+	if throughputPerGiB > 0 {
+		if throughput > 0 {
+			return nil, status.Error(codes.InvalidArgument, "Specify either throughput or throughputPerGiB, not both")
+		}
+		if volumeType != "" && !strings.EqualFold(volumeType, cloud.VolumeTypeGP3) {
+			return nil, status.Errorf(codes.InvalidArgument, "throughputPerGiB is only supported for gp3 volumes, not %q", volumeType)
+		}
+		throughput = gp3ThroughputForSize(throughputPerGiB, volSizeBytes)
+	}
+
+	mutableParameters := volumeAttributesClassParameters.resolve(req.GetMutableParameters())
 
 	// "Values specified in mutable_parameters MUST take precedence over the values from parameters."
 	// https://github.com/container-storage-interface/spec/blob/master/spec.md#createvolume
@@ -247,13 +378,6 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 				return nil, status.Errorf(codes.InvalidArgument, "Could not parse throughput: %q", value)
 			}
 			throughput = int32(vacThroughput)
-		case DeprecatedModificationKeyVolumeType:
-			if _, ok := mutableParameters[ModificationKeyVolumeType]; ok {
-				klog.Infof("Ignoring deprecated key `volumeType` because preferred key `type` is present")
-				continue
-			}
-			klog.InfoS("Key `volumeType` is deprecated, please use `type` instead")
-			volumeType = value
 		case VolumeTypeKey:
 			volumeType = value
 		default:
@@ -266,10 +390,50 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 		}
 	}
 
+	if minSizeBytes := util.GiBToBytes(cloud.MinVolumeSizeGiB(volumeType)); roundUpToMinimumSize && volSizeBytes < minSizeBytes {
+		if limit := req.GetCapacityRange().GetLimitBytes(); limit > 0 && limit < minSizeBytes {
+			return nil, status.Errorf(codes.InvalidArgument, "%s volumes must be at least %d GiB, which exceeds the limit specified", volumeType, cloud.MinVolumeSizeGiB(volumeType))
+		}
+		klog.V(4).InfoS("CreateVolume: rounding up the size to the minimum of the volume type", "volumeID", volName, "volumeType", volumeType, "requestedBytes", volSizeBytes, "sizeBytes", minSizeBytes)
+		volSizeBytes = minSizeBytes
+	}
+
+	if multiAttachParam != "" {
+		if !isTrue(multiAttachParam) && multiAttach {
+			return nil, status.Errorf(codes.InvalidArgument, "Volume capabilities with multi-node multi-writer access require %s to be true", MultiAttachKey)
+		}
+		multiAttach = isTrue(multiAttachParam)
+		if multiAttach && volumeType != cloud.VolumeTypeIO2 {
+			return nil, status.Errorf(codes.InvalidArgument, "Multi-attach is only supported for io2 volumes, not %q", volumeType)
+		}
+	}
+	if multiAttach && len(fallbackVolumeTypes) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "fallbackVolumeTypes cannot be specified for multi-attach volumes, which must be io2")
+	}
+
 	for key, value := range d.options.ExtraTags {
 		tagsToEvaluate = append(tagsToEvaluate, key+"="+value)
 	}
 
+	nameTemplate := ""
+	if d.options.NameTagFromTemplate {
+		nameTemplate = d.options.NameTagTemplate
+	}
+	// Volume tag policies select PVCs by label
+	if referencesPVCMetadata(append(tagsToEvaluate, nameTemplate)...) || d.tagPolicies != nil {
+		if err = d.pvcMetadata.load(ctx, tProps); err != nil {
+			if !d.options.WarnOnInvalidTag {
+				return nil, status.Errorf(codes.InvalidArgument, "Error reading PVC labels and annotations for tag values: %v", err)
+			}
+			klog.InfoS("Unable to read PVC labels and annotations for tag values", "volumeName", volName, "err", err)
+		}
+	}
+	policyTags, err := d.volumeTagPolicyTags(tProps)
+	if err != nil {
+		return nil, err
+	}
+	tagsToEvaluate = append(tagsToEvaluate, policyTags...)
+
 	addTags, err := template.Evaluate(tagsToEvaluate, tProps, d.options.WarnOnInvalidTag)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "Error interpolating tag value: %v", err)
@@ -288,6 +452,18 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 		volumeTags[ClusterNameTagKey] = d.options.KubernetesClusterID
 	}
 
+	if d.options.NameTagFromTemplate && (tProps.PVCName != "" || tProps.PVName != "") {
+		name, nameErr := templatedNameTag(d.options.NameTagTemplate, tProps, volName)
+		switch {
+		case nameErr != nil && !d.options.WarnOnInvalidTag:
+			return nil, status.Errorf(codes.InvalidArgument, "Error interpolating Name tag: %v", nameErr)
+		case nameErr != nil:
+			klog.InfoS("Unable to interpolate Name tag", "template", d.options.NameTagTemplate, "err", nameErr)
+		case name != "":
+			volumeTags[NameTag] = name
+		}
+	}
+
 	maps.Copy(volumeTags, addTags)
 
 	responseCtx := map[string]string{}
@@ -334,14 +510,40 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 			return nil, err
 		}
 	}
+	if ext4Fscrypt {
+		responseCtx[Ext4FscryptKey] = trueStr
+		if err = validateFormattingOption(volCap, Ext4FscryptKey, FileSystemConfigs); err != nil {
+			return nil, err
+		}
+	}
+	if xfsProjectQuota {
+		responseCtx[XfsProjectQuotaKey] = trueStr
+		if err = validateFormattingOption(volCap, XfsProjectQuotaKey, FileSystemConfigs); err != nil {
+			return nil, err
+		}
+	}
 	if blockAttachUntilInitialized {
 		responseCtx[BlockAttachUntilInitializedKey] = trueStr
 	}
+	if multiAttach {
+		responseCtx[MultiAttachKey] = trueStr
+	}
+	if apiBudgetClass != "" {
+		responseCtx[APIBudgetClassKey] = apiBudgetClass
+		ctx = util.WithAPIBudgetClass(ctx, apiBudgetClass)
+	}
 
 	if !ext4BigAlloc && len(ext4ClusterSize) > 0 {
 		return nil, status.Errorf(codes.InvalidArgument, "Cannot set ext4BigAllocClusterSize when ext4BigAlloc is false")
 	}
 
+	if d.options.ForceEncryption {
+		isEncrypted, kmsKeyID, err = forceEncryption(d.options, encryptedKey, kmsKeyID, req.GetVolumeContentSource().GetVolume() != nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	snapshotID := ""
 	volumeID := ""
 	volumeSource := req.GetVolumeContentSource()
@@ -376,24 +578,72 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 		sourceVolume, err := d.cloud.GetDiskByID(ctx, volumeID)
 
 		if err != nil {
-			return nil, status.Errorf(codes.NotFound, "Error source volume with volumeID %v not found: %v", volumeID, err)
+			return nil, statusWithAWSDetails(codes.NotFound, err, "Error source volume with volumeID %v not found: %v", volumeID, err)
+		}
+
+		if d.options.ForceEncryption && !sourceVolume.Encrypted {
+			return nil, status.Errorf(codes.InvalidArgument, "Cannot clone unencrypted volume %s: the driver encrypts every volume (--force-encryption)", volumeID)
 		}
 
 		if kmsKeyID != "" && sourceVolume.KmsKeyID != kmsKeyID {
-			return nil, status.Errorf(codes.InvalidArgument, "Cannot provision clone with different KMS key than source volume")
+			// The key of the StorageClass may be an alias or key ID of the key of the source volume, which EC2 reports by ARN
+			kmsKeyARN, err := d.cloud.ResolveKMSKey(ctx, kmsKeyID)
+			if err != nil {
+				if errors.Is(err, cloud.ErrInvalidArgument) {
+					return nil, status.Errorf(codes.InvalidArgument, "Could not resolve KMS key: %v", err)
+				}
+				return nil, status.Errorf(codes.Internal, "Could not resolve KMS key: %v", err)
+			}
+			if sourceVolume.KmsKeyID != kmsKeyARN {
+				return nil, status.Errorf(codes.InvalidArgument, "Cannot provision clone with different KMS key than source volume")
+			}
 		}
 
 		err = checkSourceTopology(req.GetAccessibilityRequirements(), sourceVolume.AvailabilityZone, sourceVolume.OutpostArn, sourceVolume.AvailabilityZoneID)
 		if err != nil {
 			return nil, err
 		}
+		if outpostArnParam != "" && sourceVolume.OutpostArn != outpostArnParam {
+			return nil, status.Errorf(codes.InvalidArgument, "Cannot provision clone on Outpost %s, source volume is not on it", outpostArnParam)
+		}
 		zone = sourceVolume.AvailabilityZone
 		zoneID = sourceVolume.AvailabilityZoneID
 		outpostArn = sourceVolume.OutpostArn
+	} else if outpostArnParam != "" {
+		topology := pickOutpostTopology(req.GetAccessibilityRequirements(), outpostArnParam)
+		if topology == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Outpost %s is not in the accessible topology of the volume, use volumeBindingMode WaitForFirstConsumer or allowedTopologies with %s", outpostArnParam, AwsOutpostIDKey)
+		}
+		zone = topologyZone(topology)
+		zoneID = topology.GetSegments()[ZoneIDTopologyKey]
+		outpostArn = outpostArnParam
+	} else if topology := d.zonePicker.pick(ctx, volName, volumeType, volumeTypeTopologies(req.GetAccessibilityRequirements(), volumeType), tProps.PVCNamespace, tProps.PVCName); topology != nil {
+		zone = topologyZone(topology)
+		zoneID = topology.GetSegments()[ZoneIDTopologyKey]
 	} else {
-		zone = pickAvailabilityZone(req.GetAccessibilityRequirements())
-		zoneID = pickAvailabilityZoneID(req.GetAccessibilityRequirements())
-		outpostArn = getOutpostArn(req.GetAccessibilityRequirements())
+		requirement := volumeTypeTopologies(req.GetAccessibilityRequirements(), volumeType)
+		zone = pickAvailabilityZone(requirement)
+		zoneID = pickAvailabilityZoneID(requirement)
+		outpostArn = getOutpostArn(requirement)
+	}
+
+	if snapshotID != "" {
+		snapshot, topology, err := d.checkSnapshotRestore(ctx, snapshotID, req.GetAccessibilityRequirements(), outpostArn)
+		if err != nil {
+			return nil, err
+		}
+		if topology != nil {
+			zone = topologyZone(topology)
+			zoneID = topology.GetSegments()[ZoneIDTopologyKey]
+			outpostArn = BuildOutpostArn(topology.GetSegments())
+		}
+		// The rate of the StorageClass overrides the rate of the VolumeSnapshotClass the snapshot was taken with
+		if volumeInitializationRate == 0 && snapshot != nil {
+			volumeInitializationRate = snapshot.VolumeInitializationRate
+		}
+	} else if volumeInitializationRate > 0 {
+		klog.V(4).InfoS("CreateVolume: ignoring volumeInitializationRate of volume not restored from a snapshot", "volumeName", volName)
+		volumeInitializationRate = 0
 	}
 
 	opts := &cloud.DiskOptions{
@@ -415,8 +665,20 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 		VolumeInitializationRate: volumeInitializationRate,
 	}
 
-	disk, err := d.cloud.CreateDisk(ctx, volName, opts)
+	if err = d.quotaBackoff.check(volumeType); err != nil {
+		return nil, err
+	}
+
+	release, err := d.namespaceQuotas.reserve(ctx, tProps, volName, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	disk, err := d.createDiskWithFallback(ctx, volName, opts, fallbackVolumeTypes, tProps)
+	release(disk)
+	d.quotaBackoff.record(volumeType, err)
 	if err != nil {
+		d.zonePicker.recordFailure(volName, zone, err)
 		var errCode codes.Code
 		switch {
 		case errors.Is(err, cloud.ErrIdempotentParameterMismatch), errors.Is(err, cloud.ErrAlreadyExists):
@@ -426,10 +688,20 @@ func (d *ControllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 		case errors.Is(err, cloud.ErrSourceNotFound):
 			errCode = codes.NotFound
 		default:
-			errCode = codes.Aborted
+			return nil, awsErrorToStatus(err, codes.Aborted, "Could not create volume %q: %v", volName, err)
 		}
-		return nil, status.Errorf(errCode, "Could not create volume %q: %v", volName, err)
+		return nil, statusWithAWSDetails(errCode, err, "Could not create volume %q: %v", volName, err)
 	}
+	if snapshotID != "" {
+		d.restoreProgress.track(disk.VolumeID, tProps.PVCNamespace, tProps.PVCName)
+		if !disk.FastRestored {
+			responseCtx[LazilyLoadedKey] = trueStr
+		}
+	}
+	if disk.RequiresBlockExpress {
+		responseCtx[RequiresBlockExpressKey] = trueStr
+	}
+	d.recordVolumeCost(ctx, tProps, disk)
 	return newCreateVolumeResponse(disk, responseCtx), nil
 }
 
@@ -444,8 +716,8 @@ func validateCreateVolumeRequest(req *csi.CreateVolumeRequest) error {
 		return status.Error(codes.InvalidArgument, "Volume capabilities not provided")
 	}
 
-	if !isValidVolumeCapabilities(volCaps) {
-		return status.Error(codes.InvalidArgument, "Volume capabilities not supported")
+	if err := validateVolumeCapabilities(volCaps); err != nil {
+		return status.Errorf(codes.InvalidArgument, "Volume capabilities not supported: %v", err)
 	}
 	return nil
 }
@@ -464,12 +736,23 @@ func (d *ControllerService) DeleteVolume(ctx context.Context, req *csi.DeleteVol
 	}
 	defer d.inFlight.Delete(volumeID)
 
+	if err := d.deletionGuard.check(ctx, volumeID); err != nil {
+		return nil, err
+	}
+
+	d.handoff.record(ctx, handoffDeletePrefix, volumeID, "")
+	defer d.handoff.forget(ctx, handoffDeletePrefix, volumeID)
+
+	if d.options.SoftDeleteRetention > 0 {
+		return d.softDeleteVolume(ctx, volumeID)
+	}
+
 	if _, err := d.cloud.DeleteDisk(ctx, volumeID); err != nil {
 		if errors.Is(err, cloud.ErrNotFound) {
 			klog.V(4).InfoS("DeleteVolume: volume not found, returning with success")
 			return &csi.DeleteVolumeResponse{}, nil
 		}
-		return nil, status.Errorf(codes.Internal, "Could not delete volume ID %q: %v", volumeID, err)
+		return nil, awsErrorToStatus(err, codes.Internal, "Could not delete volume ID %q: %v", volumeID, err)
 	}
 
 	return &csi.DeleteVolumeResponse{}, nil
@@ -504,6 +787,37 @@ func (d *ControllerService) ControllerPublishVolume(ctx context.Context, req *cs
 	}
 	defer d.inFlight.Delete(volumeID + nodeID)
 
+	if class := req.GetVolumeContext()[APIBudgetClassKey]; class != "" {
+		ctx = util.WithAPIBudgetClass(ctx, class)
+	}
+
+	if req.GetVolumeContext()[MultiAttachKey] == trueStr || req.GetVolumeCapability().GetAccessMode().GetMode() == MultiNodeMultiWriter {
+		if err := d.cloud.CheckMultiAttachSupport(ctx, nodeID); err != nil {
+			if errors.Is(err, cloud.ErrMultiAttachNotSupported) {
+				return nil, statusWithAWSDetails(codes.FailedPrecondition, err, "Could not attach multi-attach volume %q: %v", volumeID, err)
+			}
+			if errors.Is(err, cloud.ErrNotFound) {
+				return nil, status.Errorf(codes.NotFound, "Instance %q not found", nodeID)
+			}
+			return nil, awsErrorToStatus(err, codes.Internal, "Could not check multi-attach support of node %q: %v", nodeID, err)
+		}
+		if err := d.checkAttachmentZone(ctx, volumeID, nodeID); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.GetVolumeContext()[RequiresBlockExpressKey] == trueStr {
+		if err := d.cloud.CheckBlockExpressSupport(ctx, nodeID); err != nil {
+			if errors.Is(err, cloud.ErrBlockExpressNotSupported) {
+				return nil, statusWithAWSDetails(codes.FailedPrecondition, err, "Could not attach io2 volume %q, its size or IOPS need Block Express: %v", volumeID, err)
+			}
+			if errors.Is(err, cloud.ErrNotFound) {
+				return nil, status.Errorf(codes.NotFound, "Instance %q not found", nodeID)
+			}
+			return nil, awsErrorToStatus(err, codes.Internal, "Could not check Block Express support of node %q: %v", nodeID, err)
+		}
+	}
+
 	klog.V(2).InfoS("ControllerPublishVolume: attaching", "volumeID", volumeID, "nodeID", nodeID)
 	devicePath, err := d.cloud.AttachDisk(ctx, volumeID, nodeID)
 	if err != nil {
@@ -511,12 +825,16 @@ func (d *ControllerService) ControllerPublishVolume(ctx context.Context, req *cs
 			return nil, status.Errorf(codes.NotFound, "Volume %q not found", volumeID)
 		}
 		if errors.Is(err, cloud.ErrLimitExceeded) {
-			return nil, status.Errorf(codes.ResourceExhausted, "Attachment limit exceeded for volume %q on node %q: %v", volumeID, nodeID, err)
+			return nil, statusWithAWSDetails(codes.ResourceExhausted, err, "Attachment limit exceeded for volume %q on node %q: %v", volumeID, nodeID, err)
 		}
-		return nil, status.Errorf(codes.Internal, "Could not attach volume %q to node %q: %v", volumeID, nodeID, err)
+		return nil, awsErrorToStatus(err, codes.Internal, "Could not attach volume %q to node %q: %v", volumeID, nodeID, err)
 	}
 	klog.InfoS("ControllerPublishVolume: attached", "volumeID", volumeID, "nodeID", nodeID, "devicePath", devicePath)
 
+	if d.options.CheckEBSBandwidth {
+		d.checkEBSBandwidth(ctx, volumeID, nodeID)
+	}
+
 	if val, ok := req.GetVolumeContext()[BlockAttachUntilInitializedKey]; ok && val == trueStr {
 		isInitialized := false
 		var err error
@@ -526,7 +844,7 @@ func (d *ControllerService) ControllerPublishVolume(ctx context.Context, req *cs
 		for !isInitialized {
 			isInitialized, err = d.cloud.IsVolumeInitialized(ctx, volumeID)
 			if err != nil {
-				return nil, status.Errorf(codes.Internal, "Cannot validate that volume %q is initialized while polling EC2 DescribeVolumeStatus: %v", volumeID, err)
+				return nil, statusWithAWSDetails(codes.Internal, err, "Cannot validate that volume %q is initialized while polling EC2 DescribeVolumeStatus: %v", volumeID, err)
 			}
 		}
 	}
@@ -549,8 +867,8 @@ func validateControllerPublishVolumeRequest(req *csi.ControllerPublishVolumeRequ
 		return status.Error(codes.InvalidArgument, "Volume capability not provided")
 	}
 
-	if !isValidCapability(volCap) {
-		return status.Error(codes.InvalidArgument, "Volume capability not supported")
+	if err := validateCapability(volCap); err != nil {
+		return status.Errorf(codes.InvalidArgument, "Volume capability not supported: %v", err)
 	}
 	return nil
 }
@@ -567,9 +885,9 @@ func (d *ControllerService) controllerPublishVolumeNodeLocal(ctx context.Context
 	realVolumeID, err := d.cloud.GetVolumeIDByNodeAndDevice(ctx, nodeID, deviceName)
 	if err != nil {
 		if errors.Is(err, cloud.ErrNotFound) {
-			return nil, status.Errorf(codes.NotFound, "Failed to find volume at device %s on node %s: %v", deviceName, nodeID, err)
+			return nil, statusWithAWSDetails(codes.NotFound, err, "Failed to find volume at device %s on node %s: %v", deviceName, nodeID, err)
 		}
-		return nil, status.Errorf(codes.Internal, "Failed to get volume at device %s on node %s: %v", deviceName, nodeID, err)
+		return nil, statusWithAWSDetails(codes.Internal, err, "Failed to get volume at device %s on node %s: %v", deviceName, nodeID, err)
 	}
 
 	klog.InfoS("ControllerPublishVolume: resolved node-local volume", "volumeID", volumeID, "realVolumeID", realVolumeID, "nodeID", nodeID, "deviceName", deviceName)
@@ -607,7 +925,7 @@ func (d *ControllerService) ControllerUnpublishVolume(ctx context.Context, req *
 			klog.InfoS("ControllerUnpublishVolume: attachment not found", "volumeID", volumeID, "nodeID", nodeID)
 			return &csi.ControllerUnpublishVolumeResponse{}, nil
 		}
-		return nil, status.Errorf(codes.Internal, "Could not detach volume %q from node %q: %v", volumeID, nodeID, err)
+		return nil, awsErrorToStatus(err, codes.Internal, "Could not detach volume %q from node %q: %v", volumeID, nodeID, err)
 	}
 	klog.InfoS("ControllerUnpublishVolume: detached", "volumeID", volumeID, "nodeID", nodeID)
 
@@ -629,8 +947,12 @@ func validateControllerUnpublishVolumeRequest(req *csi.ControllerUnpublishVolume
 func (d *ControllerService) ControllerGetCapabilities(ctx context.Context, req *csi.ControllerGetCapabilitiesRequest) (*csi.ControllerGetCapabilitiesResponse, error) {
 	klog.V(4).InfoS("ControllerGetCapabilities: called", "args", req)
 
-	caps := make([]*csi.ControllerServiceCapability, 0, len(controllerCaps))
-	for _, capability := range controllerCaps {
+	rpcs := controllerCaps
+	if d.capacityEnabled() {
+		rpcs = append(slices.Clone(rpcs), csi.ControllerServiceCapability_RPC_GET_CAPACITY)
+	}
+	caps := make([]*csi.ControllerServiceCapability, 0, len(rpcs))
+	for _, capability := range rpcs {
 		c := &csi.ControllerServiceCapability{
 			Type: &csi.ControllerServiceCapability_Rpc{
 				Rpc: &csi.ControllerServiceCapability_RPC{
@@ -643,14 +965,27 @@ func (d *ControllerService) ControllerGetCapabilities(ctx context.Context, req *
 	return &csi.ControllerGetCapabilitiesResponse{Capabilities: caps}, nil
 }
 
-func (d *ControllerService) GetCapacity(ctx context.Context, req *csi.GetCapacityRequest) (*csi.GetCapacityResponse, error) {
-	klog.V(4).InfoS("GetCapacity: called", "args", req)
-	return nil, status.Error(codes.Unimplemented, "")
-}
-
+// ListVolumes lists the volumes created by the driver, and by this cluster when --k8s-tag-cluster-id is set. The
+// published node IDs of a volume are the instances it is attached to.
 func (d *ControllerService) ListVolumes(ctx context.Context, req *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
 	klog.V(4).InfoS("ListVolumes: called", "args", req)
-	return nil, status.Error(codes.Unimplemented, "")
+	filter := map[string]string{cloud.AwsEbsDriverTagKey: isManagedByDriver}
+	if d.options.KubernetesClusterID != "" {
+		filter[ResourceLifecycleTagPrefix+d.options.KubernetesClusterID] = ResourceLifecycleOwned
+	}
+
+	cloudDisks, err := d.cloud.ListDisksPage(ctx, filter, req.GetMaxEntries(), req.GetStartingToken())
+	if err != nil {
+		if errors.Is(err, cloud.ErrInvalidMaxResults) {
+			return nil, status.Errorf(codes.InvalidArgument, "Error mapping MaxEntries to AWS MaxResults: %v", err)
+		}
+		if errors.Is(err, cloud.ErrInvalidNextToken) {
+			return nil, status.Errorf(codes.Aborted, "Invalid starting token %q: %v", req.GetStartingToken(), err)
+		}
+		return nil, awsErrorToStatus(err, codes.Internal, "Could not list volumes: %v", err)
+	}
+
+	return newListVolumesResponse(cloudDisks), nil
 }
 
 func (d *ControllerService) ValidateVolumeCapabilities(ctx context.Context, req *csi.ValidateVolumeCapabilitiesRequest) (*csi.ValidateVolumeCapabilitiesResponse, error) {
@@ -671,11 +1006,12 @@ func (d *ControllerService) ValidateVolumeCapabilities(ctx context.Context, req
 			if errors.Is(err, cloud.ErrNotFound) {
 				return nil, status.Error(codes.NotFound, "Volume not found")
 			}
-			return nil, status.Errorf(codes.Internal, "Could not get volume with ID %q: %v", volumeID, err)
+			return nil, awsErrorToStatus(err, codes.Internal, "Could not get volume with ID %q: %v", volumeID, err)
 		}
 	}
 
 	var confirmed *csi.ValidateVolumeCapabilitiesResponse_Confirmed
+	var message string
 	if isNodeLocalVolume(volumeID) {
 		// For node-local volumes, allow RWX
 		valid := true
@@ -688,11 +1024,14 @@ func (d *ControllerService) ValidateVolumeCapabilities(ctx context.Context, req
 		if valid {
 			confirmed = &csi.ValidateVolumeCapabilitiesResponse_Confirmed{VolumeCapabilities: volCaps}
 		}
-	} else if isValidVolumeCapabilities(volCaps) {
+	} else if err := validateVolumeCapabilities(volCaps); err != nil {
+		message = err.Error()
+	} else {
 		confirmed = &csi.ValidateVolumeCapabilitiesResponse_Confirmed{VolumeCapabilities: volCaps}
 	}
 	return &csi.ValidateVolumeCapabilitiesResponse{
 		Confirmed: confirmed,
+		Message:   message,
 	}, nil
 }
 
@@ -706,6 +1045,13 @@ func (d *ControllerService) ControllerExpandVolume(ctx context.Context, req *csi
 	if isNodeLocalVolume(volumeID) {
 		return nil, status.Error(codes.InvalidArgument, "node-local volumes cannot be expanded")
 	}
+	owner, err := d.shardOwner(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil {
+		return csi.NewControllerClient(owner).ControllerExpandVolume(shardForwardedContext(ctx), req)
+	}
 
 	capRange := req.GetCapacityRange()
 	if capRange == nil {
@@ -718,11 +1064,11 @@ func (d *ControllerService) ControllerExpandVolume(ctx context.Context, req *csi
 		return nil, status.Error(codes.InvalidArgument, "After round-up, volume size exceeds the limit specified")
 	}
 
-	actualSizeGiB, err := d.modifyVolumeCoalescer.Coalesce(volumeID, modifyVolumeRequest{
+	actualSizeGiB, err := d.modifyVolumeCoalescer.Coalesce(ctx, volumeID, modifyVolumeRequest{
 		newSize: newSize,
 	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "Could not resize volume %q: %v", volumeID, err)
+		return nil, statusWithAWSDetails(codes.Internal, err, "Could not resize volume %q: %v", volumeID, err)
 	}
 
 	nodeExpansionRequired := true
@@ -749,13 +1095,24 @@ func (d *ControllerService) ControllerModifyVolume(ctx context.Context, req *csi
 	if isNodeLocalVolume(volumeID) {
 		return nil, status.Error(codes.InvalidArgument, "node-local volumes cannot be modified")
 	}
+	owner, err := d.shardOwner(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil {
+		return csi.NewControllerClient(owner).ControllerModifyVolume(shardForwardedContext(ctx), req)
+	}
 
+	d.parameters.reportDeprecated(ctx, "ControllerModifyVolume", volumeAttributesClassParameters.deprecatedIn(req.GetMutableParameters()), req.GetMutableParameters())
+	if err := d.checkParameterValues(ctx, "ControllerModifyVolume", volumeAttributesClassParameters, req.GetMutableParameters(), req.GetMutableParameters()); err != nil {
+		return nil, err
+	}
 	options, err := parseModifyVolumeParameters(req.GetMutableParameters())
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = d.modifyVolumeCoalescer.Coalesce(volumeID, modifyVolumeRequest{
+	_, err = d.modifyVolumeCoalescer.Coalesce(ctx, volumeID, modifyVolumeRequest{
 		modifyDiskOptions: options.modifyDiskOptions,
 		modifyTagsOptions: options.modifyTagsOptions,
 	})
@@ -766,39 +1123,68 @@ func (d *ControllerService) ControllerModifyVolume(ctx context.Context, req *csi
 	return &csi.ControllerModifyVolumeResponse{}, nil
 }
 
+// ControllerGetVolume returns a volume with the instances it is attached to and its condition according to EC2
+// DescribeVolumeStatus, which external-health-monitor reports on the PV.
 func (d *ControllerService) ControllerGetVolume(ctx context.Context, req *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
 	klog.V(4).InfoS("ControllerGetVolume: called", "args", req)
-	return nil, status.Error(codes.Unimplemented, "")
+	volumeID := req.GetVolumeId()
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
+	}
+	if isNodeLocalVolume(volumeID) {
+		return nil, status.Error(codes.InvalidArgument, "node-local volumes have no EBS volume condition")
+	}
+
+	disk, err := d.cloud.GetDiskByID(ctx, volumeID)
+	if err != nil {
+		if errors.Is(err, cloud.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "Volume not found")
+		}
+		return nil, awsErrorToStatus(err, codes.Internal, "Could not get volume with ID %q: %v", volumeID, err)
+	}
+	health, err := d.cloud.GetVolumeHealth(ctx, []string{volumeID})
+	if err != nil {
+		return nil, awsErrorToStatus(err, codes.Internal, "Could not get status of volume %q: %v", volumeID, err)
+	}
+
+	return &csi.ControllerGetVolumeResponse{
+		Volume: newCreateVolumeResponse(disk, nil).GetVolume(),
+		Status: &csi.ControllerGetVolumeResponse_VolumeStatus{
+			PublishedNodeIds: disk.Attachments,
+			VolumeCondition:  newVolumeCondition(volumeID, health[volumeID]),
+		},
+	}, nil
 }
 
-func isValidVolumeCapabilities(v []*csi.VolumeCapability) bool {
+// errMultiWriterFilesystem is returned for multi-node multi-writer capabilities of filesystem volumes. Multi-attach
+// volumes can only be used as raw block devices, as the filesystems the driver formats are not cluster-aware.
+var errMultiWriterFilesystem = errors.New("access mode MULTI_NODE_MULTI_WRITER is only supported in Block mode, filesystem volumes cannot be attached to several nodes")
+
+// validateVolumeCapabilities returns why one of the volume capabilities is not supported, or nil.
+func validateVolumeCapabilities(v []*csi.VolumeCapability) error {
 	for _, c := range v {
-		if !isValidCapability(c) {
-			return false
+		if err := validateCapability(c); err != nil {
+			return err
 		}
 	}
-	return true
+	return nil
 }
 
-func isValidCapability(c *csi.VolumeCapability) bool {
+// validateCapability returns why a volume capability is not supported, or nil.
+func validateCapability(c *csi.VolumeCapability) error {
 	accessMode := c.GetAccessMode().GetMode()
 
 	//nolint:exhaustive
 	switch accessMode {
 	case SingleNodeWriter:
-		return true
-
+		return nil
 	case MultiNodeMultiWriter:
 		if isBlock(c) {
-			return true
-		} else {
-			klog.InfoS("isValidCapability: access mode is only supported for block devices", "accessMode", accessMode)
-			return false
+			return nil
 		}
-
+		return errMultiWriterFilesystem
 	default:
-		klog.InfoS("isValidCapability: access mode is not supported", "accessMode", accessMode)
-		return false
+		return fmt.Errorf("access mode %s is not supported", accessMode)
 	}
 }
 
@@ -909,6 +1295,11 @@ func (d *ControllerService) CreateSnapshot(ctx context.Context, req *csi.CreateS
 				return nil, status.Errorf(codes.InvalidArgument, "Could not parse SnapshotLockCoolOffPeriod: %q", value)
 			}
 			vsLock.CoolOffPeriod = aws.Int32(int32(lockCoolOffPeriod))
+		case VolumeInitializationRateKey:
+			if _, err := parseVolumeInitializationRate(value); err != nil {
+				return nil, err
+			}
+			snapshotTags[cloud.VolumeInitializationRateTagKey] = value
 		default:
 			if strings.HasPrefix(key, TagKeyPrefix) {
 				vscTags = append(vscTags, value)
@@ -918,6 +1309,12 @@ func (d *ControllerService) CreateSnapshot(ctx context.Context, req *csi.CreateS
 		}
 	}
 
+	policyTags, err := d.snapshotTagPolicyTags(ctx, vsProps)
+	if err != nil {
+		return nil, err
+	}
+	vscTags = append(vscTags, policyTags...)
+
 	addTags, err := template.Evaluate(vscTags, vsProps, d.options.WarnOnInvalidTag)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "Error interpolating tag value: %v", err)
@@ -933,6 +1330,17 @@ func (d *ControllerService) CreateSnapshot(ctx context.Context, req *csi.CreateS
 		snapshotTags[NameTag] = d.options.KubernetesClusterID + "-dynamic-" + snapshotName
 		snapshotTags[ClusterNameTagKey] = d.options.KubernetesClusterID
 	}
+	if d.options.NameTagFromTemplate && vsProps.VolumeSnapshotName != "" {
+		name, nameErr := templatedNameTag(d.options.SnapshotNameTagTemplate, vsProps, snapshotName)
+		switch {
+		case nameErr != nil && !d.options.WarnOnInvalidTag:
+			return nil, status.Errorf(codes.InvalidArgument, "Error interpolating Name tag: %v", nameErr)
+		case nameErr != nil:
+			klog.InfoS("Unable to interpolate Name tag", "template", d.options.SnapshotNameTagTemplate, "err", nameErr)
+		case name != "":
+			snapshotTags[NameTag] = name
+		}
+	}
 	maps.Copy(snapshotTags, d.options.ExtraTags)
 
 	maps.Copy(snapshotTags, addTags)
@@ -957,18 +1365,25 @@ func (d *ControllerService) CreateSnapshot(ctx context.Context, req *csi.CreateS
 		}
 	}
 
+	release, err := d.snapshotLimiter.acquire(ctx, vsProps.VolumeSnapshotNamespace)
+	if err != nil {
+		return nil, err
+	}
 	snapshot, err = d.cloud.CreateSnapshot(ctx, volumeID, opts)
+	release()
 	if err != nil {
 		if errors.Is(err, cloud.ErrAlreadyExists) {
 			return nil, status.Errorf(codes.AlreadyExists, "Snapshot %q already exists", snapshotName)
 		} else if errors.Is(err, cloud.ErrLimitExceeded) {
-			return nil, status.Errorf(codes.ResourceExhausted, "Could not create snapshot (resource exhausted) %q: %v", snapshotName, err)
+			return nil, statusWithAWSDetails(codes.ResourceExhausted, err, "Could not create snapshot (resource exhausted) %q: %v", snapshotName, err)
 		}
-		return nil, status.Errorf(codes.Internal, "Could not create snapshot %q: %v", snapshotName, err)
+		return nil, awsErrorToStatus(err, codes.Internal, "Could not create snapshot %q: %v", snapshotName, err)
 	}
 
 	if len(fsrAvailabilityZones) > 0 {
+		d.handoff.record(ctx, handoffFSRPrefix, snapshot.SnapshotID, strings.Join(fsrAvailabilityZones, ","))
 		_, err := d.cloud.EnableFastSnapshotRestores(ctx, fsrAvailabilityZones, snapshot.SnapshotID)
+		d.handoff.forget(ctx, handoffFSRPrefix, snapshot.SnapshotID)
 		if err != nil {
 			return nil, d.cleanupSnapshotOnError(ctx, snapshot.SnapshotID, snapshotName, err, "Failed to create Fast Snapshot Restores")
 		}
@@ -982,6 +1397,7 @@ func (d *ControllerService) CreateSnapshot(ctx context.Context, req *csi.CreateS
 		}
 	}
 
+	d.recordSnapshotCost(vsProps, snapshot)
 	return newCreateSnapshotResponse(snapshot), nil
 }
 
@@ -1021,7 +1437,7 @@ func (d *ControllerService) DeleteSnapshot(ctx context.Context, req *csi.DeleteS
 			klog.V(4).InfoS("DeleteSnapshot: snapshot not found, returning with success")
 			return &csi.DeleteSnapshotResponse{}, nil
 		}
-		return nil, status.Errorf(codes.Internal, "Could not delete snapshot ID %q: %v", snapshotID, err)
+		return nil, awsErrorToStatus(err, codes.Internal, "Could not delete snapshot ID %q: %v", snapshotID, err)
 	}
 
 	return &csi.DeleteSnapshotResponse{}, nil
@@ -1046,7 +1462,7 @@ func (d *ControllerService) ListSnapshots(ctx context.Context, req *csi.ListSnap
 				klog.V(4).InfoS("ListSnapshots: snapshot not found, returning with success")
 				return &csi.ListSnapshotsResponse{}, nil
 			}
-			return nil, status.Errorf(codes.Internal, "Could not get snapshot ID %q: %v", snapshotID, err)
+			return nil, awsErrorToStatus(err, codes.Internal, "Could not get snapshot ID %q: %v", snapshotID, err)
 		}
 		snapshots = append(snapshots, snapshot)
 		response := newListSnapshotsResponse(&cloud.ListSnapshotsResponse{
@@ -1068,7 +1484,7 @@ func (d *ControllerService) ListSnapshots(ctx context.Context, req *csi.ListSnap
 		if errors.Is(err, cloud.ErrInvalidMaxResults) {
 			return nil, status.Errorf(codes.InvalidArgument, "Error mapping MaxEntries to AWS MaxResults: %v", err)
 		}
-		return nil, status.Errorf(codes.Internal, "Could not list snapshots: %v", err)
+		return nil, awsErrorToStatus(err, codes.Internal, "Could not list snapshots: %v", err)
 	}
 
 	response := newListSnapshotsResponse(cloudSnapshots)
@@ -1144,6 +1560,42 @@ func getOutpostArn(requirement *csi.TopologyRequirement) string {
 	return ""
 }
 
+// pickOutpostTopology returns the preferred or requisite topology of the Outpost outpostArn, or nil if the
+// requirement has none.
+func pickOutpostTopology(requirement *csi.TopologyRequirement, outpostArn string) *csi.Topology {
+	for _, topology := range slices.Concat(requirement.GetPreferred(), requirement.GetRequisite()) {
+		if BuildOutpostArn(topology.GetSegments()) == outpostArn {
+			return topology
+		}
+	}
+	return nil
+}
+
+// volumeTypeTopologies returns the requirement without the topologies of the Local Zones and Wavelength Zones that do
+// not support volumeType, so that an io2 volume whose requirement allows both an Availability Zone and a Local Zone is
+// created in the Availability Zone. The requirement is returned unchanged if none of its topologies supports the type,
+// for CreateDisk to fail with an error naming the supported types.
+func volumeTypeTopologies(requirement *csi.TopologyRequirement, volumeType string) *csi.TopologyRequirement {
+	if requirement == nil || volumeType == "" {
+		return requirement
+	}
+	supports := func(topology *csi.Topology) bool {
+		if _, ok := topology.GetSegments()[AwsOutpostIDKey]; ok {
+			return true
+		}
+		return cloud.ZoneSupportsVolumeType(topologyZone(topology), topology.GetSegments()[ZoneIDTopologyKey], volumeType)
+	}
+	preferred := slices.DeleteFunc(slices.Clone(requirement.GetPreferred()), func(t *csi.Topology) bool { return !supports(t) })
+	requisite := slices.DeleteFunc(slices.Clone(requirement.GetRequisite()), func(t *csi.Topology) bool { return !supports(t) })
+	if len(preferred) == len(requirement.GetPreferred()) && len(requisite) == len(requirement.GetRequisite()) {
+		return requirement
+	}
+	if len(preferred) == 0 && len(requisite) == 0 {
+		return requirement
+	}
+	return &csi.TopologyRequirement{Preferred: preferred, Requisite: requisite}
+}
+
 // Check if source volumes topology matches with clones requisite topology requirements.
 func checkSourceTopology(requirement *csi.TopologyRequirement, sourceVolumeZone string, sourceVolumeOutpostArn string, sourceVolumeZoneID string) error {
 	if requirement.GetRequisite() == nil || requirement == nil {
@@ -1252,6 +1704,22 @@ func newCreateSnapshotResponse(snapshot *cloud.Snapshot) *csi.CreateSnapshotResp
 	}
 }
 
+func newListVolumesResponse(cloudResponse *cloud.ListDisksResponse) *csi.ListVolumesResponse {
+	entries := make([]*csi.ListVolumesResponse_Entry, 0, len(cloudResponse.Disks))
+	for _, disk := range cloudResponse.Disks {
+		entries = append(entries, &csi.ListVolumesResponse_Entry{
+			Volume: newCreateVolumeResponse(disk, nil).GetVolume(),
+			Status: &csi.ListVolumesResponse_VolumeStatus{
+				PublishedNodeIds: disk.Attachments,
+			},
+		})
+	}
+	return &csi.ListVolumesResponse{
+		Entries:   entries,
+		NextToken: cloudResponse.NextToken,
+	}
+}
+
 func newListSnapshotsResponse(cloudResponse *cloud.ListSnapshotsResponse) *csi.ListSnapshotsResponse {
 	entries := make([]*csi.ListSnapshotsResponse_Entry, 0, len(cloudResponse.Snapshots))
 	for _, snapshot := range cloudResponse.Snapshots {
@@ -1293,6 +1761,13 @@ func getVolSizeBytes(req *csi.CreateVolumeRequest) (int64, error) {
 	return volSizeBytes, nil
 }
 
+// gp3ThroughputForSize returns the throughput in MiB/s of a gp3 volume of volSizeBytes with throughputPerGiB, clamped
+// to the throughput supported by gp3 volumes.
+func gp3ThroughputForSize(throughputPerGiB float64, volSizeBytes int64) int32 {
+	throughput := throughputPerGiB * float64(util.BytesToGiB(volSizeBytes))
+	return int32(min(max(throughput, gp3MinThroughput), gp3MaxThroughput))
+}
+
 // BuildOutpostArn returns the string representation of the outpost ARN from the given csi.TopologyRequirement.segments.
 func BuildOutpostArn(segments map[string]string) string {
 	if len(segments[AwsPartitionKey]) == 0 {
@@ -1343,7 +1818,7 @@ func isTrue(value string) bool {
 
 func (d *ControllerService) cleanupSnapshotOnError(ctx context.Context, snapshotID, snapshotName string, originalErr error, errorMsg string) error {
 	if _, deleteErr := d.cloud.DeleteSnapshot(ctx, snapshotID); deleteErr != nil {
-		return status.Errorf(codes.Internal, "Could not delete snapshot ID %q: %v", snapshotName, deleteErr)
+		return awsErrorToStatus(deleteErr, codes.Internal, "Could not delete snapshot ID %q: %v", snapshotName, deleteErr)
 	}
-	return status.Errorf(codes.Internal, "%s for snapshot ID %q: %v", errorMsg, snapshotName, originalErr)
+	return awsErrorToStatus(originalErr, codes.Internal, "%s for snapshot ID %q: %v", errorMsg, snapshotName, originalErr)
 }
@@ -0,0 +1,80 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+// attachZoneMismatchReason is the reason of the events emitted when a multi-attach volume is published to a node in
+// another Availability Zone.
+const attachZoneMismatchReason = "MultiAttachZoneMismatch"
+
+// zoneMismatchReporter explains with an event on the PV why a multi-attach volume cannot be attached to a node of
+// another Availability Zone. Multi-attach lets several instances share a volume, but only within its Availability
+// Zone, which new users of multi-attach regularly overlook.
+type zoneMismatchReporter struct {
+	pvs      *pvIndex
+	recorder record.EventRecorder
+}
+
+func newZoneMismatchReporter(k kubernetes.Interface, pvs *pvIndex) *zoneMismatchReporter {
+	if k == nil {
+		return nil
+	}
+	return &zoneMismatchReporter{pvs: pvs, recorder: newEventRecorder(k)}
+}
+
+// report emits a warning event on the PV of the volume, if it has one.
+func (r *zoneMismatchReporter) report(ctx context.Context, volumeID, nodeID string, err error) {
+	if r == nil {
+		return
+	}
+	pv, findErr := r.pvs.get(ctx, volumeID)
+	if findErr != nil || pv == nil {
+		klog.V(4).InfoS("ControllerPublishVolume: no PV to report the zone mismatch on", "volumeID", volumeID, "err", findErr)
+		return
+	}
+	r.recorder.Eventf(pv, corev1.EventTypeWarning, attachZoneMismatchReason,
+		"Cannot attach volume %s to node %s: %v. EBS volumes are scoped to an Availability Zone, multi-attach only shares a volume between instances of its Availability Zone: schedule the pods using it on nodes of that zone", volumeID, nodeID, err)
+}
+
+// checkAttachmentZone fails with FailedPrecondition before attaching a multi-attach volume to a node of another
+// Availability Zone, rather than waiting for the attachment to fail in EC2.
+func (d *ControllerService) checkAttachmentZone(ctx context.Context, volumeID, nodeID string) error {
+	err := d.cloud.CheckAttachmentZone(ctx, volumeID, nodeID)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, cloud.ErrZoneMismatch):
+		d.zoneMismatch.report(ctx, volumeID, nodeID, err)
+		return status.Errorf(codes.FailedPrecondition, "Could not attach multi-attach volume %q, EBS volumes can only be attached to instances of their Availability Zone: %v", volumeID, err)
+	case errors.Is(err, cloud.ErrNotFound):
+		return status.Errorf(codes.NotFound, "Volume %q or instance %q not found", volumeID, nodeID)
+	default:
+		return awsErrorToStatus(err, codes.Internal, "Could not check the Availability Zone of volume %q and node %q: %v", volumeID, nodeID, err)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestCheckAttachmentZone(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	zoneErr := fmt.Errorf("%w: volume vol-test is in us-east-1a but instance i-test is in us-east-1b", cloud.ErrZoneMismatch)
+	mockCloud.EXPECT().CheckAttachmentZone(gomock.Any(), "vol-test", "i-test").Return(zoneErr)
+	mockCloud.EXPECT().CheckAttachmentZone(gomock.Any(), "vol-other", "i-test").Return(zoneErr)
+
+	recorder := record.NewFakeRecorder(10)
+	client := fake.NewClientset(newTestPV("pv-test", util.GetDriverName(), "vol-test"))
+	d := &ControllerService{cloud: mockCloud, zoneMismatch: &zoneMismatchReporter{pvs: newPVIndex(client), recorder: recorder}}
+
+	err := d.checkAttachmentZone(t.Context(), "vol-test", "i-test")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Warning MultiAttachZoneMismatch Cannot attach volume vol-test to node i-test: volume and instance are in different Availability Zones")
+
+	// Volumes without a PV fail without events
+	err = d.checkAttachmentZone(t.Context(), "vol-other", "i-test")
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Empty(t, recorder.Events)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/awslabs/volume-modifier-for-k8s/pkg/rpc"
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"k8s.io/klog/v2"
+)
+
+// queueFullRetryAfter is the retry delay suggested to callers rejected because internal queues are full.
+const queueFullRetryAfter = 5 * time.Second
+
+// queuedRequests returns the number of requests waiting on batched EC2 calls or coalesced volume modifications.
+func (d *ControllerService) queuedRequests() int {
+	queued := d.cloud.BatchQueueLen()
+	if d.modifyVolumeCoalescer != nil {
+		queued += d.modifyVolumeCoalescer.Len()
+	}
+	return queued
+}
+
+// checkQueuedRequests returns a ResourceExhausted error with a RetryInfo detail if the number of queued
+// requests has reached --max-queued-requests, so that sidecar retry storms cannot grow the queues without bound.
+func (d *ControllerService) checkQueuedRequests() error {
+	limit := d.options.MaxQueuedRequests
+	if limit <= 0 {
+		return nil
+	}
+	queued := d.queuedRequests()
+	if queued < limit {
+		return nil
+	}
+
+	klog.V(4).InfoS("Rejecting request, too many queued requests", "queued", queued, "limit", limit)
+	return queueFullError("Too many queued requests (%d, limit %d), retry after %s", queued, limit, queueFullRetryAfter)
+}
+
+// queueFullError returns a ResourceExhausted error with the given message and a RetryInfo detail suggesting to retry
+// after queueFullRetryAfter.
+func queueFullError(format string, args ...any) error {
+	st := status.Newf(codes.ResourceExhausted, format, args...)
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(queueFullRetryAfter)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// isControllerOperation returns true for the controller and modify RPCs that act on volumes and snapshots,
+// which excludes ControllerGetCapabilities.
+func isControllerOperation(fullMethod string) bool {
+	return fullMethod != csi.Controller_ControllerGetCapabilities_FullMethodName &&
+		(strings.HasPrefix(fullMethod, "/"+csi.Controller_ServiceDesc.ServiceName+"/") || strings.HasPrefix(fullMethod, "/"+rpc.Modify_ServiceDesc.ServiceName+"/"))
+}
+
+// queuedRequestsInterceptor rejects controller and modify RPCs while internal queues are full.
+// ControllerGetCapabilities is always served because it never reaches a queue.
+func (d *ControllerService) queuedRequestsInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if isControllerOperation(info.FullMethod) {
+		if err := d.checkQueuedRequests(); err != nil {
+			return nil, err
+		}
+	}
+	return handler(ctx, req)
+}
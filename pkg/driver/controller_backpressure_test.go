@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestQueuedRequestsInterceptor(t *testing.T) {
+	testCases := []struct {
+		name              string
+		method            string
+		maxQueuedRequests int
+		queued            int
+		expectedCode      codes.Code
+	}{
+		{
+			name:              "success: below limit",
+			method:            csi.Controller_CreateVolume_FullMethodName,
+			maxQueuedRequests: 10,
+			queued:            9,
+		},
+		{
+			name:              "success: limit disabled",
+			method:            csi.Controller_CreateVolume_FullMethodName,
+			maxQueuedRequests: 0,
+			queued:            1000,
+		},
+		{
+			name:              "success: capabilities are always served",
+			method:            csi.Controller_ControllerGetCapabilities_FullMethodName,
+			maxQueuedRequests: 10,
+			queued:            10,
+		},
+		{
+			name:              "success: non-controller RPCs are not limited",
+			method:            csi.Identity_Probe_FullMethodName,
+			maxQueuedRequests: 10,
+			queued:            10,
+		},
+		{
+			name:              "fail: limit reached",
+			method:            csi.Controller_DeleteVolume_FullMethodName,
+			maxQueuedRequests: 10,
+			queued:            10,
+			expectedCode:      codes.ResourceExhausted,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			mockCloud.EXPECT().BatchQueueLen().Return(tc.queued).AnyTimes()
+
+			d := &ControllerService{
+				cloud:   mockCloud,
+				options: &Options{MaxQueuedRequests: tc.maxQueuedRequests},
+			}
+
+			called := false
+			handler := func(ctx context.Context, req any) (any, error) {
+				called = true
+				return nil, nil
+			}
+			_, err := d.queuedRequestsInterceptor(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+
+			if tc.expectedCode == codes.OK {
+				require.NoError(t, err)
+				assert.True(t, called)
+				return
+			}
+			require.Error(t, err)
+			assert.False(t, called)
+			st := status.Convert(err)
+			assert.Equal(t, tc.expectedCode, st.Code())
+			require.Len(t, st.Details(), 1)
+			retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+			require.True(t, ok)
+			assert.Equal(t, queueFullRetryAfter, retryInfo.GetRetryDelay().AsDuration())
+		})
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+// ebsBandwidthOversubscribedReason is the reason of the events emitted when the volumes attached to a node can exceed
+// the EBS-optimized bandwidth of its instance type.
+const ebsBandwidthOversubscribedReason = "EBSBandwidthOversubscribed"
+
+// bandwidthReporter warns with an event on the PV of a volume attached to a node whose volumes can exceed the
+// EBS-optimized bandwidth of its instance type. The event is emitted on the PV rather than on the Node because the
+// controller only knows the instance ID of the node, not its name.
+type bandwidthReporter struct {
+	pvs      *pvIndex
+	recorder record.EventRecorder
+}
+
+func newBandwidthReporter(k kubernetes.Interface, pvs *pvIndex) *bandwidthReporter {
+	if k == nil {
+		return nil
+	}
+	return &bandwidthReporter{pvs: pvs, recorder: newEventRecorder(k)}
+}
+
+// report emits a warning event on the PV of the volume, if it has one.
+func (r *bandwidthReporter) report(ctx context.Context, volumeID, nodeID string, bandwidth *cloud.EBSBandwidth) {
+	if r == nil {
+		return
+	}
+	pv, err := r.pvs.get(ctx, volumeID)
+	if err != nil || pv == nil {
+		klog.V(4).InfoS("ControllerPublishVolume: no PV to report the EBS bandwidth oversubscription on", "volumeID", volumeID, "err", err)
+		return
+	}
+	r.recorder.Eventf(pv, corev1.EventTypeWarning, ebsBandwidthOversubscribedReason,
+		"Volumes attached to node %s can reach %d MB/s, more than the %d MB/s of EBS-optimized bandwidth of its instance type %s: they will throttle each other", nodeID, bandwidth.AttachedMBps, bandwidth.MaximumMBps, bandwidth.InstanceType)
+}
+
+// checkEBSBandwidth warns when the volumes attached to a node can together exceed the EBS-optimized bandwidth of its
+// instance type, in which case they starve each other of throughput. It never fails the attachment.
+func (d *ControllerService) checkEBSBandwidth(ctx context.Context, volumeID, nodeID string) {
+	if util.IsHyperPodNode(nodeID) {
+		return
+	}
+	bandwidth, err := d.cloud.GetEBSBandwidth(ctx, nodeID)
+	if err != nil {
+		klog.ErrorS(err, "ControllerPublishVolume: could not check EBS bandwidth of node", "nodeID", nodeID)
+		return
+	}
+	klog.V(5).InfoS("ControllerPublishVolume: EBS bandwidth of node", "nodeID", nodeID, "instanceType", bandwidth.InstanceType, "maximumMBps", bandwidth.MaximumMBps, "attachedMBps", bandwidth.AttachedMBps)
+	if !bandwidth.Oversubscribed() {
+		return
+	}
+	klog.Warningf("ControllerPublishVolume: volumes attached to node %s can reach %d MB/s, more than the %d MB/s of EBS bandwidth of its instance type %s (volume %s)",
+		nodeID, bandwidth.AttachedMBps, bandwidth.MaximumMBps, bandwidth.InstanceType, volumeID)
+	d.bandwidth.report(ctx, volumeID, nodeID, bandwidth)
+	metrics.Recorder().IncreaseCount(metrics.EBSBandwidthOversubscribed, metrics.EBSBandwidthOversubscribedHelpText, map[string]string{"instance_type": bandwidth.InstanceType})
+}
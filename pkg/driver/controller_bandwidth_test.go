@@ -0,0 +1,60 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestCheckEBSBandwidth(t *testing.T) {
+	_, registry := metrics.InitializeRecorder(false)
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	mockCloud.EXPECT().GetEBSBandwidth(gomock.Any(), "i-oversubscribed").Return(&cloud.EBSBandwidth{InstanceType: "m5.large", MaximumMBps: 593, AttachedMBps: 1125}, nil)
+	mockCloud.EXPECT().GetEBSBandwidth(gomock.Any(), "i-within").Return(&cloud.EBSBandwidth{InstanceType: "m5.large", MaximumMBps: 593, AttachedMBps: 250}, nil)
+
+	recorder := record.NewFakeRecorder(10)
+	client := fake.NewClientset(newTestPV("pv-test", util.GetDriverName(), "vol-test"))
+	d := &ControllerService{cloud: mockCloud, options: &Options{CheckEBSBandwidth: true}, bandwidth: &bandwidthReporter{pvs: newPVIndex(client), recorder: recorder}}
+	d.checkEBSBandwidth(t.Context(), "vol-test", "i-oversubscribed")
+	d.checkEBSBandwidth(t.Context(), "vol-test", "i-within")
+	// HyperPod nodes are not EC2 instances and are never checked
+	d.checkEBSBandwidth(t.Context(), "vol-test", "hyperpod-cluster-i-test")
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var count float64
+	for _, family := range families {
+		if family.GetName() == metrics.EBSBandwidthOversubscribed {
+			for _, metric := range family.GetMetric() {
+				count += metric.GetCounter().GetValue()
+			}
+		}
+	}
+	require.InDelta(t, 1, count, 0)
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Warning EBSBandwidthOversubscribed Volumes attached to node i-oversubscribed can reach 1125 MB/s, more than the 593 MB/s")
+}
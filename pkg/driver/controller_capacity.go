@@ -0,0 +1,69 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// capacityEnabled reports whether EBS quotas are configured for GetCapacity to report the storage left under them.
+func (d *ControllerService) capacityEnabled() bool {
+	return d.options != nil && (len(d.options.StorageQuotas) > 0 || d.options.VolumesPerRegionQuota > 0)
+}
+
+// GetCapacity reports the storage left under the EBS quotas of the account for the volume type of a StorageClass,
+// so that the scheduler avoids topology segments where volumes cannot be created. EBS quotas are per region, so
+// every availability zone of the region reports the same capacity. Volume types without a configured storage quota
+// report unlimited capacity until the volumes per region quota is reached.
+func (d *ControllerService) GetCapacity(ctx context.Context, req *csi.GetCapacityRequest) (*csi.GetCapacityResponse, error) {
+	klog.V(4).InfoS("GetCapacity: called", "args", req)
+	if !d.capacityEnabled() {
+		return nil, status.Error(codes.Unimplemented, "")
+	}
+
+	volumeType := cloud.VolumeTypeGP3
+	for key, value := range req.GetParameters() {
+		if strings.ToLower(key) == VolumeTypeKey {
+			volumeType = strings.ToLower(value)
+		}
+	}
+
+	usage, err := d.cloud.GetStorageUsage(ctx)
+	if err != nil {
+		return nil, awsErrorToStatus(err, codes.Internal, "Could not get EBS storage usage: %v", err)
+	}
+
+	available := int64(math.MaxInt64)
+	if quotaTiB, ok := d.options.StorageQuotas[volumeType]; ok {
+		available = max(int64(quotaTiB)*1024-usage.SizeGiB[volumeType], 0) * util.GiB
+	}
+	if quota := d.options.VolumesPerRegionQuota; quota > 0 && usage.Volumes >= quota {
+		available = 0
+	}
+	klog.V(4).InfoS("GetCapacity: storage left under EBS quotas", "volumeType", volumeType, "availableBytes", available, "volumes", usage.Volumes)
+
+	return &csi.GetCapacityResponse{AvailableCapacity: available}, nil
+}
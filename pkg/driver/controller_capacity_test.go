@@ -0,0 +1,125 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetCapacity(t *testing.T) {
+	usage := &cloud.StorageUsage{SizeGiB: map[string]int64{cloud.VolumeTypeGP3: 1024, cloud.VolumeTypeIO2: 30 * 1024}, Volumes: 10}
+	testCases := []struct {
+		name         string
+		options      *Options
+		parameters   map[string]string
+		expAvailable int64
+	}{
+		{
+			name:         "default volume type",
+			options:      &Options{StorageQuotas: map[string]int{cloud.VolumeTypeGP3: 50}},
+			expAvailable: 49 * 1024 * util.GiB,
+		},
+		{
+			name:         "volume type parameter",
+			options:      &Options{StorageQuotas: map[string]int{cloud.VolumeTypeGP3: 50, cloud.VolumeTypeIO2: 40}},
+			parameters:   map[string]string{"Type": "IO2"},
+			expAvailable: 10 * 1024 * util.GiB,
+		},
+		{
+			name:         "quota exceeded",
+			options:      &Options{StorageQuotas: map[string]int{cloud.VolumeTypeIO2: 20}},
+			parameters:   map[string]string{VolumeTypeKey: cloud.VolumeTypeIO2},
+			expAvailable: 0,
+		},
+		{
+			name:         "volume type without quota",
+			options:      &Options{StorageQuotas: map[string]int{cloud.VolumeTypeIO2: 40}},
+			parameters:   map[string]string{VolumeTypeKey: cloud.VolumeTypeST1},
+			expAvailable: math.MaxInt64,
+		},
+		{
+			name:         "volumes per region quota reached",
+			options:      &Options{StorageQuotas: map[string]int{cloud.VolumeTypeGP3: 50}, VolumesPerRegionQuota: 10},
+			expAvailable: 0,
+		},
+		{
+			name:         "volumes per region quota not reached",
+			options:      &Options{VolumesPerRegionQuota: 11},
+			expAvailable: math.MaxInt64,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			mockCloud.EXPECT().GetStorageUsage(gomock.Any()).Return(usage, nil)
+
+			d := &ControllerService{cloud: mockCloud, options: tc.options}
+			// EBS quotas are per region, every zone reports the same capacity
+			resp, err := d.GetCapacity(t.Context(), &csi.GetCapacityRequest{
+				Parameters:         tc.parameters,
+				AccessibleTopology: &csi.Topology{Segments: map[string]string{WellKnownZoneTopologyKey: "us-east-1a"}},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expAvailable, resp.GetAvailableCapacity())
+		})
+	}
+}
+
+func TestGetCapacityError(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	mockCloud.EXPECT().GetStorageUsage(gomock.Any()).Return(nil, errors.New("DescribeVolumes error"))
+
+	d := &ControllerService{cloud: mockCloud, options: &Options{VolumesPerRegionQuota: 100}}
+	_, err := d.GetCapacity(t.Context(), &csi.GetCapacityRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestGetCapacityCapability(t *testing.T) {
+	hasCapacity := func(d *ControllerService) bool {
+		resp, err := d.ControllerGetCapabilities(t.Context(), &csi.ControllerGetCapabilitiesRequest{})
+		require.NoError(t, err)
+		for _, capability := range resp.GetCapabilities() {
+			if capability.GetRpc().GetType() == csi.ControllerServiceCapability_RPC_GET_CAPACITY {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Without quotas, GetCapacity is not advertised and stays unimplemented
+	d := &ControllerService{options: &Options{}}
+	assert.False(t, hasCapacity(d))
+	_, err := d.GetCapacity(t.Context(), &csi.GetCapacityRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	assert.True(t, hasCapacity(&ControllerService{options: &Options{StorageQuotas: map[string]int{cloud.VolumeTypeGP3: 50}}}))
+	assert.True(t, hasCapacity(&ControllerService{options: &Options{VolumesPerRegionQuota: 5000}}))
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+// clientTokenRetention is how long the token number of a volume is persisted. EC2 forgets client tokens after about
+// 12 hours, after which the first token of the volume can be used again.
+const clientTokenRetention = 24 * time.Hour
+
+// clientTokenConfigMap persists the client token numbers of CreateVolume in a ConfigMap, with one key per token base
+// whose value is the token number and the time it was saved, like "2,2025-06-02T10:15:04Z". Entries older than
+// clientTokenRetention are removed whenever a token is saved.
+type clientTokenConfigMap struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	now       func() time.Time
+	// mu serializes the read-modify-write updates of the ConfigMap by this replica
+	mu sync.Mutex
+}
+
+var _ cloud.ClientTokenStore = &clientTokenConfigMap{}
+
+func newClientTokenConfigMap(k kubernetes.Interface, o *Options) *clientTokenConfigMap {
+	if o.ClientTokenConfigMap == "" {
+		return nil
+	}
+	if k == nil {
+		klog.InfoS("No Kubernetes client available, client tokens are kept in memory only", "configMap", o.ClientTokenConfigMap)
+		return nil
+	}
+	return &clientTokenConfigMap{
+		client:    k,
+		namespace: o.ClientTokenConfigMapNamespace,
+		name:      o.ClientTokenConfigMap,
+		now:       time.Now,
+	}
+}
+
+func (s *clientTokenConfigMap) Load(ctx context.Context) (map[string]int, error) {
+	cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return map[string]int{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	numbers := map[string]int{}
+	for tokenBase, value := range cm.Data {
+		number, savedAt, err := parseClientTokenEntry(value)
+		if err != nil {
+			klog.InfoS("Ignoring invalid client token entry", "tokenBase", tokenBase, "value", value, "err", err)
+			continue
+		}
+		if s.now().Sub(savedAt) < clientTokenRetention {
+			numbers[tokenBase] = number
+		}
+	}
+	return numbers, nil
+}
+
+func (s *clientTokenConfigMap) Save(ctx context.Context, tokenBase string, number int) error {
+	if errs := validation.IsConfigMapKey(tokenBase); len(errs) > 0 {
+		return fmt.Errorf("token base %q cannot be a ConfigMap key: %s", tokenBase, strings.Join(errs, ", "))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Other replicas only save tokens while they serve CreateVolume, retry if one did since the ConfigMap was read,
+	// or created it after it was found missing
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		cm, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: s.namespace, Name: s.name}}
+			cm.Data = map[string]string{tokenBase: formatClientTokenEntry(number, s.now())}
+			_, err = s.client.CoreV1().ConfigMaps(s.namespace).Create(ctx, cm, metav1.CreateOptions{})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		for key, value := range cm.Data {
+			if _, savedAt, err := parseClientTokenEntry(value); err != nil || s.now().Sub(savedAt) >= clientTokenRetention {
+				delete(cm.Data, key)
+			}
+		}
+		cm.Data[tokenBase] = formatClientTokenEntry(number, s.now())
+		_, err = s.client.CoreV1().ConfigMaps(s.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func formatClientTokenEntry(number int, savedAt time.Time) string {
+	return strconv.Itoa(number) + "," + savedAt.UTC().Format(time.RFC3339)
+}
+
+func parseClientTokenEntry(value string) (int, time.Time, error) {
+	numberStr, savedAtStr, ok := strings.Cut(value, ",")
+	if !ok {
+		return 0, time.Time{}, errors.New("missing save time")
+	}
+	number, err := strconv.Atoi(numberStr)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	savedAt, err := time.Parse(time.RFC3339, savedAtStr)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return number, savedAt, nil
+}
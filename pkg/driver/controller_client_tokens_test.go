@@ -0,0 +1,92 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestClientTokenConfigMap(t *testing.T) {
+	assert.Nil(t, newClientTokenConfigMap(fake.NewClientset(), &Options{}))
+	assert.Nil(t, newClientTokenConfigMap(nil, &Options{ClientTokenConfigMap: "ebs-csi-client-tokens"}))
+
+	client := fake.NewClientset()
+	s := newClientTokenConfigMap(client, &Options{ClientTokenConfigMap: "ebs-csi-client-tokens", ClientTokenConfigMapNamespace: "kube-system"})
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	numbers, err := s.Load(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, numbers)
+
+	require.NoError(t, s.Save(t.Context(), "pvc-1", 2))
+	now = now.Add(time.Hour)
+	require.NoError(t, s.Save(t.Context(), "pvc-2", 3))
+	require.NoError(t, s.Save(t.Context(), "pvc-1", 4))
+	require.Error(t, s.Save(t.Context(), "pvc/invalid", 2))
+
+	numbers, err = s.Load(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"pvc-1": 4, "pvc-2": 3}, numbers)
+
+	// Entries expire after clientTokenRetention, and are removed by the next save
+	now = now.Add(clientTokenRetention)
+	numbers, err = s.Load(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, numbers)
+	require.NoError(t, s.Save(t.Context(), "pvc-3", 2))
+	cm, err := client.CoreV1().ConfigMaps("kube-system").Get(t.Context(), "ebs-csi-client-tokens", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"pvc-3": "2,2025-01-02T01:00:00Z"}, cm.Data)
+}
+
+func TestClientTokenConfigMapSaveCreatedConcurrently(t *testing.T) {
+	client := fake.NewClientset()
+	s := newClientTokenConfigMap(client, &Options{ClientTokenConfigMap: "ebs-csi-client-tokens", ClientTokenConfigMapNamespace: "kube-system"})
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	// Another replica creates the ConfigMap between the Get and the Create of this replica
+	var created atomic.Bool
+	client.PrependReactor("create", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if created.Swap(true) {
+			return false, nil, nil
+		}
+		other := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "ebs-csi-client-tokens"},
+			Data:       map[string]string{"pvc-other": "5,2025-01-01T00:00:00Z"},
+		}
+		require.NoError(t, client.Tracker().Add(other))
+		return true, nil, apierrors.NewAlreadyExists(corev1.Resource("configmaps"), "ebs-csi-client-tokens")
+	})
+
+	require.NoError(t, s.Save(t.Context(), "pvc-1", 2))
+	cm, err := client.CoreV1().ConfigMaps("kube-system").Get(t.Context(), "ebs-csi-client-tokens", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"pvc-other": "5,2025-01-01T00:00:00Z", "pvc-1": "2,2025-01-01T00:00:00Z"}, cm.Data)
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// cloneSnapshotNamePrefix is prepended to the volume name to build the name of the
+	// intermediate snapshot used when cloning via snapshot.
+	cloneSnapshotNamePrefix = "clone-"
+)
+
+// cloneSnapshotPollInterval is how often the intermediate snapshot is checked for readiness, unless the retry policy
+// sets snapshotPollInterval. It is a variable so that unit tests can shorten it.
+var cloneSnapshotPollInterval = 5 * time.Second
+
+// The sweeper deletes the intermediate clone snapshots that are older than cloneSnapshotTTL every
+// cloneSnapshotSweepInterval. They are variables so that unit tests can shorten them.
+var (
+	cloneSnapshotSweepInterval = time.Hour
+	cloneSnapshotTTL           = 24 * time.Hour
+)
+
+// errSnapshotFailed is returned when a snapshot the driver waits for ends in the error state, which it never leaves.
+var errSnapshotFailed = errors.New("snapshot failed")
+
+func (d *ControllerService) snapshotPollInterval() time.Duration {
+	if d.options.RetryPolicy != nil && d.options.RetryPolicy.SnapshotPollInterval.Duration != 0 {
+		return d.options.RetryPolicy.SnapshotPollInterval.Duration
+	}
+	return cloneSnapshotPollInterval
+}
+
+// createDiskViaSnapshot clones a volume by taking a temporary snapshot of the source volume,
+// restoring a new volume from it, and then deleting the snapshot.
+//
+// The snapshot is named after the target volume so that retried CreateVolume calls re-use the
+// same intermediate snapshot instead of creating a new one on every attempt. It is kept when the
+// restore fails with a transient error, and deleted when the restore is rejected or the snapshot
+// fails, so that the next attempt starts over.
+func (d *ControllerService) createDiskViaSnapshot(ctx context.Context, volName string, opts *cloud.DiskOptions) (*cloud.Disk, error) {
+	sourceVolumeID := opts.SourceVolumeID
+	snapshotName := cloneSnapshotNamePrefix + volName
+
+	snapshot, err := d.cloud.GetSnapshotByName(ctx, snapshotName)
+	if err != nil && !errors.Is(err, cloud.ErrNotFound) {
+		return nil, fmt.Errorf("could not look up intermediate clone snapshot %q: %w", snapshotName, err)
+	}
+	if snapshot == nil {
+		tags := map[string]string{
+			cloud.SnapshotNameTagKey: snapshotName,
+			cloud.AwsEbsDriverTagKey: isManagedByDriver,
+		}
+		if d.options.KubernetesClusterID != "" {
+			tags[ResourceLifecycleTagPrefix+d.options.KubernetesClusterID] = ResourceLifecycleOwned
+			tags[ClusterNameTagKey] = d.options.KubernetesClusterID
+		}
+		klog.V(4).InfoS("createDiskViaSnapshot: creating intermediate snapshot", "sourceVolumeID", sourceVolumeID, "snapshotName", snapshotName)
+		snapshot, err = d.cloud.CreateSnapshot(ctx, sourceVolumeID, &cloud.SnapshotOptions{
+			Tags:       tags,
+			OutpostArn: opts.OutpostArn,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not create intermediate clone snapshot of volume %q: %w", sourceVolumeID, err)
+		}
+	} else if snapshot.SourceVolumeID != sourceVolumeID {
+		return nil, fmt.Errorf("%w: intermediate clone snapshot %q belongs to volume %q", cloud.ErrAlreadyExists, snapshotName, snapshot.SourceVolumeID)
+	}
+
+	snapshotID := snapshot.SnapshotID
+	if !snapshot.ReadyToUse {
+		err = wait.PollUntilContextCancel(ctx, d.snapshotPollInterval(), false, func(ctx context.Context) (bool, error) {
+			s, err := d.cloud.GetSnapshotByID(ctx, snapshotID)
+			if err != nil {
+				return false, err
+			}
+			if s.Failed {
+				return false, errSnapshotFailed
+			}
+			return s.ReadyToUse, nil
+		})
+		if err != nil {
+			if errors.Is(err, errSnapshotFailed) {
+				d.deleteCloneSnapshot(ctx, snapshotID, volName)
+			}
+			return nil, fmt.Errorf("intermediate clone snapshot %q did not become ready: %w", snapshotID, err)
+		}
+	}
+
+	restoreOpts := *opts
+	restoreOpts.SourceVolumeID = ""
+	restoreOpts.SnapshotID = snapshotID
+
+	disk, err := d.cloud.CreateDisk(ctx, volName, &restoreOpts)
+	if err != nil {
+		// A retried CreateVolume re-uses the snapshot, unless the restore cannot succeed with the same parameters.
+		if isTerminalRestoreError(err) {
+			d.deleteCloneSnapshot(ctx, snapshotID, volName)
+		}
+		return nil, err
+	}
+
+	d.deleteCloneSnapshot(ctx, snapshotID, volName)
+
+	disk.SnapshotID = ""
+	disk.SourceVolumeID = sourceVolumeID
+	return disk, nil
+}
+
+// deleteCloneSnapshot deletes the intermediate snapshot of a clone. Failures are only logged: a leaked snapshot is
+// tagged and can be found and removed later.
+func (d *ControllerService) deleteCloneSnapshot(ctx context.Context, snapshotID, volName string) {
+	if _, err := d.cloud.DeleteSnapshot(ctx, snapshotID); err != nil && !errors.Is(err, cloud.ErrNotFound) {
+		klog.ErrorS(err, "createDiskViaSnapshot: failed to delete intermediate snapshot", "snapshotID", snapshotID, "volumeName", volName)
+	}
+}
+
+// isTerminalRestoreError returns whether restoring a clone from its intermediate snapshot failed in a way that
+// retrying with the same parameters cannot fix.
+func isTerminalRestoreError(err error) bool {
+	return errors.Is(err, cloud.ErrInvalidArgument) ||
+		errors.Is(err, cloud.ErrIdempotentParameterMismatch) ||
+		errors.Is(err, cloud.ErrAlreadyExists) ||
+		errors.Is(err, cloud.ErrSourceNotFound)
+}
+
+// startCloneSnapshotSweeper periodically deletes the intermediate clone snapshots that were abandoned, once this
+// replica is elected leader. A snapshot is abandoned when its PVC is deleted while the clone keeps failing, since
+// the CO then stops calling CreateVolume for it.
+func (d *ControllerService) startCloneSnapshotSweeper(k kubernetes.Interface) {
+	if k == nil {
+		klog.InfoS("No Kubernetes client available, deleting abandoned clone snapshots without leader election")
+		go d.runCloneSnapshotSweeper(context.Background())
+		return
+	}
+	go runLeaderElection(context.Background(), k, "clone-snapshot-sweeper-"+util.GetDriverName(), d.runCloneSnapshotSweeper)
+}
+
+// runCloneSnapshotSweeper sweeps abandoned clone snapshots every cloneSnapshotSweepInterval until ctx is done.
+func (d *ControllerService) runCloneSnapshotSweeper(ctx context.Context) {
+	ticker := time.NewTicker(cloneSnapshotSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.sweepCloneSnapshots(ctx)
+		}
+	}
+}
+
+// sweepCloneSnapshots deletes the intermediate clone snapshots created by the driver, and by this cluster when
+// --k8s-tag-cluster-id is set, that are older than cloneSnapshotTTL. A clone still being retried after that long
+// takes a fresh snapshot on its next attempt, and snapshots whose clone is in progress on this replica are kept.
+func (d *ControllerService) sweepCloneSnapshots(ctx context.Context) {
+	tags := map[string]string{
+		cloud.SnapshotNameTagKey: cloneSnapshotNamePrefix + "*",
+		cloud.AwsEbsDriverTagKey: isManagedByDriver,
+	}
+	maps.Copy(tags, softDeleteScopeTags(d.options.KubernetesClusterID))
+	snapshots, err := d.cloud.ListSnapshotsByTags(ctx, tags)
+	if err != nil {
+		klog.ErrorS(err, "sweepCloneSnapshots: could not list clone snapshots")
+		return
+	}
+
+	for _, snapshot := range snapshots {
+		volName, ok := strings.CutPrefix(snapshot.Name, cloneSnapshotNamePrefix)
+		if !ok || time.Since(snapshot.CreationTime) < cloneSnapshotTTL {
+			continue
+		}
+		if !d.inFlight.Insert(volName) {
+			continue
+		}
+		if _, err := d.cloud.DeleteSnapshot(ctx, snapshot.SnapshotID); err != nil && !errors.Is(err, cloud.ErrNotFound) {
+			klog.ErrorS(err, "sweepCloneSnapshots: could not delete abandoned clone snapshot", "snapshotID", snapshot.SnapshotID, "volumeName", volName)
+		} else {
+			klog.InfoS("sweepCloneSnapshots: deleted abandoned clone snapshot", "snapshotID", snapshot.SnapshotID, "volumeName", volName, "creationTime", snapshot.CreationTime)
+		}
+		d.inFlight.Delete(volName)
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver/internal"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateVolumeCloneViaSnapshot(t *testing.T) {
+	pollInterval := cloneSnapshotPollInterval
+	t.Cleanup(func() { cloneSnapshotPollInterval = pollInterval })
+	cloneSnapshotPollInterval = time.Millisecond
+
+	const (
+		volName    = "random-vol-name"
+		snapshotID = "snap-clone"
+	)
+	stdVolSize := int64(5 * util.GiB)
+	sourceDisk := &cloud.Disk{
+		VolumeID:         testSourceVolID,
+		AvailabilityZone: expZone,
+	}
+
+	newRequest := func() *csi.CreateVolumeRequest {
+		return &csi.CreateVolumeRequest{
+			Name:          volName,
+			CapacityRange: &csi.CapacityRange{RequiredBytes: stdVolSize},
+			VolumeCapabilities: []*csi.VolumeCapability{
+				{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+				},
+			},
+			VolumeContentSource: &csi.VolumeContentSource{
+				Type: &csi.VolumeContentSource_Volume{
+					Volume: &csi.VolumeContentSource_VolumeSource{VolumeId: testSourceVolID},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		mockFunc     func(*cloud.MockCloud)
+		expectedCode codes.Code
+	}{
+		{
+			name: "success: snapshot created, restored, and deleted",
+			mockFunc: func(mc *cloud.MockCloud) {
+				mc.EXPECT().GetDiskByID(gomock.Any(), testSourceVolID).Return(sourceDisk, nil)
+				mc.EXPECT().GetSnapshotByName(gomock.Any(), cloneSnapshotNamePrefix+volName).Return(nil, cloud.ErrNotFound)
+				mc.EXPECT().CreateSnapshot(gomock.Any(), testSourceVolID, gomock.Any()).Return(&cloud.Snapshot{SnapshotID: snapshotID, SourceVolumeID: testSourceVolID}, nil)
+				mc.EXPECT().GetSnapshotByID(gomock.Any(), snapshotID).Return(&cloud.Snapshot{SnapshotID: snapshotID, ReadyToUse: true}, nil)
+				mc.EXPECT().CreateDisk(gomock.Any(), volName, gomock.Any()).DoAndReturn(func(_ any, _ string, opts *cloud.DiskOptions) (*cloud.Disk, error) {
+					assert.Equal(t, snapshotID, opts.SnapshotID)
+					assert.Empty(t, opts.SourceVolumeID)
+					return &cloud.Disk{VolumeID: "vol-clone", CapacityGiB: 5, AvailabilityZone: expZone, SnapshotID: snapshotID}, nil
+				})
+				mc.EXPECT().DeleteSnapshot(gomock.Any(), snapshotID).Return(true, nil)
+			},
+		},
+		{
+			name: "success: existing ready snapshot is reused",
+			mockFunc: func(mc *cloud.MockCloud) {
+				mc.EXPECT().GetDiskByID(gomock.Any(), testSourceVolID).Return(sourceDisk, nil)
+				mc.EXPECT().GetSnapshotByName(gomock.Any(), cloneSnapshotNamePrefix+volName).Return(&cloud.Snapshot{SnapshotID: snapshotID, SourceVolumeID: testSourceVolID, ReadyToUse: true}, nil)
+				mc.EXPECT().CreateDisk(gomock.Any(), volName, gomock.Any()).Return(&cloud.Disk{VolumeID: "vol-clone", CapacityGiB: 5, AvailabilityZone: expZone}, nil)
+				mc.EXPECT().DeleteSnapshot(gomock.Any(), snapshotID).Return(false, errors.New("delete failed"))
+			},
+		},
+		{
+			name: "fail: existing snapshot belongs to another volume",
+			mockFunc: func(mc *cloud.MockCloud) {
+				mc.EXPECT().GetDiskByID(gomock.Any(), testSourceVolID).Return(sourceDisk, nil)
+				mc.EXPECT().GetSnapshotByName(gomock.Any(), cloneSnapshotNamePrefix+volName).Return(&cloud.Snapshot{SnapshotID: snapshotID, SourceVolumeID: "vol-other"}, nil)
+			},
+			expectedCode: codes.AlreadyExists,
+		},
+		{
+			name: "fail: snapshot creation error",
+			mockFunc: func(mc *cloud.MockCloud) {
+				mc.EXPECT().GetDiskByID(gomock.Any(), testSourceVolID).Return(sourceDisk, nil)
+				mc.EXPECT().GetSnapshotByName(gomock.Any(), cloneSnapshotNamePrefix+volName).Return(nil, cloud.ErrNotFound)
+				mc.EXPECT().CreateSnapshot(gomock.Any(), testSourceVolID, gomock.Any()).Return(nil, errors.New("boom"))
+			},
+			expectedCode: codes.Aborted,
+		},
+		{
+			name: "fail: snapshot failed, snapshot deleted",
+			mockFunc: func(mc *cloud.MockCloud) {
+				mc.EXPECT().GetDiskByID(gomock.Any(), testSourceVolID).Return(sourceDisk, nil)
+				mc.EXPECT().GetSnapshotByName(gomock.Any(), cloneSnapshotNamePrefix+volName).Return(&cloud.Snapshot{SnapshotID: snapshotID, SourceVolumeID: testSourceVolID}, nil)
+				mc.EXPECT().GetSnapshotByID(gomock.Any(), snapshotID).Return(&cloud.Snapshot{SnapshotID: snapshotID, Failed: true}, nil)
+				mc.EXPECT().CreateDisk(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				mc.EXPECT().DeleteSnapshot(gomock.Any(), snapshotID).Return(true, nil)
+			},
+			expectedCode: codes.Aborted,
+		},
+		{
+			name: "fail: restore rejected, snapshot deleted",
+			mockFunc: func(mc *cloud.MockCloud) {
+				mc.EXPECT().GetDiskByID(gomock.Any(), testSourceVolID).Return(sourceDisk, nil)
+				mc.EXPECT().GetSnapshotByName(gomock.Any(), cloneSnapshotNamePrefix+volName).Return(&cloud.Snapshot{SnapshotID: snapshotID, SourceVolumeID: testSourceVolID, ReadyToUse: true}, nil)
+				mc.EXPECT().CreateDisk(gomock.Any(), volName, gomock.Any()).Return(nil, fmt.Errorf("%w: bad parameters", cloud.ErrInvalidArgument))
+				mc.EXPECT().DeleteSnapshot(gomock.Any(), snapshotID).Return(true, nil)
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "fail: restore error, snapshot kept for retry",
+			mockFunc: func(mc *cloud.MockCloud) {
+				mc.EXPECT().GetDiskByID(gomock.Any(), testSourceVolID).Return(sourceDisk, nil)
+				mc.EXPECT().GetSnapshotByName(gomock.Any(), cloneSnapshotNamePrefix+volName).Return(&cloud.Snapshot{SnapshotID: snapshotID, SourceVolumeID: testSourceVolID, ReadyToUse: true}, nil)
+				mc.EXPECT().CreateDisk(gomock.Any(), volName, gomock.Any()).Return(nil, errors.New("timed out waiting for volume"))
+			},
+			expectedCode: codes.Aborted,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			tc.mockFunc(mockCloud)
+
+			awsDriver := ControllerService{
+				cloud:    mockCloud,
+				inFlight: internal.NewInFlight(),
+				options:  &Options{CloneViaSnapshot: true},
+			}
+
+			resp, err := awsDriver.CreateVolume(t.Context(), newRequest())
+			if tc.expectedCode != codes.OK {
+				require.Error(t, err)
+				assert.Equal(t, tc.expectedCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testSourceVolID, resp.GetVolume().GetContentSource().GetVolume().GetVolumeId())
+			assert.Nil(t, resp.GetVolume().GetContentSource().GetSnapshot())
+		})
+	}
+}
+
+func TestSweepCloneSnapshots(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	tags := map[string]string{
+		cloud.SnapshotNameTagKey:               cloneSnapshotNamePrefix + "*",
+		cloud.AwsEbsDriverTagKey:               isManagedByDriver,
+		ResourceLifecycleTagPrefix + "cluster": ResourceLifecycleOwned,
+	}
+	abandoned := time.Now().Add(-cloneSnapshotTTL - time.Hour)
+	mockCloud.EXPECT().ListSnapshotsByTags(gomock.Any(), tags).Return([]*cloud.Snapshot{
+		{SnapshotID: "snap-abandoned", Name: cloneSnapshotNamePrefix + "pvc-abandoned", CreationTime: abandoned},
+		{SnapshotID: "snap-gone", Name: cloneSnapshotNamePrefix + "pvc-gone", CreationTime: abandoned},
+		{SnapshotID: "snap-recent", Name: cloneSnapshotNamePrefix + "pvc-recent", CreationTime: time.Now()},
+		{SnapshotID: "snap-in-flight", Name: cloneSnapshotNamePrefix + "pvc-in-flight", CreationTime: abandoned},
+	}, nil)
+	mockCloud.EXPECT().DeleteSnapshot(gomock.Any(), "snap-abandoned").Return(true, nil)
+	mockCloud.EXPECT().DeleteSnapshot(gomock.Any(), "snap-gone").Return(false, cloud.ErrNotFound)
+
+	d := &ControllerService{
+		cloud:    mockCloud,
+		inFlight: internal.NewInFlight(),
+		options:  &Options{CloneViaSnapshot: true, KubernetesClusterID: "cluster"},
+	}
+	// CreateVolume is still cloning this volume, so its snapshot must be kept
+	d.inFlight.Insert("pvc-in-flight")
+
+	d.sweepCloneSnapshots(t.Context())
+	assert.False(t, d.inFlight.Insert("pvc-in-flight"))
+	assert.True(t, d.inFlight.Insert("pvc-abandoned"), "the sweeper must release the volume name")
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// VolumePrices are the monthly prices in US dollars of the volumes of a type.
+type VolumePrices struct {
+	// GiB is the price of a GiB of provisioned storage.
+	GiB float64 `json:"gib,omitempty"`
+	// IOPS is the price of a provisioned IOPS above IncludedIOPS.
+	IOPS         float64 `json:"iops,omitempty"`
+	IncludedIOPS int32   `json:"includedIOPS,omitempty"`
+	// Throughput is the price of a provisioned MiB/s of throughput above IncludedThroughput.
+	Throughput         float64 `json:"throughput,omitempty"`
+	IncludedThroughput int32   `json:"includedThroughput,omitempty"`
+}
+
+// PriceTable are the prices the controller estimates the cost of the volumes and snapshots it creates with.
+type PriceTable struct {
+	// VolumeTypes are the prices of volumes, by volume type.
+	VolumeTypes map[string]VolumePrices `json:"volumeTypes,omitempty"`
+	// SnapshotGiB is the monthly price in US dollars of a GiB of snapshot storage.
+	SnapshotGiB *float64 `json:"snapshotGiB,omitempty"`
+}
+
+// defaultPrices are the us-east-1 list prices of EBS, used for the volume types and snapshots a price table file
+// does not price.
+var defaultPrices = PriceTable{
+	VolumeTypes: map[string]VolumePrices{
+		cloud.VolumeTypeGP3:      {GiB: 0.08, IOPS: 0.005, IncludedIOPS: gp3BaselineIOPS, Throughput: 0.04, IncludedThroughput: gp3MinThroughput},
+		cloud.VolumeTypeGP2:      {GiB: 0.10},
+		cloud.VolumeTypeIO1:      {GiB: 0.125, IOPS: 0.065},
+		cloud.VolumeTypeIO2:      {GiB: 0.125, IOPS: 0.065},
+		cloud.VolumeTypeST1:      {GiB: 0.045},
+		cloud.VolumeTypeSC1:      {GiB: 0.015},
+		cloud.VolumeTypeStandard: {GiB: 0.05},
+	},
+	SnapshotGiB: aws.Float64(0.05),
+}
+
+// Validate checks that the prices are for known volume types and not negative.
+func (t *PriceTable) Validate() error {
+	for volumeType, prices := range t.VolumeTypes {
+		if !slices.Contains(cloud.ValidVolumeTypes, volumeType) {
+			return fmt.Errorf("invalid volume type %q, must be one of %v", volumeType, cloud.ValidVolumeTypes)
+		}
+		if prices.GiB < 0 || prices.IOPS < 0 || prices.Throughput < 0 || prices.IncludedIOPS < 0 || prices.IncludedThroughput < 0 {
+			return fmt.Errorf("invalid prices of volume type %q, must not be negative", volumeType)
+		}
+	}
+	if t.SnapshotGiB != nil && *t.SnapshotGiB < 0 {
+		return fmt.Errorf("invalid snapshotGiB %v, must not be negative", *t.SnapshotGiB)
+	}
+	return nil
+}
+
+// priceTableFile is a flag.Value that loads a PriceTable from a YAML or JSON file when the flag is set.
+type priceTableFile struct {
+	path  string
+	table **PriceTable
+}
+
+func (f *priceTableFile) String() string { return f.path }
+
+func (f *priceTableFile) Type() string { return "string" }
+
+func (f *priceTableFile) Set(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read price table file: %w", err)
+	}
+	table := &PriceTable{}
+	if err := yaml.UnmarshalStrict(data, table); err != nil {
+		return fmt.Errorf("could not parse price table file %s: %w", path, err)
+	}
+	if err := table.Validate(); err != nil {
+		return fmt.Errorf("invalid price table file %s: %w", path, err)
+	}
+	f.path = path
+	*f.table = table
+	return nil
+}
+
+// costEstimator records the approximate monthly cost of the volumes and snapshots the controller creates, so that
+// their cost can be attributed to namespaces and StorageClasses when they are provisioned.
+type costEstimator struct {
+	prices PriceTable
+}
+
+// newCostEstimator returns nil unless --estimate-costs is set.
+func newCostEstimator(o *Options) *costEstimator {
+	if !o.EstimateCosts {
+		return nil
+	}
+	prices := PriceTable{VolumeTypes: maps.Clone(defaultPrices.VolumeTypes), SnapshotGiB: defaultPrices.SnapshotGiB}
+	if o.CostPriceTable != nil {
+		maps.Copy(prices.VolumeTypes, o.CostPriceTable.VolumeTypes)
+		if o.CostPriceTable.SnapshotGiB != nil {
+			prices.SnapshotGiB = o.CostPriceTable.SnapshotGiB
+		}
+	}
+	return &costEstimator{prices: prices}
+}
+
+// volumeCost returns the monthly cost of a volume created by CreateDisk, or false if its type has no price.
+func (e *costEstimator) volumeCost(disk *cloud.Disk) (float64, bool) {
+	prices, ok := e.prices.VolumeTypes[disk.VolumeType]
+	if !ok {
+		return 0, false
+	}
+	cost := float64(disk.CapacityGiB) * prices.GiB
+	cost += float64(max(disk.IOPS-prices.IncludedIOPS, 0)) * prices.IOPS
+	cost += float64(max(disk.Throughput-prices.IncludedThroughput, 0)) * prices.Throughput
+	return cost, true
+}
+
+// snapshotCost returns the monthly cost of a snapshot as if it stored its whole source volume. The first snapshot of
+// a volume stores only its written blocks and the following ones only the blocks changed since, so this is an upper
+// bound.
+func (e *costEstimator) snapshotCost(snapshot *cloud.Snapshot) float64 {
+	return float64(snapshot.Size) * *e.prices.SnapshotGiB
+}
+
+// recordVolumeCost records the estimated monthly cost of a volume created by CreateVolume.
+func (d *ControllerService) recordVolumeCost(ctx context.Context, props *template.PVProps, disk *cloud.Disk) {
+	if d.costs == nil {
+		return
+	}
+	cost, ok := d.costs.volumeCost(disk)
+	if !ok {
+		klog.V(4).InfoS("CreateVolume: no price for volume type, not estimating its cost", "volumeID", disk.VolumeID, "volumeType", disk.VolumeType)
+		return
+	}
+	storageClass := d.pvcMetadata.storageClass(ctx, props.PVCNamespace, props.PVCName)
+	klog.V(4).InfoS("CreateVolume: estimated monthly cost", "volumeID", disk.VolumeID, "namespace", props.PVCNamespace, "storageClass", storageClass, "cost", cost)
+	recordEstimatedCost("CreateVolume", props.PVCNamespace, storageClass, disk.VolumeType, cost)
+}
+
+// recordSnapshotCost records the estimated monthly cost of a snapshot created by CreateSnapshot.
+func (d *ControllerService) recordSnapshotCost(props *template.VolumeSnapshotProps, snapshot *cloud.Snapshot) {
+	if d.costs == nil {
+		return
+	}
+	cost := d.costs.snapshotCost(snapshot)
+	klog.V(4).InfoS("CreateSnapshot: estimated monthly cost", "snapshotID", snapshot.SnapshotID, "namespace", props.VolumeSnapshotNamespace, "cost", cost)
+	recordEstimatedCost("CreateSnapshot", props.VolumeSnapshotNamespace, "", "", cost)
+}
+
+func recordEstimatedCost(operation, namespace, storageClass, volumeType string, cost float64) {
+	metrics.Recorder().AddCount(metrics.EstimatedMonthlyCost, metrics.EstimatedMonthlyCostHelpText, cost, map[string]string{
+		"operation":     operation,
+		"namespace":     namespace,
+		"storage_class": storageClass,
+		"volume_type":   volumeType,
+	})
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCostEstimator(t *testing.T) {
+	assert.Nil(t, newCostEstimator(&Options{}))
+
+	e := newCostEstimator(&Options{EstimateCosts: true})
+	testCases := []struct {
+		name string
+		disk *cloud.Disk
+		cost float64
+	}{
+		{name: "gp3 baseline", disk: &cloud.Disk{VolumeType: cloud.VolumeTypeGP3, CapacityGiB: 100}, cost: 8},
+		{name: "gp3 above baseline", disk: &cloud.Disk{VolumeType: cloud.VolumeTypeGP3, CapacityGiB: 100, IOPS: 4000, Throughput: 250}, cost: 8 + 5 + 5},
+		{name: "io2", disk: &cloud.Disk{VolumeType: cloud.VolumeTypeIO2, CapacityGiB: 100, IOPS: 1000}, cost: 12.5 + 65},
+		{name: "sc1", disk: &cloud.Disk{VolumeType: cloud.VolumeTypeSC1, CapacityGiB: 1000}, cost: 15},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cost, ok := e.volumeCost(tc.disk)
+			assert.True(t, ok)
+			assert.InDelta(t, tc.cost, cost, 1e-9)
+		})
+	}
+	_, ok := e.volumeCost(&cloud.Disk{VolumeType: "unknown", CapacityGiB: 100})
+	assert.False(t, ok)
+	assert.InDelta(t, 5, e.snapshotCost(&cloud.Snapshot{Size: 100}), 1e-9)
+
+	snapshotGiB := 0.1
+	e = newCostEstimator(&Options{EstimateCosts: true, CostPriceTable: &PriceTable{
+		VolumeTypes: map[string]VolumePrices{cloud.VolumeTypeGP3: {GiB: 0.1}},
+		SnapshotGiB: &snapshotGiB,
+	}})
+	cost, _ := e.volumeCost(&cloud.Disk{VolumeType: cloud.VolumeTypeGP3, CapacityGiB: 100, IOPS: 4000})
+	assert.InDelta(t, 10, cost, 1e-9, "the file prices replace the built-in prices of gp3")
+	cost, _ = e.volumeCost(&cloud.Disk{VolumeType: cloud.VolumeTypeGP2, CapacityGiB: 100})
+	assert.InDelta(t, 10, cost, 1e-9, "gp2 keeps its built-in price")
+	assert.InDelta(t, 10, e.snapshotCost(&cloud.Snapshot{Size: 100}), 1e-9)
+	assert.InDelta(t, 0.05, *defaultPrices.SnapshotGiB, 0, "the built-in prices are not modified")
+}
+
+func TestRecordCosts(t *testing.T) {
+	_, registry := metrics.InitializeRecorder(false)
+	pvc := newLabeledPVC()
+	storageClass := "fast"
+	pvc.Spec = corev1.PersistentVolumeClaimSpec{StorageClassName: &storageClass}
+	d := &ControllerService{
+		options:     &Options{},
+		costs:       newCostEstimator(&Options{EstimateCosts: true}),
+		pvcMetadata: newPVCMetadataReader(fake.NewClientset(pvc), &Options{}),
+	}
+	d.recordVolumeCost(t.Context(), &template.PVProps{PVCNamespace: "default", PVCName: "data"}, &cloud.Disk{VolumeType: cloud.VolumeTypeGP3, CapacityGiB: 100})
+	d.recordVolumeCost(t.Context(), &template.PVProps{PVCNamespace: "default", PVCName: "data"}, &cloud.Disk{VolumeType: cloud.VolumeTypeGP3, CapacityGiB: 50})
+	d.recordSnapshotCost(&template.VolumeSnapshotProps{VolumeSnapshotNamespace: "default"}, &cloud.Snapshot{Size: 100})
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	costs := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != metrics.EstimatedMonthlyCost {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			costs[labels["operation"]+"/"+labels["namespace"]+"/"+labels["storage_class"]+"/"+labels["volume_type"]] = metric.GetCounter().GetValue()
+		}
+	}
+	assert.InDeltaMapValues(t, map[string]float64{
+		"CreateVolume/default/fast/gp3": 12,
+		"CreateSnapshot/default//":      5,
+	}, costs, 1e-9)
+}
+
+func TestPriceTableFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+volumeTypes:
+  gp3:
+    gib: 0.0912
+    iops: 0.0057
+    includedIOPS: 3000
+snapshotGiB: 0.055
+`), 0o600))
+
+	var table *PriceTable
+	f := &priceTableFile{table: &table}
+	require.NoError(t, f.Set(path))
+	assert.Equal(t, map[string]VolumePrices{cloud.VolumeTypeGP3: {GiB: 0.0912, IOPS: 0.0057, IncludedIOPS: 3000}}, table.VolumeTypes)
+	assert.InDelta(t, 0.055, *table.SnapshotGiB, 0)
+
+	require.NoError(t, os.WriteFile(path, []byte("volumeTypes:\n  gp4:\n    gib: 0.1\n"), 0o600))
+	require.Error(t, f.Set(path))
+	require.NoError(t, os.WriteFile(path, []byte("volumeTypes:\n  gp3:\n    gib: -0.1\n"), 0o600))
+	require.Error(t, f.Set(path))
+	require.NoError(t, os.WriteFile(path, []byte("volumeTypes:\n  gp3:\n    storage: 0.1\n"), 0o600))
+	require.Error(t, f.Set(path))
+}
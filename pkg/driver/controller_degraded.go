@@ -0,0 +1,194 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// degradedCheckInterval is the period over which throttled EC2 calls are counted.
+	degradedCheckInterval = time.Minute
+	// degradedSustainedChecks is the number of consecutive checks above the threshold, or below it, after which
+	// degraded provisioning is announced, or its end.
+	degradedSustainedChecks = 3
+
+	degradedProvisioningReason  = "DegradedProvisioning"
+	provisioningRecoveredReason = "ProvisioningRecovered"
+
+	// Keys of the status ConfigMap
+	degradedKey           = "degraded"
+	degradedReasonKey     = "reason"
+	degradedMessageKey    = "message"
+	degradedSinceKey      = "since"
+	degradedReplicaKey    = "replica"
+	degradedThrottlesKey  = "throttlesPerMinute"
+	degradedEC2Throttling = "EC2Throttling"
+)
+
+// degradedAnnouncer announces degraded provisioning while EC2 throttles the controller, so that dashboards and
+// on-call engineers can tell slow volume operations caused by AWS rate limits from driver bugs. Once the throttled
+// EC2 calls of the replica stay above the threshold for degradedSustainedChecks minutes, it sets degraded to true in
+// a ConfigMap and emits a Warning event on it, and once they stay below the threshold as long, it sets degraded back
+// to false and emits a Normal event. Each replica announces the throttling it sees, identified by its hostname.
+type degradedAnnouncer struct {
+	cloud     cloud.Cloud
+	client    kubernetes.Interface
+	recorder  record.EventRecorder
+	namespace string
+	name      string
+	identity  string
+	threshold int64
+	now       func() time.Time
+
+	lastThrottles int64
+	// above and below count the consecutive checks above and below the threshold
+	above, below int
+	degraded     bool
+	// since is the time degraded last changed
+	since time.Time
+	// published is false until the ConfigMap reflects degraded
+	published bool
+}
+
+func newDegradedAnnouncer(c cloud.Cloud, k kubernetes.Interface, o *Options) *degradedAnnouncer {
+	if o.DegradedThrottleThreshold <= 0 {
+		return nil
+	}
+	if k == nil {
+		klog.InfoS("No Kubernetes client available, not announcing degraded provisioning")
+		return nil
+	}
+	identity, err := os.Hostname()
+	if err != nil {
+		identity = "unknown"
+	}
+	return &degradedAnnouncer{
+		cloud:     c,
+		client:    k,
+		recorder:  newEventRecorder(k),
+		namespace: o.DegradedStatusNamespace,
+		name:      o.DegradedStatusConfigMap,
+		identity:  identity,
+		threshold: int64(o.DegradedThrottleThreshold),
+		now:       time.Now,
+		published: true,
+	}
+}
+
+// start checks the throttled EC2 calls every degradedCheckInterval in the background.
+func (a *degradedAnnouncer) start() {
+	a.lastThrottles = a.cloud.Throttles()
+	go func() {
+		ticker := time.NewTicker(degradedCheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			a.check(context.Background())
+		}
+	}()
+}
+
+// check counts the EC2 calls throttled since the last check and announces the transitions of degraded provisioning.
+func (a *degradedAnnouncer) check(ctx context.Context) {
+	throttles := a.cloud.Throttles()
+	perMinute := throttles - a.lastThrottles
+	a.lastThrottles = throttles
+
+	if perMinute > a.threshold {
+		a.above, a.below = a.above+1, 0
+	} else {
+		a.above, a.below = 0, a.below+1
+	}
+	switch {
+	case !a.degraded && a.above >= degradedSustainedChecks:
+		a.degraded, a.published, a.since = true, false, a.now()
+		klog.InfoS("EC2 is throttling the controller, announcing degraded provisioning", "throttlesPerMinute", perMinute, "threshold", a.threshold)
+		a.recorder.Eventf(a.reference(), corev1.EventTypeWarning, degradedProvisioningReason, "%s", a.message(perMinute))
+	case a.degraded && a.below >= degradedSustainedChecks:
+		a.degraded, a.published, a.since = false, false, a.now()
+		klog.InfoS("EC2 stopped throttling the controller, announcing recovered provisioning", "throttlesPerMinute", perMinute, "threshold", a.threshold)
+		a.recorder.Eventf(a.reference(), corev1.EventTypeNormal, provisioningRecoveredReason, "%s", a.message(perMinute))
+	}
+	degraded := 0.0
+	if a.degraded {
+		degraded = 1
+	}
+	metrics.Recorder().SetGauge(metrics.DegradedProvisioning, metrics.DegradedProvisioningHelpText, degraded, map[string]string{"reason": degradedEC2Throttling})
+
+	if !a.published {
+		if err := a.publish(ctx, perMinute); err != nil {
+			klog.ErrorS(err, "Could not announce degraded provisioning, retrying on the next check", "namespace", a.namespace, "name", a.name)
+			return
+		}
+		a.published = true
+	}
+}
+
+func (a *degradedAnnouncer) message(perMinute int64) string {
+	if a.degraded {
+		return fmt.Sprintf("EC2 is throttling the controller on %s (%d throttled calls in the last minute, threshold %d), volume operations are slowed down by AWS API rate limits", a.identity, perMinute, a.threshold)
+	}
+	return fmt.Sprintf("EC2 stopped throttling the controller on %s (%d throttled calls in the last minute, threshold %d)", a.identity, perMinute, a.threshold)
+}
+
+func (a *degradedAnnouncer) reference() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: a.namespace, Name: a.name},
+	}
+}
+
+// publish writes the degraded state to the ConfigMap, creating it if needed.
+func (a *degradedAnnouncer) publish(ctx context.Context, perMinute int64) error {
+	data := map[string]string{
+		degradedKey:          strconv.FormatBool(a.degraded),
+		degradedMessageKey:   a.message(perMinute),
+		degradedSinceKey:     a.since.UTC().Format(time.RFC3339),
+		degradedReplicaKey:   a.identity,
+		degradedThrottlesKey: strconv.FormatInt(perMinute, 10),
+	}
+	if a.degraded {
+		data[degradedReasonKey] = degradedEC2Throttling
+	}
+
+	cm, err := a.client.CoreV1().ConfigMaps(a.namespace).Get(ctx, a.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm = a.reference()
+		cm.Data = data
+		_, err = a.client.CoreV1().ConfigMaps(a.namespace).Create(ctx, cm, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	cm.Data = data
+	_, err = a.client.CoreV1().ConfigMaps(a.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
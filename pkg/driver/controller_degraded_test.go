@@ -0,0 +1,103 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestDegradedAnnouncer(t *testing.T) {
+	assert.Nil(t, newDegradedAnnouncer(nil, fake.NewClientset(), &Options{}))
+	assert.Nil(t, newDegradedAnnouncer(nil, nil, &Options{DegradedThrottleThreshold: 10}))
+
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	var throttles int64
+	mockCloud.EXPECT().Throttles().DoAndReturn(func() int64 { return throttles }).AnyTimes()
+	client := fake.NewClientset()
+	recorder := record.NewFakeRecorder(10)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	a := newDegradedAnnouncer(mockCloud, client, &Options{DegradedThrottleThreshold: 10, DegradedStatusConfigMap: "status", DegradedStatusNamespace: "kube-system"})
+	require.NotNil(t, a)
+	a.recorder = recorder
+	a.identity = "controller-0"
+	a.now = func() time.Time { return now }
+
+	check := func(perMinute int64) {
+		throttles += perMinute
+		now = now.Add(degradedCheckInterval)
+		a.check(t.Context())
+	}
+	statusData := func() map[string]string {
+		cm, err := client.CoreV1().ConfigMaps("kube-system").Get(t.Context(), "status", metav1.GetOptions{})
+		if err != nil {
+			return nil
+		}
+		return cm.Data
+	}
+
+	// Throttling that is not sustained is not announced
+	check(50)
+	check(50)
+	check(5)
+	check(50)
+	check(50)
+	assert.Nil(t, statusData())
+	assert.Empty(t, recorder.Events)
+
+	check(50)
+	assert.Equal(t, map[string]string{
+		degradedKey:          "true",
+		degradedReasonKey:    degradedEC2Throttling,
+		degradedMessageKey:   "EC2 is throttling the controller on controller-0 (50 throttled calls in the last minute, threshold 10), volume operations are slowed down by AWS API rate limits",
+		degradedSinceKey:     "2025-01-01T00:06:00Z",
+		degradedReplicaKey:   "controller-0",
+		degradedThrottlesKey: "50",
+	}, statusData())
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Warning DegradedProvisioning EC2 is throttling the controller")
+
+	// Failures to update the ConfigMap are retried on the next check
+	check(0)
+	check(0)
+	client.PrependReactor("update", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+	check(0)
+	assert.Equal(t, "true", statusData()[degradedKey])
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Normal ProvisioningRecovered EC2 stopped throttling the controller")
+	client.ReactionChain = client.ReactionChain[1:]
+	check(0)
+	assert.Equal(t, "false", statusData()[degradedKey])
+	assert.Equal(t, "2025-01-01T00:09:00Z", statusData()[degradedSinceKey])
+	assert.NotContains(t, statusData(), degradedReasonKey)
+	assert.Empty(t, recorder.Events)
+}
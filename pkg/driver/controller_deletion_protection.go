@@ -0,0 +1,87 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ConfirmDeletionAnnotation must be set to "true" on the PV of a volume of a deletion-protected namespace before
+	// DeleteVolume deletes it.
+	ConfirmDeletionAnnotation = "ebs.csi.aws.com/confirm-deletion"
+	// allNamespaces in --deletion-protected-namespaces protects the volumes of every namespace.
+	allNamespaces = "*"
+)
+
+// deletionGuard refuses to delete the volumes whose PV is bound to a claim of a deletion-protected namespace until
+// the PV is annotated with ConfirmDeletionAnnotation, even when their reclaim policy is Delete. Policy engines can
+// intercept the deletion of PVCs and PVs, but not the DeleteVolume calls of the external-provisioner, which keeps
+// the PV until DeleteVolume succeeds.
+type deletionGuard struct {
+	pvs        *pvIndex
+	namespaces map[string]bool
+}
+
+func newDeletionGuard(pvs *pvIndex, o *Options) *deletionGuard {
+	if len(o.DeletionProtectedNamespaces) == 0 {
+		return nil
+	}
+	if pvs == nil {
+		// Refuse every deletion rather than silently lifting the protection
+		klog.InfoS("No Kubernetes client available, volumes of deletion-protected namespaces cannot be deleted")
+	}
+	namespaces := map[string]bool{}
+	for _, ns := range o.DeletionProtectedNamespaces {
+		namespaces[ns] = true
+	}
+	return &deletionGuard{pvs: pvs, namespaces: namespaces}
+}
+
+// check returns FailedPrecondition if the volume may not be deleted yet.
+func (g *deletionGuard) check(ctx context.Context, volumeID string) error {
+	if g == nil {
+		return nil
+	}
+	if g.pvs == nil {
+		return status.Errorf(codes.FailedPrecondition, "Deletion protection is enabled but the controller has no Kubernetes client to read the PV of volume %s", volumeID)
+	}
+	pv, err := g.pvs.get(ctx, volumeID)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "Could not find the PV of volume %s to check its deletion protection: %v", volumeID, err)
+	}
+	if pv == nil {
+		klog.V(4).InfoS("DeleteVolume: no PV found, deletion protection does not apply", "volumeID", volumeID)
+		return nil
+	}
+	if pv.Spec.ClaimRef == nil {
+		return nil
+	}
+	namespace := pv.Spec.ClaimRef.Namespace
+	if !g.namespaces[namespace] && !g.namespaces[allNamespaces] {
+		return nil
+	}
+	if pv.GetAnnotations()[ConfirmDeletionAnnotation] == "true" {
+		klog.InfoS("DeleteVolume: deletion of protected volume confirmed", "volumeID", volumeID, "pv", pv.Name, "namespace", namespace)
+		return nil
+	}
+	return status.Errorf(codes.FailedPrecondition, "Volume %s of PV %s belongs to deletion-protected namespace %s, annotate the PV with %s=true to delete it", volumeID, pv.Name, namespace, ConfirmDeletionAnnotation)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver/internal"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newClaimedPV(name, volumeID, namespace string, annotations map[string]string) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: util.GetDriverName(), VolumeHandle: volumeID},
+			},
+			ClaimRef: &corev1.ObjectReference{Namespace: namespace, Name: "data"},
+		},
+	}
+}
+
+func TestDeleteVolumeDeletionProtection(t *testing.T) {
+	confirmed := map[string]string{ConfirmDeletionAnnotation: "true"}
+
+	testCases := []struct {
+		name       string
+		namespaces []string
+		noClient   bool
+		pvs        []runtime.Object
+		expErrCode codes.Code
+	}{
+		{
+			name: "success: protection disabled",
+			pvs:  []runtime.Object{newClaimedPV("pv-1", "vol-test", "payments", nil)},
+		},
+		{
+			name:       "success: unprotected namespace",
+			namespaces: []string{"payments"},
+			pvs:        []runtime.Object{newClaimedPV("pv-1", "vol-test", "default", nil)},
+		},
+		{
+			name:       "success: no PV",
+			namespaces: []string{"payments"},
+			pvs:        []runtime.Object{newClaimedPV("pv-1", "vol-other", "payments", nil)},
+		},
+		{
+			name:       "success: PV of another driver",
+			namespaces: []string{"payments"},
+			pvs: []runtime.Object{&corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+				Spec: corev1.PersistentVolumeSpec{
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						CSI: &corev1.CSIPersistentVolumeSource{Driver: "other.csi.example.com", VolumeHandle: "vol-test"},
+					},
+					ClaimRef: &corev1.ObjectReference{Namespace: "payments", Name: "data"},
+				},
+			}},
+		},
+		{
+			name:       "success: deletion confirmed",
+			namespaces: []string{"payments"},
+			pvs:        []runtime.Object{newClaimedPV("pv-1", "vol-test", "payments", confirmed)},
+		},
+		{
+			name:       "fail: protected namespace",
+			namespaces: []string{"billing", "payments"},
+			pvs:        []runtime.Object{newClaimedPV("pv-1", "vol-test", "payments", nil)},
+			expErrCode: codes.FailedPrecondition,
+		},
+		{
+			name:       "fail: all namespaces protected",
+			namespaces: []string{allNamespaces},
+			pvs:        []runtime.Object{newClaimedPV("pv-1", "vol-test", "default", map[string]string{ConfirmDeletionAnnotation: "yes"})},
+			expErrCode: codes.FailedPrecondition,
+		},
+		{
+			name:       "fail: no Kubernetes client",
+			namespaces: []string{"payments"},
+			noClient:   true,
+			expErrCode: codes.FailedPrecondition,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			if tc.expErrCode == codes.OK {
+				mockCloud.EXPECT().DeleteDisk(gomock.Any(), "vol-test").Return(true, nil)
+			}
+			o := &Options{DeletionProtectedNamespaces: tc.namespaces}
+			var client kubernetes.Interface
+			if !tc.noClient {
+				client = fake.NewClientset(tc.pvs...)
+			}
+			d := &ControllerService{cloud: mockCloud, inFlight: internal.NewInFlight(), options: o, deletionGuard: newDeletionGuard(newPVIndex(client), o)}
+
+			_, err := d.DeleteVolume(t.Context(), &csi.DeleteVolumeRequest{VolumeId: "vol-test"})
+			assert.Equal(t, tc.expErrCode, status.Code(err))
+		})
+	}
+}
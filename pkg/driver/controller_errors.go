@@ -0,0 +1,161 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"fmt"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// awsErrorDomain is the ErrorInfo domain of AWS errors returned to the CO.
+const awsErrorDomain = "ec2.amazonaws.com"
+
+// cloudErrorCodes maps the sentinel errors wrapped by cloud errors to the gRPC code returned to the CO, in order of
+// precedence. Codes are chosen by how the CSI sidecars react to them: Unavailable and ResourceExhausted are retried,
+// and ResourceExhausted from CreateVolume additionally lets the scheduler pick another topology.
+// Errors not listed here keep the code chosen by the RPC handler.
+var cloudErrorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{cloud.ErrThrottled, codes.Unavailable},
+	{cloud.ErrServiceUnavailable, codes.Unavailable},
+	{cloud.ErrInsufficientCapacity, codes.ResourceExhausted},
+	{cloud.ErrLimitExceeded, codes.ResourceExhausted},
+	{cloud.ErrUnauthorized, codes.PermissionDenied},
+	{cloud.ErrNotFound, codes.NotFound},
+	{cloud.ErrInvalidArgument, codes.InvalidArgument},
+	{cloud.ErrIncorrectState, codes.FailedPrecondition},
+	{cloud.ErrIdempotentParameterMismatch, codes.AlreadyExists},
+}
+
+// awsErrorSuggestedActions maps EC2 error codes to the action suggested to the user in the details of the
+// statuses they cause. Codes not listed here get no suggested action.
+var awsErrorSuggestedActions = map[string]string{
+	"RequestLimitExceeded": "The EC2 API rate limit was exceeded, the request is retried. If this persists, enable --batching or reduce the rate of volume operations.",
+	"Throttling":           "The EC2 API rate limit was exceeded, the request is retried. If this persists, enable --batching or reduce the rate of volume operations.",
+	"InternalError":        "EC2 failed to handle the request, it is retried.",
+	"ServiceUnavailable":   "EC2 failed to handle the request, it is retried.",
+	"Unavailable":          "EC2 failed to handle the request, it is retried.",
+
+	"InsufficientVolumeCapacity":          "EC2 is out of capacity for this volume type in this availability zone. Try another availability zone or volume type.",
+	"VolumeLimitExceeded":                 "The account reached its EBS storage quota. Request a quota increase in Service Quotas.",
+	"SnapshotLimitExceeded":               "The account reached its snapshot quota. Delete unused snapshots or request a quota increase in Service Quotas.",
+	"AttachmentLimitExceeded":             "The instance cannot attach more volumes. Schedule the workload on another node or reduce --volume-attach-limit.",
+	"MaxIOPSLimitExceeded":                "The account reached its provisioned IOPS quota. Request a quota increase in Service Quotas.",
+	"ConcurrentSnapshotLimitExceeded":     "The volume has too many snapshots in progress. Wait for them to complete or reduce the rate of snapshots.",
+	"VolumeModificationSizeLimitExceeded": "The account reached its quota of storage being modified. Wait for modifications to complete or request a quota increase in Service Quotas.",
+	"ResourceLimitExceeded":               "The account reached a resource quota. Request a quota increase in Service Quotas.",
+
+	"AuthFailure":           "The controller's AWS credentials are invalid or expired. Check the credentials or IAM role of the controller.",
+	"UnauthorizedOperation": "The controller's IAM role is not allowed to perform the operation. Add it to the IAM policy of the role, see the CloudTrail entry of the request ID for the denied action.",
+	"OptInRequired":         "The account is not subscribed to EC2 in this region.",
+	"Blocked":               "The account is blocked from using EC2. Contact AWS Support.",
+
+	"InvalidVolume.NotFound":     "The volume does not exist. It may have been deleted outside of Kubernetes.",
+	"InvalidSnapshot.NotFound":   "The snapshot does not exist. It may have been deleted outside of Kubernetes.",
+	"InvalidInstanceID.NotFound": "The instance does not exist. The node may have been terminated.",
+
+	"InvalidZone.NotFound": "The availability zone does not exist in this region. Check the topology of the StorageClass.",
+	"InvalidKMSKey.Id":     "The KMS key does not exist or the controller cannot use it. Check kmsKeyId in the StorageClass and the key policy.",
+
+	"IncorrectModificationState": "The volume is still being modified. Volumes can be modified once every 6 hours.",
+	"VolumeInUse":                "The volume is attached to an instance. Detach it first.",
+	"InvalidVolume.ZoneMismatch": "The volume and instance are in different availability zones.",
+}
+
+// awsErrorToStatus returns a gRPC status error with the given message. The status code is looked up in
+// cloudErrorCodes by the sentinel errors that err wraps (falling back to fallback for other errors), and if err wraps
+// an AWS API error, the status carries ErrorInfo and RequestInfo details with the AWS error code and request ID.
+func awsErrorToStatus(err error, fallback codes.Code, format string, args ...any) error {
+	code := fallback
+	for _, c := range cloudErrorCodes {
+		if errors.Is(err, c.err) {
+			code = c.code
+			break
+		}
+	}
+	return statusWithAWSDetails(code, err, format, args...)
+}
+
+// statusWithAWSDetails returns a gRPC status error with the given code and message. If err wraps an AWS API
+// error, the status carries ErrorInfo and RequestInfo details with the AWS error code, request ID, operation and
+// suggested action, so that the failure can be matched with its CloudTrail entry. Errors caused by an exceeded quota
+// also carry the name of the quota in Service Quotas.
+func statusWithAWSDetails(code codes.Code, err error, format string, args ...any) error {
+	st := status.New(code, fmt.Sprintf(format, args...))
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return st.Err()
+	}
+
+	errorInfo := &errdetails.ErrorInfo{
+		Reason:   apiErr.ErrorCode(),
+		Domain:   awsErrorDomain,
+		Metadata: map[string]string{},
+	}
+	var opErr *smithy.OperationError
+	if errors.As(err, &opErr) {
+		errorInfo.Metadata["operation"] = opErr.Operation()
+	}
+	if action, ok := awsErrorSuggestedActions[apiErr.ErrorCode()]; ok {
+		errorInfo.Metadata["suggestedAction"] = action
+	}
+	var quotaErr *cloud.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		errorInfo.Metadata["quota"] = quotaErr.QuotaName()
+	}
+	details := []protoadapt.MessageV1{errorInfo}
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) && respErr.ServiceRequestID() != "" {
+		errorInfo.Metadata["requestID"] = respErr.ServiceRequestID()
+		details = append(details, &errdetails.RequestInfo{RequestId: respErr.ServiceRequestID()})
+	}
+	if detailed, detailErr := st.WithDetails(details...); detailErr == nil {
+		return detailed.Err()
+	}
+	return st.Err()
+}
+
+// statusDetailsKeysAndValues returns the AWS error details carried by the status of err as structured logging
+// key/value pairs, so that every failed RPC is logged with them.
+func statusDetailsKeysAndValues(err error) []any {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	var kvs []any
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetDomain() == awsErrorDomain {
+			kvs = append(kvs, "awsErrorCode", info.GetReason())
+			for _, key := range []string{"requestID", "operation", "quota", "suggestedAction"} {
+				if value, ok := info.GetMetadata()[key]; ok {
+					kvs = append(kvs, key, value)
+				}
+			}
+		}
+	}
+	return kvs
+}
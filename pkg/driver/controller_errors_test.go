@@ -0,0 +1,222 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newAWSResponseError returns an error like the ones returned by the EC2 client middlewares for an API error.
+func newAWSResponseError(code, requestID string) error {
+	return cloud.ClassifyError(&awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusBadRequest}},
+			Err:      &smithy.GenericAPIError{Code: code, Message: "test message"},
+		},
+		RequestID: requestID,
+	})
+}
+
+// newAWSOperationError returns an error like the ones returned by the EC2 client for operation.
+func newAWSOperationError(operation, code, requestID string) error {
+	return &smithy.OperationError{ServiceID: "EC2", OperationName: operation, Err: newAWSResponseError(code, requestID)}
+}
+
+func TestAWSErrorToStatus(t *testing.T) {
+	testCases := []struct {
+		name              string
+		err               error
+		fallback          codes.Code
+		expectedCode      codes.Code
+		expectedReason    string
+		expectedRequestID string
+	}{
+		{
+			name:         "non-AWS error uses fallback",
+			err:          errors.New("test error"),
+			fallback:     codes.Internal,
+			expectedCode: codes.Internal,
+		},
+		{
+			name:              "throttling is unavailable",
+			err:               fmt.Errorf("%w: wrapped", newAWSResponseError("RequestLimitExceeded", "req-1")),
+			fallback:          codes.Internal,
+			expectedCode:      codes.Unavailable,
+			expectedReason:    "RequestLimitExceeded",
+			expectedRequestID: "req-1",
+		},
+		{
+			name:              "insufficient capacity is resource exhausted",
+			err:               newAWSResponseError("InsufficientVolumeCapacity", "req-2"),
+			fallback:          codes.Aborted,
+			expectedCode:      codes.ResourceExhausted,
+			expectedReason:    "InsufficientVolumeCapacity",
+			expectedRequestID: "req-2",
+		},
+		{
+			name:           "permission error without request ID",
+			err:            fmt.Errorf("%w: %w", cloud.ErrInvalidRequest, cloud.ClassifyError(&smithy.GenericAPIError{Code: "UnauthorizedOperation"})),
+			fallback:       codes.Internal,
+			expectedCode:   codes.PermissionDenied,
+			expectedReason: "UnauthorizedOperation",
+		},
+		{
+			name:         "cloud error without AWS error",
+			err:          fmt.Errorf("could not find volume: %w", cloud.ErrNotFound),
+			fallback:     codes.Internal,
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:           "unclassified AWS error uses fallback",
+			err:            &smithy.GenericAPIError{Code: "InsufficientVolumeCapacity"},
+			fallback:       codes.Internal,
+			expectedCode:   codes.Internal,
+			expectedReason: "InsufficientVolumeCapacity",
+		},
+		{
+			name:              "unknown AWS error uses fallback",
+			err:               newAWSResponseError("SomethingNew", "req-3"),
+			fallback:          codes.Internal,
+			expectedCode:      codes.Internal,
+			expectedReason:    "SomethingNew",
+			expectedRequestID: "req-3",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := awsErrorToStatus(tc.err, tc.fallback, "Could not do thing: %v", tc.err)
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, tc.expectedCode, st.Code())
+			assert.Equal(t, "Could not do thing: "+tc.err.Error(), st.Message())
+
+			var reason, requestID string
+			for _, detail := range st.Details() {
+				switch d := detail.(type) {
+				case *errdetails.ErrorInfo:
+					assert.Equal(t, awsErrorDomain, d.GetDomain())
+					reason = d.GetReason()
+				case *errdetails.RequestInfo:
+					requestID = d.GetRequestId()
+				}
+			}
+			assert.Equal(t, tc.expectedReason, reason)
+			assert.Equal(t, tc.expectedRequestID, requestID)
+		})
+	}
+}
+
+func TestStatusWithAWSDetails(t *testing.T) {
+	testCases := []struct {
+		name           string
+		code           codes.Code
+		err            error
+		expMetadata    map[string]string
+		expRequestInfo bool
+		expLogged      []any
+	}{
+		{
+			name: "non-AWS error has no details",
+			code: codes.NotFound,
+			err:  errors.New("test error"),
+		},
+		{
+			name: "operation error keeps code and carries details",
+			code: codes.ResourceExhausted,
+			err:  fmt.Errorf("%w: %w", cloud.ErrLimitExceeded, newAWSOperationError("AttachVolume", "AttachmentLimitExceeded", "req-1")),
+			expMetadata: map[string]string{
+				"requestID":       "req-1",
+				"operation":       "AttachVolume",
+				"suggestedAction": awsErrorSuggestedActions["AttachmentLimitExceeded"],
+			},
+			expRequestInfo: true,
+			expLogged: []any{
+				"awsErrorCode", "AttachmentLimitExceeded",
+				"requestID", "req-1",
+				"operation", "AttachVolume",
+				"suggestedAction", awsErrorSuggestedActions["AttachmentLimitExceeded"],
+			},
+		},
+		{
+			name: "quota error carries the quota name",
+			code: codes.ResourceExhausted,
+			err:  &cloud.QuotaExceededError{Quota: cloud.QuotaIOPS, VolumeType: cloud.VolumeTypeIO2, Err: newAWSOperationError("CreateVolume", "MaxIOPSLimitExceeded", "req-2")},
+			expMetadata: map[string]string{
+				"requestID":       "req-2",
+				"operation":       "CreateVolume",
+				"quota":           "IOPS for Provisioned IOPS SSD (io2) volumes",
+				"suggestedAction": awsErrorSuggestedActions["MaxIOPSLimitExceeded"],
+			},
+			expRequestInfo: true,
+			expLogged: []any{
+				"awsErrorCode", "MaxIOPSLimitExceeded",
+				"requestID", "req-2",
+				"operation", "CreateVolume",
+				"quota", "IOPS for Provisioned IOPS SSD (io2) volumes",
+				"suggestedAction", awsErrorSuggestedActions["MaxIOPSLimitExceeded"],
+			},
+		},
+		{
+			name:        "unknown AWS error has no suggested action",
+			code:        codes.Internal,
+			err:         &smithy.GenericAPIError{Code: "SomethingNew"},
+			expMetadata: map[string]string{},
+			expLogged:   []any{"awsErrorCode", "SomethingNew"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := statusWithAWSDetails(tc.code, tc.err, "Could not do thing: %v", tc.err)
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, tc.code, st.Code())
+			assert.Equal(t, "Could not do thing: "+tc.err.Error(), st.Message())
+
+			var metadata map[string]string
+			var requestInfo bool
+			for _, detail := range st.Details() {
+				switch d := detail.(type) {
+				case *errdetails.ErrorInfo:
+					metadata = d.GetMetadata()
+					if metadata == nil {
+						metadata = map[string]string{}
+					}
+				case *errdetails.RequestInfo:
+					requestInfo = true
+				}
+			}
+			assert.Equal(t, tc.expMetadata, metadata)
+			assert.Equal(t, tc.expRequestInfo, requestInfo)
+			assert.Equal(t, tc.expLogged, statusDetailsKeysAndValues(err))
+		})
+	}
+}
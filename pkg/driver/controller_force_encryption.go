@@ -0,0 +1,36 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// forceEncryption returns the encryption of a volume created with --force-encryption: the volume is always
+// encrypted, with the key of its StorageClass if it sets kmsKeyId, or else with --force-encryption-kms-key-id.
+// StorageClasses that set encrypted to anything but true are rejected rather than silently overridden, so that
+// their owners learn about the mandate. Clones keep the key of their source volume, which CopyVolumes cannot change.
+func forceEncryption(o *Options, encryptedKey, kmsKeyID string, clone bool) (bool, string, error) {
+	if encryptedKey != "" && !isTrue(encryptedKey) {
+		return false, "", status.Errorf(codes.InvalidArgument, "Cannot create an unencrypted volume: the driver encrypts every volume (--force-encryption), remove the %s parameter from the StorageClass", EncryptedKey)
+	}
+	if kmsKeyID == "" && !clone {
+		kmsKeyID = o.ForceEncryptionKMSKeyID
+	}
+	return true, kmsKeyID, nil
+}
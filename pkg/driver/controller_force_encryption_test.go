@@ -0,0 +1,77 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestForceEncryption(t *testing.T) {
+	o := &Options{ForceEncryption: true, ForceEncryptionKMSKeyID: "alias/default"}
+
+	testCases := []struct {
+		name         string
+		encryptedKey string
+		kmsKeyID     string
+		clone        bool
+		expKMSKeyID  string
+		expErr       bool
+	}{
+		{
+			name:        "encrypted is not set",
+			expKMSKeyID: "alias/default",
+		},
+		{
+			name:         "encrypted is true",
+			encryptedKey: "true",
+			expKMSKeyID:  "alias/default",
+		},
+		{
+			name:         "StorageClass key",
+			encryptedKey: "true",
+			kmsKeyID:     "alias/team",
+			expKMSKeyID:  "alias/team",
+		},
+		{
+			name:  "clone keeps the key of its source",
+			clone: true,
+		},
+		{
+			name:         "encrypted is false",
+			encryptedKey: "false",
+			expErr:       true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encrypted, kmsKeyID, err := forceEncryption(o, tc.encryptedKey, tc.kmsKeyID, tc.clone)
+			if tc.expErr {
+				require.Error(t, err)
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, encrypted)
+			assert.Equal(t, tc.expKMSKeyID, kmsKeyID)
+		})
+	}
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"google.golang.org/grpc"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// handoffDeletePrefix prefixes the name of the handoff Lease annotations of volumes being deleted.
+	handoffDeletePrefix = "delete."
+	// handoffFSRPrefix prefixes the name of the handoff Lease annotations of snapshots whose fast snapshot restores
+	// are being enabled. The value of the annotation is the comma separated list of availability zones.
+	handoffFSRPrefix = "fsr."
+)
+
+const (
+	// handoffFlushInterval is how long operations are batched before they are written to the handoff Lease. Operations
+	// that complete within it are never written.
+	handoffFlushInterval = time.Second
+	// serviceAccountNamespaceFile holds the namespace of the Pod, in which the chart grants access to Leases.
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// handoffStore records the operations in flight on this controller replica in the annotations of a Lease, so that
+// the next leader can resume them as soon as it is elected instead of waiting for the sidecars to retry them.
+// Recording is best effort: a failure to update the Lease is logged and never fails the operation. Operations are
+// written in batches by a background flusher, so that controller RPCs never wait for the Lease.
+type handoffStore struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	// mu guards changes
+	mu sync.Mutex
+	// changes are the annotations to write at the next flush, keyed by annotation name. A nil value removes the
+	// annotation.
+	changes map[string]*string
+	// dirty wakes up the flusher when changes are made
+	dirty chan struct{}
+	// flushMu serializes the flushes, so that the changes of a flush are never overwritten by an earlier one
+	flushMu sync.Mutex
+	// resumeOnce ensures the operations of the previous leader are only resumed once
+	resumeOnce sync.Once
+}
+
+func newHandoffStore(k kubernetes.Interface, o *Options) *handoffStore {
+	if o.HandoffLease == "" {
+		return nil
+	}
+	if k == nil {
+		klog.InfoS("No Kubernetes client available, not recording in-flight operations for handoff", "lease", o.HandoffLease)
+		return nil
+	}
+	namespace := o.HandoffLeaseNamespace
+	if namespace == "" {
+		namespace = podNamespace()
+	}
+	// The handed off operations come from RPCs that are not sharded, so all the shards share the Lease: the
+	// replica elected leader after a failover may be of another shard than the previous leader.
+	return &handoffStore{
+		client:    k,
+		namespace: namespace,
+		name:      o.HandoffLease,
+		changes:   map[string]*string{},
+		dirty:     make(chan struct{}, 1),
+	}
+}
+
+// podNamespace returns the namespace of the Pod the driver runs in, or kube-system when it cannot be determined.
+func podNamespace() string {
+	namespace, err := os.ReadFile(serviceAccountNamespaceFile)
+	if err != nil || len(bytes.TrimSpace(namespace)) == 0 {
+		return "kube-system"
+	}
+	return string(bytes.TrimSpace(namespace))
+}
+
+// handoffKey returns the name of the Lease annotation of an operation.
+func handoffKey(prefix, id string) string {
+	return util.GetDriverName() + "/" + prefix + id
+}
+
+// record adds an operation to the Lease.
+func (s *handoffStore) record(_ context.Context, prefix, id, value string) {
+	if s == nil {
+		return
+	}
+	s.change(handoffKey(prefix, id), &value)
+}
+
+// forget removes an operation from the Lease once it has completed.
+func (s *handoffStore) forget(_ context.Context, prefix, id string) {
+	if s == nil {
+		return
+	}
+	s.change(handoffKey(prefix, id), nil)
+}
+
+func (s *handoffStore) change(key string, value *string) {
+	s.mu.Lock()
+	s.changes[key] = value
+	s.mu.Unlock()
+	s.wake()
+}
+
+// wake signals the flusher that there are changes to write.
+func (s *handoffStore) wake() {
+	select {
+	case s.dirty <- struct{}{}:
+	default:
+	}
+}
+
+// pending returns the IDs and values of the operations with the given prefix recorded in the Lease.
+func (s *handoffStore) pending(ctx context.Context, prefix string) (map[string]string, error) {
+	lease, err := s.client.CoordinationV1().Leases(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	keyPrefix := handoffKey(prefix, "")
+	pending := map[string]string{}
+	for k, v := range lease.GetAnnotations() {
+		if id, ok := strings.CutPrefix(k, keyPrefix); ok {
+			pending[id] = v
+		}
+	}
+	return pending, nil
+}
+
+// start writes the recorded operations to the Lease in the background.
+func (s *handoffStore) start() {
+	go s.run(context.Background())
+}
+
+// run flushes the changes handoffFlushInterval after they are made, until ctx is done.
+func (s *handoffStore) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.dirty:
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(handoffFlushInterval):
+		}
+		s.flush(ctx)
+	}
+}
+
+// flush writes the changes made since the last flush to the Lease. Only the annotations changed by this replica are
+// written, and conflicting updates are retried, so that the operations recorded by other replicas are kept. Changes
+// that could not be written are retried at the next flush.
+func (s *handoffStore) flush(ctx context.Context) {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	changes := s.changes
+	s.changes = map[string]*string{}
+	s.mu.Unlock()
+	if len(changes) == 0 {
+		return
+	}
+
+	leases := s.client.CoordinationV1().Leases(s.namespace)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		lease, err := leases.Get(ctx, s.name, metav1.GetOptions{})
+		notFound := apierrors.IsNotFound(err)
+		if err != nil && !notFound {
+			return err
+		}
+		if notFound {
+			lease = &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Namespace: s.namespace, Name: s.name}}
+		}
+		if lease.Annotations == nil {
+			lease.Annotations = map[string]string{}
+		}
+		changed := false
+		for key, value := range changes {
+			current, ok := lease.Annotations[key]
+			switch {
+			case value == nil && ok:
+				delete(lease.Annotations, key)
+				changed = true
+			case value != nil && (!ok || current != *value):
+				lease.Annotations[key] = *value
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		if notFound {
+			_, err = leases.Create(ctx, lease, metav1.CreateOptions{})
+		} else {
+			_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+		}
+		return err
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to update handoff Lease", "namespace", s.namespace, "name", s.name)
+		// Keep the changes for the next flush, unless they were superseded in the meantime
+		s.mu.Lock()
+		for key, value := range changes {
+			if _, ok := s.changes[key]; !ok {
+				s.changes[key] = value
+			}
+		}
+		s.mu.Unlock()
+		s.wake()
+	}
+}
+
+// handoffInterceptor resumes the operations handed off by the previous leader when this replica receives its first
+// controller RPC, which only happens once its sidecars have been elected leader. ControllerGetCapabilities is ignored
+// because the sidecars call it on startup, before leader election. The sharded RPCs are ignored too, because every
+// shard receives them whether or not its sidecars are the leaders.
+func (d *ControllerService) handoffInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if isControllerOperation(info.FullMethod) && (d.options.ControllerShards <= 1 || !shardedRPCs[path.Base(info.FullMethod)]) {
+		d.handoff.resumeOnce.Do(func() {
+			go d.resumeHandoff(context.Background())
+		})
+	}
+	return handler(ctx, req)
+}
+
+// resumeHandoff completes the volume deletions and fast snapshot restore enablements recorded in the handoff Lease.
+func (d *ControllerService) resumeHandoff(ctx context.Context) {
+	deletes, err := d.handoff.pending(ctx, handoffDeletePrefix)
+	if err != nil {
+		klog.ErrorS(err, "resumeHandoff: could not read pending volume deletions")
+	}
+	// DeleteVolume is not sharded: every deletion is resumed, whichever shard owns the volume
+	for volumeID := range deletes {
+		klog.InfoS("resumeHandoff: resuming volume deletion", "volumeID", volumeID)
+		if _, err := d.DeleteVolume(ctx, &csi.DeleteVolumeRequest{VolumeId: volumeID}); err != nil {
+			klog.ErrorS(err, "resumeHandoff: could not delete volume", "volumeID", volumeID)
+		}
+	}
+
+	fsrs, err := d.handoff.pending(ctx, handoffFSRPrefix)
+	if err != nil {
+		klog.ErrorS(err, "resumeHandoff: could not read pending fast snapshot restores")
+	}
+	for snapshotID, zones := range fsrs {
+		klog.InfoS("resumeHandoff: resuming fast snapshot restore enablement", "snapshotID", snapshotID, "availabilityZones", zones)
+		// Like CreateSnapshot, give up on failure rather than retrying at every failover
+		if _, err := d.cloud.EnableFastSnapshotRestores(ctx, strings.Split(zones, ","), snapshotID); err != nil {
+			klog.ErrorS(err, "resumeHandoff: could not enable fast snapshot restores", "snapshotID", snapshotID)
+		}
+		d.handoff.forget(ctx, handoffFSRPrefix, snapshotID)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandoffStore(t *testing.T) {
+	assert.Nil(t, newHandoffStore(fake.NewClientset(), &Options{}))
+	assert.Nil(t, newHandoffStore(nil, &Options{HandoffLease: "ebs-csi-handoff"}))
+
+	client := fake.NewClientset()
+	s := newHandoffStore(client, &Options{HandoffLease: "ebs-csi-handoff", HandoffLeaseNamespace: "kube-system"})
+	require.NotNil(t, s)
+
+	s.record(t.Context(), handoffDeletePrefix, "vol-1", "")
+	s.record(t.Context(), handoffDeletePrefix, "vol-2", "")
+	s.record(t.Context(), handoffFSRPrefix, "snap-1", "us-east-1a,us-east-1b")
+	s.forget(t.Context(), handoffDeletePrefix, "vol-1")
+	s.flush(t.Context())
+
+	deletes, err := s.pending(t.Context(), handoffDeletePrefix)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"vol-2": ""}, deletes)
+	fsrs, err := s.pending(t.Context(), handoffFSRPrefix)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"snap-1": "us-east-1a,us-east-1b"}, fsrs)
+
+	lease, err := client.CoordinationV1().Leases("kube-system").Get(t.Context(), "ebs-csi-handoff", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, lease.Spec.HolderIdentity)
+
+	// Another replica sharing the Lease only changes its own operations
+	other := newHandoffStore(client, &Options{HandoffLease: "ebs-csi-handoff", HandoffLeaseNamespace: "kube-system"})
+	other.record(t.Context(), handoffDeletePrefix, "vol-3", "")
+	other.flush(t.Context())
+	deletes, err = s.pending(t.Context(), handoffDeletePrefix)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"vol-2": "", "vol-3": ""}, deletes)
+
+	// DeleteVolume and CreateSnapshot are not sharded, so every shard shares the Lease
+	sharded := newHandoffStore(client, &Options{HandoffLease: "ebs-csi-handoff", HandoffLeaseNamespace: "kube-system", ControllerShards: 3, ControllerShardIndex: 2})
+	assert.Equal(t, "ebs-csi-handoff", sharded.name)
+}
+
+func TestResumeHandoff(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	mockCloud.EXPECT().DeleteDisk(gomock.Any(), "vol-pending").Return(true, nil)
+	mockCloud.EXPECT().EnableFastSnapshotRestores(gomock.Any(), []string{"us-east-1a", "us-east-1b"}, "snap-pending").Return(&ec2.EnableFastSnapshotRestoresOutput{}, nil)
+
+	o := &Options{HandoffLease: "ebs-csi-handoff", HandoffLeaseNamespace: "kube-system"}
+	// The previous leader recorded its in-flight operations before it failed
+	previous := newHandoffStore(fake.NewClientset(), o)
+	previous.record(t.Context(), handoffDeletePrefix, "vol-pending", "")
+	previous.record(t.Context(), handoffFSRPrefix, "snap-pending", "us-east-1a,us-east-1b")
+	previous.flush(t.Context())
+
+	d := NewControllerService(mockCloud, o, previous.client)
+	d.resumeHandoff(t.Context())
+	d.handoff.flush(t.Context())
+
+	for _, prefix := range []string{handoffDeletePrefix, handoffFSRPrefix} {
+		pending, err := d.handoff.pending(t.Context(), prefix)
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	}
+}
+
+func TestResumeHandoffSharded(t *testing.T) {
+	volumeIDs := []string{"vol-pending-1", "vol-pending-2", "vol-pending-3", "vol-pending-4"}
+	shards := map[int]bool{}
+	for _, volumeID := range volumeIDs {
+		shards[volumeShard(volumeID, 2)] = true
+	}
+	require.Len(t, shards, 2, "expected the volumes to belong to both shards")
+
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	for _, volumeID := range volumeIDs {
+		mockCloud.EXPECT().DeleteDisk(gomock.Any(), volumeID).Return(true, nil)
+	}
+
+	client := fake.NewClientset()
+	// The previous provisioner leader ran in shard 0 and recorded the deletions of the volumes of every shard
+	previous := newHandoffStore(client, &Options{HandoffLease: "ebs-csi-handoff", HandoffLeaseNamespace: "kube-system", ControllerShards: 2, ControllerShardIndex: 0})
+	for _, volumeID := range volumeIDs {
+		previous.record(t.Context(), handoffDeletePrefix, volumeID, "")
+	}
+	previous.flush(t.Context())
+
+	// The next leader runs in shard 1
+	d := NewControllerService(mockCloud, &Options{HandoffLease: "ebs-csi-handoff", HandoffLeaseNamespace: "kube-system", ControllerShards: 2, ControllerShardIndex: 1}, client)
+	d.resumeHandoff(t.Context())
+	d.handoff.flush(t.Context())
+
+	pending, err := d.handoff.pending(t.Context(), handoffDeletePrefix)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestHandoffInterceptorIgnoresShardedRPCs(t *testing.T) {
+	d := NewControllerService(cloud.NewMockCloud(gomock.NewController(t)), &Options{HandoffLease: "ebs-csi-handoff", HandoffLeaseNamespace: "kube-system", ControllerShards: 2}, fake.NewClientset())
+	handler := func(context.Context, any) (any, error) { return nil, nil }
+
+	// Every shard receives the sharded RPCs, whether or not its sidecars are the leaders
+	_, err := d.handoffInterceptor(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: csi.Controller_ControllerExpandVolume_FullMethodName}, handler)
+	require.NoError(t, err)
+	resumed := true
+	d.handoff.resumeOnce.Do(func() { resumed = false })
+	assert.False(t, resumed, "expected a sharded RPC not to resume the handoff")
+}
+
+func TestIsControllerOperation(t *testing.T) {
+	assert.True(t, isControllerOperation(csi.Controller_DeleteVolume_FullMethodName))
+	assert.True(t, isControllerOperation("/modify.v1.Modify/ModifyVolumeProperties"))
+	assert.False(t, isControllerOperation(csi.Controller_ControllerGetCapabilities_FullMethodName))
+	assert.False(t, isControllerOperation(csi.Identity_Probe_FullMethodName))
+}
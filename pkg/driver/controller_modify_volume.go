@@ -19,9 +19,8 @@ package driver
 import (
 	"context"
 	"errors"
-	"fmt"
 	"maps"
-	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -29,6 +28,7 @@ import (
 	"github.com/awslabs/volume-modifier-for-k8s/pkg/rpc"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/coalescer"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -49,6 +49,13 @@ const (
 	ModificationDeleteTag = "tagDeletion"
 )
 
+// coalescedRequestsBuckets covers the handful of expansion and modification requests that can target one volume at once.
+var coalescedRequestsBuckets = []float64{1, 2, 3, 4, 5, 10}
+
+// coalesceWaitBuckets covers the merge window (--modify-volume-request-handler-timeout, 2s by default) and the
+// longer waits of requests queued behind an in-flight modification of the same volume.
+var coalesceWaitBuckets = []float64{0.5, 1, 2, 2.5, 5, 10, 30, 60, 120, 300}
+
 type modifyVolumeRequest struct {
 	newSize           int64
 	modifyDiskOptions cloud.ModifyDiskOptions
@@ -71,13 +78,24 @@ func (d *ControllerService) ModifyVolumeProperties(
 	if name == "" {
 		return nil, status.Error(codes.InvalidArgument, "Volume name not provided")
 	}
+	owner, err := d.shardOwner(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil {
+		return rpc.NewModifyClient(owner).ModifyVolumeProperties(shardForwardedContext(ctx), req)
+	}
 
+	d.parameters.reportDeprecated(ctx, "ModifyVolumeProperties", volumeAttributesClassParameters.deprecatedIn(req.GetParameters()), req.GetParameters())
+	if err := d.checkParameterValues(ctx, "ModifyVolumeProperties", volumeAttributesClassParameters, req.GetParameters(), req.GetParameters()); err != nil {
+		return nil, err
+	}
 	options, err := parseModifyVolumeParameters(req.GetParameters())
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = d.modifyVolumeCoalescer.Coalesce(name, *options)
+	_, err = d.modifyVolumeCoalescer.Coalesce(ctx, name, *options)
 	if err != nil {
 		return nil, err
 	}
@@ -86,62 +104,103 @@ func (d *ControllerService) ModifyVolumeProperties(
 }
 
 func newModifyVolumeCoalescer(c cloud.Cloud, o *Options) coalescer.Coalescer[modifyVolumeRequest, int32] {
-	return coalescer.New[modifyVolumeRequest, int32](o.ModifyVolumeRequestHandlerTimeout, mergeModifyVolumeRequest, executeModifyVolumeRequest(c))
+	return coalescer.New[modifyVolumeRequest, int32](o.ModifyVolumeRequestHandlerTimeout, mergeModifyVolumeRequest, executeModifyVolumeRequest(c)).
+		WithObserver(func(requests int, wait time.Duration) {
+			labels := map[string]string{"request": "ModifyVolume"}
+			metrics.Recorder().ObserveHistogram(metrics.CoalescedRequests, metrics.CoalescedRequestsHelpText, float64(requests), labels, coalescedRequestsBuckets)
+			metrics.Recorder().ObserveHistogram(metrics.CoalesceWaitDuration, metrics.CoalesceWaitDurationHelpText, wait.Seconds(), labels, coalesceWaitBuckets)
+		}).
+		WithConflictObserver(func(error) {
+			metrics.Recorder().IncreaseCount(metrics.CoalesceConflicts, metrics.CoalesceConflictsHelpText, map[string]string{"request": "ModifyVolume"})
+		})
 }
 
+// mergeModifyVolumeRequest merges input into the pending request for the same volume. Requests for conflicting
+// values fail with Aborted, so that the caller retries once the pending request has been executed.
 func mergeModifyVolumeRequest(input modifyVolumeRequest, existing modifyVolumeRequest) (modifyVolumeRequest, error) {
 	if input.newSize != 0 {
 		if existing.newSize != 0 && input.newSize != existing.newSize {
-			return existing, fmt.Errorf("different size was requested by a previous request. Current: %d, Requested: %d", existing.newSize, input.newSize)
+			return existing, status.Errorf(codes.Aborted, "different size was requested by a previous request. Current: %d, Requested: %d", existing.newSize, input.newSize)
 		}
 		existing.newSize = input.newSize
 	}
 	if input.modifyDiskOptions.IOPS != 0 {
 		if existing.modifyDiskOptions.IOPS != 0 && input.modifyDiskOptions.IOPS != existing.modifyDiskOptions.IOPS {
-			return existing, fmt.Errorf("different IOPS was requested by a previous request. Current: %d, Requested: %d", existing.modifyDiskOptions.IOPS, input.modifyDiskOptions.IOPS)
+			return existing, status.Errorf(codes.Aborted, "different IOPS was requested by a previous request. Current: %d, Requested: %d", existing.modifyDiskOptions.IOPS, input.modifyDiskOptions.IOPS)
 		}
 		existing.modifyDiskOptions.IOPS = input.modifyDiskOptions.IOPS
 	}
 	if input.modifyDiskOptions.Throughput != 0 {
 		if existing.modifyDiskOptions.Throughput != 0 && input.modifyDiskOptions.Throughput != existing.modifyDiskOptions.Throughput {
-			return existing, fmt.Errorf("different throughput was requested by a previous request. Current: %d, Requested: %d", existing.modifyDiskOptions.Throughput, input.modifyDiskOptions.Throughput)
+			return existing, status.Errorf(codes.Aborted, "different throughput was requested by a previous request. Current: %d, Requested: %d", existing.modifyDiskOptions.Throughput, input.modifyDiskOptions.Throughput)
 		}
 		existing.modifyDiskOptions.Throughput = input.modifyDiskOptions.Throughput
 	}
 	if input.modifyDiskOptions.VolumeType != "" {
 		if existing.modifyDiskOptions.VolumeType != "" && input.modifyDiskOptions.VolumeType != existing.modifyDiskOptions.VolumeType {
-			return existing, fmt.Errorf("different volume type was requested by a previous request. Current: %s, Requested: %s", existing.modifyDiskOptions.VolumeType, input.modifyDiskOptions.VolumeType)
+			return existing, status.Errorf(codes.Aborted, "different volume type was requested by a previous request. Current: %s, Requested: %s", existing.modifyDiskOptions.VolumeType, input.modifyDiskOptions.VolumeType)
 		}
 		existing.modifyDiskOptions.VolumeType = input.modifyDiskOptions.VolumeType
 	}
 	if len(input.modifyTagsOptions.TagsToAdd) > 0 || len(input.modifyTagsOptions.TagsToDelete) > 0 {
-		if (len(existing.modifyTagsOptions.TagsToAdd) > 0 || len(existing.modifyTagsOptions.TagsToDelete) > 0) && !(reflect.DeepEqual(input.modifyTagsOptions, existing.modifyTagsOptions)) {
-			return existing, fmt.Errorf("different tags were requested by a previous request. Current: %v, Requested: %v", existing.modifyTagsOptions, input.modifyTagsOptions)
-		}
-		existing.modifyTagsOptions = cloud.ModifyTagsOptions{
-			TagsToAdd:    input.modifyTagsOptions.TagsToAdd,
-			TagsToDelete: input.modifyTagsOptions.TagsToDelete,
+		merged, err := mergeModifyTagsOptions(input.modifyTagsOptions, existing.modifyTagsOptions)
+		if err != nil {
+			return existing, err
 		}
+		existing.modifyTagsOptions = merged
 	}
 	return existing, nil
 }
 
+// mergeModifyTagsOptions combines the tag changes of two requests into a single set of changes. Like the other
+// parameters, a tag set to different values, or added by one request and deleted by the other, fails with Aborted.
+func mergeModifyTagsOptions(input cloud.ModifyTagsOptions, existing cloud.ModifyTagsOptions) (cloud.ModifyTagsOptions, error) {
+	for key, value := range input.TagsToAdd {
+		if existingValue, ok := existing.TagsToAdd[key]; ok && existingValue != value {
+			return existing, status.Errorf(codes.Aborted, "different value of tag %q was requested by a previous request. Current: %s, Requested: %s", key, existingValue, value)
+		}
+		if slices.Contains(existing.TagsToDelete, key) {
+			return existing, status.Errorf(codes.Aborted, "tag %q was deleted by a previous request and requested to be added", key)
+		}
+	}
+	for _, key := range input.TagsToDelete {
+		if _, ok := existing.TagsToAdd[key]; ok {
+			return existing, status.Errorf(codes.Aborted, "tag %q was added by a previous request and requested to be deleted", key)
+		}
+	}
+
+	merged := cloud.ModifyTagsOptions{
+		TagsToAdd:    maps.Clone(existing.TagsToAdd),
+		TagsToDelete: slices.Clone(existing.TagsToDelete),
+	}
+	if merged.TagsToAdd == nil {
+		merged.TagsToAdd = make(map[string]string, len(input.TagsToAdd))
+	}
+	maps.Copy(merged.TagsToAdd, input.TagsToAdd)
+	for _, key := range input.TagsToDelete {
+		if !slices.Contains(merged.TagsToDelete, key) {
+			merged.TagsToDelete = append(merged.TagsToDelete, key)
+		}
+	}
+	return merged, nil
+}
+
 func executeModifyTagsRequest(volumeID string, options modifyVolumeRequest, c cloud.Cloud, ctx context.Context) error {
 	if len(options.modifyTagsOptions.TagsToAdd) > 0 || len(options.modifyTagsOptions.TagsToDelete) > 0 {
 		err := c.ModifyTags(ctx, volumeID, options.modifyTagsOptions)
 		if err != nil {
 			if errors.Is(err, cloud.ErrInvalidArgument) {
-				return status.Errorf(codes.InvalidArgument, "Could not modify volume tags (invalid argument) %q: %v", volumeID, err)
+				return statusWithAWSDetails(codes.InvalidArgument, err, "Could not modify volume tags (invalid argument) %q: %v", volumeID, err)
 			}
-			return status.Errorf(codes.Internal, "Could not modify volume tags %q: %v", volumeID, err)
+			return awsErrorToStatus(err, codes.Internal, "Could not modify volume tags %q: %v", volumeID, err)
 		}
 	}
 	return nil
 }
 
-func executeModifyVolumeRequest(c cloud.Cloud) func(string, modifyVolumeRequest) (int32, error) {
-	return func(volumeID string, req modifyVolumeRequest) (int32, error) {
-		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+func executeModifyVolumeRequest(c cloud.Cloud) func(context.Context, string, modifyVolumeRequest) (int32, error) {
+	return func(ctx context.Context, volumeID string, req modifyVolumeRequest) (int32, error) {
+		ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 		defer cancel()
 		err := executeModifyTagsRequest(volumeID, req, c, ctx)
 		if err != nil {
@@ -156,15 +215,15 @@ func executeModifyVolumeRequest(c cloud.Cloud) func(string, modifyVolumeRequest)
 					// Returning Internal error instead of InvaliArgument because at this point any tag modifications have succeeded.
 					// It would not be correct to return an error that is considered infeasible by the resizer if the volume was already modified in any way.
 					if len(req.modifyTagsOptions.TagsToAdd) > 0 || len(req.modifyTagsOptions.TagsToDelete) > 0 {
-						return 0, status.Errorf(codes.Internal, "Could not modify volume (invalid argument) %q: %v", volumeID, err)
+						return 0, statusWithAWSDetails(codes.Internal, err, "Could not modify volume (invalid argument) %q: %v", volumeID, err)
 					}
-					return 0, status.Errorf(codes.InvalidArgument, "Could not modify volume (invalid argument) %q: %v", volumeID, err)
+					return 0, statusWithAWSDetails(codes.InvalidArgument, err, "Could not modify volume (invalid argument) %q: %v", volumeID, err)
 				case errors.Is(err, cloud.ErrNotFound):
-					return 0, status.Errorf(codes.NotFound, "Could not modify volume (not found) %q: %v", volumeID, err)
+					return 0, statusWithAWSDetails(codes.NotFound, err, "Could not modify volume (not found) %q: %v", volumeID, err)
 				case errors.Is(err, cloud.ErrLimitExceeded):
-					return 0, status.Errorf(codes.ResourceExhausted, "Could not modify volume (resource exhausted) %q: %v", volumeID, err)
+					return 0, statusWithAWSDetails(codes.ResourceExhausted, err, "Could not modify volume (resource exhausted) %q: %v", volumeID, err)
 				default:
-					return 0, status.Errorf(codes.Internal, "Could not modify volume %q: %v", volumeID, err)
+					return 0, awsErrorToStatus(err, codes.Internal, "Could not modify volume %q: %v", volumeID, err)
 				}
 			} else {
 				return actualSizeGiB, nil
@@ -185,7 +244,7 @@ func parseModifyVolumeParameters(params map[string]string) (*modifyVolumeRequest
 	var rawTagsToAdd []string
 	var noValidationTags = make(map[string]string)
 	tProps := new(template.PVProps)
-	for key, value := range params {
+	for key, value := range volumeAttributesClassParameters.resolve(params) {
 		switch key {
 		case ModificationKeyIOPS:
 			iops, err := strconv.ParseInt(value, 10, 32)
@@ -199,13 +258,6 @@ func parseModifyVolumeParameters(params map[string]string) (*modifyVolumeRequest
 				return nil, status.Errorf(codes.InvalidArgument, "Could not parse throughput: %q", value)
 			}
 			options.modifyDiskOptions.Throughput = int32(throughput)
-		case DeprecatedModificationKeyVolumeType:
-			if _, ok := params[ModificationKeyVolumeType]; ok {
-				klog.Infof("Ignoring deprecated key `volumeType` because preferred key `type` is present")
-				continue
-			}
-			klog.InfoS("Key `volumeType` is deprecated, please use `type` instead")
-			options.modifyDiskOptions.VolumeType = value
 		case ModificationKeyVolumeType:
 			options.modifyDiskOptions.VolumeType = value
 		case IopsPerGBKey:
@@ -23,6 +23,8 @@ import (
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -129,6 +131,88 @@ func TestMergeModifyVolumeRequest(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "Valid merge of modification and tags",
+			input: modifyVolumeRequest{
+				modifyTagsOptions: cloud.ModifyTagsOptions{
+					TagsToAdd: map[string]string{"key1": "tag1"},
+				},
+			},
+			existing: modifyVolumeRequest{
+				modifyDiskOptions: cloud.ModifyDiskOptions{
+					IOPS: validIopsInt,
+				},
+			},
+			expectedModifyVolumeRequest: modifyVolumeRequest{
+				modifyDiskOptions: cloud.ModifyDiskOptions{
+					IOPS: validIopsInt,
+				},
+				modifyTagsOptions: cloud.ModifyTagsOptions{
+					TagsToAdd: map[string]string{"key1": "tag1"},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid merge of different tags",
+			input: modifyVolumeRequest{
+				modifyTagsOptions: cloud.ModifyTagsOptions{
+					TagsToAdd:    map[string]string{"key1": "tag1", "key2": "tag2"},
+					TagsToDelete: []string{"key3"},
+				},
+			},
+			existing: modifyVolumeRequest{
+				modifyTagsOptions: cloud.ModifyTagsOptions{
+					TagsToAdd:    map[string]string{"key1": "tag1", "key4": "tag4"},
+					TagsToDelete: []string{"key5"},
+				},
+			},
+			expectedModifyVolumeRequest: modifyVolumeRequest{
+				modifyTagsOptions: cloud.ModifyTagsOptions{
+					TagsToAdd:    map[string]string{"key1": "tag1", "key2": "tag2", "key4": "tag4"},
+					TagsToDelete: []string{"key5", "key3"},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Invalid merge of different tag values",
+			input: modifyVolumeRequest{
+				modifyTagsOptions: cloud.ModifyTagsOptions{
+					TagsToAdd: map[string]string{"key1": "new"},
+				},
+			},
+			existing: modifyVolumeRequest{
+				modifyTagsOptions: cloud.ModifyTagsOptions{
+					TagsToAdd: map[string]string{"key1": "old"},
+				},
+			},
+			expectedModifyVolumeRequest: modifyVolumeRequest{
+				modifyTagsOptions: cloud.ModifyTagsOptions{
+					TagsToAdd: map[string]string{"key1": "old"},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "Invalid merge of added and deleted tag",
+			input: modifyVolumeRequest{
+				modifyTagsOptions: cloud.ModifyTagsOptions{
+					TagsToDelete: []string{"key1"},
+				},
+			},
+			existing: modifyVolumeRequest{
+				modifyTagsOptions: cloud.ModifyTagsOptions{
+					TagsToAdd: map[string]string{"key1": "tag1"},
+				},
+			},
+			expectedModifyVolumeRequest: modifyVolumeRequest{
+				modifyTagsOptions: cloud.ModifyTagsOptions{
+					TagsToAdd: map[string]string{"key1": "tag1"},
+				},
+			},
+			expectError: true,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -136,6 +220,7 @@ func TestMergeModifyVolumeRequest(t *testing.T) {
 			assert.Equal(t, tc.expectedModifyVolumeRequest, result)
 			if tc.expectError {
 				require.Error(t, err)
+				assert.Equal(t, codes.Aborted, status.Code(err))
 			} else {
 				require.NoError(t, err)
 			}
@@ -0,0 +1,328 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// namespaceQuotaWildcard is the NamespaceQuotas key whose quota applies to namespaces without their own entry.
+const namespaceQuotaWildcard = "*"
+
+// Default IOPS of volumes created without the iops or iopsPerGB parameter, used to account for their IOPS.
+const (
+	gp3DefaultIOPS  = 3000
+	gp2IOPSPerGiB   = 3
+	gp2MinBurstIOPS = 100
+	gp2MaxBaseIOPS  = 16000
+)
+
+// minIOPS are the minimum IOPS of the volume types with provisioned IOPS, which volumes are increased to with
+// allowAutoIOPSPerGBIncrease.
+var minIOPS = map[string]int64{
+	cloud.VolumeTypeGP3: gp3DefaultIOPS,
+	cloud.VolumeTypeIO1: 100,
+	cloud.VolumeTypeIO2: 100,
+}
+
+// QuotaLimits are limits on the total size and IOPS of the volumes provisioned for a namespace. 0 means no limit.
+type QuotaLimits struct {
+	CapacityGiB int64 `json:"capacityGiB,omitempty"`
+	IOPS        int64 `json:"iops,omitempty"`
+}
+
+// NamespaceQuota limits the volumes provisioned for a namespace, in total and per volume type.
+type NamespaceQuota struct {
+	QuotaLimits
+	VolumeTypes map[string]QuotaLimits `json:"volumeTypes,omitempty"`
+}
+
+// namespaceQuotasFile is a flag.Value that loads NamespaceQuotas from a YAML or JSON file when the flag is set.
+type namespaceQuotasFile struct {
+	path   string
+	quotas *map[string]NamespaceQuota
+}
+
+func (f *namespaceQuotasFile) String() string { return f.path }
+
+func (f *namespaceQuotasFile) Type() string { return "string" }
+
+func (f *namespaceQuotasFile) Set(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read namespace quotas file: %w", err)
+	}
+	quotas := map[string]NamespaceQuota{}
+	if err := yaml.UnmarshalStrict(data, &quotas); err != nil {
+		return fmt.Errorf("could not parse namespace quotas file %s: %w", path, err)
+	}
+	f.path = path
+	*f.quotas = quotas
+	return nil
+}
+
+// namespaceQuotaExceededReason is the reason of the events emitted on PVCs whose volume would take their namespace
+// over its quota.
+const namespaceQuotaExceededReason = "NamespaceQuotaExceeded"
+
+// namespaceUsageTTL is how long the usage of a namespace read from EC2 is used before it is read again. Usage that
+// would exceed a quota is always read again first, so that volumes deleted since are not counted.
+const namespaceUsageTTL = 5 * time.Minute
+
+// namespaceQuotaEnforcer rejects CreateVolume requests that would take a namespace over its NamespaceQuota.
+// Usage is computed from the PVC namespace tag of the volumes provisioned by the driver, so it survives controller
+// restarts and leader changes but requires the external-provisioner to run with --extra-create-metadata.
+type namespaceQuotaEnforcer struct {
+	cloud     cloud.Cloud
+	quotas    map[string]NamespaceQuota
+	clusterID string
+	recorder  record.EventRecorder
+
+	// usages caches the usage of each namespace. Entries are never removed; there is one per namespace.
+	mu     sync.Mutex
+	usages map[string]*namespaceUsage
+}
+
+// volumeUsage is the size and IOPS of a volume, counted against the quotas of its volume type.
+type volumeUsage struct {
+	volumeType string
+	QuotaLimits
+}
+
+// namespaceUsage is the usage of a namespace: the volumes read from EC2, the volumes created since, and the volumes
+// being created. Its lock serializes the quota checks of the namespace, so that concurrent requests cannot all fit in
+// the remaining quota, but is not held while volumes are created.
+type namespaceUsage struct {
+	mu sync.Mutex
+	// volumes are keyed by volume ID
+	volumes map[string]volumeUsage
+	readAt  time.Time
+	// reservations are the volumes being created, keyed by volume name
+	reservations map[string]volumeUsage
+}
+
+func newNamespaceQuotaEnforcer(c cloud.Cloud, k kubernetes.Interface, o *Options) *namespaceQuotaEnforcer {
+	if len(o.NamespaceQuotas) == 0 {
+		return nil
+	}
+	e := &namespaceQuotaEnforcer{
+		cloud:     c,
+		quotas:    o.NamespaceQuotas,
+		clusterID: o.KubernetesClusterID,
+		usages:    map[string]*namespaceUsage{},
+	}
+	if k != nil {
+		e.recorder = newEventRecorder(k)
+	}
+	return e
+}
+
+func (e *namespaceQuotaEnforcer) quotaFor(namespace string) (NamespaceQuota, bool) {
+	if quota, ok := e.quotas[namespace]; ok {
+		return quota, true
+	}
+	quota, ok := e.quotas[namespaceQuotaWildcard]
+	return quota, ok
+}
+
+func (e *namespaceQuotaEnforcer) usage(namespace string) *namespaceUsage {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	u, ok := e.usages[namespace]
+	if !ok {
+		u = &namespaceUsage{reservations: map[string]volumeUsage{}}
+		e.usages[namespace] = u
+	}
+	return u
+}
+
+// reserve checks that the volume fits in the quota of the namespace of its PVC and reserves its size and IOPS until
+// it is created. On success, it returns a function that must be called with the created volume, or nil if creation
+// failed, to release the reservation. Reserving a volume name again, for instance with a fallback volume type,
+// replaces its reservation.
+func (e *namespaceQuotaEnforcer) reserve(ctx context.Context, tProps *template.PVProps, volName string, opts *cloud.DiskOptions) (func(*cloud.Disk), error) {
+	if e == nil {
+		return func(*cloud.Disk) {}, nil
+	}
+	namespace := tProps.PVCNamespace
+	if namespace == "" {
+		klog.V(4).InfoS("CreateVolume: PVC namespace unknown, not enforcing namespace quotas", "volumeName", volName)
+		return func(*cloud.Disk) {}, nil
+	}
+	quota, ok := e.quotaFor(namespace)
+	if !ok {
+		return func(*cloud.Disk) {}, nil
+	}
+	volumeType := opts.VolumeType
+	if volumeType == "" {
+		volumeType = cloud.VolumeTypeGP3
+	}
+	sizeGiB := int64(util.BytesToGiB(opts.CapacityBytes))
+	requested := volumeUsage{
+		volumeType:  volumeType,
+		QuotaLimits: QuotaLimits{CapacityGiB: sizeGiB, IOPS: requestedIOPS(volumeType, sizeGiB, opts.IOPS, opts.IOPSPerGB, opts.AllowIOPSPerGBIncrease)},
+	}
+
+	u := e.usage(namespace)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	read := false
+	if time.Since(u.readAt) > namespaceUsageTTL {
+		if err := e.read(ctx, namespace, u); err != nil {
+			return nil, awsErrorToStatus(err, codes.Unavailable, "Could not compute usage of namespace %q: %v", namespace, err)
+		}
+		read = true
+	}
+	exceeded := u.exceeded(quota, volName, requested)
+	if exceeded != "" && !read {
+		if err := e.read(ctx, namespace, u); err != nil {
+			return nil, awsErrorToStatus(err, codes.Unavailable, "Could not compute usage of namespace %q: %v", namespace, err)
+		}
+		exceeded = u.exceeded(quota, volName, requested)
+	}
+	if exceeded == "" {
+		u.reservations[volName] = requested
+		return func(disk *cloud.Disk) {
+			u.mu.Lock()
+			defer u.mu.Unlock()
+			delete(u.reservations, volName)
+			if disk != nil {
+				u.volumes[disk.VolumeID] = requested
+			}
+		}, nil
+	}
+
+	// The volume may already exist if this is a retry of a request that succeeded, in which case it is already
+	// part of the usage and CreateDisk will return it.
+	if _, err := e.cloud.GetDiskByName(ctx, volName, opts.CapacityBytes); err == nil {
+		return func(*cloud.Disk) {}, nil
+	}
+	klog.InfoS("CreateVolume: namespace quota exceeded", "namespace", namespace, "volumeName", volName, "quota", exceeded)
+	if e.recorder != nil && tProps.PVCName != "" {
+		claim := &corev1.ObjectReference{Kind: "PersistentVolumeClaim", APIVersion: "v1", Namespace: namespace, Name: tProps.PVCName}
+		e.recorder.Eventf(claim, corev1.EventTypeWarning, namespaceQuotaExceededReason, "Namespace quota exceeded: %s", exceeded)
+	}
+	return nil, status.Errorf(codes.ResourceExhausted, "Namespace %q quota exceeded: %s", namespace, exceeded)
+}
+
+// read reads the volumes provisioned for namespace from EC2, except the ones pending deletion.
+func (e *namespaceQuotaEnforcer) read(ctx context.Context, namespace string, u *namespaceUsage) error {
+	filter := map[string]string{
+		cloud.AwsEbsDriverTagKey: isManagedByDriver,
+		PVCNamespaceTag:          namespace,
+	}
+	if e.clusterID != "" {
+		filter[ResourceLifecycleTagPrefix+e.clusterID] = ResourceLifecycleOwned
+	}
+	disks, err := e.cloud.ListDisks(ctx, nil, filter)
+	if err != nil {
+		return err
+	}
+	u.volumes = make(map[string]volumeUsage, len(disks))
+	for _, disk := range disks {
+		// Soft-deleted volumes were deleted by their PVC and no longer count, even though they exist until reaped
+		if !disk.PendingDeletionAt.IsZero() {
+			continue
+		}
+		u.volumes[disk.VolumeID] = volumeUsage{
+			volumeType:  disk.VolumeType,
+			QuotaLimits: QuotaLimits{CapacityGiB: int64(disk.CapacityGiB), IOPS: int64(disk.IOPS)},
+		}
+	}
+	u.readAt = time.Now()
+	return nil
+}
+
+// exceeded returns a description of the limit of quota that adding the volume to the usage would exceed, or "" if it
+// fits. The previous reservation of the volume, if any, is not counted.
+func (u *namespaceUsage) exceeded(quota NamespaceQuota, volName string, requested volumeUsage) string {
+	var total, typeTotal QuotaLimits
+	add := func(v volumeUsage) {
+		total.CapacityGiB += v.CapacityGiB
+		total.IOPS += v.IOPS
+		if v.volumeType == requested.volumeType {
+			typeTotal.CapacityGiB += v.CapacityGiB
+			typeTotal.IOPS += v.IOPS
+		}
+	}
+	for _, v := range u.volumes {
+		add(v)
+	}
+	for name, v := range u.reservations {
+		if name != volName {
+			add(v)
+		}
+	}
+
+	if exceeded := exceededQuota(quota.QuotaLimits, total, requested.CapacityGiB, requested.IOPS, ""); exceeded != "" {
+		return exceeded
+	}
+	if typeQuota, ok := quota.VolumeTypes[requested.volumeType]; ok {
+		return exceededQuota(typeQuota, typeTotal, requested.CapacityGiB, requested.IOPS, requested.volumeType+" ")
+	}
+	return ""
+}
+
+// exceededQuota returns a description of the limit that adding a volume to used would exceed, or "" if it fits.
+func exceededQuota(limits, used QuotaLimits, sizeGiB, iops int64, prefix string) string {
+	if limits.CapacityGiB > 0 && used.CapacityGiB+sizeGiB > limits.CapacityGiB {
+		return fmt.Sprintf("%scapacity (requested %d GiB, used %d GiB of %d GiB)", prefix, sizeGiB, used.CapacityGiB, limits.CapacityGiB)
+	}
+	if limits.IOPS > 0 && used.IOPS+iops > limits.IOPS {
+		return fmt.Sprintf("%sIOPS (requested %d, used %d of %d)", prefix, iops, used.IOPS, limits.IOPS)
+	}
+	return ""
+}
+
+// requestedIOPS estimates the IOPS of a new volume from the CreateVolume parameters. With allowIOPSIncrease, IOPS
+// below the minimum of the volume type are increased to it, like CreateDisk does.
+func requestedIOPS(volumeType string, sizeGiB int64, iops, iopsPerGB int32, allowIOPSIncrease bool) int64 {
+	requested := int64(iops)
+	if requested == 0 && iopsPerGB > 0 {
+		requested = int64(iopsPerGB) * sizeGiB
+	}
+	if requested > 0 {
+		if allowIOPSIncrease {
+			requested = max(requested, minIOPS[volumeType])
+		}
+		return requested
+	}
+	switch volumeType {
+	case cloud.VolumeTypeGP3:
+		return gp3DefaultIOPS
+	case cloud.VolumeTypeGP2:
+		return min(max(gp2IOPSPerGiB*sizeGiB, gp2MinBurstIOPS), gp2MaxBaseIOPS)
+	default:
+		return 0
+	}
+}
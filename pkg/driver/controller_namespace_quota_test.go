@@ -0,0 +1,270 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver/internal"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestNamespaceQuotaReserve(t *testing.T) {
+	const namespace = "team-a"
+	quotas := map[string]NamespaceQuota{
+		namespace: {
+			QuotaLimits: QuotaLimits{CapacityGiB: 100, IOPS: 10000},
+			VolumeTypes: map[string]QuotaLimits{cloud.VolumeTypeIO2: {CapacityGiB: 20}},
+		},
+		namespaceQuotaWildcard: {QuotaLimits: QuotaLimits{CapacityGiB: 10}},
+	}
+	used := []*cloud.Disk{
+		{VolumeID: "vol-gp3", CapacityGiB: 50, VolumeType: cloud.VolumeTypeGP3, IOPS: 3000},
+		{VolumeID: "vol-io2", CapacityGiB: 10, VolumeType: cloud.VolumeTypeIO2, IOPS: 1000},
+		// Soft-deleted volumes do not count
+		{VolumeID: "vol-pending-deletion", CapacityGiB: 500, VolumeType: cloud.VolumeTypeGP3, IOPS: 3000, PendingDeletionAt: time.Now().Add(time.Hour)},
+	}
+
+	testCases := []struct {
+		name         string
+		namespace    string
+		volumeType   string
+		sizeGiB      int64
+		iops         int32
+		listErr      error
+		existingDisk bool
+		expectList   bool
+		expectedCode codes.Code
+	}{
+		{
+			name:       "success: within quota",
+			namespace:  namespace,
+			sizeGiB:    40,
+			expectList: true,
+		},
+		{
+			name:      "success: namespace unknown",
+			namespace: "",
+			sizeGiB:   1000,
+		},
+		{
+			name:         "fail: capacity exceeded",
+			namespace:    namespace,
+			sizeGiB:      41,
+			expectList:   true,
+			expectedCode: codes.ResourceExhausted,
+		},
+		{
+			name:         "fail: IOPS exceeded",
+			namespace:    namespace,
+			volumeType:   cloud.VolumeTypeIO1,
+			sizeGiB:      10,
+			iops:         6001,
+			expectList:   true,
+			expectedCode: codes.ResourceExhausted,
+		},
+		{
+			name:         "fail: volume type capacity exceeded",
+			namespace:    namespace,
+			volumeType:   cloud.VolumeTypeIO2,
+			sizeGiB:      11,
+			iops:         100,
+			expectList:   true,
+			expectedCode: codes.ResourceExhausted,
+		},
+		{
+			name:         "success: volume already created",
+			namespace:    namespace,
+			sizeGiB:      41,
+			existingDisk: true,
+			expectList:   true,
+		},
+		{
+			name:         "fail: wildcard quota exceeded",
+			namespace:    "team-b",
+			sizeGiB:      61,
+			expectList:   true,
+			expectedCode: codes.ResourceExhausted,
+		},
+		{
+			name:         "fail: usage unavailable",
+			namespace:    namespace,
+			sizeGiB:      1,
+			listErr:      errors.New("list error"),
+			expectList:   true,
+			expectedCode: codes.Unavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			if tc.expectList {
+				mockCloud.EXPECT().ListDisks(gomock.Any(), gomock.Nil(), gomock.Any()).DoAndReturn(
+					func(_ any, _ []string, tags map[string]string) ([]*cloud.Disk, error) {
+						assert.Equal(t, tc.namespace, tags[PVCNamespaceTag])
+						return used, tc.listErr
+					})
+			}
+			if tc.expectedCode == codes.ResourceExhausted || tc.existingDisk {
+				var getErr error
+				if !tc.existingDisk {
+					getErr = cloud.ErrNotFound
+				}
+				mockCloud.EXPECT().GetDiskByName(gomock.Any(), "vol-name", tc.sizeGiB*util.GiB).Return(&cloud.Disk{}, getErr)
+			}
+
+			e := newNamespaceQuotaEnforcer(mockCloud, nil, &Options{NamespaceQuotas: quotas})
+			recorder := record.NewFakeRecorder(1)
+			e.recorder = recorder
+			tProps := &template.PVProps{PVCName: "claim", PVCNamespace: tc.namespace}
+			opts := &cloud.DiskOptions{VolumeType: tc.volumeType, CapacityBytes: tc.sizeGiB * util.GiB, IOPS: tc.iops}
+			release, err := e.reserve(t.Context(), tProps, "vol-name", opts)
+			if tc.expectedCode != codes.OK {
+				require.Error(t, err)
+				assert.Equal(t, tc.expectedCode, status.Code(err))
+				if tc.expectedCode == codes.ResourceExhausted {
+					require.Len(t, recorder.Events, 1)
+					assert.Contains(t, <-recorder.Events, "Warning "+namespaceQuotaExceededReason+" Namespace quota exceeded: ")
+				}
+				return
+			}
+			require.NoError(t, err)
+			release(nil)
+			assert.Empty(t, recorder.Events)
+		})
+	}
+}
+
+func TestNamespaceQuotaReservations(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	quotas := map[string]NamespaceQuota{"team-a": {QuotaLimits: QuotaLimits{CapacityGiB: 100}}}
+	e := newNamespaceQuotaEnforcer(mockCloud, nil, &Options{NamespaceQuotas: quotas})
+	tProps := &template.PVProps{PVCNamespace: "team-a"}
+	opts := &cloud.DiskOptions{CapacityBytes: 40 * util.GiB}
+
+	// Usage is read once, then the reservations are counted until the volumes are created
+	mockCloud.EXPECT().ListDisks(gomock.Any(), gomock.Nil(), gomock.Any()).Return([]*cloud.Disk{{VolumeID: "vol-1", CapacityGiB: 20}}, nil)
+	release1, err := e.reserve(t.Context(), tProps, "vol-name-1", opts)
+	require.NoError(t, err)
+	release2, err := e.reserve(t.Context(), tProps, "vol-name-2", opts)
+	require.NoError(t, err)
+
+	// Usage that would exceed the quota is read again before the request is rejected
+	mockCloud.EXPECT().ListDisks(gomock.Any(), gomock.Nil(), gomock.Any()).Return([]*cloud.Disk{{VolumeID: "vol-1", CapacityGiB: 20}}, nil)
+	mockCloud.EXPECT().GetDiskByName(gomock.Any(), "vol-name-3", opts.CapacityBytes).Return(nil, cloud.ErrNotFound)
+	_, err = e.reserve(t.Context(), tProps, "vol-name-3", opts)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// A failed creation frees its reservation, a successful one is counted as a volume
+	release1(nil)
+	release2(&cloud.Disk{VolumeID: "vol-2"})
+	release3, err := e.reserve(t.Context(), tProps, "vol-name-3", opts)
+	require.NoError(t, err)
+
+	// Reserving the same volume again, as for a fallback volume type, replaces its reservation
+	_, err = e.reserve(t.Context(), tProps, "vol-name-3", &cloud.DiskOptions{CapacityBytes: 40 * util.GiB, VolumeType: cloud.VolumeTypeGP2})
+	require.NoError(t, err)
+	release3(nil)
+}
+
+func TestCreateVolumeNamespaceQuota(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	mockCloud.EXPECT().ListDisks(gomock.Any(), gomock.Nil(), gomock.Any()).Return([]*cloud.Disk{{CapacityGiB: 10, VolumeType: cloud.VolumeTypeGP3}}, nil)
+	mockCloud.EXPECT().GetDiskByName(gomock.Any(), "vol-name", gomock.Any()).Return(nil, cloud.ErrNotFound)
+	mockCloud.EXPECT().CreateDisk(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	o := &Options{NamespaceQuotas: map[string]NamespaceQuota{"team-a": {QuotaLimits: QuotaLimits{CapacityGiB: 10}}}}
+	d := &ControllerService{
+		cloud:           mockCloud,
+		inFlight:        internal.NewInFlight(),
+		options:         o,
+		namespaceQuotas: newNamespaceQuotaEnforcer(mockCloud, nil, o),
+	}
+	_, err := d.CreateVolume(t.Context(), &csi.CreateVolumeRequest{
+		Name:          "vol-name",
+		CapacityRange: &csi.CapacityRange{RequiredBytes: util.GiB},
+		VolumeCapabilities: []*csi.VolumeCapability{{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		}},
+		Parameters: map[string]string{PVCNamespaceKey: "team-a"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), `Namespace "team-a" quota exceeded`)
+}
+
+func TestRequestedIOPS(t *testing.T) {
+	assert.Equal(t, int64(5000), requestedIOPS(cloud.VolumeTypeIO2, 10, 5000, 0, false))
+	assert.Equal(t, int64(500), requestedIOPS(cloud.VolumeTypeIO1, 10, 0, 50, false))
+	assert.Equal(t, int64(gp3DefaultIOPS), requestedIOPS(cloud.VolumeTypeGP3, 10, 0, 0, false))
+	assert.Equal(t, int64(gp2MinBurstIOPS), requestedIOPS(cloud.VolumeTypeGP2, 10, 0, 0, false))
+	assert.Equal(t, int64(300), requestedIOPS(cloud.VolumeTypeGP2, 100, 0, 0, false))
+	assert.Equal(t, int64(gp2MaxBaseIOPS), requestedIOPS(cloud.VolumeTypeGP2, 10000, 0, 0, false))
+	assert.Equal(t, int64(0), requestedIOPS(cloud.VolumeTypeST1, 500, 0, 0, false))
+
+	// allowAutoIOPSPerGBIncrease increases the IOPS of small volumes to the minimum of the volume type
+	assert.Equal(t, int64(400), requestedIOPS(cloud.VolumeTypeGP3, 4, 0, 100, false))
+	assert.Equal(t, int64(gp3DefaultIOPS), requestedIOPS(cloud.VolumeTypeGP3, 4, 0, 100, true))
+	assert.Equal(t, int64(100), requestedIOPS(cloud.VolumeTypeIO2, 4, 0, 1, true))
+	assert.Equal(t, int64(5000), requestedIOPS(cloud.VolumeTypeGP3, 10, 0, 500, true))
+}
+
+func TestNamespaceQuotasFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotas.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+team-a:
+  capacityGiB: 1000
+  iops: 50000
+  volumeTypes:
+    io2:
+      iops: 20000
+"*":
+  capacityGiB: 100
+`), 0o600))
+
+	var quotas map[string]NamespaceQuota
+	f := &namespaceQuotasFile{quotas: &quotas}
+	require.NoError(t, f.Set(path))
+	assert.Equal(t, map[string]NamespaceQuota{
+		"team-a": {
+			QuotaLimits: QuotaLimits{CapacityGiB: 1000, IOPS: 50000},
+			VolumeTypes: map[string]QuotaLimits{cloud.VolumeTypeIO2: {IOPS: 20000}},
+		},
+		namespaceQuotaWildcard: {QuotaLimits: QuotaLimits{CapacityGiB: 100}},
+	}, quotas)
+
+	require.NoError(t, os.WriteFile(path, []byte("team-a:\n  capacity: 10\n"), 0o600))
+	require.Error(t, f.Set(path))
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"google.golang.org/grpc"
+	"k8s.io/klog/v2"
+)
+
+// OperationsPath is the path of the debug endpoint that serves the in-flight operations of the controller.
+const OperationsPath = "/debug/operations"
+
+// Operation is a controller RPC in flight.
+type Operation struct {
+	RPC string `json:"rpc"`
+	// Name is the name of the volume or snapshot created by CreateVolume and CreateSnapshot.
+	Name       string    `json:"name,omitempty"`
+	VolumeID   string    `json:"volumeID,omitempty"`
+	SnapshotID string    `json:"snapshotID,omitempty"`
+	NodeID     string    `json:"nodeID,omitempty"`
+	StartTime  time.Time `json:"startTime"`
+	// Duration is how long the RPC has been in flight, in seconds.
+	Duration       float64  `json:"duration"`
+	CorrelationIDs []string `json:"correlationIDs,omitempty"`
+	// Step is what the RPC is currently doing, such as the EC2 call or the wait it is blocked on.
+	Step string `json:"step,omitempty"`
+}
+
+// trackedOperation is an Operation whose step is updated by the RPC.
+type trackedOperation struct {
+	Operation
+	step *util.OperationStep
+}
+
+// operationTracker keeps the controller RPCs in flight, to tell what the controller is doing during incidents such
+// as stuck provisioning. It serves them as JSON on OperationsPath.
+type operationTracker struct {
+	mu         sync.Mutex
+	next       uint64
+	operations map[uint64]*trackedOperation
+}
+
+func newOperationTracker() *operationTracker {
+	return &operationTracker{operations: map[uint64]*trackedOperation{}}
+}
+
+// interceptor tracks the controller RPCs while they are in flight.
+func (t *operationTracker) interceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !isControllerOperation(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	op := &trackedOperation{
+		Operation: Operation{
+			RPC:            path.Base(info.FullMethod),
+			StartTime:      time.Now(),
+			CorrelationIDs: util.CorrelationIDs(ctx),
+		},
+		step: &util.OperationStep{},
+	}
+	if r, ok := req.(interface{ GetName() string }); ok {
+		op.Name = r.GetName()
+	}
+	if r, ok := req.(interface{ GetVolumeId() string }); ok {
+		op.VolumeID = r.GetVolumeId()
+	}
+	if r, ok := req.(interface{ GetSourceVolumeId() string }); ok {
+		op.VolumeID = r.GetSourceVolumeId()
+	}
+	if r, ok := req.(interface{ GetSnapshotId() string }); ok {
+		op.SnapshotID = r.GetSnapshotId()
+	}
+	if r, ok := req.(interface{ GetNodeId() string }); ok {
+		op.NodeID = r.GetNodeId()
+	}
+
+	t.mu.Lock()
+	id := t.next
+	t.next++
+	t.operations[id] = op
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.operations, id)
+	}()
+
+	return handler(util.WithOperationStep(ctx, op.step), req)
+}
+
+// list returns the operations in flight, oldest first.
+func (t *operationTracker) list() []Operation {
+	now := time.Now()
+	t.mu.Lock()
+	operations := make([]Operation, 0, len(t.operations))
+	for _, op := range t.operations {
+		o := op.Operation
+		o.Duration = now.Sub(o.StartTime).Seconds()
+		o.Step = op.step.Get()
+		operations = append(operations, o)
+	}
+	t.mu.Unlock()
+	slices.SortFunc(operations, func(a, b Operation) int { return a.StartTime.Compare(b.StartTime) })
+	return operations
+}
+
+// ServeHTTP serves the operations in flight.
+func (t *operationTracker) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(t.list()); err != nil {
+		klog.ErrorS(err, "Could not write in-flight operations")
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestOperationTracker(t *testing.T) {
+	tracker := newOperationTracker()
+	ctx := util.WithCorrelationIDs(t.Context(), "corr-1")
+
+	var inFlight []Operation
+	handler := func(ctx context.Context, _ any) (any, error) {
+		defer util.SetOperationStep(ctx, "EC2 AttachVolume")()
+		inFlight = tracker.list()
+		return nil, nil
+	}
+	req := &csi.ControllerPublishVolumeRequest{VolumeId: "vol-test", NodeId: "i-test"}
+	_, err := tracker.interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: csi.Controller_ControllerPublishVolume_FullMethodName}, handler)
+	require.NoError(t, err)
+
+	require.Len(t, inFlight, 1)
+	assert.Equal(t, "ControllerPublishVolume", inFlight[0].RPC)
+	assert.Equal(t, "vol-test", inFlight[0].VolumeID)
+	assert.Equal(t, "i-test", inFlight[0].NodeID)
+	assert.Equal(t, []string{"corr-1"}, inFlight[0].CorrelationIDs)
+	assert.Equal(t, "EC2 AttachVolume", inFlight[0].Step)
+	assert.Empty(t, tracker.list())
+
+	// Node and identity RPCs are not tracked
+	inFlight = nil
+	_, err = tracker.interceptor(ctx, &csi.NodeStageVolumeRequest{}, &grpc.UnaryServerInfo{FullMethod: csi.Node_NodeStageVolume_FullMethodName}, handler)
+	require.NoError(t, err)
+	assert.Empty(t, inFlight)
+}
+
+func TestOperationTrackerServeHTTP(t *testing.T) {
+	tracker := newOperationTracker()
+	handler := func(context.Context, any) (any, error) {
+		rec := httptest.NewRecorder()
+		tracker.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, OperationsPath, nil))
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var operations []Operation
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &operations))
+		require.Len(t, operations, 1)
+		assert.Equal(t, "CreateSnapshot", operations[0].RPC)
+		assert.Equal(t, "snap-name", operations[0].Name)
+		assert.Equal(t, "vol-source", operations[0].VolumeID)
+		return nil, nil
+	}
+	req := &csi.CreateSnapshotRequest{Name: "snap-name", SourceVolumeId: "vol-source"}
+	_, err := tracker.interceptor(t.Context(), req, &grpc.UnaryServerInfo{FullMethod: csi.Controller_CreateSnapshot_FullMethodName}, handler)
+	require.NoError(t, err)
+}
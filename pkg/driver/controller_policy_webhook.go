@@ -0,0 +1,220 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// Failure policies of the policy webhook, named after those of Kubernetes admission webhooks.
+const (
+	PolicyWebhookFailurePolicyFail   = "Fail"
+	PolicyWebhookFailurePolicyIgnore = "Ignore"
+)
+
+const (
+	createVolumeReviewAPIVersion = "ebs.csi.aws.com/v1alpha1"
+	createVolumeReviewKind       = "CreateVolumeReview"
+	// maxCreateVolumeReviewSize bounds the responses read from the policy webhook.
+	maxCreateVolumeReviewSize = 1 << 20
+)
+
+// Results of policy webhook reviews, reported by the PolicyWebhookReviews metric.
+const (
+	policyWebhookAllowed = "allowed"
+	policyWebhookMutated = "mutated"
+	policyWebhookDenied  = "denied"
+	policyWebhookError   = "error"
+)
+
+// CreateVolumeReview is the body of the requests sent to the policy webhook and of its responses.
+type CreateVolumeReview struct {
+	APIVersion string                      `json:"apiVersion"`
+	Kind       string                      `json:"kind"`
+	Request    *CreateVolumeReviewRequest  `json:"request,omitempty"`
+	Response   *CreateVolumeReviewResponse `json:"response,omitempty"`
+}
+
+// CreateVolumeReviewRequest describes a CreateVolume request before EC2 is called.
+type CreateVolumeReviewRequest struct {
+	// Name is the name of the volume, the name of its PV.
+	Name          string `json:"name"`
+	CapacityBytes int64  `json:"capacityBytes"`
+	// Parameters are the StorageClass parameters of the volume, including --default-volume-parameters.
+	Parameters        map[string]string `json:"parameters,omitempty"`
+	MutableParameters map[string]string `json:"mutableParameters,omitempty"`
+	// The PVC fields are only set when the external-provisioner runs with --extra-create-metadata.
+	PVCNamespace   string            `json:"pvcNamespace,omitempty"`
+	PVCName        string            `json:"pvcName,omitempty"`
+	PVCLabels      map[string]string `json:"pvcLabels,omitempty"`
+	PVCAnnotations map[string]string `json:"pvcAnnotations,omitempty"`
+}
+
+// CreateVolumeReviewResponse is the decision of the policy webhook.
+type CreateVolumeReviewResponse struct {
+	Allowed bool `json:"allowed"`
+	// Reason is reported in the error of denied requests.
+	Reason string `json:"reason,omitempty"`
+	// Parameters, when set, replace the parameters of allowed requests. They are validated like StorageClass
+	// parameters.
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// policyWebhookCAFile is a flag.Value that loads the PEM certificates of a file into a pool when the flag is set.
+type policyWebhookCAFile struct {
+	path string
+	pool **x509.CertPool
+}
+
+func (f *policyWebhookCAFile) String() string { return f.path }
+
+func (f *policyWebhookCAFile) Type() string { return "string" }
+
+func (f *policyWebhookCAFile) Set(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read policy webhook CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("no PEM certificate found in policy webhook CA file %s", path)
+	}
+	f.path = path
+	*f.pool = pool
+	return nil
+}
+
+// policyWebhook sends CreateVolume requests to an operator-provided HTTP endpoint that allows, denies or mutates them
+// before the controller calls EC2.
+type policyWebhook struct {
+	url        string
+	client     *http.Client
+	failClosed bool
+}
+
+// newPolicyWebhook returns nil unless --policy-webhook-url is set.
+func newPolicyWebhook(o *Options) *policyWebhook {
+	if o.PolicyWebhookURL == "" {
+		return nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: o.PolicyWebhookCAs, MinVersion: tls.VersionTLS12}
+	return &policyWebhook{
+		url:        o.PolicyWebhookURL,
+		client:     &http.Client{Transport: transport, Timeout: o.PolicyWebhookTimeout},
+		failClosed: o.PolicyWebhookFailurePolicy != PolicyWebhookFailurePolicyIgnore,
+	}
+}
+
+// review returns the parameters to create a volume with, as mutated by the policy webhook. Requests the webhook
+// denies fail with PermissionDenied. Requests the webhook cannot review fail with Unavailable, unless the failure
+// policy is Ignore and they are created with their own parameters.
+func (w *policyWebhook) review(ctx context.Context, req *csi.CreateVolumeRequest, parameters map[string]string, pvcMetadata *pvcMetadataReader) (map[string]string, error) {
+	if w == nil {
+		return parameters, nil
+	}
+	response, err := w.send(ctx, req, parameters, pvcMetadata)
+	if err != nil {
+		countPolicyWebhookReview(policyWebhookError)
+		if !w.failClosed {
+			klog.ErrorS(err, "CreateVolume: policy webhook failed, creating the volume without review", "volumeName", req.GetName())
+			return parameters, nil
+		}
+		return nil, status.Errorf(codes.Unavailable, "Could not review volume %q with the policy webhook: %v", req.GetName(), err)
+	}
+	if !response.Allowed {
+		countPolicyWebhookReview(policyWebhookDenied)
+		klog.InfoS("CreateVolume: denied by the policy webhook", "volumeName", req.GetName(), "reason", response.Reason)
+		return nil, status.Errorf(codes.PermissionDenied, "Volume %q denied by the policy webhook: %s", req.GetName(), response.Reason)
+	}
+	if response.Parameters == nil || maps.Equal(response.Parameters, parameters) {
+		countPolicyWebhookReview(policyWebhookAllowed)
+		return parameters, nil
+	}
+	countPolicyWebhookReview(policyWebhookMutated)
+	klog.InfoS("CreateVolume: parameters mutated by the policy webhook", "volumeName", req.GetName(), "parameters", response.Parameters)
+	return response.Parameters, nil
+}
+
+func (w *policyWebhook) send(ctx context.Context, req *csi.CreateVolumeRequest, parameters map[string]string, pvcMetadata *pvcMetadataReader) (*CreateVolumeReviewResponse, error) {
+	props := &template.PVProps{PVCNamespace: parameters[PVCNamespaceKey], PVCName: parameters[PVCNameKey]}
+	if props.PVCNamespace != "" && props.PVCName != "" {
+		// Without a Kubernetes client, the webhook is only sent the PVC name
+		if err := pvcMetadata.load(ctx, props); err != nil && !errors.Is(err, errNoPVCMetadataClient) {
+			return nil, err
+		}
+	}
+	body, err := json.Marshal(&CreateVolumeReview{
+		APIVersion: createVolumeReviewAPIVersion,
+		Kind:       createVolumeReviewKind,
+		Request: &CreateVolumeReviewRequest{
+			Name:              req.GetName(),
+			CapacityBytes:     req.GetCapacityRange().GetRequiredBytes(),
+			Parameters:        parameters,
+			MutableParameters: req.GetMutableParameters(),
+			PVCNamespace:      props.PVCNamespace,
+			PVCName:           props.PVCName,
+			PVCLabels:         props.PVCLabels,
+			PVCAnnotations:    props.PVCAnnotations,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := w.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("policy webhook returned HTTP status %d", httpResp.StatusCode)
+	}
+	review := &CreateVolumeReview{}
+	if err := json.NewDecoder(io.LimitReader(httpResp.Body, maxCreateVolumeReviewSize)).Decode(review); err != nil {
+		return nil, fmt.Errorf("could not decode policy webhook response: %w", err)
+	}
+	if review.Response == nil {
+		return nil, errors.New("policy webhook response has no response field")
+	}
+	return review.Response, nil
+}
+
+func countPolicyWebhookReview(result string) {
+	metrics.Recorder().IncreaseCount(metrics.PolicyWebhookReviews, metrics.PolicyWebhookReviewsHelpText, map[string]string{"result": result})
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver/internal"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCreateVolumePolicyWebhook(t *testing.T) {
+	testCases := []struct {
+		name          string
+		failurePolicy string
+		handler       func(t *testing.T, w http.ResponseWriter, review *CreateVolumeReview)
+		expVolumeType string
+		expCode       codes.Code
+	}{
+		{
+			name: "allowed",
+			handler: func(t *testing.T, w http.ResponseWriter, review *CreateVolumeReview) {
+				t.Helper()
+				assert.Equal(t, "ebs.csi.aws.com/v1alpha1", review.APIVersion)
+				assert.Equal(t, "CreateVolumeReview", review.Kind)
+				assert.Equal(t, "vol-test", review.Request.Name)
+				assert.Equal(t, util.GiB, review.Request.CapacityBytes)
+				assert.Equal(t, "gp3", review.Request.Parameters[VolumeTypeKey])
+				assert.Equal(t, "data", review.Request.PVCName)
+				assert.Equal(t, map[string]string{"team": "storage"}, review.Request.PVCLabels)
+				assert.Equal(t, map[string]string{"example.com/cost-center": "1234"}, review.Request.PVCAnnotations)
+				writeReview(t, w, &CreateVolumeReviewResponse{Allowed: true})
+			},
+			expVolumeType: "gp3",
+		},
+		{
+			name: "mutated",
+			handler: func(t *testing.T, w http.ResponseWriter, review *CreateVolumeReview) {
+				t.Helper()
+				parameters := review.Request.Parameters
+				parameters[VolumeTypeKey] = "io2"
+				parameters[IopsKey] = "4000"
+				writeReview(t, w, &CreateVolumeReviewResponse{Allowed: true, Parameters: parameters})
+			},
+			expVolumeType: "io2",
+		},
+		{
+			name: "invalid mutation",
+			handler: func(t *testing.T, w http.ResponseWriter, review *CreateVolumeReview) {
+				t.Helper()
+				writeReview(t, w, &CreateVolumeReviewResponse{Allowed: true, Parameters: map[string]string{"unknown": "true"}})
+			},
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name: "denied",
+			handler: func(t *testing.T, w http.ResponseWriter, _ *CreateVolumeReview) {
+				t.Helper()
+				writeReview(t, w, &CreateVolumeReviewResponse{Reason: "gp3 volumes must be encrypted"})
+			},
+			expCode: codes.PermissionDenied,
+		},
+		{
+			name: "failure fails closed",
+			handler: func(t *testing.T, w http.ResponseWriter, _ *CreateVolumeReview) {
+				t.Helper()
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			expCode: codes.Unavailable,
+		},
+		{
+			name:          "failure ignored",
+			failurePolicy: PolicyWebhookFailurePolicyIgnore,
+			handler: func(t *testing.T, w http.ResponseWriter, _ *CreateVolumeReview) {
+				t.Helper()
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			expVolumeType: "gp3",
+		},
+		{
+			name: "timeout fails closed",
+			handler: func(t *testing.T, w http.ResponseWriter, _ *CreateVolumeReview) {
+				t.Helper()
+				time.Sleep(500 * time.Millisecond)
+				writeReview(t, w, &CreateVolumeReviewResponse{Allowed: true})
+			},
+			expCode: codes.Unavailable,
+		},
+		{
+			name: "no response",
+			handler: func(t *testing.T, w http.ResponseWriter, _ *CreateVolumeReview) {
+				t.Helper()
+				writeReview(t, w, nil)
+			},
+			expCode: codes.Unavailable,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				review := &CreateVolumeReview{}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(review))
+				tc.handler(t, w, review)
+			}))
+			defer server.Close()
+
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			if tc.expVolumeType != "" {
+				mockCloud.EXPECT().CreateDisk(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+					func(_ context.Context, volumeName string, opts *cloud.DiskOptions) (*cloud.Disk, error) {
+						assert.Equal(t, tc.expVolumeType, opts.VolumeType)
+						return &cloud.Disk{VolumeID: volumeName, AvailabilityZone: expZone, CapacityGiB: 1}, nil
+					})
+			}
+			o := &Options{
+				PolicyWebhookURL:           server.URL,
+				PolicyWebhookTimeout:       100 * time.Millisecond,
+				PolicyWebhookFailurePolicy: tc.failurePolicy,
+				StrictParameters:           true,
+			}
+			d := &ControllerService{
+				cloud:         mockCloud,
+				inFlight:      internal.NewInFlight(),
+				options:       o,
+				pvcMetadata:   newPVCMetadataReader(fake.NewClientset(newLabeledPVC()), o),
+				policyWebhook: newPolicyWebhook(o),
+			}
+			_, err := d.CreateVolume(t.Context(), &csi.CreateVolumeRequest{
+				Name:          "vol-test",
+				CapacityRange: &csi.CapacityRange{RequiredBytes: util.GiB},
+				VolumeCapabilities: []*csi.VolumeCapability{{
+					AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+					AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+				}},
+				Parameters: map[string]string{
+					VolumeTypeKey:   "gp3",
+					PVCNamespaceKey: "default",
+					PVCNameKey:      "data",
+				},
+			})
+			assert.Equal(t, tc.expCode, status.Code(err), err)
+		})
+	}
+}
+
+func writeReview(t *testing.T, w http.ResponseWriter, response *CreateVolumeReviewResponse) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	assert.NoError(t, json.NewEncoder(w).Encode(&CreateVolumeReview{APIVersion: createVolumeReviewAPIVersion, Kind: createVolumeReviewKind, Response: response}))
+}
+
+func TestPolicyWebhookCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeReview(t, w, &CreateVolumeReviewResponse{Allowed: true})
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	req := &csi.CreateVolumeRequest{Name: "vol-test"}
+	o := &Options{PolicyWebhookURL: server.URL, PolicyWebhookTimeout: time.Second}
+	_, err := newPolicyWebhook(o).review(t.Context(), req, map[string]string{}, nil)
+	assert.Equal(t, codes.Unavailable, status.Code(err), "the certificate of the server is not trusted without the CA file")
+
+	var pool *x509.CertPool
+	f := &policyWebhookCAFile{pool: &pool}
+	require.NoError(t, f.Set(path))
+	o.PolicyWebhookCAs = pool
+	_, err = newPolicyWebhook(o).review(t.Context(), req, map[string]string{}, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("not a certificate"), 0o600))
+	require.Error(t, f.Set(path))
+}
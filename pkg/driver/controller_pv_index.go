@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// pvVolumeHandleIndex indexes the PVs of the driver by CSI volume handle.
+const pvVolumeHandleIndex = "volumeHandle"
+
+var errPVIndexNotSynced = errors.New("PV cache not synced")
+
+// pvIndex finds the PV of a volume in a watch of the PVs of the cluster indexed by volume handle, rather than
+// listing every PV for each lookup. The watch is only started by the first lookup, so controllers that never look up
+// PVs do not watch them.
+type pvIndex struct {
+	client   kubernetes.Interface
+	once     sync.Once
+	informer cache.SharedIndexInformer
+}
+
+func newPVIndex(k kubernetes.Interface) *pvIndex {
+	if k == nil {
+		return nil
+	}
+	return &pvIndex{client: k}
+}
+
+func (i *pvIndex) start() {
+	factory := informers.NewSharedInformerFactory(i.client, 0)
+	i.informer = factory.Core().V1().PersistentVolumes().Informer()
+	// Only keep what the lookups read, the cache holds every PV of the cluster
+	if err := i.informer.SetTransform(stripPV); err != nil {
+		klog.ErrorS(err, "Could not strip the PVs of the PV cache")
+	}
+	if err := i.informer.AddIndexers(cache.Indexers{pvVolumeHandleIndex: pvVolumeHandle}); err != nil {
+		klog.ErrorS(err, "Could not index the PV cache by volume handle")
+	}
+	factory.Start(context.Background().Done())
+}
+
+// pvVolumeHandle indexes the PVs of the driver by volume handle.
+func pvVolumeHandle(obj any) ([]string, error) {
+	pv, ok := obj.(*corev1.PersistentVolume)
+	if !ok || pv.Spec.CSI == nil || pv.Spec.CSI.Driver != util.GetDriverName() {
+		return nil, nil
+	}
+	return []string{pv.Spec.CSI.VolumeHandle}, nil
+}
+
+// stripPV drops everything but the metadata, claim and CSI source of a PV.
+func stripPV(obj any) (any, error) {
+	pv, ok := obj.(*corev1.PersistentVolume)
+	if !ok {
+		return obj, nil
+	}
+	return &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{
+		Name:            pv.Name,
+		UID:             pv.UID,
+		ResourceVersion: pv.ResourceVersion,
+		Annotations:     pv.Annotations,
+	}, Spec: corev1.PersistentVolumeSpec{
+		ClaimRef:               pv.Spec.ClaimRef,
+		PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: pv.Spec.CSI},
+	}}, nil
+}
+
+// get returns the PV of the volume, or nil if there is none. The first lookup waits for the PVs to be listed.
+func (i *pvIndex) get(ctx context.Context, volumeID string) (*corev1.PersistentVolume, error) {
+	i.once.Do(i.start)
+	if !cache.WaitForCacheSync(ctx.Done(), i.informer.HasSynced) {
+		return nil, errPVIndexNotSynced
+	}
+	objs, err := i.informer.GetIndexer().ByIndex(pvVolumeHandleIndex, volumeID)
+	if err != nil || len(objs) == 0 {
+		return nil, err
+	}
+	pv, ok := objs[0].(*corev1.PersistentVolume)
+	if !ok {
+		return nil, nil
+	}
+	return pv, nil
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+)
+
+var (
+	errNoPVCMetadataClient = errors.New("no Kubernetes client available to read PVC labels and annotations")
+	errNoPVC               = errors.New("PVC name and namespace unknown, is --extra-create-metadata enabled on the provisioner?")
+)
+
+// Results of the lookups of PVCs in the PVC metadata cache.
+const (
+	pvcCacheHit   = "hit"
+	pvcCacheMiss  = "miss"
+	pvcCacheStale = "stale"
+)
+
+// pvcMetadataReader reads the labels and annotations of the PVC of a volume for the tag templates that reference
+// them, so that volumes can be tagged from PVC metadata such as a team or cost center label.
+type pvcMetadataReader struct {
+	client kubernetes.Interface
+	// cache is nil without --pvc-metadata-cache-max-staleness, in which case every PVC is read from the API server.
+	cache *pvcMetadataCache
+}
+
+func newPVCMetadataReader(k kubernetes.Interface, o *Options) *pvcMetadataReader {
+	if k == nil {
+		return nil
+	}
+	r := &pvcMetadataReader{client: k}
+	if o.PVCMetadataCacheMaxStaleness > 0 {
+		r.cache = newPVCMetadataCache(k, o.PVCMetadataCacheMaxStaleness)
+	}
+	return r
+}
+
+// referencesPVCMetadata returns whether any of the templates references the labels or annotations of the PVC.
+func referencesPVCMetadata(templates ...string) bool {
+	for _, t := range templates {
+		if strings.Contains(t, ".PVCLabels") || strings.Contains(t, ".PVCAnnotations") {
+			return true
+		}
+	}
+	return false
+}
+
+// load sets the labels and annotations of the PVC of props.
+func (r *pvcMetadataReader) load(ctx context.Context, props *template.PVProps) error {
+	if r == nil {
+		return errNoPVCMetadataClient
+	}
+	if props.PVCNamespace == "" || props.PVCName == "" {
+		return errNoPVC
+	}
+	if pvc := r.cache.get(props.PVCNamespace, props.PVCName); pvc != nil {
+		props.PVCLabels = pvc.GetLabels()
+		props.PVCAnnotations = pvc.GetAnnotations()
+		return nil
+	}
+	pvc, err := r.client.CoreV1().PersistentVolumeClaims(props.PVCNamespace).Get(ctx, props.PVCName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get PVC %s/%s: %w", props.PVCNamespace, props.PVCName, err)
+	}
+	props.PVCLabels = pvc.GetLabels()
+	props.PVCAnnotations = pvc.GetAnnotations()
+	return nil
+}
+
+// storageClass returns the name of the StorageClass of a PVC, or "" if it cannot be read.
+func (r *pvcMetadataReader) storageClass(ctx context.Context, namespace, name string) string {
+	if r == nil || namespace == "" || name == "" {
+		return ""
+	}
+	pvc := r.cache.get(namespace, name)
+	if pvc == nil {
+		var err error
+		if pvc, err = r.client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+			klog.ErrorS(err, "Could not get PVC to read its StorageClass", "namespace", namespace, "name", name)
+			return ""
+		}
+	}
+	if pvc.Spec.StorageClassName == nil {
+		return ""
+	}
+	return *pvc.Spec.StorageClassName
+}
+
+// pvcMetadataCache keeps the labels and annotations of every PVC of the cluster from a watch, so that provisioning
+// bursts do not get each PVC from the API server. The cache is only trusted while its watch progresses: the resource
+// version it last observed, which watch bookmarks advance even when no PVC changes, must have changed within
+// maxStaleness. PVCs created after the last observed event are missing from it and read from the API server instead.
+type pvcMetadataCache struct {
+	lister              corelisters.PersistentVolumeClaimLister
+	hasSynced           func() bool
+	lastResourceVersion func() string
+	maxStaleness        time.Duration
+	now                 func() time.Time
+
+	mu              sync.Mutex
+	resourceVersion string
+	progressedAt    time.Time
+}
+
+func newPVCMetadataCache(k kubernetes.Interface, maxStaleness time.Duration) *pvcMetadataCache {
+	factory := informers.NewSharedInformerFactory(k, 0)
+	informer := factory.Core().V1().PersistentVolumeClaims()
+	// Only keep the metadata the tag templates read, the cache holds every PVC of the cluster
+	if err := informer.Informer().SetTransform(stripPVC); err != nil {
+		klog.ErrorS(err, "Could not strip the PVCs of the PVC metadata cache")
+	}
+	c := &pvcMetadataCache{
+		lister:              informer.Lister(),
+		hasSynced:           informer.Informer().HasSynced,
+		lastResourceVersion: informer.Informer().LastSyncResourceVersion,
+		maxStaleness:        maxStaleness,
+		now:                 time.Now,
+	}
+	factory.Start(context.Background().Done())
+	go func() {
+		// Check often enough that the progress of the watch is known within a tenth of the tolerated staleness
+		for range time.Tick(max(maxStaleness/10, time.Second)) {
+			c.observe()
+		}
+	}()
+	return c
+}
+
+// stripPVC drops everything but the name, namespace, labels, annotations and StorageClass of a PVC.
+func stripPVC(obj any) (any, error) {
+	pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		return obj, nil
+	}
+	return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Namespace:       pvc.Namespace,
+		Name:            pvc.Name,
+		UID:             pvc.UID,
+		ResourceVersion: pvc.ResourceVersion,
+		Labels:          pvc.Labels,
+		Annotations:     pvc.Annotations,
+	}, Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: pvc.Spec.StorageClassName}}, nil
+}
+
+// observe records when the resource version observed by the watch last changed.
+func (c *pvcMetadataCache) observe() {
+	resourceVersion := c.lastResourceVersion()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if resourceVersion != c.resourceVersion {
+		c.resourceVersion = resourceVersion
+		c.progressedAt = c.now()
+	}
+}
+
+// fresh returns whether the cache is synced and its watch progressed within maxStaleness.
+func (c *pvcMetadataCache) fresh() bool {
+	if !c.hasSynced() {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.progressedAt.IsZero() && c.now().Sub(c.progressedAt) <= c.maxStaleness
+}
+
+// get returns the cached PVC, or nil if it must be read from the API server because the cache is stale or does not
+// have it yet.
+func (c *pvcMetadataCache) get(namespace, name string) *corev1.PersistentVolumeClaim {
+	if c == nil {
+		return nil
+	}
+	if !c.fresh() {
+		c.count(pvcCacheStale)
+		return nil
+	}
+	pvc, err := c.lister.PersistentVolumeClaims(namespace).Get(name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Could not get PVC from the PVC metadata cache", "namespace", namespace, "name", name)
+		}
+		c.count(pvcCacheMiss)
+		return nil
+	}
+	c.count(pvcCacheHit)
+	return pvc
+}
+
+func (c *pvcMetadataCache) count(result string) {
+	metrics.Recorder().IncreaseCount(metrics.PVCMetadataCacheRequests, metrics.PVCMetadataCacheRequestsHelpText, map[string]string{"result": result})
+}
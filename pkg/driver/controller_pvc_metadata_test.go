@@ -0,0 +1,161 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver/internal"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newLabeledPVC() *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "default",
+		Name:        "data",
+		Labels:      map[string]string{"team": "storage"},
+		Annotations: map[string]string{"example.com/cost-center": "1234"},
+	}}
+}
+
+func TestPVCMetadataReader(t *testing.T) {
+	assert.True(t, referencesPVCMetadata("key1={{ .PVCName }}", "team={{ .PVCLabels.team }}"))
+	assert.True(t, referencesPVCMetadata(`cost={{ get "example.com/cost-center" .PVCAnnotations }}`))
+	assert.False(t, referencesPVCMetadata("key1={{ .PVCName }}", ""))
+
+	var nilReader *pvcMetadataReader
+	assert.Nil(t, newPVCMetadataReader(nil, &Options{}))
+	require.ErrorIs(t, nilReader.load(t.Context(), &template.PVProps{PVCNamespace: "default", PVCName: "data"}), errNoPVCMetadataClient)
+
+	r := newPVCMetadataReader(fake.NewClientset(newLabeledPVC()), &Options{})
+	require.ErrorIs(t, r.load(t.Context(), &template.PVProps{}), errNoPVC)
+	require.Error(t, r.load(t.Context(), &template.PVProps{PVCNamespace: "default", PVCName: "missing"}))
+
+	props := &template.PVProps{PVCNamespace: "default", PVCName: "data"}
+	require.NoError(t, r.load(t.Context(), props))
+	assert.Equal(t, map[string]string{"team": "storage"}, props.PVCLabels)
+	assert.Equal(t, map[string]string{"example.com/cost-center": "1234"}, props.PVCAnnotations)
+}
+
+func TestPVCMetadataCache(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	stripped, err := stripPVC(newLabeledPVC())
+	require.NoError(t, err)
+	require.NoError(t, indexer.Add(stripped))
+
+	now := time.Now()
+	synced := false
+	resourceVersion := "1"
+	c := &pvcMetadataCache{
+		lister:              corelisters.NewPersistentVolumeClaimLister(indexer),
+		hasSynced:           func() bool { return synced },
+		lastResourceVersion: func() string { return resourceVersion },
+		maxStaleness:        time.Minute,
+		now:                 func() time.Time { return now },
+	}
+	// The PVC is labeled differently in the API server, to tell where it was read from
+	apiPVC := newLabeledPVC()
+	apiPVC.Labels["team"] = "api"
+	r := &pvcMetadataReader{client: fake.NewClientset(apiPVC), cache: c}
+	loadTeam := func() string {
+		t.Helper()
+		props := &template.PVProps{PVCNamespace: "default", PVCName: "data"}
+		require.NoError(t, r.load(t.Context(), props))
+		return props.PVCLabels["team"]
+	}
+
+	assert.Equal(t, "api", loadTeam(), "not synced yet")
+
+	synced = true
+	assert.Equal(t, "api", loadTeam(), "watch progress not observed yet")
+
+	c.observe()
+	assert.Equal(t, "storage", loadTeam())
+	assert.Equal(t, map[string]string{"example.com/cost-center": "1234"}, stripped.(*corev1.PersistentVolumeClaim).Annotations)
+
+	now = now.Add(2 * time.Minute)
+	c.observe()
+	assert.Equal(t, "api", loadTeam(), "watch did not progress for longer than maxStaleness")
+
+	resourceVersion = "2"
+	c.observe()
+	assert.Equal(t, "storage", loadTeam())
+
+	require.NoError(t, indexer.Delete(stripped))
+	assert.Equal(t, "api", loadTeam(), "PVC not in the cache yet")
+}
+
+func TestCreateVolumePVCMetadataTags(t *testing.T) {
+	volCap := []*csi.VolumeCapability{
+		{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		},
+	}
+	req := func(pvcName string) *csi.CreateVolumeRequest {
+		return &csi.CreateVolumeRequest{
+			Name:               "vol-test",
+			CapacityRange:      &csi.CapacityRange{RequiredBytes: util.GiB},
+			VolumeCapabilities: volCap,
+			Parameters: map[string]string{
+				PVCNamespaceKey:     "default",
+				PVCNameKey:          pvcName,
+				TagKeyPrefix + "_1": "team={{ .PVCLabels.team }}",
+				TagKeyPrefix + "_2": `cost-center={{ get "example.com/cost-center" .PVCAnnotations }}`,
+				TagKeyPrefix + "_3": "owner={{ .PVCLabels.owner }}",
+				TagKeyPrefix + "_4": "pvc={{ .PVCName }}",
+			},
+		}
+	}
+
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	mockCloud.EXPECT().CreateDisk(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+		func(_ context.Context, volumeName string, opts *cloud.DiskOptions) (*cloud.Disk, error) {
+			assert.Equal(t, "storage", opts.Tags["team"])
+			assert.Equal(t, "1234", opts.Tags["cost-center"])
+			assert.Empty(t, opts.Tags["owner"], "labels missing from the PVC must be empty")
+			assert.Equal(t, "data", opts.Tags["pvc"])
+			return &cloud.Disk{VolumeID: volumeName, AvailabilityZone: expZone, CapacityGiB: 1}, nil
+		})
+	d := &ControllerService{
+		cloud:       mockCloud,
+		inFlight:    internal.NewInFlight(),
+		options:     &Options{},
+		pvcMetadata: newPVCMetadataReader(fake.NewClientset(newLabeledPVC()), &Options{}),
+	}
+	_, err := d.CreateVolume(t.Context(), req("data"))
+	require.NoError(t, err)
+
+	_, err = d.CreateVolume(t.Context(), req("missing"))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
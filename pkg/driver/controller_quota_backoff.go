@@ -0,0 +1,113 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"k8s.io/klog/v2"
+)
+
+// maxQuotaBackoffFactor bounds how much the quota backoff of a volume type grows with consecutive failures.
+const maxQuotaBackoffFactor = 16
+
+// quotaBackoffEntry is the backoff of the volumes of a type after their quota was exceeded.
+type quotaBackoffEntry struct {
+	err   *cloud.QuotaExceededError
+	delay time.Duration
+	until time.Time
+}
+
+// quotaBackoff stops CreateVolume from calling EC2 for the volumes of a type for a while after EC2 reported that the
+// account exceeded their storage or IOPS quota, since every such call would fail the same way while consuming EC2
+// request tokens. The delay starts at --quota-exceeded-backoff and doubles with each consecutive quota failure of
+// the volume type, up to maxQuotaBackoffFactor times. A volume of the type created successfully resets it.
+type quotaBackoff struct {
+	mu      sync.Mutex
+	initial time.Duration
+	entries map[string]*quotaBackoffEntry
+	now     func() time.Time
+}
+
+// newQuotaBackoff returns nil unless --quota-exceeded-backoff is set.
+func newQuotaBackoff(o *Options) *quotaBackoff {
+	if o.QuotaExceededBackoff <= 0 {
+		return nil
+	}
+	return &quotaBackoff{
+		initial: o.QuotaExceededBackoff,
+		entries: map[string]*quotaBackoffEntry{},
+		now:     time.Now,
+	}
+}
+
+// check returns a ResourceExhausted error with a RetryInfo detail if the volumes of volumeType, the volume type
+// parameter of a StorageClass, are backing off.
+func (b *quotaBackoff) check(volumeType string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry, ok := b.entries[volumeType]
+	if !ok {
+		return nil
+	}
+	retryAfter := entry.until.Sub(b.now())
+	if retryAfter <= 0 {
+		return nil
+	}
+	st := status.Newf(codes.ResourceExhausted, "EC2 quota %q exceeded, not creating volumes of this type for %s", entry.err.QuotaName(), retryAfter.Round(time.Second))
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// record starts or extends the backoff of volumeType if err is caused by an exceeded storage or IOPS quota, or resets
+// it if err is nil.
+func (b *quotaBackoff) record(volumeType string, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		delete(b.entries, volumeType)
+		return
+	}
+	var quotaErr *cloud.QuotaExceededError
+	if !errors.As(err, &quotaErr) || (quotaErr.Quota != cloud.QuotaStorage && quotaErr.Quota != cloud.QuotaIOPS) {
+		return
+	}
+	entry, ok := b.entries[volumeType]
+	if !ok {
+		entry = &quotaBackoffEntry{}
+		b.entries[volumeType] = entry
+	}
+	entry.err = quotaErr
+	entry.delay = min(max(2*entry.delay, b.initial), maxQuotaBackoffFactor*b.initial)
+	entry.until = b.now().Add(entry.delay)
+	klog.InfoS("CreateVolume: EC2 quota exceeded, backing off", "quota", quotaErr.QuotaName(), "volumeType", volumeType, "delay", entry.delay)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver/internal"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newStorageQuotaError(volumeType string) error {
+	return &cloud.QuotaExceededError{Quota: cloud.QuotaStorage, VolumeType: volumeType, Err: &smithy.GenericAPIError{Code: "VolumeLimitExceeded"}}
+}
+
+func TestQuotaBackoff(t *testing.T) {
+	assert.Nil(t, newQuotaBackoff(&Options{}))
+	var nilBackoff *quotaBackoff
+	require.NoError(t, nilBackoff.check(cloud.VolumeTypeGP3))
+
+	now := time.Now()
+	b := newQuotaBackoff(&Options{QuotaExceededBackoff: time.Minute})
+	b.now = func() time.Time { return now }
+
+	b.record(cloud.VolumeTypeGP3, errors.New("not a quota error"))
+	b.record(cloud.VolumeTypeGP3, &cloud.QuotaExceededError{Quota: cloud.QuotaSnapshots, Err: errors.New("snapshot limit")})
+	require.NoError(t, b.check(cloud.VolumeTypeGP3), "only storage and IOPS quotas back off")
+
+	b.record(cloud.VolumeTypeGP3, newStorageQuotaError(cloud.VolumeTypeGP3))
+	err := b.check(cloud.VolumeTypeGP3)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "Storage for General Purpose SSD (gp3) volumes, in TiB")
+	require.Len(t, status.Convert(err).Details(), 1)
+	assert.Equal(t, time.Minute, status.Convert(err).Details()[0].(*errdetails.RetryInfo).GetRetryDelay().AsDuration())
+	require.NoError(t, b.check(cloud.VolumeTypeIO2), "other volume types do not back off")
+
+	// Consecutive failures double the delay, up to maxQuotaBackoffFactor times
+	expDelays := []time.Duration{2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 16 * time.Minute, 16 * time.Minute}
+	for _, expDelay := range expDelays {
+		now = now.Add(expDelay)
+		require.NoError(t, b.check(cloud.VolumeTypeGP3))
+		b.record(cloud.VolumeTypeGP3, newStorageQuotaError(cloud.VolumeTypeGP3))
+		assert.Equal(t, expDelay, b.entries[cloud.VolumeTypeGP3].delay)
+	}
+
+	b.record(cloud.VolumeTypeGP3, nil)
+	require.NoError(t, b.check(cloud.VolumeTypeGP3))
+	b.record(cloud.VolumeTypeGP3, newStorageQuotaError(cloud.VolumeTypeGP3))
+	assert.Equal(t, time.Minute, b.entries[cloud.VolumeTypeGP3].delay, "a created volume resets the delay")
+}
+
+func TestCreateVolumeQuotaBackoff(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	mockCloud.EXPECT().CreateDisk(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+		func(context.Context, string, *cloud.DiskOptions) (*cloud.Disk, error) {
+			return nil, newStorageQuotaError(cloud.VolumeTypeGP3)
+		})
+	o := &Options{QuotaExceededBackoff: time.Minute}
+	d := &ControllerService{
+		cloud:        mockCloud,
+		inFlight:     internal.NewInFlight(),
+		options:      o,
+		quotaBackoff: newQuotaBackoff(o),
+	}
+	req := &csi.CreateVolumeRequest{
+		Name:          "vol-test",
+		CapacityRange: &csi.CapacityRange{RequiredBytes: util.GiB},
+		VolumeCapabilities: []*csi.VolumeCapability{{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		}},
+		Parameters: map[string]string{VolumeTypeKey: cloud.VolumeTypeGP3},
+	}
+	_, err := d.CreateVolume(t.Context(), req)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), `EC2 quota "Storage for General Purpose SSD (gp3) volumes, in TiB" exceeded`)
+
+	// The second request fails without calling EC2
+	_, err = d.CreateVolume(t.Context(), req)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// volumeInitializingReason is the reason of the events emitted on the PVC of a volume restored from a snapshot
+	// while its blocks are downloaded from the snapshot.
+	volumeInitializingReason = "VolumeInitializing"
+	// volumeInitializedReason is the reason of the event emitted once such a volume is initialized.
+	volumeInitializedReason = "VolumeInitialized"
+)
+
+// restoredPVC is the PVC of a volume restored from a snapshot.
+type restoredPVC struct {
+	namespace string
+	name      string
+	// initializing is true once the volume was seen initializing
+	initializing bool
+}
+
+// restoreProgressTracker polls the initialization of the volumes restored from a snapshot by this replica and reports
+// it with events on their PVC and a gauge, because reads of blocks not yet downloaded from the snapshot are slower.
+// Volumes restored from a snapshot with fast snapshot restore enabled are initialized at creation and never reported.
+// Tracked volumes are kept in memory: the volumes restored by the previous leader are not tracked after a failover.
+type restoreProgressTracker struct {
+	cloud    cloud.Cloud
+	client   kubernetes.Interface
+	recorder record.EventRecorder
+	mu       sync.Mutex
+	volumes  map[string]*restoredPVC
+}
+
+func newRestoreProgressTracker(c cloud.Cloud, k kubernetes.Interface, o *Options) *restoreProgressTracker {
+	if o.VolumeInitializationPollInterval <= 0 {
+		return nil
+	}
+	if k == nil {
+		klog.InfoS("No Kubernetes client available, not reporting the initialization of restored volumes")
+		return nil
+	}
+	t := &restoreProgressTracker{
+		cloud:    c,
+		client:   k,
+		recorder: newEventRecorder(k),
+		volumes:  map[string]*restoredPVC{},
+	}
+	go func() {
+		for range time.Tick(o.VolumeInitializationPollInterval) {
+			t.poll(context.Background())
+		}
+	}()
+	return t
+}
+
+// track starts reporting the initialization of a volume restored from a snapshot for a PVC.
+func (t *restoreProgressTracker) track(volumeID, pvcNamespace, pvcName string) {
+	if t == nil || pvcNamespace == "" || pvcName == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.volumes[volumeID] = &restoredPVC{namespace: pvcNamespace, name: pvcName}
+}
+
+// poll reports the initialization progress of the tracked volumes and stops tracking the initialized ones.
+func (t *restoreProgressTracker) poll(ctx context.Context) {
+	t.mu.Lock()
+	volumeIDs := make([]string, 0, len(t.volumes))
+	for volumeID := range t.volumes {
+		volumeIDs = append(volumeIDs, volumeID)
+	}
+	t.mu.Unlock()
+	if len(volumeIDs) == 0 {
+		return
+	}
+
+	health, err := t.cloud.GetVolumeHealth(ctx, volumeIDs)
+	if err != nil {
+		klog.ErrorS(err, "pollRestoreProgress: could not get the initialization status of restored volumes")
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, volumeID := range volumeIDs {
+		pvc := t.volumes[volumeID]
+		h, ok := health[volumeID]
+		if !ok {
+			// The volume was deleted
+			delete(t.volumes, volumeID)
+			continue
+		}
+		labels := map[string]string{"volume_id": volumeID}
+		if !h.Initializing {
+			delete(t.volumes, volumeID)
+			if pvc.initializing {
+				metrics.Recorder().SetGauge(metrics.VolumeInitializationProgress, metrics.VolumeInitializationProgressHelpText, 100, labels)
+				t.event(ctx, pvc, corev1.EventTypeNormal, volumeInitializedReason, fmt.Sprintf("Volume %s is initialized, all its blocks were downloaded from the snapshot", volumeID))
+			}
+			continue
+		}
+
+		pvc.initializing = true
+		metrics.Recorder().SetGauge(metrics.VolumeInitializationProgress, metrics.VolumeInitializationProgressHelpText, float64(h.InitializationProgress), labels)
+		message := fmt.Sprintf("Volume %s is initializing from its snapshot: %d%% complete", volumeID, h.InitializationProgress)
+		if h.InitializationTimeLeft > 0 {
+			message += fmt.Sprintf(", about %s left", h.InitializationTimeLeft)
+		}
+		message += ". Reads of blocks not yet downloaded from the snapshot are slower until it completes"
+		t.event(ctx, pvc, corev1.EventTypeNormal, volumeInitializingReason, message)
+	}
+}
+
+func (t *restoreProgressTracker) event(ctx context.Context, pvc *restoredPVC, eventType, reason, message string) {
+	claim, err := t.client.CoreV1().PersistentVolumeClaims(pvc.namespace).Get(ctx, pvc.name, metav1.GetOptions{})
+	if err != nil {
+		klog.ErrorS(err, "pollRestoreProgress: could not get PVC", "namespace", pvc.namespace, "name", pvc.name)
+		return
+	}
+	t.recorder.Event(claim, eventType, reason, message)
+}
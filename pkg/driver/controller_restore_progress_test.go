@@ -0,0 +1,74 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRestoreProgressTracker(t *testing.T) {
+	var nilTracker *restoreProgressTracker
+	nilTracker.track("vol-test", "default", "data")
+	assert.Nil(t, newRestoreProgressTracker(nil, fake.NewClientset(), &Options{}))
+
+	client := fake.NewClientset(
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "restored"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "fsr"}},
+	)
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	recorder := record.NewFakeRecorder(10)
+	tracker := &restoreProgressTracker{cloud: mockCloud, client: client, recorder: recorder, volumes: map[string]*restoredPVC{}}
+
+	tracker.track("vol-restored", "default", "restored")
+	tracker.track("vol-fsr", "default", "fsr")
+	tracker.track("vol-deleted", "default", "deleted")
+	// Volumes provisioned without --extra-create-metadata are not tracked
+	tracker.track("vol-no-pvc", "", "")
+
+	gomock.InOrder(
+		mockCloud.EXPECT().GetVolumeHealth(gomock.Any(), gomock.InAnyOrder([]string{"vol-restored", "vol-fsr", "vol-deleted"})).Return(map[string]*cloud.VolumeHealth{
+			"vol-restored": {IOEnabled: true, Initializing: true, InitializationProgress: 42, InitializationTimeLeft: 10 * time.Minute},
+			"vol-fsr":      {IOEnabled: true},
+		}, nil),
+		mockCloud.EXPECT().GetVolumeHealth(gomock.Any(), []string{"vol-restored"}).Return(map[string]*cloud.VolumeHealth{
+			"vol-restored": {IOEnabled: true},
+		}, nil),
+	)
+
+	tracker.poll(t.Context())
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Normal VolumeInitializing Volume vol-restored is initializing from its snapshot: 42% complete, about 10m0s left. Reads of blocks not yet downloaded from the snapshot are slower until it completes", <-recorder.Events)
+
+	tracker.poll(t.Context())
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Normal VolumeInitialized Volume vol-restored is initialized, all its blocks were downloaded from the snapshot", <-recorder.Events)
+
+	assert.Empty(t, tracker.volumes)
+	// Nothing left to poll
+	tracker.poll(t.Context())
+}
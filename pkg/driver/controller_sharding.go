@@ -0,0 +1,179 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// shardForwardedKey is the gRPC metadata key set on the requests forwarded to the controller shard of their volume.
+const shardForwardedKey = "x-ebs-csi-shard-forwarded"
+
+// shardedRPCs are the controller RPCs handled by the shard of their volume, the only ones served to other shards.
+var shardedRPCs = map[string]bool{
+	"ControllerExpandVolume": true,
+	"ControllerModifyVolume": true,
+	"ModifyVolumeProperties": true,
+}
+
+// volumeShard returns the controller shard, between 0 and shards-1, that handles volumeID.
+func volumeShard(volumeID string, shards int) int {
+	if shards <= 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(volumeID))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// ownsVolume returns true if volumeID is handled by the shard of this controller replica.
+// Every volume is owned by the replica when --controller-shards is not set.
+func (d *ControllerService) ownsVolume(volumeID string) bool {
+	return volumeShard(volumeID, d.options.ControllerShards) == d.options.ControllerShardIndex
+}
+
+// shardPeers connects to the other controller shards, to which the requests for their volumes are forwarded.
+type shardPeers struct {
+	addresses []string
+	// tlsConfig authenticates this replica to the other shards, and them to it. Nil if it could not be loaded,
+	// the error is then returned by conn.
+	tlsConfig *tls.Config
+	tlsErr    error
+	// conns are created on first use, keyed by shard
+	mu    sync.Mutex
+	conns map[int]*grpc.ClientConn
+}
+
+func newShardPeers(o *Options) *shardPeers {
+	if len(o.ControllerShardPeers) == 0 {
+		return nil
+	}
+	tlsConfig, err := shardPeerTLSConfig(o)
+	if err != nil {
+		klog.ErrorS(err, "Could not load the TLS configuration of the controller shard peers, requests for the volumes of other shards will fail")
+	}
+	return &shardPeers{addresses: o.ControllerShardPeers, tlsConfig: tlsConfig, tlsErr: err, conns: map[int]*grpc.ClientConn{}}
+}
+
+func (p *shardPeers) conn(shard int) (*grpc.ClientConn, error) {
+	if p.tlsErr != nil {
+		return nil, p.tlsErr
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if conn, ok := p.conns[shard]; ok {
+		return conn, nil
+	}
+	conn, err := grpc.NewClient(p.addresses[shard], grpc.WithTransportCredentials(credentials.NewTLS(p.tlsConfig)))
+	if err != nil {
+		return nil, err
+	}
+	p.conns[shard] = conn
+	return conn, nil
+}
+
+// shardOwner returns nil if volumeID is handled by this replica, or the connection to the controller shard handling
+// it, to which the request must be forwarded. Without --controller-shard-peers, or when the request was already
+// forwarded by another shard, requests for the volumes of other shards fail with Unavailable, so that the sidecar
+// retries and the replica owning the volume is the only one to modify it.
+func (d *ControllerService) shardOwner(ctx context.Context, volumeID string) (*grpc.ClientConn, error) {
+	if d.ownsVolume(volumeID) {
+		return nil, nil
+	}
+	shard := volumeShard(volumeID, d.options.ControllerShards)
+	if d.shardPeers == nil || isShardForwarded(ctx) {
+		klog.V(4).InfoS("Volume is handled by another controller shard", "volumeID", volumeID, "shard", shard, "localShard", d.options.ControllerShardIndex)
+		return nil, status.Errorf(codes.Unavailable, "volume %s is handled by controller shard %d, this is shard %d", volumeID, shard, d.options.ControllerShardIndex)
+	}
+	conn, err := d.shardPeers.conn(shard)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "could not connect to controller shard %d of volume %s: %v", shard, volumeID, err)
+	}
+	klog.V(4).InfoS("Forwarding request to the controller shard of the volume", "volumeID", volumeID, "shard", shard, "localShard", d.options.ControllerShardIndex)
+	return conn, nil
+}
+
+// shardForwardedContext marks the outgoing request as forwarded, so that the receiving shard never forwards it again.
+func shardForwardedContext(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, shardForwardedKey, "true")
+}
+
+func isShardForwarded(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(md.Get(shardForwardedKey)) > 0
+}
+
+// shardPeerTLSConfig returns the mutual TLS configuration of the connections between controller shards. Each replica
+// presents the certificate of --controller-shard-peer-tls-cert-file, as a server and as a client, and only accepts
+// peers whose certificate is signed by --controller-shard-peer-tls-ca-file. The key pair is read again on every
+// handshake, so that rotated certificates are picked up without a restart.
+func shardPeerTLSConfig(o *Options) (*tls.Config, error) {
+	data, err := os.ReadFile(o.ControllerShardPeerTLSCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read controller shard peer CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificate found in controller shard peer CA file %s", o.ControllerShardPeerTLSCAFile)
+	}
+	loadKeyPair := func() (*tls.Certificate, error) {
+		cert, err := tls.LoadX509KeyPair(o.ControllerShardPeerTLSCertFile, o.ControllerShardPeerTLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load controller shard peer certificate: %w", err)
+		}
+		return &cert, nil
+	}
+	if _, err := loadKeyPair(); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+		RootCAs:    pool,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return loadKeyPair()
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return loadKeyPair()
+		},
+	}, nil
+}
+
+// shardPeerInterceptor only lets the sharded RPCs forwarded by other shards through the --controller-shard-peer-address
+// listener. The peers themselves are authenticated by the mutual TLS of the listener, the metadata only tells
+// forwarded requests apart so that they are never forwarded again.
+func shardPeerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !shardedRPCs[path.Base(info.FullMethod)] || !isShardForwarded(ctx) {
+		return nil, status.Errorf(codes.PermissionDenied, "%s is not served to other controller shards", info.FullMethod)
+	}
+	return handler(ctx, req)
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestVolumeShard(t *testing.T) {
+	const shards = 3
+	counts := make([]int, shards)
+	for i := range 300 {
+		volumeID := fmt.Sprintf("vol-%017x", i)
+		shard := volumeShard(volumeID, shards)
+		require.Equal(t, shard, volumeShard(volumeID, shards), "shard of a volume must be stable")
+		counts[shard]++
+	}
+	for shard, count := range counts {
+		assert.Positive(t, count, "shard %d handles no volumes", shard)
+	}
+
+	assert.Equal(t, 0, volumeShard("vol-test", 0))
+	assert.Equal(t, 0, volumeShard("vol-test", 1))
+}
+
+func TestControllerModifyVolumeOtherShard(t *testing.T) {
+	const volumeID = "vol-test"
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	mockCloud.EXPECT().ResizeOrModifyDisk(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	o := &Options{
+		ControllerShards:                  2,
+		ControllerShardIndex:              1 - volumeShard(volumeID, 2),
+		ModifyVolumeRequestHandlerTimeout: time.Millisecond,
+	}
+	d := NewControllerService(mockCloud, o, nil)
+
+	_, err := d.ControllerModifyVolume(t.Context(), &csi.ControllerModifyVolumeRequest{
+		VolumeId:          volumeID,
+		MutableParameters: map[string]string{"iops": "3000"},
+	})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	_, err = d.ControllerExpandVolume(t.Context(), &csi.ControllerExpandVolumeRequest{
+		VolumeId:      volumeID,
+		CapacityRange: &csi.CapacityRange{RequiredBytes: 10 * 1024 * 1024 * 1024},
+	})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestControllerExpandVolumeForwardedToShard(t *testing.T) {
+	const volumeID = "vol-test"
+	owner := volumeShard(volumeID, 2)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	certFile, keyFile, caFile := writeShardPeerCertificates(t)
+
+	// The replica of the shard owning the volume serves forwarded requests
+	ownerCloud := cloud.NewMockCloud(gomock.NewController(t))
+	ownerCloud.EXPECT().ResizeOrModifyDisk(gomock.Any(), volumeID, int64(10*1024*1024*1024), gomock.Any()).Return(int32(10), nil)
+	ownerOptions := &Options{
+		ControllerShards:                  2,
+		ControllerShardIndex:              owner,
+		ControllerShardPeerTLSCertFile:    certFile,
+		ControllerShardPeerTLSKeyFile:     keyFile,
+		ControllerShardPeerTLSCAFile:      caFile,
+		ModifyVolumeRequestHandlerTimeout: time.Millisecond,
+	}
+	ownerService := NewControllerService(ownerCloud, ownerOptions, nil)
+	tlsConfig, err := shardPeerTLSConfig(ownerOptions)
+	require.NoError(t, err)
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)), grpc.UnaryInterceptor(shardPeerInterceptor))
+	csi.RegisterControllerServer(srv, ownerService)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	peers := make([]string, 2)
+	peers[owner] = listener.Addr().String()
+	otherCloud := cloud.NewMockCloud(gomock.NewController(t))
+	otherCloud.EXPECT().ResizeOrModifyDisk(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	d := NewControllerService(otherCloud, &Options{
+		ControllerShards:               2,
+		ControllerShardIndex:           1 - owner,
+		ControllerShardPeers:           peers,
+		ControllerShardPeerTLSCertFile: certFile,
+		ControllerShardPeerTLSKeyFile:  keyFile,
+		ControllerShardPeerTLSCAFile:   caFile,
+	}, nil)
+
+	resp, err := d.ControllerExpandVolume(t.Context(), &csi.ControllerExpandVolumeRequest{
+		VolumeId:      volumeID,
+		CapacityRange: &csi.CapacityRange{RequiredBytes: 10 * 1024 * 1024 * 1024},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10*1024*1024*1024), resp.GetCapacityBytes())
+
+	// A request forwarded to the wrong shard is never forwarded again
+	forwarded := metadata.NewIncomingContext(t.Context(), metadata.Pairs(shardForwardedKey, "true"))
+	_, err = d.ControllerExpandVolume(forwarded, &csi.ControllerExpandVolumeRequest{
+		VolumeId:      volumeID,
+		CapacityRange: &csi.CapacityRange{RequiredBytes: 10 * 1024 * 1024 * 1024},
+	})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// Clients without a certificate signed by the peer CA are rejected, whatever metadata they send
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	_, err = csi.NewControllerClient(conn).ControllerExpandVolume(shardForwardedContext(t.Context()), &csi.ControllerExpandVolumeRequest{
+		VolumeId:      volumeID,
+		CapacityRange: &csi.CapacityRange{RequiredBytes: 20 * 1024 * 1024 * 1024},
+	})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+// writeShardPeerCertificates writes a CA and a certificate it signs for 127.0.0.1, used as both the server and the
+// client certificate of the shard peers.
+func writeShardPeerCertificates(t *testing.T) (certFile, keyFile, caFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	writePEM := func(name, blockType string, der []byte) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+		return p
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ebs-csi-controller-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caFile = writePEM("ca.crt", "CERTIFICATE", caDER)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "ebs-csi-controller"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	certFile = writePEM("tls.crt", "CERTIFICATE", der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	keyFile = writePEM("tls.key", "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile, caFile
+}
+
+func TestShardPeerInterceptor(t *testing.T) {
+	handler := func(_ context.Context, _ any) (any, error) { return "ok", nil }
+	forwarded := metadata.NewIncomingContext(t.Context(), metadata.Pairs(shardForwardedKey, "true"))
+
+	resp, err := shardPeerInterceptor(forwarded, nil, &grpc.UnaryServerInfo{FullMethod: csi.Controller_ControllerExpandVolume_FullMethodName}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = shardPeerInterceptor(forwarded, nil, &grpc.UnaryServerInfo{FullMethod: csi.Controller_DeleteVolume_FullMethodName}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = shardPeerInterceptor(t.Context(), nil, &grpc.UnaryServerInfo{FullMethod: csi.Controller_ControllerExpandVolume_FullMethodName}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestReapSoftDeletedVolumesOtherShard(t *testing.T) {
+	const volumeID = "vol-expired"
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	mockCloud.EXPECT().ListPendingDeletionDisks(gomock.Any(), gomock.Any()).Return(map[string]time.Time{
+		volumeID: time.Now().Add(-time.Minute),
+	}, nil)
+	mockCloud.EXPECT().DeleteDisk(gomock.Any(), gomock.Any()).Times(0)
+
+	d := &ControllerService{
+		cloud:    mockCloud,
+		inFlight: internal.NewInFlight(),
+		options: &Options{
+			SoftDeleteRetention:  time.Hour,
+			ControllerShards:     2,
+			ControllerShardIndex: 1 - volumeShard(volumeID, 2),
+		},
+	}
+	d.reapSoftDeletedVolumes(t.Context())
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// snapshotLimiter limits the number of CreateSnapshot calls in flight, so that large backup jobs cannot exhaust the
+// EC2 API quota and starve provisioning. Requests over the limit wait in a queue; when a call completes, the next
+// call is granted to the oldest waiting request of the namespace with the fewest calls in flight, so that a namespace
+// snapshotting many volumes at once cannot starve the others.
+type snapshotLimiter struct {
+	limit     int
+	maxQueued int
+
+	mu       sync.Mutex
+	inFlight map[string]int
+	total    int
+	waiting  []*snapshotWaiter
+}
+
+// snapshotWaiter is a CreateSnapshot request waiting in the queue of a snapshotLimiter. ready is closed once the
+// request is granted a call.
+type snapshotWaiter struct {
+	namespace string
+	ready     chan struct{}
+}
+
+func newSnapshotLimiter(o *Options) *snapshotLimiter {
+	if o.MaxConcurrentSnapshots <= 0 {
+		return nil
+	}
+	return &snapshotLimiter{
+		limit:     o.MaxConcurrentSnapshots,
+		maxQueued: o.MaxQueuedSnapshots,
+		inFlight:  map[string]int{},
+	}
+}
+
+// acquire waits until a CreateSnapshot call of namespace can be made. On success, it returns a function that must be
+// called once the call completes. It fails if the queue is full or ctx is done before the call is granted.
+func (l *snapshotLimiter) acquire(ctx context.Context, namespace string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	release := func() { l.release(namespace) }
+
+	l.mu.Lock()
+	if l.total < l.limit && len(l.waiting) == 0 {
+		l.grant(namespace)
+		l.mu.Unlock()
+		return release, nil
+	}
+	if l.maxQueued > 0 && len(l.waiting) >= l.maxQueued {
+		queued := len(l.waiting)
+		l.mu.Unlock()
+		klog.V(4).InfoS("CreateSnapshot: rejecting request, too many queued snapshots", "namespace", namespace, "queued", queued, "limit", l.maxQueued)
+		return nil, queueFullError("Too many queued snapshots (%d, limit %d), retry after %s", queued, l.maxQueued, queueFullRetryAfter)
+	}
+	w := &snapshotWaiter{namespace: namespace, ready: make(chan struct{})}
+	l.waiting = append(l.waiting, w)
+	klog.V(4).InfoS("CreateSnapshot: waiting for concurrent snapshots", "namespace", namespace, "inFlight", l.total, "queued", len(l.waiting))
+	l.mu.Unlock()
+
+	defer util.SetOperationStep(ctx, "waiting for concurrent snapshots")()
+	select {
+	case <-w.ready:
+		return release, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		i := slices.Index(l.waiting, w)
+		if i >= 0 {
+			l.waiting = slices.Delete(l.waiting, i, i+1)
+		}
+		l.mu.Unlock()
+		if i < 0 {
+			// The call was granted while ctx was done, give it to the next request.
+			release()
+		}
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// release ends a call of namespace and grants calls to the waiting requests that now fit in the limit.
+func (l *snapshotLimiter) release(namespace string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight[namespace]--
+	if l.inFlight[namespace] <= 0 {
+		delete(l.inFlight, namespace)
+	}
+	l.total--
+
+	for l.total < l.limit && len(l.waiting) > 0 {
+		// Waiting requests are in arrival order, so the first request of the namespace with the fewest calls in
+		// flight is its oldest one.
+		next := 0
+		for i, w := range l.waiting {
+			if l.inFlight[w.namespace] < l.inFlight[l.waiting[next].namespace] {
+				next = i
+			}
+		}
+		w := l.waiting[next]
+		l.waiting = slices.Delete(l.waiting, next, next+1)
+		l.grant(w.namespace)
+		close(w.ready)
+	}
+}
+
+// grant counts a call of namespace as in flight. Must be called with mu held.
+func (l *snapshotLimiter) grant(namespace string) {
+	l.inFlight[namespace]++
+	l.total++
+}
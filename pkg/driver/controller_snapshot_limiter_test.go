@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// queueSnapshot starts acquiring a call of namespace and waits for the request to be queued. The returned channel
+// receives the release function once the call is granted.
+func queueSnapshot(t *testing.T, l *snapshotLimiter, namespace string) chan func() {
+	t.Helper()
+	l.mu.Lock()
+	queued := len(l.waiting)
+	l.mu.Unlock()
+
+	granted := make(chan func(), 1)
+	go func() {
+		release, err := l.acquire(t.Context(), namespace)
+		assert.NoError(t, err)
+		granted <- release
+	}()
+	require.Eventually(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return len(l.waiting) == queued+1
+	}, time.Second, time.Millisecond)
+	return granted
+}
+
+func TestSnapshotLimiter(t *testing.T) {
+	var nilLimiter *snapshotLimiter
+	release, err := nilLimiter.acquire(t.Context(), "backup")
+	require.NoError(t, err)
+	release()
+	assert.Nil(t, newSnapshotLimiter(&Options{}))
+
+	l := newSnapshotLimiter(&Options{MaxConcurrentSnapshots: 2, MaxQueuedSnapshots: 3})
+	releaseBackup1, err := l.acquire(t.Context(), "backup")
+	require.NoError(t, err)
+	releaseBackup2, err := l.acquire(t.Context(), "backup")
+	require.NoError(t, err)
+
+	backup3 := queueSnapshot(t, l, "backup")
+	backup4 := queueSnapshot(t, l, "backup")
+	app1 := queueSnapshot(t, l, "app")
+
+	// The queue is full
+	_, err = l.acquire(t.Context(), "app")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// The namespace with the fewest calls in flight goes first, even though it queued last
+	releaseBackup1()
+	releaseApp1 := <-app1
+	assert.Empty(t, backup3)
+
+	releaseBackup2()
+	releaseBackup3 := <-backup3
+	assert.Empty(t, backup4)
+
+	// Requests whose context is done leave the queue
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	_, err = l.acquire(ctx, "app")
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Len(t, l.waiting, 1)
+
+	releaseApp1()
+	releaseBackup4 := <-backup4
+	releaseBackup3()
+	releaseBackup4()
+	assert.Zero(t, l.total)
+	assert.Empty(t, l.inFlight)
+	assert.Empty(t, l.waiting)
+}
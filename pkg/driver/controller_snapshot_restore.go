@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// minVolumeInitializationRate and maxVolumeInitializationRate bound the provisioned initialization rate of
+	// volumes restored from snapshots, in MiB/s.
+	minVolumeInitializationRate = 100
+	maxVolumeInitializationRate = 300
+)
+
+// checkSnapshotRestore checks that a snapshot can be restored to the Outpost the volume is created on, which is
+// empty for volumes created in a region, before calling EC2, and returns the snapshot, or nil if it could not be
+// read. Snapshots stored in a region can be restored to any zone or Outpost of the region, but snapshots stored on an
+// Outpost only to that Outpost, whose accessible topology is returned for them so that the volume is created in the
+// zone of the Outpost.
+// A restore that the accessible topologies cannot satisfy fails with ResourceExhausted, for which the
+// external-provisioner reschedules the PVCs of WaitForFirstConsumer StorageClasses onto another node.
+func (d *ControllerService) checkSnapshotRestore(ctx context.Context, snapshotID string, requirement *csi.TopologyRequirement, outpostArn string) (*cloud.Snapshot, *csi.Topology, error) {
+	snapshot, err := d.cloud.GetSnapshotByID(ctx, snapshotID)
+	if errors.Is(err, cloud.ErrNotFound) {
+		return nil, nil, status.Errorf(codes.NotFound, "Source snapshot %s not found", snapshotID)
+	}
+	if err != nil {
+		// EC2 validates the restore when the volume is created
+		klog.V(4).InfoS("Could not get source snapshot to validate its restore topology", "snapshotID", snapshotID, "err", err)
+		return nil, nil, nil
+	}
+	if snapshot.OutpostArn == "" {
+		return snapshot, nil, nil
+	}
+	if outpostArn != "" && outpostArn != snapshot.OutpostArn {
+		return nil, nil, status.Errorf(codes.ResourceExhausted, "Snapshot %s is stored on Outpost %s and cannot be restored to Outpost %s", snapshotID, snapshot.OutpostArn, outpostArn)
+	}
+	topology := pickOutpostTopology(requirement, snapshot.OutpostArn)
+	if topology == nil {
+		return nil, nil, status.Errorf(codes.ResourceExhausted, "Snapshot %s is stored on Outpost %s, which is not in the accessible topology of the volume", snapshotID, snapshot.OutpostArn)
+	}
+	return snapshot, topology, nil
+}
+
+// parseVolumeInitializationRate parses the volumeInitializationRate parameter of a StorageClass or
+// VolumeSnapshotClass.
+func parseVolumeInitializationRate(value string) (int32, error) {
+	rate, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "Could not parse invalid volumeInitializationRate: %v", err)
+	}
+	if rate < minVolumeInitializationRate || rate > maxVolumeInitializationRate {
+		return 0, status.Errorf(codes.InvalidArgument, "Invalid volumeInitializationRate %d, must be between %d and %d MiB/s", rate, minVolumeInitializationRate, maxVolumeInitializationRate)
+	}
+	return int32(rate), nil
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver/internal"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateVolumeSnapshotRestoreTopology(t *testing.T) {
+	const otherOutpostArn = "arn:aws:outposts:us-west-2:111111111111:outpost/op-1bbb111b1bbbb11b1"
+	volCap := []*csi.VolumeCapability{
+		{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		},
+	}
+	zoneTopology := &csi.Topology{Segments: map[string]string{WellKnownZoneTopologyKey: "us-west-2a"}}
+	outpostTopology := &csi.Topology{Segments: map[string]string{
+		WellKnownZoneTopologyKey: "us-west-2b",
+		AwsAccountIDKey:          "111111111111",
+		AwsOutpostIDKey:          "op-0aaa000a0aaaa00a0",
+		AwsRegionKey:             "us-west-2",
+		AwsPartitionKey:          "aws",
+	}}
+
+	testCases := []struct {
+		name          string
+		snapshot      *cloud.Snapshot
+		snapshotErr   error
+		requirement   *csi.TopologyRequirement
+		expZone       string
+		expOutpostArn string
+		expErrCode    codes.Code
+	}{
+		{
+			name:        "success: regional snapshot restored to zone",
+			snapshot:    &cloud.Snapshot{SnapshotID: "snap-test"},
+			requirement: &csi.TopologyRequirement{Preferred: []*csi.Topology{zoneTopology}},
+			expZone:     "us-west-2a",
+		},
+		{
+			name:          "success: regional snapshot restored to outpost",
+			snapshot:      &cloud.Snapshot{SnapshotID: "snap-test"},
+			requirement:   &csi.TopologyRequirement{Preferred: []*csi.Topology{outpostTopology}},
+			expZone:       "us-west-2b",
+			expOutpostArn: testOutpostARN,
+		},
+		{
+			name:          "success: outpost snapshot restored to the accessible topology of its outpost",
+			snapshot:      &cloud.Snapshot{SnapshotID: "snap-test", OutpostArn: testOutpostARN},
+			requirement:   &csi.TopologyRequirement{Preferred: []*csi.Topology{zoneTopology}, Requisite: []*csi.Topology{zoneTopology, outpostTopology}},
+			expZone:       "us-west-2b",
+			expOutpostArn: testOutpostARN,
+		},
+		{
+			name:        "fail: outpost snapshot not in accessible topology",
+			snapshot:    &cloud.Snapshot{SnapshotID: "snap-test", OutpostArn: testOutpostARN},
+			requirement: &csi.TopologyRequirement{Preferred: []*csi.Topology{zoneTopology}},
+			expErrCode:  codes.ResourceExhausted,
+		},
+		{
+			name:        "fail: outpost snapshot restored to another outpost",
+			snapshot:    &cloud.Snapshot{SnapshotID: "snap-test", OutpostArn: otherOutpostArn},
+			requirement: &csi.TopologyRequirement{Preferred: []*csi.Topology{outpostTopology}},
+			expErrCode:  codes.ResourceExhausted,
+		},
+		{
+			name:        "fail: snapshot not found",
+			snapshotErr: cloud.ErrNotFound,
+			expErrCode:  codes.NotFound,
+		},
+		{
+			name:        "success: snapshot lookup failure left to EC2",
+			snapshotErr: errors.New("throttled"),
+			requirement: &csi.TopologyRequirement{Preferred: []*csi.Topology{zoneTopology}},
+			expZone:     "us-west-2a",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			mockCloud.EXPECT().GetSnapshotByID(gomock.Any(), "snap-test").Return(tc.snapshot, tc.snapshotErr)
+			if tc.expErrCode == codes.OK {
+				mockCloud.EXPECT().CreateDisk(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+					func(_ context.Context, volumeName string, opts *cloud.DiskOptions) (*cloud.Disk, error) {
+						assert.Equal(t, tc.expZone, opts.AvailabilityZone)
+						assert.Equal(t, tc.expOutpostArn, opts.OutpostArn)
+						return &cloud.Disk{VolumeID: volumeName, AvailabilityZone: opts.AvailabilityZone, OutpostArn: opts.OutpostArn, CapacityGiB: 1}, nil
+					})
+			}
+			d := &ControllerService{cloud: mockCloud, inFlight: internal.NewInFlight(), options: &Options{}}
+
+			_, err := d.CreateVolume(t.Context(), &csi.CreateVolumeRequest{
+				Name:                      "vol-test",
+				CapacityRange:             &csi.CapacityRange{RequiredBytes: util.GiB},
+				VolumeCapabilities:        volCap,
+				AccessibilityRequirements: tc.requirement,
+				VolumeContentSource: &csi.VolumeContentSource{
+					Type: &csi.VolumeContentSource_Snapshot{Snapshot: &csi.VolumeContentSource_SnapshotSource{SnapshotId: "snap-test"}},
+				},
+			})
+			assert.Equal(t, tc.expErrCode, status.Code(err))
+		})
+	}
+}
+
+func TestVolumeInitializationRate(t *testing.T) {
+	volCap := []*csi.VolumeCapability{
+		{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		},
+	}
+	snapshotSource := &csi.VolumeContentSource{
+		Type: &csi.VolumeContentSource_Snapshot{Snapshot: &csi.VolumeContentSource_SnapshotSource{SnapshotId: "snap-test"}},
+	}
+
+	testCases := []struct {
+		name         string
+		rate         string
+		source       *csi.VolumeContentSource
+		snapshotRate int32
+		expRate      int32
+		expErrCode   codes.Code
+	}{
+		{
+			name:    "success: StorageClass rate",
+			rate:    "200",
+			source:  snapshotSource,
+			expRate: 200,
+		},
+		{
+			name:         "success: VolumeSnapshotClass rate",
+			source:       snapshotSource,
+			snapshotRate: 150,
+			expRate:      150,
+		},
+		{
+			name:         "success: StorageClass rate overrides VolumeSnapshotClass rate",
+			rate:         "300",
+			source:       snapshotSource,
+			snapshotRate: 150,
+			expRate:      300,
+		},
+		{
+			name: "success: rate ignored without snapshot",
+			rate: "200",
+		},
+		{
+			name:       "fail: rate out of range",
+			rate:       "50",
+			source:     snapshotSource,
+			expErrCode: codes.InvalidArgument,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			if tc.expErrCode == codes.OK {
+				if tc.source != nil {
+					mockCloud.EXPECT().GetSnapshotByID(gomock.Any(), "snap-test").Return(&cloud.Snapshot{SnapshotID: "snap-test", VolumeInitializationRate: tc.snapshotRate}, nil)
+				}
+				mockCloud.EXPECT().CreateDisk(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+					func(_ context.Context, volumeName string, opts *cloud.DiskOptions) (*cloud.Disk, error) {
+						assert.Equal(t, tc.expRate, opts.VolumeInitializationRate)
+						return &cloud.Disk{VolumeID: volumeName, AvailabilityZone: expZone, CapacityGiB: 1}, nil
+					})
+			}
+			d := &ControllerService{cloud: mockCloud, inFlight: internal.NewInFlight(), options: &Options{}}
+			params := map[string]string{}
+			if tc.rate != "" {
+				params[VolumeInitializationRateKey] = tc.rate
+			}
+
+			_, err := d.CreateVolume(t.Context(), &csi.CreateVolumeRequest{
+				Name:                "vol-test",
+				CapacityRange:       &csi.CapacityRange{RequiredBytes: util.GiB},
+				VolumeCapabilities:  volCap,
+				Parameters:          params,
+				VolumeContentSource: tc.source,
+			})
+			assert.Equal(t, tc.expErrCode, status.Code(err))
+		})
+	}
+}
+
+func TestCreateSnapshotVolumeInitializationRate(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	mockCloud.EXPECT().GetSnapshotByName(gomock.Any(), "test-snapshot").Return(nil, cloud.ErrNotFound).Times(2)
+	mockCloud.EXPECT().CreateSnapshot(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+		func(_ context.Context, volumeID string, opts *cloud.SnapshotOptions) (*cloud.Snapshot, error) {
+			assert.Equal(t, "250", opts.Tags[cloud.VolumeInitializationRateTagKey])
+			return &cloud.Snapshot{SnapshotID: "snap-test", SourceVolumeID: volumeID, Size: 1}, nil
+		})
+	d := &ControllerService{cloud: mockCloud, inFlight: internal.NewInFlight(), options: &Options{}}
+
+	_, err := d.CreateSnapshot(t.Context(), &csi.CreateSnapshotRequest{
+		Name:           "test-snapshot",
+		SourceVolumeId: "vol-test",
+		Parameters:     map[string]string{"volumeInitializationRate": "250"},
+	})
+	require.NoError(t, err)
+
+	_, err = d.CreateSnapshot(t.Context(), &csi.CreateSnapshotRequest{
+		Name:           "test-snapshot",
+		SourceVolumeId: "vol-test",
+		Parameters:     map[string]string{"volumeInitializationRate": "400"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
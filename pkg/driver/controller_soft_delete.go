@@ -0,0 +1,147 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"google.golang.org/grpc/codes"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// softDeleteReapInterval is how often the reaper looks for soft-deleted volumes whose retention has expired.
+const softDeleteReapInterval = 10 * time.Minute
+
+// softDeleteVolume tags the volume for deletion once --soft-delete-retention has passed instead of deleting it.
+// The CO only calls DeleteVolume once the volume has been unpublished from every node, so the volume is already
+// detached and is left available for recovery until the reaper deletes it. Removing the tag cancels the deletion.
+// A retried DeleteVolume keeps the tag of the first call, so that retries do not push the deletion back.
+func (d *ControllerService) softDeleteVolume(ctx context.Context, volumeID string) (*csi.DeleteVolumeResponse, error) {
+	disk, err := d.cloud.GetDiskByID(ctx, volumeID)
+	if err != nil {
+		if errors.Is(err, cloud.ErrNotFound) {
+			klog.V(4).InfoS("DeleteVolume: volume not found, returning with success")
+			return &csi.DeleteVolumeResponse{}, nil
+		}
+		return nil, awsErrorToStatus(err, codes.Internal, "Could not get volume ID %q: %v", volumeID, err)
+	}
+	if !disk.PendingDeletionAt.IsZero() {
+		klog.V(4).InfoS("DeleteVolume: volume already marked for deletion", "volumeID", volumeID, "deleteAfter", disk.PendingDeletionAt)
+		return &csi.DeleteVolumeResponse{}, nil
+	}
+
+	deleteAfter := time.Now().Add(d.options.SoftDeleteRetention).UTC().Format(time.RFC3339)
+	err = d.cloud.ModifyTags(ctx, volumeID, cloud.ModifyTagsOptions{
+		TagsToAdd: map[string]string{cloud.PendingDeletionTagKey: deleteAfter},
+	})
+	if err != nil {
+		if errors.Is(err, cloud.ErrNotFound) {
+			klog.V(4).InfoS("DeleteVolume: volume not found, returning with success")
+			return &csi.DeleteVolumeResponse{}, nil
+		}
+		return nil, awsErrorToStatus(err, codes.Internal, "Could not mark volume ID %q for deletion: %v", volumeID, err)
+	}
+	klog.InfoS("DeleteVolume: volume marked for deletion", "volumeID", volumeID, "deleteAfter", deleteAfter)
+	return &csi.DeleteVolumeResponse{}, nil
+}
+
+// startSoftDeleteReaper periodically deletes soft-deleted volumes whose retention period has expired, once this
+// replica is elected leader. With --controller-shards, each shard elects its own leader, which only deletes the
+// volumes of its shard.
+func (d *ControllerService) startSoftDeleteReaper(k kubernetes.Interface) {
+	if k == nil {
+		klog.InfoS("No Kubernetes client available, deleting soft-deleted volumes without leader election")
+		go d.runSoftDeleteReaper(context.Background())
+		return
+	}
+	lockName := "soft-delete-" + util.GetDriverName()
+	if d.options.ControllerShards > 1 {
+		lockName += "-shard-" + strconv.Itoa(d.options.ControllerShardIndex)
+	}
+	go runLeaderElection(context.Background(), k, lockName, d.runSoftDeleteReaper)
+}
+
+// runSoftDeleteReaper reaps soft-deleted volumes every softDeleteReapInterval until ctx is done.
+func (d *ControllerService) runSoftDeleteReaper(ctx context.Context) {
+	ticker := time.NewTicker(softDeleteReapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.reapSoftDeletedVolumes(ctx)
+		}
+	}
+}
+
+func (d *ControllerService) reapSoftDeletedVolumes(ctx context.Context) {
+	pending, err := d.cloud.ListPendingDeletionDisks(ctx, softDeleteScopeTags(d.options.KubernetesClusterID))
+	if err != nil {
+		klog.ErrorS(err, "reapSoftDeletedVolumes: could not list volumes pending deletion")
+		return
+	}
+
+	for volumeID, deleteAfter := range pending {
+		if time.Now().Before(deleteAfter) || !d.ownsVolume(volumeID) {
+			continue
+		}
+		if !d.inFlight.Insert(volumeID) {
+			continue
+		}
+		d.reapSoftDeletedVolume(ctx, volumeID)
+		d.inFlight.Delete(volumeID)
+	}
+}
+
+// reapSoftDeletedVolume deletes a soft-deleted volume whose retention period has expired. The listing of the reaper
+// can be minutes old by the time a volume is reached, so the tag is checked again right before the deletion in
+// case the volume was recovered in the meantime.
+func (d *ControllerService) reapSoftDeletedVolume(ctx context.Context, volumeID string) {
+	disk, err := d.cloud.GetDiskByID(ctx, volumeID)
+	if err != nil {
+		if !errors.Is(err, cloud.ErrNotFound) {
+			klog.ErrorS(err, "reapSoftDeletedVolumes: could not get volume", "volumeID", volumeID)
+		}
+		return
+	}
+	if disk.PendingDeletionAt.IsZero() || time.Now().Before(disk.PendingDeletionAt) {
+		klog.InfoS("reapSoftDeletedVolumes: deletion of volume was cancelled or postponed", "volumeID", volumeID)
+		return
+	}
+	if _, err := d.cloud.DeleteDisk(ctx, volumeID); err != nil && !errors.Is(err, cloud.ErrNotFound) {
+		klog.ErrorS(err, "reapSoftDeletedVolumes: could not delete volume", "volumeID", volumeID)
+	} else {
+		klog.InfoS("reapSoftDeletedVolumes: deleted volume after retention period", "volumeID", volumeID, "deleteAfter", disk.PendingDeletionAt)
+	}
+}
+
+// softDeleteScopeTags returns the tags, on top of the tag of the driver, that soft-deleted volumes must carry to be
+// reaped by this controller: the cluster tag when --k8s-tag-cluster-id is set.
+func softDeleteScopeTags(clusterID string) map[string]string {
+	if clusterID == "" {
+		return nil
+	}
+	return map[string]string{ResourceLifecycleTagPrefix + clusterID: ResourceLifecycleOwned}
+}
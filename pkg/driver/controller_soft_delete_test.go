@@ -0,0 +1,155 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeleteVolumeSoftDelete(t *testing.T) {
+	const retention = 72 * time.Hour
+	testCases := []struct {
+		name              string
+		pendingDeletionAt time.Time
+		getErr            error
+		modifyErr         error
+		expectModify      bool
+		expectedCode      codes.Code
+	}{
+		{
+			name:         "success: volume marked for deletion",
+			expectModify: true,
+		},
+		{
+			name:              "success: retry keeps the existing mark",
+			pendingDeletionAt: time.Now().Add(time.Hour),
+		},
+		{
+			name:   "success: volume not found",
+			getErr: cloud.ErrNotFound,
+		},
+		{
+			name:         "success: volume deleted before tagging",
+			modifyErr:    cloud.ErrNotFound,
+			expectModify: true,
+		},
+		{
+			name:         "fail: describe error",
+			getErr:       errors.New("describe error"),
+			expectedCode: codes.Internal,
+		},
+		{
+			name:         "fail: tagging error",
+			modifyErr:    errors.New("tagging error"),
+			expectModify: true,
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			mockCloud.EXPECT().DeleteDisk(gomock.Any(), gomock.Any()).Times(0)
+			var disk *cloud.Disk
+			if tc.getErr == nil {
+				disk = &cloud.Disk{VolumeID: "vol-test", PendingDeletionAt: tc.pendingDeletionAt}
+			}
+			mockCloud.EXPECT().GetDiskByID(gomock.Any(), "vol-test").Return(disk, tc.getErr)
+			if tc.expectModify {
+				mockCloud.EXPECT().ModifyTags(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, options cloud.ModifyTagsOptions) error {
+						deleteAfter, err := time.Parse(time.RFC3339, options.TagsToAdd[cloud.PendingDeletionTagKey])
+						require.NoError(t, err)
+						assert.WithinDuration(t, time.Now().Add(retention), deleteAfter, time.Minute)
+						return tc.modifyErr
+					})
+			}
+
+			d := &ControllerService{
+				cloud:    mockCloud,
+				inFlight: internal.NewInFlight(),
+				options:  &Options{SoftDeleteRetention: retention},
+			}
+			resp, err := d.DeleteVolume(t.Context(), &csi.DeleteVolumeRequest{VolumeId: "vol-test"})
+			if tc.expectedCode != codes.OK {
+				assert.Equal(t, tc.expectedCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, &csi.DeleteVolumeResponse{}, resp)
+		})
+	}
+}
+
+func TestReapSoftDeletedVolumes(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	// Only the soft-deleted volumes of this cluster are listed
+	clusterTags := map[string]string{ResourceLifecycleTagPrefix + "cluster": ResourceLifecycleOwned}
+	mockCloud.EXPECT().ListPendingDeletionDisks(gomock.Any(), clusterTags).Return(map[string]time.Time{
+		"vol-expired":   time.Now().Add(-time.Minute),
+		"vol-gone":      time.Now().Add(-time.Hour),
+		"vol-recovered": time.Now().Add(-time.Hour),
+		"vol-retained":  time.Now().Add(time.Hour),
+		"vol-in-flight": time.Now().Add(-time.Hour),
+	}, nil)
+	mockCloud.EXPECT().GetDiskByID(gomock.Any(), "vol-expired").Return(&cloud.Disk{VolumeID: "vol-expired", PendingDeletionAt: time.Now().Add(-time.Minute)}, nil)
+	mockCloud.EXPECT().DeleteDisk(gomock.Any(), "vol-expired").Return(true, nil)
+	mockCloud.EXPECT().GetDiskByID(gomock.Any(), "vol-gone").Return(&cloud.Disk{VolumeID: "vol-gone", PendingDeletionAt: time.Now().Add(-time.Hour)}, nil)
+	mockCloud.EXPECT().DeleteDisk(gomock.Any(), "vol-gone").Return(false, cloud.ErrNotFound)
+	// The tag was removed after the volumes were listed, so the volume must not be deleted
+	mockCloud.EXPECT().GetDiskByID(gomock.Any(), "vol-recovered").Return(&cloud.Disk{VolumeID: "vol-recovered"}, nil)
+
+	d := &ControllerService{
+		cloud:    mockCloud,
+		inFlight: internal.NewInFlight(),
+		options:  &Options{SoftDeleteRetention: time.Hour, KubernetesClusterID: "cluster"},
+	}
+	// A DeleteVolume call for this volume is in progress, so the reaper must leave it alone
+	d.inFlight.Insert("vol-in-flight")
+
+	d.reapSoftDeletedVolumes(t.Context())
+}
+
+func TestRunSoftDeleteReaperStops(t *testing.T) {
+	d := &ControllerService{options: &Options{SoftDeleteRetention: time.Hour}}
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		d.runSoftDeleteReaper(ctx)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the reaper did not stop when its context was cancelled")
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud/metadata"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// volumeTagPolicyResource are the VolumeTagPolicy objects, which tag the volumes and snapshots of the PVCs of their
+	// namespace.
+	volumeTagPolicyResource = schema.GroupVersionResource{Group: "ebs.csi.aws.com", Version: "v1alpha1", Resource: "volumetagpolicies"}
+	// clusterVolumeTagPolicyResource are the ClusterVolumeTagPolicy objects, which tag the volumes and snapshots of the
+	// PVCs of all namespaces.
+	clusterVolumeTagPolicyResource = schema.GroupVersionResource{Group: "ebs.csi.aws.com", Version: "v1alpha1", Resource: "clustervolumetagpolicies"}
+	volumeSnapshotResource         = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+
+	errVolumeTagPoliciesNotSynced = errors.New("volume tag policies are not synced yet")
+)
+
+// volumeTagPolicy is a VolumeTagPolicy or ClusterVolumeTagPolicy object.
+type volumeTagPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              volumeTagPolicySpec `json:"spec"`
+}
+
+type volumeTagPolicySpec struct {
+	// Selector selects the PVCs of the policy by label, all PVCs if unset.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// VolumeTags are the tags of the volumes of the PVCs, whose values are templates like the tags of StorageClasses.
+	VolumeTags map[string]string `json:"volumeTags,omitempty"`
+	// SnapshotTags are the tags of the snapshots of the PVCs, whose values are templates like the tags of
+	// VolumeSnapshotClasses.
+	SnapshotTags map[string]string `json:"snapshotTags,omitempty"`
+}
+
+// volumeTagPolicies watches the volume tag policies of the cluster, which declare the tags of the volumes and
+// snapshots of the PVCs they select in addition to the tags of their StorageClass, VolumeSnapshotClass and
+// --extra-tags.
+type volumeTagPolicies struct {
+	client     dynamic.Interface
+	namespaced cache.GenericLister
+	cluster    cache.GenericLister
+	hasSynced  []cache.InformerSynced
+}
+
+func newVolumeTagPolicies(k kubernetes.Interface, o *Options) *volumeTagPolicies {
+	if !o.VolumeTagPolicies {
+		return nil
+	}
+	if k == nil {
+		klog.InfoS("No Kubernetes client available, not applying volume tag policies")
+		return nil
+	}
+	config, err := metadata.DefaultKubernetesAPIConfig(o.Kubeconfig)
+	if err != nil {
+		klog.ErrorS(err, "Could not configure the Kubernetes client of volume tag policies, not applying them")
+		return nil
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		klog.ErrorS(err, "Could not create the Kubernetes client of volume tag policies, not applying them")
+		return nil
+	}
+	return newVolumeTagPolicyWatch(client)
+}
+
+func newVolumeTagPolicyWatch(client dynamic.Interface) *volumeTagPolicies {
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	namespaced := factory.ForResource(volumeTagPolicyResource)
+	cluster := factory.ForResource(clusterVolumeTagPolicyResource)
+	p := &volumeTagPolicies{
+		client:     client,
+		namespaced: namespaced.Lister(),
+		cluster:    cluster.Lister(),
+		hasSynced:  []cache.InformerSynced{namespaced.Informer().HasSynced, cluster.Informer().HasSynced},
+	}
+	factory.Start(context.Background().Done())
+	return p
+}
+
+// tags returns the volume or snapshot tags of the policies that select a PVC, as key=value tag templates: the tags of
+// the VolumeTagPolicies of the namespace of the PVC, then those of the ClusterVolumeTagPolicies, each kind in the order
+// of their names, so that cluster policies override namespace policies when they set the same tag.
+func (p *volumeTagPolicies) tags(pvcNamespace string, pvcLabels map[string]string, snapshot bool) ([]string, error) {
+	if p == nil {
+		return nil, nil
+	}
+	for _, synced := range p.hasSynced {
+		if !synced() {
+			return nil, errVolumeTagPoliciesNotSynced
+		}
+	}
+
+	var objects []runtime.Object
+	if pvcNamespace != "" {
+		namespaced, err := p.namespaced.ByNamespace(pvcNamespace).List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, sortedByName(namespaced)...)
+	}
+	cluster, err := p.cluster.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	objects = append(objects, sortedByName(cluster)...)
+
+	var tags []string
+	for _, object := range objects {
+		u, ok := object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		var policy volumeTagPolicy
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &policy); err != nil {
+			klog.ErrorS(err, "Ignoring invalid volume tag policy", "kind", u.GetKind(), "namespace", u.GetNamespace(), "name", u.GetName())
+			continue
+		}
+		selector := labels.Everything()
+		if policy.Spec.Selector != nil {
+			if selector, err = metav1.LabelSelectorAsSelector(policy.Spec.Selector); err != nil {
+				klog.ErrorS(err, "Ignoring volume tag policy with invalid selector", "kind", u.GetKind(), "namespace", u.GetNamespace(), "name", u.GetName())
+				continue
+			}
+		}
+		if !selector.Matches(labels.Set(pvcLabels)) {
+			continue
+		}
+		policyTags := policy.Spec.VolumeTags
+		if snapshot {
+			policyTags = policy.Spec.SnapshotTags
+		}
+		klog.V(4).InfoS("Applying volume tag policy", "kind", u.GetKind(), "namespace", u.GetNamespace(), "name", u.GetName(), "snapshot", snapshot)
+		for _, key := range slices.Sorted(maps.Keys(policyTags)) {
+			tags = append(tags, key+"="+policyTags[key])
+		}
+	}
+	return tags, nil
+}
+
+// snapshotSource returns the name of the PVC a VolumeSnapshot was taken from.
+func (p *volumeTagPolicies) snapshotSource(ctx context.Context, namespace, name string) (string, error) {
+	snapshot, err := p.client.Resource(volumeSnapshotResource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("could not get VolumeSnapshot %s/%s: %w", namespace, name, err)
+	}
+	pvcName, _, err := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	return pvcName, err
+}
+
+func sortedByName(objects []runtime.Object) []runtime.Object {
+	return slices.SortedFunc(slices.Values(objects), func(a, b runtime.Object) int {
+		return strings.Compare(a.(metav1.Object).GetName(), b.(metav1.Object).GetName())
+	})
+}
+
+// volumeTagPolicyTags returns the tags of the volume tag policies that select the PVC of a volume.
+func (d *ControllerService) volumeTagPolicyTags(props *template.PVProps) ([]string, error) {
+	tags, err := d.tagPolicies.tags(props.PVCNamespace, props.PVCLabels, false)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "Could not apply volume tag policies: %v", err)
+	}
+	return tags, nil
+}
+
+// snapshotTagPolicyTags returns the tags of the volume tag policies that select the PVC a snapshot is taken from.
+func (d *ControllerService) snapshotTagPolicyTags(ctx context.Context, props *template.VolumeSnapshotProps) ([]string, error) {
+	if d.tagPolicies == nil {
+		return nil, nil
+	}
+	pvProps := &template.PVProps{PVCNamespace: props.VolumeSnapshotNamespace}
+	if props.VolumeSnapshotNamespace != "" && props.VolumeSnapshotName != "" {
+		pvcName, err := d.tagPolicies.snapshotSource(ctx, props.VolumeSnapshotNamespace, props.VolumeSnapshotName)
+		if err == nil && pvcName != "" {
+			pvProps.PVCName = pvcName
+			err = d.pvcMetadata.load(ctx, pvProps)
+		}
+		if err != nil {
+			if !d.options.WarnOnInvalidTag {
+				return nil, status.Errorf(codes.InvalidArgument, "Error reading the PVC of the snapshot for volume tag policies: %v", err)
+			}
+			klog.InfoS("Unable to read the PVC of the snapshot for volume tag policies", "namespace", props.VolumeSnapshotNamespace, "name", props.VolumeSnapshotName, "err", err)
+		}
+	}
+	tags, err := d.tagPolicies.tags(pvProps.PVCNamespace, pvProps.PVCLabels, true)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "Could not apply volume tag policies: %v", err)
+	}
+	return tags, nil
+}
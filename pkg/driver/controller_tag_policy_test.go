@@ -0,0 +1,182 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver/internal"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newVolumeTagPolicy(kind, namespace, name string, spec map[string]any) *unstructured.Unstructured {
+	policy := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "ebs.csi.aws.com/v1alpha1",
+		"kind":       kind,
+		"metadata":   map[string]any{"name": name},
+		"spec":       spec,
+	}}
+	policy.SetNamespace(namespace)
+	return policy
+}
+
+func newTestVolumeTagPolicies(t *testing.T) *volumeTagPolicies {
+	t.Helper()
+	snapshot := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "snapshot.storage.k8s.io/v1",
+		"kind":       "VolumeSnapshot",
+		"metadata":   map[string]any{"namespace": "default", "name": "data-snapshot"},
+		"spec":       map[string]any{"source": map[string]any{"persistentVolumeClaimName": "data"}},
+	}}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			volumeTagPolicyResource:        "VolumeTagPolicyList",
+			clusterVolumeTagPolicyResource: "ClusterVolumeTagPolicyList",
+			volumeSnapshotResource:         "VolumeSnapshotList",
+		},
+		snapshot,
+		newVolumeTagPolicy("VolumeTagPolicy", "default", "b-storage", map[string]any{
+			"selector":     map[string]any{"matchLabels": map[string]any{"team": "storage"}},
+			"volumeTags":   map[string]any{"team": "{{ .PVCLabels.team }}", "backup": "daily"},
+			"snapshotTags": map[string]any{"retention": "30d"},
+		}),
+		newVolumeTagPolicy("VolumeTagPolicy", "default", "a-all", map[string]any{
+			"volumeTags": map[string]any{"backup": "weekly", "namespace": "default"},
+		}),
+		newVolumeTagPolicy("VolumeTagPolicy", "other", "other", map[string]any{
+			"volumeTags": map[string]any{"namespace": "other"},
+		}),
+		newVolumeTagPolicy("VolumeTagPolicy", "default", "invalid", map[string]any{
+			"selector":   map[string]any{"matchExpressions": []any{map[string]any{"key": "team", "operator": "Invalid"}}},
+			"volumeTags": map[string]any{"invalid": "true"},
+		}),
+		newVolumeTagPolicy("ClusterVolumeTagPolicy", "", "cost", map[string]any{
+			"volumeTags":   map[string]any{"cost-center": "1234", "backup": "hourly"},
+			"snapshotTags": map[string]any{"cost-center": "1234"},
+		}),
+	)
+	p := newVolumeTagPolicyWatch(client)
+	require.True(t, cache.WaitForCacheSync(t.Context().Done(), p.hasSynced...))
+	return p
+}
+
+func TestVolumeTagPolicies(t *testing.T) {
+	var nilPolicies *volumeTagPolicies
+	tags, err := nilPolicies.tags("default", nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+	assert.Nil(t, newVolumeTagPolicies(fake.NewClientset(), &Options{}))
+	assert.Nil(t, newVolumeTagPolicies(nil, &Options{VolumeTagPolicies: true}))
+
+	notSynced := &volumeTagPolicies{hasSynced: []cache.InformerSynced{func() bool { return false }}}
+	_, err = notSynced.tags("default", nil, false)
+	require.ErrorIs(t, err, errVolumeTagPoliciesNotSynced)
+
+	p := newTestVolumeTagPolicies(t)
+
+	// Namespace policies by name, then cluster policies, so that later policies override earlier ones
+	tags, err = p.tags("default", map[string]string{"team": "storage"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"backup=weekly", "namespace=default",
+		"backup=daily", "team={{ .PVCLabels.team }}",
+		"backup=hourly", "cost-center=1234",
+	}, tags)
+
+	tags, err = p.tags("default", map[string]string{"team": "web"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cost-center=1234"}, tags)
+
+	tags, err = p.tags("", nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup=hourly", "cost-center=1234"}, tags, "only cluster policies apply without PVC namespace")
+
+	pvcName, err := p.snapshotSource(t.Context(), "default", "data-snapshot")
+	require.NoError(t, err)
+	assert.Equal(t, "data", pvcName)
+	_, err = p.snapshotSource(t.Context(), "default", "missing")
+	require.Error(t, err)
+}
+
+func TestCreateVolumeTagPolicies(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	mockCloud.EXPECT().CreateDisk(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+		func(_ context.Context, volumeName string, opts *cloud.DiskOptions) (*cloud.Disk, error) {
+			assert.Equal(t, "storage", opts.Tags["team"])
+			assert.Equal(t, "hourly", opts.Tags["backup"], "policies must override the tags of the StorageClass")
+			assert.Equal(t, "1234", opts.Tags["cost-center"])
+			return &cloud.Disk{VolumeID: volumeName, AvailabilityZone: expZone, CapacityGiB: 1}, nil
+		})
+	d := &ControllerService{
+		cloud:       mockCloud,
+		inFlight:    internal.NewInFlight(),
+		options:     &Options{},
+		pvcMetadata: newPVCMetadataReader(fake.NewClientset(newLabeledPVC()), &Options{}),
+		tagPolicies: newTestVolumeTagPolicies(t),
+	}
+	_, err := d.CreateVolume(t.Context(), &csi.CreateVolumeRequest{
+		Name:          "vol-test",
+		CapacityRange: &csi.CapacityRange{RequiredBytes: util.GiB},
+		VolumeCapabilities: []*csi.VolumeCapability{{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		}},
+		Parameters: map[string]string{
+			PVCNamespaceKey:     "default",
+			PVCNameKey:          "data",
+			TagKeyPrefix + "_1": "backup=never",
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestSnapshotTagPolicyTags(t *testing.T) {
+	d := &ControllerService{options: &Options{}}
+	tags, err := d.snapshotTagPolicyTags(t.Context(), &template.VolumeSnapshotProps{VolumeSnapshotNamespace: "default", VolumeSnapshotName: "data-snapshot"})
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+
+	d.tagPolicies = newTestVolumeTagPolicies(t)
+	d.pvcMetadata = newPVCMetadataReader(fake.NewClientset(newLabeledPVC()), &Options{})
+	tags, err = d.snapshotTagPolicyTags(t.Context(), &template.VolumeSnapshotProps{VolumeSnapshotNamespace: "default", VolumeSnapshotName: "data-snapshot"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"retention=30d", "cost-center=1234"}, tags)
+
+	_, err = d.snapshotTagPolicyTags(t.Context(), &template.VolumeSnapshotProps{VolumeSnapshotNamespace: "default", VolumeSnapshotName: "missing"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	d.options.WarnOnInvalidTag = true
+	tags, err = d.snapshotTagPolicyTags(t.Context(), &template.VolumeSnapshotProps{VolumeSnapshotNamespace: "default", VolumeSnapshotName: "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cost-center=1234"}, tags)
+}
@@ -24,6 +24,7 @@ import (
 	"math/rand"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -42,6 +43,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -635,6 +637,7 @@ func TestCreateVolume(t *testing.T) {
 
 				mockCloud := cloud.NewMockCloud(mockCtl)
 				mockCloud.EXPECT().GetDiskByID(gomock.Eq(ctx), gomock.Eq("volume-id")).Return(mockSourceDisk, nil)
+				mockCloud.EXPECT().ResolveKMSKey(gomock.Eq(ctx), gomock.Eq(req.GetParameters()[KmsKeyIDKey])).Return(req.GetParameters()[KmsKeyIDKey], nil)
 				awsDriver := ControllerService{
 					cloud:    mockCloud,
 					inFlight: internal.NewInFlight(),
@@ -646,6 +649,56 @@ func TestCreateVolume(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "clone success: alias of the KMS key of the source",
+			testFunc: func(t *testing.T) {
+				t.Helper()
+				keyARN := "arn:aws:kms:us-east-1:012345678910:key/abcd1234-a123-456a-a12b-a123b4cd56ef"
+				req := &csi.CreateVolumeRequest{
+					Name:               "random-vol-name",
+					CapacityRange:      stdCapRange,
+					VolumeCapabilities: stdVolCap,
+					Parameters: map[string]string{
+						EncryptedKey: "true",
+						KmsKeyIDKey:  "alias/ebs",
+					},
+					VolumeContentSource: &csi.VolumeContentSource{
+						Type: &csi.VolumeContentSource_Volume{
+							Volume: &csi.VolumeContentSource_VolumeSource{
+								VolumeId: "volume-id",
+							},
+						},
+					},
+				}
+
+				ctx := t.Context()
+
+				mockSourceDisk := &cloud.Disk{
+					VolumeID:         testSourceVolID,
+					AvailabilityZone: expZone,
+					KmsKeyID:         keyARN,
+				}
+
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockCloud := cloud.NewMockCloud(mockCtl)
+				mockCloud.EXPECT().GetDiskByID(gomock.Eq(ctx), gomock.Eq("volume-id")).Return(mockSourceDisk, nil)
+				mockCloud.EXPECT().ResolveKMSKey(gomock.Eq(ctx), gomock.Eq("alias/ebs")).Return(keyARN, nil)
+				mockCloud.EXPECT().CreateDisk(gomock.Eq(ctx), gomock.Eq(req.GetName()), gomock.Any()).Return(&cloud.Disk{
+					VolumeID:         "vol-test",
+					CapacityGiB:      1,
+					AvailabilityZone: expZone,
+				}, nil)
+				awsDriver := ControllerService{
+					cloud:    mockCloud,
+					inFlight: internal.NewInFlight(),
+					options:  &Options{},
+				}
+				_, err := awsDriver.CreateVolume(ctx, req)
+				require.NoError(t, err)
+			},
+		},
 		{
 			name: "success AZ-ID",
 			testFunc: func(t *testing.T) {
@@ -820,6 +873,7 @@ func TestCreateVolume(t *testing.T) {
 				defer mockCtl.Finish()
 
 				mockCloud := cloud.NewMockCloud(mockCtl)
+				mockCloud.EXPECT().GetSnapshotByID(gomock.Eq(ctx), gomock.Any()).Return(&cloud.Snapshot{ReadyToUse: true}, nil)
 				expectedOpts := &cloud.DiskOptions{
 					CapacityBytes: stdVolSize,
 					SnapshotID:    req.GetVolumeContentSource().GetSnapshot().GetSnapshotId(),
@@ -852,6 +906,9 @@ func TestCreateVolume(t *testing.T) {
 				if rsp.GetVolume().GetContentSource().GetSnapshot().GetSnapshotId() != "snapshot-id" {
 					t.Errorf("Unexpected snapshot ID: %q", snapshotID)
 				}
+				if rsp.GetVolume().GetVolumeContext()[LazilyLoadedKey] != trueStr {
+					t.Errorf("Expected volume context %s=%s, got %v", LazilyLoadedKey, trueStr, rsp.GetVolume().GetVolumeContext())
+				}
 			},
 		},
 		{
@@ -885,6 +942,7 @@ func TestCreateVolume(t *testing.T) {
 				defer mockCtl.Finish()
 
 				mockCloud := cloud.NewMockCloud(mockCtl)
+				mockCloud.EXPECT().GetSnapshotByID(gomock.Eq(ctx), gomock.Any()).Return(&cloud.Snapshot{ReadyToUse: true}, nil)
 				expectedOpts := &cloud.DiskOptions{
 					CapacityBytes: stdVolSize,
 					SnapshotID:    req.GetVolumeContentSource().GetSnapshot().GetSnapshotId(),
@@ -943,6 +1001,7 @@ func TestCreateVolume(t *testing.T) {
 				defer mockCtl.Finish()
 
 				mockCloud := cloud.NewMockCloud(mockCtl)
+				mockCloud.EXPECT().GetSnapshotByID(gomock.Eq(ctx), gomock.Any()).Return(&cloud.Snapshot{ReadyToUse: true}, nil)
 				expectedOpts := &cloud.DiskOptions{
 					CapacityBytes: stdVolSize,
 					SnapshotID:    req.GetVolumeContentSource().GetSnapshot().GetSnapshotId(),
@@ -994,6 +1053,7 @@ func TestCreateVolume(t *testing.T) {
 				defer mockCtl.Finish()
 
 				mockCloud := cloud.NewMockCloud(mockCtl)
+				mockCloud.EXPECT().GetSnapshotByID(gomock.Any(), gomock.Any()).Return(&cloud.Snapshot{ReadyToUse: true}, nil)
 				expectedOpts := &cloud.DiskOptions{
 					CapacityBytes:            stdVolSize,
 					SnapshotID:               req.GetVolumeContentSource().GetSnapshot().GetSnapshotId(),
@@ -1969,6 +2029,7 @@ func TestCreateVolume(t *testing.T) {
 				defer mockCtl.Finish()
 
 				mockCloud := cloud.NewMockCloud(mockCtl)
+				mockCloud.EXPECT().GetSnapshotByID(gomock.Eq(ctx), gomock.Any()).Return(&cloud.Snapshot{ReadyToUse: true}, nil).Times(2)
 				expectedOpts := &cloud.DiskOptions{
 					CapacityBytes: stdVolSize,
 					SnapshotID:    req.GetVolumeContentSource().GetSnapshot().GetSnapshotId(),
@@ -2691,6 +2752,144 @@ func TestCreateVolume(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "success multi-attach parameter",
+			testFunc: func(t *testing.T) {
+				t.Helper()
+				req := &csi.CreateVolumeRequest{
+					Name:               "random-vol-name",
+					CapacityRange:      stdCapRange,
+					VolumeCapabilities: stdVolCap,
+					Parameters:         map[string]string{VolumeTypeKey: cloud.VolumeTypeIO2, MultiAttachKey: "true"},
+				}
+
+				ctx := t.Context()
+
+				mockDisk := &cloud.Disk{
+					VolumeID:         req.GetName(),
+					AvailabilityZone: expZone,
+					CapacityGiB:      util.BytesToGiB(stdVolSize),
+				}
+
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockCloud := cloud.NewMockCloud(mockCtl)
+				expectedOpts := &cloud.DiskOptions{
+					CapacityBytes:      stdVolSize,
+					VolumeType:         cloud.VolumeTypeIO2,
+					MultiAttachEnabled: true,
+					Tags: map[string]string{
+						cloud.VolumeNameTagKey:   req.GetName(),
+						cloud.AwsEbsDriverTagKey: "true",
+					},
+				}
+				mockCloud.EXPECT().CreateDisk(gomock.Eq(ctx), gomock.Eq(req.GetName()), gomock.Eq(expectedOpts)).Return(mockDisk, nil)
+
+				awsDriver := ControllerService{
+					cloud:    mockCloud,
+					inFlight: internal.NewInFlight(),
+					options:  &Options{},
+				}
+
+				resp, err := awsDriver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if resp.GetVolume().GetVolumeContext()[MultiAttachKey] != trueStr {
+					t.Fatalf("Expected volume context %s=true, got %v", MultiAttachKey, resp.GetVolume().GetVolumeContext())
+				}
+			},
+		},
+		{
+			name: "success io2 volume requiring Block Express",
+			testFunc: func(t *testing.T) {
+				t.Helper()
+				req := &csi.CreateVolumeRequest{
+					Name:               "random-vol-name",
+					CapacityRange:      &csi.CapacityRange{RequiredBytes: util.GiBToBytes(20000)},
+					VolumeCapabilities: stdVolCap,
+					Parameters:         map[string]string{VolumeTypeKey: cloud.VolumeTypeIO2},
+				}
+
+				ctx := t.Context()
+
+				mockDisk := &cloud.Disk{
+					VolumeID:             req.GetName(),
+					AvailabilityZone:     expZone,
+					CapacityGiB:          20000,
+					RequiresBlockExpress: true,
+				}
+
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockCloud := cloud.NewMockCloud(mockCtl)
+				mockCloud.EXPECT().CreateDisk(gomock.Eq(ctx), gomock.Eq(req.GetName()), gomock.Any()).Return(mockDisk, nil)
+
+				awsDriver := ControllerService{
+					cloud:    mockCloud,
+					inFlight: internal.NewInFlight(),
+					options:  &Options{},
+				}
+
+				resp, err := awsDriver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if resp.GetVolume().GetVolumeContext()[RequiresBlockExpressKey] != trueStr {
+					t.Fatalf("Expected volume context %s=true, got %v", RequiresBlockExpressKey, resp.GetVolume().GetVolumeContext())
+				}
+			},
+		},
+		{
+			name: "fail multi-attach parameter - unsupported volume type",
+			testFunc: func(t *testing.T) {
+				t.Helper()
+				req := &csi.CreateVolumeRequest{
+					Name:               "random-vol-name",
+					CapacityRange:      stdCapRange,
+					VolumeCapabilities: stdVolCap,
+					Parameters:         map[string]string{VolumeTypeKey: cloud.VolumeTypeGP3, MultiAttachKey: "true"},
+				}
+
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				awsDriver := ControllerService{
+					cloud:    cloud.NewMockCloud(mockCtl),
+					inFlight: internal.NewInFlight(),
+					options:  &Options{},
+				}
+
+				_, err := awsDriver.CreateVolume(t.Context(), req)
+				checkExpectedErrorCode(t, err, codes.InvalidArgument)
+			},
+		},
+		{
+			name: "fail multi-attach parameter - disabled with multi-node multi-writer capability",
+			testFunc: func(t *testing.T) {
+				t.Helper()
+				req := &csi.CreateVolumeRequest{
+					Name:               "random-vol-name",
+					CapacityRange:      stdCapRange,
+					VolumeCapabilities: multiAttachVolCap,
+					Parameters:         map[string]string{VolumeTypeKey: cloud.VolumeTypeIO2, MultiAttachKey: "false"},
+				}
+
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				awsDriver := ControllerService{
+					cloud:    cloud.NewMockCloud(mockCtl),
+					inFlight: internal.NewInFlight(),
+					options:  &Options{},
+				}
+
+				_, err := awsDriver.CreateVolume(t.Context(), req)
+				checkExpectedErrorCode(t, err, codes.InvalidArgument)
+			},
+		},
 		{
 			name: "fail multi-attach - invalid mount capability",
 			testFunc: func(t *testing.T) {
@@ -2868,6 +3067,28 @@ func TestCreateVolumeWithFormattingParameters(t *testing.T) {
 			},
 			errExpected: false,
 		},
+		{
+			name: "success with ext4 fscrypt",
+			formattingOptionParameters: map[string]string{
+				Ext4FscryptKey: "true",
+			},
+			errExpected: false,
+		},
+		{
+			name: "failure with ext4 fscrypt and xfs",
+			formattingOptionParameters: map[string]string{
+				FSTypeKey:      FSTypeXfs,
+				Ext4FscryptKey: "true",
+			},
+			errExpected: true,
+		},
+		{
+			name: "success with xfs project quota",
+			formattingOptionParameters: map[string]string{
+				XfsProjectQuotaKey: "true",
+			},
+			errExpected: false,
+		},
 		{
 			name: "failure with IOPSPerGBKey",
 			formattingOptionParameters: map[string]string{
@@ -3468,6 +3689,65 @@ func TestPickAvailabilityZone(t *testing.T) {
 	}
 }
 
+func TestVolumeTypeTopologies(t *testing.T) {
+	zonal := func(zone string) *csi.Topology {
+		return &csi.Topology{Segments: map[string]string{WellKnownZoneTopologyKey: zone}}
+	}
+	testCases := []struct {
+		name        string
+		requirement *csi.TopologyRequirement
+		volumeType  string
+		expZone     string
+	}{
+		{
+			name: "io2 skips preferred local zone",
+			requirement: &csi.TopologyRequirement{
+				Requisite: []*csi.Topology{zonal("us-west-2-lax-1a"), zonal("us-west-2a")},
+				Preferred: []*csi.Topology{zonal("us-west-2-lax-1a"), zonal("us-west-2a")},
+			},
+			volumeType: cloud.VolumeTypeIO2,
+			expZone:    "us-west-2a",
+		},
+		{
+			name: "gp3 keeps preferred local zone",
+			requirement: &csi.TopologyRequirement{
+				Preferred: []*csi.Topology{zonal("us-west-2-lax-1a"), zonal("us-west-2a")},
+			},
+			volumeType: cloud.VolumeTypeGP3,
+			expZone:    "us-west-2-lax-1a",
+		},
+		{
+			name: "gp3 skips wavelength zone",
+			requirement: &csi.TopologyRequirement{
+				Preferred: []*csi.Topology{zonal("us-east-1-wl1-bos-wlz-1"), zonal("us-east-1a")},
+			},
+			volumeType: cloud.VolumeTypeGP3,
+			expZone:    "us-east-1a",
+		},
+		{
+			name: "default type keeps local zone",
+			requirement: &csi.TopologyRequirement{
+				Preferred: []*csi.Topology{zonal("us-west-2-lax-1a"), zonal("us-west-2a")},
+			},
+			expZone: "us-west-2-lax-1a",
+		},
+		{
+			name: "no zone supports the type",
+			requirement: &csi.TopologyRequirement{
+				Requisite: []*csi.Topology{zonal("us-west-2-lax-1a")},
+			},
+			volumeType: cloud.VolumeTypeIO2,
+			expZone:    "us-west-2-lax-1a",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expZone, pickAvailabilityZone(volumeTypeTopologies(tc.requirement, tc.volumeType)))
+		})
+	}
+}
+
 func TestGetOutpostArn(t *testing.T) {
 	expRawOutpostArn := testOutpostARN
 	outpostArn, _ := arn.Parse(strings.ReplaceAll(expRawOutpostArn, "outpost/", ""))
@@ -3563,6 +3843,22 @@ func TestBuildOutpostArn(t *testing.T) {
 			awsAccountID: "111111111111",
 			expectedArn:  expRawOutpostArn,
 		},
+		{
+			name:         "GovCloud partition",
+			awsPartition: "aws-us-gov",
+			awsRegion:    "us-gov-west-1",
+			awsOutpostID: "op-0aaa000a0aaaa00a0",
+			awsAccountID: "111111111111",
+			expectedArn:  "arn:aws-us-gov:outposts:us-gov-west-1:111111111111:outpost/op-0aaa000a0aaaa00a0",
+		},
+		{
+			name:         "China partition",
+			awsPartition: "aws-cn",
+			awsRegion:    "cn-north-1",
+			awsOutpostID: "op-0aaa000a0aaaa00a0",
+			awsAccountID: "111111111111",
+			expectedArn:  "arn:aws-cn:outposts:cn-north-1:111111111111:outpost/op-0aaa000a0aaaa00a0",
+		},
 		{
 			name:         "partition is missing",
 			awsRegion:    "us-west-2",
@@ -5145,44 +5441,244 @@ func TestListSnapshots(t *testing.T) {
 	}
 }
 
-func TestControllerPublishVolume(t *testing.T) {
-	stdVolCap := &csi.VolumeCapability{
-		AccessType: &csi.VolumeCapability_Mount{
-			Mount: &csi.VolumeCapability_MountVolume{},
-		},
-		AccessMode: &csi.VolumeCapability_AccessMode{
-			Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
-		},
-	}
-
+func TestListVolumes(t *testing.T) {
 	testCases := []struct {
-		name             string
-		volumeID         string
-		nodeID           string
-		volumeCapability *csi.VolumeCapability
-		volumeContext    map[string]string
-		mockAttach       func(mockCloud *cloud.MockCloud, ctx context.Context, volumeID string, nodeID string)
-		expResp          *csi.ControllerPublishVolumeResponse
-		errorCode        codes.Code
-		setupFunc        func(ControllerService *ControllerService)
+		name        string
+		req         *csi.ListVolumesRequest
+		clusterID   string
+		expFilter   map[string]string
+		response    *cloud.ListDisksResponse
+		listErr     error
+		expResponse *csi.ListVolumesResponse
+		expCode     codes.Code
 	}{
 		{
-			name:             "AttachDisk successfully with valid volume ID, node ID, and volume capability",
-			volumeID:         "vol-test",
-			nodeID:           expInstanceID,
-			volumeCapability: stdVolCap,
-			mockAttach: func(mockCloud *cloud.MockCloud, ctx context.Context, volumeID string, nodeID string) {
-				mockCloud.EXPECT().AttachDisk(gomock.Eq(ctx), volumeID, gomock.Eq(nodeID)).Return(expDevicePath, nil)
+			name:      "success",
+			req:       &csi.ListVolumesRequest{MaxEntries: 10, StartingToken: "token-1"},
+			expFilter: map[string]string{cloud.AwsEbsDriverTagKey: isManagedByDriver},
+			response: &cloud.ListDisksResponse{
+				Disks: []*cloud.Disk{
+					{VolumeID: "vol-attached", CapacityGiB: 10, AvailabilityZone: expZone, Attachments: []string{"i-test"}},
+					{VolumeID: "vol-detached", CapacityGiB: 20, AvailabilityZone: expZone},
+				},
+				NextToken: "token-2",
 			},
-			expResp: &csi.ControllerPublishVolumeResponse{
-				PublishContext: map[string]string{DevicePathKey: expDevicePath},
+			expResponse: &csi.ListVolumesResponse{
+				Entries: []*csi.ListVolumesResponse_Entry{
+					{
+						Volume: &csi.Volume{
+							VolumeId:           "vol-attached",
+							CapacityBytes:      10 * util.GiB,
+							AccessibleTopology: []*csi.Topology{{Segments: map[string]string{WellKnownZoneTopologyKey: expZone}}},
+						},
+						Status: &csi.ListVolumesResponse_VolumeStatus{PublishedNodeIds: []string{"i-test"}},
+					},
+					{
+						Volume: &csi.Volume{
+							VolumeId:           "vol-detached",
+							CapacityBytes:      20 * util.GiB,
+							AccessibleTopology: []*csi.Topology{{Segments: map[string]string{WellKnownZoneTopologyKey: expZone}}},
+						},
+						Status: &csi.ListVolumesResponse_VolumeStatus{},
+					},
+				},
+				NextToken: "token-2",
 			},
-			errorCode: codes.OK,
 		},
 		{
-			name:             "ResourceExhausted error when attachment limit is exceeded",
-			volumeID:         "vol-test",
-			nodeID:           expInstanceID,
+			name:      "success: volumes of the cluster",
+			req:       &csi.ListVolumesRequest{},
+			clusterID: "test-cluster",
+			expFilter: map[string]string{
+				cloud.AwsEbsDriverTagKey:                    isManagedByDriver,
+				ResourceLifecycleTagPrefix + "test-cluster": ResourceLifecycleOwned,
+			},
+			response:    &cloud.ListDisksResponse{},
+			expResponse: &csi.ListVolumesResponse{Entries: []*csi.ListVolumesResponse_Entry{}},
+		},
+		{
+			name:      "fail: invalid max entries",
+			req:       &csi.ListVolumesRequest{MaxEntries: 4},
+			expFilter: map[string]string{cloud.AwsEbsDriverTagKey: isManagedByDriver},
+			listErr:   cloud.ErrInvalidMaxResults,
+			expCode:   codes.InvalidArgument,
+		},
+		{
+			name:      "fail: invalid starting token",
+			req:       &csi.ListVolumesRequest{StartingToken: "invalid-token"},
+			expFilter: map[string]string{cloud.AwsEbsDriverTagKey: isManagedByDriver},
+			listErr:   cloud.ErrInvalidNextToken,
+			expCode:   codes.Aborted,
+		},
+		{
+			name:      "fail: EC2 error",
+			req:       &csi.ListVolumesRequest{},
+			expFilter: map[string]string{cloud.AwsEbsDriverTagKey: isManagedByDriver},
+			listErr:   errors.New("DescribeVolumes error"),
+			expCode:   codes.Internal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			mockCloud.EXPECT().ListDisksPage(gomock.Any(), tc.expFilter, tc.req.GetMaxEntries(), tc.req.GetStartingToken()).Return(tc.response, tc.listErr)
+
+			d := &ControllerService{cloud: mockCloud, options: &Options{KubernetesClusterID: tc.clusterID}}
+			resp, err := d.ListVolumes(t.Context(), tc.req)
+			if tc.expCode != codes.OK {
+				assert.Equal(t, tc.expCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(tc.expResponse, resp), "unexpected response %v", resp)
+		})
+	}
+}
+
+func TestControllerGetVolume(t *testing.T) {
+	disk := &cloud.Disk{VolumeID: "vol-test", CapacityGiB: 10, AvailabilityZone: expZone, Attachments: []string{"i-test"}}
+	expVolume := &csi.Volume{
+		VolumeId:           "vol-test",
+		CapacityBytes:      10 * util.GiB,
+		AccessibleTopology: []*csi.Topology{{Segments: map[string]string{WellKnownZoneTopologyKey: expZone}}},
+	}
+	testCases := []struct {
+		name         string
+		volumeID     string
+		disk         *cloud.Disk
+		getErr       error
+		health       map[string]*cloud.VolumeHealth
+		healthErr    error
+		expAbnormal  bool
+		expMessage   string
+		expCode      codes.Code
+		expectHealth bool
+	}{
+		{
+			name:         "success: ok volume",
+			volumeID:     "vol-test",
+			disk:         disk,
+			health:       map[string]*cloud.VolumeHealth{"vol-test": {Status: types.VolumeStatusInfoStatusOk, IOEnabled: true}},
+			expMessage:   "EBS reports volume vol-test as ok (I/O enabled: true)",
+			expectHealth: true,
+		},
+		{
+			name:         "success: impaired volume",
+			volumeID:     "vol-test",
+			disk:         disk,
+			health:       map[string]*cloud.VolumeHealth{"vol-test": {Status: types.VolumeStatusInfoStatusImpaired, IOEnabled: true, Events: "potential-data-inconsistency: I/O disabled"}},
+			expAbnormal:  true,
+			expMessage:   "EBS reports volume vol-test as impaired (I/O enabled: true): potential-data-inconsistency: I/O disabled",
+			expectHealth: true,
+		},
+		{
+			name:         "success: I/O disabled",
+			volumeID:     "vol-test",
+			disk:         disk,
+			health:       map[string]*cloud.VolumeHealth{"vol-test": {Status: types.VolumeStatusInfoStatusWarning}},
+			expAbnormal:  true,
+			expMessage:   "EBS reports volume vol-test as warning (I/O enabled: false)",
+			expectHealth: true,
+		},
+		{
+			name:         "success: status not reported",
+			volumeID:     "vol-test",
+			disk:         disk,
+			health:       map[string]*cloud.VolumeHealth{},
+			expMessage:   "EBS does not report the status of volume vol-test yet",
+			expectHealth: true,
+		},
+		{
+			name:    "fail: no volume ID",
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:     "fail: node-local volume",
+			volumeID: NodeLocalVolumeHandlePrefix + "test",
+			expCode:  codes.InvalidArgument,
+		},
+		{
+			name:     "fail: volume not found",
+			volumeID: "vol-test",
+			getErr:   cloud.ErrNotFound,
+			expCode:  codes.NotFound,
+		},
+		{
+			name:         "fail: DescribeVolumeStatus error",
+			volumeID:     "vol-test",
+			disk:         disk,
+			healthErr:    errors.New("DescribeVolumeStatus error"),
+			expCode:      codes.Internal,
+			expectHealth: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			if tc.disk != nil || tc.getErr != nil {
+				mockCloud.EXPECT().GetDiskByID(gomock.Any(), tc.volumeID).Return(tc.disk, tc.getErr)
+			}
+			if tc.expectHealth {
+				mockCloud.EXPECT().GetVolumeHealth(gomock.Any(), []string{tc.volumeID}).Return(tc.health, tc.healthErr)
+			}
+
+			d := &ControllerService{cloud: mockCloud, options: &Options{}}
+			resp, err := d.ControllerGetVolume(t.Context(), &csi.ControllerGetVolumeRequest{VolumeId: tc.volumeID})
+			if tc.expCode != codes.OK {
+				assert.Equal(t, tc.expCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(expVolume, resp.GetVolume()), "unexpected volume %v", resp.GetVolume())
+			assert.Equal(t, []string{"i-test"}, resp.GetStatus().GetPublishedNodeIds())
+			assert.Equal(t, tc.expAbnormal, resp.GetStatus().GetVolumeCondition().GetAbnormal())
+			assert.Equal(t, tc.expMessage, resp.GetStatus().GetVolumeCondition().GetMessage())
+		})
+	}
+}
+
+func TestControllerPublishVolume(t *testing.T) {
+	stdVolCap := &csi.VolumeCapability{
+		AccessType: &csi.VolumeCapability_Mount{
+			Mount: &csi.VolumeCapability_MountVolume{},
+		},
+		AccessMode: &csi.VolumeCapability_AccessMode{
+			Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		volumeID         string
+		nodeID           string
+		volumeCapability *csi.VolumeCapability
+		volumeContext    map[string]string
+		mockAttach       func(mockCloud *cloud.MockCloud, ctx context.Context, volumeID string, nodeID string)
+		expResp          *csi.ControllerPublishVolumeResponse
+		errorCode        codes.Code
+		setupFunc        func(ControllerService *ControllerService)
+	}{
+		{
+			name:             "AttachDisk successfully with valid volume ID, node ID, and volume capability",
+			volumeID:         "vol-test",
+			nodeID:           expInstanceID,
+			volumeCapability: stdVolCap,
+			mockAttach: func(mockCloud *cloud.MockCloud, ctx context.Context, volumeID string, nodeID string) {
+				mockCloud.EXPECT().AttachDisk(gomock.Eq(ctx), volumeID, gomock.Eq(nodeID)).Return(expDevicePath, nil)
+			},
+			expResp: &csi.ControllerPublishVolumeResponse{
+				PublishContext: map[string]string{DevicePathKey: expDevicePath},
+			},
+			errorCode: codes.OK,
+		},
+		{
+			name:             "ResourceExhausted error when attachment limit is exceeded",
+			volumeID:         "vol-test",
+			nodeID:           expInstanceID,
 			volumeCapability: stdVolCap,
 			mockAttach: func(mockCloud *cloud.MockCloud, ctx context.Context, volumeID string, nodeID string) {
 				mockCloud.EXPECT().AttachDisk(gomock.Eq(ctx), gomock.Eq(volumeID), gomock.Eq(expInstanceID)).Return("", cloud.ErrLimitExceeded)
@@ -5202,6 +5698,76 @@ func TestControllerPublishVolume(t *testing.T) {
 			},
 			errorCode: codes.OK,
 		},
+		{
+			name:             "AttachDisk successfully with multi-attach volume on Nitro instance",
+			volumeID:         "vol-test",
+			nodeID:           expInstanceID,
+			volumeCapability: stdVolCap,
+			volumeContext:    map[string]string{MultiAttachKey: trueStr},
+			mockAttach: func(mockCloud *cloud.MockCloud, ctx context.Context, volumeID string, nodeID string) {
+				mockCloud.EXPECT().CheckMultiAttachSupport(gomock.Eq(ctx), gomock.Eq(nodeID)).Return(nil)
+				mockCloud.EXPECT().CheckAttachmentZone(gomock.Eq(ctx), gomock.Eq(volumeID), gomock.Eq(nodeID)).Return(nil)
+				mockCloud.EXPECT().AttachDisk(gomock.Eq(ctx), gomock.Eq(volumeID), gomock.Eq(nodeID)).Return(expDevicePath, nil)
+			},
+			expResp: &csi.ControllerPublishVolumeResponse{
+				PublishContext: map[string]string{DevicePathKey: expDevicePath},
+			},
+			errorCode: codes.OK,
+		},
+		{
+			name:             "FailedPrecondition error when multi-attach volume is published to instance in another zone",
+			volumeID:         "vol-test",
+			nodeID:           expInstanceID,
+			volumeCapability: stdVolCap,
+			volumeContext:    map[string]string{MultiAttachKey: trueStr},
+			mockAttach: func(mockCloud *cloud.MockCloud, ctx context.Context, volumeID string, nodeID string) {
+				mockCloud.EXPECT().CheckMultiAttachSupport(gomock.Eq(ctx), gomock.Eq(nodeID)).Return(nil)
+				mockCloud.EXPECT().CheckAttachmentZone(gomock.Eq(ctx), gomock.Eq(volumeID), gomock.Eq(nodeID)).Return(cloud.ErrZoneMismatch)
+				mockCloud.EXPECT().AttachDisk(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			errorCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "FailedPrecondition error when multi-attach volume is published to non-Nitro instance",
+			volumeID: "vol-test",
+			nodeID:   expInstanceID,
+			volumeCapability: &csi.VolumeCapability{
+				AccessType: &csi.VolumeCapability_Block{Block: &csi.VolumeCapability_BlockVolume{}},
+				AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER},
+			},
+			mockAttach: func(mockCloud *cloud.MockCloud, ctx context.Context, volumeID string, nodeID string) {
+				mockCloud.EXPECT().CheckMultiAttachSupport(gomock.Eq(ctx), gomock.Eq(nodeID)).Return(cloud.ErrMultiAttachNotSupported)
+				mockCloud.EXPECT().AttachDisk(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			errorCode: codes.FailedPrecondition,
+		},
+		{
+			name:             "AttachDisk successfully with io2 volume requiring Block Express on Nitro instance",
+			volumeID:         "vol-test",
+			nodeID:           expInstanceID,
+			volumeCapability: stdVolCap,
+			volumeContext:    map[string]string{RequiresBlockExpressKey: trueStr},
+			mockAttach: func(mockCloud *cloud.MockCloud, ctx context.Context, volumeID string, nodeID string) {
+				mockCloud.EXPECT().CheckBlockExpressSupport(gomock.Eq(ctx), gomock.Eq(nodeID)).Return(nil)
+				mockCloud.EXPECT().AttachDisk(gomock.Eq(ctx), gomock.Eq(volumeID), gomock.Eq(nodeID)).Return(expDevicePath, nil)
+			},
+			expResp: &csi.ControllerPublishVolumeResponse{
+				PublishContext: map[string]string{DevicePathKey: expDevicePath},
+			},
+			errorCode: codes.OK,
+		},
+		{
+			name:             "FailedPrecondition error when io2 volume requiring Block Express is published to non-Nitro instance",
+			volumeID:         "vol-test",
+			nodeID:           expInstanceID,
+			volumeCapability: stdVolCap,
+			volumeContext:    map[string]string{RequiresBlockExpressKey: trueStr},
+			mockAttach: func(mockCloud *cloud.MockCloud, ctx context.Context, volumeID string, nodeID string) {
+				mockCloud.EXPECT().CheckBlockExpressSupport(gomock.Eq(ctx), gomock.Eq(nodeID)).Return(cloud.ErrBlockExpressNotSupported)
+				mockCloud.EXPECT().AttachDisk(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			errorCode: codes.FailedPrecondition,
+		},
 		{
 			name:             "Success after restored volume is initialized if blockAttachUntilInitialized set",
 			volumeID:         "vol-test",
@@ -5644,6 +6210,167 @@ func TestControllerModifyVolume(t *testing.T) {
 	}
 }
 
+// TestControllerModifyVolumeIdempotency runs scripted sequences of ControllerModifyVolume calls for one volume.
+// The calls of a step are made concurrently, so they are coalesced; steps are made one after the other.
+func TestControllerModifyVolumeIdempotency(t *testing.T) {
+	const volumeID = "vol-test"
+	type step struct {
+		// params holds the mutable parameters of each concurrent call
+		params []map[string]string
+		// expCodes holds the codes the calls must return, in any order
+		expCodes []codes.Code
+	}
+	iops := func(value string) map[string]string { return map[string]string{ModificationKeyIOPS: value} }
+	resize := func(mockCloud *cloud.MockCloud, options any) *gomock.Call {
+		return mockCloud.EXPECT().ResizeOrModifyDisk(gomock.Any(), volumeID, int64(0), options)
+	}
+
+	testCases := []struct {
+		name     string
+		steps    []step
+		mockFunc func(*cloud.MockCloud)
+	}{
+		{
+			name: "success repeated identical calls",
+			steps: []step{
+				{params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.OK}},
+				{params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.OK}},
+			},
+			mockFunc: func(mockCloud *cloud.MockCloud) {
+				// The cloud does not modify a volume that already has the requested properties
+				resize(mockCloud, &cloud.ModifyDiskOptions{IOPS: 4000}).Return(int32(0), nil).Times(2)
+			},
+		},
+		{
+			name: "success concurrent identical calls coalesced into one modification",
+			steps: []step{
+				{params: []map[string]string{iops("4000"), iops("4000"), iops("4000")}, expCodes: []codes.Code{codes.OK, codes.OK, codes.OK}},
+			},
+			mockFunc: func(mockCloud *cloud.MockCloud) {
+				resize(mockCloud, &cloud.ModifyDiskOptions{IOPS: 4000}).Return(int32(0), nil)
+			},
+		},
+		{
+			name: "success concurrent calls for different properties merged",
+			steps: []step{
+				{params: []map[string]string{iops("4000"), {ModificationKeyThroughput: "500"}}, expCodes: []codes.Code{codes.OK, codes.OK}},
+			},
+			mockFunc: func(mockCloud *cloud.MockCloud) {
+				resize(mockCloud, &cloud.ModifyDiskOptions{IOPS: 4000, Throughput: 500}).Return(int32(0), nil)
+			},
+		},
+		{
+			name: "fail concurrent conflicting call aborted",
+			steps: []step{
+				{params: []map[string]string{iops("4000"), iops("5000")}, expCodes: []codes.Code{codes.OK, codes.Aborted}},
+			},
+			mockFunc: func(mockCloud *cloud.MockCloud) {
+				resize(mockCloud, gomock.Any()).Return(int32(0), nil)
+			},
+		},
+		{
+			name: "success conflicting call once the first completed",
+			steps: []step{
+				{params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.OK}},
+				{params: []map[string]string{iops("5000")}, expCodes: []codes.Code{codes.OK}},
+			},
+			mockFunc: func(mockCloud *cloud.MockCloud) {
+				gomock.InOrder(
+					resize(mockCloud, &cloud.ModifyDiskOptions{IOPS: 4000}).Return(int32(0), nil),
+					resize(mockCloud, &cloud.ModifyDiskOptions{IOPS: 5000}).Return(int32(0), nil),
+				)
+			},
+		},
+		{
+			name: "success retry after transient failure",
+			steps: []step{
+				{params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.Internal}},
+				{params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.OK}},
+			},
+			mockFunc: func(mockCloud *cloud.MockCloud) {
+				gomock.InOrder(
+					resize(mockCloud, &cloud.ModifyDiskOptions{IOPS: 4000}).Return(int32(0), errors.New("request timed out")),
+					resize(mockCloud, &cloud.ModifyDiskOptions{IOPS: 4000}).Return(int32(0), nil),
+				)
+			},
+		},
+		{
+			name: "fail concurrent calls all receive the error",
+			steps: []step{
+				{params: []map[string]string{iops("4000"), iops("4000")}, expCodes: []codes.Code{codes.NotFound, codes.NotFound}},
+			},
+			mockFunc: func(mockCloud *cloud.MockCloud) {
+				resize(mockCloud, &cloud.ModifyDiskOptions{IOPS: 4000}).Return(int32(0), cloud.ErrNotFound)
+			},
+		},
+		{
+			name: "fail invalid argument",
+			steps: []step{
+				{params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.InvalidArgument}},
+			},
+			mockFunc: func(mockCloud *cloud.MockCloud) {
+				resize(mockCloud, &cloud.ModifyDiskOptions{IOPS: 4000}).Return(int32(0), cloud.ErrInvalidArgument)
+			},
+		},
+		{
+			name: "fail modification limit exceeded",
+			steps: []step{
+				{params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.ResourceExhausted}},
+			},
+			mockFunc: func(mockCloud *cloud.MockCloud) {
+				resize(mockCloud, &cloud.ModifyDiskOptions{IOPS: 4000}).Return(int32(0), cloud.ErrLimitExceeded)
+			},
+		},
+		{
+			name: "success repeated identical tag calls",
+			steps: []step{
+				{params: []map[string]string{{ModificationAddTag + "_1": "key1=value1"}}, expCodes: []codes.Code{codes.OK}},
+				{params: []map[string]string{{ModificationAddTag + "_1": "key1=value1"}}, expCodes: []codes.Code{codes.OK}},
+			},
+			mockFunc: func(mockCloud *cloud.MockCloud) {
+				mockCloud.EXPECT().ModifyTags(gomock.Any(), volumeID, cloud.ModifyTagsOptions{
+					TagsToAdd:    map[string]string{"key1": "value1"},
+					TagsToDelete: []string{},
+				}).Return(nil).Times(2)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			tc.mockFunc(mockCloud)
+
+			// Long enough for the concurrent calls of a step to be coalesced
+			options := &Options{ModifyVolumeRequestHandlerTimeout: 200 * time.Millisecond}
+			awsDriver := ControllerService{
+				cloud:                 mockCloud,
+				inFlight:              internal.NewInFlight(),
+				options:               options,
+				modifyVolumeCoalescer: newModifyVolumeCoalescer(mockCloud, options),
+			}
+
+			for i, step := range tc.steps {
+				results := make([]codes.Code, len(step.params))
+				var wg sync.WaitGroup
+				for j, params := range step.params {
+					wg.Go(func() {
+						_, err := awsDriver.ControllerModifyVolume(t.Context(), &csi.ControllerModifyVolumeRequest{
+							VolumeId:          volumeID,
+							MutableParameters: params,
+						})
+						results[j] = status.Code(err)
+					})
+				}
+				wg.Wait()
+				assert.ElementsMatch(t, step.expCodes, results, "step %d", i)
+			}
+		})
+	}
+}
+
 func TestValidateVolumeCapabilities(t *testing.T) {
 	stdVolCap := []*csi.VolumeCapability{
 		{
@@ -5665,14 +6392,25 @@ func TestValidateVolumeCapabilities(t *testing.T) {
 			},
 		},
 	}
+	multiWriterMountVolCap := []*csi.VolumeCapability{
+		{
+			AccessType: &csi.VolumeCapability_Mount{
+				Mount: &csi.VolumeCapability_MountVolume{},
+			},
+			AccessMode: &csi.VolumeCapability_AccessMode{
+				Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+			},
+		},
+	}
 
 	testCases := []struct {
-		name      string
-		volumeID  string
-		volCaps   []*csi.VolumeCapability
-		setupFunc func(*ControllerService)
-		mockFunc  func(*cloud.MockCloud, context.Context, string)
-		expected  bool
+		name       string
+		volumeID   string
+		volCaps    []*csi.VolumeCapability
+		setupFunc  func(*ControllerService)
+		mockFunc   func(*cloud.MockCloud, context.Context, string)
+		expected   bool
+		expMessage string
 	}{
 		{
 			name:     "Success with regular volume",
@@ -5683,6 +6421,25 @@ func TestValidateVolumeCapabilities(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name:     "Success with multi-attach block volume",
+			volumeID: "vol-test",
+			volCaps:  multiWriterVolCap,
+			mockFunc: func(mockCloud *cloud.MockCloud, ctx context.Context, volumeID string) {
+				mockCloud.EXPECT().GetDiskByID(gomock.Eq(ctx), gomock.Eq(volumeID)).Return(&cloud.Disk{}, nil)
+			},
+			expected: true,
+		},
+		{
+			name:     "Fail with multi-attach filesystem volume",
+			volumeID: "vol-test",
+			volCaps:  multiWriterMountVolCap,
+			mockFunc: func(mockCloud *cloud.MockCloud, ctx context.Context, volumeID string) {
+				mockCloud.EXPECT().GetDiskByID(gomock.Eq(ctx), gomock.Eq(volumeID)).Return(&cloud.Disk{}, nil)
+			},
+			expected:   false,
+			expMessage: errMultiWriterFilesystem.Error(),
+		},
 		{
 			name:     "Success with node-local volume and RWO",
 			volumeID: NodeLocalVolumeHandlePrefix + "dev/xvdf",
@@ -5723,6 +6480,7 @@ func TestValidateVolumeCapabilities(t *testing.T) {
 			} else {
 				assert.Nil(t, resp.GetConfirmed())
 			}
+			assert.Equal(t, tc.expMessage, resp.GetMessage())
 		})
 	}
 }
@@ -5798,3 +6556,386 @@ func createControllerService(t *testing.T) (ControllerService, *gomock.Controlle
 	}
 	return awsDriver, mockCtl, mockCloud
 }
+
+func TestCreateVolumeAPIBudgetClass(t *testing.T) {
+	volCap := []*csi.VolumeCapability{
+		{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		},
+	}
+	options := &Options{APIBudgetRate: 50, APIBudgetWeights: map[string]int{"database": 10}}
+
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	mockCloud.EXPECT().CreateDisk(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+		func(ctx context.Context, volumeName string, _ *cloud.DiskOptions) (*cloud.Disk, error) {
+			assert.Equal(t, "database", util.APIBudgetClassFromContext(ctx))
+			return &cloud.Disk{VolumeID: volumeName, AvailabilityZone: expZone, CapacityGiB: 1}, nil
+		})
+	d := &ControllerService{cloud: mockCloud, inFlight: internal.NewInFlight(), options: options}
+
+	resp, err := d.CreateVolume(t.Context(), &csi.CreateVolumeRequest{
+		Name:               "vol-test",
+		VolumeCapabilities: volCap,
+		Parameters:         map[string]string{"apiBudgetClass": "database"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "database", resp.GetVolume().GetVolumeContext()[APIBudgetClassKey])
+
+	_, err = d.CreateVolume(t.Context(), &csi.CreateVolumeRequest{
+		Name:               "vol-test",
+		VolumeCapabilities: volCap,
+		Parameters:         map[string]string{"apiBudgetClass": "batch"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestCreateVolumeNameTagFromTemplate(t *testing.T) {
+	volCap := []*csi.VolumeCapability{
+		{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		},
+	}
+	options := &Options{
+		KubernetesClusterID:     "cluster",
+		NameTagFromTemplate:     true,
+		NameTagTemplate:         DefaultNameTagTemplate,
+		SnapshotNameTagTemplate: DefaultSnapshotNameTagTemplate,
+	}
+
+	testCases := []struct {
+		name        string
+		parameters  map[string]string
+		expectedTag string
+	}{
+		{
+			name:        "PVC metadata",
+			parameters:  map[string]string{PVCNamespaceKey: "default", PVCNameKey: "data-0", PVNameKey: "vol-test"},
+			expectedTag: `^default/data-0-[0-9a-f]{8}$`,
+		},
+		{
+			name:        "no PVC metadata keeps cluster Name tag",
+			parameters:  map[string]string{},
+			expectedTag: `^cluster-dynamic-vol-test$`,
+		},
+		{
+			name:        "tagSpecification overrides template",
+			parameters:  map[string]string{PVCNamespaceKey: "default", PVCNameKey: "data-0", "tagSpecification_1": "Name=custom"},
+			expectedTag: `^custom$`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			mockCloud.EXPECT().CreateDisk(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+				func(_ context.Context, volumeName string, diskOptions *cloud.DiskOptions) (*cloud.Disk, error) {
+					assert.Regexp(t, tc.expectedTag, diskOptions.Tags[NameTag])
+					return &cloud.Disk{VolumeID: volumeName, AvailabilityZone: expZone, CapacityGiB: 1}, nil
+				})
+			d := &ControllerService{cloud: mockCloud, inFlight: internal.NewInFlight(), options: options}
+
+			_, err := d.CreateVolume(t.Context(), &csi.CreateVolumeRequest{
+				Name:               "vol-test",
+				VolumeCapabilities: volCap,
+				Parameters:         tc.parameters,
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCreateVolumeThroughputPerGiB(t *testing.T) {
+	volCap := []*csi.VolumeCapability{
+		{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		},
+	}
+
+	testCases := []struct {
+		name              string
+		sizeGiB           int64
+		parameters        map[string]string
+		mutableParameters map[string]string
+		expThroughput     int32
+		expErr            bool
+	}{
+		{
+			name:          "throughput scales with size",
+			sizeGiB:       1000,
+			parameters:    map[string]string{VolumeTypeKey: cloud.VolumeTypeGP3, "throughputPerGiB": "0.5"},
+			expThroughput: 500,
+		},
+		{
+			name:          "default volume type",
+			sizeGiB:       200,
+			parameters:    map[string]string{"throughputPerGiB": "1"},
+			expThroughput: 200,
+		},
+		{
+			name:          "clamped to gp3 minimum",
+			sizeGiB:       100,
+			parameters:    map[string]string{VolumeTypeKey: cloud.VolumeTypeGP3, "throughputPerGiB": "0.25"},
+			expThroughput: gp3MinThroughput,
+		},
+		{
+			name:          "clamped to gp3 maximum",
+			sizeGiB:       200,
+			parameters:    map[string]string{VolumeTypeKey: cloud.VolumeTypeGP3, "throughputPerGiB": "10"},
+			expThroughput: gp3MaxThroughput,
+		},
+		{
+			name:              "throughput of the VolumeAttributesClass takes precedence",
+			sizeGiB:           1000,
+			parameters:        map[string]string{VolumeTypeKey: cloud.VolumeTypeGP3, "throughputPerGiB": "0.5"},
+			mutableParameters: map[string]string{ThroughputKey: "300"},
+			expThroughput:     300,
+		},
+		{
+			name:       "invalid value",
+			sizeGiB:    100,
+			parameters: map[string]string{"throughputPerGiB": "aaa"},
+			expErr:     true,
+		},
+		{
+			name:       "negative value",
+			sizeGiB:    100,
+			parameters: map[string]string{"throughputPerGiB": "-1"},
+			expErr:     true,
+		},
+		{
+			name:       "both throughput and throughputPerGiB",
+			sizeGiB:    100,
+			parameters: map[string]string{ThroughputKey: "250", "throughputPerGiB": "1"},
+			expErr:     true,
+		},
+		{
+			name:       "volume type other than gp3",
+			sizeGiB:    100,
+			parameters: map[string]string{VolumeTypeKey: cloud.VolumeTypeIO2, "throughputPerGiB": "1"},
+			expErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			if !tc.expErr {
+				mockCloud.EXPECT().CreateDisk(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+					func(_ context.Context, volumeName string, opts *cloud.DiskOptions) (*cloud.Disk, error) {
+						assert.Equal(t, tc.expThroughput, opts.Throughput)
+						return &cloud.Disk{VolumeID: volumeName, AvailabilityZone: expZone, CapacityGiB: int32(tc.sizeGiB)}, nil
+					})
+			}
+			d := &ControllerService{cloud: mockCloud, inFlight: internal.NewInFlight(), options: &Options{}}
+
+			_, err := d.CreateVolume(t.Context(), &csi.CreateVolumeRequest{
+				Name:               "vol-test",
+				CapacityRange:      &csi.CapacityRange{RequiredBytes: tc.sizeGiB * util.GiB},
+				VolumeCapabilities: volCap,
+				Parameters:         tc.parameters,
+				MutableParameters:  tc.mutableParameters,
+			})
+			if tc.expErr {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCreateVolumeRoundUpToMinimumSize(t *testing.T) {
+	volCap := []*csi.VolumeCapability{
+		{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		sizeGiB    int64
+		limitGiB   int64
+		parameters map[string]string
+		expSizeGiB int64
+		expErr     bool
+	}{
+		{
+			name:       "st1 rounded up",
+			sizeGiB:    10,
+			parameters: map[string]string{VolumeTypeKey: cloud.VolumeTypeST1, RoundUpToMinimumSizeKey: trueStr},
+			expSizeGiB: 125,
+		},
+		{
+			name:       "sc1 rounded up, parameter in any case",
+			sizeGiB:    1,
+			parameters: map[string]string{VolumeTypeKey: cloud.VolumeTypeSC1, "roundUpToMinimumSize": trueStr},
+			expSizeGiB: 125,
+		},
+		{
+			name:       "size above the minimum unchanged",
+			sizeGiB:    500,
+			parameters: map[string]string{VolumeTypeKey: cloud.VolumeTypeST1, RoundUpToMinimumSizeKey: trueStr},
+			expSizeGiB: 500,
+		},
+		{
+			name:       "not rounded up when disabled",
+			sizeGiB:    10,
+			parameters: map[string]string{VolumeTypeKey: cloud.VolumeTypeST1},
+			expSizeGiB: 10,
+		},
+		{
+			name:       "gp3 not rounded up",
+			sizeGiB:    10,
+			parameters: map[string]string{RoundUpToMinimumSizeKey: trueStr},
+			expSizeGiB: 10,
+		},
+		{
+			name:       "minimum exceeds the limit",
+			sizeGiB:    10,
+			limitGiB:   100,
+			parameters: map[string]string{VolumeTypeKey: cloud.VolumeTypeST1, RoundUpToMinimumSizeKey: trueStr},
+			expErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			if !tc.expErr {
+				mockCloud.EXPECT().CreateDisk(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+					func(_ context.Context, volumeName string, opts *cloud.DiskOptions) (*cloud.Disk, error) {
+						assert.Equal(t, tc.expSizeGiB*util.GiB, opts.CapacityBytes)
+						return &cloud.Disk{VolumeID: volumeName, AvailabilityZone: expZone, CapacityGiB: int32(tc.expSizeGiB)}, nil
+					})
+			}
+			d := &ControllerService{cloud: mockCloud, inFlight: internal.NewInFlight(), options: &Options{}}
+
+			resp, err := d.CreateVolume(t.Context(), &csi.CreateVolumeRequest{
+				Name:               "vol-test",
+				CapacityRange:      &csi.CapacityRange{RequiredBytes: tc.sizeGiB * util.GiB, LimitBytes: tc.limitGiB * util.GiB},
+				VolumeCapabilities: volCap,
+				Parameters:         tc.parameters,
+			})
+			if tc.expErr {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expSizeGiB*util.GiB, resp.GetVolume().GetCapacityBytes())
+		})
+	}
+}
+
+func TestCreateVolumeDefaultParameters(t *testing.T) {
+	volCap := []*csi.VolumeCapability{
+		{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		},
+	}
+	options := &Options{DefaultVolumeParameters: map[string]string{EncryptedKey: trueStr, ThroughputKey: "250"}}
+
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	mockCloud.EXPECT().CreateDisk(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+		func(_ context.Context, volumeName string, opts *cloud.DiskOptions) (*cloud.Disk, error) {
+			assert.True(t, opts.Encrypted)
+			// The throughputPerGiB of the StorageClass takes precedence over the default throughput
+			assert.Equal(t, int32(500), opts.Throughput)
+			return &cloud.Disk{VolumeID: volumeName, AvailabilityZone: expZone, CapacityGiB: 1000}, nil
+		})
+	d := &ControllerService{cloud: mockCloud, inFlight: internal.NewInFlight(), options: options}
+
+	_, err := d.CreateVolume(t.Context(), &csi.CreateVolumeRequest{
+		Name:               "vol-test",
+		CapacityRange:      &csi.CapacityRange{RequiredBytes: 1000 * util.GiB},
+		VolumeCapabilities: volCap,
+		Parameters:         map[string]string{"throughputPerGiB": "0.5"},
+	})
+	require.NoError(t, err)
+}
+
+func TestCreateVolumeOutpostArnParameter(t *testing.T) {
+	volCap := []*csi.VolumeCapability{
+		{
+			AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+			AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
+		},
+	}
+	outpostTopology := func(zone, outpostID string) *csi.Topology {
+		return &csi.Topology{Segments: map[string]string{
+			WellKnownZoneTopologyKey: zone,
+			AwsAccountIDKey:          "111111111111",
+			AwsOutpostIDKey:          outpostID,
+			AwsRegionKey:             "us-west-2",
+			AwsPartitionKey:          "aws",
+		}}
+	}
+
+	testCases := []struct {
+		name        string
+		outpostArn  string
+		requirement *csi.TopologyRequirement
+		expZone     string
+		expErrCode  codes.Code
+	}{
+		{
+			name:       "success: outpost in topology",
+			outpostArn: testOutpostARN,
+			requirement: &csi.TopologyRequirement{
+				Preferred: []*csi.Topology{{Segments: map[string]string{WellKnownZoneTopologyKey: "us-west-2a"}}},
+				Requisite: []*csi.Topology{outpostTopology("us-west-2a", "op-1bbb111b1bbbb11b1"), outpostTopology("us-west-2b", "op-0aaa000a0aaaa00a0")},
+			},
+			expZone: "us-west-2b",
+		},
+		{
+			name:       "fail: outpost not in topology",
+			outpostArn: testOutpostARN,
+			requirement: &csi.TopologyRequirement{
+				Requisite: []*csi.Topology{outpostTopology("us-west-2a", "op-1bbb111b1bbbb11b1")},
+			},
+			expErrCode: codes.InvalidArgument,
+		},
+		{
+			name:       "fail: no topology",
+			outpostArn: testOutpostARN,
+			expErrCode: codes.InvalidArgument,
+		},
+		{
+			name:       "fail: not an outpost arn",
+			outpostArn: "arn:aws:ec2:us-west-2:111111111111:instance/i-0aaa000a0aaaa00a0",
+			expErrCode: codes.InvalidArgument,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			if tc.expErrCode == codes.OK {
+				mockCloud.EXPECT().CreateDisk(gomock.Any(), "vol-test", gomock.Any()).DoAndReturn(
+					func(_ context.Context, volumeName string, opts *cloud.DiskOptions) (*cloud.Disk, error) {
+						assert.Equal(t, tc.outpostArn, opts.OutpostArn)
+						assert.Equal(t, tc.expZone, opts.AvailabilityZone)
+						return &cloud.Disk{VolumeID: volumeName, AvailabilityZone: opts.AvailabilityZone, OutpostArn: opts.OutpostArn, CapacityGiB: 1}, nil
+					})
+			}
+			d := &ControllerService{cloud: mockCloud, inFlight: internal.NewInFlight(), options: &Options{}}
+
+			_, err := d.CreateVolume(t.Context(), &csi.CreateVolumeRequest{
+				Name:                      "vol-test",
+				CapacityRange:             &csi.CapacityRange{RequiredBytes: util.GiB},
+				VolumeCapabilities:        volCap,
+				Parameters:                map[string]string{"outpostArn": tc.outpostArn},
+				AccessibilityRequirements: tc.requirement,
+			})
+			assert.Equal(t, tc.expErrCode, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// volumeImpairedReason is the reason of the events emitted when EBS reports a volume as impaired.
+	volumeImpairedReason = "VolumeImpaired"
+	// volumeIOEnabledReason is the reason of the events emitted when the driver re-enables the I/O of a volume.
+	volumeIOEnabledReason = "VolumeIOEnabled"
+	// volumeIOEnableFailedReason is the reason of the events emitted when the driver fails to re-enable the I/O of a volume.
+	volumeIOEnableFailedReason = "VolumeIOEnableFailed"
+)
+
+// attachedVolume is a volume attached to a node according to its VolumeAttachment.
+type attachedVolume struct {
+	pv   *corev1.PersistentVolume
+	node string
+}
+
+// volumeHealthMonitor polls EC2 DescribeVolumeStatus for the volumes attached by the driver and reports impaired
+// volumes with events on their PV and node and with metrics. Only the replica elected leader polls.
+type volumeHealthMonitor struct {
+	cloud        cloud.Cloud
+	client       kubernetes.Interface
+	recorder     record.EventRecorder
+	interval     time.Duration
+	autoEnableIO bool
+}
+
+func newVolumeHealthMonitor(c cloud.Cloud, k kubernetes.Interface, o *Options) *volumeHealthMonitor {
+	if o.VolumeStatusPollInterval <= 0 {
+		return nil
+	}
+	if k == nil {
+		klog.InfoS("No Kubernetes client available, not monitoring the status of volumes")
+		return nil
+	}
+	return &volumeHealthMonitor{
+		cloud:        c,
+		client:       k,
+		recorder:     newEventRecorder(k),
+		interval:     o.VolumeStatusPollInterval,
+		autoEnableIO: o.AutoEnableVolumeIO,
+	}
+}
+
+// newEventRecorder returns a recorder of the events emitted by the controller on Kubernetes objects.
+func newEventRecorder(k kubernetes.Interface) record.EventRecorder {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: k.CoreV1().Events("")})
+	return broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: util.GetDriverName()})
+}
+
+// start runs the monitor in the background once this replica is elected leader.
+func (m *volumeHealthMonitor) start() {
+	go runLeaderElection(context.Background(), m.client, "volume-health-"+util.GetDriverName(), func(ctx context.Context) {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			m.check(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// check reports the impaired volumes among the volumes attached by the driver.
+func (m *volumeHealthMonitor) check(ctx context.Context) {
+	attached, err := m.attachedVolumes(ctx)
+	if err != nil {
+		klog.ErrorS(err, "checkVolumeHealth: could not list attached volumes")
+		return
+	}
+	volumeIDs := make([]string, 0, len(attached))
+	for volumeID := range attached {
+		volumeIDs = append(volumeIDs, volumeID)
+	}
+	if len(volumeIDs) == 0 {
+		return
+	}
+	health, err := m.cloud.GetVolumeHealth(ctx, volumeIDs)
+	if err != nil {
+		klog.ErrorS(err, "checkVolumeHealth: could not get the status of attached volumes")
+		return
+	}
+
+	var impaired, ioDisabled int
+	for volumeID, h := range health {
+		if !h.Impaired() {
+			continue
+		}
+		v := attached[volumeID]
+		if !h.IOEnabled {
+			ioDisabled++
+		} else {
+			impaired++
+		}
+		klog.InfoS("checkVolumeHealth: volume is impaired", "volumeID", volumeID, "pv", v.pv.Name, "node", v.node, "status", h.Status, "ioEnabled", h.IOEnabled, "events", h.Events)
+		message := volumeHealthMessage(volumeID, h)
+		nodeRef := &corev1.ObjectReference{Kind: "Node", Name: v.node, UID: k8stypes.UID(v.node)}
+		for _, obj := range []runtime.Object{v.pv, nodeRef} {
+			m.recorder.Event(obj, corev1.EventTypeWarning, volumeImpairedReason, message)
+		}
+
+		if !h.IOEnabled && m.autoEnableIO {
+			m.enableIO(ctx, volumeID, v.pv)
+		}
+	}
+
+	metrics.Recorder().SetGauge(metrics.ImpairedVolumes, metrics.ImpairedVolumesHelpText, float64(impaired), map[string]string{"status": "impaired"})
+	metrics.Recorder().SetGauge(metrics.ImpairedVolumes, metrics.ImpairedVolumesHelpText, float64(ioDisabled), map[string]string{"status": "io_disabled"})
+}
+
+// volumeHealthMessage describes the status EBS reports for a volume.
+func volumeHealthMessage(volumeID string, h *cloud.VolumeHealth) string {
+	message := fmt.Sprintf("EBS reports volume %s as %s (I/O enabled: %t)", volumeID, h.Status, h.IOEnabled)
+	if h.Events != "" {
+		message += ": " + h.Events
+	}
+	return message
+}
+
+// newVolumeCondition returns the CSI condition of a volume, abnormal when EBS reports it as impaired or disabled its
+// I/O. Volumes EC2 does not report the status of yet are considered normal.
+func newVolumeCondition(volumeID string, h *cloud.VolumeHealth) *csi.VolumeCondition {
+	if h == nil {
+		return &csi.VolumeCondition{Message: fmt.Sprintf("EBS does not report the status of volume %s yet", volumeID)}
+	}
+	return &csi.VolumeCondition{Abnormal: h.Impaired(), Message: volumeHealthMessage(volumeID, h)}
+}
+
+// enableIO re-enables the I/O of a volume, the documented action for volumes whose I/O EBS disabled because their
+// data is potentially inconsistent. The data of the volume should be checked, for example with fsck.
+func (m *volumeHealthMonitor) enableIO(ctx context.Context, volumeID string, pv *corev1.PersistentVolume) {
+	result := "success"
+	if err := m.cloud.EnableVolumeIO(ctx, volumeID); err != nil {
+		result = "error"
+		klog.ErrorS(err, "checkVolumeHealth: could not enable I/O of volume", "volumeID", volumeID)
+		m.recorder.Eventf(pv, corev1.EventTypeWarning, volumeIOEnableFailedReason, "Could not re-enable I/O of volume %s: %v", volumeID, err)
+	} else {
+		klog.InfoS("checkVolumeHealth: enabled I/O of volume", "volumeID", volumeID)
+		m.recorder.Eventf(pv, corev1.EventTypeNormal, volumeIOEnabledReason, "Re-enabled I/O of volume %s, check the consistency of its data", volumeID)
+	}
+	metrics.Recorder().IncreaseCount(metrics.VolumeIOEnabled, metrics.VolumeIOEnabledHelpText, map[string]string{"result": result})
+}
+
+// attachedVolumes returns the volumes of the driver attached to a node, keyed by volume ID.
+func (m *volumeHealthMonitor) attachedVolumes(ctx context.Context) (map[string]attachedVolume, error) {
+	vas, err := m.client.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodes := map[string]string{}
+	for _, va := range vas.Items {
+		if va.Spec.Attacher != util.GetDriverName() || !va.Status.Attached || va.Spec.Source.PersistentVolumeName == nil {
+			continue
+		}
+		nodes[*va.Spec.Source.PersistentVolumeName] = va.Spec.NodeName
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	pvs, err := m.client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	attached := map[string]attachedVolume{}
+	for i := range pvs.Items {
+		pv := &pvs.Items[i]
+		node, ok := nodes[pv.Name]
+		if !ok || pv.Spec.CSI == nil || pv.Spec.CSI.Driver != util.GetDriverName() {
+			continue
+		}
+		attached[pv.Spec.CSI.VolumeHandle] = attachedVolume{pv: pv, node: node}
+	}
+	return attached, nil
+}
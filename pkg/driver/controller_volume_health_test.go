@@ -0,0 +1,122 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func newTestPV(name, driver, volumeID string) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: driver, VolumeHandle: volumeID},
+			},
+		},
+	}
+}
+
+func newTestVolumeAttachment(name, attacher, pvName, nodeName string, attached bool) *storagev1.VolumeAttachment {
+	return &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: storagev1.VolumeAttachmentSpec{
+			Attacher: attacher,
+			NodeName: nodeName,
+			Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pvName},
+		},
+		Status: storagev1.VolumeAttachmentStatus{Attached: attached},
+	}
+}
+
+func TestNewVolumeHealthMonitor(t *testing.T) {
+	assert.Nil(t, newVolumeHealthMonitor(nil, fake.NewClientset(), &Options{}))
+	assert.Nil(t, newVolumeHealthMonitor(nil, nil, &Options{VolumeStatusPollInterval: time.Minute}))
+	assert.NotNil(t, newVolumeHealthMonitor(nil, fake.NewClientset(), &Options{VolumeStatusPollInterval: time.Minute}))
+}
+
+func TestVolumeHealthMonitorCheck(t *testing.T) {
+	driver := util.GetDriverName()
+	client := fake.NewClientset(
+		newTestPV("pv-healthy", driver, "vol-healthy"),
+		newTestPV("pv-impaired", driver, "vol-impaired"),
+		newTestPV("pv-io-disabled", driver, "vol-io-disabled"),
+		newTestPV("pv-detached", driver, "vol-detached"),
+		newTestPV("pv-other-driver", "other.csi.k8s.io", "vol-other-driver"),
+		newTestVolumeAttachment("va-healthy", driver, "pv-healthy", "node-1", true),
+		newTestVolumeAttachment("va-impaired", driver, "pv-impaired", "node-1", true),
+		newTestVolumeAttachment("va-io-disabled", driver, "pv-io-disabled", "node-2", true),
+		newTestVolumeAttachment("va-detached", driver, "pv-detached", "node-2", false),
+		newTestVolumeAttachment("va-other-driver", "other.csi.k8s.io", "pv-other-driver", "node-2", true),
+	)
+
+	tests := []struct {
+		name           string
+		autoEnableIO   bool
+		expectedEvents int
+	}{
+		{
+			name: "report impaired volumes",
+			// One event on the PV and one on the node of each impaired volume
+			expectedEvents: 4,
+		},
+		{
+			name:         "re-enable I/O",
+			autoEnableIO: true,
+			// Plus one event on the PV whose I/O was re-enabled
+			expectedEvents: 5,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			mockCloud.EXPECT().GetVolumeHealth(gomock.Any(), gomock.InAnyOrder([]string{"vol-healthy", "vol-impaired", "vol-io-disabled"})).Return(map[string]*cloud.VolumeHealth{
+				"vol-healthy":     {Status: types.VolumeStatusInfoStatusOk, IOEnabled: true},
+				"vol-impaired":    {Status: types.VolumeStatusInfoStatusImpaired, IOEnabled: true, Events: "potential-data-inconsistency: I/O performance degraded"},
+				"vol-io-disabled": {Status: types.VolumeStatusInfoStatusImpaired, IOEnabled: false},
+			}, nil)
+			if tc.autoEnableIO {
+				mockCloud.EXPECT().EnableVolumeIO(gomock.Any(), "vol-io-disabled").Return(nil)
+			}
+
+			recorder := record.NewFakeRecorder(10)
+			m := &volumeHealthMonitor{cloud: mockCloud, client: client, recorder: recorder, autoEnableIO: tc.autoEnableIO}
+			m.check(t.Context())
+
+			require.Len(t, recorder.Events, tc.expectedEvents)
+			for range tc.expectedEvents {
+				event := <-recorder.Events
+				assert.NotContains(t, event, "vol-healthy")
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+// volumeTypeFallbackReason is the reason of the events emitted on a PVC whose volume was created with a fallback
+// volume type.
+const volumeTypeFallbackReason = "VolumeTypeFallback"
+
+// volumeTypeFallbackReporter tells the owners of PVCs with an event when their volume was not created with the
+// volume type of their StorageClass.
+type volumeTypeFallbackReporter struct {
+	recorder record.EventRecorder
+}
+
+func newVolumeTypeFallbackReporter(k kubernetes.Interface) *volumeTypeFallbackReporter {
+	if k == nil {
+		return nil
+	}
+	return &volumeTypeFallbackReporter{recorder: newEventRecorder(k)}
+}
+
+// report emits an event on the PVC, known when the external-provisioner runs with --extra-create-metadata.
+func (r *volumeTypeFallbackReporter) report(tProps *template.PVProps, volumeID, volumeType, fallbackType string, err error) {
+	if r == nil || tProps.PVCName == "" {
+		return
+	}
+	claim := &corev1.ObjectReference{Kind: "PersistentVolumeClaim", APIVersion: "v1", Namespace: tProps.PVCNamespace, Name: tProps.PVCName}
+	r.recorder.Eventf(claim, corev1.EventTypeWarning, volumeTypeFallbackReason,
+		"Created volume %s as %s instead of %s, which EC2 could not create in the Availability Zone of the volume: %v", volumeID, fallbackType, volumeType, err)
+}
+
+// parseFallbackVolumeTypes parses the fallbackVolumeTypes parameter, a list of volume types separated by commas.
+func parseFallbackVolumeTypes(value string) ([]string, error) {
+	var volumeTypes []string
+	for volumeType := range strings.SplitSeq(value, ",") {
+		volumeType = strings.ToLower(strings.TrimSpace(volumeType))
+		if !slices.Contains(cloud.ValidVolumeTypes, volumeType) {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid fallbackVolumeTypes %q, %q is not one of %s", value, volumeType, strings.Join(cloud.ValidVolumeTypes, ", "))
+		}
+		volumeTypes = append(volumeTypes, volumeType)
+	}
+	return volumeTypes, nil
+}
+
+// isVolumeTypeFallbackError returns true if CreateVolume may succeed with another volume type. An existing volume
+// created with a fallback volume type by a previous call fails with IdempotentParameterMismatch with the volume type
+// of the StorageClass, and is returned by the call with the fallback volume type.
+func isVolumeTypeFallbackError(err error) bool {
+	return errors.Is(err, cloud.ErrInsufficientCapacity) || errors.Is(err, cloud.ErrUnsupported) || errors.Is(err, cloud.ErrIdempotentParameterMismatch)
+}
+
+// createDiskWithFallback creates the volume, then with each fallback volume type in order while EC2 cannot create it
+// with the previous one. IOPS and throughput are dropped for the fallback volume types that do not support them, and
+// the namespace quota is checked again against each fallback volume type.
+func (d *ControllerService) createDiskWithFallback(ctx context.Context, volName string, opts *cloud.DiskOptions, fallbackTypes []string, tProps *template.PVProps) (*cloud.Disk, error) {
+	disk, err := d.createDisk(ctx, volName, opts)
+	primaryErr := err
+	volumeType := opts.VolumeType
+	if volumeType == "" {
+		volumeType = "the default type"
+	}
+	for _, fallbackType := range fallbackTypes {
+		if err == nil || !isVolumeTypeFallbackError(err) {
+			break
+		}
+		klog.InfoS("CreateVolume: could not create volume, trying the next fallback volume type", "volumeName", volName, "volumeType", volumeType, "fallbackType", fallbackType, "err", err)
+		fallbackOpts := *opts
+		fallbackOpts.VolumeType = fallbackType
+		if !slices.Contains(parameterVolumeTypes[IopsKey], fallbackType) {
+			fallbackOpts.IOPS, fallbackOpts.IOPSPerGB, fallbackOpts.AllowIOPSPerGBIncrease = 0, 0, false
+		}
+		if !slices.Contains(parameterVolumeTypes[ThroughputKey], fallbackType) {
+			fallbackOpts.Throughput = 0
+		}
+		// The fallback volume type may have a namespace quota of its own
+		if _, err := d.namespaceQuotas.reserve(ctx, tProps, volName, &fallbackOpts); err != nil {
+			return nil, err
+		}
+		var fallbackErr error
+		disk, fallbackErr = d.createDisk(ctx, volName, &fallbackOpts)
+		if fallbackErr == nil {
+			d.volumeTypeFallback.report(tProps, disk.VolumeID, volumeType, fallbackType, primaryErr)
+		}
+		err = fallbackErr
+	}
+	return disk, err
+}
+
+// createDisk creates the volume, through an intermediate snapshot for clones with --clone-via-snapshot.
+func (d *ControllerService) createDisk(ctx context.Context, volName string, opts *cloud.DiskOptions) (*cloud.Disk, error) {
+	if opts.SourceVolumeID != "" && d.options.CloneViaSnapshot {
+		return d.createDiskViaSnapshot(ctx, volName, opts)
+	}
+	return d.cloud.CreateDisk(ctx, volName, opts)
+}
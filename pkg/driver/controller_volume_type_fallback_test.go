@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestParseFallbackVolumeTypes(t *testing.T) {
+	volumeTypes, err := parseFallbackVolumeTypes("gp3, GP2,st1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{cloud.VolumeTypeGP3, cloud.VolumeTypeGP2, cloud.VolumeTypeST1}, volumeTypes)
+
+	_, err = parseFallbackVolumeTypes("gp3,gp4")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = parseFallbackVolumeTypes("")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestCreateDiskWithFallback(t *testing.T) {
+	opts := &cloud.DiskOptions{VolumeType: cloud.VolumeTypeIO2, IOPS: 5000, Throughput: 500}
+	tProps := &template.PVProps{PVCName: "claim", PVCNamespace: "default"}
+	disk := &cloud.Disk{VolumeID: "vol-test"}
+
+	testCases := []struct {
+		name          string
+		fallbackTypes []string
+		mockCreate    func(m *cloud.MockCloud)
+		expDisk       *cloud.Disk
+		expErr        error
+		expEvent      string
+	}{
+		{
+			name:          "success: primary volume type",
+			fallbackTypes: []string{cloud.VolumeTypeGP3},
+			mockCreate: func(m *cloud.MockCloud) {
+				m.EXPECT().CreateDisk(gomock.Any(), "vol", opts).Return(disk, nil)
+			},
+			expDisk: disk,
+		},
+		{
+			name:          "success: second fallback volume type",
+			fallbackTypes: []string{cloud.VolumeTypeGP3, cloud.VolumeTypeGP2},
+			mockCreate: func(m *cloud.MockCloud) {
+				gomock.InOrder(
+					m.EXPECT().CreateDisk(gomock.Any(), "vol", opts).Return(nil, cloud.ErrInsufficientCapacity),
+					m.EXPECT().CreateDisk(gomock.Any(), "vol", &cloud.DiskOptions{VolumeType: cloud.VolumeTypeGP3, IOPS: 5000, Throughput: 500}).Return(nil, cloud.ErrUnsupported),
+					m.EXPECT().CreateDisk(gomock.Any(), "vol", &cloud.DiskOptions{VolumeType: cloud.VolumeTypeGP2}).Return(disk, nil),
+				)
+			},
+			expDisk:  disk,
+			expEvent: "Warning VolumeTypeFallback Created volume vol-test as gp2 instead of io2, which EC2 could not create in the Availability Zone of the volume: insufficient capacity",
+		},
+		{
+			name:          "fail: error without fallback",
+			fallbackTypes: []string{cloud.VolumeTypeGP3},
+			mockCreate: func(m *cloud.MockCloud) {
+				m.EXPECT().CreateDisk(gomock.Any(), "vol", opts).Return(nil, cloud.ErrInvalidArgument)
+			},
+			expErr: cloud.ErrInvalidArgument,
+		},
+		{
+			name:          "fail: every fallback volume type",
+			fallbackTypes: []string{cloud.VolumeTypeGP3},
+			mockCreate: func(m *cloud.MockCloud) {
+				m.EXPECT().CreateDisk(gomock.Any(), "vol", opts).Return(nil, cloud.ErrInsufficientCapacity)
+				m.EXPECT().CreateDisk(gomock.Any(), "vol", gomock.Any()).Return(nil, cloud.ErrInsufficientCapacity)
+			},
+			expErr: cloud.ErrInsufficientCapacity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockCloud := cloud.NewMockCloud(mockCtl)
+			tc.mockCreate(mockCloud)
+			recorder := record.NewFakeRecorder(10)
+			d := &ControllerService{cloud: mockCloud, options: &Options{}, volumeTypeFallback: &volumeTypeFallbackReporter{recorder: recorder}}
+
+			disk, err := d.createDiskWithFallback(t.Context(), "vol", opts, tc.fallbackTypes, tProps)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expDisk, disk)
+			if tc.expEvent == "" {
+				assert.Empty(t, recorder.Events)
+			} else {
+				require.Len(t, recorder.Events, 1)
+				assert.Equal(t, tc.expEvent, <-recorder.Events)
+			}
+		})
+	}
+}
+
+func TestCreateDiskWithFallbackNamespaceQuota(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	opts := &cloud.DiskOptions{VolumeType: cloud.VolumeTypeIO2, IOPS: 5000, CapacityBytes: 10 * util.GiB}
+	tProps := &template.PVProps{PVCName: "claim", PVCNamespace: "default"}
+	o := &Options{NamespaceQuotas: map[string]NamespaceQuota{"default": {VolumeTypes: map[string]QuotaLimits{cloud.VolumeTypeGP3: {CapacityGiB: 5}}}}}
+	mockCloud.EXPECT().ListDisks(gomock.Any(), gomock.Nil(), gomock.Any()).Return(nil, nil).Times(2)
+	mockCloud.EXPECT().CreateDisk(gomock.Any(), "vol", opts).Return(nil, cloud.ErrInsufficientCapacity)
+	mockCloud.EXPECT().GetDiskByName(gomock.Any(), "vol", opts.CapacityBytes).Return(nil, cloud.ErrNotFound)
+	d := &ControllerService{cloud: mockCloud, options: o, namespaceQuotas: newNamespaceQuotaEnforcer(mockCloud, nil, o)}
+
+	release, err := d.namespaceQuotas.reserve(t.Context(), tProps, "vol", opts)
+	require.NoError(t, err)
+	// The fallback volume type is not created when it would exceed its own quota
+	disk, err := d.createDiskWithFallback(t.Context(), "vol", opts, []string{cloud.VolumeTypeGP3}, tProps)
+	release(disk)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), "gp3 capacity")
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/expiringcache"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// selectedNodeAnnotation is set on PVCs of WaitForFirstConsumer StorageClasses by the scheduler once it picked the
+// node of their first consumer. The volume of such a PVC must be created in the zone of that node.
+const selectedNodeAnnotation = "volume.kubernetes.io/selected-node"
+
+// zonalErrors are the errors of CreateVolume failures caused by the availability zone rather than by the request,
+// which steer the next volumes away from the zone.
+var zonalErrors = []error{cloud.ErrInsufficientCapacity, cloud.ErrUnsupported}
+
+// capacityPickTTL is how long the zone picked for a volume by capacity is remembered, so that retries of
+// CreateVolume create it in the same zone although the capacity of the zones changed in between.
+const capacityPickTTL = 1 * time.Hour
+
+// zonePicker chooses the availability zone of volumes whose accessibility requirements allow several zones, instead
+// of always using the first preferred zone. Zones are chosen by weighted rendezvous hashing of the volume name, so that
+// retries of CreateVolume choose the same zone while volumes spread across zones in proportion to their weight, which
+// is divided by one plus the number of zonal CreateVolume failures in the zone within the failure window.
+// With capacity-aware selection, the zone with the most EBS capacity left is picked instead: the zone without recent
+// capacity or zonal failures where the account uses the least storage of the volume type, weights breaking ties.
+// Volumes whose PVC has a selected node, or whose PVC is unknown, keep the first preferred zone, the zone of their
+// consumer.
+type zonePicker struct {
+	client        kubernetes.Interface
+	cloud         cloud.Cloud
+	weights       map[string]int
+	failureWindow time.Duration
+	capacityAware bool
+	// capacityPicks are the zones picked by capacity, by volume name
+	capacityPicks expiringcache.ExpiringCache[string, string]
+	mu            sync.Mutex
+	failures      map[string][]time.Time
+	now           func() time.Time
+}
+
+func newZonePicker(k kubernetes.Interface, c cloud.Cloud, o *Options) *zonePicker {
+	if len(o.ZoneWeights) == 0 && o.ZoneFailureWindow <= 0 && !o.CapacityAwareZoneSelection {
+		return nil
+	}
+	if k == nil {
+		klog.InfoS("No Kubernetes client available, not weighting availability zones")
+		return nil
+	}
+	return &zonePicker{
+		client:        k,
+		cloud:         c,
+		weights:       o.ZoneWeights,
+		failureWindow: o.ZoneFailureWindow,
+		capacityAware: o.CapacityAwareZoneSelection,
+		capacityPicks: expiringcache.New[string, string](capacityPickTTL),
+		failures:      map[string][]time.Time{},
+		now:           time.Now,
+	}
+}
+
+// pick returns the topology to create a volume in, or nil if the first preferred topology must be used.
+func (p *zonePicker) pick(ctx context.Context, volName, volumeType string, requirement *csi.TopologyRequirement, pvcNamespace, pvcName string) *csi.Topology {
+	if p == nil {
+		return nil
+	}
+	candidates := zonalTopologies(requirement)
+	if len(candidates) < 2 {
+		return nil
+	}
+	if pvcNamespace == "" || pvcName == "" {
+		klog.V(4).InfoS("Not weighting availability zones of volume without PVC metadata", "volumeName", volName)
+		return nil
+	}
+	pvc, err := p.client.CoreV1().PersistentVolumeClaims(pvcNamespace).Get(ctx, pvcName, metav1.GetOptions{})
+	if err != nil {
+		klog.ErrorS(err, "Could not get PVC to weight availability zones", "namespace", pvcNamespace, "name", pvcName)
+		return nil
+	}
+	if _, ok := pvc.GetAnnotations()[selectedNodeAnnotation]; ok {
+		return nil
+	}
+
+	capacities := p.zoneCapacities(ctx, volName, volumeType)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var previous *string
+	if capacities != nil {
+		previous, _ = p.capacityPicks.Get(volName)
+	}
+	var picked *csi.Topology
+	var pickedRank zoneRank
+	for _, topology := range candidates {
+		zone := topologyZone(topology)
+		weight := p.weight(zone, topology.GetSegments()[ZoneIDTopologyKey])
+		if weight <= 0 {
+			continue
+		}
+		sum := sha256.Sum256([]byte(volName + "/" + zone))
+		// Uniform in (0, 1)
+		u := (float64(binary.BigEndian.Uint64(sum[:])>>11) + 0.5) / (1 << 53)
+		rank := zoneRank{score: -weight / math.Log(u)}
+		if capacities != nil {
+			if previous != nil && *previous == zone {
+				picked = topology
+				break
+			}
+			rank.capacity = capacities[zone]
+			rank.capacity.Exhausted = rank.capacity.Exhausted || len(p.failures[zone]) > 0
+		}
+		if picked == nil || rank.outranks(pickedRank) {
+			picked, pickedRank = topology, rank
+		}
+	}
+	if picked == nil {
+		return nil
+	}
+	zone := topologyZone(picked)
+	if capacities != nil {
+		p.capacityPicks.Set(volName, &zone)
+		klog.V(4).InfoS("Picked availability zone with the most capacity left", "volumeName", volName, "zone", zone, "usedGiB", capacities[zone].UsedGiB)
+	} else {
+		klog.V(4).InfoS("Picked weighted availability zone", "volumeName", volName, "zone", zone)
+	}
+	return picked
+}
+
+// zoneCapacities returns the capacity of the zones for a volume type if zones are picked by capacity, or nil if
+// they are picked by weight only.
+func (p *zonePicker) zoneCapacities(ctx context.Context, volName, volumeType string) map[string]cloud.ZoneCapacity {
+	if !p.capacityAware {
+		return nil
+	}
+	if volumeType == "" {
+		volumeType = cloud.VolumeTypeGP3
+	}
+	capacities, err := p.cloud.GetZoneCapacity(ctx, volumeType)
+	if err != nil {
+		klog.ErrorS(err, "Could not get the capacity of availability zones, picking the zone by weight", "volumeName", volName)
+		return nil
+	}
+	return capacities
+}
+
+// zoneRank orders the zones a volume can be created in.
+type zoneRank struct {
+	capacity cloud.ZoneCapacity
+	// score is the weighted rendezvous hashing score of the zone
+	score float64
+}
+
+// outranks returns whether a zone should be picked over another: the zone that is not exhausted, then the zone with
+// the least storage used, then the zone with the highest score. The capacity of zones picked by weight is zero.
+func (r zoneRank) outranks(other zoneRank) bool {
+	if r.capacity.Exhausted != other.capacity.Exhausted {
+		return !r.capacity.Exhausted
+	}
+	if r.capacity.UsedGiB != other.capacity.UsedGiB {
+		return r.capacity.UsedGiB < other.capacity.UsedGiB
+	}
+	return r.score > other.score
+}
+
+// weight returns the effective weight of a zone, configured by zone name or zone ID. Must be called with mu held.
+func (p *zonePicker) weight(zone, zoneID string) float64 {
+	weight, ok := p.weights[zone]
+	if !ok {
+		weight, ok = p.weights[zoneID]
+	}
+	if !ok {
+		weight = 1
+	}
+
+	recent := p.failures[zone][:0]
+	for _, failure := range p.failures[zone] {
+		if p.now().Sub(failure) < p.failureWindow {
+			recent = append(recent, failure)
+		}
+	}
+	p.failures[zone] = recent
+	return float64(weight) / float64(1+len(recent))
+}
+
+// recordFailure records a CreateVolume failure of a volume in a zone if it was caused by the zone, and forgets the
+// zone picked for the volume by capacity so that its retries can pick another zone.
+func (p *zonePicker) recordFailure(volName, zone string, err error) {
+	if p == nil || zone == "" {
+		return
+	}
+	if !slices.ContainsFunc(zonalErrors, func(zonalErr error) bool { return errors.Is(err, zonalErr) }) {
+		return
+	}
+	p.capacityPicks.Remove(volName)
+	if p.failureWindow <= 0 {
+		return
+	}
+	klog.InfoS("Steering volumes away from availability zone after zonal failure", "zone", zone, "err", err, "window", p.failureWindow)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failures[zone] = append(p.failures[zone], p.now())
+}
+
+// zonalTopologies returns the topologies a volume can be created in, one per zone: the requisite topologies, or the
+// preferred ones if there are none. Outpost topologies are left to the default selection.
+func zonalTopologies(requirement *csi.TopologyRequirement) []*csi.Topology {
+	topologies := requirement.GetRequisite()
+	if len(topologies) == 0 {
+		topologies = requirement.GetPreferred()
+	}
+	seen := map[string]bool{}
+	var zonal []*csi.Topology
+	for _, topology := range topologies {
+		if _, ok := topology.GetSegments()[AwsOutpostIDKey]; ok {
+			return nil
+		}
+		zone := topologyZone(topology)
+		if zone == "" || seen[zone] {
+			continue
+		}
+		seen[zone] = true
+		zonal = append(zonal, topology)
+	}
+	return zonal
+}
+
+func topologyZone(topology *csi.Topology) string {
+	if zone, ok := topology.GetSegments()[WellKnownZoneTopologyKey]; ok {
+		return zone
+	}
+	return topology.GetSegments()[ZoneTopologyKey]
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func zoneRequirement(zones ...string) *csi.TopologyRequirement {
+	requirement := &csi.TopologyRequirement{}
+	for i, zone := range zones {
+		topology := &csi.Topology{Segments: map[string]string{WellKnownZoneTopologyKey: zone, ZoneIDTopologyKey: fmt.Sprintf("use1-az%d", i+1)}}
+		requirement.Requisite = append(requirement.Requisite, topology)
+		requirement.Preferred = append(requirement.Preferred, topology)
+	}
+	return requirement
+}
+
+func TestZonePicker(t *testing.T) {
+	var nilPicker *zonePicker
+	assert.Nil(t, nilPicker.pick(t.Context(), "vol", "", zoneRequirement("us-east-1a", "us-east-1b"), "default", "data"))
+	nilPicker.recordFailure("pvc-0", "us-east-1a", errors.New("failure"))
+	assert.Nil(t, newZonePicker(fake.NewClientset(), nil, &Options{}))
+
+	client := fake.NewClientset(
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "wffc", Annotations: map[string]string{selectedNodeAnnotation: "node-1"}}},
+	)
+	now := time.Now()
+	p := newZonePicker(client, nil, &Options{ZoneWeights: map[string]int{"us-east-1a": 3, "use1-az3": 0}, ZoneFailureWindow: time.Minute})
+	p.now = func() time.Time { return now }
+	requirement := zoneRequirement("us-east-1a", "us-east-1b", "us-east-1c")
+
+	pickZones := func() map[string]int {
+		zones := map[string]int{}
+		for i := range 1000 {
+			topology := p.pick(t.Context(), fmt.Sprintf("pvc-%d", i), "", requirement, "default", "data")
+			require.NotNil(t, topology)
+			zones[topologyZone(topology)]++
+		}
+		return zones
+	}
+
+	zones := pickZones()
+	assert.Zero(t, zones["us-east-1c"], "zone with weight 0 by zone ID must be avoided")
+	assert.InDelta(t, 750, zones["us-east-1a"], 60)
+	assert.InDelta(t, 250, zones["us-east-1b"], 60)
+
+	first := p.pick(t.Context(), "pvc-0", "", requirement, "default", "data")
+	assert.Equal(t, first, p.pick(t.Context(), "pvc-0", "", requirement, "default", "data"), "retries must pick the same zone")
+	assert.Equal(t, "use1-az1", requirement.GetRequisite()[0].GetSegments()[ZoneIDTopologyKey])
+
+	// Consumers scheduled, unknown PVCs and single zones keep the default selection
+	assert.Nil(t, p.pick(t.Context(), "pvc-0", "", requirement, "default", "wffc"))
+	assert.Nil(t, p.pick(t.Context(), "pvc-0", "", requirement, "", ""))
+	assert.Nil(t, p.pick(t.Context(), "pvc-0", "", requirement, "default", "missing"))
+	assert.Nil(t, p.pick(t.Context(), "pvc-0", "", zoneRequirement("us-east-1a"), "default", "data"))
+
+	// Zonal failures lower the weight of their zone until they leave the window
+	capacityErr := cloud.ClassifyError(&smithy.GenericAPIError{Code: "InsufficientVolumeCapacity"})
+	for range 5 {
+		p.recordFailure("pvc-0", "us-east-1a", capacityErr)
+	}
+	p.recordFailure("pvc-0", "us-east-1b", cloud.ClassifyError(&smithy.GenericAPIError{Code: "RequestLimitExceeded"}))
+	p.recordFailure("pvc-0", "us-east-1b", errors.New("not an API error"))
+	zones = pickZones()
+	assert.InDelta(t, 333, zones["us-east-1a"], 60)
+	assert.InDelta(t, 667, zones["us-east-1b"], 60)
+
+	now = now.Add(time.Minute)
+	zones = pickZones()
+	assert.InDelta(t, 750, zones["us-east-1a"], 60)
+	assert.Empty(t, p.failures["us-east-1a"])
+}
+
+func TestZonePickerCapacityAware(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := cloud.NewMockCloud(mockCtl)
+	client := fake.NewClientset(&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data"}})
+	p := newZonePicker(client, mockCloud, &Options{CapacityAwareZoneSelection: true, ZoneWeights: map[string]int{"us-east-1d": 0}})
+	require.NotNil(t, p)
+	requirement := zoneRequirement("us-east-1a", "us-east-1b", "us-east-1c", "us-east-1d")
+
+	capacities := map[string]cloud.ZoneCapacity{
+		"us-east-1a": {UsedGiB: 500},
+		"us-east-1b": {UsedGiB: 100},
+		"us-east-1c": {UsedGiB: 10, Exhausted: true},
+	}
+	mockCloud.EXPECT().GetZoneCapacity(gomock.Any(), cloud.VolumeTypeGP3).DoAndReturn(func(_ any, _ string) (map[string]cloud.ZoneCapacity, error) {
+		return capacities, nil
+	}).AnyTimes()
+
+	// The zone with weight 0 is avoided although no storage is used in it, and the exhausted zone comes last
+	topology := p.pick(t.Context(), "pvc-0", "", requirement, "default", "data")
+	require.NotNil(t, topology)
+	assert.Equal(t, "us-east-1b", topologyZone(topology))
+
+	// Retries keep the zone although the capacity of the zones changed
+	capacities["us-east-1a"] = cloud.ZoneCapacity{UsedGiB: 0}
+	assert.Equal(t, "us-east-1b", topologyZone(p.pick(t.Context(), "pvc-0", "", requirement, "default", "data")))
+	assert.Equal(t, "us-east-1a", topologyZone(p.pick(t.Context(), "pvc-1", "", requirement, "default", "data")))
+
+	// Zonal failures forget the zone of the volume
+	p.recordFailure("pvc-0", "us-east-1b", errors.New("not an API error"))
+	assert.Equal(t, "us-east-1b", topologyZone(p.pick(t.Context(), "pvc-0", "", requirement, "default", "data")))
+	p.recordFailure("pvc-0", "us-east-1b", cloud.ClassifyError(&smithy.GenericAPIError{Code: "InsufficientVolumeCapacity"}))
+	assert.Equal(t, "us-east-1a", topologyZone(p.pick(t.Context(), "pvc-0", "", requirement, "default", "data")))
+
+	// Zones are weighted if their capacity is unknown
+	mockCloud = cloud.NewMockCloud(mockCtl)
+	mockCloud.EXPECT().GetZoneCapacity(gomock.Any(), cloud.VolumeTypeIO2).Return(nil, errors.New("DescribeVolumes error"))
+	p.cloud = mockCloud
+	assert.NotEqual(t, "us-east-1d", topologyZone(p.pick(t.Context(), "pvc-2", cloud.VolumeTypeIO2, requirement, "default", "data")))
+}
+
+func TestZoneRank(t *testing.T) {
+	exhausted := zoneRank{capacity: cloud.ZoneCapacity{Exhausted: true}, score: 3}
+	full := zoneRank{capacity: cloud.ZoneCapacity{UsedGiB: 100}, score: 2}
+	empty := zoneRank{score: 1}
+	assert.True(t, full.outranks(exhausted))
+	assert.True(t, empty.outranks(full))
+	assert.False(t, exhausted.outranks(empty))
+	assert.True(t, zoneRank{score: 2}.outranks(empty))
+}
+
+func TestZonalTopologies(t *testing.T) {
+	assert.Empty(t, zonalTopologies(nil))
+
+	requirement := &csi.TopologyRequirement{
+		Preferred: []*csi.Topology{
+			{Segments: map[string]string{ZoneTopologyKey: "us-east-1a"}},
+			{Segments: map[string]string{WellKnownZoneTopologyKey: "us-east-1a"}},
+			{Segments: map[string]string{WellKnownZoneTopologyKey: "us-east-1b"}},
+		},
+	}
+	topologies := zonalTopologies(requirement)
+	require.Len(t, topologies, 2)
+	assert.Equal(t, "us-east-1a", topologyZone(topologies[0]))
+	assert.Equal(t, "us-east-1b", topologyZone(topologies[1]))
+
+	requirement.Requisite = []*csi.Topology{{Segments: map[string]string{WellKnownZoneTopologyKey: "us-east-1c", AwsOutpostIDKey: "op-1"}}}
+	assert.Empty(t, zonalTopologies(requirement))
+}
@@ -18,17 +18,27 @@ package driver
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net"
+	"net/http"
+	"path"
+	"time"
 
 	"github.com/awslabs/volume-modifier-for-k8s/pkg/rpc"
 	csi "github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/kubernetes-csi/csi-lib-utils/protosanitizer"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud/metadata"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/mounter"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/nodeapi"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 )
@@ -46,6 +56,12 @@ const (
 
 	// MetadataLabelerMode is the mode that starts the metadata labeler.
 	MetadataLabelerMode Mode = "metadataLabeler"
+
+	// AdoptMode is the mode that adopts existing volumes and prints PV/PVC manifests for them.
+	AdoptMode Mode = "adopt"
+
+	// MigrateMode is the mode that migrates the volume of a PVC to another availability zone.
+	MigrateMode Mode = "migrate"
 )
 
 const (
@@ -72,6 +88,10 @@ type Driver struct {
 	controller *ControllerService
 	node       *NodeService
 	srv        *grpc.Server
+	// peerSrv serves the requests forwarded by other controller shards
+	peerSrv *grpc.Server
+	// nodeAPISrv serves the volumes of the node on --node-api-endpoint
+	nodeAPISrv *http.Server
 	options    *Options
 	csi.UnimplementedIdentityServer
 }
@@ -102,13 +122,13 @@ func NewDriver(c cloud.Cloud, o *Options, m mounter.Mounter, md metadata.Metadat
 
 	switch o.Mode {
 	case ControllerMode:
-		driver.controller = NewControllerService(c, o)
+		driver.controller = NewControllerService(c, o, k)
 	case NodeMode:
 		driver.node = NewNodeService(o, md, m, k)
 	case AllMode:
-		driver.controller = NewControllerService(c, o)
+		driver.controller = NewControllerService(c, o, k)
 		driver.node = NewNodeService(o, md, m, k)
-	case MetadataLabelerMode:
+	case MetadataLabelerMode, AdoptMode, MigrateMode:
 		return nil, fmt.Errorf("mode %s is not handled by the driver, it is handled separately in main", o.Mode)
 	default:
 		return nil, fmt.Errorf("unknown mode: %s", o.Mode)
@@ -123,22 +143,38 @@ func (d *Driver) Run() error {
 		return err
 	}
 
+	if scheme == "tcp" {
+		if !util.IsLoopbackAddress(addr) && !d.options.AllowNonLoopbackEndpoint {
+			return fmt.Errorf("CSI endpoint %s is not a loopback address, pass --allow-non-loopback-endpoint to listen on it", addr)
+		}
+		klog.Warningf("CSI endpoint %s is an unauthenticated TCP socket, only use it for development and debugging", addr)
+	}
+
 	listenConfig := net.ListenConfig{}
 	listener, err := listenConfig.Listen(context.Background(), scheme, addr)
 	if err != nil {
 		return err
 	}
 
-	logErr := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		resp, err := handler(ctx, req)
-		if err != nil {
-			klog.ErrorS(err, "GRPC error")
+	interceptors := []grpc.UnaryServerInterceptor{correlationIDInterceptor, subsystemInterceptor, logErrorInterceptor, operationMetricsInterceptor}
+	if d.options.PayloadLogSampleRate > 0 {
+		interceptors = append(interceptors, payloadLogInterceptor(d.options.PayloadLogSampleRate))
+	}
+	if d.controller != nil {
+		interceptors = append(interceptors, d.controller.operations.interceptor, d.controller.queuedRequestsInterceptor)
+		metrics.Recorder().HandleDebug(OperationsPath, d.controller.operations)
+		if d.controller.handoff != nil {
+			interceptors = append(interceptors, d.controller.handoffInterceptor)
+		}
+	}
+	if d.node != nil && d.options.NodeAPIEndpoint != "" {
+		if err := d.serveNodeAPI(); err != nil {
+			return err
 		}
-		return resp, err
 	}
 
 	opts := []grpc.ServerOption{
-		grpc.UnaryInterceptor(logErr),
+		grpc.ChainUnaryInterceptor(interceptors...),
 	}
 
 	if d.options.EnableOtelTracing {
@@ -164,10 +200,138 @@ func (d *Driver) Run() error {
 		return fmt.Errorf("unknown mode: %s", d.options.Mode)
 	}
 
+	if d.controller != nil && d.options.ControllerShardPeerAddress != "" {
+		if err := d.serveShardPeers(interceptors); err != nil {
+			return err
+		}
+	}
+
 	klog.V(4).InfoS("Listening for connections", "address", listener.Addr())
 	return d.srv.Serve(listener)
 }
 
+// serveShardPeers serves the requests forwarded by the other controller shards on --controller-shard-peer-address.
+func (d *Driver) serveShardPeers(interceptors []grpc.UnaryServerInterceptor) error {
+	listenConfig := net.ListenConfig{}
+	listener, err := listenConfig.Listen(context.Background(), "tcp", d.options.ControllerShardPeerAddress)
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := shardPeerTLSConfig(d.options)
+	if err != nil {
+		return err
+	}
+	d.peerSrv = grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)), grpc.ChainUnaryInterceptor(append([]grpc.UnaryServerInterceptor{shardPeerInterceptor}, interceptors...)...))
+	csi.RegisterControllerServer(d.peerSrv, d.controller)
+	rpc.RegisterModifyServer(d.peerSrv, d.controller)
+	klog.V(4).InfoS("Listening for requests forwarded by other controller shards", "address", listener.Addr())
+	go func() {
+		if err := d.peerSrv.Serve(listener); err != nil {
+			klog.ErrorS(err, "Could not serve requests forwarded by other controller shards")
+		}
+	}()
+	return nil
+}
+
+// serveNodeAPI serves the volumes of the node on --node-api-endpoint, for the metadata labeler and other tooling.
+func (d *Driver) serveNodeAPI() error {
+	listenConfig := net.ListenConfig{}
+	listener, err := listenConfig.Listen(context.Background(), "tcp", d.options.NodeAPIEndpoint)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(nodeapi.VolumesPath, d.node.volumes)
+	d.nodeAPISrv = &http.Server{Handler: mux, ReadHeaderTimeout: 3 * time.Second}
+	klog.V(4).InfoS("Serving the node API", "address", listener.Addr(), "path", nodeapi.VolumesPath)
+	go func() {
+		if err := d.nodeAPISrv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.ErrorS(err, "Could not serve the node API")
+		}
+	}()
+	return nil
+}
+
+// correlationIDInterceptor assigns a correlation ID to every RPC. The ID follows the request
+// through batched and coalesced EC2 calls so that their logs can be traced back to it.
+func correlationIDInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id := util.NewCorrelationID()
+	klog.V(6).InfoS("Assigned correlation ID", "method", info.FullMethod, "correlationID", id)
+	return handler(util.WithCorrelationIDs(ctx, id), req)
+}
+
+// rpcSubsystems maps the controller RPCs that call EC2 to the subsystem their EC2 calls are attributed to.
+var rpcSubsystems = map[string]util.Subsystem{
+	"CreateVolume":               util.SubsystemProvision,
+	"DeleteVolume":               util.SubsystemProvision,
+	"ValidateVolumeCapabilities": util.SubsystemProvision,
+	"ControllerPublishVolume":    util.SubsystemAttach,
+	"ControllerUnpublishVolume":  util.SubsystemAttach,
+	"CreateSnapshot":             util.SubsystemSnapshot,
+	"DeleteSnapshot":             util.SubsystemSnapshot,
+	"ListSnapshots":              util.SubsystemSnapshot,
+	"ControllerExpandVolume":     util.SubsystemModify,
+	"ControllerModifyVolume":     util.SubsystemModify,
+	"ModifyVolumeProperties":     util.SubsystemModify,
+}
+
+// subsystemInterceptor tags every RPC with the subsystem of the driver it belongs to, which is appended to the
+// user agent of its EC2 calls with --subsystem-user-agent.
+func subsystemInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if subsystem, ok := rpcSubsystems[path.Base(info.FullMethod)]; ok {
+		ctx = util.WithSubsystem(ctx, subsystem)
+	}
+	return handler(ctx, req)
+}
+
+// payloadLogInterceptor logs the full request and response of a sample of RPCs, with secrets redacted, so that
+// interoperability issues with the sidecars can be debugged without logging every RPC at a high log level.
+func payloadLogInterceptor(sampleRate float64) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if rand.Float64() >= sampleRate {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		klog.InfoS("Sampled GRPC call", "method", info.FullMethod, "correlationIDs", util.CorrelationIDs(ctx),
+			"request", protosanitizer.StripSecrets(req), "response", protosanitizer.StripSecrets(resp), "code", status.Code(err).String(), "duration", time.Since(start))
+		return resp, err
+	}
+}
+
+// logErrorInterceptor logs every failed RPC with its correlation ID and, when it failed because of an AWS error,
+// the AWS error code, request ID, operation and suggested action returned in the status details.
+func logErrorInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		keysAndValues := append([]any{"method", info.FullMethod, "correlationIDs", util.CorrelationIDs(ctx)}, statusDetailsKeysAndValues(err)...)
+		klog.ErrorS(err, "GRPC error", keysAndValues...)
+	}
+	return resp, err
+}
+
+// operationDurationBuckets cover CSI operations from cached node calls to attachments waiting minutes for EC2.
+var operationDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// operationMetricsInterceptor records the duration of every RPC. With tracing enabled, the span of the RPC is already
+// in ctx, so the observation links to its trace through an exemplar.
+func operationMetricsInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	labels := map[string]string{
+		"method": path.Base(info.FullMethod),
+		"code":   status.Code(err).String(),
+	}
+	metrics.Recorder().ObserveHistogramWithContext(ctx, metrics.OperationDuration, metrics.OperationDurationHelpText, time.Since(start).Seconds(), labels, operationDurationBuckets)
+	return resp, err
+}
+
 func (d *Driver) Stop() {
 	d.srv.Stop()
+	if d.peerSrv != nil {
+		d.peerSrv.Stop()
+	}
+	if d.nodeAPISrv != nil {
+		_ = d.nodeAPISrv.Close()
+	}
 }
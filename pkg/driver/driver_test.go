@@ -15,13 +15,20 @@
 package driver
 
 import (
+	"context"
 	"testing"
 
+	csi "github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/golang/mock/gomock"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud/metadata"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/mounter"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -96,3 +103,98 @@ func TestNewDriver(t *testing.T) {
 		})
 	}
 }
+
+func TestCorrelationIDInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/CreateVolume"}
+	var ids [][]string
+	handler := func(ctx context.Context, _ any) (any, error) {
+		ids = append(ids, util.CorrelationIDs(ctx))
+		return nil, nil
+	}
+
+	for range 2 {
+		_, err := correlationIDInterceptor(t.Context(), nil, info, handler)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, ids, 2)
+	require.Len(t, ids[0], 1)
+	require.Len(t, ids[1], 1)
+	require.NotEqual(t, ids[0][0], ids[1][0])
+}
+
+func TestSubsystemInterceptor(t *testing.T) {
+	testCases := []struct {
+		method   string
+		expected util.Subsystem
+	}{
+		{method: "/csi.v1.Controller/CreateVolume", expected: util.SubsystemProvision},
+		{method: "/csi.v1.Controller/ControllerPublishVolume", expected: util.SubsystemAttach},
+		{method: "/csi.v1.Controller/CreateSnapshot", expected: util.SubsystemSnapshot},
+		{method: "/modify.v1alpha1.Modify/ModifyVolumeProperties", expected: util.SubsystemModify},
+		{method: "/csi.v1.Node/NodeStageVolume", expected: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.method, func(t *testing.T) {
+			info := &grpc.UnaryServerInfo{FullMethod: tc.method}
+			_, err := subsystemInterceptor(t.Context(), nil, info, func(ctx context.Context, _ any) (any, error) {
+				require.Equal(t, tc.expected, util.SubsystemFromContext(ctx))
+				return nil, nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPayloadLogInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/CreateVolume"}
+	req := &csi.CreateVolumeRequest{Name: "pvc-1", Secrets: map[string]string{"key": "secret"}}
+	expectedResp := &csi.CreateVolumeResponse{Volume: &csi.Volume{VolumeId: "vol-test"}}
+	for _, sampleRate := range []float64{0.000001, 1} {
+		calls := 0
+		handler := func(_ context.Context, r any) (any, error) {
+			calls++
+			require.Equal(t, req, r)
+			return expectedResp, status.Error(codes.Internal, "failed")
+		}
+
+		resp, err := payloadLogInterceptor(sampleRate)(t.Context(), req, info, handler)
+		require.Equal(t, expectedResp, resp)
+		require.Equal(t, codes.Internal, status.Code(err))
+		require.Equal(t, 1, calls)
+		require.Equal(t, "secret", req.GetSecrets()["key"], "logging must not modify the request")
+	}
+}
+
+func TestOperationMetricsInterceptor(t *testing.T) {
+	_, registry := metrics.InitializeRecorder(false)
+	info := &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/ControllerPublishVolume"}
+	handler := func(_ context.Context, _ any) (any, error) {
+		return nil, status.Error(codes.NotFound, "volume not found")
+	}
+
+	_, err := operationMetricsInterceptor(t.Context(), nil, info, handler)
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var labels map[string]string
+	for _, family := range families {
+		if family.GetName() != metrics.OperationDuration {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels = map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+		}
+	}
+	require.Equal(t, map[string]string{"method": "ControllerPublishVolume", "code": "NotFound"}, labels)
+}
+
+func TestRunRejectsNonLoopbackTCPEndpoint(t *testing.T) {
+	d := &Driver{options: &Options{Mode: NodeMode, Endpoint: "tcp://0.0.0.0:10000"}}
+	err := d.Run()
+	require.EqualError(t, err, "CSI endpoint 0.0.0.0:10000 is not a loopback address, pass --allow-non-loopback-endpoint to listen on it")
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+)
+
+// The timings of the leader elections of the background loops of the controller, which match the defaults of
+// csi-lib-utils. They are variables so that tests can shorten them.
+var (
+	leaderElectionLeaseDuration = 15 * time.Second
+	leaderElectionRenewDeadline = 10 * time.Second
+	leaderElectionRetryPeriod   = 5 * time.Second
+)
+
+var invalidLeaseNameChars = regexp.MustCompile(`[^a-zA-Z0-9-]`)
+
+// runLeaderElection calls run whenever this replica is elected leader of the Lease name, until ctx is done. The
+// context passed to run is cancelled when the leadership is lost, after which the replica runs for election again.
+// Unlike the leader election of csi-lib-utils, losing the leadership does not exit the process, so that a
+// background loop does not take the rest of the controller down with it.
+func runLeaderElection(ctx context.Context, k kubernetes.Interface, name string, run func(context.Context)) {
+	identity, err := os.Hostname()
+	if err != nil {
+		klog.ErrorS(err, "Could not get the identity of the leader election", "lease", name)
+		return
+	}
+	lock, err := resourcelock.New(resourcelock.LeasesResourceLock, podNamespace(), leaseName(name), k.CoreV1(), k.CoordinationV1(), resourcelock.ResourceLockConfig{Identity: identity})
+	if err != nil {
+		klog.ErrorS(err, "Could not create the lock of the leader election", "lease", name)
+		return
+	}
+
+	// Runs are serialized so that a new term never overlaps with the end of the previous one.
+	var running sync.Mutex
+	for ctx.Err() == nil {
+		le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			LeaseDuration:   leaderElectionLeaseDuration,
+			RenewDeadline:   leaderElectionRenewDeadline,
+			RetryPeriod:     leaderElectionRetryPeriod,
+			ReleaseOnCancel: true,
+			Name:            name,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(ctx context.Context) {
+					running.Lock()
+					defer running.Unlock()
+					klog.InfoS("Became leader", "lease", name)
+					run(ctx)
+				},
+				OnStoppedLeading: func() {
+					klog.InfoS("Stopped leading", "lease", name)
+				},
+			},
+		})
+		if err != nil {
+			klog.ErrorS(err, "Could not run leader election", "lease", name)
+			return
+		}
+		le.Run(ctx)
+	}
+}
+
+// leaseName returns a valid Lease name for name, the same as csi-lib-utils, so that replicas of older releases
+// contend for the same Lease during an upgrade.
+func leaseName(name string) string {
+	name = invalidLeaseNameChars.ReplaceAllString(name, "-")
+	if strings.HasSuffix(name, "-") {
+		name += "X"
+	}
+	return name
+}
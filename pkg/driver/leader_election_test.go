@@ -0,0 +1,91 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestLeaseName(t *testing.T) {
+	assert.Equal(t, "soft-delete-ebs-csi-aws-com", leaseName("soft-delete-ebs.csi.aws.com"))
+	assert.Equal(t, "volume-health-X", leaseName("volume-health."))
+}
+
+func TestRunLeaderElectionRunsAgainAfterLosingLeadership(t *testing.T) {
+	oldLease, oldRenew, oldRetry := leaderElectionLeaseDuration, leaderElectionRenewDeadline, leaderElectionRetryPeriod
+	t.Cleanup(func() {
+		leaderElectionLeaseDuration, leaderElectionRenewDeadline, leaderElectionRetryPeriod = oldLease, oldRenew, oldRetry
+	})
+	leaderElectionLeaseDuration, leaderElectionRenewDeadline, leaderElectionRetryPeriod = time.Second, 500*time.Millisecond, 100*time.Millisecond
+
+	client := fake.NewClientset()
+	var failRenewals atomic.Bool
+	client.PrependReactor("update", "leases", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if failRenewals.Load() {
+			return true, nil, errors.New("update error")
+		}
+		return false, nil, nil
+	})
+	started := make(chan context.Context, 2)
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		runLeaderElection(ctx, client, "test.lease", func(ctx context.Context) {
+			started <- ctx
+			<-ctx.Done()
+		})
+		close(done)
+	}()
+
+	var first context.Context
+	select {
+	case first = <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the replica was not elected leader")
+	}
+
+	// The Lease cannot be renewed for a while: the run must be stopped, and the replica must run for election again
+	// instead of exiting
+	failRenewals.Store(true)
+	select {
+	case <-first.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("the run was not stopped when the leadership was lost")
+	}
+	failRenewals.Store(false)
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the replica was not elected leader again")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the leader election did not stop when its context was cancelled")
+	}
+}
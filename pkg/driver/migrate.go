@@ -0,0 +1,440 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// MigratedFromAnnotation is set on the PVCs and PVs rebound by the migrate command to the ID of the volume they
+	// were migrated from.
+	MigratedFromAnnotation = "ebs.csi.aws.com/migrated-from"
+
+	// Reasons of the events the migrate command records on the migrated PVC.
+	VolumeMigrationStarted         = "VolumeMigrationStarted"
+	VolumeMigrationSnapshotCreated = "VolumeMigrationSnapshotCreated"
+	VolumeMigrationVolumeCreated   = "VolumeMigrationVolumeCreated"
+	VolumeMigrated                 = "VolumeMigrated"
+	VolumeMigrationFailed          = "VolumeMigrationFailed"
+
+	migrationPollInterval = 15 * time.Second
+)
+
+// migrationDroppedPVCAnnotations are set by the PV controller and the provisioner on bound PVCs. They are not copied
+// to the recreated PVC, which must be bound to the new PV from scratch. The selected node is in the old zone.
+var migrationDroppedPVCAnnotations = []string{
+	"pv.kubernetes.io/bind-completed",
+	"pv.kubernetes.io/bound-by-controller",
+	"volume.beta.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/selected-node",
+}
+
+// MigrateVolume moves the volume of the PVC selected by --pvc to the availability zone --target-zone. It snapshots
+// the volume, restores the snapshot in the target zone, and rebinds the PVC to a new PV for the restored volume.
+//
+// The PVC must not be used by any pod. The old PV is switched to the Retain reclaim policy and left Released with
+// its volume, so that it can be deleted once the migrated data has been checked. Every step can be resumed by running
+// the command again: the snapshot, volume and PV are found by name. The manifests of the new PV and PVC are written
+// to w before the PVC is deleted, so that they can be applied by hand if the command is interrupted before the PVC
+// is recreated.
+func MigrateVolume(ctx context.Context, c cloud.Cloud, k kubernetes.Interface, o *Options, w io.Writer) error {
+	namespace, name, _ := strings.Cut(o.MigratePVC, "/")
+	pvc, err := k.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get PVC %s: %w", o.MigratePVC, err)
+	}
+	if pvc.Status.Phase != corev1.ClaimBound || pvc.Spec.VolumeName == "" {
+		return fmt.Errorf("PVC %s is not bound", o.MigratePVC)
+	}
+	pv, err := k.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get PV %s: %w", pvc.Spec.VolumeName, err)
+	}
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != util.GetDriverName() {
+		return fmt.Errorf("PV %s is not provisioned by %s", pv.Name, util.GetDriverName())
+	}
+
+	disks, err := c.ListDisks(ctx, []string{pv.Spec.CSI.VolumeHandle}, nil)
+	if err != nil {
+		return fmt.Errorf("could not get volume %s: %w", pv.Spec.CSI.VolumeHandle, err)
+	}
+	if len(disks) == 0 {
+		return fmt.Errorf("volume %s of PV %s not found", pv.Spec.CSI.VolumeHandle, pv.Name)
+	}
+	source := disks[0]
+	if source.AvailabilityZone == o.MigrateTargetZone {
+		klog.InfoS("MigrateVolume: volume is already in the target zone", "pvc", klog.KObj(pvc), "pv", pv.Name, "volumeID", source.VolumeID, "zone", source.AvailabilityZone)
+		return nil
+	}
+	if source.OutpostArn != "" {
+		return fmt.Errorf("volume %s is on Outpost %s and cannot be migrated to another zone", source.VolumeID, source.OutpostArn)
+	}
+	if err := checkVolumeNotInUse(ctx, k, pvc, pv, source); err != nil {
+		return err
+	}
+
+	m := &volumeMigration{cloud: c, client: k, options: o, pvc: pvc, pv: pv, source: source}
+	m.event(ctx, corev1.EventTypeNormal, VolumeMigrationStarted, fmt.Sprintf("Migrating volume %s from zone %s to zone %s", source.VolumeID, source.AvailabilityZone, o.MigrateTargetZone))
+	if err := m.run(ctx, w); err != nil {
+		m.event(ctx, corev1.EventTypeWarning, VolumeMigrationFailed, err.Error())
+		return err
+	}
+	return nil
+}
+
+// checkVolumeNotInUse returns an error when a pod uses pvc or the volume of pv is attached. Migrating a volume that
+// is written to would lose the writes made after the snapshot.
+func checkVolumeNotInUse(ctx context.Context, k kubernetes.Interface, pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume, source *cloud.Disk) error {
+	pods, err := k.CoreV1().Pods(pvc.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("could not list pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, v := range pod.Spec.Volumes {
+			if v.PersistentVolumeClaim != nil && v.PersistentVolumeClaim.ClaimName == pvc.Name {
+				return fmt.Errorf("PVC %s is used by pod %s, scale down its workload first", klog.KObj(pvc), pod.Name)
+			}
+		}
+	}
+
+	attachments, err := k.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("could not list volume attachments: %w", err)
+	}
+	for _, va := range attachments.Items {
+		if va.Spec.Source.PersistentVolumeName != nil && *va.Spec.Source.PersistentVolumeName == pv.Name {
+			return fmt.Errorf("PV %s is attached to node %s, wait for it to be detached", pv.Name, va.Spec.NodeName)
+		}
+	}
+	if len(source.Attachments) > 0 {
+		return fmt.Errorf("volume %s is attached to %s, wait for it to be detached", source.VolumeID, strings.Join(source.Attachments, ", "))
+	}
+	return nil
+}
+
+// volumeMigration holds the state of a MigrateVolume run.
+type volumeMigration struct {
+	cloud   cloud.Cloud
+	client  kubernetes.Interface
+	options *Options
+	pvc     *corev1.PersistentVolumeClaim
+	pv      *corev1.PersistentVolume
+	source  *cloud.Disk
+}
+
+func (m *volumeMigration) run(ctx context.Context, w io.Writer) error {
+	newPV := m.newPV()
+	target, err := m.createVolume(ctx, newPV)
+	if err != nil {
+		return err
+	}
+	newPV.Spec.CSI.VolumeHandle = target.VolumeID
+	newPV.Spec.NodeAffinity = migratedNodeAffinity(m.pv.Spec.NodeAffinity, m.source, target)
+	newPVC := m.newPVC(newPV)
+
+	for _, obj := range []any{newPV, newPVC} {
+		manifest, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("could not marshal manifest: %w", err)
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", manifest); err != nil {
+			return err
+		}
+	}
+
+	if err := m.rebind(ctx, newPV, newPVC); err != nil {
+		return err
+	}
+	klog.InfoS("MigrateVolume: volume migrated", "pvc", klog.KObj(m.pvc), "oldPV", m.pv.Name, "oldVolumeID", m.source.VolumeID, "pv", newPV.Name, "volumeID", target.VolumeID, "zone", target.AvailabilityZone)
+	m.event(ctx, corev1.EventTypeNormal, VolumeMigrated, fmt.Sprintf("Migrated to volume %s in zone %s and PV %s. PV %s and volume %s are retained", target.VolumeID, target.AvailabilityZone, newPV.Name, m.pv.Name, m.source.VolumeID))
+	return nil
+}
+
+// createVolume restores a snapshot of the source volume in the target zone, with the type, performance and
+// encryption of the source volume. A volume restored by an earlier run is reused.
+func (m *volumeMigration) createVolume(ctx context.Context, newPV *corev1.PersistentVolume) (*cloud.Disk, error) {
+	capacityBytes := util.GiBToBytes(m.source.CapacityGiB)
+	disk, err := m.cloud.GetDiskByName(ctx, newPV.Name, capacityBytes)
+	switch {
+	case err == nil:
+		klog.InfoS("MigrateVolume: reusing volume restored by an earlier run", "volumeID", disk.VolumeID, "pv", newPV.Name)
+	case errors.Is(err, cloud.ErrNotFound):
+		snapshot, err := m.createSnapshot(ctx, newPV.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		opts := &cloud.DiskOptions{
+			CapacityBytes:    capacityBytes,
+			Tags:             adoptedVolumeTags(newPV, m.pvc, m.options.KubernetesClusterID),
+			VolumeType:       m.source.VolumeType,
+			AvailabilityZone: m.options.MigrateTargetZone,
+			Encrypted:        m.source.Encrypted,
+			KmsKeyID:         m.source.KmsKeyID,
+			SnapshotID:       snapshot.SnapshotID,
+		}
+		// The IOPS of gp2 volumes and the throughput of other types than gp3 are reported but cannot be set
+		switch m.source.VolumeType {
+		case cloud.VolumeTypeGP3:
+			opts.IOPS = m.source.IOPS
+			opts.Throughput = m.source.Throughput
+		case cloud.VolumeTypeIO1, cloud.VolumeTypeIO2:
+			opts.IOPS = m.source.IOPS
+		}
+		if disk, err = m.cloud.CreateDisk(ctx, newPV.Name, opts); err != nil {
+			return nil, fmt.Errorf("could not create volume from snapshot %s: %w", snapshot.SnapshotID, err)
+		}
+		klog.InfoS("MigrateVolume: volume created", "volumeID", disk.VolumeID, "zone", disk.AvailabilityZone, "snapshotID", snapshot.SnapshotID)
+
+		// The source volume is retained, the snapshot is not needed anymore
+		if _, err := m.cloud.DeleteSnapshot(ctx, snapshot.SnapshotID); err != nil {
+			klog.ErrorS(err, "MigrateVolume: could not delete snapshot, it must be deleted manually", "snapshotID", snapshot.SnapshotID)
+		}
+	default:
+		return nil, fmt.Errorf("could not get volume %s: %w", newPV.Name, err)
+	}
+	if disk.AvailabilityZone != m.options.MigrateTargetZone {
+		return nil, fmt.Errorf("volume %s named %s is in zone %s instead of %s", disk.VolumeID, newPV.Name, disk.AvailabilityZone, m.options.MigrateTargetZone)
+	}
+
+	// CreateDisk and GetDiskByName do not return the zone ID
+	disks, err := m.cloud.ListDisks(ctx, []string{disk.VolumeID}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not get volume %s: %w", disk.VolumeID, err)
+	}
+	if len(disks) == 0 {
+		return nil, fmt.Errorf("volume %s not found", disk.VolumeID)
+	}
+	m.event(ctx, corev1.EventTypeNormal, VolumeMigrationVolumeCreated, fmt.Sprintf("Created volume %s in zone %s", disk.VolumeID, disk.AvailabilityZone))
+	return disks[0], nil
+}
+
+// createSnapshot snapshots the source volume and waits for the snapshot to complete. A snapshot started by an earlier
+// run is reused, unless it failed, in which case it is deleted so that the next run starts over.
+func (m *volumeMigration) createSnapshot(ctx context.Context, name string) (*cloud.Snapshot, error) {
+	snapshotName := "migrate-" + name
+	snapshot, err := m.cloud.GetSnapshotByName(ctx, snapshotName)
+	if errors.Is(err, cloud.ErrNotFound) {
+		opts := &cloud.SnapshotOptions{Tags: map[string]string{
+			cloud.SnapshotNameTagKey: snapshotName,
+			cloud.AwsEbsDriverTagKey: isManagedByDriver,
+		}}
+		snapshot, err = m.cloud.CreateSnapshot(ctx, m.source.VolumeID, opts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not snapshot volume %s: %w", m.source.VolumeID, err)
+	}
+	klog.InfoS("MigrateVolume: waiting for snapshot", "snapshotID", snapshot.SnapshotID, "volumeID", m.source.VolumeID)
+
+	err = wait.PollUntilContextCancel(ctx, migrationPollInterval, true, func(ctx context.Context) (bool, error) {
+		if snapshot.Failed {
+			return false, errSnapshotFailed
+		}
+		if snapshot.ReadyToUse {
+			return true, nil
+		}
+		latest, err := m.cloud.GetSnapshotByID(ctx, snapshot.SnapshotID)
+		if errors.Is(err, cloud.ErrNotFound) {
+			// DescribeSnapshots is eventually consistent
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		snapshot = latest
+		if snapshot.Failed {
+			return false, errSnapshotFailed
+		}
+		return snapshot.ReadyToUse, nil
+	})
+	if errors.Is(err, errSnapshotFailed) {
+		// The next run takes a new snapshot instead of waiting on this one
+		if _, deleteErr := m.cloud.DeleteSnapshot(ctx, snapshot.SnapshotID); deleteErr != nil && !errors.Is(deleteErr, cloud.ErrNotFound) {
+			klog.ErrorS(deleteErr, "MigrateVolume: could not delete failed snapshot", "snapshotID", snapshot.SnapshotID)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("snapshot of volume %s did not complete: %w", m.source.VolumeID, err)
+	}
+	m.event(ctx, corev1.EventTypeNormal, VolumeMigrationSnapshotCreated, fmt.Sprintf("Created snapshot %s of volume %s", snapshot.SnapshotID, m.source.VolumeID))
+	return snapshot, nil
+}
+
+// rebind retains the old PV, creates the new one and recreates the PVC bound to it.
+func (m *volumeMigration) rebind(ctx context.Context, newPV *corev1.PersistentVolume, newPVC *corev1.PersistentVolumeClaim) error {
+	if m.pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+		pv := m.pv.DeepCopy()
+		pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+		if _, err := m.client.CoreV1().PersistentVolumes().Update(ctx, pv, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("could not retain PV %s: %w", pv.Name, err)
+		}
+	}
+
+	if _, err := m.client.CoreV1().PersistentVolumes().Create(ctx, newPV, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("could not create PV %s: %w", newPV.Name, err)
+	}
+
+	pvcs := m.client.CoreV1().PersistentVolumeClaims(m.pvc.Namespace)
+	if err := pvcs.Delete(ctx, m.pvc.Name, metav1.DeleteOptions{Preconditions: metav1.NewUIDPreconditions(string(m.pvc.UID))}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("could not delete PVC %s: %w", klog.KObj(m.pvc), err)
+	}
+	klog.InfoS("MigrateVolume: waiting for PVC to be deleted", "pvc", klog.KObj(m.pvc))
+	err := wait.PollUntilContextCancel(ctx, migrationPollInterval, true, func(ctx context.Context) (bool, error) {
+		_, err := pvcs.Get(ctx, m.pvc.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		return fmt.Errorf("PVC %s was not deleted: %w", klog.KObj(m.pvc), err)
+	}
+
+	created, err := pvcs.Create(ctx, newPVC, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("could not recreate PVC %s, apply the printed manifest to recreate it: %w", klog.KObj(m.pvc), err)
+	}
+	m.pvc = created
+	return nil
+}
+
+// newPV returns the PV of the migrated volume, named after the old PV and the target zone. The volume handle and
+// node affinity are set once the volume is created.
+func (m *volumeMigration) newPV() *corev1.PersistentVolume {
+	annotations := map[string]string{}
+	for k, v := range m.pv.Annotations {
+		if k != "pv.kubernetes.io/bound-by-controller" {
+			annotations[k] = v
+		}
+	}
+	annotations[MigratedFromAnnotation] = m.source.VolumeID
+
+	spec := m.pv.Spec.DeepCopy()
+	spec.ClaimRef = &corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "PersistentVolumeClaim",
+		Name:       m.pvc.Name,
+		Namespace:  m.pvc.Namespace,
+	}
+	return &corev1.PersistentVolume{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolume"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        m.pv.Name + "-" + m.options.MigrateTargetZone,
+			Labels:      m.pv.Labels,
+			Annotations: annotations,
+		},
+		Spec: *spec,
+	}
+}
+
+// newPVC returns the PVC to recreate, bound to newPV.
+func (m *volumeMigration) newPVC(newPV *corev1.PersistentVolume) *corev1.PersistentVolumeClaim {
+	annotations := map[string]string{}
+	for k, v := range m.pvc.Annotations {
+		if !slices.Contains(migrationDroppedPVCAnnotations, k) {
+			annotations[k] = v
+		}
+	}
+	annotations[MigratedFromAnnotation] = m.source.VolumeID
+
+	spec := m.pvc.Spec.DeepCopy()
+	spec.VolumeName = newPV.Name
+	return &corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            m.pvc.Name,
+			Namespace:       m.pvc.Namespace,
+			Labels:          m.pvc.Labels,
+			Annotations:     annotations,
+			OwnerReferences: m.pvc.OwnerReferences,
+		},
+		Spec: *spec,
+	}
+}
+
+// migratedNodeAffinity returns the node affinity of the PV of target, with the zones of source replaced by the zone
+// of target.
+func migratedNodeAffinity(affinity *corev1.VolumeNodeAffinity, source, target *cloud.Disk) *corev1.VolumeNodeAffinity {
+	if affinity == nil || affinity.Required == nil {
+		return affinity
+	}
+	affinity = affinity.DeepCopy()
+	for _, term := range affinity.Required.NodeSelectorTerms {
+		for i, expr := range term.MatchExpressions {
+			for j, value := range expr.Values {
+				switch value {
+				case source.AvailabilityZone:
+					term.MatchExpressions[i].Values[j] = target.AvailabilityZone
+				case source.AvailabilityZoneID:
+					term.MatchExpressions[i].Values[j] = target.AvailabilityZoneID
+				}
+			}
+		}
+	}
+	return affinity
+}
+
+// event records an event on the migrated PVC. Events are created synchronously because the command exits as soon as
+// the migration is done.
+func (m *volumeMigration) event(ctx context.Context, eventType, reason, message string) {
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", m.pvc.Name, now.UnixNano()),
+			Namespace: m.pvc.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      "v1",
+			Kind:            "PersistentVolumeClaim",
+			Name:            m.pvc.Name,
+			Namespace:       m.pvc.Namespace,
+			UID:             m.pvc.UID,
+			ResourceVersion: m.pvc.ResourceVersion,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: util.GetDriverName() + "-migrate"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if _, err := m.client.CoreV1().Events(m.pvc.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		klog.ErrorS(err, "MigrateVolume: could not record event", "pvc", klog.KObj(m.pvc), "reason", reason)
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newMigrationObjects() (*corev1.PersistentVolumeClaim, *corev1.PersistentVolume) {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "data",
+			Namespace: "apps",
+			UID:       "pvc-uid",
+			Labels:    map[string]string{"app": "db"},
+			Annotations: map[string]string{
+				"pv.kubernetes.io/bind-completed":    "yes",
+				"volume.kubernetes.io/selected-node": "node-1",
+				"example.com/owner":                  "storage",
+			},
+		},
+		Spec:   corev1.PersistentVolumeClaimSpec{VolumeName: "pvc-1234"},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-1234"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+			ClaimRef:                      &corev1.ObjectReference{Name: "data", Namespace: "apps", UID: "pvc-uid"},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: util.GetDriverName(), VolumeHandle: "vol-source"},
+			},
+			NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: WellKnownZoneTopologyKey, Operator: corev1.NodeSelectorOpIn, Values: []string{"us-east-1a"}},
+					{Key: ZoneIDTopologyKey, Operator: corev1.NodeSelectorOpIn, Values: []string{"use1-az1"}},
+				}}},
+			}},
+		},
+	}
+	return pvc, pv
+}
+
+func TestMigrateVolume(t *testing.T) {
+	source := &cloud.Disk{VolumeID: "vol-source", CapacityGiB: 100, AvailabilityZone: "us-east-1a", AvailabilityZoneID: "use1-az1", VolumeType: cloud.VolumeTypeGP3, IOPS: 4000, Throughput: 250, Encrypted: true, KmsKeyID: "key"}
+	target := &cloud.Disk{VolumeID: "vol-target", CapacityGiB: 100, AvailabilityZone: "us-east-1b", AvailabilityZoneID: "use1-az2"}
+	options := &Options{MigratePVC: "apps/data", MigrateTargetZone: "us-east-1b"}
+	pvcUser := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "apps"},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+		}}}},
+	}
+	pvName := "pvc-1234"
+	attachment := &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "csi-1234"},
+		Spec:       storagev1.VolumeAttachmentSpec{NodeName: "node-1", Source: storagev1.VolumeAttachmentSource{PersistentVolumeName: &pvName}},
+	}
+
+	testCases := []struct {
+		name        string
+		objects     []runtime.Object
+		source      *cloud.Disk
+		expectedErr string
+	}{
+		{
+			name:        "fail: pod uses the PVC",
+			objects:     []runtime.Object{pvcUser},
+			source:      source,
+			expectedErr: "is used by pod db-0",
+		},
+		{
+			name:        "fail: volume attached",
+			objects:     []runtime.Object{attachment},
+			source:      source,
+			expectedErr: "is attached to node node-1",
+		},
+		{
+			name:        "fail: volume on an Outpost",
+			source:      &cloud.Disk{VolumeID: "vol-source", AvailabilityZone: "us-east-1a", OutpostArn: "arn:aws:outposts:us-east-1:123456789012:outpost/op-1"},
+			expectedErr: "is on Outpost",
+		},
+		{
+			name:   "success: volume already in the target zone",
+			source: &cloud.Disk{VolumeID: "vol-source", AvailabilityZone: "us-east-1b"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pvc, pv := newMigrationObjects()
+			k := fake.NewClientset(append(tc.objects, pvc, pv)...)
+			mockCloud := cloud.NewMockCloud(gomock.NewController(t))
+			mockCloud.EXPECT().ListDisks(gomock.Any(), []string{"vol-source"}, gomock.Any()).Return([]*cloud.Disk{tc.source}, nil)
+
+			err := MigrateVolume(t.Context(), mockCloud, k, options, &bytes.Buffer{})
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			_, err = k.CoreV1().PersistentVolumes().Get(t.Context(), "pvc-1234-us-east-1b", metav1.GetOptions{})
+			assert.Error(t, err, "no PV must be created")
+		})
+	}
+
+	t.Run("success: volume migrated and PVC rebound", func(t *testing.T) {
+		pvc, pv := newMigrationObjects()
+		k := fake.NewClientset(pvc, pv)
+		mockCloud := cloud.NewMockCloud(gomock.NewController(t))
+		gomock.InOrder(
+			mockCloud.EXPECT().ListDisks(gomock.Any(), []string{"vol-source"}, gomock.Any()).Return([]*cloud.Disk{source}, nil),
+			mockCloud.EXPECT().GetDiskByName(gomock.Any(), "pvc-1234-us-east-1b", util.GiBToBytes(100)).Return(nil, cloud.ErrNotFound),
+			mockCloud.EXPECT().GetSnapshotByName(gomock.Any(), "migrate-pvc-1234-us-east-1b").Return(nil, cloud.ErrNotFound),
+			mockCloud.EXPECT().CreateSnapshot(gomock.Any(), "vol-source", gomock.Any()).Return(&cloud.Snapshot{SnapshotID: "snap-test"}, nil),
+			mockCloud.EXPECT().GetSnapshotByID(gomock.Any(), "snap-test").Return(&cloud.Snapshot{SnapshotID: "snap-test", ReadyToUse: true}, nil),
+			mockCloud.EXPECT().CreateDisk(gomock.Any(), "pvc-1234-us-east-1b", gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ string, opts *cloud.DiskOptions) (*cloud.Disk, error) {
+					assert.Equal(t, "snap-test", opts.SnapshotID)
+					assert.Equal(t, "us-east-1b", opts.AvailabilityZone)
+					assert.Equal(t, cloud.VolumeTypeGP3, opts.VolumeType)
+					assert.Equal(t, int32(4000), opts.IOPS)
+					assert.Equal(t, int32(250), opts.Throughput)
+					assert.True(t, opts.Encrypted)
+					assert.Equal(t, "key", opts.KmsKeyID)
+					assert.Equal(t, "pvc-1234-us-east-1b", opts.Tags[PVNameTag])
+					assert.Equal(t, "data", opts.Tags[PVCNameTag])
+					return &cloud.Disk{VolumeID: "vol-target", CapacityGiB: 100, AvailabilityZone: "us-east-1b"}, nil
+				}),
+			mockCloud.EXPECT().DeleteSnapshot(gomock.Any(), "snap-test").Return(true, nil),
+			mockCloud.EXPECT().ListDisks(gomock.Any(), []string{"vol-target"}, gomock.Any()).Return([]*cloud.Disk{target}, nil),
+		)
+
+		var out bytes.Buffer
+		require.NoError(t, MigrateVolume(t.Context(), mockCloud, k, options, &out))
+		assert.Contains(t, out.String(), "volumeHandle: vol-target")
+
+		oldPV, err := k.CoreV1().PersistentVolumes().Get(t.Context(), "pvc-1234", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, corev1.PersistentVolumeReclaimRetain, oldPV.Spec.PersistentVolumeReclaimPolicy)
+
+		newPV, err := k.CoreV1().PersistentVolumes().Get(t.Context(), "pvc-1234-us-east-1b", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "vol-target", newPV.Spec.CSI.VolumeHandle)
+		assert.Equal(t, corev1.PersistentVolumeReclaimDelete, newPV.Spec.PersistentVolumeReclaimPolicy)
+		assert.Equal(t, "vol-source", newPV.Annotations[MigratedFromAnnotation])
+		assert.Empty(t, newPV.Spec.ClaimRef.UID)
+		exprs := newPV.Spec.NodeAffinity.Required.NodeSelectorTerms[0].MatchExpressions
+		assert.Equal(t, []string{"us-east-1b"}, exprs[0].Values)
+		assert.Equal(t, []string{"use1-az2"}, exprs[1].Values)
+
+		newPVC, err := k.CoreV1().PersistentVolumeClaims("apps").Get(t.Context(), "data", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "pvc-1234-us-east-1b", newPVC.Spec.VolumeName)
+		assert.Equal(t, map[string]string{"app": "db"}, newPVC.Labels)
+		assert.Equal(t, map[string]string{"example.com/owner": "storage", MigratedFromAnnotation: "vol-source"}, newPVC.Annotations)
+
+		events, err := k.CoreV1().Events("apps").List(t.Context(), metav1.ListOptions{})
+		require.NoError(t, err)
+		var reasons []string
+		for _, e := range events.Items {
+			reasons = append(reasons, e.Reason)
+		}
+		assert.ElementsMatch(t, []string{VolumeMigrationStarted, VolumeMigrationSnapshotCreated, VolumeMigrationVolumeCreated, VolumeMigrated}, reasons)
+	})
+
+	t.Run("fail: snapshot failed and deleted", func(t *testing.T) {
+		pvc, pv := newMigrationObjects()
+		k := fake.NewClientset(pvc, pv)
+		mockCloud := cloud.NewMockCloud(gomock.NewController(t))
+		gomock.InOrder(
+			mockCloud.EXPECT().ListDisks(gomock.Any(), []string{"vol-source"}, gomock.Any()).Return([]*cloud.Disk{source}, nil),
+			mockCloud.EXPECT().GetDiskByName(gomock.Any(), "pvc-1234-us-east-1b", util.GiBToBytes(100)).Return(nil, cloud.ErrNotFound),
+			mockCloud.EXPECT().GetSnapshotByName(gomock.Any(), "migrate-pvc-1234-us-east-1b").Return(&cloud.Snapshot{SnapshotID: "snap-test"}, nil),
+			mockCloud.EXPECT().GetSnapshotByID(gomock.Any(), "snap-test").Return(&cloud.Snapshot{SnapshotID: "snap-test", Failed: true}, nil),
+			mockCloud.EXPECT().DeleteSnapshot(gomock.Any(), "snap-test").Return(true, nil),
+		)
+		mockCloud.EXPECT().CreateDisk(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		err := MigrateVolume(t.Context(), mockCloud, k, options, &bytes.Buffer{})
+		require.ErrorIs(t, err, errSnapshotFailed)
+	})
+
+	t.Run("success: volume restored by an earlier run is reused", func(t *testing.T) {
+		pvc, pv := newMigrationObjects()
+		k := fake.NewClientset(pvc, pv)
+		mockCloud := cloud.NewMockCloud(gomock.NewController(t))
+		mockCloud.EXPECT().ListDisks(gomock.Any(), []string{"vol-source"}, gomock.Any()).Return([]*cloud.Disk{source}, nil)
+		mockCloud.EXPECT().GetDiskByName(gomock.Any(), "pvc-1234-us-east-1b", util.GiBToBytes(100)).Return(&cloud.Disk{VolumeID: "vol-target", AvailabilityZone: "us-east-1b"}, nil)
+		mockCloud.EXPECT().ListDisks(gomock.Any(), []string{"vol-target"}, gomock.Any()).Return([]*cloud.Disk{target}, nil)
+
+		var out bytes.Buffer
+		require.NoError(t, MigrateVolume(t.Context(), mockCloud, k, options, &out))
+		assert.Equal(t, 2, strings.Count(out.String(), "---\n"))
+	})
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
+)
+
+const (
+	// DefaultNameTagTemplate is the default template of the Name tag of volumes.
+	DefaultNameTagTemplate = "{{ .PVCNamespace }}/{{ .PVCName }}"
+	// DefaultSnapshotNameTagTemplate is the default template of the Name tag of snapshots.
+	DefaultSnapshotNameTagTemplate = "{{ .VolumeSnapshotNamespace }}/{{ .VolumeSnapshotName }}"
+
+	// maxTagValueLength is the maximum number of characters of an EC2 tag value.
+	maxTagValueLength = 256
+	// nameTagHashLength is the number of hex characters of the resource name hash appended to templated Name tags.
+	nameTagHashLength = 8
+)
+
+// templatedNameTag renders the Name tag of a volume or snapshot from tmpl. A short hash of resourceName, the
+// unique CSI name of the volume or snapshot, is appended so that resources for which the template renders the same
+// value, such as the volumes of a PVC that was deleted and recreated, can be told apart. The rendered value is
+// truncated so that the tag fits in the EC2 limit. Returns an empty string if the template renders nothing.
+func templatedNameTag(tmpl string, props any, resourceName string) (string, error) {
+	name, err := template.Execute(tmpl, props)
+	if err != nil {
+		return "", err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil
+	}
+
+	sum := sha256.Sum256([]byte(resourceName))
+	suffix := "-" + hex.EncodeToString(sum[:])[:nameTagHashLength]
+	if runes := []rune(name); len(runes) > maxTagValueLength-len(suffix) {
+		name = string(runes[:maxTagValueLength-len(suffix)])
+	}
+	return name + suffix, nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplatedNameTag(t *testing.T) {
+	props := &template.PVProps{PVCNamespace: "default", PVCName: "data-0", PVName: "pvc-1"}
+
+	name, err := templatedNameTag(DefaultNameTagTemplate, props, "pvc-1")
+	require.NoError(t, err)
+	assert.Regexp(t, `^default/data-0-[0-9a-f]{8}$`, name)
+
+	other, err := templatedNameTag(DefaultNameTagTemplate, props, "pvc-2")
+	require.NoError(t, err)
+	assert.NotEqual(t, name, other, "recreated PVC must get a different Name tag")
+
+	long := &template.PVProps{PVCNamespace: "default", PVCName: strings.Repeat("é", 300)}
+	name, err = templatedNameTag(DefaultNameTagTemplate, long, "pvc-1")
+	require.NoError(t, err)
+	assert.Equal(t, maxTagValueLength, utf8.RuneCountInString(name))
+	assert.True(t, utf8.ValidString(name))
+
+	name, err = templatedNameTag("{{ .PVName | toUpper }}", props, "pvc-1")
+	require.NoError(t, err)
+	assert.Regexp(t, `^PVC-1-[0-9a-f]{8}$`, name)
+
+	name, err = templatedNameTag(" ", props, "pvc-1")
+	require.NoError(t, err)
+	assert.Empty(t, name)
+
+	_, err = templatedNameTag("{{ .PVCName | html }}", props, "pvc-1")
+	assert.Error(t, err)
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud/limits"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud/metadata"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver/internal"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/mounter"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/plugin"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
@@ -46,6 +47,7 @@ import (
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 )
 
@@ -53,6 +55,16 @@ const (
 	// default file system type to be used when it is not provided.
 	defaultFsType = FSTypeExt4
 
+	// xfsProjectQuotaMountOption enables project quota accounting and enforcement on xfs. XFS has no format time
+	// quota setting: the quota inodes are created the first time the filesystem is mounted with this option.
+	xfsProjectQuotaMountOption = "prjquota"
+
+	// fscryptKeySize is the size of the keys of volumes encrypted with fscrypt, which use AES-256-XTS.
+	fscryptKeySize = 64
+
+	// selinuxContextMountOptionPrefix is the prefix of the mount option that sets the SELinux label of all files in a volume.
+	selinuxContextMountOptionPrefix = "context="
+
 	// VolumeOperationAlreadyExists is message fmt returned to CO when there is another in-flight call on the given volumeID.
 	VolumeOperationAlreadyExists = "An operation with the given volume=%q is already in progress"
 )
@@ -86,6 +98,15 @@ type NodeService struct {
 	mounter  mounter.Mounter
 	inFlight *internal.InFlight
 	options  *Options
+	// recorder emits events on the node named nodeName. Nil without a Kubernetes client or CSI_NODE_NAME.
+	recorder record.EventRecorder
+	nodeName string
+	// volumes keeps the staged volumes served on nodeapi.VolumesPath. Nil in tests that do not need it.
+	volumes *nodeVolumes
+	// warmer warms up the lazily loaded volumes once staged. Nil without --volume-warm-up-rate.
+	warmer *volumeWarmer
+	// operationLimiter limits the stage and publish calls running at once. Nil without --max-concurrent-node-operations.
+	operationLimiter *nodeOperationLimiter
 	csi.UnimplementedNodeServer
 }
 
@@ -97,12 +118,23 @@ func NewNodeService(o *Options, md metadata.MetadataService, m mounter.Mounter,
 		go startNotReadyTaintWatcher(k, taintWatcherDuration)
 	}
 
-	return &NodeService{
-		metadata: md,
-		mounter:  m,
-		inFlight: internal.NewInFlight(),
-		options:  o,
+	d := &NodeService{
+		metadata:         md,
+		mounter:          m,
+		inFlight:         internal.NewInFlight(),
+		options:          o,
+		nodeName:         os.Getenv("CSI_NODE_NAME"),
+		volumes:          newNodeVolumes(m),
+		warmer:           newVolumeWarmer(o),
+		operationLimiter: newNodeOperationLimiter(o),
+	}
+	if k != nil && d.nodeName != "" {
+		d.recorder = newEventRecorder(k)
+		if o.AllocatableReconcileInterval > 0 {
+			d.startAllocatableReconciler(k)
+		}
 	}
+	return d
 }
 
 func (d *NodeService) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolumeRequest) (*csi.NodeStageVolumeResponse, error) {
@@ -128,8 +160,8 @@ func (d *NodeService) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 			return nil, status.Error(codes.InvalidArgument, "Volume capability not supported")
 		}
 	} else {
-		if !isValidVolumeCapabilities([]*csi.VolumeCapability{volCap}) {
-			return nil, status.Error(codes.InvalidArgument, "Volume capability not supported")
+		if err := validateCapability(volCap); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Volume capability not supported: %v", err)
 		}
 	}
 	volumeContext := req.GetVolumeContext()
@@ -187,8 +219,40 @@ func (d *NodeService) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 	if err != nil {
 		return nil, err
 	}
+	xfsProjectQuota, err := recheckFormattingOptionParameter(context, XfsProjectQuotaKey, FileSystemConfigs, fsType)
+	if err != nil {
+		return nil, err
+	}
+	ext4Fscrypt, err := recheckFormattingOptionParameter(context, Ext4FscryptKey, FileSystemConfigs, fsType)
+	if err != nil {
+		return nil, err
+	}
+	var fscryptKey []byte
+	if ext4Fscrypt == trueStr {
+		key, ok := req.GetSecrets()[FscryptSecretKey]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "NodeStageVolume: %s requires the %s key in the node stage secret", Ext4FscryptKey, FscryptSecretKey)
+		}
+		if len(key) != fscryptKeySize {
+			return nil, status.Errorf(codes.InvalidArgument, "NodeStageVolume: %s in the node stage secret must be %d bytes, got %d", FscryptSecretKey, fscryptKeySize, len(key))
+		}
+		fscryptKey = []byte(key)
+	}
 
 	mountOptions := collectMountOptions(fsType, mountVolume.GetMountFlags())
+	if xfsProjectQuota == trueStr && !hasMountOption(mountOptions, xfsProjectQuotaMountOption) {
+		mountOptions = append(mountOptions, xfsProjectQuotaMountOption)
+	}
+
+	// With SELinuxMount, kubelet passes the SELinux label of the pod as a context mount option and tells the container
+	// runtime not to relabel the volume recursively. The option must then really be applied, or the pod cannot access the volume.
+	selinuxContext := selinuxMountContext(mountOptions)
+	if selinuxContext != "" {
+		if !d.mounter.SELinuxEnabled() {
+			return nil, status.Errorf(codes.FailedPrecondition, "NodeStageVolume: mount option %s requires SELinux to be enabled in the node plugin container, mount /sys/fs/selinux into it", selinuxContext)
+		}
+		klog.V(4).InfoS("NodeStageVolume: mounting with SELinux context, the container runtime will not relabel the volume", "volumeID", volumeID, "context", selinuxContext)
+	}
 
 	if ok = d.inFlight.Insert(volumeID); !ok {
 		return nil, status.Errorf(codes.Aborted, VolumeOperationAlreadyExists, volumeID)
@@ -225,6 +289,12 @@ func (d *NodeService) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 	}
 
 	klog.V(4).InfoS("NodeStageVolume: find device path", "devicePath", devicePath, "source", source)
+	// A device name is reused once its volume is detached, do not stage another volume on it while it is in use
+	if ok = d.inFlight.Insert(source); !ok {
+		return nil, status.Errorf(codes.Aborted, "An operation on device %s is already in progress", source)
+	}
+	defer d.inFlight.Delete(source)
+
 	exists, err := d.mounter.PathExists(target)
 	if err != nil {
 		msg := fmt.Sprintf("failed to check if target %q exists: %v", target, err)
@@ -254,10 +324,23 @@ func (d *NodeService) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 	// and is identical to the specified volume_capability the Plugin MUST reply 0 OK.
 	klog.V(4).InfoS("NodeStageVolume: checking if volume is already staged", "device", device, "source", source, "target", target)
 	if device == source {
+		if err := d.setupFscrypt(volumeID, target, fscryptKey); err != nil {
+			return nil, err
+		}
 		klog.V(4).InfoS("NodeStageVolume: volume already staged", "volumeID", volumeID)
+		d.volumes.stage(volumeID, source, target, fsType)
+		if volumeContext[LazilyLoadedKey] == trueStr {
+			d.warmer.start(volumeID, source)
+		}
 		return &csi.NodeStageVolumeResponse{}, nil
 	}
 
+	release, err := d.operationLimiter.acquire(ctx, "NodeStageVolume", volumeID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	// FormatAndMount will format only if needed
 	klog.V(4).InfoS("NodeStageVolume: staging volume", "source", source, "volumeID", volumeID, "target", target, "fstype", fsType)
 	formatOptions := []string{}
@@ -286,12 +369,13 @@ func (d *NodeService) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 	if len(ext4ClusterSize) > 0 {
 		formatOptions = append(formatOptions, "-C", ext4ClusterSize)
 	}
-	if ext4EncryptionSupport == "true" {
+	if ext4EncryptionSupport == trueStr || ext4Fscrypt == trueStr {
 		formatOptions = append(formatOptions, "-O", "encrypt")
 	}
 	if fsType == FSTypeXfs && d.options.LegacyXFSProgs {
 		formatOptions = append(formatOptions, "-m", "bigtime=0,inobtcount=0,reflink=0", "-i", "nrext64=0")
 	}
+	mountOptions = d.applyHardenedMountDefaults("NodeStageVolume", volumeID, target, mountOptions)
 	err = d.mounter.FormatAndMountSensitiveWithFormatOptions(source, target, fsType, mountOptions, nil, formatOptions)
 	if err != nil {
 		msg := fmt.Sprintf("could not format %q and mount it at %q: %v", source, target, err)
@@ -309,7 +393,17 @@ func (d *NodeService) NodeStageVolume(ctx context.Context, req *csi.NodeStageVol
 			return nil, status.Errorf(codes.Internal, "Could not resize volume %q (%q):  %v", volumeID, source, err)
 		}
 	}
+	if err := d.setupFscrypt(volumeID, target, fscryptKey); err != nil {
+		return nil, err
+	}
+	if selinuxContext != "" {
+		metrics.Recorder().IncreaseCount(metrics.SELinuxContextMounts, metrics.SELinuxContextMountsHelpText, map[string]string{"fs_type": fsType})
+	}
 	klog.V(4).InfoS("NodeStageVolume: successfully staged volume", "source", source, "volumeID", volumeID, "target", target, "fstype", fsType)
+	d.volumes.stage(volumeID, source, target, fsType)
+	if volumeContext[LazilyLoadedKey] == trueStr {
+		d.warmer.start(volumeID, source)
+	}
 	return &csi.NodeStageVolumeResponse{}, nil
 }
 
@@ -332,6 +426,7 @@ func (d *NodeService) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstag
 		klog.V(4).InfoS("NodeUnStageVolume: volume operation finished", "volumeID", volumeID)
 		d.inFlight.Delete(volumeID)
 	}()
+	d.warmer.stop(volumeID)
 
 	// Check if target directory is a mount point. GetDeviceNameFromMount
 	// given a mnt point, finds the device from /proc/mounts
@@ -347,6 +442,7 @@ func (d *NodeService) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstag
 	// reply 0 OK.
 	if refCount == 0 {
 		klog.V(5).InfoS("[Debug] NodeUnstageVolume: target not mounted", "target", target)
+		d.volumes.unstage(volumeID)
 		return &csi.NodeUnstageVolumeResponse{}, nil
 	}
 
@@ -354,12 +450,21 @@ func (d *NodeService) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstag
 		klog.InfoS("NodeUnstageVolume: found references to device mounted at target path", "refCount", refCount, "device", dev, "target", target)
 	}
 
+	// Unmounting drops the fscrypt keys of the filesystem too, but only once no other mount of it is left.
+	if err := d.mounter.RemoveFscryptKey(filepath.Join(target, FscryptDataDir)); err != nil {
+		klog.InfoS("NodeUnstageVolume: could not remove fscrypt key, continuing", "volumeID", volumeID, "err", err)
+	}
+
 	klog.V(4).InfoS("NodeUnstageVolume: unmounting", "target", target)
 	err = d.mounter.Unstage(target)
+	if err != nil && isBusyErr(err) {
+		err = d.retryBusyUnstage(ctx, volumeID, target, err)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "Could not unmount target %q: %v", target, err)
 	}
 	klog.V(4).InfoS("NodeUnStageVolume: successfully unstaged volume", "volumeID", volumeID, "target", target)
+	d.volumes.unstage(volumeID)
 	return &csi.NodeUnstageVolumeResponse{}, nil
 }
 
@@ -386,8 +491,8 @@ func (d *NodeService) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandV
 	// VolumeCapability is optional, if specified, use that as source of truth
 	if volumeCapability != nil {
 		caps := []*csi.VolumeCapability{volumeCapability}
-		if !isValidVolumeCapabilities(caps) {
-			return nil, status.Error(codes.InvalidArgument, ("VolumeCapability is invalid"))
+		if err := validateVolumeCapabilities(caps); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "VolumeCapability is invalid: %v", err)
 		}
 
 		if blk := volumeCapability.GetBlock(); blk != nil {
@@ -460,8 +565,8 @@ func (d *NodeService) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 			return nil, status.Error(codes.InvalidArgument, "Volume capability not supported")
 		}
 	} else {
-		if !isValidVolumeCapabilities([]*csi.VolumeCapability{volCap}) {
-			return nil, status.Error(codes.InvalidArgument, "Volume capability not supported")
+		if err := validateCapability(volCap); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Volume capability not supported: %v", err)
 		}
 	}
 
@@ -473,6 +578,12 @@ func (d *NodeService) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 		d.inFlight.Delete(volumeID)
 	}()
 
+	release, err := d.operationLimiter.acquire(ctx, "NodePublishVolume", volumeID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	mountOptions := []string{"bind"}
 	if req.GetReadonly() {
 		mountOptions = append(mountOptions, "ro")
@@ -572,6 +683,12 @@ func (d *NodeService) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVo
 			Used:      stats.UsedBytes,
 		},
 	}
+	// A project quota is the effective capacity of the path, so report it instead of the filesystem size
+	if stats.ProjectQuotaLimitBytes > 0 {
+		usage[0].Total = stats.ProjectQuotaLimitBytes
+		usage[0].Used = stats.ProjectQuotaUsedBytes
+		usage[0].Available = max(stats.ProjectQuotaLimitBytes-stats.ProjectQuotaUsedBytes, 0)
+	}
 	if stats.TotalInodes != 0 {
 		usage = append(usage, &csi.VolumeUsage{
 			Unit:      csi.VolumeUsage_INODES,
@@ -675,6 +792,10 @@ func (d *NodeService) nodePublishVolumeForBlock(req *csi.NodePublishVolumeReques
 	if err != nil {
 		return status.Errorf(codes.NotFound, "Failed to find device path %s. %v", devicePath, err)
 	}
+	source, err = d.mounter.BlockDevicePath(source)
+	if err != nil {
+		return status.Errorf(codes.Internal, "Failed to get block device path of %s: %v", devicePath, err)
+	}
 
 	klog.V(4).InfoS("NodePublishVolume [block]: find device path", "devicePath", devicePath, "source", source)
 
@@ -771,6 +892,9 @@ func (d *NodeService) isMounted(_ string, target string) (bool, error) {
 func (d *NodeService) nodePublishVolumeForFileSystem(req *csi.NodePublishVolumeRequest, mountOptions []string, mode *csi.VolumeCapability_Mount) error {
 	target := req.GetTargetPath()
 	source := req.GetStagingTargetPath()
+	if req.GetVolumeContext()[Ext4FscryptKey] == trueStr {
+		source = filepath.Join(source, FscryptDataDir)
+	}
 	if m := mode.Mount; m != nil {
 		for _, f := range m.GetMountFlags() {
 			if !hasMountOption(mountOptions, f) {
@@ -801,6 +925,7 @@ func (d *NodeService) nodePublishVolumeForFileSystem(req *csi.NodePublishVolumeR
 		}
 
 		mountOptions = collectMountOptions(fsType, mountOptions)
+		mountOptions = d.applyHardenedMountDefaults("NodePublishVolume", req.GetVolumeId(), target, mountOptions)
 		klog.V(4).InfoS("NodePublishVolume: mounting", "source", source, "target", target, "mountOptions", mountOptions, "fsType", fsType)
 		if err := d.mounter.Mount(source, target, fsType, mountOptions); err != nil {
 			return status.Errorf(codes.Internal, "Could not mount %q at %q: %v", source, target, err)
@@ -823,6 +948,9 @@ func (d *NodeService) getVolumesLimit() int64 {
 
 	// Calculate reserved volume attachments (additional EBS volumes)
 	reservedVolumeAttachments := d.options.ReservedVolumeAttachments
+	if reserved, ok := d.options.ReservedAttachments[AttachmentClassEBS]; ok {
+		reservedVolumeAttachments = reserved
+	}
 	if reservedVolumeAttachments == -1 {
 		// Auto-detect number of reserved volume attachments - plus 1 to account for the root volume
 		reservedVolumeAttachments = d.metadata.GetNumBlockDeviceMappings() + 1
@@ -830,11 +958,21 @@ func (d *NodeService) getVolumesLimit() int64 {
 	klog.V(4).InfoS("getVolumesLimit: Removing reserved attachments", "reservedVolumeAttachments", reservedVolumeAttachments)
 	availableAttachments -= reservedVolumeAttachments
 
-	// For shared attachment types, subtract ENIs
+	// For shared attachment types, subtract secondary ENIs and the other reserved devices
 	if limitType == util.AttachmentShared {
-		enis := d.metadata.GetNumAttachedENIs()
-		klog.V(4).InfoS("getVolumesLimit: Removing ENIs on shared limit", "enis", enis)
-		availableAttachments -= (enis - 1)
+		secondaryENIs, ok := d.options.ReservedAttachments[AttachmentClassENI]
+		if !ok {
+			secondaryENIs = d.metadata.GetNumAttachedENIs() - 1
+		}
+		klog.V(4).InfoS("getVolumesLimit: Removing ENIs on shared limit", "secondaryENIs", secondaryENIs)
+		availableAttachments -= secondaryENIs
+
+		for _, class := range []AttachmentClass{AttachmentClassNVMeLocal, AttachmentClassAccelerator} {
+			if reserved := d.options.ReservedAttachments[class]; reserved > 0 {
+				klog.V(4).InfoS("getVolumesLimit: Removing reserved attachments on shared limit", "class", class, "reserved", reserved)
+				availableAttachments -= reserved
+			}
+		}
 	}
 
 	// Safety measure: Never return a limit of below 1, as Kubernetes will treat it as infinite
@@ -874,6 +1012,65 @@ func collectMountOptions(fsType string, mntFlags []string) []string {
 	return options
 }
 
+// setupFscrypt unlocks the encrypted data directory of a volume staged at target, creating it on first use.
+func (d *NodeService) setupFscrypt(volumeID, target string, key []byte) error {
+	if key == nil {
+		return nil
+	}
+	if err := d.mounter.SetupFscrypt(filepath.Join(target, FscryptDataDir), key); err != nil {
+		return status.Errorf(codes.Internal, "Could not set up fscrypt for volume %q: %v", volumeID, err)
+	}
+	klog.V(4).InfoS("NodeStageVolume: fscrypt key loaded", "volumeID", volumeID)
+	return nil
+}
+
+// hardenedMountOption is a mount option applied by --hardened-mount-defaults, and the mount option that a
+// StorageClass can set in its mountOptions to opt out of it.
+type hardenedMountOption struct {
+	option   string
+	override string
+}
+
+var (
+	hardenedMountOptions = []hardenedMountOption{{"nodev", "dev"}, {"nosuid", "suid"}}
+	hardenedNoExecOption = hardenedMountOption{"noexec", "exec"}
+)
+
+// applyHardenedMountDefaults adds the hardened mount options enabled on the node to the options of a filesystem
+// mount, except those that the StorageClass overrides, and logs the result for auditing.
+func (d *NodeService) applyHardenedMountDefaults(rpc, volumeID, target string, options []string) []string {
+	if !d.options.HardenedMountDefaults {
+		return options
+	}
+	defaults := hardenedMountOptions
+	if d.options.HardenedMountNoExec {
+		defaults = append(slices.Clone(defaults), hardenedNoExecOption)
+	}
+
+	var applied, overridden []string
+	for _, o := range defaults {
+		switch {
+		case hasMountOption(options, o.override):
+			overridden = append(overridden, o.override)
+		case !hasMountOption(options, o.option):
+			options = append(options, o.option)
+			applied = append(applied, o.option)
+		}
+	}
+	klog.InfoS(rpc+": applying hardened mount defaults", "volumeID", volumeID, "target", target, "applied", applied, "overridden", overridden, "mountOptions", options)
+	return options
+}
+
+// selinuxMountContext returns the SELinux context mount option in options, or "" if there is none.
+func selinuxMountContext(options []string) string {
+	for _, opt := range options {
+		if strings.HasPrefix(opt, selinuxContextMountOptionPrefix) {
+			return opt
+		}
+	}
+	return ""
+}
+
 // startNotReadyTaintWatcher launches a short‑lived Node informer that removes the
 // ebs.csi.aws.com/agent‑not‑ready taint. The informer is stopped after maxWatchDuration.
 func startNotReadyTaintWatcher(clientset kubernetes.Interface, maxWatchDuration time.Duration) {
@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultRegistrationDir is the directory where the kubelet watches the registration sockets of plugins.
+	DefaultRegistrationDir = "/var/lib/kubelet/plugins_registry"
+	// allocatableCorrectedReason is the reason of the events emitted on the node when its CSINode allocatable count
+	// is corrected.
+	allocatableCorrectedReason = "AllocatableCorrected"
+)
+
+// startAllocatableReconciler compares the allocatable count of the driver in the CSINode of the node with the
+// volume limit of the node every --allocatable-reconcile-interval in the background.
+func (d *NodeService) startAllocatableReconciler(k kubernetes.Interface) {
+	go func() {
+		ticker := time.NewTicker(d.options.AllocatableReconcileInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := d.reconcileAllocatable(context.Background(), k); err != nil {
+				klog.ErrorS(err, "Could not reconcile the CSINode allocatable count", "node", d.nodeName)
+			}
+		}
+	}()
+}
+
+// reconcileAllocatable triggers the re-registration of the driver when the allocatable count the kubelet stored in
+// the CSINode of the node at registration diverges from the volume limit of the node, for example after ENIs were
+// attached or detached. The kubelet only calls NodeGetInfo at registration: removing the registration socket makes
+// it unregister the driver and fails the health check of node-driver-registrar, whose restart registers the driver
+// again with the current limit.
+func (d *NodeService) reconcileAllocatable(ctx context.Context, k kubernetes.Interface) error {
+	csiNode, err := k.StorageV1().CSINodes().Get(ctx, d.nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get CSINode %s: %w", d.nodeName, err)
+	}
+	var advertised *int32
+	for _, driver := range csiNode.Spec.Drivers {
+		if driver.Name == util.GetDriverName() && driver.Allocatable != nil {
+			advertised = driver.Allocatable.Count
+		}
+	}
+	if advertised == nil {
+		// Not registered yet, or being registered again
+		return nil
+	}
+
+	if err := d.metadata.UpdateMetadata(); err != nil {
+		klog.ErrorS(err, "Failed to update metadata, using cached values")
+	}
+	limit := d.getVolumesLimit()
+	if int64(*advertised) == limit {
+		return nil
+	}
+
+	socket := filepath.Join(d.options.RegistrationDir, util.GetDriverName()+"-reg.sock")
+	klog.InfoS("CSINode allocatable count diverges from the volume limit of the node, registering the driver again", "node", d.nodeName, "allocatable", *advertised, "limit", limit, "socket", socket)
+	if err := os.Remove(socket); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("could not remove registration socket %s: %w", socket, err)
+	}
+	metrics.Recorder().IncreaseCount(metrics.AllocatableCorrections, metrics.AllocatableCorrectionsHelpText, map[string]string{})
+	if d.recorder != nil {
+		node := &corev1.ObjectReference{Kind: "Node", Name: d.nodeName, UID: k8stypes.UID(d.nodeName)}
+		d.recorder.Eventf(node, corev1.EventTypeNormal, allocatableCorrectedReason, "CSINode allocatable count of driver %s is %d but the node can attach %d volumes, registering the driver again", util.GetDriverName(), *advertised, limit)
+	}
+	return nil
+}
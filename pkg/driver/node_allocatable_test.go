@@ -0,0 +1,99 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud/metadata"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestReconcileAllocatable(t *testing.T) {
+	testCases := []struct {
+		name          string
+		allocatable   *int32
+		registered    bool
+		expRemoved    bool
+		expectedEvent string
+	}{
+		{
+			name:        "allocatable matches the limit",
+			allocatable: aws.Int32(10),
+			registered:  true,
+		},
+		{
+			name:          "allocatable diverges from the limit",
+			allocatable:   aws.Int32(12),
+			registered:    true,
+			expRemoved:    true,
+			expectedEvent: "Normal AllocatableCorrected CSINode allocatable count of driver test.ebs.csi.aws.com is 12 but the node can attach 10 volumes, registering the driver again",
+		},
+		{
+			name: "driver not registered",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			mockMetadata := metadata.NewMockMetadataService(mockCtl)
+			if tc.registered {
+				mockMetadata.EXPECT().UpdateMetadata().Return(nil)
+			}
+
+			dir := t.TempDir()
+			socket := filepath.Join(dir, util.GetDriverName()+"-reg.sock")
+			require.NoError(t, os.WriteFile(socket, nil, 0o600))
+
+			csiNode := &storagev1.CSINode{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+			if tc.registered {
+				csiNode.Spec.Drivers = []storagev1.CSINodeDriver{{
+					Name:        util.GetDriverName(),
+					Allocatable: &storagev1.VolumeNodeResources{Count: tc.allocatable},
+				}}
+			}
+			recorder := record.NewFakeRecorder(10)
+			d := &NodeService{
+				metadata: mockMetadata,
+				options:  &Options{VolumeAttachLimit: 10, RegistrationDir: dir},
+				nodeName: "test-node",
+				recorder: recorder,
+			}
+
+			require.NoError(t, d.reconcileAllocatable(t.Context(), fake.NewClientset(csiNode)))
+			_, err := os.Stat(socket)
+			assert.Equal(t, tc.expRemoved, os.IsNotExist(err))
+			if tc.expectedEvent == "" {
+				assert.Empty(t, recorder.Events)
+			} else {
+				require.Len(t, recorder.Events, 1)
+				assert.Equal(t, tc.expectedEvent, <-recorder.Events)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// volumeBusyReason is the reason of the events emitted on the node when a staging path stays busy.
+	volumeBusyReason = "VolumeUnstageBusy"
+	// maxReportedMountHolders caps the number of processes listed in errors and events.
+	maxReportedMountHolders = 10
+)
+
+// busyUnstageBackoff is how NodeUnstageVolume retries unmounting a busy staging path, about 15 seconds in total, to
+// ride out processes that are about to exit before the kubelet retries the whole call.
+var busyUnstageBackoff = wait.Backoff{Duration: time.Second, Factor: 2, Steps: 4}
+
+func isBusyErr(err error) bool {
+	return errors.Is(err, syscall.EBUSY) || strings.Contains(strings.ToLower(err.Error()), "busy")
+}
+
+// retryBusyUnstage retries unmounting target while it is busy. If it stays busy, the processes holding it are
+// looked up and reported in the returned error and in an event on the node.
+func (d *NodeService) retryBusyUnstage(ctx context.Context, volumeID, target string, err error) error {
+	backoff := busyUnstageBackoff
+	for backoff.Steps > 0 && err != nil && isBusyErr(err) {
+		klog.InfoS("NodeUnstageVolume: target is busy, retrying", "volumeID", volumeID, "target", target, "err", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff.Step()):
+		}
+		err = d.mounter.Unstage(target)
+	}
+	if err == nil || !isBusyErr(err) {
+		return err
+	}
+
+	holders, holdersErr := d.mounter.MountHolders(target)
+	var diagnostics string
+	switch {
+	case holdersErr != nil:
+		diagnostics = fmt.Sprintf("could not look up the processes holding it: %v", holdersErr)
+	case len(holders) == 0:
+		diagnostics = "no process holds it open, it may still have mounts under it or be held by the kernel, for example by a loop device or an NFS export"
+	default:
+		names := make([]string, 0, min(len(holders), maxReportedMountHolders))
+		for _, holder := range holders[:min(len(holders), maxReportedMountHolders)] {
+			names = append(names, holder.String())
+		}
+		diagnostics = "held open by " + strings.Join(names, ", ")
+		if len(holders) > maxReportedMountHolders {
+			diagnostics += fmt.Sprintf(" and %d more processes", len(holders)-maxReportedMountHolders)
+		}
+	}
+	klog.InfoS("NodeUnstageVolume: target is still busy", "volumeID", volumeID, "target", target, "diagnostics", diagnostics)
+	if d.recorder != nil {
+		node := &corev1.ObjectReference{Kind: "Node", Name: d.nodeName, UID: k8stypes.UID(d.nodeName)}
+		d.recorder.Eventf(node, corev1.EventTypeWarning, volumeBusyReason, "Could not unstage volume %s, %s is busy: %s", volumeID, target, diagnostics)
+	}
+	return fmt.Errorf("%w: %s", err, diagnostics)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver/internal"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/mounter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestNodeUnstageVolumeBusy(t *testing.T) {
+	defaultBackoff := busyUnstageBackoff
+	busyUnstageBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 2}
+	t.Cleanup(func() { busyUnstageBackoff = defaultBackoff })
+
+	const target = "/staging/path"
+	busyErr := errors.New("unmount failed: exit status 32\nOutput: umount: /staging/path: target is busy.")
+	req := &csi.NodeUnstageVolumeRequest{VolumeId: "vol-test", StagingTargetPath: target}
+
+	testCases := []struct {
+		name          string
+		mounterMock   func(m *mounter.MockMounter)
+		expectedErr   string
+		expectedEvent string
+	}{
+		{
+			name: "busy until retried",
+			mounterMock: func(m *mounter.MockMounter) {
+				gomock.InOrder(
+					m.EXPECT().Unstage(target).Return(syscall.EBUSY),
+					m.EXPECT().Unstage(target).Return(nil),
+				)
+			},
+		},
+		{
+			name: "stays busy",
+			mounterMock: func(m *mounter.MockMounter) {
+				m.EXPECT().Unstage(target).Return(busyErr).Times(3)
+				m.EXPECT().MountHolders(target).Return([]mounter.MountHolder{
+					{PID: 42, Command: "bash", Uses: []string{"cwd"}},
+					{PID: 43, Command: "python", PodUID: "3f2a8c1e-0b4d-4e5f-9a6b-7c8d9e0f1a2b", Uses: []string{"fd"}},
+				}, nil)
+			},
+			expectedErr:   `Could not unmount target "/staging/path": ` + busyErr.Error() + ": held open by pid 42 (bash: cwd), pid 43 (python in pod 3f2a8c1e-0b4d-4e5f-9a6b-7c8d9e0f1a2b: fd)",
+			expectedEvent: "Warning VolumeUnstageBusy Could not unstage volume vol-test, /staging/path is busy: held open by pid 42 (bash: cwd), pid 43 (python in pod 3f2a8c1e-0b4d-4e5f-9a6b-7c8d9e0f1a2b: fd)",
+		},
+		{
+			name: "stays busy without holders",
+			mounterMock: func(m *mounter.MockMounter) {
+				m.EXPECT().Unstage(target).Return(syscall.EBUSY).Times(3)
+				m.EXPECT().MountHolders(target).Return(nil, nil)
+			},
+			expectedErr:   `Could not unmount target "/staging/path": device or resource busy: no process holds it open, it may still have mounts under it or be held by the kernel, for example by a loop device or an NFS export`,
+			expectedEvent: "Warning VolumeUnstageBusy Could not unstage volume vol-test, /staging/path is busy: no process holds it open, it may still have mounts under it or be held by the kernel, for example by a loop device or an NFS export",
+		},
+		{
+			name: "other error after busy",
+			mounterMock: func(m *mounter.MockMounter) {
+				gomock.InOrder(
+					m.EXPECT().Unstage(target).Return(syscall.EBUSY),
+					m.EXPECT().Unstage(target).Return(errors.New("unmount failed")),
+				)
+			},
+			expectedErr: `Could not unmount target "/staging/path": unmount failed`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			m := mounter.NewMockMounter(mockCtl)
+			m.EXPECT().GetDeviceNameFromMount(target).Return("/dev/nvme1n1", 1, nil)
+			m.EXPECT().RemoveFscryptKey(filepath.Join(target, FscryptDataDir)).Return(nil)
+			tc.mounterMock(m)
+			recorder := record.NewFakeRecorder(10)
+			d := &NodeService{mounter: m, inFlight: internal.NewInFlight(), options: &Options{}, recorder: recorder, nodeName: "node-1"}
+
+			_, err := d.NodeUnstageVolume(t.Context(), req)
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				assert.Equal(t, codes.Internal, status.Code(err))
+				assert.Equal(t, tc.expectedErr, status.Convert(err).Message())
+			}
+			if tc.expectedEvent == "" {
+				assert.Empty(t, recorder.Events)
+			} else {
+				require.Len(t, recorder.Events, 1)
+				assert.Equal(t, tc.expectedEvent, <-recorder.Events)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// nodeOperationWaitBuckets covers operations granted at once up to the default kubelet timeout of 2 minutes.
+var nodeOperationWaitBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// nodeOperationLimiter limits the number of NodeStageVolume and NodePublishVolume calls that format and mount volumes
+// at once, so that many pods starting together do not run as many mkfs and mount processes and starve the node.
+// Calls over the limit wait in arrival order until a call completes or their context is done. Calls on the same
+// volume are already serialized by the inFlight set of the NodeService.
+type nodeOperationLimiter struct {
+	slots chan struct{}
+
+	mu       sync.Mutex
+	inFlight int
+	queued   int
+}
+
+func newNodeOperationLimiter(o *Options) *nodeOperationLimiter {
+	if o.MaxConcurrentNodeOperations <= 0 {
+		return nil
+	}
+	return &nodeOperationLimiter{slots: make(chan struct{}, o.MaxConcurrentNodeOperations)}
+}
+
+// acquire waits until operation can run on volumeID. On success, it returns a function that must be called once the
+// operation completes. It fails if ctx is done before the operation is granted.
+func (l *nodeOperationLimiter) acquire(ctx context.Context, operation, volumeID string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	start := time.Now()
+	select {
+	case l.slots <- struct{}{}:
+	default:
+		l.update(0, 1)
+		klog.V(4).InfoS(operation+": waiting for concurrent node operations", "volumeID", volumeID, "limit", cap(l.slots))
+		done := util.SetOperationStep(ctx, "waiting for concurrent node operations")
+		select {
+		case l.slots <- struct{}{}:
+			done()
+			l.update(0, -1)
+		case <-ctx.Done():
+			done()
+			l.update(0, -1)
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	l.update(1, 0)
+	metrics.Recorder().ObserveHistogram(metrics.NodeOperationWaitDuration, metrics.NodeOperationWaitDurationHelpText, time.Since(start).Seconds(), map[string]string{"operation": operation}, nodeOperationWaitBuckets)
+	return func() {
+		<-l.slots
+		l.update(-1, 0)
+	}, nil
+}
+
+// update changes the number of operations in flight and queued, and reports them.
+func (l *nodeOperationLimiter) update(inFlight, queued int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight += inFlight
+	l.queued += queued
+	metrics.Recorder().SetGauge(metrics.NodeOperationsInFlight, metrics.NodeOperationsInFlightHelpText, float64(l.inFlight), map[string]string{})
+	metrics.Recorder().SetGauge(metrics.NodeOperationsQueued, metrics.NodeOperationsQueuedHelpText, float64(l.queued), map[string]string{})
+}
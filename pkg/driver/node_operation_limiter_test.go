@@ -0,0 +1,63 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNodeOperationLimiter(t *testing.T) {
+	var unlimited *nodeOperationLimiter
+	release, err := unlimited.acquire(t.Context(), "NodeStageVolume", "vol-1")
+	require.NoError(t, err)
+	release()
+
+	l := newNodeOperationLimiter(&Options{MaxConcurrentNodeOperations: 2})
+	release1, err := l.acquire(t.Context(), "NodeStageVolume", "vol-1")
+	require.NoError(t, err)
+	release2, err := l.acquire(t.Context(), "NodePublishVolume", "vol-2")
+	require.NoError(t, err)
+
+	// The third operation waits until its context is done
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	_, err = l.acquire(ctx, "NodeStageVolume", "vol-3")
+	assert.Equal(t, codes.Canceled, status.Code(err))
+
+	// or until an operation completes
+	granted := make(chan func())
+	go func() {
+		release3, err := l.acquire(t.Context(), "NodeStageVolume", "vol-3")
+		assert.NoError(t, err)
+		granted <- release3
+	}()
+	release1()
+	release3 := <-granted
+
+	release2()
+	release3()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	assert.Equal(t, 0, l.inFlight)
+	assert.Equal(t, 0, l.queued)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AttachmentClass is a class of device that takes up attachment slots of an instance.
+type AttachmentClass string
+
+const (
+	// AttachmentClassEBS reserves slots for EBS volumes not managed by the driver, including the root volume.
+	AttachmentClassEBS AttachmentClass = "ebs"
+	// AttachmentClassENI reserves slots for secondary network interfaces.
+	AttachmentClassENI AttachmentClass = "eni"
+	// AttachmentClassNVMeLocal reserves slots for NVMe instance store volumes.
+	AttachmentClassNVMeLocal AttachmentClass = "nvme-local"
+	// AttachmentClassAccelerator reserves slots for accelerators such as GPUs.
+	AttachmentClassAccelerator AttachmentClass = "accelerator"
+)
+
+var attachmentClasses = []AttachmentClass{AttachmentClassEBS, AttachmentClassENI, AttachmentClassNVMeLocal, AttachmentClassAccelerator}
+
+// reservedAttachments parses --reserved-attachments, a comma separated list of <class>=<number of slots> pairs.
+type reservedAttachments struct {
+	reserved *map[AttachmentClass]int
+}
+
+func (f *reservedAttachments) String() string {
+	if f.reserved == nil || len(*f.reserved) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(*f.reserved))
+	for class, n := range *f.reserved {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", class, n))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f *reservedAttachments) Type() string { return "mapStringInt" }
+
+func (f *reservedAttachments) Set(value string) error {
+	reserved := map[AttachmentClass]int{}
+	for pair := range strings.SplitSeq(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		class, count, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid reserved attachments %q, must be <class>=<number of slots>", pair)
+		}
+		if !slices.Contains(attachmentClasses, AttachmentClass(class)) {
+			return fmt.Errorf("invalid attachment class %q, must be one of %v", class, attachmentClasses)
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid number of reserved %s attachments %q, must be a non-negative integer", class, count)
+		}
+		reserved[AttachmentClass(class)] = n
+	}
+	*f.reserved = reserved
+	return nil
+}
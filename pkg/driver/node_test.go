@@ -22,6 +22,7 @@ import (
 	"context"
 	"errors"
 	"maps"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
@@ -120,6 +121,101 @@ func TestNodeStageVolume(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "success_selinux_context",
+			req: &csi.NodeStageVolumeRequest{
+				VolumeId:          "vol-test",
+				StagingTargetPath: "/staging/path",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{
+							FsType:     "ext4",
+							MountFlags: []string{`context="system_u:object_r:container_file_t:s0:c0,c1"`},
+						},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+					},
+				},
+				PublishContext: map[string]string{DevicePathKey: "/dev/xvdba"},
+			},
+			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
+				m := mounter.NewMockMounter(ctrl)
+				m.EXPECT().SELinuxEnabled().Return(true)
+				m.EXPECT().FindDevicePath(gomock.Eq("/dev/xvdba"), gomock.Eq("vol-test"), gomock.Eq(""), gomock.Eq("us-west-2")).Return("/dev/xvdba", nil)
+				m.EXPECT().PathExists(gomock.Eq("/staging/path")).Return(true, nil)
+				m.EXPECT().GetDeviceNameFromMount(gomock.Eq("/staging/path")).Return("", 1, nil)
+				m.EXPECT().FormatAndMountSensitiveWithFormatOptions(gomock.Eq("/dev/xvdba"), gomock.Eq("/staging/path"), gomock.Eq("ext4"), gomock.Eq([]string{`context="system_u:object_r:container_file_t:s0:c0,c1"`}), gomock.Nil(), gomock.Eq([]string{})).Return(nil)
+				m.EXPECT().NeedResize(gomock.Eq("/dev/xvdba"), gomock.Eq("/staging/path")).Return(false, nil)
+				return m
+			},
+			metadataMock: func(ctrl *gomock.Controller) *metadata.MockMetadataService {
+				m := metadata.NewMockMetadataService(ctrl)
+				m.EXPECT().GetRegion().Return("us-west-2")
+				return m
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "success_hardened_mount_defaults",
+			req: &csi.NodeStageVolumeRequest{
+				VolumeId:          "vol-test",
+				StagingTargetPath: "/staging/path",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{
+							FsType:     "ext4",
+							MountFlags: []string{"suid"},
+						},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+					},
+				},
+				PublishContext: map[string]string{DevicePathKey: "/dev/xvdba"},
+			},
+			options: &Options{HardenedMountDefaults: true, HardenedMountNoExec: true},
+			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
+				m := mounter.NewMockMounter(ctrl)
+				m.EXPECT().FindDevicePath(gomock.Eq("/dev/xvdba"), gomock.Eq("vol-test"), gomock.Eq(""), gomock.Eq("us-west-2")).Return("/dev/xvdba", nil)
+				m.EXPECT().PathExists(gomock.Eq("/staging/path")).Return(true, nil)
+				m.EXPECT().GetDeviceNameFromMount(gomock.Eq("/staging/path")).Return("", 1, nil)
+				m.EXPECT().FormatAndMountSensitiveWithFormatOptions(gomock.Eq("/dev/xvdba"), gomock.Eq("/staging/path"), gomock.Eq("ext4"), gomock.Eq([]string{"suid", "nodev", "noexec"}), gomock.Nil(), gomock.Eq([]string{})).Return(nil)
+				m.EXPECT().NeedResize(gomock.Eq("/dev/xvdba"), gomock.Eq("/staging/path")).Return(false, nil)
+				return m
+			},
+			metadataMock: func(ctrl *gomock.Controller) *metadata.MockMetadataService {
+				m := metadata.NewMockMetadataService(ctrl)
+				m.EXPECT().GetRegion().Return("us-west-2")
+				return m
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "selinux_context_without_selinux",
+			req: &csi.NodeStageVolumeRequest{
+				VolumeId:          "vol-test",
+				StagingTargetPath: "/staging/path",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{
+							FsType:     "ext4",
+							MountFlags: []string{"context=system_u:object_r:container_file_t:s0"},
+						},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+					},
+				},
+				PublishContext: map[string]string{DevicePathKey: "/dev/xvdba"},
+			},
+			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
+				m := mounter.NewMockMounter(ctrl)
+				m.EXPECT().SELinuxEnabled().Return(false)
+				return m
+			},
+			expectedErr: status.Error(codes.FailedPrecondition, "NodeStageVolume: mount option context=system_u:object_r:container_file_t:s0 requires SELinux to be enabled in the node plugin container, mount /sys/fs/selinux into it"),
+		},
 		{
 			name: "missing_volume_id",
 			req: &csi.NodeStageVolumeRequest{
@@ -209,7 +305,27 @@ func TestNodeStageVolume(t *testing.T) {
 			},
 			mounterMock:  nil,
 			metadataMock: nil,
-			expectedErr:  status.Error(codes.InvalidArgument, "Volume capability not supported"),
+			expectedErr:  status.Error(codes.InvalidArgument, "Volume capability not supported: access mode UNKNOWN is not supported"),
+		},
+		{
+			name: "multi_attach_filesystem_volume",
+			req: &csi.NodeStageVolumeRequest{
+				VolumeId:          "vol-test",
+				StagingTargetPath: "/staging/path",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{
+							FsType: "ext4",
+						},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+					},
+				},
+			},
+			mounterMock:  nil,
+			metadataMock: nil,
+			expectedErr:  status.Errorf(codes.InvalidArgument, "Volume capability not supported: %v", errMultiWriterFilesystem),
 		},
 		{
 			name: "block_volume",
@@ -965,6 +1081,151 @@ func TestNodeStageVolume(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "success_ext4_fscrypt",
+			req: &csi.NodeStageVolumeRequest{
+				VolumeId:          "vol-test",
+				StagingTargetPath: "/staging/path",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{
+							FsType: "ext4",
+						},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+					},
+				},
+				VolumeContext: map[string]string{
+					Ext4FscryptKey: "true",
+				},
+				Secrets: map[string]string{
+					FscryptSecretKey: strings.Repeat("k", fscryptKeySize),
+				},
+				PublishContext: map[string]string{
+					DevicePathKey: "/dev/xvdba",
+				},
+			},
+			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
+				m := mounter.NewMockMounter(ctrl)
+				m.EXPECT().FindDevicePath(gomock.Eq("/dev/xvdba"), gomock.Eq("vol-test"), gomock.Eq(""), gomock.Eq("us-west-2")).Return("/dev/xvdba", nil)
+				m.EXPECT().PathExists(gomock.Eq("/staging/path")).Return(true, nil)
+				m.EXPECT().GetDeviceNameFromMount(gomock.Eq("/staging/path")).Return("", 1, nil)
+				m.EXPECT().FormatAndMountSensitiveWithFormatOptions(gomock.Eq("/dev/xvdba"), gomock.Eq("/staging/path"), gomock.Eq("ext4"), gomock.Eq([]string(nil)), gomock.Eq([]string(nil)), gomock.Eq([]string{"-O", "encrypt"})).Return(nil)
+				m.EXPECT().NeedResize(gomock.Eq("/dev/xvdba"), gomock.Eq("/staging/path")).Return(false, nil)
+				m.EXPECT().SetupFscrypt(gomock.Eq(filepath.Join("/staging/path", FscryptDataDir)), gomock.Eq([]byte(strings.Repeat("k", fscryptKeySize)))).Return(nil)
+				return m
+			},
+			metadataMock: func(ctrl *gomock.Controller) *metadata.MockMetadataService {
+				m := metadata.NewMockMetadataService(ctrl)
+				m.EXPECT().GetRegion().Return("us-west-2")
+				return m
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "fail_ext4_fscrypt_already_staged_setup_error",
+			req: &csi.NodeStageVolumeRequest{
+				VolumeId:          "vol-test",
+				StagingTargetPath: "/staging/path",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{
+							FsType: "ext4",
+						},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+					},
+				},
+				VolumeContext: map[string]string{
+					Ext4FscryptKey: "true",
+				},
+				Secrets: map[string]string{
+					FscryptSecretKey: strings.Repeat("k", fscryptKeySize),
+				},
+				PublishContext: map[string]string{
+					DevicePathKey: "/dev/xvdba",
+				},
+			},
+			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
+				m := mounter.NewMockMounter(ctrl)
+				m.EXPECT().FindDevicePath(gomock.Eq("/dev/xvdba"), gomock.Eq("vol-test"), gomock.Eq(""), gomock.Eq("us-west-2")).Return("/dev/xvdba", nil)
+				m.EXPECT().PathExists(gomock.Eq("/staging/path")).Return(true, nil)
+				m.EXPECT().GetDeviceNameFromMount(gomock.Eq("/staging/path")).Return("/dev/xvdba", 1, nil)
+				m.EXPECT().SetupFscrypt(gomock.Eq(filepath.Join("/staging/path", FscryptDataDir)), gomock.Any()).Return(errors.New("key mismatch"))
+				return m
+			},
+			metadataMock: func(ctrl *gomock.Controller) *metadata.MockMetadataService {
+				m := metadata.NewMockMetadataService(ctrl)
+				m.EXPECT().GetRegion().Return("us-west-2")
+				return m
+			},
+			expectedErr: status.Errorf(codes.Internal, "Could not set up fscrypt for volume %q: %v", "vol-test", errors.New("key mismatch")),
+		},
+		{
+			name: "fail_ext4_fscrypt_missing_secret",
+			req: &csi.NodeStageVolumeRequest{
+				VolumeId:          "vol-test",
+				StagingTargetPath: "/staging/path",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{
+							FsType: "ext4",
+						},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+					},
+				},
+				VolumeContext: map[string]string{
+					Ext4FscryptKey: "true",
+				},
+				PublishContext: map[string]string{
+					DevicePathKey: "/dev/xvdba",
+				},
+			},
+			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
+				return mounter.NewMockMounter(ctrl)
+			},
+			metadataMock: func(ctrl *gomock.Controller) *metadata.MockMetadataService {
+				return metadata.NewMockMetadataService(ctrl)
+			},
+			expectedErr: status.Errorf(codes.InvalidArgument, "NodeStageVolume: %s requires the %s key in the node stage secret", Ext4FscryptKey, FscryptSecretKey),
+		},
+		{
+			name: "fail_ext4_fscrypt_invalid_key_size",
+			req: &csi.NodeStageVolumeRequest{
+				VolumeId:          "vol-test",
+				StagingTargetPath: "/staging/path",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{
+							FsType: "ext4",
+						},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+					},
+				},
+				VolumeContext: map[string]string{
+					Ext4FscryptKey: "true",
+				},
+				Secrets: map[string]string{
+					FscryptSecretKey: "short",
+				},
+				PublishContext: map[string]string{
+					DevicePathKey: "/dev/xvdba",
+				},
+			},
+			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
+				return mounter.NewMockMounter(ctrl)
+			},
+			metadataMock: func(ctrl *gomock.Controller) *metadata.MockMetadataService {
+				return metadata.NewMockMetadataService(ctrl)
+			},
+			expectedErr: status.Errorf(codes.InvalidArgument, "NodeStageVolume: %s in the node stage secret must be %d bytes, got %d", FscryptSecretKey, fscryptKeySize, 5),
+		},
 		{
 			name: "format_options_xfs",
 			req: &csi.NodeStageVolumeRequest{
@@ -1198,6 +1459,35 @@ func TestGetVolumesLimit(t *testing.T) {
 				return m
 			},
 		},
+		{
+			name: "ReservedAttachments_specified_shared_limit",
+			options: &Options{
+				VolumeAttachLimit:         -1,
+				ReservedVolumeAttachments: -1,
+				ReservedAttachments:       map[AttachmentClass]int{AttachmentClassEBS: 2, AttachmentClassENI: 4, AttachmentClassNVMeLocal: 2},
+			},
+			expectedVal: 19,
+			metadataMock: func(ctrl *gomock.Controller) *metadata.MockMetadataService {
+				m := metadata.NewMockMetadataService(ctrl)
+				m.EXPECT().GetInstanceType().Return("m5.large")
+				return m
+			},
+		},
+		{
+			name: "ReservedAttachments_specified_dedicated_limit",
+			options: &Options{
+				VolumeAttachLimit:         -1,
+				ReservedVolumeAttachments: -1,
+				ReservedAttachments:       map[AttachmentClass]int{AttachmentClassENI: 4, AttachmentClassAccelerator: 2},
+			},
+			expectedVal: 127,
+			metadataMock: func(ctrl *gomock.Controller) *metadata.MockMetadataService {
+				m := metadata.NewMockMetadataService(ctrl)
+				m.EXPECT().GetInstanceType().Return("m7i.48xlarge")
+				m.EXPECT().GetNumBlockDeviceMappings().Return(0)
+				return m
+			},
+		},
 		{
 			name: "m5d.large_volume_attach_limit",
 			options: &Options{
@@ -1445,6 +1735,7 @@ func TestNodePublishVolume(t *testing.T) {
 		req          *csi.NodePublishVolumeRequest
 		mounterMock  func(ctrl *gomock.Controller) *mounter.MockMounter
 		metadataMock func(ctrl *gomock.Controller) *metadata.MockMetadataService
+		options      *Options
 		expectedErr  error
 		inflight     bool
 	}{
@@ -1470,6 +1761,7 @@ func TestNodePublishVolume(t *testing.T) {
 				m := mounter.NewMockMounter(ctrl)
 
 				m.EXPECT().FindDevicePath(gomock.Eq("/dev/xvdba"), gomock.Eq("vol-test"), gomock.Eq(""), gomock.Eq("us-west-2")).Return("/dev/xvdba", nil)
+				m.EXPECT().BlockDevicePath(gomock.Eq("/dev/xvdba")).Return("/dev/xvdba", nil)
 				m.EXPECT().PathExists(gomock.Eq("/target")).Return(true, nil)
 				m.EXPECT().MakeFile(gomock.Eq("/target/path")).Return(nil)
 				m.EXPECT().IsLikelyNotMountPoint(gomock.Eq("/target/path")).Return(true, nil)
@@ -1508,6 +1800,62 @@ func TestNodePublishVolume(t *testing.T) {
 				return m
 			},
 		},
+		{
+			name: "success_fs_ext4_fscrypt",
+			req: &csi.NodePublishVolumeRequest{
+				VolumeId:          "vol-test",
+				StagingTargetPath: "/staging/path",
+				TargetPath:        "/target/path",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+					},
+				},
+				VolumeContext: map[string]string{
+					Ext4FscryptKey: "true",
+				},
+				PublishContext: map[string]string{
+					DevicePathKey: "/dev/xvdba",
+				},
+			},
+			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
+				m := mounter.NewMockMounter(ctrl)
+				m.EXPECT().PreparePublishTarget(gomock.Eq("/target/path")).Return(nil)
+				m.EXPECT().IsLikelyNotMountPoint(gomock.Eq("/target/path")).Return(true, nil)
+				m.EXPECT().Mount(gomock.Eq(filepath.Join("/staging/path", FscryptDataDir)), gomock.Eq("/target/path"), gomock.Eq("ext4"), gomock.Eq([]string{"bind"})).Return(nil)
+				return m
+			},
+		},
+		{
+			name: "success_fs_hardened_mount_defaults",
+			req: &csi.NodePublishVolumeRequest{
+				VolumeId:          "vol-test",
+				StagingTargetPath: "/staging/path",
+				TargetPath:        "/target/path",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Mount{
+						Mount: &csi.VolumeCapability_MountVolume{},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+					},
+				},
+				PublishContext: map[string]string{
+					DevicePathKey: "/dev/xvdba",
+				},
+			},
+			options: &Options{HardenedMountDefaults: true},
+			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
+				m := mounter.NewMockMounter(ctrl)
+				m.EXPECT().PreparePublishTarget(gomock.Eq("/target/path")).Return(nil)
+				m.EXPECT().IsLikelyNotMountPoint(gomock.Eq("/target/path")).Return(true, nil)
+				m.EXPECT().Mount(gomock.Eq("/staging/path"), gomock.Eq("/target/path"), gomock.Eq("ext4"), gomock.Eq([]string{"bind", "nodev", "nosuid"})).Return(nil)
+				return m
+			},
+		},
 		{
 			name: "volume_id_not_provided",
 			req: &csi.NodePublishVolumeRequest{
@@ -1616,7 +1964,7 @@ func TestNodePublishVolume(t *testing.T) {
 					DevicePathKey: "/dev/xvdba",
 				},
 			},
-			expectedErr: status.Errorf(codes.InvalidArgument, "Volume capability not supported"),
+			expectedErr: status.Errorf(codes.InvalidArgument, "Volume capability not supported: access mode UNKNOWN is not supported"),
 		},
 		{
 			name: "read_only_enabled",
@@ -1641,6 +1989,7 @@ func TestNodePublishVolume(t *testing.T) {
 				m := mounter.NewMockMounter(ctrl)
 
 				m.EXPECT().FindDevicePath(gomock.Eq("/dev/xvdba"), gomock.Eq("vol-test"), gomock.Eq(""), gomock.Eq("us-west-2")).Return("/dev/xvdba", nil)
+				m.EXPECT().BlockDevicePath(gomock.Eq("/dev/xvdba")).Return("/dev/xvdba", nil)
 				m.EXPECT().PathExists(gomock.Eq("/target")).Return(true, nil)
 				m.EXPECT().MakeFile(gomock.Eq("/target/path")).Return(nil)
 				m.EXPECT().IsLikelyNotMountPoint(gomock.Eq("/target/path")).Return(true, nil)
@@ -1718,6 +2067,7 @@ func TestNodePublishVolume(t *testing.T) {
 				m := mounter.NewMockMounter(ctrl)
 
 				m.EXPECT().FindDevicePath(gomock.Eq("/dev/xvdba"), gomock.Eq("vol-test"), gomock.Eq(""), gomock.Eq("us-west-2")).Return("/dev/xvdba", nil)
+				m.EXPECT().BlockDevicePath(gomock.Eq("/dev/xvdba")).Return("/dev/xvdba", nil)
 				m.EXPECT().PathExists(gomock.Eq("/target")).Return(true, nil)
 				m.EXPECT().MakeFile(gomock.Eq("/target/path")).Return(nil)
 				m.EXPECT().IsLikelyNotMountPoint(gomock.Eq("/target/path")).Return(true, nil)
@@ -1755,6 +2105,7 @@ func TestNodePublishVolume(t *testing.T) {
 				m := mounter.NewMockMounter(ctrl)
 
 				m.EXPECT().FindDevicePath(gomock.Eq("/dev/xvdba"), gomock.Eq("vol-test"), gomock.Eq("1"), gomock.Eq("us-west-2")).Return("/dev/xvdba1", nil)
+				m.EXPECT().BlockDevicePath(gomock.Eq("/dev/xvdba1")).Return("/dev/xvdba1", nil)
 				m.EXPECT().PathExists(gomock.Eq("/target")).Return(true, nil)
 				m.EXPECT().MakeFile(gomock.Eq("/target/path")).Return(nil)
 				m.EXPECT().IsLikelyNotMountPoint(gomock.Eq("/target/path")).Return(true, nil)
@@ -1798,6 +2149,38 @@ func TestNodePublishVolume(t *testing.T) {
 			},
 			expectedErr: status.Error(codes.NotFound, "Failed to find device path /dev/xvdba. device path error"),
 		},
+		{
+			name: "nodePublishVolumeForBlock_block_device_path_failure",
+			req: &csi.NodePublishVolumeRequest{
+				VolumeId:          "vol-test",
+				StagingTargetPath: "/staging/path",
+				TargetPath:        "/target/path",
+				VolumeCapability: &csi.VolumeCapability{
+					AccessType: &csi.VolumeCapability_Block{
+						Block: &csi.VolumeCapability_BlockVolume{},
+					},
+					AccessMode: &csi.VolumeCapability_AccessMode{
+						Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER,
+					},
+				},
+				PublishContext: map[string]string{
+					DevicePathKey: "/dev/xvdba",
+				},
+			},
+			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
+				m := mounter.NewMockMounter(ctrl)
+
+				m.EXPECT().FindDevicePath(gomock.Eq("/dev/xvdba"), gomock.Eq("vol-test"), gomock.Eq(""), gomock.Eq("us-west-2")).Return("1", nil)
+				m.EXPECT().BlockDevicePath(gomock.Eq("1")).Return("", errors.New("no device interface found for disk 1"))
+				return m
+			},
+			metadataMock: func(ctrl *gomock.Controller) *metadata.MockMetadataService {
+				m := metadata.NewMockMetadataService(ctrl)
+				m.EXPECT().GetRegion().Return("us-west-2")
+				return m
+			},
+			expectedErr: status.Error(codes.Internal, "Failed to get block device path of /dev/xvdba: no device interface found for disk 1"),
+		},
 		{
 			name: "node_local_volume_block_success",
 			req: &csi.NodePublishVolumeRequest{
@@ -1820,6 +2203,7 @@ func TestNodePublishVolume(t *testing.T) {
 			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
 				m := mounter.NewMockMounter(ctrl)
 				m.EXPECT().FindDevicePath(gomock.Eq("/dev/xvdba"), gomock.Eq("vol-real"), gomock.Eq(""), gomock.Eq("us-west-2")).Return("/dev/xvdba", nil)
+				m.EXPECT().BlockDevicePath(gomock.Eq("/dev/xvdba")).Return("/dev/xvdba", nil)
 				m.EXPECT().PathExists(gomock.Eq("/target")).Return(true, nil)
 				m.EXPECT().MakeFile(gomock.Eq("/target/path")).Return(nil)
 				m.EXPECT().IsLikelyNotMountPoint(gomock.Eq("/target/path")).Return(true, nil)
@@ -1868,9 +2252,15 @@ func TestNodePublishVolume(t *testing.T) {
 				metadata = tc.metadataMock(ctrl)
 			}
 
+			options := tc.options
+			if options == nil {
+				options = &Options{}
+			}
+
 			driver := &NodeService{
 				metadata: metadata,
 				mounter:  mounter,
+				options:  options,
 				inFlight: internal.NewInFlight(),
 			}
 
@@ -1903,6 +2293,7 @@ func TestNodeUnstageVolume(t *testing.T) {
 			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
 				m := mounter.NewMockMounter(ctrl)
 				m.EXPECT().GetDeviceNameFromMount(gomock.Eq("/staging/path")).Return("dev-test", 1, nil)
+				m.EXPECT().RemoveFscryptKey(gomock.Eq(filepath.Join("/staging/path", FscryptDataDir))).Return(nil)
 				m.EXPECT().Unstage(gomock.Eq("/staging/path")).Return(nil)
 				return m
 			},
@@ -1930,6 +2321,7 @@ func TestNodeUnstageVolume(t *testing.T) {
 			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
 				m := mounter.NewMockMounter(ctrl)
 				m.EXPECT().GetDeviceNameFromMount(gomock.Eq("/staging/path")).Return("", 1, nil)
+				m.EXPECT().RemoveFscryptKey(gomock.Eq(filepath.Join("/staging/path", FscryptDataDir))).Return(nil)
 				m.EXPECT().Unstage(gomock.Eq("/staging/path")).Return(errors.New("unstage failed"))
 				return m
 			},
@@ -1969,6 +2361,7 @@ func TestNodeUnstageVolume(t *testing.T) {
 			mounterMock: func(ctrl *gomock.Controller) *mounter.MockMounter {
 				m := mounter.NewMockMounter(ctrl)
 				m.EXPECT().GetDeviceNameFromMount(gomock.Eq("/staging/path")).Return("dev-test", 2, nil)
+				m.EXPECT().RemoveFscryptKey(gomock.Eq(filepath.Join("/staging/path", FscryptDataDir))).Return(nil)
 				m.EXPECT().Unstage(gomock.Eq("/staging/path")).Return(nil)
 				return m
 			},
@@ -2312,7 +2705,7 @@ func TestNodeExpandVolume(t *testing.T) {
 					},
 				},
 			},
-			expectedErr: status.Error(codes.InvalidArgument, "VolumeCapability is invalid"),
+			expectedErr: status.Error(codes.InvalidArgument, "VolumeCapability is invalid: access mode UNKNOWN is not supported"),
 		},
 		{
 			name: "block_device",
@@ -2509,6 +2902,7 @@ func TestNodeGetVolumeStats(t *testing.T) {
 		metricsStatErr bool
 		mounterMock    func(mockCtl *gomock.Controller, dir string) *mounter.MockMounter
 		expectedErr    func(dir string) error
+		expectedBytes  *csi.VolumeUsage
 	}{
 		{
 			name:       "success normal",
@@ -2525,6 +2919,33 @@ func TestNodeGetVolumeStats(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name:       "success with xfs project quota",
+			validVolID: true,
+			validPath:  true,
+			mounterMock: func(ctrl *gomock.Controller, dir string) *mounter.MockMounter {
+				m := mounter.NewMockMounter(ctrl)
+				m.EXPECT().PathExists(dir).Return(true, nil)
+				m.EXPECT().IsBlockDevice(gomock.Eq(dir)).Return(false, nil)
+				m.EXPECT().GetVolumeStats(gomock.Eq(dir)).Return(mounter.VolumeStats{
+					TotalBytes:             100 * util.GiB,
+					AvailableBytes:         90 * util.GiB,
+					UsedBytes:              10 * util.GiB,
+					ProjectQuotaLimitBytes: 4 * util.GiB,
+					ProjectQuotaUsedBytes:  1 * util.GiB,
+				}, nil)
+				return m
+			},
+			expectedErr: func(dir string) error {
+				return nil
+			},
+			expectedBytes: &csi.VolumeUsage{
+				Unit:      csi.VolumeUsage_BYTES,
+				Total:     4 * util.GiB,
+				Available: 3 * util.GiB,
+				Used:      1 * util.GiB,
+			},
+		},
 		{
 			name:       "invalid_volume_id",
 			validVolID: false,
@@ -2641,11 +3062,17 @@ func TestNodeGetVolumeStats(t *testing.T) {
 				req.VolumePath = "fake-path"
 			}
 
-			_, err := driver.NodeGetVolumeStats(t.Context(), req)
+			resp, err := driver.NodeGetVolumeStats(t.Context(), req)
 
 			if !reflect.DeepEqual(err, tc.expectedErr(dir)) {
 				t.Fatalf("Expected error '%v' but got '%v'", tc.expectedErr(dir), err)
 			}
+			if tc.expectedBytes != nil {
+				got := resp.GetUsage()[0]
+				if got.GetTotal() != tc.expectedBytes.GetTotal() || got.GetAvailable() != tc.expectedBytes.GetAvailable() || got.GetUsed() != tc.expectedBytes.GetUsed() {
+					t.Fatalf("Expected bytes usage %v but got %v", tc.expectedBytes, got)
+				}
+			}
 		})
 	}
 }
@@ -0,0 +1,108 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/mounter"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/nodeapi"
+	"k8s.io/klog/v2"
+)
+
+// nodeVolumes keeps the volumes staged by the node plugin and serves them on nodeapi.VolumesPath, along with the
+// volumes attached to the node, so that the metadata labeler, sidecars and tooling do not need to map devices to
+// volumes from sysfs.
+type nodeVolumes struct {
+	mounter mounter.Mounter
+
+	mu     sync.Mutex
+	staged map[string]nodeapi.Volume
+}
+
+func newNodeVolumes(m mounter.Mounter) *nodeVolumes {
+	return &nodeVolumes{mounter: m, staged: map[string]nodeapi.Volume{}}
+}
+
+// stage records that a volume was staged at target from device.
+func (v *nodeVolumes) stage(volumeID, device, target, fsType string) {
+	if v == nil {
+		return
+	}
+	now := time.Now()
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.staged[volumeID] = nodeapi.Volume{VolumeID: volumeID, DevicePath: device, StagingTargetPath: target, FsType: fsType, StagedAt: &now}
+}
+
+// unstage forgets a volume once it is unstaged.
+func (v *nodeVolumes) unstage(volumeID string) {
+	if v == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.staged, volumeID)
+}
+
+// list returns the volumes attached to the node or staged by the node plugin, sorted by volume ID, or only volumeID
+// if it is not empty.
+func (v *nodeVolumes) list(volumeID string) *nodeapi.VolumeList {
+	attached, err := v.mounter.AttachedVolumes()
+	if err != nil {
+		klog.V(4).InfoS("Could not list the volumes attached to the node, only listing staged volumes", "err", err)
+	}
+
+	volumes := map[string]nodeapi.Volume{}
+	for id, device := range attached {
+		volumes[id] = nodeapi.Volume{VolumeID: id, DevicePath: device}
+	}
+	v.mu.Lock()
+	for id, staged := range v.staged {
+		if device := volumes[id].DevicePath; device != "" {
+			staged.DevicePath = device
+		}
+		volumes[id] = staged
+	}
+	v.mu.Unlock()
+
+	list := &nodeapi.VolumeList{AttachedVolumesListed: err == nil, Volumes: make([]nodeapi.Volume, 0, len(volumes))}
+	for id, volume := range volumes {
+		if volumeID == "" || id == volumeID {
+			list.Volumes = append(list.Volumes, volume)
+		}
+	}
+	slices.SortFunc(list.Volumes, func(a, b nodeapi.Volume) int { return strings.Compare(a.VolumeID, b.VolumeID) })
+	return list
+}
+
+// ServeHTTP serves the volumes of the node, for one volume with the volumeID query parameter or for all volumes.
+func (v *nodeVolumes) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v.list(r.URL.Query().Get("volumeID"))); err != nil {
+		klog.ErrorS(err, "Could not write node volumes")
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/mounter"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/nodeapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeVolumes(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockMounter := mounter.NewMockMounter(mockCtl)
+	mockMounter.EXPECT().AttachedVolumes().Return(map[string]string{
+		"vol-attached": "/dev/nvme1n1",
+		"vol-staged":   "/dev/nvme2n1",
+	}, nil).AnyTimes()
+
+	volumes := newNodeVolumes(mockMounter)
+	volumes.stage("vol-staged", "/dev/xvdba", "/staging/vol-staged", FSTypeXfs)
+	volumes.stage("vol-xen", "/dev/xvdbb", "/staging/vol-xen", FSTypeExt4)
+	volumes.stage("vol-unstaged", "/dev/nvme3n1", "/staging/vol-unstaged", FSTypeExt4)
+	volumes.unstage("vol-unstaged")
+
+	list := volumes.list("").Volumes
+	require.Len(t, list, 3)
+	assert.Equal(t, nodeapi.Volume{VolumeID: "vol-attached", DevicePath: "/dev/nvme1n1"}, list[0])
+	// The nvme device found by serial number is reported rather than the device path the volume was staged from
+	assert.Equal(t, "vol-staged", list[1].VolumeID)
+	assert.Equal(t, "/dev/nvme2n1", list[1].DevicePath)
+	assert.Equal(t, "/staging/vol-staged", list[1].StagingTargetPath)
+	assert.Equal(t, FSTypeXfs, list[1].FsType)
+	assert.NotNil(t, list[1].StagedAt)
+	assert.Equal(t, "vol-xen", list[2].VolumeID)
+	assert.Equal(t, "/dev/xvdbb", list[2].DevicePath)
+
+	rec := httptest.NewRecorder()
+	volumes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, nodeapi.VolumesPath+"?volumeID=vol-attached", nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var served nodeapi.VolumeList
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	assert.Equal(t, nodeapi.VolumeList{AttachedVolumesListed: true, Volumes: []nodeapi.Volume{{VolumeID: "vol-attached", DevicePath: "/dev/nvme1n1"}}}, served)
+}
+
+func TestNodeVolumesAttachedVolumesError(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockMounter := mounter.NewMockMounter(mockCtl)
+	mockMounter.EXPECT().AttachedVolumes().Return(nil, errors.New("not supported"))
+
+	// Staged volumes are still listed on platforms where the attached volumes cannot be listed
+	volumes := newNodeVolumes(mockMounter)
+	volumes.stage("vol-staged", "/dev/xvdba", "/staging/vol-staged", FSTypeExt4)
+	list := volumes.list("")
+	assert.False(t, list.AttachedVolumesListed)
+	require.Len(t, list.Volumes, 1)
+	assert.Equal(t, "/dev/xvdba", list.Volumes[0].DevicePath)
+}
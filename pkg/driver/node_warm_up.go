@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"golang.org/x/time/rate"
+	"k8s.io/klog/v2"
+)
+
+// warmUpChunkSize is the size of the reads of a volume being warmed up.
+const warmUpChunkSize = 1 << 20
+
+// Results of the warm-up of a volume.
+const (
+	warmUpCompleted = "completed"
+	warmUpCanceled  = "canceled"
+	warmUpFailed    = "failed"
+)
+
+// volumeWarmer reads the whole device of the volumes restored from a snapshot without fast snapshot restore in the
+// background, so that EBS downloads their blocks from the snapshot before the workload reads them instead of on
+// first access. Reads are limited to --volume-warm-up-rate for all volumes together, to leave the EBS bandwidth of the
+// instance to the workloads. Warm-ups are kept in memory: they are not resumed after the node plugin restarts.
+type volumeWarmer struct {
+	limiter *rate.Limiter
+
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+	// wg tracks the running warm-ups, for tests
+	wg sync.WaitGroup
+}
+
+func newVolumeWarmer(o *Options) *volumeWarmer {
+	if o.VolumeWarmUpRate <= 0 {
+		return nil
+	}
+	bytesPerSecond := o.VolumeWarmUpRate << 20
+	return &volumeWarmer{
+		limiter: rate.NewLimiter(rate.Limit(bytesPerSecond), max(bytesPerSecond, warmUpChunkSize)),
+		running: map[string]context.CancelFunc{},
+	}
+}
+
+// start warms up the volume read from device, unless it is already being warmed up.
+func (w *volumeWarmer) start(volumeID, device string) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.running[volumeID]; ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.running[volumeID] = cancel
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		result := warmUpCompleted
+		err := w.warmUp(ctx, volumeID, device)
+		switch {
+		case errors.Is(err, context.Canceled):
+			result = warmUpCanceled
+			klog.V(4).InfoS("Volume warm-up canceled", "volumeID", volumeID)
+		case err != nil:
+			result = warmUpFailed
+			klog.ErrorS(err, "Could not warm up volume", "volumeID", volumeID, "device", device)
+		default:
+			klog.InfoS("Volume warmed up", "volumeID", volumeID, "device", device)
+		}
+		metrics.Recorder().IncreaseCount(metrics.VolumeWarmUps, metrics.VolumeWarmUpsHelpText, map[string]string{"result": result})
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		// A stopped warm-up was already removed, and the volume may be warming up again since it was staged again
+		if ctx.Err() == nil {
+			delete(w.running, volumeID)
+		}
+		cancel()
+	}()
+}
+
+// stop cancels the warm-up of the volume, if any, before it is unstaged.
+func (w *volumeWarmer) stop(volumeID string) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if cancel, ok := w.running[volumeID]; ok {
+		cancel()
+		delete(w.running, volumeID)
+	}
+}
+
+// warmUp reads device sequentially from start to end and reports the progress of the read.
+func (w *volumeWarmer) warmUp(ctx context.Context, volumeID, device string) error {
+	f, err := os.Open(device)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("could not get the size of %s: %w", device, err)
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	klog.InfoS("Warming up volume restored from a snapshot", "volumeID", volumeID, "device", device, "sizeBytes", size)
+	labels := map[string]string{"volume_id": volumeID}
+	buf := make([]byte, warmUpChunkSize)
+	var read int64
+	lastPercent := -1
+	for {
+		if err = w.limiter.WaitN(ctx, len(buf)); err != nil {
+			return err
+		}
+		n, readErr := io.ReadFull(f, buf)
+		read += int64(n)
+		if percent := progressPercent(read, size); percent != lastPercent {
+			lastPercent = percent
+			metrics.Recorder().SetGauge(metrics.VolumeWarmUpProgress, metrics.VolumeWarmUpProgressHelpText, float64(percent), labels)
+		}
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// progressPercent returns the percentage of size that read is.
+func progressPercent(read, size int64) int {
+	if size <= 0 || read >= size {
+		return 100
+	}
+	return int(read * 100 / size)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolumeWarmer(t *testing.T) {
+	assert.Nil(t, newVolumeWarmer(&Options{}))
+
+	device := filepath.Join(t.TempDir(), "device")
+	require.NoError(t, os.WriteFile(device, make([]byte, 3*warmUpChunkSize+10), 0o600))
+
+	t.Run("reads the whole device", func(t *testing.T) {
+		w := newVolumeWarmer(&Options{VolumeWarmUpRate: 100})
+		w.start("vol-test", device)
+		w.wg.Wait()
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		assert.Empty(t, w.running)
+	})
+
+	t.Run("stop cancels the warm-up", func(t *testing.T) {
+		// One chunk per second: the warm-up waits for the limiter after its first chunk
+		w := newVolumeWarmer(&Options{VolumeWarmUpRate: 1})
+		w.start("vol-test", device)
+		w.start("vol-test", device)
+		w.mu.Lock()
+		assert.Len(t, w.running, 1)
+		w.mu.Unlock()
+		w.stop("vol-test")
+		w.wg.Wait()
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		assert.Empty(t, w.running)
+	})
+
+	t.Run("missing device", func(t *testing.T) {
+		w := newVolumeWarmer(&Options{VolumeWarmUpRate: 100})
+		err := w.warmUp(t.Context(), "vol-test", filepath.Join(t.TempDir(), "missing"))
+		require.Error(t, err)
+	})
+}
+
+func TestProgressPercent(t *testing.T) {
+	assert.Equal(t, 0, progressPercent(0, 100))
+	assert.Equal(t, 42, progressPercent(42, 100))
+	assert.Equal(t, 100, progressPercent(100, 100))
+	assert.Equal(t, 100, progressPercent(0, 0))
+}
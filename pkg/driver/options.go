@@ -17,13 +17,23 @@ limitations under the License.
 package driver
 
 import (
+	"crypto/x509"
 	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud/metadata"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/mounter"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/nodeapi"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util/template"
 	flag "github.com/spf13/pflag"
 	cliflag "k8s.io/component-base/cli/flag"
+	"sigs.k8s.io/yaml"
 )
 
 // Options contains options and configuration settings for the driver.
@@ -38,6 +48,15 @@ type Options struct {
 
 	// Endpoint is the endpoint for the CSI driver server
 	Endpoint string
+	// AllowNonLoopbackEndpoint allows Endpoint to be a TCP address that is not a loopback address
+	AllowNonLoopbackEndpoint bool
+	// NodeAPIEndpoint is the TCP network address where the node plugin serves the volumes of its node. Empty, the
+	// default, disables the node API, which is not authenticated.
+	NodeAPIEndpoint string
+	// NodeAPIPort and NodePluginSelector locate the node API of the node plugin on each node for the metadata
+	// labeler. A NodeAPIPort of 0, the default, counts the volumes of the nodes from EC2 only.
+	NodeAPIPort        int
+	NodePluginSelector string
 	// HTTPEndpoint is the TCP network address where the HTTP server for metrics will listen
 	HTTPEndpoint string
 	// MetricsCertFile is the location of the certificate for serving the metrics server over HTTPS
@@ -46,6 +65,8 @@ type Options struct {
 	MetricsKeyFile string
 	// EnableOtelTracing is a flag to enable opentelemetry tracing for the driver
 	EnableOtelTracing bool
+	// PayloadLogSampleRate is the fraction of RPCs whose full request and response are logged, with secrets redacted
+	PayloadLogSampleRate float64
 
 	// #### Controller options ####
 
@@ -63,10 +84,28 @@ type Options struct {
 	AwsSdkDebugLog bool
 	// flag to warn on invalid tag, instead of returning an error
 	WarnOnInvalidTag bool
+	// StrictParameters rejects StorageClass and VolumeAttributesClass parameters set to an invalid value instead of
+	// reading them as their default value.
+	StrictParameters bool
+	// NameTagFromTemplate sets the Name tag of created volumes and snapshots from NameTagTemplate and
+	// SnapshotNameTagTemplate.
+	NameTagFromTemplate bool
+	// NameTagTemplate is the template of the Name tag of volumes, interpolated like tagSpecification parameters.
+	NameTagTemplate string
+	// SnapshotNameTagTemplate is the template of the Name tag of snapshots, interpolated like tagSpecification
+	// parameters of VolumeSnapshotClasses.
+	SnapshotNameTagTemplate string
+	// PVCMetadataCacheMaxStaleness enables reading the labels and annotations of PVCs for tag templates from a watch
+	// of PVCs, trusted while the watch progressed within this duration. 0 gets each PVC from the API server.
+	PVCMetadataCacheMaxStaleness time.Duration
 	// flag to set user agent
 	UserAgentExtra string
 	// flag to enable batching of API calls
 	Batching bool
+	// BatchMinDelay is how long batched EC2 describe calls wait for more requests when there is little load.
+	BatchMinDelay time.Duration
+	// BatchMaxDelay is how long batched EC2 describe calls wait for more requests under load.
+	BatchMaxDelay time.Duration
 	// flag to set the timeout for volume modification requests to be coalesced into a single
 	// volume modification call to AWS.
 	ModifyVolumeRequestHandlerTimeout time.Duration
@@ -74,6 +113,162 @@ type Options struct {
 	DeprecatedMetrics bool
 	// flag to enable node-local volume support
 	EnableNodeLocalVolumes bool
+	// flag to clone volumes by restoring from a temporary snapshot of the source volume
+	// instead of calling EC2 CopyVolumes.
+	CloneViaSnapshot bool
+	// flag to set the maximum number of requests waiting on batched or coalesced EC2 calls before
+	// new controller RPCs are rejected with ResourceExhausted. 0 disables the limit.
+	MaxQueuedRequests int
+	// flag to append the correlation ID of the originating CSI request to the user agent of EC2 calls
+	CorrelationIDUserAgent bool
+	// flag to append the driver subsystem (provision, attach, snapshot or modify) that caused an EC2 call to its user agent
+	SubsystemUserAgent bool
+	// SoftDeleteRetention enables soft-delete when non-zero: DeleteVolume tags volumes for deletion
+	// after this period instead of deleting them, and a background reaper deletes them once it expires.
+	SoftDeleteRetention time.Duration
+	// NamespaceQuotas limits the total size and IOPS of the volumes provisioned for each namespace, keyed by
+	// namespace ("*" for all other namespaces). Loaded from the file passed to --namespace-quotas-file.
+	NamespaceQuotas map[string]NamespaceQuota
+	// SnapshotsPerRegionQuota is the Snapshots per Region quota of the account. When non-zero, CreateSnapshot
+	// counts the snapshots of the account and fails early when the quota would be exceeded.
+	SnapshotsPerRegionQuota int
+	// MaxConcurrentSnapshots limits the number of CreateSnapshot calls to EC2 in flight at once. Requests over the
+	// limit wait in a queue that is served fairly across namespaces. 0 disables the limit.
+	MaxConcurrentSnapshots int
+	// MaxQueuedSnapshots is the number of CreateSnapshot requests that can wait for MaxConcurrentSnapshots before
+	// new requests are rejected with ResourceExhausted. 0 means no limit.
+	MaxQueuedSnapshots int
+	// VolumeStatusPollInterval is how often the status of the attached volumes is polled to report impaired
+	// volumes. 0 disables polling.
+	VolumeStatusPollInterval time.Duration
+	// VolumeInitializationPollInterval is how often the initialization of the volumes restored from a snapshot is
+	// polled to report its progress on their PVC. 0 disables polling.
+	VolumeInitializationPollInterval time.Duration
+	// AutoEnableVolumeIO re-enables the I/O of attached volumes whose I/O EBS disabled.
+	AutoEnableVolumeIO bool
+	// APIBudgetRate is the rate of mutating EC2 calls per second split between the API budget classes. 0 disables
+	// API budgets.
+	APIBudgetRate float64
+	// APIBudgetWeights are the relative weights of the API budget classes that StorageClasses select with the
+	// apiBudgetClass parameter.
+	APIBudgetWeights map[string]int
+	// ZoneWeights are the relative weights of availability zones, by zone name or zone ID, used to choose the zone
+	// of volumes that can be created in several zones. Zones not listed have weight 1.
+	ZoneWeights map[string]int
+	// ZoneFailureWindow is how long zonal CreateVolume failures lower the weight of their zone. 0 disables it.
+	ZoneFailureWindow time.Duration
+	// CapacityAwareZoneSelection makes the controller create volumes that can be created in several zones in the zone
+	// with the most EBS capacity left, instead of spreading them by weight.
+	CapacityAwareZoneSelection bool
+	// QuotaExceededBackoff is how long CreateVolume stops calling EC2 for the volumes of a type after their storage or
+	// IOPS quota was exceeded, doubling with consecutive failures. 0 disables it.
+	QuotaExceededBackoff time.Duration
+	// PolicyWebhookURL is the HTTP endpoint CreateVolume requests are sent to for review before EC2 is called. Empty
+	// disables the policy webhook.
+	PolicyWebhookURL string
+	// PolicyWebhookTimeout bounds each call to the policy webhook.
+	PolicyWebhookTimeout time.Duration
+	// PolicyWebhookFailurePolicy is Fail to fail, or Ignore to allow, the requests the policy webhook cannot review.
+	PolicyWebhookFailurePolicy string
+	// PolicyWebhookCAs verify the certificate of an HTTPS policy webhook instead of the system roots. Loaded from the
+	// file passed to --policy-webhook-ca-file.
+	PolicyWebhookCAs *x509.CertPool
+	// EstimateCosts makes the controller record the approximate monthly cost of the volumes and snapshots it creates.
+	EstimateCosts bool
+	// CostPriceTable overrides the built-in prices EstimateCosts uses, by volume type. Loaded from the file passed to
+	// --cost-price-table-file.
+	CostPriceTable *PriceTable
+	// VolumeTagPolicies makes the controller watch VolumeTagPolicy and ClusterVolumeTagPolicy objects and tag the volumes
+	// and snapshots of the PVCs they select.
+	VolumeTagPolicies bool
+	// StorageQuotas are the EBS storage quotas of the account in TiB, by volume type. GetCapacity reports the
+	// storage left under the quota of the volume type of a StorageClass.
+	StorageQuotas map[string]int
+	// VolumesPerRegionQuota is the number of volumes the account may own in the region. When non-zero,
+	// GetCapacity reports no capacity once the account owns this many volumes.
+	VolumesPerRegionQuota int
+	// DefaultVolumeParameters are CreateVolume parameters applied to the volumes whose StorageClass does not set them.
+	DefaultVolumeParameters map[string]string
+	// DefaultGP3IOPS and DefaultGP3Throughput are the IOPS and throughput in MiB/s of the gp3 volumes whose
+	// StorageClass does not set them. They take precedence over DefaultVolumeParameters. 0 keeps the gp3 baseline.
+	DefaultGP3IOPS       int32
+	DefaultGP3Throughput int32
+	// ForceEncryption encrypts every created volume, and rejects StorageClasses that disable encryption.
+	ForceEncryption bool
+	// ForceEncryptionKMSKeyID is the KMS key of the volumes encrypted by ForceEncryption whose StorageClass does not
+	// set kmsKeyId. Empty uses the default EBS encryption key of the account.
+	ForceEncryptionKMSKeyID string
+	// RetryPolicy overrides how the controller waits for and retries EC2 operations. Loaded from the file passed to
+	// --retry-policy-file.
+	RetryPolicy *cloud.RetryPolicy
+	// AttachmentHistoryLength is the number of attach and detach transitions kept in memory per volume and served
+	// on the debug endpoint of the metrics server. 0 disables the history.
+	AttachmentHistoryLength int
+	// AttachmentHistoryLog also logs each attach and detach transition.
+	AttachmentHistoryLog bool
+	// ClientTokenStrategy is how the client token of CreateVolume is derived: from the volume name only
+	// (volume-name) or from the volume name and the parameters of the request (request-hash).
+	ClientTokenStrategy string
+	// ClientTokenConfigMap is the name of the ConfigMap in which the client tokens retired after
+	// IdempotentParameterMismatch errors are persisted across restarts. Empty keeps them in memory only.
+	ClientTokenConfigMap string
+	// ClientTokenConfigMapNamespace is the namespace of ClientTokenConfigMap.
+	ClientTokenConfigMapNamespace string
+	// ControllerShards is the number of active controller replicas that split the modification of volumes and the
+	// background reconcilers between them by volume ID hash. 0 or 1 disables sharding.
+	ControllerShards int
+	// ControllerShardIndex is the shard, between 0 and ControllerShards-1, handled by this replica.
+	ControllerShardIndex int
+	// ControllerShardPeers are the gRPC addresses of the controller shards, in shard order, to which the requests
+	// for the volumes of other shards are forwarded. Empty rejects them with Unavailable.
+	ControllerShardPeers []string
+	// ControllerShardPeerAddress is the address on which requests forwarded by other shards are served.
+	ControllerShardPeerAddress string
+	// ControllerShardPeerTLSCertFile and ControllerShardPeerTLSKeyFile are the key pair presented to the other shards,
+	// and ControllerShardPeerTLSCAFile the certificate authorities that their certificates must be signed by.
+	ControllerShardPeerTLSCertFile string
+	ControllerShardPeerTLSKeyFile  string
+	ControllerShardPeerTLSCAFile   string
+	// HandoffLease is the name of the Lease in which in-flight volume deletions and fast snapshot restore
+	// enablements are recorded, so that the next leader resumes them after a failover. Empty disables the handoff.
+	HandoffLease string
+	// HandoffLeaseNamespace is the namespace of HandoffLease. Empty uses the namespace of the controller Pod.
+	HandoffLeaseNamespace string
+	// DegradedThrottleThreshold is the number of throttled EC2 calls per minute above which, when sustained, the
+	// controller announces degraded provisioning. 0 disables the announcements.
+	DegradedThrottleThreshold int
+	// DegradedStatusConfigMap is the name of the ConfigMap in which degraded provisioning is announced.
+	DegradedStatusConfigMap string
+	// DegradedStatusNamespace is the namespace of DegradedStatusConfigMap.
+	DegradedStatusNamespace string
+	// DeletionProtectedNamespaces are the namespaces whose volumes DeleteVolume only deletes once their PV is
+	// annotated with ConfirmDeletionAnnotation. "*" protects every namespace.
+	DeletionProtectedNamespaces []string
+	// CheckEBSBandwidth compares the EBS-optimized bandwidth of instances with the throughput of their attached
+	// volumes on ControllerPublishVolume, and warns when it is oversubscribed.
+	CheckEBSBandwidth bool
+
+	// #### Adopt options #####
+
+	// AdoptVolumeIDs are the IDs of the existing volumes to adopt.
+	AdoptVolumeIDs []string
+	// AdoptTagFilter selects existing volumes to adopt by tag. Volumes must carry every tag in the filter.
+	AdoptTagFilter map[string]string
+	// AdoptNamespace is the namespace of the generated PVCs.
+	AdoptNamespace string
+	// AdoptStorageClass is the StorageClass name set on the generated PVs and PVCs.
+	AdoptStorageClass string
+	// AdoptFSType is the filesystem type set on the generated PVs.
+	AdoptFSType string
+	// AdoptDryRun prints the manifests without tagging the volumes.
+	AdoptDryRun bool
+
+	// #### Migrate options #####
+
+	// MigratePVC is the namespace/name of the PVC whose volume is migrated.
+	MigratePVC string
+	// MigrateTargetZone is the availability zone the volume is migrated to.
+	MigrateTargetZone string
 
 	// #### Node options #####
 
@@ -92,6 +287,11 @@ type Options struct {
 	// When -1, the amount of reserved attachments is loaded from instance metadata that captured state at node boot
 	// and may include not only system disks but also CSI volumes (and therefore it may be wrong).
 	ReservedVolumeAttachments int
+	// ReservedAttachments specifies the number of attachment slots reserved for each class of device.
+	// The ebs class replaces ReservedVolumeAttachments and the eni class replaces the number of secondary network
+	// interfaces found in instance metadata. The other classes are only reserved on instance types whose attachment
+	// limit is shared between EBS volumes and other devices. This option is not used when --volume-attach-limit is specified.
+	ReservedAttachments map[AttachmentClass]int
 	// ALPHA: WindowsHostProcess indicates whether the driver is running in a Windows privileged container
 	WindowsHostProcess bool
 	// LegacyXFSProgs formats XFS volumes with `bigtime=0,inobtcount=0,reflink=0,nrext64=0`, so that they can be mounted onto nodes with linux kernel ≤ v5.4. Volumes formatted with this option may experience issues after 2038, and will be unable to use some XFS features (for example, reflinks).
@@ -102,21 +302,50 @@ type Options struct {
 	// The driver will attempt to rely on each source in order until one succeeds.
 	// Valid options include 'imds' and 'kubernetes'.
 	MetadataSources []string
+	// MountNamespace is the mount namespace in which volumes are formatted and mounted: container, host, or auto.
+	MountNamespace string
+	// HardenedMountDefaults mounts filesystem volumes with nodev and nosuid unless their StorageClass sets dev or suid.
+	HardenedMountDefaults bool
+	// HardenedMountNoExec additionally mounts filesystem volumes with noexec unless their StorageClass sets exec.
+	HardenedMountNoExec bool
+	// HostRootfsPath is the path where the root filesystem of the host is mounted in the driver container, used to
+	// enter the host mount namespace.
+	HostRootfsPath string
+	// UdevSettleStrategy is how the node waits for the device of an attached volume to show up: poll, udevadm, or none.
+	UdevSettleStrategy string
+	// UdevSettleTimeout is how long the node waits for the device of an attached volume to show up.
+	UdevSettleTimeout time.Duration
+	// UdevPollInterval is the minimum time between two lookups of the device of an attached volume.
+	UdevPollInterval time.Duration
+	// AllocatableReconcileInterval is how often the node compares the allocatable count of its CSINode with its
+	// volume limit, and registers the driver again when they diverge. 0 disables the comparison.
+	AllocatableReconcileInterval time.Duration
+	// RegistrationDir is the directory where the kubelet watches the registration sockets of plugins.
+	RegistrationDir string
+	// VolumeWarmUpRate is the rate, in MiB/s, at which the node reads the volumes restored from a snapshot without
+	// fast snapshot restore once staged, so that their blocks are downloaded before the workload reads them.
+	// 0 disables the warm-up.
+	VolumeWarmUpRate int
+	// MaxConcurrentNodeOperations is the maximum number of NodeStageVolume and NodePublishVolume calls running at
+	// once. 0 means no limit.
+	MaxConcurrentNodeOperations int
 }
 
 func (o *Options) AddFlags(f *flag.FlagSet) {
 	f.StringVar(&o.Kubeconfig, "kubeconfig", "", "Absolute path to a kubeconfig file. The default is the empty string, which causes the in-cluster config to be used")
 
 	// Server options
-	f.StringVar(&o.Endpoint, "endpoint", DefaultCSIEndpoint, "Endpoint for the CSI driver server")
+	f.StringVar(&o.Endpoint, "endpoint", DefaultCSIEndpoint, "Endpoint for the CSI driver server. Either a unix socket (unix:///path/to/csi.sock) or, for development and debugging, a TCP address (tcp://127.0.0.1:10000).")
+	f.BoolVar(&o.AllowNonLoopbackEndpoint, "allow-non-loopback-endpoint", false, "Allow --endpoint to be a TCP address that is not a loopback address. CSI RPCs are unauthenticated, so anyone who can reach the address can provision, attach and mount volumes.")
 	f.StringVar(&o.HTTPEndpoint, "http-endpoint", "", "The TCP network address where the HTTP server for metrics will listen (example: `:8080`). The default is empty string, which means the server is disabled.")
 	f.StringVar(&o.MetricsCertFile, "metrics-cert-file", "", "The path to a certificate to use for serving the metrics server over HTTPS. If the certificate is signed by a certificate authority, this file should be the concatenation of the server's certificate, any intermediates, and the CA's certificate. If this is non-empty, --http-endpoint and --metrics-key-file MUST also be non-empty.")
 	f.StringVar(&o.MetricsKeyFile, "metrics-key-file", "", "The path to a key to use for serving the metrics server over HTTPS. If this is non-empty, --http-endpoint and --metrics-cert-file MUST also be non-empty.")
 	f.BoolVar(&o.EnableOtelTracing, "enable-otel-tracing", false, "To enable opentelemetry tracing for the driver. The tracing is disabled by default. Configure the exporter endpoint with OTEL_EXPORTER_OTLP_ENDPOINT and other env variables, see https://opentelemetry.io/docs/specs/otel/configuration/sdk-environment-variables/#general-sdk-configuration.")
+	f.Float64Var(&o.PayloadLogSampleRate, "payload-log-sample-rate", 0, "Fraction of CSI RPCs, between 0 and 1, whose full request and response are logged with secrets redacted. Use it to debug sidecar interoperability issues without raising the log level. 0 disables payload logging.")
 	f.StringSliceVar(&o.MetadataSources, "metadata-sources", metadata.DefaultMetadataSources, "Dictates which sources are used to retrieve instance metadata. The driver will attempt to rely on each source in order until one succeeds. Valid options include 'imds', 'kubernetes', and (ALPHA) 'metadata-labeler'.")
 
 	// AWS SDK options, shared by all modes that create a cloud client
-	if o.Mode == AllMode || o.Mode == ControllerMode || o.Mode == MetadataLabelerMode {
+	if o.Mode == AllMode || o.Mode == ControllerMode || o.Mode == MetadataLabelerMode || o.Mode == AdoptMode || o.Mode == MigrateMode {
 		f.StringVar(&o.UserAgentExtra, "user-agent-extra", "", "Extra string appended to user agent.")
 		f.BoolVar(&o.AwsSdkDebugLog, "aws-sdk-debug-log", false, "To enable the aws sdk debug log level (default to false).")
 	}
@@ -127,18 +356,111 @@ func (o *Options) AddFlags(f *flag.FlagSet) {
 		f.Var(cliflag.NewMapStringString(&o.ExtraVolumeTags), "extra-volume-tags", "DEPRECATED: Please use --extra-tags instead. Extra volume tags to attach to each dynamically provisioned volume. It is a comma separated list of key value pairs like '<key1>=<value1>,<key2>=<value2>'")
 		f.StringVar(&o.KubernetesClusterID, "k8s-tag-cluster-id", "", "ID of the Kubernetes cluster used for tagging provisioned EBS volumes (optional).")
 		f.BoolVar(&o.WarnOnInvalidTag, "warn-on-invalid-tag", false, "To warn on invalid tags, instead of returning an error")
+		f.BoolVar(&o.StrictParameters, "strict-parameters", false, "Reject CreateVolume and volume modification requests that set a boolean StorageClass or VolumeAttributesClass parameter to a value other than 'true' or 'false', such as 'True' or 'yes'. Otherwise, such values are read as false and reported with an InvalidParameterValue warning event on the PVC.")
+		f.BoolVar(&o.NameTagFromTemplate, "name-tag-from-template", false, "Set the Name tag of created volumes and snapshots from --name-tag-template and --snapshot-name-tag-template, so that they can be found by PVC or VolumeSnapshot in the AWS console. A short hash of the volume or snapshot name is appended to tell apart resources with the same rendered name, and the value is truncated to 256 characters. Overrides the Name tag set by --k8s-tag-cluster-id. Requires the external-provisioner and external-snapshotter to run with --extra-create-metadata.")
+		f.StringVar(&o.NameTagTemplate, "name-tag-template", DefaultNameTagTemplate, "Template of the Name tag of volumes, with the same fields and functions as tagSpecification StorageClass parameters.")
+		f.StringVar(&o.SnapshotNameTagTemplate, "snapshot-name-tag-template", DefaultSnapshotNameTagTemplate, "Template of the Name tag of snapshots, with the same fields and functions as tagSpecification VolumeSnapshotClass parameters.")
+		f.DurationVar(&o.PVCMetadataCacheMaxStaleness, "pvc-metadata-cache-max-staleness", 0, "If set, the controller watches PVCs and reads the labels and annotations referenced by tag templates from the watch instead of getting the PVC of each volume from the API server. The PVC is still got from the API server when the watch did not progress for this long, or when it does not have the PVC yet. The API server sends watch bookmarks about every minute, so values under 2m fall back to the API server more often. 0 gets every PVC from the API server.")
 		f.BoolVar(&o.Batching, "batching", false, "To enable batching of API calls. This is especially helpful for improving performance in workloads that are sensitive to EC2 rate limits.")
+		f.DurationVar(&o.BatchMinDelay, "batch-min-delay", cloud.DefaultBatchDelay, "How long batched EC2 describe calls wait for more requests when there is little load. The wait grows towards --batch-max-delay as batches fill up. Lower it to reduce the latency of idle clusters.")
+		f.DurationVar(&o.BatchMaxDelay, "batch-max-delay", cloud.DefaultBatchDelay, "How long batched EC2 describe calls wait for more requests under load.")
 		f.DurationVar(&o.ModifyVolumeRequestHandlerTimeout, "modify-volume-request-handler-timeout", DefaultModifyVolumeRequestHandlerTimeout, "Timeout for the window in which volume modification calls must be received in order for them to coalesce into a single volume modification call to AWS. This must be lower than the csi-resizer and volumemodifier timeouts")
 		f.BoolVar(&o.DeprecatedMetrics, "deprecated-metrics", false, "DEPRECATED: To enable deprecated metrics. This parameter is only for backward compatibility and may be removed in a future release.")
 		f.BoolVar(&o.EnableNodeLocalVolumes, "enable-node-local-volumes", false, "Enable support for node-local volumes that use pre-attached EBS volumes.")
+		f.BoolVar(&o.CloneViaSnapshot, "clone-via-snapshot", false, "Clone volumes by creating a temporary snapshot of the source volume, restoring from it, and deleting the snapshot, instead of using EC2 CopyVolumes.")
+		f.IntVar(&o.MaxQueuedRequests, "max-queued-requests", 0, "Maximum number of requests waiting on batched or coalesced EC2 calls before new controller RPCs are rejected with ResourceExhausted and a retry delay. 0 means no limit.")
+		f.BoolVar(&o.CorrelationIDUserAgent, "correlation-id-user-agent", false, "Append the correlation ID of the CSI request that caused an EC2 call to its user agent, so that the call can be matched with driver logs in CloudTrail.")
+		f.BoolVar(&o.SubsystemUserAgent, "subsystem-user-agent", false, "Append the driver subsystem that caused an EC2 call (provision, attach, snapshot or modify) to its user agent, so that API usage can be attributed to each subsystem in CloudTrail.")
+		f.Var(&namespaceQuotasFile{quotas: &o.NamespaceQuotas}, "namespace-quotas-file", "Path to a YAML or JSON file with per-namespace limits on the total size and IOPS of provisioned volumes, in total and per volume type. CreateVolume requests that exceed them are rejected. Requires the external-provisioner to run with --extra-create-metadata.")
+		f.IntVar(&o.SnapshotsPerRegionQuota, "snapshots-per-region-quota", 0, "Snapshots per Region quota of the account. If set, CreateSnapshot fails early with ResourceExhausted when the account already owns this many snapshots in the region. Counting the snapshots of the account is expensive, the count is cached and refreshed hourly. 0 disables the check.")
+		f.IntVar(&o.MaxConcurrentSnapshots, "max-concurrent-snapshots", 0, "Maximum number of CreateSnapshot calls to EC2 in flight at once. Requests over the limit wait in a queue served fairly across VolumeSnapshot namespaces, so that large backup jobs cannot exhaust the EC2 API quota. Requires the external-snapshotter to run with --extra-create-metadata to tell namespaces apart. 0 means no limit.")
+		f.IntVar(&o.MaxQueuedSnapshots, "max-queued-snapshots", 0, "Maximum number of CreateSnapshot requests waiting for --max-concurrent-snapshots before new requests are rejected with ResourceExhausted and a retry delay. 0 means no limit.")
+		f.DurationVar(&o.VolumeStatusPollInterval, "volume-status-poll-interval", 0, "If set, the leader controller polls EC2 DescribeVolumeStatus for the volumes attached by the driver at this interval, and reports impaired volumes and volumes whose I/O is disabled with events on their PV and node and with metrics. 0 disables polling.")
+		f.DurationVar(&o.VolumeInitializationPollInterval, "volume-initialization-poll-interval", 0, "If set, the controller polls EC2 DescribeVolumeStatus at this interval for the volumes it restored from a snapshot without fast snapshot restore, and reports the progress of their initialization with events on their PVC and with metrics until they are initialized. Requires the external-provisioner to run with --extra-create-metadata. 0 disables polling.")
+		f.BoolVar(&o.AutoEnableVolumeIO, "auto-enable-volume-io", false, "Re-enable the I/O of attached volumes whose I/O EBS disabled because their data is potentially inconsistent. Requires --volume-status-poll-interval and the ec2:EnableVolumeIO permission.")
+		f.Float64Var(&o.APIBudgetRate, "api-budget-rate", 0, "Rate of mutating EC2 calls per second split between the API budget classes of --api-budget-weights. The calls made for the volumes of a StorageClass wait for the share of the class selected by its apiBudgetClass parameter. Should be set below the EC2 request rate limits of the account. 0 disables API budgets.")
+		f.StringToIntVar(&o.APIBudgetWeights, "api-budget-weights", nil, "Relative weights of the API budget classes, as a comma separated list like 'database=10,batch=1'. Calls without a class, or with an unknown class, use the 'default' class, whose weight is 1 unless set. Requires --api-budget-rate.")
+		f.StringToIntVar(&o.ZoneWeights, "zone-weights", nil, "Relative weights of availability zones, by zone name or zone ID, as a comma separated list like 'us-east-1a=3,use1-az4=0'. Volumes whose accessibility requirements allow several zones and whose PVC has no selected node are spread across these zones in proportion to their weight, instead of being created in the first preferred zone. Zones not listed have weight 1, zones with weight 0 are avoided. Requires the external-provisioner to run with --extra-create-metadata.")
+		f.DurationVar(&o.ZoneFailureWindow, "zone-failure-window", 0, "If set, each CreateVolume failure caused by an availability zone, such as InsufficientVolumeCapacity, divides the weight of the zone for this period, steering the next volumes to other zones. Applies to the same volumes as --zone-weights. 0 disables it.")
+		f.BoolVar(&o.CapacityAwareZoneSelection, "capacity-aware-zone-selection", false, "Create volumes whose accessibility requirements allow several zones and whose PVC has no selected node in the allowed zone with the most EBS capacity left: the zone where the account uses the least storage of the volume type, avoiding zones where EC2 recently ran out of capacity. --zone-weights then only break ties and zones with weight 0 are still avoided. Requires the external-provisioner to run with --extra-create-metadata.")
+		f.BoolVar(&o.VolumeTagPolicies, "volume-tag-policies", false, "Watch VolumeTagPolicy and ClusterVolumeTagPolicy objects and tag the volumes and snapshots of the PVCs they select with their tags, which override the tags of StorageClasses, VolumeSnapshotClasses and --extra-tags. Volumes and snapshots are not created until the policies are synced. Requires their CustomResourceDefinitions and the external-provisioner and external-snapshotter to run with --extra-create-metadata.")
+		f.DurationVar(&o.QuotaExceededBackoff, "quota-exceeded-backoff", 0, "If set, after EC2 reports that the account exceeded its storage or IOPS quota of a volume type, CreateVolume requests for volumes of that type fail with ResourceExhausted without calling EC2 for this long. The delay doubles with each consecutive quota failure, up to 16 times this value, and is reset once a volume of the type is created. 0 disables it.")
+		f.StringVar(&o.PolicyWebhookURL, "policy-webhook-url", "", "HTTP or HTTPS endpoint that the controller POSTs a CreateVolumeReview with the parameters, size and PVC labels and annotations of each CreateVolume request to before calling EC2. The endpoint can deny the request or replace its parameters. Requires the external-provisioner to run with --extra-create-metadata to send PVC metadata. Empty disables the policy webhook.")
+		f.DurationVar(&o.PolicyWebhookTimeout, "policy-webhook-timeout", 10*time.Second, "Timeout of each call to --policy-webhook-url.")
+		f.StringVar(&o.PolicyWebhookFailurePolicy, "policy-webhook-failure-policy", PolicyWebhookFailurePolicyFail, "What to do with CreateVolume requests that --policy-webhook-url cannot review because it times out, fails or returns an invalid response: 'Fail' them with Unavailable so that they are retried, or 'Ignore' the webhook and create the volume with its own parameters.")
+		f.Var(&policyWebhookCAFile{pool: &o.PolicyWebhookCAs}, "policy-webhook-ca-file", "Path to a PEM file with the certificate authorities that verify the certificate of an HTTPS --policy-webhook-url, instead of the system roots.")
+		f.BoolVar(&o.EstimateCosts, "estimate-costs", false, "Record the approximate monthly cost in US dollars of each volume and snapshot the controller creates, estimated from its size, IOPS and throughput, in the aws_ebs_csi_estimated_monthly_cost_dollars_total metric by namespace, StorageClass and volume type. Uses the us-east-1 list prices unless --cost-price-table-file overrides them. Requires the external-provisioner and external-snapshotter to run with --extra-create-metadata to tell namespaces apart.")
+		f.Var(&priceTableFile{table: &o.CostPriceTable}, "cost-price-table-file", "Path to a YAML or JSON file with the monthly prices in US dollars that --estimate-costs uses, which replace the built-in prices of the volume types it lists and of snapshots if it sets them.")
+		f.StringToIntVar(&o.StorageQuotas, "storage-quotas", nil, "EBS storage quotas of the account in TiB, by volume type, as a comma separated list like 'gp3=50,io2=20'. If set, the controller implements GetCapacity and reports the storage left under the quota of the volume type of each StorageClass, so that the external-provisioner can publish CSIStorageCapacity objects when it runs with --enable-capacity. The storage used is counted with DescribeVolumes and cached for a minute.")
+		f.IntVar(&o.VolumesPerRegionQuota, "volumes-per-region-quota", 0, "Number of volumes the account may own in the region. If set, the controller implements GetCapacity and reports no capacity once the account owns this many volumes. 0 disables the check.")
+		f.Int32Var(&o.DefaultGP3IOPS, "default-gp3-iops", 0, "IOPS of the gp3 volumes whose StorageClass sets neither iops nor iopsPerGB, instead of the gp3 baseline of 3000. Takes precedence over the IOPS defaults of --default-volume-parameters, and is capped by the maximum IOPS per GiB of small volumes. 0 disables it.")
+		f.Int32Var(&o.DefaultGP3Throughput, "default-gp3-throughput", 0, "Throughput in MiB/s of the gp3 volumes whose StorageClass sets neither throughput nor throughputPerGiB, instead of the gp3 baseline of 125. Takes precedence over the throughput defaults of --default-volume-parameters. Must be at most a quarter of the --default-gp3-iops, or of 3000 without it. 0 disables it.")
+		f.Var(cliflag.NewMapStringString(&o.DefaultVolumeParameters), "default-volume-parameters", "Default StorageClass parameters of the volumes created by the controller, as a comma separated list like 'encrypted=true,throughput=250'. A default is applied unless the StorageClass sets the parameter, or a parameter that conflicts with it such as iopsPerGB for iops, and IOPS and throughput defaults only apply to the volume types that support them.")
+		f.BoolVar(&o.ForceEncryption, "force-encryption", false, "Encrypt every volume created by the controller, even when its StorageClass does not set encrypted=true. CreateVolume requests whose StorageClass sets encrypted to another value, and clones of unencrypted volumes, are rejected.")
+		f.StringVar(&o.ForceEncryptionKMSKeyID, "force-encryption-kms-key-id", "", "KMS key, as a key ID, key ARN, alias name or alias ARN, of the volumes encrypted by --force-encryption whose StorageClass does not set kmsKeyId. If empty, the default EBS encryption key of the account is used.")
+		f.Var(&retryPolicyFile{policy: &o.RetryPolicy}, "retry-policy-file", "Path to a YAML or JSON file that overrides how the controller polls volume creation, attachment and modification, retries the deletion of volumes and snapshots that are still in use, and polls the snapshots taken to clone volumes.")
+		f.IntVar(&o.AttachmentHistoryLength, "attachment-history-length", 10, "Number of attach and detach transitions, with their time, node, device, AWS request ID and error, kept in memory for each volume attached or detached in the last 24 hours. They are served as JSON on "+cloud.AttachmentHistoryPath+" of --http-endpoint. 0 disables the history.")
+		f.BoolVar(&o.AttachmentHistoryLog, "attachment-history-log", false, "Also log each attach and detach transition kept in the attachment history, so that it can be exported with the driver logs.")
+		f.StringVar(&o.ClientTokenStrategy, "client-token-strategy", cloud.ClientTokenStrategyVolumeName, "How the idempotency token of CreateVolume is derived. 'volume-name' hashes the volume name only, so a retry with different parameters fails instead of creating a second volume. 'request-hash' also hashes the parameters of the request, so a retry with different parameters does not fail while no volume exists; a volume created by a previous attempt is returned instead of creating a second one if its parameters match, and the retry fails with AlreadyExists otherwise.")
+		f.StringVar(&o.ClientTokenConfigMap, "client-token-configmap", "", "Name of a ConfigMap in which the controller persists the client tokens of CreateVolume it retired after IdempotentParameterMismatch errors, so that a restarted or newly elected controller creates each volume with its latest client token. Entries are kept for 24 hours. Empty keeps them in memory only.")
+		f.StringVar(&o.ClientTokenConfigMapNamespace, "client-token-configmap-namespace", "kube-system", "Namespace of the ConfigMap passed to --client-token-configmap.")
+		f.IntVar(&o.ControllerShards, "controller-shards", 0, "Number of active controller replicas that split the expansion and modification of volumes and the background reconcilers between them by volume ID hash. Each replica only handles the volumes of the shard passed to --controller-shard-index. 0 or 1 disables sharding.")
+		f.IntVar(&o.ControllerShardIndex, "controller-shard-index", 0, "Shard handled by this controller replica, between 0 and --controller-shards minus 1.")
+		f.StringSliceVar(&o.ControllerShardPeers, "controller-shard-peers", nil, "Comma separated list of the addresses of the controller shards, in shard order, such as 'ebs-csi-controller-0.ebs-csi-controller:9809,ebs-csi-controller-1.ebs-csi-controller:9809'. Requests for volumes handled by another shard are forwarded to it instead of failing with Unavailable. Requires --controller-shard-peer-address.")
+		f.StringVar(&o.ControllerShardPeerAddress, "controller-shard-peer-address", "", "TCP address on which this controller replica serves the requests forwarded by the other shards, such as ':9809'. Only volume expansion and modification requests for the volumes of this shard are served, to the peers authenticated with mutual TLS. Requires --controller-shard-peer-tls-cert-file, --controller-shard-peer-tls-key-file and --controller-shard-peer-tls-ca-file.")
+		f.StringVar(&o.ControllerShardPeerTLSCertFile, "controller-shard-peer-tls-cert-file", "", "Path to the PEM certificate that this controller replica presents to the other shards, both when serving and when forwarding requests. It must be valid for the host names of --controller-shard-peers.")
+		f.StringVar(&o.ControllerShardPeerTLSKeyFile, "controller-shard-peer-tls-key-file", "", "Path to the PEM private key of --controller-shard-peer-tls-cert-file.")
+		f.StringVar(&o.ControllerShardPeerTLSCAFile, "controller-shard-peer-tls-ca-file", "", "Path to a PEM file with the certificate authorities that the certificates of the other shards must be signed by. Connections from peers without such a certificate are rejected.")
+		f.StringVar(&o.HandoffLease, "handoff-lease", "", "Name of a Lease in which the controller records in-flight volume deletions and fast snapshot restore enablements, so that the replica elected leader after a failover resumes them immediately instead of waiting for the sidecars to retry. Empty disables the handoff.")
+		f.StringVar(&o.HandoffLeaseNamespace, "handoff-lease-namespace", "", "Namespace of the Lease passed to --handoff-lease. Empty uses the namespace of the controller Pod.")
+		f.IntVar(&o.DegradedThrottleThreshold, "degraded-throttle-threshold", 0, "Number of throttled EC2 calls per minute above which, when sustained for 3 minutes, the controller announces degraded provisioning in the ConfigMap passed to --degraded-status-configmap and with a Warning event, until throttling stays below it for 3 minutes. 0 disables the announcements.")
+		f.StringVar(&o.DegradedStatusConfigMap, "degraded-status-configmap", "ebs-csi-controller-status", "Name of the ConfigMap in which the controller announces degraded provisioning. Requires --degraded-throttle-threshold.")
+		f.StringVar(&o.DegradedStatusNamespace, "degraded-status-namespace", "kube-system", "Namespace of the ConfigMap passed to --degraded-status-configmap.")
+		f.StringSliceVar(&o.DeletionProtectedNamespaces, "deletion-protected-namespaces", nil, "Comma separated list of namespaces whose volumes are only deleted once their PV is annotated with "+ConfirmDeletionAnnotation+"=true, even when their reclaim policy is Delete. Until then DeleteVolume fails with FailedPrecondition and the volume and its PV are kept. '*' protects every namespace.")
+		f.BoolVar(&o.CheckEBSBandwidth, "check-ebs-bandwidth", false, "After each attachment, compare the EBS-optimized bandwidth of the instance with the maximum throughput of its attached volumes, and log a warning and increment aws_ebs_csi_ebs_bandwidth_oversubscribed_total when the volumes can exceed it. Costs a DescribeVolumes call per attachment.")
+		f.DurationVar(&o.SoftDeleteRetention, "soft-delete-retention", 0, "If set, DeleteVolume tags volumes for deletion after this period instead of deleting them immediately, so that accidentally deleted volumes can be recovered by removing the tag. 0 disables soft-delete.")
+	}
+	// Metadata labeler options
+	if o.Mode == MetadataLabelerMode {
+		f.IntVar(&o.NodeAPIPort, "node-api-port", 0, "Port of the node API of the node plugin, set with its --node-api-endpoint, from which the volumes attached to each node are counted. Nodes whose node API cannot be reached, or cannot list the attached volumes, are counted from the block device mappings of their instance in EC2. 0 only uses EC2.")
+		f.StringVar(&o.NodePluginSelector, "node-plugin-selector", "app=ebs-csi-node", "Label selector of the node plugin Pods whose node API is used by --node-api-port.")
+	}
+	// Adopt options
+	if o.Mode == AdoptMode {
+		f.StringVar(&o.KubernetesClusterID, "k8s-tag-cluster-id", "", "ID of the Kubernetes cluster used for tagging adopted EBS volumes (optional). Should match the value passed to the controller.")
+		f.StringSliceVar(&o.AdoptVolumeIDs, "volume-ids", nil, "Comma separated list of IDs of existing volumes to adopt.")
+		f.Var(cliflag.NewMapStringString(&o.AdoptTagFilter), "tag-filter", "Adopt existing volumes that carry all of these tags. It is a comma separated list of key value pairs like '<key1>=<value1>,<key2>=<value2>'")
+		f.StringVar(&o.AdoptNamespace, "namespace", "default", "Namespace of the generated PersistentVolumeClaims.")
+		f.StringVar(&o.AdoptStorageClass, "storage-class", "", "StorageClass name set on the generated PersistentVolumes and PersistentVolumeClaims. Empty means no StorageClass.")
+		f.StringVar(&o.AdoptFSType, "fs-type", FSTypeExt4, "Filesystem type set on the generated PersistentVolumes. Must match the filesystem already on the volumes.")
+		f.BoolVar(&o.AdoptDryRun, "dry-run", false, "Print the manifests without tagging the volumes.")
+	}
+	// Migrate options
+	if o.Mode == MigrateMode {
+		f.StringVar(&o.KubernetesClusterID, "k8s-tag-cluster-id", "", "ID of the Kubernetes cluster used for tagging migrated EBS volumes (optional). Should match the value passed to the controller.")
+		f.StringVar(&o.MigratePVC, "pvc", "", "Namespace and name of the PersistentVolumeClaim whose volume is migrated, like '<namespace>/<name>'.")
+		f.StringVar(&o.MigrateTargetZone, "target-zone", "", "Availability zone the volume is migrated to.")
 	}
 	// Node options
 	if o.Mode == AllMode || o.Mode == NodeMode {
 		f.Int64Var(&o.VolumeAttachLimit, "volume-attach-limit", -1, "Value for the maximum number of volumes attachable per node. If specified, the limit applies to all nodes and overrides --reserved-volume-attachments. If not specified, the value is approximated from the instance type.")
+		f.Var(&reservedAttachments{reserved: &o.ReservedAttachments}, "reserved-attachments", "Attachment slots reserved for each class of device, as a comma separated list like 'eni=4,nvme-local=2'. Classes are ebs (EBS volumes not managed by the driver, replaces --reserved-volume-attachments), eni (secondary network interfaces, replaces the number attached at boot), nvme-local (NVMe instance store volumes) and accelerator (GPUs and other accelerators). nvme-local and accelerator are only reserved on instance types whose attachment limit is shared with other devices. Not used when --volume-attach-limit is specified.")
 		f.IntVar(&o.ReservedVolumeAttachments, "reserved-volume-attachments", -1, "Number of volume attachments reserved for system use. Not used when --volume-attach-limit is specified. The total amount of volume attachments for a node is computed as: <nr. of attachments for corresponding instance type> - <number of NICs, if relevant to the instance type> - <reserved-volume-attachments value>. When -1, the amount of reserved attachments is loaded from instance metadata that captured state at node boot and may include not only system disks but also CSI volumes.")
 		f.BoolVar(&o.WindowsHostProcess, "windows-host-process", false, "ALPHA: Indicates whether the driver is running in a Windows privileged container")
 		f.BoolVar(&o.LegacyXFSProgs, "legacy-xfs", false, "Warning: This option will be removed in a future version of EBS CSI Driver. Formats XFS volumes with `bigtime=0,inobtcount=0,reflink=0,nrext64=0`, so that they can be mounted onto nodes with linux kernel ≤ v5.4. Volumes formatted with this option may experience issues after 2038, and will be unable to use some XFS features (for example, reflinks).")
 		f.StringVar(&o.CsiMountPointPath, "csi-mount-point-prefix", "", "A prefix of the mountpoints of all CSI-managed volumes. If this value is non-empty, all volumes mounted to a path beginning with the provided value are assumed to be CSI volumes owned by the EBS CSI Driver and safe to treat as such (for example, by exposing volume metrics).")
+		f.StringVar(&o.MountNamespace, "mount-namespace", string(mounter.MountNamespaceContainer), "Mount namespace in which volumes are formatted and mounted on Linux nodes. 'container' uses the namespace of the driver container. 'host' uses the namespace of the host through nsenter, for distros where mounts made in the container leak or do not propagate to the host. 'auto' uses the host namespace only when the host root filesystem is available at --host-rootfs-path and the kubelet directory is not a shared mount in the driver container.")
+		f.BoolVar(&o.HardenedMountDefaults, "hardened-mount-defaults", false, "Mount filesystem volumes with nodev and nosuid, unless the StorageClass sets the dev or suid mount option. The mount options applied to each volume are logged.")
+		f.BoolVar(&o.HardenedMountNoExec, "hardened-mount-noexec", false, "With --hardened-mount-defaults, also mount filesystem volumes with noexec, unless the StorageClass sets the exec mount option. Only enable if no workload runs binaries or scripts stored on its volumes.")
+		f.StringVar(&o.HostRootfsPath, "host-rootfs-path", mounter.DefaultHostRootfsPath, "Path where the root filesystem of the host is mounted in the driver container. Used to enter the host mount namespace with --mount-namespace.")
+		f.StringVar(&o.UdevSettleStrategy, "udev-settle-strategy", string(mounter.UdevSettleStrategyPoll), "How Linux nodes wait for the device of an attached volume to show up. 'poll' looks the device up again every --udev-poll-interval. 'udevadm' runs `udevadm settle` between lookups. 'none' fails if the device is not found on the first lookup.")
+		f.DurationVar(&o.UdevSettleTimeout, "udev-settle-timeout", mounter.DefaultUdevSettleTimeout, "How long Linux nodes wait for the device of an attached volume to show up before failing the request.")
+		f.DurationVar(&o.UdevPollInterval, "udev-poll-interval", mounter.DefaultUdevPollInterval, "Minimum time between two lookups of the device of an attached volume on Linux nodes.")
+		f.DurationVar(&o.AllocatableReconcileInterval, "allocatable-reconcile-interval", 0, "If set, the node compares the allocatable count of the driver in its CSINode with its volume limit at this interval, and registers the driver again when they diverge, for example after network interfaces were attached. Requires the registration directory of the kubelet at --registration-dir. 0 disables the comparison.")
+		f.StringVar(&o.RegistrationDir, "registration-dir", DefaultRegistrationDir, "Directory where the kubelet watches the registration sockets of plugins. Used by --allocatable-reconcile-interval to register the driver again.")
+		f.IntVar(&o.VolumeWarmUpRate, "volume-warm-up-rate", 0, "If set, the node reads the whole device of the filesystem volumes restored from a snapshot without fast snapshot restore in the background once staged, at this rate in MiB/s, so that their blocks are downloaded from the snapshot before the workload reads them. 0 disables the warm-up.")
+		f.StringVar(&o.NodeAPIEndpoint, "node-api-endpoint", "", "TCP network address, for example :3310, where the node plugin serves the volumes attached to its node as JSON on "+nodeapi.VolumesPath+", for the metadata labeler and other tooling. The node API is not authenticated: anyone who can reach the address can list the volumes of the node. Empty disables the node API.")
+		f.IntVar(&o.MaxConcurrentNodeOperations, "max-concurrent-node-operations", 0, "Maximum number of NodeStageVolume and NodePublishVolume calls running at once, so that many pods starting together do not format and mount as many volumes at once. Calls over the limit wait in arrival order, until the kubelet cancels them. Calls on the same volume or device always run one at a time. 0 means no limit.")
 	}
 }
 
@@ -147,6 +469,175 @@ func (o *Options) Validate() error {
 		if o.VolumeAttachLimit != -1 && o.ReservedVolumeAttachments != -1 {
 			return errors.New("only one of --volume-attach-limit and --reserved-volume-attachments may be specified")
 		}
+		if len(o.ReservedAttachments) > 0 && o.VolumeAttachLimit != -1 {
+			return errors.New("only one of --volume-attach-limit and --reserved-attachments may be specified")
+		}
+		if _, ok := o.ReservedAttachments[AttachmentClassEBS]; ok && o.ReservedVolumeAttachments != -1 {
+			return errors.New("only one of --reserved-volume-attachments and --reserved-attachments=ebs=<n> may be specified")
+		}
+		switch mounter.MountNamespace(o.MountNamespace) {
+		case "", mounter.MountNamespaceContainer, mounter.MountNamespaceHost, mounter.MountNamespaceAuto:
+		default:
+			return fmt.Errorf("invalid --mount-namespace %q, must be one of container, host, or auto", o.MountNamespace)
+		}
+		if o.HardenedMountNoExec && !o.HardenedMountDefaults {
+			return errors.New("--hardened-mount-noexec requires --hardened-mount-defaults")
+		}
+		switch mounter.UdevSettleStrategy(o.UdevSettleStrategy) {
+		case "", mounter.UdevSettleStrategyPoll, mounter.UdevSettleStrategyUdevadm, mounter.UdevSettleStrategyNone:
+		default:
+			return fmt.Errorf("invalid --udev-settle-strategy %q, must be one of poll, udevadm, or none", o.UdevSettleStrategy)
+		}
+		if o.UdevSettleTimeout < 0 || o.UdevPollInterval < 0 {
+			return errors.New("--udev-settle-timeout and --udev-poll-interval must not be negative")
+		}
+		if o.AllocatableReconcileInterval < 0 {
+			return errors.New("--allocatable-reconcile-interval must not be negative")
+		}
+		if o.VolumeWarmUpRate < 0 {
+			return errors.New("--volume-warm-up-rate must not be negative")
+		}
+		if o.MaxConcurrentNodeOperations < 0 {
+			return errors.New("--max-concurrent-node-operations must not be negative")
+		}
+	}
+
+	if o.AutoEnableVolumeIO && o.VolumeStatusPollInterval <= 0 {
+		return errors.New("--auto-enable-volume-io requires --volume-status-poll-interval")
+	}
+
+	if o.BatchMinDelay < 0 || o.BatchMaxDelay < 0 {
+		return errors.New("--batch-min-delay and --batch-max-delay must not be negative")
+	}
+	if o.BatchMinDelay > 0 && o.BatchMaxDelay > 0 && o.BatchMinDelay > o.BatchMaxDelay {
+		return fmt.Errorf("invalid --batch-min-delay %v, must not be greater than --batch-max-delay %v", o.BatchMinDelay, o.BatchMaxDelay)
+	}
+
+	if o.APIBudgetRate < 0 {
+		return fmt.Errorf("invalid --api-budget-rate %v, must not be negative", o.APIBudgetRate)
+	}
+	if len(o.APIBudgetWeights) > 0 && o.APIBudgetRate == 0 {
+		return errors.New("--api-budget-weights requires --api-budget-rate")
+	}
+	for class, weight := range o.APIBudgetWeights {
+		if weight <= 0 {
+			return fmt.Errorf("invalid --api-budget-weights weight %d of class %q, must be positive", weight, class)
+		}
+	}
+
+	for zone, weight := range o.ZoneWeights {
+		if weight < 0 {
+			return fmt.Errorf("invalid --zone-weights weight %d of zone %q, must not be negative", weight, zone)
+		}
+	}
+	if o.ZoneFailureWindow < 0 {
+		return errors.New("--zone-failure-window must not be negative")
+	}
+	if o.PVCMetadataCacheMaxStaleness < 0 {
+		return errors.New("--pvc-metadata-cache-max-staleness must not be negative")
+	}
+
+	for volumeType, quota := range o.StorageQuotas {
+		if !slices.Contains(cloud.ValidVolumeTypes, volumeType) {
+			return fmt.Errorf("invalid --storage-quotas volume type %q, must be one of %v", volumeType, cloud.ValidVolumeTypes)
+		}
+		if quota <= 0 {
+			return fmt.Errorf("invalid --storage-quotas quota %d of volume type %q, must be positive", quota, volumeType)
+		}
+	}
+
+	if err := validateDefaultVolumeParameters(o.DefaultVolumeParameters); err != nil {
+		return fmt.Errorf("invalid --default-volume-parameters: %w", err)
+	}
+	if o.DefaultGP3IOPS != 0 && o.DefaultGP3IOPS < gp3BaselineIOPS {
+		return fmt.Errorf("invalid --default-gp3-iops %d, must be 0 or at least the gp3 baseline of %d", o.DefaultGP3IOPS, gp3BaselineIOPS)
+	}
+	if o.DefaultGP3Throughput != 0 && (o.DefaultGP3Throughput < gp3MinThroughput || o.DefaultGP3Throughput > gp3MaxThroughput) {
+		return fmt.Errorf("invalid --default-gp3-throughput %d, must be 0 or between %d and %d", o.DefaultGP3Throughput, gp3MinThroughput, gp3MaxThroughput)
+	}
+	if iops := max(o.DefaultGP3IOPS, gp3BaselineIOPS); o.DefaultGP3Throughput > iops/gp3IOPSPerThroughput {
+		return fmt.Errorf("invalid --default-gp3-throughput %d, gp3 volumes with %d IOPS support at most %d MiB/s", o.DefaultGP3Throughput, iops, iops/gp3IOPSPerThroughput)
+	}
+
+	if o.QuotaExceededBackoff < 0 {
+		return errors.New("--quota-exceeded-backoff must not be negative")
+	}
+
+	if o.PolicyWebhookURL != "" {
+		u, err := url.Parse(o.PolicyWebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid --policy-webhook-url %q, must be an http or https URL", o.PolicyWebhookURL)
+		}
+		if o.PolicyWebhookTimeout <= 0 {
+			return errors.New("--policy-webhook-timeout must be positive")
+		}
+		if o.PolicyWebhookFailurePolicy != PolicyWebhookFailurePolicyFail && o.PolicyWebhookFailurePolicy != PolicyWebhookFailurePolicyIgnore {
+			return fmt.Errorf("invalid --policy-webhook-failure-policy %q, must be %s or %s", o.PolicyWebhookFailurePolicy, PolicyWebhookFailurePolicyFail, PolicyWebhookFailurePolicyIgnore)
+		}
+	}
+
+	if o.CostPriceTable != nil && !o.EstimateCosts {
+		return errors.New("--cost-price-table-file requires --estimate-costs")
+	}
+
+	if o.ForceEncryptionKMSKeyID != "" && !o.ForceEncryption {
+		return errors.New("--force-encryption-kms-key-id requires --force-encryption")
+	}
+
+	if o.DegradedThrottleThreshold < 0 {
+		return fmt.Errorf("invalid --degraded-throttle-threshold %d, must not be negative", o.DegradedThrottleThreshold)
+	}
+	if o.DegradedThrottleThreshold > 0 && o.DegradedStatusConfigMap == "" {
+		return errors.New("--degraded-throttle-threshold requires --degraded-status-configmap")
+	}
+
+	if slices.Contains(o.DeletionProtectedNamespaces, "") {
+		return fmt.Errorf("invalid --deletion-protected-namespaces %q, must not contain empty namespaces", strings.Join(o.DeletionProtectedNamespaces, ","))
+	}
+
+	if o.AttachmentHistoryLength < 0 {
+		return fmt.Errorf("invalid --attachment-history-length %d, must not be negative", o.AttachmentHistoryLength)
+	}
+
+	if o.NameTagFromTemplate {
+		if err := template.Parse(o.NameTagTemplate); err != nil {
+			return fmt.Errorf("invalid --name-tag-template: %w", err)
+		}
+		if err := template.Parse(o.SnapshotNameTagTemplate); err != nil {
+			return fmt.Errorf("invalid --snapshot-name-tag-template: %w", err)
+		}
+	}
+
+	if o.ClientTokenStrategy != "" && !slices.Contains(cloud.ClientTokenStrategies, o.ClientTokenStrategy) {
+		return fmt.Errorf("invalid --client-token-strategy %q, must be one of %s", o.ClientTokenStrategy, strings.Join(cloud.ClientTokenStrategies, ", "))
+	}
+
+	if o.ControllerShards < 0 || o.ControllerShardIndex < 0 || o.ControllerShardIndex >= max(o.ControllerShards, 1) {
+		return fmt.Errorf("invalid --controller-shard-index %d, must be between 0 and --controller-shards minus 1", o.ControllerShardIndex)
+	}
+	if len(o.ControllerShardPeers) > 0 {
+		if len(o.ControllerShardPeers) != o.ControllerShards {
+			return fmt.Errorf("invalid --controller-shard-peers, must have one address per shard, got %d for %d shards", len(o.ControllerShardPeers), o.ControllerShards)
+		}
+		if o.ControllerShardPeerAddress == "" {
+			return errors.New("--controller-shard-peers requires --controller-shard-peer-address")
+		}
+	}
+	if o.ControllerShardPeerAddress != "" && (o.ControllerShardPeerTLSCertFile == "" || o.ControllerShardPeerTLSKeyFile == "" || o.ControllerShardPeerTLSCAFile == "") {
+		return errors.New("--controller-shard-peer-address requires --controller-shard-peer-tls-cert-file, --controller-shard-peer-tls-key-file and --controller-shard-peer-tls-ca-file")
+	}
+
+	if o.Mode == AdoptMode && len(o.AdoptVolumeIDs) == 0 && len(o.AdoptTagFilter) == 0 {
+		return errors.New("one of --volume-ids and --tag-filter MUST be specified in adopt mode")
+	}
+
+	if o.Mode == MigrateMode {
+		if namespace, name, ok := strings.Cut(o.MigratePVC, "/"); !ok || namespace == "" || name == "" {
+			return fmt.Errorf("invalid --pvc %q, must be <namespace>/<name>", o.MigratePVC)
+		}
+		if o.MigrateTargetZone == "" {
+			return errors.New("--target-zone MUST be specified in migrate mode")
+		}
 	}
 
 	if o.MetricsCertFile != "" || o.MetricsKeyFile != "" {
@@ -160,6 +651,10 @@ func (o *Options) Validate() error {
 		}
 	}
 
+	if o.PayloadLogSampleRate < 0 || o.PayloadLogSampleRate > 1 {
+		return fmt.Errorf("invalid --payload-log-sample-rate %v, must be between 0 and 1", o.PayloadLogSampleRate)
+	}
+
 	for i, s := range o.MetadataSources {
 		s = strings.ToLower(strings.TrimSpace(s))
 		switch s {
@@ -172,3 +667,30 @@ func (o *Options) Validate() error {
 
 	return nil
 }
+
+// retryPolicyFile is a flag.Value that loads a cloud.RetryPolicy from a YAML or JSON file when the flag is set.
+type retryPolicyFile struct {
+	path   string
+	policy **cloud.RetryPolicy
+}
+
+func (f *retryPolicyFile) String() string { return f.path }
+
+func (f *retryPolicyFile) Type() string { return "string" }
+
+func (f *retryPolicyFile) Set(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read retry policy file: %w", err)
+	}
+	policy := &cloud.RetryPolicy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return fmt.Errorf("could not parse retry policy file %s: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("invalid retry policy file %s: %w", path, err)
+	}
+	f.path = path
+	*f.policy = policy
+	return nil
+}
@@ -15,11 +15,17 @@
 package driver
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud/metadata"
 	flag "github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestAddFlags(t *testing.T) {
@@ -32,6 +38,9 @@ func TestAddFlags(t *testing.T) {
 	if err := f.Set("endpoint", "custom-endpoint"); err != nil {
 		t.Errorf("error setting endpoint: %v", err)
 	}
+	if err := f.Set("allow-non-loopback-endpoint", "true"); err != nil {
+		t.Errorf("error setting allow-non-loopback-endpoint: %v", err)
+	}
 	if err := f.Set("http-endpoint", ":8080"); err != nil {
 		t.Errorf("error setting http-endpoint: %v", err)
 	}
@@ -80,14 +89,74 @@ func TestAddFlags(t *testing.T) {
 	if err := f.Set("enable-node-local-volumes", "true"); err != nil {
 		t.Errorf("error setting enable-node-local-volumes: %v", err)
 	}
+	if err := f.Set("clone-via-snapshot", "true"); err != nil {
+		t.Errorf("error setting clone-via-snapshot: %v", err)
+	}
+	if err := f.Set("max-queued-requests", "100"); err != nil {
+		t.Errorf("error setting max-queued-requests: %v", err)
+	}
+	if err := f.Set("correlation-id-user-agent", "true"); err != nil {
+		t.Errorf("error setting correlation-id-user-agent: %v", err)
+	}
+	if err := f.Set("subsystem-user-agent", "true"); err != nil {
+		t.Errorf("error setting subsystem-user-agent: %v", err)
+	}
+	if err := f.Set("soft-delete-retention", "72h"); err != nil {
+		t.Errorf("error setting soft-delete-retention: %v", err)
+	}
+	if err := f.Set("snapshots-per-region-quota", "100000"); err != nil {
+		t.Errorf("error setting snapshots-per-region-quota: %v", err)
+	}
+	if err := f.Set("volume-initialization-poll-interval", "5m"); err != nil {
+		t.Errorf("error setting volume-initialization-poll-interval: %v", err)
+	}
+	if err := f.Set("volume-status-poll-interval", "5m"); err != nil {
+		t.Errorf("error setting volume-status-poll-interval: %v", err)
+	}
+	if err := f.Set("auto-enable-volume-io", "true"); err != nil {
+		t.Errorf("error setting auto-enable-volume-io: %v", err)
+	}
+	if err := f.Set("api-budget-rate", "50"); err != nil {
+		t.Errorf("error setting api-budget-rate: %v", err)
+	}
+	if err := f.Set("api-budget-weights", "database=10,batch=1"); err != nil {
+		t.Errorf("error setting api-budget-weights: %v", err)
+	}
+	if err := f.Set("client-token-strategy", "request-hash"); err != nil {
+		t.Errorf("error setting client-token-strategy: %v", err)
+	}
 
 	if err := f.Set("csi-mount-point-prefix", "/var/lib/kubelet"); err != nil {
 		t.Errorf("error setting csi-mount-point-prefix: %v", err)
 	}
+	if err := f.Set("mount-namespace", "host"); err != nil {
+		t.Errorf("error setting mount-namespace: %v", err)
+	}
+	if err := f.Set("host-rootfs-path", "/host"); err != nil {
+		t.Errorf("error setting host-rootfs-path: %v", err)
+	}
+	if err := f.Set("hardened-mount-defaults", "true"); err != nil {
+		t.Errorf("error setting hardened-mount-defaults: %v", err)
+	}
+	if err := f.Set("hardened-mount-noexec", "true"); err != nil {
+		t.Errorf("error setting hardened-mount-noexec: %v", err)
+	}
+	if err := f.Set("udev-settle-strategy", "udevadm"); err != nil {
+		t.Errorf("error setting udev-settle-strategy: %v", err)
+	}
+	if err := f.Set("udev-settle-timeout", "30s"); err != nil {
+		t.Errorf("error setting udev-settle-timeout: %v", err)
+	}
+	if err := f.Set("udev-poll-interval", "1s"); err != nil {
+		t.Errorf("error setting udev-poll-interval: %v", err)
+	}
 
 	if o.Endpoint != "custom-endpoint" {
 		t.Errorf("unexpected Endpoint: got %s, want custom-endpoint", o.Endpoint)
 	}
+	if !o.AllowNonLoopbackEndpoint {
+		t.Error("unexpected AllowNonLoopbackEndpoint: got false, want true")
+	}
 	if o.HTTPEndpoint != ":8080" {
 		t.Errorf("unexpected HTTPEndpoint: got %s, want :8080", o.HTTPEndpoint)
 	}
@@ -127,6 +196,48 @@ func TestAddFlags(t *testing.T) {
 	if !o.EnableNodeLocalVolumes {
 		t.Error("unexpected EnableNodeLocalVolumes: got false, want true")
 	}
+	if !o.CloneViaSnapshot {
+		t.Error("unexpected CloneViaSnapshot: got false, want true")
+	}
+	if o.MaxQueuedRequests != 100 {
+		t.Errorf("unexpected MaxQueuedRequests: got %d, want 100", o.MaxQueuedRequests)
+	}
+	if !o.CorrelationIDUserAgent {
+		t.Error("unexpected CorrelationIDUserAgent: got false, want true")
+	}
+	if !o.SubsystemUserAgent {
+		t.Error("unexpected SubsystemUserAgent: got false, want true")
+	}
+	if o.SoftDeleteRetention != 72*time.Hour {
+		t.Errorf("unexpected SoftDeleteRetention: got %v, want 72h", o.SoftDeleteRetention)
+	}
+	if o.SnapshotsPerRegionQuota != 100000 {
+		t.Errorf("unexpected SnapshotsPerRegionQuota: got %d, want 100000", o.SnapshotsPerRegionQuota)
+	}
+	if o.VolumeInitializationPollInterval != 5*time.Minute {
+		t.Errorf("unexpected VolumeInitializationPollInterval: got %v, want 5m", o.VolumeInitializationPollInterval)
+	}
+	if o.VolumeStatusPollInterval != 5*time.Minute || !o.AutoEnableVolumeIO {
+		t.Errorf("unexpected volume status options: got %v, %t, want 5m, true", o.VolumeStatusPollInterval, o.AutoEnableVolumeIO)
+	}
+	if o.APIBudgetRate != 50 || !reflect.DeepEqual(o.APIBudgetWeights, map[string]int{"database": 10, "batch": 1}) {
+		t.Errorf("unexpected API budget options: got %v, %v, want 50, map[batch:1 database:10]", o.APIBudgetRate, o.APIBudgetWeights)
+	}
+	if o.ClientTokenStrategy != "request-hash" {
+		t.Errorf("unexpected ClientTokenStrategy: got %s, want request-hash", o.ClientTokenStrategy)
+	}
+	if o.MountNamespace != "host" {
+		t.Errorf("unexpected MountNamespace: got %s, want host", o.MountNamespace)
+	}
+	if o.HostRootfsPath != "/host" {
+		t.Errorf("unexpected HostRootfsPath: got %s, want /host", o.HostRootfsPath)
+	}
+	if !o.HardenedMountDefaults || !o.HardenedMountNoExec {
+		t.Error("unexpected HardenedMountDefaults or HardenedMountNoExec: got false, want true")
+	}
+	if o.UdevSettleStrategy != "udevadm" || o.UdevSettleTimeout != 30*time.Second || o.UdevPollInterval != time.Second {
+		t.Errorf("unexpected udev settle options: got %s, %v, %v, want udevadm, 30s, 1s", o.UdevSettleStrategy, o.UdevSettleTimeout, o.UdevPollInterval)
+	}
 }
 
 func TestAddFlagsMetadataLabelerMode(t *testing.T) {
@@ -168,6 +279,438 @@ func TestAddFlagsMetadataLabelerMode(t *testing.T) {
 	}
 }
 
+func TestAddFlagsAdoptMode(t *testing.T) {
+	o := &Options{}
+	o.Mode = AdoptMode
+
+	f := flag.NewFlagSet("test", flag.ExitOnError)
+	o.AddFlags(f)
+
+	if err := f.Set("volume-ids", "vol-1,vol-2"); err != nil {
+		t.Errorf("error setting volume-ids: %v", err)
+	}
+	if len(o.AdoptVolumeIDs) != 2 || o.AdoptVolumeIDs[0] != "vol-1" || o.AdoptVolumeIDs[1] != "vol-2" {
+		t.Errorf("unexpected AdoptVolumeIDs: got %v, want [vol-1 vol-2]", o.AdoptVolumeIDs)
+	}
+	if err := f.Set("tag-filter", "team=storage"); err != nil {
+		t.Errorf("error setting tag-filter: %v", err)
+	}
+	if o.AdoptTagFilter["team"] != "storage" {
+		t.Errorf("unexpected AdoptTagFilter: got %v, want map[team:storage]", o.AdoptTagFilter)
+	}
+	if o.AdoptNamespace != "default" {
+		t.Errorf("unexpected AdoptNamespace: got %s, want default", o.AdoptNamespace)
+	}
+	if o.AdoptFSType != FSTypeExt4 {
+		t.Errorf("unexpected AdoptFSType: got %s, want %s", o.AdoptFSType, FSTypeExt4)
+	}
+
+	// Controller-only flags should NOT be registered for adopt mode
+	controllerOnlyFlags := []string{"extra-tags", "batching", "soft-delete-retention"}
+	for _, name := range controllerOnlyFlags {
+		if fl := f.Lookup(name); fl != nil {
+			t.Errorf("flag --%s should not be registered in AdoptMode", name)
+		}
+	}
+}
+
+func TestValidateAdoptMode(t *testing.T) {
+	o := &Options{Mode: AdoptMode}
+	if err := o.Validate(); err == nil || err.Error() != "one of --volume-ids and --tag-filter MUST be specified in adopt mode" {
+		t.Errorf("Options.Validate() error = %v, want missing volume selection error", err)
+	}
+
+	o.AdoptTagFilter = map[string]string{"team": "storage"}
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateMigrateMode(t *testing.T) {
+	o := &Options{Mode: MigrateMode, MigratePVC: "data", MigrateTargetZone: "us-east-1b"}
+	if err := o.Validate(); err == nil || err.Error() != `invalid --pvc "data", must be <namespace>/<name>` {
+		t.Errorf("Options.Validate() error = %v, want invalid --pvc error", err)
+	}
+
+	o.MigratePVC = "apps/data"
+	o.MigrateTargetZone = ""
+	if err := o.Validate(); err == nil || err.Error() != "--target-zone MUST be specified in migrate mode" {
+		t.Errorf("Options.Validate() error = %v, want missing --target-zone error", err)
+	}
+
+	o.MigrateTargetZone = "us-east-1b"
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidatePayloadLogSampleRate(t *testing.T) {
+	o := &Options{Mode: ControllerMode, PayloadLogSampleRate: 1.5}
+	if err := o.Validate(); err == nil || err.Error() != "invalid --payload-log-sample-rate 1.5, must be between 0 and 1" {
+		t.Errorf("Options.Validate() error = %v, want invalid payload log sample rate error", err)
+	}
+
+	o.PayloadLogSampleRate = 0.01
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateControllerShards(t *testing.T) {
+	o := &Options{Mode: ControllerMode, ControllerShards: 3, ControllerShardIndex: 3}
+	if err := o.Validate(); err == nil || err.Error() != "invalid --controller-shard-index 3, must be between 0 and --controller-shards minus 1" {
+		t.Errorf("Options.Validate() error = %v, want invalid controller shard index error", err)
+	}
+
+	o.ControllerShardIndex = 2
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+
+	o.ControllerShardPeers = []string{"controller-0:9809", "controller-1:9809"}
+	if err := o.Validate(); err == nil || err.Error() != "invalid --controller-shard-peers, must have one address per shard, got 2 for 3 shards" {
+		t.Errorf("Options.Validate() error = %v, want invalid controller shard peers error", err)
+	}
+
+	o.ControllerShardPeers = append(o.ControllerShardPeers, "controller-2:9809")
+	if err := o.Validate(); err == nil || err.Error() != "--controller-shard-peers requires --controller-shard-peer-address" {
+		t.Errorf("Options.Validate() error = %v, want missing peer address error", err)
+	}
+
+	o.ControllerShardPeerAddress = ":9809"
+	if err := o.Validate(); err == nil || err.Error() != "--controller-shard-peer-address requires --controller-shard-peer-tls-cert-file, --controller-shard-peer-tls-key-file and --controller-shard-peer-tls-ca-file" {
+		t.Errorf("Options.Validate() error = %v, want missing peer TLS error", err)
+	}
+
+	o.ControllerShardPeerTLSCertFile = "/etc/ebs-csi/tls.crt"
+	o.ControllerShardPeerTLSKeyFile = "/etc/ebs-csi/tls.key"
+	o.ControllerShardPeerTLSCAFile = "/etc/ebs-csi/ca.crt"
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateMountNamespace(t *testing.T) {
+	o := &Options{Mode: NodeMode, VolumeAttachLimit: -1, ReservedVolumeAttachments: -1, MountNamespace: "auto"}
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+
+	o.MountNamespace = "shared"
+	if err := o.Validate(); err == nil || err.Error() != `invalid --mount-namespace "shared", must be one of container, host, or auto` {
+		t.Errorf("Options.Validate() error = %v, want invalid mount namespace error", err)
+	}
+}
+
+func TestValidateHardenedMountNoExec(t *testing.T) {
+	o := &Options{Mode: NodeMode, VolumeAttachLimit: -1, ReservedVolumeAttachments: -1, HardenedMountNoExec: true}
+	if err := o.Validate(); err == nil || err.Error() != "--hardened-mount-noexec requires --hardened-mount-defaults" {
+		t.Errorf("Options.Validate() error = %v, want missing --hardened-mount-defaults error", err)
+	}
+
+	o.HardenedMountDefaults = true
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateAPIBudget(t *testing.T) {
+	tests := []struct {
+		name        string
+		rate        float64
+		weights     map[string]int
+		expectedErr string
+	}{
+		{
+			name:    "valid",
+			rate:    50,
+			weights: map[string]int{"database": 10, "batch": 1},
+		},
+		{
+			name:        "negative rate",
+			rate:        -1,
+			expectedErr: "invalid --api-budget-rate -1, must not be negative",
+		},
+		{
+			name:        "weights without rate",
+			weights:     map[string]int{"database": 10},
+			expectedErr: "--api-budget-weights requires --api-budget-rate",
+		},
+		{
+			name:        "zero weight",
+			rate:        50,
+			weights:     map[string]int{"batch": 0},
+			expectedErr: `invalid --api-budget-weights weight 0 of class "batch", must be positive`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &Options{Mode: ControllerMode, APIBudgetRate: tc.rate, APIBudgetWeights: tc.weights}
+			err := o.Validate()
+			if (err != nil) != (tc.expectedErr != "") || (err != nil && err.Error() != tc.expectedErr) {
+				t.Errorf("Options.Validate() error = %v, wantErrMsg %v", err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestValidateAutoEnableVolumeIO(t *testing.T) {
+	o := &Options{Mode: ControllerMode, AutoEnableVolumeIO: true}
+	if err := o.Validate(); err == nil || err.Error() != "--auto-enable-volume-io requires --volume-status-poll-interval" {
+		t.Errorf("Options.Validate() error = %v, want missing poll interval error", err)
+	}
+
+	o.VolumeStatusPollInterval = 5 * time.Minute
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateClientTokenStrategy(t *testing.T) {
+	o := &Options{Mode: ControllerMode, ClientTokenStrategy: "random"}
+	if err := o.Validate(); err == nil || err.Error() != `invalid --client-token-strategy "random", must be one of volume-name, request-hash` {
+		t.Errorf("Options.Validate() error = %v, want invalid client token strategy error", err)
+	}
+
+	o.ClientTokenStrategy = "request-hash"
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateZoneWeights(t *testing.T) {
+	o := &Options{Mode: ControllerMode, ZoneWeights: map[string]int{"us-east-1a": -1}}
+	if err := o.Validate(); err == nil || err.Error() != `invalid --zone-weights weight -1 of zone "us-east-1a", must not be negative` {
+		t.Errorf("Options.Validate() error = %v, want negative zone weight error", err)
+	}
+
+	o.ZoneWeights = map[string]int{"us-east-1a": 0}
+	o.ZoneFailureWindow = -time.Minute
+	if err := o.Validate(); err == nil {
+		t.Error("Options.Validate() error = nil, want negative zone failure window error")
+	}
+
+	o.ZoneFailureWindow = time.Minute
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateBatchDelays(t *testing.T) {
+	o := &Options{Mode: ControllerMode, BatchMinDelay: time.Second, BatchMaxDelay: 500 * time.Millisecond}
+	if err := o.Validate(); err == nil || !strings.HasPrefix(err.Error(), "invalid --batch-min-delay") {
+		t.Errorf("Options.Validate() error = %v, want min delay greater than max delay error", err)
+	}
+
+	o.BatchMinDelay = -time.Second
+	if err := o.Validate(); err == nil || err.Error() != "--batch-min-delay and --batch-max-delay must not be negative" {
+		t.Errorf("Options.Validate() error = %v, want negative delay error", err)
+	}
+
+	o.BatchMinDelay = 50 * time.Millisecond
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateStorageQuotas(t *testing.T) {
+	o := &Options{Mode: ControllerMode, StorageQuotas: map[string]int{"gp4": 50}}
+	if err := o.Validate(); err == nil || !strings.HasPrefix(err.Error(), `invalid --storage-quotas volume type "gp4"`) {
+		t.Errorf("Options.Validate() error = %v, want invalid volume type error", err)
+	}
+
+	o.StorageQuotas = map[string]int{"gp3": 0}
+	if err := o.Validate(); err == nil || err.Error() != `invalid --storage-quotas quota 0 of volume type "gp3", must be positive` {
+		t.Errorf("Options.Validate() error = %v, want non-positive quota error", err)
+	}
+
+	o.StorageQuotas = map[string]int{"gp3": 50, "io2": 20}
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateDefaultVolumeParameters(t *testing.T) {
+	o := &Options{Mode: ControllerMode, DefaultVolumeParameters: map[string]string{"csi.storage.k8s.io/fstype": "xfs"}}
+	if err := o.Validate(); err == nil || err.Error() != `invalid --default-volume-parameters: parameter "csi.storage.k8s.io/fstype" is set by the external-provisioner and cannot have a default` {
+		t.Errorf("Options.Validate() error = %v, want external-provisioner parameter error", err)
+	}
+
+	o.DefaultVolumeParameters = map[string]string{"iops": "3000", "iopsPerGB": "10"}
+	if err := o.Validate(); err == nil || !strings.HasPrefix(err.Error(), "invalid --default-volume-parameters: parameters ") {
+		t.Errorf("Options.Validate() error = %v, want conflicting parameters error", err)
+	}
+
+	o.DefaultVolumeParameters = map[string]string{"encrypted": "true", "throughput": "250"}
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateAttachmentHistoryLength(t *testing.T) {
+	o := &Options{Mode: ControllerMode, AttachmentHistoryLength: -1}
+	if err := o.Validate(); err == nil || err.Error() != "invalid --attachment-history-length -1, must not be negative" {
+		t.Errorf("Options.Validate() error = %v, want negative attachment history length error", err)
+	}
+
+	o.AttachmentHistoryLength = 0
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateDegradedThrottleThreshold(t *testing.T) {
+	o := &Options{Mode: ControllerMode, DegradedThrottleThreshold: -1}
+	if err := o.Validate(); err == nil || err.Error() != "invalid --degraded-throttle-threshold -1, must not be negative" {
+		t.Errorf("Options.Validate() error = %v, want negative degraded throttle threshold error", err)
+	}
+
+	o.DegradedThrottleThreshold = 100
+	if err := o.Validate(); err == nil || err.Error() != "--degraded-throttle-threshold requires --degraded-status-configmap" {
+		t.Errorf("Options.Validate() error = %v, want missing degraded status ConfigMap error", err)
+	}
+
+	o.DegradedStatusConfigMap = "ebs-csi-controller-status"
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateForceEncryption(t *testing.T) {
+	o := &Options{Mode: ControllerMode, ForceEncryptionKMSKeyID: "alias/ebs"}
+	if err := o.Validate(); err == nil || err.Error() != "--force-encryption-kms-key-id requires --force-encryption" {
+		t.Errorf("Options.Validate() error = %v, want missing force encryption error", err)
+	}
+
+	o.ForceEncryption = true
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidatePolicyWebhook(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options Options
+		expErr  string
+	}{
+		{
+			name:    "valid",
+			options: Options{PolicyWebhookURL: "https://policy.example.com/review", PolicyWebhookTimeout: time.Second, PolicyWebhookFailurePolicy: PolicyWebhookFailurePolicyIgnore},
+		},
+		{
+			name:    "invalid url",
+			options: Options{PolicyWebhookURL: "policy.example.com", PolicyWebhookTimeout: time.Second, PolicyWebhookFailurePolicy: PolicyWebhookFailurePolicyFail},
+			expErr:  `invalid --policy-webhook-url "policy.example.com", must be an http or https URL`,
+		},
+		{
+			name:    "no timeout",
+			options: Options{PolicyWebhookURL: "http://policy:8080", PolicyWebhookFailurePolicy: PolicyWebhookFailurePolicyFail},
+			expErr:  "--policy-webhook-timeout must be positive",
+		},
+		{
+			name:    "invalid failure policy",
+			options: Options{PolicyWebhookURL: "http://policy:8080", PolicyWebhookTimeout: time.Second, PolicyWebhookFailurePolicy: "Allow"},
+			expErr:  `invalid --policy-webhook-failure-policy "Allow", must be Fail or Ignore`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.options.Mode = ControllerMode
+			err := tc.options.Validate()
+			if tc.expErr == "" {
+				if err != nil {
+					t.Errorf("Options.Validate() unexpected error = %v", err)
+				}
+			} else if err == nil || err.Error() != tc.expErr {
+				t.Errorf("Options.Validate() error = %v, want %q", err, tc.expErr)
+			}
+		})
+	}
+}
+
+func TestValidateCostPriceTable(t *testing.T) {
+	o := &Options{Mode: ControllerMode, CostPriceTable: &PriceTable{}}
+	if err := o.Validate(); err == nil || err.Error() != "--cost-price-table-file requires --estimate-costs" {
+		t.Errorf("Options.Validate() error = %v, want missing estimate costs error", err)
+	}
+
+	o.EstimateCosts = true
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateDefaultGP3Parameters(t *testing.T) {
+	testCases := []struct {
+		name       string
+		iops       int32
+		throughput int32
+		expErr     string
+	}{
+		{name: "disabled"},
+		{name: "IOPS and throughput", iops: 6000, throughput: 500},
+		{name: "throughput within the baseline IOPS", throughput: 750},
+		{name: "IOPS below the baseline", iops: 2000, expErr: "invalid --default-gp3-iops 2000, must be 0 or at least the gp3 baseline of 3000"},
+		{name: "throughput below the baseline", throughput: 100, expErr: "invalid --default-gp3-throughput 100, must be 0 or between 125 and 1000"},
+		{name: "throughput above the IOPS", iops: 3200, throughput: 1000, expErr: "invalid --default-gp3-throughput 1000, gp3 volumes with 3200 IOPS support at most 800 MiB/s"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &Options{Mode: ControllerMode, DefaultGP3IOPS: tc.iops, DefaultGP3Throughput: tc.throughput}
+			err := o.Validate()
+			if tc.expErr == "" && err != nil {
+				t.Errorf("Options.Validate() unexpected error = %v", err)
+			}
+			if tc.expErr != "" && (err == nil || err.Error() != tc.expErr) {
+				t.Errorf("Options.Validate() error = %v, want %q", err, tc.expErr)
+			}
+		})
+	}
+}
+
+func TestValidateDeletionProtectedNamespaces(t *testing.T) {
+	o := &Options{Mode: ControllerMode, DeletionProtectedNamespaces: []string{"payments", ""}}
+	if err := o.Validate(); err == nil || err.Error() != `invalid --deletion-protected-namespaces "payments,", must not contain empty namespaces` {
+		t.Errorf("Options.Validate() error = %v, want empty namespace error", err)
+	}
+
+	o.DeletionProtectedNamespaces = []string{"payments", "billing"}
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateNameTagTemplate(t *testing.T) {
+	o := &Options{Mode: ControllerMode, NameTagFromTemplate: true, NameTagTemplate: "{{ .PVCName }", SnapshotNameTagTemplate: DefaultSnapshotNameTagTemplate}
+	if err := o.Validate(); err == nil || !strings.HasPrefix(err.Error(), "invalid --name-tag-template: ") {
+		t.Errorf("Options.Validate() error = %v, want invalid name tag template error", err)
+	}
+
+	o.NameTagTemplate = DefaultNameTagTemplate
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
+func TestValidateUdevSettle(t *testing.T) {
+	o := &Options{Mode: NodeMode, VolumeAttachLimit: -1, ReservedVolumeAttachments: -1, UdevSettleStrategy: "wait"}
+	if err := o.Validate(); err == nil || err.Error() != `invalid --udev-settle-strategy "wait", must be one of poll, udevadm, or none` {
+		t.Errorf("Options.Validate() error = %v, want invalid udev settle strategy error", err)
+	}
+
+	o.UdevSettleStrategy = "udevadm"
+	o.UdevSettleTimeout = -time.Second
+	if err := o.Validate(); err == nil {
+		t.Error("Options.Validate() error = nil, want negative udev settle timeout error")
+	}
+
+	o.UdevSettleTimeout = time.Second
+	if err := o.Validate(); err != nil {
+		t.Errorf("Options.Validate() unexpected error = %v", err)
+	}
+}
+
 func TestValidateAttachmentLimits(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -227,6 +770,88 @@ func TestValidateAttachmentLimits(t *testing.T) {
 	}
 }
 
+func TestReservedAttachments(t *testing.T) {
+	tests := []struct {
+		name                      string
+		value                     string
+		volumeAttachLimit         int64
+		reservedVolumeAttachments int
+		expected                  map[AttachmentClass]int
+		expectedSetErr            string
+		expectedValidateErr       string
+	}{
+		{
+			name:                      "valid classes",
+			value:                     "eni=4, nvme-local=2",
+			volumeAttachLimit:         -1,
+			reservedVolumeAttachments: -1,
+			expected:                  map[AttachmentClass]int{AttachmentClassENI: 4, AttachmentClassNVMeLocal: 2},
+		},
+		{
+			name:           "unknown class",
+			value:          "gpu=1",
+			expectedSetErr: "invalid attachment class \"gpu\", must be one of [ebs eni nvme-local accelerator]",
+		},
+		{
+			name:           "negative number of slots",
+			value:          "eni=-1",
+			expectedSetErr: "invalid number of reserved eni attachments \"-1\", must be a non-negative integer",
+		},
+		{
+			name:           "missing number of slots",
+			value:          "eni",
+			expectedSetErr: "invalid reserved attachments \"eni\", must be <class>=<number of slots>",
+		},
+		{
+			name:                      "volumeAttachLimit set",
+			value:                     "eni=4",
+			volumeAttachLimit:         10,
+			reservedVolumeAttachments: -1,
+			expectedValidateErr:       "only one of --volume-attach-limit and --reserved-attachments may be specified",
+		},
+		{
+			name:                      "ebs class and reservedVolumeAttachments set",
+			value:                     "ebs=1",
+			volumeAttachLimit:         -1,
+			reservedVolumeAttachments: 2,
+			expectedValidateErr:       "only one of --reserved-volume-attachments and --reserved-attachments=ebs=<n> may be specified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Options{}
+			o.Mode = NodeMode
+			f := flag.NewFlagSet("test", flag.ContinueOnError)
+			o.AddFlags(f)
+
+			err := f.Set("reserved-attachments", tt.value)
+			if tt.expectedSetErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedSetErr) {
+					t.Errorf("error setting reserved-attachments = %v, wantErrMsg %v", err, tt.expectedSetErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error setting reserved-attachments: %v", err)
+			}
+			if tt.expected != nil && !reflect.DeepEqual(o.ReservedAttachments, tt.expected) {
+				t.Errorf("ReservedAttachments = %v, want %v", o.ReservedAttachments, tt.expected)
+			}
+
+			o.VolumeAttachLimit = tt.volumeAttachLimit
+			o.ReservedVolumeAttachments = tt.reservedVolumeAttachments
+			err = o.Validate()
+			if (err != nil) != (tt.expectedValidateErr != "") {
+				t.Errorf("Options.Validate() error = %v, wantErrMsg %v", err, tt.expectedValidateErr)
+			}
+			if err != nil && err.Error() != tt.expectedValidateErr {
+				t.Errorf("Options.Validate() error message = %v, wantErrMsg %v", err.Error(), tt.expectedValidateErr)
+			}
+		})
+	}
+}
+
 func TestValidateMetricsHTTPS(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -341,3 +966,41 @@ func TestValidateMetadataSources(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryPolicyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "retry-policy.yaml")
+	if err := os.WriteFile(path, []byte(`
+volumeAttachment:
+  interval: 2s
+  factor: 1.5
+  steps: 20
+dependencyViolation:
+  steps: 4
+snapshotPollInterval: 30s
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	o := &Options{}
+	f := &retryPolicyFile{policy: &o.RetryPolicy}
+	if err := f.Set(path); err != nil {
+		t.Fatalf("retryPolicyFile.Set() unexpected error = %v", err)
+	}
+	want := &cloud.RetryPolicy{
+		VolumeAttachment:     &cloud.Backoff{Interval: metav1.Duration{Duration: 2 * time.Second}, Factor: 1.5, Steps: 20},
+		DependencyViolation:  &cloud.Backoff{Steps: 4},
+		SnapshotPollInterval: metav1.Duration{Duration: 30 * time.Second},
+	}
+	if !reflect.DeepEqual(o.RetryPolicy, want) {
+		t.Errorf("retryPolicyFile.Set() policy = %+v, want %+v", o.RetryPolicy, want)
+	}
+
+	for _, content := range []string{"volumeAttachment:\n  delay: 2s\n", "volumeAttachment:\n  factor: 0.5\n"} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Set(path); err == nil {
+			t.Errorf("retryPolicyFile.Set() error = nil for %q, want invalid retry policy error", content)
+		}
+	}
+}
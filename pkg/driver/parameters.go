@@ -0,0 +1,342 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// deprecatedParameterReason is the reason of the events emitted on PVCs whose class uses a deprecated parameter.
+	deprecatedParameterReason = "DeprecatedParameter"
+	// invalidParameterValueReason is the reason of the events emitted on PVCs whose class sets a parameter to a
+	// value that is not valid, without --strict-parameters.
+	invalidParameterValueReason = "InvalidParameterValue"
+)
+
+// deprecatedParameter is a StorageClass or VolumeAttributesClass parameter that was renamed or removed.
+type deprecatedParameter struct {
+	key string
+	// replacement is the parameter that replaced key, which key is an alias of. Empty if key has no effect anymore.
+	replacement string
+	// note explains how to update the classes that still set a parameter without replacement.
+	note string
+}
+
+func (p deprecatedParameter) message() string {
+	if p.replacement != "" {
+		return fmt.Sprintf("Parameter %q is deprecated, use %q instead", p.key, p.replacement)
+	}
+	return fmt.Sprintf("Parameter %q is deprecated and has no effect, %s", p.key, p.note)
+}
+
+// invalidParameterValue is a parameter set to a value that is not valid. Such values were historically read as the
+// default value of the parameter instead of being rejected.
+type invalidParameterValue struct {
+	key   string
+	value string
+}
+
+func (p invalidParameterValue) message() string {
+	return fmt.Sprintf("Invalid value %q of parameter %q, must be %q or %q", p.value, p.key, trueStr, "false")
+}
+
+// parameterRegistry lists the deprecated parameters of a kind of class, so that classes written before a parameter
+// was renamed keep working while their owners are told to update them. Request parsers only handle current keys.
+type parameterRegistry struct {
+	// caseInsensitive matches keys in any case, like CreateVolume does for StorageClass parameters.
+	caseInsensitive bool
+	deprecated      []deprecatedParameter
+	// booleans are the parameters whose value must be "true" or "false". Any other value is read as false.
+	booleans []string
+}
+
+var (
+	// storageClassParameters are the parameters of StorageClasses, passed to CreateVolume.
+	storageClassParameters = parameterRegistry{
+		caseInsensitive: true,
+		deprecated: []deprecatedParameter{
+			{key: "fstype", note: `set "csi.storage.k8s.io/fstype" instead`},
+			{key: DeprecatedBlockExpressKey, note: "all io2 volumes are Block Express and share the same IOPS cap"},
+		},
+		booleans: []string{
+			AllowAutoIOPSIncreaseOnModifyKey, AllowAutoIOPSPerGBIncreaseKey, EncryptedKey, Ext4BigAllocKey, Ext4EncryptionSupportKey,
+			Ext4FscryptKey, XfsProjectQuotaKey, BlockAttachUntilInitializedKey, MultiAttachKey, RoundUpToMinimumSizeKey,
+		},
+	}
+	// volumeAttributesClassParameters are the parameters of VolumeAttributesClasses, passed to CreateVolume and
+	// ControllerModifyVolume, and of the PVC annotations passed to ModifyVolumeProperties.
+	volumeAttributesClassParameters = parameterRegistry{
+		deprecated: []deprecatedParameter{
+			{key: DeprecatedModificationKeyVolumeType, replacement: ModificationKeyVolumeType},
+		},
+		booleans: []string{AllowAutoIOPSIncreaseOnModifyKey},
+	}
+)
+
+func (r parameterRegistry) matches(key, registered string) bool {
+	if r.caseInsensitive {
+		return strings.EqualFold(key, registered)
+	}
+	return key == registered
+}
+
+func (r parameterRegistry) lookup(key string) (deprecatedParameter, bool) {
+	for _, p := range r.deprecated {
+		if r.matches(key, p.key) {
+			return p, true
+		}
+	}
+	return deprecatedParameter{}, false
+}
+
+// resolve returns params with deprecated keys renamed to their replacement and removed keys dropped. A replacement
+// set explicitly takes precedence over its deprecated alias.
+func (r parameterRegistry) resolve(params map[string]string) map[string]string {
+	resolved := make(map[string]string, len(params))
+	aliased := map[string]string{}
+	for key, value := range params {
+		p, ok := r.lookup(key)
+		switch {
+		case !ok:
+			resolved[key] = value
+		case p.replacement != "":
+			aliased[p.replacement] = value
+		}
+	}
+	for replacement, value := range aliased {
+		if !r.contains(resolved, replacement) {
+			resolved[replacement] = value
+		}
+	}
+	return resolved
+}
+
+func (r parameterRegistry) contains(params map[string]string, key string) bool {
+	for k := range params {
+		if r.matches(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// deprecatedIn returns the deprecated parameters set in params.
+func (r parameterRegistry) deprecatedIn(params map[string]string) []deprecatedParameter {
+	var deprecated []deprecatedParameter
+	for key := range params {
+		if p, ok := r.lookup(key); ok {
+			deprecated = append(deprecated, p)
+		}
+	}
+	return deprecated
+}
+
+// invalidIn returns the parameters of params set to a value that is not valid.
+func (r parameterRegistry) invalidIn(params map[string]string) []invalidParameterValue {
+	var invalid []invalidParameterValue
+	for key, value := range params {
+		for _, boolean := range r.booleans {
+			if r.matches(key, boolean) && value != trueStr && value != "false" {
+				invalid = append(invalid, invalidParameterValue{key: key, value: value})
+			}
+		}
+	}
+	return invalid
+}
+
+// checkParameterValues rejects the parameters of a request whose value is not valid with --strict-parameters.
+// Otherwise, it reports them and they keep being read as their default value.
+func (d *ControllerService) checkParameterValues(ctx context.Context, request string, registry parameterRegistry, params, pvcParams map[string]string) error {
+	invalid := registry.invalidIn(params)
+	if len(invalid) == 0 {
+		return nil
+	}
+	if d.options.StrictParameters {
+		return status.Error(codes.InvalidArgument, invalid[0].message())
+	}
+	d.parameters.reportInvalid(ctx, request, invalid, pvcParams)
+	return nil
+}
+
+// parameterConflicts are the StorageClass parameters that cannot be set together. A default parameter is not applied
+// to a StorageClass that sets a parameter it conflicts with.
+var parameterConflicts = map[string]string{
+	IopsKey:             IopsPerGBKey,
+	IopsPerGBKey:        IopsKey,
+	ThroughputKey:       ThroughputPerGiBKey,
+	ThroughputPerGiBKey: ThroughputKey,
+}
+
+// parameterVolumeTypes are the volume types supported by the StorageClass parameters that only apply to some volume
+// types. A default parameter is not applied to the volumes of other types.
+var parameterVolumeTypes = map[string][]string{
+	IopsKey:             {cloud.VolumeTypeGP3, cloud.VolumeTypeIO1, cloud.VolumeTypeIO2},
+	IopsPerGBKey:        {cloud.VolumeTypeGP3, cloud.VolumeTypeIO1, cloud.VolumeTypeIO2},
+	ThroughputKey:       {cloud.VolumeTypeGP3},
+	ThroughputPerGiBKey: {cloud.VolumeTypeGP3},
+}
+
+// validateDefaultVolumeParameters checks the parameters of --default-volume-parameters.
+func validateDefaultVolumeParameters(defaults map[string]string) error {
+	for key := range defaults {
+		if strings.HasPrefix(strings.ToLower(key), "csi.storage.k8s.io/") {
+			return fmt.Errorf("parameter %q is set by the external-provisioner and cannot have a default", key)
+		}
+		if conflict, ok := parameterConflicts[strings.ToLower(key)]; ok && storageClassParameters.contains(defaults, conflict) {
+			return fmt.Errorf("parameters %q and %q cannot be set together", key, conflict)
+		}
+	}
+	return nil
+}
+
+// withDefaultParameters returns the StorageClass parameters of a CreateVolume request with the default parameters of
+// --default-volume-parameters, --default-gp3-iops and --default-gp3-throughput that apply to them. A default is
+// applied unless params sets the same parameter or a parameter that conflicts with it, and unless it does not apply
+// to the volume type of params. Parameters are matched in any case, like CreateVolume reads them.
+func (d *ControllerService) withDefaultParameters(params map[string]string) map[string]string {
+	if len(d.options.DefaultVolumeParameters) == 0 && d.options.DefaultGP3IOPS == 0 && d.options.DefaultGP3Throughput == 0 {
+		return params
+	}
+
+	volumeType := cloud.VolumeTypeGP3
+	for _, p := range []map[string]string{d.options.DefaultVolumeParameters, params} {
+		for key, value := range p {
+			if strings.EqualFold(key, VolumeTypeKey) {
+				volumeType = strings.ToLower(value)
+			}
+		}
+	}
+
+	merged := maps.Clone(params)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	for key, value := range d.defaultParameters(volumeType) {
+		lower := strings.ToLower(key)
+		if storageClassParameters.contains(params, key) {
+			continue
+		}
+		if conflict, ok := parameterConflicts[lower]; ok && storageClassParameters.contains(params, conflict) {
+			continue
+		}
+		if volumeTypes, ok := parameterVolumeTypes[lower]; ok && !slices.Contains(volumeTypes, volumeType) {
+			continue
+		}
+		klog.V(4).InfoS("CreateVolume: applying default parameter", "parameter", key, "value", value)
+		metrics.Recorder().IncreaseCount(metrics.DefaultParametersApplied, metrics.DefaultParametersAppliedHelpText, map[string]string{"parameter": lower})
+		merged[key] = value
+	}
+	return merged
+}
+
+// defaultParameters returns the default parameters of the volumes of a type: --default-volume-parameters, whose IOPS
+// and throughput parameters are replaced by --default-gp3-iops and --default-gp3-throughput for gp3 volumes.
+func (d *ControllerService) defaultParameters(volumeType string) map[string]string {
+	if volumeType != cloud.VolumeTypeGP3 || (d.options.DefaultGP3IOPS == 0 && d.options.DefaultGP3Throughput == 0) {
+		return d.options.DefaultVolumeParameters
+	}
+	defaults := maps.Clone(d.options.DefaultVolumeParameters)
+	if defaults == nil {
+		defaults = map[string]string{}
+	}
+	replace := func(key string, value int32) {
+		maps.DeleteFunc(defaults, func(k, _ string) bool {
+			return storageClassParameters.matches(k, key) || storageClassParameters.matches(k, parameterConflicts[key])
+		})
+		defaults[key] = strconv.Itoa(int(value))
+	}
+	if d.options.DefaultGP3IOPS > 0 {
+		replace(IopsKey, d.options.DefaultGP3IOPS)
+	}
+	if d.options.DefaultGP3Throughput > 0 {
+		replace(ThroughputKey, d.options.DefaultGP3Throughput)
+	}
+	return defaults
+}
+
+// parameterReporter emits warning events on the PVCs whose class uses deprecated parameters or invalid values.
+type parameterReporter struct {
+	client   kubernetes.Interface
+	recorder record.EventRecorder
+}
+
+func newParameterReporter(k kubernetes.Interface) *parameterReporter {
+	if k == nil {
+		return nil
+	}
+	return &parameterReporter{client: k, recorder: newEventRecorder(k)}
+}
+
+// reportDeprecated logs and counts the deprecated parameters used by a request, and emits a warning event for each
+// of them on the PVC named by params.
+func (r *parameterReporter) reportDeprecated(ctx context.Context, request string, deprecated []deprecatedParameter, params map[string]string) {
+	if len(deprecated) == 0 {
+		return
+	}
+	messages := make([]string, 0, len(deprecated))
+	for _, p := range deprecated {
+		klog.InfoS("Deprecated parameter used", "request", request, "parameter", p.key, "message", p.message())
+		metrics.Recorder().IncreaseCount(metrics.DeprecatedParameters, metrics.DeprecatedParametersHelpText, map[string]string{"request": request, "parameter": p.key})
+		messages = append(messages, p.message()+". The parameter will be removed in a future release, update the StorageClass or VolumeAttributesClass")
+	}
+	r.event(ctx, deprecatedParameterReason, messages, params)
+}
+
+// reportInvalid logs and counts the parameters of a request set to a value that is not valid, and emits a warning
+// event for each of them on the PVC named by params.
+func (r *parameterReporter) reportInvalid(ctx context.Context, request string, invalid []invalidParameterValue, params map[string]string) {
+	messages := make([]string, 0, len(invalid))
+	for _, p := range invalid {
+		klog.InfoS("Invalid parameter value used", "request", request, "parameter", p.key, "value", p.value)
+		metrics.Recorder().IncreaseCount(metrics.InvalidParameters, metrics.InvalidParametersHelpText, map[string]string{"request": request, "parameter": strings.ToLower(p.key)})
+		messages = append(messages, p.message()+`. It is read as "false", fix the StorageClass or VolumeAttributesClass`)
+	}
+	r.event(ctx, invalidParameterValueReason, messages, params)
+}
+
+// event emits a warning event for each message on the PVC named by params, which is only known when the sidecars
+// run with --extra-create-metadata or --extra-modify-metadata.
+func (r *parameterReporter) event(ctx context.Context, reason string, messages []string, params map[string]string) {
+	namespace, name := params[PVCNamespaceKey], params[PVCNameKey]
+	if r == nil || namespace == "" || name == "" {
+		return
+	}
+	claim, err := r.client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		klog.ErrorS(err, "Could not get PVC to report parameters", "namespace", namespace, "name", name, "reason", reason)
+		return
+	}
+	for _, message := range messages {
+		r.recorder.Event(claim, corev1.EventTypeWarning, reason, message)
+	}
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestParameterRegistryResolve(t *testing.T) {
+	testCases := []struct {
+		name               string
+		registry           parameterRegistry
+		params             map[string]string
+		expectedParams     map[string]string
+		expectedDeprecated []string
+	}{
+		{
+			name:           "no deprecated parameters",
+			registry:       storageClassParameters,
+			params:         map[string]string{VolumeTypeKey: "gp3"},
+			expectedParams: map[string]string{VolumeTypeKey: "gp3"},
+		},
+		{
+			name:               "removed parameter is dropped in any case",
+			registry:           storageClassParameters,
+			params:             map[string]string{VolumeTypeKey: "io2", "blockExpress": "true", "FsType": "xfs"},
+			expectedParams:     map[string]string{VolumeTypeKey: "io2"},
+			expectedDeprecated: []string{DeprecatedBlockExpressKey, "fstype"},
+		},
+		{
+			name:               "alias is renamed",
+			registry:           volumeAttributesClassParameters,
+			params:             map[string]string{DeprecatedModificationKeyVolumeType: "io2", ModificationKeyIOPS: "3000"},
+			expectedParams:     map[string]string{ModificationKeyVolumeType: "io2", ModificationKeyIOPS: "3000"},
+			expectedDeprecated: []string{DeprecatedModificationKeyVolumeType},
+		},
+		{
+			name:               "replacement takes precedence over alias",
+			registry:           volumeAttributesClassParameters,
+			params:             map[string]string{DeprecatedModificationKeyVolumeType: "io2", ModificationKeyVolumeType: "gp3"},
+			expectedParams:     map[string]string{ModificationKeyVolumeType: "gp3"},
+			expectedDeprecated: []string{DeprecatedModificationKeyVolumeType},
+		},
+		{
+			name:           "case-sensitive registry ignores other cases",
+			registry:       volumeAttributesClassParameters,
+			params:         map[string]string{"VolumeType": "io2"},
+			expectedParams: map[string]string{"VolumeType": "io2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedParams, tc.registry.resolve(tc.params))
+			var deprecated []string
+			for _, p := range tc.registry.deprecatedIn(tc.params) {
+				deprecated = append(deprecated, p.key)
+			}
+			assert.ElementsMatch(t, tc.expectedDeprecated, deprecated)
+		})
+	}
+}
+
+func TestParameterReporterDeprecated(t *testing.T) {
+	deprecated := volumeAttributesClassParameters.deprecatedIn(map[string]string{DeprecatedModificationKeyVolumeType: "io2"})
+
+	// Without a Kubernetes client or PVC metadata, deprecated parameters are only logged and counted
+	var nilReporter *parameterReporter
+	nilReporter.reportDeprecated(t.Context(), "CreateVolume", deprecated, map[string]string{PVCNamespaceKey: "default", PVCNameKey: "data"})
+	assert.Nil(t, newParameterReporter(nil))
+
+	client := fake.NewClientset(&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data"}})
+	recorder := record.NewFakeRecorder(10)
+	reporter := &parameterReporter{client: client, recorder: recorder}
+
+	reporter.reportDeprecated(t.Context(), "CreateVolume", deprecated, map[string]string{})
+	assert.Empty(t, recorder.Events)
+
+	reporter.reportDeprecated(t.Context(), "CreateVolume", deprecated, map[string]string{PVCNamespaceKey: "default", PVCNameKey: "data"})
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, `Warning DeprecatedParameter Parameter "volumeType" is deprecated, use "type" instead. The parameter will be removed in a future release, update the StorageClass or VolumeAttributesClass`, <-recorder.Events)
+
+	reporter.reportDeprecated(t.Context(), "CreateVolume", storageClassParameters.deprecatedIn(map[string]string{"blockExpress": "true"}), map[string]string{PVCNamespaceKey: "default", PVCNameKey: "data"})
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, `Warning DeprecatedParameter Parameter "blockexpress" is deprecated and has no effect, all io2 volumes are Block Express and share the same IOPS cap. The parameter will be removed in a future release, update the StorageClass or VolumeAttributesClass`, <-recorder.Events)
+}
+
+func TestCheckParameterValues(t *testing.T) {
+	params := map[string]string{
+		PVCNamespaceKey: "default",
+		PVCNameKey:      "data",
+		VolumeTypeKey:   "io2",
+		"Encrypted":     "True",
+		MultiAttachKey:  "false",
+	}
+	client := fake.NewClientset(&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data"}})
+	recorder := record.NewFakeRecorder(10)
+	d := &ControllerService{options: &Options{}, parameters: &parameterReporter{client: client, recorder: recorder}}
+
+	require.NoError(t, d.checkParameterValues(t.Context(), "CreateVolume", storageClassParameters, params, params))
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, `Warning InvalidParameterValue Invalid value "True" of parameter "Encrypted", must be "true" or "false". It is read as "false", fix the StorageClass or VolumeAttributesClass`, <-recorder.Events)
+
+	d.options.StrictParameters = true
+	err := d.checkParameterValues(t.Context(), "CreateVolume", storageClassParameters, params, params)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, `Invalid value "True" of parameter "Encrypted", must be "true" or "false"`, status.Convert(err).Message())
+	assert.Empty(t, recorder.Events)
+
+	params["Encrypted"] = trueStr
+	require.NoError(t, d.checkParameterValues(t.Context(), "CreateVolume", storageClassParameters, params, params))
+	require.NoError(t, d.checkParameterValues(t.Context(), "ControllerModifyVolume", volumeAttributesClassParameters, map[string]string{AllowAutoIOPSIncreaseOnModifyKey: "false"}, nil))
+	err = d.checkParameterValues(t.Context(), "ControllerModifyVolume", volumeAttributesClassParameters, map[string]string{AllowAutoIOPSIncreaseOnModifyKey: "yes"}, nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestWithDefaultParameters(t *testing.T) {
+	defaults := map[string]string{EncryptedKey: trueStr, "throughput": "250", "iopsPerGB": "50"}
+	testCases := []struct {
+		name           string
+		defaults       map[string]string
+		params         map[string]string
+		expectedParams map[string]string
+	}{
+		{
+			name:           "no defaults",
+			params:         map[string]string{VolumeTypeKey: "gp3"},
+			expectedParams: map[string]string{VolumeTypeKey: "gp3"},
+		},
+		{
+			name:           "defaults apply to the default volume type",
+			defaults:       defaults,
+			params:         nil,
+			expectedParams: map[string]string{EncryptedKey: trueStr, "throughput": "250", "iopsPerGB": "50"},
+		},
+		{
+			name:           "StorageClass parameters take precedence in any case",
+			defaults:       defaults,
+			params:         map[string]string{"Encrypted": "false", "THROUGHPUT": "500"},
+			expectedParams: map[string]string{"Encrypted": "false", "THROUGHPUT": "500", "iopsPerGB": "50"},
+		},
+		{
+			name:           "conflicting parameters are not applied",
+			defaults:       defaults,
+			params:         map[string]string{"iops": "6000", "throughputPerGiB": "0.5"},
+			expectedParams: map[string]string{"iops": "6000", "throughputPerGiB": "0.5", EncryptedKey: trueStr},
+		},
+		{
+			name:           "parameters of other volume types are not applied",
+			defaults:       defaults,
+			params:         map[string]string{VolumeTypeKey: "io2"},
+			expectedParams: map[string]string{VolumeTypeKey: "io2", EncryptedKey: trueStr, "iopsPerGB": "50"},
+		},
+		{
+			name:           "default volume type",
+			defaults:       map[string]string{VolumeTypeKey: "st1", "throughput": "250"},
+			params:         map[string]string{},
+			expectedParams: map[string]string{VolumeTypeKey: "st1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &ControllerService{options: &Options{DefaultVolumeParameters: tc.defaults}}
+			params := maps.Clone(tc.params)
+			assert.Equal(t, tc.expectedParams, d.withDefaultParameters(params))
+			assert.Equal(t, tc.params, params, "StorageClass parameters must not be modified")
+		})
+	}
+}
+
+func TestWithDefaultGP3Parameters(t *testing.T) {
+	defaults := map[string]string{EncryptedKey: trueStr, "IOPSPerGB": "50", "throughput": "250"}
+	testCases := []struct {
+		name           string
+		iops           int32
+		throughput     int32
+		params         map[string]string
+		expectedParams map[string]string
+	}{
+		{
+			name:           "gp3 defaults replace the IOPS and throughput defaults",
+			iops:           6000,
+			throughput:     500,
+			params:         map[string]string{},
+			expectedParams: map[string]string{EncryptedKey: trueStr, "iops": "6000", "throughput": "500"},
+		},
+		{
+			name:           "StorageClass parameters take precedence",
+			iops:           6000,
+			throughput:     500,
+			params:         map[string]string{"iopsPerGB": "10", "Throughput": "125"},
+			expectedParams: map[string]string{"iopsPerGB": "10", "Throughput": "125", EncryptedKey: trueStr},
+		},
+		{
+			name:           "only the set gp3 default is replaced",
+			throughput:     500,
+			params:         map[string]string{VolumeTypeKey: "gp3"},
+			expectedParams: map[string]string{VolumeTypeKey: "gp3", EncryptedKey: trueStr, "IOPSPerGB": "50", "throughput": "500"},
+		},
+		{
+			name:           "gp3 defaults do not apply to other volume types",
+			iops:           6000,
+			throughput:     500,
+			params:         map[string]string{VolumeTypeKey: "io2"},
+			expectedParams: map[string]string{VolumeTypeKey: "io2", EncryptedKey: trueStr, "IOPSPerGB": "50"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &Options{DefaultVolumeParameters: defaults, DefaultGP3IOPS: tc.iops, DefaultGP3Throughput: tc.throughput}
+			d := &ControllerService{options: o}
+			assert.Equal(t, tc.expectedParams, d.withDefaultParameters(tc.params))
+			assert.Equal(t, map[string]string{EncryptedKey: trueStr, "IOPSPerGB": "50", "throughput": "250"}, o.DefaultVolumeParameters, "defaults must not be modified")
+		})
+	}
+
+	d := &ControllerService{options: &Options{DefaultGP3IOPS: 4000}}
+	assert.Equal(t, map[string]string{"iops": "4000"}, d.withDefaultParameters(nil))
+}
@@ -39,6 +39,41 @@ func ValidateDriverOptions(options *Options) error {
 		return errors.New("invalid modifyVolumeRequestHandlerTimeout: timeout cannot be zero")
 	}
 
+	if options.MaxQueuedRequests < 0 {
+		return errors.New("invalid maxQueuedRequests: limit cannot be negative")
+	}
+
+	if options.SnapshotsPerRegionQuota < 0 {
+		return errors.New("invalid snapshotsPerRegionQuota: quota cannot be negative")
+	}
+
+	if options.VolumesPerRegionQuota < 0 {
+		return errors.New("invalid volumesPerRegionQuota: quota cannot be negative")
+	}
+
+	if options.MaxConcurrentSnapshots < 0 {
+		return errors.New("invalid maxConcurrentSnapshots: limit cannot be negative")
+	}
+
+	if options.MaxQueuedSnapshots < 0 {
+		return errors.New("invalid maxQueuedSnapshots: limit cannot be negative")
+	}
+
+	if options.SoftDeleteRetention < 0 {
+		return errors.New("invalid softDeleteRetention: retention cannot be negative")
+	}
+
+	for namespace, quota := range options.NamespaceQuotas {
+		if err := validateQuotaLimits(quota.QuotaLimits); err != nil {
+			return fmt.Errorf("invalid namespace quota for %q: %w", namespace, err)
+		}
+		for volumeType, limits := range quota.VolumeTypes {
+			if err := validateQuotaLimits(limits); err != nil {
+				return fmt.Errorf("invalid namespace quota for %q volume type %s: %w", namespace, volumeType, err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -70,6 +105,13 @@ func validateExtraTags(tags map[string]string, warnOnly bool) error {
 	return nil
 }
 
+func validateQuotaLimits(limits QuotaLimits) error {
+	if limits.CapacityGiB < 0 || limits.IOPS < 0 {
+		return errors.New("limits cannot be negative")
+	}
+	return nil
+}
+
 func validateMode(mode Mode) error {
 	if mode != AllMode && mode != ControllerMode && mode != NodeMode {
 		return fmt.Errorf("mode is not supported (actual: %s, supported: %v)", mode, []Mode{AllMode, ControllerMode, NodeMode})
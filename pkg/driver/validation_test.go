@@ -194,6 +194,13 @@ func TestValidateDriverOptions(t *testing.T) {
 		mode                Mode
 		extraVolumeTags     map[string]string
 		modifyVolumeTimeout time.Duration
+		maxQueuedRequests   int
+		softDeleteRetention time.Duration
+		snapshotsQuota      int
+		volumesQuota        int
+		maxSnapshots        int
+		maxQueuedSnapshots  int
+		namespaceQuotas     map[string]NamespaceQuota
 		expErr              error
 	}{
 		{
@@ -223,6 +230,62 @@ func TestValidateDriverOptions(t *testing.T) {
 			modifyVolumeTimeout: 0,
 			expErr:              errors.New("invalid modifyVolumeRequestHandlerTimeout: timeout cannot be zero"),
 		},
+		{
+			name:                "fail because maxQueuedRequests is negative",
+			mode:                AllMode,
+			modifyVolumeTimeout: 5 * time.Second,
+			maxQueuedRequests:   -1,
+			expErr:              errors.New("invalid maxQueuedRequests: limit cannot be negative"),
+		},
+		{
+			name:                "fail because snapshotsPerRegionQuota is negative",
+			mode:                ControllerMode,
+			modifyVolumeTimeout: 5 * time.Second,
+			snapshotsQuota:      -1,
+			expErr:              errors.New("invalid snapshotsPerRegionQuota: quota cannot be negative"),
+		},
+		{
+			name:                "fail because volumesPerRegionQuota is negative",
+			mode:                ControllerMode,
+			modifyVolumeTimeout: 5 * time.Second,
+			volumesQuota:        -1,
+			expErr:              errors.New("invalid volumesPerRegionQuota: quota cannot be negative"),
+		},
+		{
+			name:                "fail because maxConcurrentSnapshots is negative",
+			mode:                ControllerMode,
+			modifyVolumeTimeout: 5 * time.Second,
+			maxSnapshots:        -1,
+			expErr:              errors.New("invalid maxConcurrentSnapshots: limit cannot be negative"),
+		},
+		{
+			name:                "fail because maxQueuedSnapshots is negative",
+			mode:                ControllerMode,
+			modifyVolumeTimeout: 5 * time.Second,
+			maxQueuedSnapshots:  -1,
+			expErr:              errors.New("invalid maxQueuedSnapshots: limit cannot be negative"),
+		},
+		{
+			name:                "fail because softDeleteRetention is negative",
+			mode:                AllMode,
+			modifyVolumeTimeout: 5 * time.Second,
+			softDeleteRetention: -time.Hour,
+			expErr:              errors.New("invalid softDeleteRetention: retention cannot be negative"),
+		},
+		{
+			name:                "fail because namespace quota is negative",
+			mode:                AllMode,
+			modifyVolumeTimeout: 5 * time.Second,
+			namespaceQuotas:     map[string]NamespaceQuota{"team-a": {QuotaLimits: QuotaLimits{CapacityGiB: -1}}},
+			expErr:              fmt.Errorf("invalid namespace quota for %q: %w", "team-a", errors.New("limits cannot be negative")),
+		},
+		{
+			name:                "fail because volume type quota is negative",
+			mode:                AllMode,
+			modifyVolumeTimeout: 5 * time.Second,
+			namespaceQuotas:     map[string]NamespaceQuota{"*": {VolumeTypes: map[string]QuotaLimits{"io2": {IOPS: -1}}}},
+			expErr:              fmt.Errorf("invalid namespace quota for %q volume type %s: %w", "*", "io2", errors.New("limits cannot be negative")),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -231,6 +294,13 @@ func TestValidateDriverOptions(t *testing.T) {
 				ExtraTags:                         tc.extraVolumeTags,
 				Mode:                              tc.mode,
 				ModifyVolumeRequestHandlerTimeout: tc.modifyVolumeTimeout,
+				MaxQueuedRequests:                 tc.maxQueuedRequests,
+				SoftDeleteRetention:               tc.softDeleteRetention,
+				SnapshotsPerRegionQuota:           tc.snapshotsQuota,
+				VolumesPerRegionQuota:             tc.volumesQuota,
+				MaxConcurrentSnapshots:            tc.maxSnapshots,
+				MaxQueuedSnapshots:                tc.maxQueuedSnapshots,
+				NamespaceQuotas:                   tc.namespaceQuotas,
 			})
 			if !reflect.DeepEqual(err, tc.expErr) {
 				t.Fatalf("error not equal\ngot:\n%s\nexpected:\n%s", err, tc.expErr)
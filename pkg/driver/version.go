@@ -17,9 +17,14 @@ limitations under the License.
 package driver
 
 import (
+	"crypto/fips140"
 	"encoding/json"
 	"fmt"
 	"runtime"
+	"strconv"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"k8s.io/component-base/featuregate"
 )
 
 // These are set during build time via -ldflags.
@@ -57,3 +62,28 @@ func GetVersionJSON() (string, error) {
 	}
 	return string(marshalled), nil
 }
+
+// RecordBuildInfo records the build of the driver, whether it runs in FIPS mode and the state of every feature gate
+// of gates as metrics, so that the drivers of a fleet can be inventoried from their metrics.
+func RecordBuildInfo(gates featuregate.MutableFeatureGate) {
+	info := GetVersion()
+	metrics.Recorder().SetGauge(metrics.BuildInfo, metrics.BuildInfoHelpText, 1, map[string]string{
+		"version":    info.DriverVersion,
+		"git_commit": info.GitCommit,
+		"build_date": info.BuildDate,
+		"go_version": info.GoVersion,
+		"platform":   info.Platform,
+		"fips":       strconv.FormatBool(fips140.Enabled()),
+	})
+
+	for feature, spec := range gates.GetAll() {
+		enabled := 0.0
+		if gates.Enabled(feature) {
+			enabled = 1
+		}
+		metrics.Recorder().SetGauge(metrics.FeatureEnabled, metrics.FeatureEnabledHelpText, enabled, map[string]string{
+			"name":  string(feature),
+			"stage": string(spec.PreRelease),
+		})
+	}
+}
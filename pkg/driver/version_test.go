@@ -17,10 +17,16 @@ limitations under the License.
 package driver
 
 import (
+	"crypto/fips140"
 	"fmt"
 	"reflect"
 	"runtime"
+	"strconv"
 	"testing"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"github.com/stretchr/testify/require"
+	"k8s.io/component-base/featuregate"
 )
 
 func TestGetVersion(t *testing.T) {
@@ -59,3 +65,44 @@ func TestGetVersionJSON(t *testing.T) {
 		t.Fatalf("json not equall\ngot:\n%s\nexpected:\n%s", version, expected)
 	}
 }
+
+func TestRecordBuildInfo(t *testing.T) {
+	_, registry := metrics.InitializeRecorder(false)
+	gates := featuregate.NewFeatureGate()
+	require.NoError(t, gates.Add(map[featuregate.Feature]featuregate.FeatureSpec{
+		"TestAlphaFeature": {Default: false, PreRelease: featuregate.Alpha},
+		"TestBetaFeature":  {Default: true, PreRelease: featuregate.Beta},
+	}))
+
+	RecordBuildInfo(gates)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	values := map[string]map[string]float64{}
+	var buildInfo map[string]string
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			switch family.GetName() {
+			case metrics.BuildInfo:
+				buildInfo = labels
+			case metrics.FeatureEnabled:
+				values[labels["name"]] = map[string]float64{labels["stage"]: metric.GetGauge().GetValue()}
+			}
+		}
+	}
+
+	require.Equal(t, map[string]string{
+		"version":    "",
+		"git_commit": "",
+		"build_date": "",
+		"go_version": runtime.Version(),
+		"platform":   fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		"fips":       strconv.FormatBool(fips140.Enabled()),
+	}, buildInfo)
+	require.Equal(t, map[string]float64{"ALPHA": 0}, values["TestAlphaFeature"])
+	require.Equal(t, map[string]float64{"BETA": 1}, values["TestBetaFeature"])
+}
@@ -43,8 +43,23 @@ type ExpiringCache[KeyType comparable, ValueType any] interface {
 	Set(key KeyType, value *ValueType)
 	// Remove operates identically to removing a value in a map
 	Remove(key KeyType)
+	// WithObserver registers fn to be called on every cache hit, miss and eviction
+	// It must be called before the cache is first used
+	WithObserver(fn func(event Event)) ExpiringCache[KeyType, ValueType]
 }
 
+// Event is something that happened to a cache entry, reported to the cache's observer.
+type Event string
+
+const (
+	// EventHit is a Get of a key in the cache.
+	EventHit Event = "hit"
+	// EventMiss is a Get of a key not in the cache.
+	EventMiss Event = "miss"
+	// EventEviction is the removal of an entry that was not accessed for the expiration delay.
+	EventEviction Event = "eviction"
+)
+
 type timedValue[ValueType any] struct {
 	value *ValueType
 	timer *time.Timer
@@ -54,6 +69,7 @@ type expiringCache[KeyType comparable, ValueType any] struct {
 	expirationDelay time.Duration
 	values          map[KeyType]timedValue[ValueType]
 	mutex           sync.Mutex
+	observe         func(event Event)
 }
 
 // New returns a new ExpiringCache
@@ -71,8 +87,10 @@ func (c *expiringCache[KeyType, ValueType]) Get(key KeyType) (*ValueType, bool)
 
 	if v, ok := c.values[key]; ok {
 		v.timer.Reset(c.expirationDelay)
+		c.notify(EventHit)
 		return v.value, true
 	} else {
+		c.notify(EventMiss)
 		return nil, false
 	}
 }
@@ -91,7 +109,10 @@ func (c *expiringCache[KeyType, ValueType]) Set(key KeyType, value *ValueType) {
 				c.mutex.Lock()
 				defer c.mutex.Unlock()
 
-				delete(c.values, key)
+				if _, ok := c.values[key]; ok {
+					delete(c.values, key)
+					c.notify(EventEviction)
+				}
 			}),
 			value: value,
 		}
@@ -104,3 +125,14 @@ func (c *expiringCache[KeyType, ValueType]) Remove(key KeyType) {
 	// In the case we call Remove on a key that does not exist delete is a no op
 	delete(c.values, key)
 }
+
+func (c *expiringCache[KeyType, ValueType]) WithObserver(fn func(event Event)) ExpiringCache[KeyType, ValueType] {
+	c.observe = fn
+	return c
+}
+
+func (c *expiringCache[KeyType, ValueType]) notify(event Event) {
+	if c.observe != nil {
+		c.observe(event)
+	}
+}
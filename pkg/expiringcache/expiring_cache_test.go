@@ -17,6 +17,7 @@ limitations under the License.
 package expiringcache
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -69,3 +70,29 @@ func TestExpiringCache(t *testing.T) {
 	assert.False(t, ok, "Should not be able to Get() value after it is removed")
 	assert.Nil(t, value, "Value should be nil when Get() returns not ok (after removal)")
 }
+
+func TestExpiringCacheObserver(t *testing.T) {
+	t.Parallel()
+
+	var mutex sync.Mutex
+	events := map[Event]int{}
+	cache := New[string, string](testExpiration).WithObserver(func(event Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events[event]++
+	})
+
+	cache.Get(testKey)
+	cache.Set(testKey, &testValue1)
+	cache.Get(testKey)
+	cache.Get(testKey)
+	cache.Set("removed", &testValue1)
+	cache.Remove("removed")
+
+	time.Sleep(testExpiration * 2)
+	cache.Get(testKey)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, map[Event]int{EventHit: 2, EventMiss: 2, EventEviction: 1}, events, "Removed entries should not be reported as evicted")
+}
@@ -16,16 +16,90 @@ package metrics
 
 // constants for prometheus metrics use.
 const (
-	APIRequestDuration                    = "aws_ebs_csi_api_request_duration_seconds"
-	APIRequestErrors                      = "aws_ebs_csi_api_request_errors_total"
-	APIRequestThrottles                   = "aws_ebs_csi_api_request_throttles_total"
-	APIRequestDurationHelpText            = "AWS SDK API request duration by request type in seconds"
-	APIRequestErrorsHelpText              = "Total number of AWS SDK API errors by error code and request type"
-	APIRequestThrottlesHelpText           = "Total number of throttled AWS SDK API requests per request type"
-	DeprecatedAPIRequestDurationHelpText  = APIRequestDurationHelpText + " (deprecated)"
-	DeprecatedAPIRequestErrorsHelpText    = APIRequestErrorsHelpText + " (deprecated)"
-	DeprecatedAPIRequestThrottlesHelpText = APIRequestThrottlesHelpText + " (deprecated)"
-	DeprecatedAPIRequestDuration          = "cloudprovider_aws_api_request_duration_seconds"
-	DeprecatedAPIRequestErrors            = "cloudprovider_aws_api_request_errors"
-	DeprecatedAPIRequestThrottles         = "cloudprovider_aws_api_throttled_requests_total"
+	APIRequestDuration                      = "aws_ebs_csi_api_request_duration_seconds"
+	APIRequestErrors                        = "aws_ebs_csi_api_request_errors_total"
+	APIRequestThrottles                     = "aws_ebs_csi_api_request_throttles_total"
+	APIRequestDurationHelpText              = "AWS SDK API request duration by request type in seconds"
+	APIRequestErrorsHelpText                = "Total number of AWS SDK API errors by error code and request type"
+	APIRequestThrottlesHelpText             = "Total number of throttled AWS SDK API requests per request type"
+	DeprecatedAPIRequestDurationHelpText    = APIRequestDurationHelpText + " (deprecated)"
+	DeprecatedAPIRequestErrorsHelpText      = APIRequestErrorsHelpText + " (deprecated)"
+	DeprecatedAPIRequestThrottlesHelpText   = APIRequestThrottlesHelpText + " (deprecated)"
+	DeprecatedAPIRequestDuration            = "cloudprovider_aws_api_request_duration_seconds"
+	DeprecatedAPIRequestErrors              = "cloudprovider_aws_api_request_errors"
+	DeprecatedAPIRequestThrottles           = "cloudprovider_aws_api_throttled_requests_total"
+	BatchWaitDuration                       = "aws_ebs_csi_batch_wait_duration_seconds"
+	BatchWaitDurationHelpText               = "Time callers wait on a batched AWS SDK API request in seconds, by request type and batching lane"
+	BatchRetryBudgetExhausted               = "aws_ebs_csi_batch_retry_budget_exhausted_total"
+	BatchRetryBudgetExhaustedHelpText       = "Total number of retries of batched AWS SDK API requests refused because the retry budget shared by the batches of the request type was exhausted, by request type"
+	BatchSize                               = "aws_ebs_csi_batch_size"
+	BatchSizeHelpText                       = "Number of distinct resources in each batched AWS SDK API request, by request type and batching lane"
+	BatchRequests                           = "aws_ebs_csi_batch_requests"
+	BatchRequestsHelpText                   = "Number of callers served by each batched AWS SDK API request, by request type and batching lane"
+	CoalescedRequests                       = "aws_ebs_csi_coalesced_requests"
+	CoalescedRequestsHelpText               = "Number of requests merged into each coalesced operation, by request type"
+	CoalesceWaitDuration                    = "aws_ebs_csi_coalesce_wait_duration_seconds"
+	CoalesceWaitDurationHelpText            = "Time the first request merged into each coalesced operation waited for it to execute in seconds, by request type"
+	CoalesceConflicts                       = "aws_ebs_csi_coalesce_conflicts_total"
+	CoalesceConflictsHelpText               = "Total number of requests rejected because they conflict with a pending coalesced operation, by request type"
+	EBSBandwidthOversubscribed              = "aws_ebs_csi_ebs_bandwidth_oversubscribed_total"
+	EBSBandwidthOversubscribedHelpText      = "Total number of volumes attached to instances whose attached volumes can together exceed their EBS-optimized bandwidth, by instance type"
+	ClientTokenConflicts                    = "aws_ebs_csi_client_token_conflicts_total"
+	ClientTokenConflictsHelpText            = "Total number of CreateVolume calls that failed with IdempotentParameterMismatch, by client token strategy and whether the client token was retired (new_token) or kept because the volume exists (existing_volume) or could not be looked up (unknown)"
+	ImpairedVolumes                         = "aws_ebs_csi_impaired_volumes"
+	ImpairedVolumesHelpText                 = "Number of attached volumes that EBS reports as impaired or whose I/O EBS disabled, by status"
+	VolumeIOEnabled                         = "aws_ebs_csi_volume_io_enabled_total"
+	VolumeIOEnabledHelpText                 = "Total number of volumes whose I/O the driver re-enabled after EBS disabled it, by result"
+	VolumeInitializationProgress            = "aws_ebs_csi_volume_initialization_progress_percent"
+	VolumeInitializationProgressHelpText    = "Percentage of the blocks of a volume restored from a snapshot already downloaded from the snapshot, by volume ID"
+	APIBudgetWaitDuration                   = "aws_ebs_csi_api_budget_wait_duration_seconds"
+	APIBudgetWaitDurationHelpText           = "Time mutating EC2 calls waited for the share of their API budget class in seconds, by class"
+	DeprecatedParameters                    = "aws_ebs_csi_deprecated_parameters_total"
+	DeprecatedParametersHelpText            = "Total number of requests that used a deprecated StorageClass or VolumeAttributesClass parameter, by request and parameter"
+	InvalidParameters                       = "aws_ebs_csi_invalid_parameters_total"
+	InvalidParametersHelpText               = "Total number of requests that set a StorageClass or VolumeAttributesClass parameter to an invalid value read as its default value, by request and parameter"
+	DefaultParametersApplied                = "aws_ebs_csi_default_parameters_applied_total"
+	DefaultParametersAppliedHelpText        = "Total number of CreateVolume requests that a parameter of --default-volume-parameters was applied to because their StorageClass does not set it, by parameter"
+	SELinuxContextMounts                    = "aws_ebs_csi_selinux_context_mounts_total"
+	SELinuxContextMountsHelpText            = "Total number of volumes staged with an SELinux context mount option, which are not relabeled by the container runtime, by filesystem type"
+	DeviceResolutionDuration                = "aws_ebs_csi_device_resolution_duration_seconds"
+	DeviceResolutionDurationHelpText        = "Time taken to find the device of a volume on the node in seconds, by the method that found it (by_id, device_path, nvme_identify) or not_found"
+	DeviceResolutionTimeouts                = "aws_ebs_csi_device_resolution_timeouts_total"
+	DeviceResolutionTimeoutsHelpText        = "Total number of device lookups that gave up after waiting for udev for the udev settle timeout, by udev settle strategy"
+	UdevSettleDuration                      = "aws_ebs_csi_udev_settle_duration_seconds"
+	UdevSettleDurationHelpText              = "Time spent waiting in udevadm settle while looking for the device of a volume, in seconds"
+	FilesystemGeometryCacheRequests         = "aws_ebs_csi_filesystem_geometry_cache_requests_total"
+	FilesystemGeometryCacheRequestsHelpText = "Total number of filesystem resize checks and resizes, by operation and whether they were skipped because the filesystem was known to fill its device (hit) or not (miss)"
+	OperationDuration                       = "aws_ebs_csi_operation_duration_seconds"
+	OperationDurationHelpText               = "CSI operation duration in seconds, by CSI method and gRPC status code"
+	ExpiringCacheRequests                   = "aws_ebs_csi_expiring_cache_requests_total"
+	ExpiringCacheRequestsHelpText           = "Total number of lookups in the driver's in-memory caches, by cache name and whether the key was cached (hit) or not (miss)"
+	ExpiringCacheEvictions                  = "aws_ebs_csi_expiring_cache_evictions_total"
+	ExpiringCacheEvictionsHelpText          = "Total number of entries removed from the driver's in-memory caches because they were not accessed for the cache's expiration delay, by cache name"
+	BuildInfo                               = "aws_ebs_csi_build_info"
+	BuildInfoHelpText                       = "A metric with a constant '1' value labeled by the version, git commit, build date, Go version and platform the driver was built with, and whether it runs in FIPS 140-3 mode"
+	DegradedProvisioning                    = "aws_ebs_csi_degraded_provisioning"
+	DegradedProvisioningHelpText            = "Whether the controller announced degraded provisioning (1) or not (0), by reason"
+	FeatureEnabled                          = "aws_ebs_csi_feature_enabled"
+	FeatureEnabledHelpText                  = "Whether each feature gate of the driver is enabled (1) or disabled (0), by feature name and stage"
+	AllocatableCorrections                  = "aws_ebs_csi_allocatable_corrections_total"
+	AllocatableCorrectionsHelpText          = "Total number of times the node registered the driver again because the CSINode allocatable count diverged from the volume limit of the node"
+	VolumeWarmUpProgress                    = "aws_ebs_csi_volume_warm_up_progress_percent"
+	VolumeWarmUpProgressHelpText            = "Percentage of the device of a volume restored from a snapshot already read by the node to warm it up, by volume ID"
+	VolumeWarmUps                           = "aws_ebs_csi_volume_warm_ups_total"
+	VolumeWarmUpsHelpText                   = "Total number of volume warm-ups that ended, by result (completed, canceled or failed)"
+	NodeOperationsInFlight                  = "aws_ebs_csi_node_operations_in_flight"
+	NodeOperationsInFlightHelpText          = "Number of NodeStageVolume and NodePublishVolume calls running under --max-concurrent-node-operations"
+	NodeOperationsQueued                    = "aws_ebs_csi_node_operations_queued"
+	NodeOperationsQueuedHelpText            = "Number of NodeStageVolume and NodePublishVolume calls waiting for --max-concurrent-node-operations"
+	NodeOperationWaitDuration               = "aws_ebs_csi_node_operation_wait_duration_seconds"
+	NodeOperationWaitDurationHelpText       = "Time NodeStageVolume and NodePublishVolume calls waited for --max-concurrent-node-operations in seconds, by operation"
+	PVCMetadataCacheRequests                = "aws_ebs_csi_pvc_metadata_cache_requests_total"
+	PVCMetadataCacheRequestsHelpText        = "Total number of PVCs whose labels and annotations were read for tag templates from the PVC metadata cache (hit), or from the API server because the cache did not have them (miss) or was stale (stale)"
+	QuotaExceeded                           = "aws_ebs_csi_quota_exceeded_total"
+	QuotaExceededHelpText                   = "Total number of EC2 requests that failed because an account quota was exceeded, by quota and volume type"
+	PolicyWebhookReviews                    = "aws_ebs_csi_policy_webhook_reviews_total"
+	PolicyWebhookReviewsHelpText            = "Total number of CreateVolume requests sent to the policy webhook, by result (allowed, mutated, denied or error)"
+	EstimatedMonthlyCost                    = "aws_ebs_csi_estimated_monthly_cost_dollars_total"
+	EstimatedMonthlyCostHelpText            = "Total approximate monthly cost in US dollars of the volumes and snapshots created by the controller, estimated from their size, IOPS and throughput with the price table of --estimate-costs, by operation, namespace, StorageClass and volume type"
 )
@@ -15,12 +15,14 @@
 package metrics
 
 import (
+	"context"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/time/rate"
 	"k8s.io/klog/v2"
 )
@@ -59,6 +61,7 @@ type MetricRecorder struct {
 	mu              sync.RWMutex
 	metrics         map[string]any
 	asyncEC2Metrics *AsyncEC2Collector
+	mux             *http.ServeMux
 }
 
 // Recorder returns the singleton instance of metricRecorder.
@@ -125,6 +128,11 @@ func AsyncEC2Metrics() *AsyncEC2Collector {
 
 // IncreaseCount increases the counter metric by 1.
 func (m *MetricRecorder) IncreaseCount(name string, helpText string, labels map[string]string) {
+	m.AddCount(name, helpText, 1, labels)
+}
+
+// AddCount increases the counter metric by the given non-negative value.
+func (m *MetricRecorder) AddCount(name string, helpText string, value float64, labels map[string]string) {
 	if m == nil {
 		return // recorder is not initialized
 	}
@@ -136,18 +144,43 @@ func (m *MetricRecorder) IncreaseCount(name string, helpText string, labels map[
 	if !ok {
 		klog.V(4).InfoS("Metric not found, registering", "name", name, "labels", labels)
 		m.registerCounterVec(name, helpText, getLabelNames(labels))
-		m.IncreaseCount(name, helpText, labels)
+		m.AddCount(name, helpText, value, labels)
 		return
 	}
 
 	metricAsCounterVec, ok := metric.(*prometheus.CounterVec)
 	if ok {
-		metricAsCounterVec.With(labels).Inc()
+		metricAsCounterVec.With(labels).Add(value)
 	} else {
 		klog.V(4).InfoS("Could not assert metric as metrics.CounterVec. Metric increase may have been skipped")
 	}
 }
 
+// SetGauge sets the gauge metric to the given value.
+func (m *MetricRecorder) SetGauge(name string, helpText string, value float64, labels map[string]string) {
+	if m == nil {
+		return // recorder is not initialized
+	}
+
+	m.mu.RLock()
+	metric, ok := m.metrics[name]
+	m.mu.RUnlock()
+
+	if !ok {
+		klog.V(4).InfoS("Metric not found, registering", "name", name, "labels", labels)
+		m.registerGaugeVec(name, helpText, getLabelNames(labels))
+		m.SetGauge(name, helpText, value, labels)
+		return
+	}
+
+	metricAsGaugeVec, ok := metric.(*prometheus.GaugeVec)
+	if ok {
+		metricAsGaugeVec.With(labels).Set(value)
+	} else {
+		klog.V(4).InfoS("Could not assert metric as metrics.GaugeVec. Metric update may have been skipped")
+	}
+}
+
 // ObserveHistogram records the given value in the histogram metric.
 func (m *MetricRecorder) ObserveHistogram(name string, helpText string, value float64, labels map[string]string, buckets []float64) {
 	if m == nil {
@@ -173,6 +206,57 @@ func (m *MetricRecorder) ObserveHistogram(name string, helpText string, value fl
 	}
 }
 
+// ObserveHistogramWithContext records the given value in the histogram metric like ObserveHistogram. When ctx
+// carries a sampled OpenTelemetry span, the observation is recorded with an exemplar holding its trace and span IDs,
+// so that a latency spike can be followed to the trace of the request that caused it.
+func (m *MetricRecorder) ObserveHistogramWithContext(ctx context.Context, name string, helpText string, value float64, labels map[string]string, buckets []float64) {
+	if m == nil {
+		return // recorder is not initialized
+	}
+
+	exemplar := traceExemplar(ctx)
+	if exemplar == nil {
+		m.ObserveHistogram(name, helpText, value, labels, buckets)
+		return
+	}
+
+	m.mu.RLock()
+	metric, ok := m.metrics[name]
+	m.mu.RUnlock()
+
+	if !ok {
+		klog.V(4).InfoS("Metric not found, registering", "name", name, "labels", labels, "buckets", buckets)
+		m.registerHistogramVec(name, helpText, getLabelNames(labels), buckets)
+		m.ObserveHistogramWithContext(ctx, name, helpText, value, labels, buckets)
+		return
+	}
+
+	metricAsHistogramVec, ok := metric.(*prometheus.HistogramVec)
+	if !ok {
+		klog.V(4).InfoS("Could not assert metric as metrics.HistogramVec. Metric observation may have been skipped")
+		return
+	}
+	observer := metricAsHistogramVec.With(labels)
+	if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+		exemplarObserver.ObserveWithExemplar(value, exemplar)
+	} else {
+		observer.Observe(value)
+	}
+}
+
+// traceExemplar returns the exemplar labels of the sampled span in ctx, or nil if there is none. Unsampled spans are
+// not exported, so an exemplar pointing to them would lead nowhere.
+func traceExemplar(ctx context.Context) prometheus.Labels {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsSampled() {
+		return nil
+	}
+	return prometheus.Labels{
+		"trace_id": spanContext.TraceID().String(),
+		"span_id":  spanContext.SpanID().String(),
+	}
+}
+
 // rateLimitMiddleware applies rate limiting to metric HTTP requests.
 func rateLimitMiddleware(limiter *rate.Limiter, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -193,8 +277,13 @@ func (m *MetricRecorder) InitializeMetricsHandler(address, path, certFile, keyFi
 
 	limiter := rate.NewLimiter(metricsRateLimit, metricsRateBurst)
 	mux := http.NewServeMux()
-	metricsHandler := promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+	metricsHandler := promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+		// Exemplars are only exposed in the OpenMetrics format, which Prometheus negotiates when exemplar storage is enabled
+		EnableOpenMetrics: true,
+	})
 	mux.Handle(path, rateLimitMiddleware(limiter, metricsHandler))
+	m.mux = mux
 
 	server := &http.Server{
 		Addr:        address,
@@ -219,6 +308,15 @@ func (m *MetricRecorder) InitializeMetricsHandler(address, path, certFile, keyFi
 	}()
 }
 
+// HandleDebug serves a debug handler on path of the metrics server, with its own rate limit.
+func (m *MetricRecorder) HandleDebug(path string, handler http.Handler) {
+	if m == nil || m.mux == nil {
+		klog.InfoS("HandleDebug: metrics server is not initialized", "path", path)
+		return
+	}
+	m.mux.Handle(path, rateLimitMiddleware(rate.NewLimiter(metricsRateLimit, metricsRateBurst), handler))
+}
+
 func (m *MetricRecorder) registerHistogramVec(name, help string, labels []string, buckets []float64) *prometheus.HistogramVec {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -259,6 +357,23 @@ func (m *MetricRecorder) registerCounterVec(name, help string, labels []string)
 	m.registry.MustRegister(counter)
 }
 
+func (m *MetricRecorder) registerGaugeVec(name, help string, labels []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.metrics[name]; exists {
+		return
+	}
+	gauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: name,
+			Help: help,
+		},
+		labels,
+	)
+	m.metrics[name] = gauge
+	m.registry.MustRegister(gauge)
+}
+
 func getLabelNames(labels map[string]string) []string {
 	names := make([]string, 0, len(labels))
 	for n := range labels {
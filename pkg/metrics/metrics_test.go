@@ -15,12 +15,15 @@
 package metrics
 
 import (
+	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/component-base/metrics/testutil"
 )
 
@@ -43,6 +46,19 @@ test_total{key="value"} 1
 			`,
 			recorder: true,
 		},
+		{
+			name: "TestMetricRecorder: AddCounterMetric",
+			exec: func(m *MetricRecorder) {
+				m.AddCount("test_added_total", "help text", 1.5, map[string]string{"key": "value"})
+				m.AddCount("test_added_total", "help text", 0.25, map[string]string{"key": "value"})
+			},
+			expected: `
+# HELP test_added_total help text
+# TYPE test_added_total counter
+test_added_total{key="value"} 1.75
+			`,
+			recorder: true,
+		},
 		{
 			name: "TestMetricRecorder: ObserveHistogramMetric",
 			exec: func(m *MetricRecorder) {
@@ -60,6 +76,21 @@ test_count{key="value"} 1
 			`,
 			recorder: true,
 		},
+		{
+			name: "TestMetricRecorder: SetGaugeMetric",
+			exec: func(m *MetricRecorder) {
+				m.SetGauge("test_level", "help text", 1, map[string]string{"key": "value1"})
+				m.SetGauge("test_level", "help text", 0, map[string]string{"key": "value2"})
+				m.SetGauge("test_level", "help text", 2, map[string]string{"key": "value1"})
+			},
+			expected: `
+# HELP test_level help text
+# TYPE test_level gauge
+test_level{key="value1"} 2
+test_level{key="value2"} 0
+			`,
+			recorder: true,
+		},
 		{
 			name: "TestMetricRecorder: Re-register metric",
 			exec: func(m *MetricRecorder) {
@@ -115,6 +146,80 @@ func TestMetricRecorderConcurrentAccess(t *testing.T) {
 	wg.Wait()
 }
 
+func TestObserveHistogramWithContext(t *testing.T) {
+	traceID := trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+	spanID := trace.SpanID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	spanContext := func(flags trace.TraceFlags) context.Context {
+		return trace.ContextWithSpanContext(t.Context(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: flags,
+		}))
+	}
+
+	tests := []struct {
+		name             string
+		ctx              context.Context
+		expectedExemplar map[string]string
+	}{
+		{
+			name: "sampled span",
+			ctx:  spanContext(trace.FlagsSampled),
+			expectedExemplar: map[string]string{
+				"trace_id": traceID.String(),
+				"span_id":  spanID.String(),
+			},
+		},
+		{
+			name: "unsampled span",
+			ctx:  spanContext(0),
+		},
+		{
+			name: "no span",
+			ctx:  t.Context(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MetricRecorder{
+				registry: prometheus.NewRegistry(),
+				metrics:  make(map[string]any),
+			}
+
+			m.ObserveHistogramWithContext(tt.ctx, "test_duration_seconds", "help text", 1.5, map[string]string{"key": "value"}, []float64{1, 2, 3})
+
+			families, err := m.registry.Gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(families) != 1 || len(families[0].GetMetric()) != 1 {
+				t.Fatalf("expected a single histogram, got %v", families)
+			}
+			histogram := families[0].GetMetric()[0].GetHistogram()
+			if histogram.GetSampleCount() != 1 {
+				t.Fatalf("expected 1 observation, got %d", histogram.GetSampleCount())
+			}
+
+			exemplar := map[string]string{}
+			for _, bucket := range histogram.GetBucket() {
+				for _, label := range bucket.GetExemplar().GetLabel() {
+					exemplar[label.GetName()] = label.GetValue()
+				}
+			}
+			if tt.expectedExemplar == nil {
+				if len(exemplar) != 0 {
+					t.Fatalf("expected no exemplar, got %v", exemplar)
+				}
+				return
+			}
+			if !reflect.DeepEqual(exemplar, tt.expectedExemplar) {
+				t.Fatalf("expected exemplar %v, got %v", tt.expectedExemplar, exemplar)
+			}
+		})
+	}
+}
+
 func getMetricNameFromExpected(expected string) string {
 	lines := strings.SplitSeq(expected, "\n")
 	for line := range lines {
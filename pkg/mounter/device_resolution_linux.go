@@ -0,0 +1,188 @@
+//go:build linux
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mounter
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+)
+
+const (
+	nvmeByIDPath   = "/dev/disk/by-id/"
+	nvmeByIDPrefix = "nvme-Amazon_Elastic_Block_Store_"
+	nvmeDeviceGlob = "/dev/nvme[0-9]*n1"
+
+	// Methods reported by the device resolution duration metric.
+	deviceResolutionByID         = "by_id"
+	deviceResolutionDevicePath   = "device_path"
+	deviceResolutionNvmeIdentify = "nvme_identify"
+	deviceResolutionNotFound     = "not_found"
+
+	// As defined in <linux/nvme_ioctl.h> and the NVMe base specification.
+	nvmeIoctlAdminCmd         = 0xC0484E41
+	nvmeAdminIdentify         = 0x06
+	nvmeIdentifyCNSController = 0x01
+	nvmeIdentifyDataSize      = 4096
+	nvmeIdentifySerialOffset  = 4
+	nvmeIdentifySerialSize    = 20
+)
+
+// nvmeAdminCommand is struct nvme_admin_cmd from <linux/nvme_ioctl.h>.
+type nvmeAdminCommand struct {
+	opcode      uint8
+	flags       uint8
+	rsvd1       uint16
+	nsid        uint32
+	cdw2        uint32
+	cdw3        uint32
+	metadata    uint64
+	addr        uint64
+	metadataLen uint32
+	dataLen     uint32
+	cdw10       uint32
+	cdw11       uint32
+	cdw12       uint32
+	cdw13       uint32
+	cdw14       uint32
+	cdw15       uint32
+	timeoutMs   uint32
+	result      uint32
+}
+
+// waitForUdev returns once the device may have shown up: after udevadm settle returns with the udevadm strategy,
+// and no sooner than one poll interval after it is called. It never waits past deadline.
+func (m *NodeMounter) waitForUdev(symlink string, deadline time.Time) {
+	interval := m.deviceResolution.PollInterval
+	if interval <= 0 {
+		interval = DefaultUdevPollInterval
+	}
+	next := time.Now().Add(interval)
+	if m.deviceResolution.UdevSettleStrategy == UdevSettleStrategyUdevadm {
+		m.udevSettle(symlink, deadline)
+	}
+	if wait := time.Until(next); wait > 0 {
+		time.Sleep(min(wait, time.Until(deadline)))
+	}
+}
+
+// udevSettle waits for udev to process its event queue, or for symlink to exist. It runs udevadm through Exec so
+// that it uses the udev of the host with the host mount namespace.
+func (m *NodeMounter) udevSettle(symlink string, deadline time.Time) {
+	timeout := max(int(math.Ceil(time.Until(deadline).Seconds())), 1)
+	start := time.Now()
+	output, err := m.Exec.Command("udevadm", "settle", fmt.Sprintf("--timeout=%d", timeout), "--exit-if-exists="+symlink).CombinedOutput()
+	metrics.Recorder().ObserveHistogram(metrics.UdevSettleDuration, metrics.UdevSettleDurationHelpText, time.Since(start).Seconds(), map[string]string{}, nil)
+	if err != nil {
+		klog.V(4).InfoS("udevadm settle failed, polling instead", "err", err, "output", string(output))
+	}
+}
+
+func observeDeviceResolution(method string, start time.Time) {
+	metrics.Recorder().ObserveHistogram(metrics.DeviceResolutionDuration, metrics.DeviceResolutionDurationHelpText, time.Since(start).Seconds(), map[string]string{"method": method}, nil)
+}
+
+// findNvmeDeviceBySerial returns the first nvme device matching pattern whose controller reports serial, or "" if
+// there is none. Devices that cannot be identified are skipped.
+func findNvmeDeviceBySerial(pattern, serial string, identify func(devicePath string) (string, error)) (string, error) {
+	devices, err := filepath.Glob(pattern)
+	if err != nil {
+		return "", err
+	}
+	for _, device := range devices {
+		deviceSerial, err := identify(device)
+		if err != nil {
+			klog.V(5).InfoS("[Debug] could not identify nvme device", "device", device, "err", err)
+			continue
+		}
+		if deviceSerial == serial {
+			return device, nil
+		}
+	}
+	return "", nil
+}
+
+// AttachedVolumes returns the nvme devices of the EBS volumes attached to the instance by volume ID. The volumes are
+// identified by the serial number of their controller, like FindDevicePath falls back to, so that the mapping does not
+// depend on udev symlinks. Volumes attached as Xen devices are not returned.
+func (m *NodeMounter) AttachedVolumes() (map[string]string, error) {
+	return listNvmeVolumes(nvmeDeviceGlob, nvmeIdentifySerial)
+}
+
+// listNvmeVolumes returns the nvme devices matching pattern whose controller reports the serial number of an EBS volume,
+// by volume ID. Devices that cannot be identified, such as instance store volumes, are skipped.
+func listNvmeVolumes(pattern string, identify func(devicePath string) (string, error)) (map[string]string, error) {
+	devices, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	volumes := map[string]string{}
+	for _, device := range devices {
+		serial, err := identify(device)
+		if err != nil {
+			klog.V(5).InfoS("[Debug] could not identify nvme device", "device", device, "err", err)
+			continue
+		}
+		if volumeID, ok := strings.CutPrefix(serial, "vol"); ok && volumeID != "" {
+			volumes["vol-"+strings.TrimPrefix(volumeID, "-")] = device
+		}
+	}
+	return volumes, nil
+}
+
+// nvmeIdentifySerial returns the serial number of the controller of an nvme device, from its identify controller data.
+func nvmeIdentifySerial(devicePath string) (string, error) {
+	data := make([]byte, nvmeIdentifyDataSize)
+	cmd := nvmeAdminCommand{
+		opcode:  nvmeAdminIdentify,
+		addr:    uint64(uintptr(unsafe.Pointer(&data[0]))),
+		dataLen: nvmeIdentifyDataSize,
+		cdw10:   nvmeIdentifyCNSController,
+	}
+
+	f, err := os.OpenFile(devicePath, os.O_RDONLY, 0)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	status, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), nvmeIoctlAdminCmd, uintptr(unsafe.Pointer(&cmd)))
+	runtime.KeepAlive(data)
+	if errno != 0 {
+		return "", fmt.Errorf("identify ioctl error: %w", errno)
+	}
+	if status != 0 {
+		return "", fmt.Errorf("identify command failed with status %d", status)
+	}
+	return parseNvmeIdentifySerial(data), nil
+}
+
+// parseNvmeIdentifySerial returns the serial number field of identify controller data, which is padded with spaces.
+func parseNvmeIdentifySerial(data []byte) string {
+	return strings.TrimSpace(string(data[nvmeIdentifySerialOffset : nvmeIdentifySerialOffset+nvmeIdentifySerialSize]))
+}
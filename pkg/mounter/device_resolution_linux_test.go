@@ -0,0 +1,166 @@
+//go:build linux
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mounter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/mount-utils"
+	utilexec "k8s.io/utils/exec"
+	fakeexec "k8s.io/utils/exec/testing"
+)
+
+func TestParseNvmeIdentifySerial(t *testing.T) {
+	data := make([]byte, nvmeIdentifyDataSize)
+	copy(data[nvmeIdentifySerialOffset:], "vol0123456789abcdef0")
+	assert.Equal(t, "vol0123456789abcdef0", parseNvmeIdentifySerial(data))
+
+	data = make([]byte, nvmeIdentifyDataSize)
+	copy(data[nvmeIdentifySerialOffset:], "AWS12345            Amazon EC2 NVMe")
+	assert.Equal(t, "AWS12345", parseNvmeIdentifySerial(data))
+}
+
+func TestFindNvmeDeviceBySerial(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"nvme0n1", "nvme1n1", "nvme2n1", "nvme10n1", "nvme1n1p1"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+	serials := map[string]string{
+		"nvme0n1":  "vol0aaaaaaaaaaaaaaaa",
+		"nvme2n1":  "AWS12345",
+		"nvme10n1": "vol0bbbbbbbbbbbbbbbb",
+	}
+	identify := func(devicePath string) (string, error) {
+		serial, ok := serials[filepath.Base(devicePath)]
+		if !ok {
+			return "", errors.New("identify failed")
+		}
+		return serial, nil
+	}
+	pattern := filepath.Join(dir, "nvme[0-9]*n1")
+
+	device, err := findNvmeDeviceBySerial(pattern, "vol0bbbbbbbbbbbbbbbb", identify)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "nvme10n1"), device)
+
+	device, err = findNvmeDeviceBySerial(pattern, "vol0cccccccccccccccc", identify)
+	require.NoError(t, err)
+	assert.Empty(t, device)
+}
+
+func TestListNvmeVolumes(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"nvme0n1", "nvme1n1", "nvme2n1", "nvme10n1", "nvme1n1p1"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+	serials := map[string]string{
+		"nvme0n1":  "vol0aaaaaaaaaaaaaaaa",
+		"nvme2n1":  "AWS12345",
+		"nvme10n1": "vol0bbbbbbbbbbbbbbbb",
+	}
+	identify := func(devicePath string) (string, error) {
+		serial, ok := serials[filepath.Base(devicePath)]
+		if !ok {
+			return "", errors.New("identify failed")
+		}
+		return serial, nil
+	}
+
+	volumes, err := listNvmeVolumes(filepath.Join(dir, "nvme[0-9]*n1"), identify)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"vol-0aaaaaaaaaaaaaaaa": filepath.Join(dir, "nvme0n1"),
+		"vol-0bbbbbbbbbbbbbbbb": filepath.Join(dir, "nvme10n1"),
+	}, volumes)
+}
+
+func TestFindDevicePathWaitsForUdev(t *testing.T) {
+	testCases := []struct {
+		name             string
+		strategy         UdevSettleStrategy
+		timeout          time.Duration
+		expectSettles    bool
+		expectedMinDelay time.Duration
+	}{
+		{
+			name:     "none: looks up the device once",
+			strategy: UdevSettleStrategyNone,
+			timeout:  time.Second,
+		},
+		{
+			name:     "poll: no timeout looks up the device once",
+			strategy: UdevSettleStrategyPoll,
+		},
+		{
+			name:             "poll: waits until timeout",
+			strategy:         UdevSettleStrategyPoll,
+			timeout:          200 * time.Millisecond,
+			expectedMinDelay: 200 * time.Millisecond,
+		},
+		{
+			name:             "udevadm: settles between lookups",
+			strategy:         UdevSettleStrategyUdevadm,
+			timeout:          200 * time.Millisecond,
+			expectSettles:    true,
+			expectedMinDelay: 200 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var settles [][]string
+			fexec := &fakeexec.FakeExec{}
+			for range 10 {
+				fexec.CommandScript = append(fexec.CommandScript, func(cmd string, args ...string) utilexec.Cmd {
+					settles = append(settles, append([]string{cmd}, args...))
+					return fakeexec.InitFakeCmd(&fakeexec.FakeCmd{
+						CombinedOutputScript: []fakeexec.FakeAction{func() ([]byte, []byte, error) { return nil, nil, nil }},
+					}, cmd, args...)
+				})
+			}
+			m := &NodeMounter{
+				SafeFormatAndMount: &mount.SafeFormatAndMount{Interface: mount.New(""), Exec: fexec},
+				deviceResolution: DeviceResolutionOptions{
+					UdevSettleStrategy: tc.strategy,
+					Timeout:            tc.timeout,
+					PollInterval:       50 * time.Millisecond,
+				},
+			}
+
+			start := time.Now()
+			_, err := m.FindDevicePath(filepath.Join(t.TempDir(), "xvdba"), "vol-0fab1d5e3f72a5e23", "", "us-west-2")
+			require.Error(t, err)
+			assert.GreaterOrEqual(t, time.Since(start), tc.expectedMinDelay)
+			if tc.expectSettles {
+				assert.GreaterOrEqual(t, len(settles), 2)
+			} else {
+				assert.Empty(t, settles)
+			}
+			for _, settle := range settles {
+				assert.Equal(t, []string{"udevadm", "settle", "--timeout=1", "--exit-if-exists=/dev/disk/by-id/nvme-Amazon_Elastic_Block_Store_vol0fab1d5e3f72a5e23"}, settle)
+			}
+		})
+	}
+}
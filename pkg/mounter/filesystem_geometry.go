@@ -0,0 +1,88 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mounter
+
+import (
+	"sync"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
+	"k8s.io/klog/v2"
+)
+
+// filesystemGeometry is the size of a device and the size and UUID of the filesystem on it.
+type filesystemGeometry struct {
+	DeviceSizeBytes     int64
+	FilesystemSizeBytes int64
+	UUID                string
+}
+
+// filesystemGeometryCache remembers the geometry of the devices whose filesystem was last found to fill them, by
+// device path. As long as the geometry of a device is unchanged, its filesystem does not need to be checked or
+// resized again, which saves the blockdev, blkid, and dumpe2fs or xfs_io commands that NeedResize runs. Reading the
+// geometry itself only takes an ioctl and a read of the superblock. Any change of the geometry, such as the device
+// growing after the volume is expanded, invalidates the entry of the device.
+type filesystemGeometryCache struct {
+	read    func(devicePath string) (filesystemGeometry, error)
+	entries sync.Map
+}
+
+func newFilesystemGeometryCache() *filesystemGeometryCache {
+	return &filesystemGeometryCache{read: readFilesystemGeometry}
+}
+
+// fillsDevice returns whether the filesystem on devicePath is known to fill it, along with its current geometry.
+// The geometry is empty if it could not be read.
+func (c *filesystemGeometryCache) fillsDevice(devicePath, operation string) (bool, filesystemGeometry) {
+	if c == nil {
+		return false, filesystemGeometry{}
+	}
+	geometry, err := c.read(devicePath)
+	if err != nil {
+		klog.V(5).InfoS("[Debug] Could not read filesystem geometry", "devicePath", devicePath, "err", err)
+		return false, filesystemGeometry{}
+	}
+	cached, ok := c.entries.Load(devicePath)
+	hit := ok && cached.(filesystemGeometry) == geometry
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	metrics.Recorder().IncreaseCount(metrics.FilesystemGeometryCacheRequests, metrics.FilesystemGeometryCacheRequestsHelpText, map[string]string{"operation": operation, "result": result})
+	return hit, geometry
+}
+
+// store records that the filesystem on devicePath fills it. It rereads the geometry when it is unknown or the
+// filesystem was just resized.
+func (c *filesystemGeometryCache) store(devicePath string, geometry filesystemGeometry) {
+	if c == nil {
+		return
+	}
+	if geometry == (filesystemGeometry{}) {
+		var err error
+		if geometry, err = c.read(devicePath); err != nil {
+			c.entries.Delete(devicePath)
+			return
+		}
+	}
+	c.entries.Store(devicePath, geometry)
+}
+
+func (c *filesystemGeometryCache) invalidate(devicePath string) {
+	if c != nil {
+		c.entries.Delete(devicePath)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build linux
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mounter
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Superblock layouts, from fs/ext4/ext4.h and fs/xfs/libxfs/xfs_format.h.
+const (
+	ext4SuperblockOffset   = 1024
+	ext4MagicOffset        = 0x38
+	ext4Magic              = 0xEF53
+	ext4BlocksCountLo      = 0x4
+	ext4LogBlockSize       = 0x18
+	ext4FeatureIncompat    = 0x60
+	ext4UUIDOffset         = 0x68
+	ext4BlocksCountHi      = 0x150
+	ext4FeatureIncompat64  = 0x80
+	xfsMagic               = "XFSB"
+	xfsBlockSizeOffset     = 4
+	xfsDataBlocksOffset    = 8
+	xfsUUIDOffset          = 32
+	uuidSize               = 16
+	filesystemGeometrySize = ext4SuperblockOffset + ext4BlocksCountHi + 4
+)
+
+// readFilesystemGeometry reads the size of the block device at devicePath with an ioctl, and the size and UUID of
+// its ext2/3/4 or XFS filesystem from the superblock. It fails for other filesystems.
+func readFilesystemGeometry(devicePath string) (filesystemGeometry, error) {
+	f, err := os.Open(devicePath)
+	if err != nil {
+		return filesystemGeometry{}, err
+	}
+	defer f.Close()
+
+	var deviceSize uint64
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), unix.BLKGETSIZE64, uintptr(unsafe.Pointer(&deviceSize))); errno != 0 {
+		return filesystemGeometry{}, fmt.Errorf("could not get size of %s: %w", devicePath, errno)
+	}
+	fsSize, uuid, err := parseSuperblock(f)
+	if err != nil {
+		return filesystemGeometry{}, err
+	}
+	return filesystemGeometry{DeviceSizeBytes: int64(deviceSize), FilesystemSizeBytes: fsSize, UUID: uuid}, nil
+}
+
+// parseSuperblock returns the size and UUID of the ext2/3/4 or XFS filesystem at the start of r.
+func parseSuperblock(r io.ReaderAt) (int64, string, error) {
+	data := make([]byte, filesystemGeometrySize)
+	if _, err := r.ReadAt(data, 0); err != nil {
+		return 0, "", fmt.Errorf("could not read superblock: %w", err)
+	}
+
+	if string(data[:len(xfsMagic)]) == xfsMagic {
+		blockSize := int64(binary.BigEndian.Uint32(data[xfsBlockSizeOffset:]))
+		blocks := int64(binary.BigEndian.Uint64(data[xfsDataBlocksOffset:]))
+		return blocks * blockSize, hex.EncodeToString(data[xfsUUIDOffset : xfsUUIDOffset+uuidSize]), nil
+	}
+
+	sb := data[ext4SuperblockOffset:]
+	if binary.LittleEndian.Uint16(sb[ext4MagicOffset:]) == ext4Magic {
+		blockSize := int64(1024) << binary.LittleEndian.Uint32(sb[ext4LogBlockSize:])
+		blocks := int64(binary.LittleEndian.Uint32(sb[ext4BlocksCountLo:]))
+		if binary.LittleEndian.Uint32(sb[ext4FeatureIncompat:])&ext4FeatureIncompat64 != 0 {
+			blocks |= int64(binary.LittleEndian.Uint32(sb[ext4BlocksCountHi:])) << 32
+		}
+		return blocks * blockSize, hex.EncodeToString(sb[ext4UUIDOffset : ext4UUIDOffset+uuidSize]), nil
+	}
+
+	return 0, "", errors.New("unsupported filesystem")
+}
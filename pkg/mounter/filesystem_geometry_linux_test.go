@@ -0,0 +1,145 @@
+//go:build linux
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mounter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/mount-utils"
+	utilexec "k8s.io/utils/exec"
+	fakeexec "k8s.io/utils/exec/testing"
+)
+
+func TestParseSuperblock(t *testing.T) {
+	uuid := []byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0}
+
+	ext4 := make([]byte, 8192)
+	sb := ext4[ext4SuperblockOffset:]
+	binary.LittleEndian.PutUint16(sb[ext4MagicOffset:], ext4Magic)
+	binary.LittleEndian.PutUint32(sb[ext4BlocksCountLo:], 262144)
+	binary.LittleEndian.PutUint32(sb[ext4LogBlockSize:], 2)
+	copy(sb[ext4UUIDOffset:], uuid)
+	size, gotUUID, err := parseSuperblock(bytes.NewReader(ext4))
+	require.NoError(t, err)
+	assert.Equal(t, int64(262144*4096), size)
+	assert.Equal(t, "123456789abcdef0123456789abcdef0", gotUUID)
+
+	binary.LittleEndian.PutUint32(sb[ext4FeatureIncompat:], ext4FeatureIncompat64)
+	binary.LittleEndian.PutUint32(sb[ext4BlocksCountHi:], 1)
+	size, _, err = parseSuperblock(bytes.NewReader(ext4))
+	require.NoError(t, err)
+	assert.Equal(t, int64((1<<32)+262144)*4096, size)
+
+	xfs := make([]byte, 8192)
+	copy(xfs, xfsMagic)
+	binary.BigEndian.PutUint32(xfs[xfsBlockSizeOffset:], 4096)
+	binary.BigEndian.PutUint64(xfs[xfsDataBlocksOffset:], 2621440)
+	copy(xfs[xfsUUIDOffset:], uuid)
+	size, gotUUID, err = parseSuperblock(bytes.NewReader(xfs))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2621440*4096), size)
+	assert.Equal(t, "123456789abcdef0123456789abcdef0", gotUUID)
+
+	_, _, err = parseSuperblock(bytes.NewReader(make([]byte, 8192)))
+	require.Error(t, err)
+	_, _, err = parseSuperblock(bytes.NewReader(make([]byte, 512)))
+	require.Error(t, err)
+}
+
+func TestFilesystemGeometryCache(t *testing.T) {
+	geometry := filesystemGeometry{DeviceSizeBytes: 2 << 30, FilesystemSizeBytes: 2 << 30, UUID: "uuid"}
+	var readErr error
+	c := &filesystemGeometryCache{read: func(string) (filesystemGeometry, error) { return geometry, readErr }}
+
+	fills, got := c.fillsDevice("/dev/nvme1n1", "NeedResize")
+	assert.False(t, fills)
+	assert.Equal(t, geometry, got)
+
+	c.store("/dev/nvme1n1", got)
+	fills, _ = c.fillsDevice("/dev/nvme1n1", "NeedResize")
+	assert.True(t, fills)
+	fills, _ = c.fillsDevice("/dev/nvme2n1", "NeedResize")
+	assert.False(t, fills)
+
+	// The volume was expanded.
+	geometry.DeviceSizeBytes = 4 << 30
+	fills, _ = c.fillsDevice("/dev/nvme1n1", "Resize")
+	assert.False(t, fills)
+
+	c.store("/dev/nvme1n1", filesystemGeometry{})
+	fills, _ = c.fillsDevice("/dev/nvme1n1", "NeedResize")
+	assert.True(t, fills)
+
+	c.invalidate("/dev/nvme1n1")
+	fills, _ = c.fillsDevice("/dev/nvme1n1", "NeedResize")
+	assert.False(t, fills)
+
+	readErr = errors.New("not a block device")
+	c.store("/dev/nvme1n1", geometry)
+	fills, got = c.fillsDevice("/dev/nvme1n1", "NeedResize")
+	assert.False(t, fills)
+	assert.Equal(t, filesystemGeometry{}, got)
+
+	var nilCache *filesystemGeometryCache
+	fills, _ = nilCache.fillsDevice("/dev/nvme1n1", "NeedResize")
+	assert.False(t, fills)
+	nilCache.store("/dev/nvme1n1", geometry)
+	nilCache.invalidate("/dev/nvme1n1")
+}
+
+func TestResizeCached(t *testing.T) {
+	fcmd := fakeexec.FakeCmd{
+		CombinedOutputScript: []fakeexec.FakeAction{
+			func() ([]byte, []byte, error) { return []byte("0"), nil, nil },
+			func() ([]byte, []byte, error) { return []byte("DEVNAME=/dev/test\nTYPE=ext4\n"), nil, nil },
+			func() ([]byte, []byte, error) { return []byte("DEVNAME=/dev/test\nTYPE=ext4\n"), nil, nil },
+			func() ([]byte, []byte, error) { return nil, nil, nil },
+		},
+	}
+	action := func(cmd string, args ...string) utilexec.Cmd { return fakeexec.InitFakeCmd(&fcmd, cmd, args...) }
+	fexec := &fakeexec.FakeExec{CommandScript: []fakeexec.FakeCommandAction{action, action, action, action}}
+	geometry := filesystemGeometry{DeviceSizeBytes: 2 << 30, FilesystemSizeBytes: 2 << 30, UUID: "uuid"}
+	m := &NodeMounter{
+		SafeFormatAndMount: &mount.SafeFormatAndMount{Interface: mount.New(""), Exec: fexec},
+		geometryCache:      &filesystemGeometryCache{read: func(string) (filesystemGeometry, error) { return geometry, nil }},
+	}
+
+	needResize, err := m.NeedResize("/dev/test", "/mnt/test")
+	require.NoError(t, err)
+	assert.True(t, needResize)
+	resized, err := m.Resize("/dev/test", "/mnt/test")
+	require.NoError(t, err)
+	assert.True(t, resized)
+	assert.Equal(t, 4, fexec.CommandCalls)
+	assert.Equal(t, []string{"resize2fs", "/dev/test"}, fcmd.CombinedOutputLog[3])
+
+	// Neither checking again nor resizing runs any command while the geometry is unchanged.
+	needResize, err = m.NeedResize("/dev/test", "/mnt/test")
+	require.NoError(t, err)
+	assert.False(t, needResize)
+	resized, err = m.Resize("/dev/test", "/mnt/test")
+	require.NoError(t, err)
+	assert.False(t, resized)
+	assert.Equal(t, 4, fexec.CommandCalls)
+}
@@ -34,6 +34,36 @@ func (m *MockMounter) EXPECT() *MockMounterMockRecorder {
 	return m.recorder
 }
 
+// AttachedVolumes mocks base method.
+func (m *MockMounter) AttachedVolumes() (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachedVolumes")
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttachedVolumes indicates an expected call of AttachedVolumes.
+func (mr *MockMounterMockRecorder) AttachedVolumes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachedVolumes", reflect.TypeOf((*MockMounter)(nil).AttachedVolumes))
+}
+
+// BlockDevicePath mocks base method.
+func (m *MockMounter) BlockDevicePath(devicePath string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockDevicePath", devicePath)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockDevicePath indicates an expected call of BlockDevicePath.
+func (mr *MockMounterMockRecorder) BlockDevicePath(devicePath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockDevicePath", reflect.TypeOf((*MockMounter)(nil).BlockDevicePath), devicePath)
+}
+
 // CanSafelySkipMountPointCheck mocks base method.
 func (m *MockMounter) CanSafelySkipMountPointCheck() bool {
 	m.ctrl.T.Helper()
@@ -254,6 +284,21 @@ func (mr *MockMounterMockRecorder) Mount(source, target, fstype, options interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Mount", reflect.TypeOf((*MockMounter)(nil).Mount), source, target, fstype, options)
 }
 
+// MountHolders mocks base method.
+func (m *MockMounter) MountHolders(path string) ([]MountHolder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MountHolders", path)
+	ret0, _ := ret[0].([]MountHolder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MountHolders indicates an expected call of MountHolders.
+func (mr *MockMounterMockRecorder) MountHolders(path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MountHolders", reflect.TypeOf((*MockMounter)(nil).MountHolders), path)
+}
+
 // MountSensitive mocks base method.
 func (m *MockMounter) MountSensitive(source, target, fstype string, options, sensitiveOptions []string) error {
 	m.ctrl.T.Helper()
@@ -340,6 +385,20 @@ func (mr *MockMounterMockRecorder) PreparePublishTarget(target interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreparePublishTarget", reflect.TypeOf((*MockMounter)(nil).PreparePublishTarget), target)
 }
 
+// RemoveFscryptKey mocks base method.
+func (m *MockMounter) RemoveFscryptKey(dir string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFscryptKey", dir)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFscryptKey indicates an expected call of RemoveFscryptKey.
+func (mr *MockMounterMockRecorder) RemoveFscryptKey(dir interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFscryptKey", reflect.TypeOf((*MockMounter)(nil).RemoveFscryptKey), dir)
+}
+
 // Resize mocks base method.
 func (m *MockMounter) Resize(devicePath, deviceMountPath string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -355,6 +414,34 @@ func (mr *MockMounterMockRecorder) Resize(devicePath, deviceMountPath interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resize", reflect.TypeOf((*MockMounter)(nil).Resize), devicePath, deviceMountPath)
 }
 
+// SELinuxEnabled mocks base method.
+func (m *MockMounter) SELinuxEnabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SELinuxEnabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// SELinuxEnabled indicates an expected call of SELinuxEnabled.
+func (mr *MockMounterMockRecorder) SELinuxEnabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SELinuxEnabled", reflect.TypeOf((*MockMounter)(nil).SELinuxEnabled))
+}
+
+// SetupFscrypt mocks base method.
+func (m *MockMounter) SetupFscrypt(dir string, key []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetupFscrypt", dir, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetupFscrypt indicates an expected call of SetupFscrypt.
+func (mr *MockMounterMockRecorder) SetupFscrypt(dir, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetupFscrypt", reflect.TypeOf((*MockMounter)(nil).SetupFscrypt), dir, key)
+}
+
 // Unmount mocks base method.
 func (m *MockMounter) Unmount(target string) error {
 	m.ctrl.T.Helper()
@@ -22,6 +22,10 @@ limitations under the License.
 package mounter
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	mountutils "k8s.io/mount-utils"
 )
 
@@ -44,10 +48,34 @@ type Mounter interface {
 	Unstage(path string) error
 	Resize(devicePath, deviceMountPath string) (bool, error)
 	FindDevicePath(devicePath, volumeID, partition, region string) (string, error)
+	BlockDevicePath(devicePath string) (string, error)
 	PreparePublishTarget(target string) error
 	IsBlockDevice(fullPath string) (bool, error)
 	GetBlockSizeBytes(devicePath string) (int64, error)
 	GetVolumeStats(volumePath string) (VolumeStats, error)
+	SELinuxEnabled() bool
+	SetupFscrypt(dir string, key []byte) error
+	RemoveFscryptKey(dir string) error
+	MountHolders(path string) ([]MountHolder, error)
+	AttachedVolumes() (map[string]string, error)
+}
+
+// MountHolder is a process that keeps the filesystem mounted at a path busy, so that it cannot be unmounted.
+type MountHolder struct {
+	PID     int
+	Command string
+	// PodUID is the UID of the pod the process runs in. Empty for processes that do not run in a pod.
+	PodUID string
+	// Uses are how the process holds the filesystem: cwd, root, exe, fd, or mmap.
+	Uses []string
+}
+
+func (h MountHolder) String() string {
+	s := fmt.Sprintf("pid %d (%s", h.PID, h.Command)
+	if h.PodUID != "" {
+		s += " in pod " + h.PodUID
+	}
+	return s + ": " + strings.Join(h.Uses, ", ") + ")"
 }
 
 // VolumeStats holds volume stats returned by GetVolumeStats.
@@ -59,27 +87,107 @@ type VolumeStats struct {
 	AvailableInodes int64
 	TotalInodes     int64
 	UsedInodes      int64
+
+	// ProjectQuotaLimitBytes and ProjectQuotaUsedBytes are populated when volumePath
+	// is governed by an XFS project quota with a block limit. Both are zero otherwise.
+	ProjectQuotaLimitBytes int64
+	ProjectQuotaUsedBytes  int64
+}
+
+// MountNamespace selects the mount namespace in which NodeMounter runs mount and format commands.
+type MountNamespace string
+
+const (
+	// MountNamespaceContainer runs mount and format commands in the mount namespace of the driver container.
+	MountNamespaceContainer MountNamespace = "container"
+	// MountNamespaceHost runs mount and format commands in the mount namespace of the host with nsenter, for
+	// distros where mounts made in the container namespace leak or do not propagate back to the host.
+	MountNamespaceHost MountNamespace = "host"
+	// MountNamespaceAuto uses the host mount namespace when the host root filesystem is available and mounts
+	// made under the kubelet directory would not propagate to the host, and the container namespace otherwise.
+	MountNamespaceAuto MountNamespace = "auto"
+)
+
+// DefaultHostRootfsPath is the path where the root filesystem of the host is expected to be mounted in the driver container.
+const DefaultHostRootfsPath = "/rootfs"
+
+// UdevSettleStrategy selects how NodeMounter waits for udev to create the device of a newly attached volume.
+type UdevSettleStrategy string
+
+const (
+	// UdevSettleStrategyPoll looks the device up again every poll interval.
+	UdevSettleStrategyPoll UdevSettleStrategy = "poll"
+	// UdevSettleStrategyUdevadm runs `udevadm settle` before looking the device up again, so that lookups
+	// happen as soon as udev has processed its event queue.
+	UdevSettleStrategyUdevadm UdevSettleStrategy = "udevadm"
+	// UdevSettleStrategyNone looks the device up once and fails if it is not found.
+	UdevSettleStrategyNone UdevSettleStrategy = "none"
+)
+
+// Defaults of DeviceResolutionOptions.
+const (
+	DefaultUdevSettleTimeout = 10 * time.Second
+	DefaultUdevPollInterval  = 250 * time.Millisecond
+)
+
+// DeviceResolutionOptions configures how NodeMounter waits for the device of a volume to show up on Linux.
+type DeviceResolutionOptions struct {
+	UdevSettleStrategy UdevSettleStrategy
+	// Timeout is how long to wait for the device before failing. 0 means the device is looked up only once.
+	Timeout time.Duration
+	// PollInterval is the minimum time between two lookups of the device. 0 means DefaultUdevPollInterval.
+	PollInterval time.Duration
+}
+
+// NodeMounterOptions configures the mount namespace and the device resolution used by NodeMounter on Linux.
+type NodeMounterOptions struct {
+	MountNamespace MountNamespace
+	// HostRootfsPath is the path of the root filesystem of the host in the driver container.
+	HostRootfsPath string
+	// KubeletPath is a directory under which volumes are mounted, used to detect broken mount propagation.
+	KubeletPath string
+	// DeviceResolution configures how FindDevicePath waits for udev.
+	DeviceResolution DeviceResolutionOptions
 }
 
 // NodeMounter implements Mounter.
 // A superstruct of SafeFormatAndMount.
 type NodeMounter struct {
 	*mountutils.SafeFormatAndMount
+
+	deviceResolution DeviceResolutionOptions
+	geometryCache    *filesystemGeometryCache
+	// hostRootfsPath is where the host root filesystem is mounted, whose /proc shows the processes of the host.
+	hostRootfsPath string
 }
 
 // NewNodeMounter returns a new intsance of NodeMounter.
-func NewNodeMounter(hostprocess bool) (Mounter, error) {
+func NewNodeMounter(hostprocess bool, opts NodeMounterOptions) (Mounter, error) {
 	var safeMounter *mountutils.SafeFormatAndMount
 	var err error
 
 	if hostprocess {
 		safeMounter, err = NewSafeMounterV2()
 	} else {
-		safeMounter, err = NewSafeMounter()
+		var hostNamespace bool
+		hostNamespace, err = useHostMountNamespace(opts)
+		if err != nil {
+			return nil, err
+		}
+		if hostNamespace {
+			safeMounter, err = NewHostNamespaceSafeMounter(opts.HostRootfsPath)
+		} else {
+			safeMounter, err = NewSafeMounter()
+		}
 	}
 
 	if err != nil {
 		return nil, err
 	}
-	return &NodeMounter{safeMounter}, nil
+	return &NodeMounter{
+		SafeFormatAndMount: safeMounter,
+		deviceResolution:   opts.DeviceResolution,
+		geometryCache:      newFilesystemGeometryCache(),
+		hostRootfsPath:     opts.HostRootfsPath,
+	}, nil
 }
@@ -0,0 +1,145 @@
+//go:build linux
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mounter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+)
+
+// fscryptAddKeyArg is struct fscrypt_add_key_arg followed by the raw key, as expected by FS_IOC_ADD_ENCRYPTION_KEY.
+type fscryptAddKeyArg struct {
+	unix.FscryptAddKeyArg
+	raw [unix.FSCRYPT_MAX_KEY_SIZE]byte
+}
+
+// SetupFscrypt adds key to the keyring of the filesystem containing dir and returns once dir is encrypted with it
+// using a v2 fscrypt policy, creating dir if needed. It fails if dir is already encrypted with a different key.
+// The filesystem must be ext4 formatted with the encrypt feature.
+func (m *NodeMounter) SetupFscrypt(dir string, key []byte) error {
+	if len(key) != unix.FSCRYPT_MAX_KEY_SIZE {
+		return fmt.Errorf("fscrypt key must be %d bytes, got %d", unix.FSCRYPT_MAX_KEY_SIZE, len(key))
+	}
+
+	fs, err := os.Open(filepath.Dir(dir))
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+	arg := fscryptAddKeyArg{}
+	arg.Key_spec.Type = unix.FSCRYPT_KEY_SPEC_TYPE_IDENTIFIER
+	arg.Raw_size = uint32(len(key))
+	copy(arg.raw[:], key)
+	err = fscryptIoctl(fs, unix.FS_IOC_ADD_ENCRYPTION_KEY, unsafe.Pointer(&arg))
+	clear(arg.raw[:])
+	if err != nil {
+		return fmt.Errorf("could not add fscrypt key: %w", err)
+	}
+	identifier := arg.Key_spec.U[:unix.FSCRYPT_KEY_IDENTIFIER_SIZE]
+
+	if err := os.Mkdir(dir, 0o755); err != nil && !errors.Is(err, os.ErrExist) {
+		return err
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	existing, err := fscryptPolicyIdentifier(d)
+	switch {
+	case errors.Is(err, unix.ENODATA):
+		policy := unix.FscryptPolicyV2{
+			Version:                   unix.FSCRYPT_POLICY_V2,
+			Contents_encryption_mode:  unix.FSCRYPT_MODE_AES_256_XTS,
+			Filenames_encryption_mode: unix.FSCRYPT_MODE_AES_256_CTS,
+			Flags:                     unix.FSCRYPT_POLICY_FLAGS_PAD_32,
+		}
+		copy(policy.Master_key_identifier[:], identifier)
+		if err := fscryptIoctl(d, unix.FS_IOC_SET_ENCRYPTION_POLICY, unsafe.Pointer(&policy)); err != nil {
+			return fmt.Errorf("could not set fscrypt policy on %s: %w", dir, err)
+		}
+		klog.V(4).InfoS("SetupFscrypt: encrypted directory", "dir", dir)
+		return nil
+	case err != nil:
+		return fmt.Errorf("could not get fscrypt policy of %s: %w", dir, err)
+	case !bytes.Equal(existing, identifier):
+		return fmt.Errorf("%s is encrypted with a different fscrypt key", dir)
+	}
+	return nil
+}
+
+// RemoveFscryptKey removes the key that dir is encrypted with from the keyring of its filesystem, so that its
+// contents can no longer be read until SetupFscrypt is called again. It does nothing if dir does not exist or is not encrypted.
+func (m *NodeMounter) RemoveFscryptKey(dir string) error {
+	d, err := os.Open(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	identifier, err := fscryptPolicyIdentifier(d)
+	if errors.Is(err, unix.ENODATA) || errors.Is(err, unix.ENOTTY) || errors.Is(err, unix.EOPNOTSUPP) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("could not get fscrypt policy of %s: %w", dir, err)
+	}
+
+	arg := unix.FscryptRemoveKeyArg{}
+	arg.Key_spec.Type = unix.FSCRYPT_KEY_SPEC_TYPE_IDENTIFIER
+	copy(arg.Key_spec.U[:], identifier)
+	if err := fscryptIoctl(d, unix.FS_IOC_REMOVE_ENCRYPTION_KEY, unsafe.Pointer(&arg)); err != nil && !errors.Is(err, unix.ENOKEY) {
+		return fmt.Errorf("could not remove fscrypt key: %w", err)
+	}
+	return nil
+}
+
+// fscryptPolicyIdentifier returns the master key identifier of the v2 fscrypt policy of f. It returns ENODATA
+// if f is not encrypted.
+func fscryptPolicyIdentifier(f *os.File) ([]byte, error) {
+	arg := unix.FscryptGetPolicyExArg{Size: uint64(unsafe.Sizeof(unix.FscryptGetPolicyExArg{}.Policy))}
+	if err := fscryptIoctl(f, unix.FS_IOC_GET_ENCRYPTION_POLICY_EX, unsafe.Pointer(&arg)); err != nil {
+		return nil, err
+	}
+	return parseFscryptPolicy(arg.Policy[:arg.Size])
+}
+
+func parseFscryptPolicy(policy []byte) ([]byte, error) {
+	if len(policy) < int(unsafe.Sizeof(unix.FscryptPolicyV2{})) || policy[0] != unix.FSCRYPT_POLICY_V2 {
+		return nil, errors.New("unsupported fscrypt policy version, only v2 policies are supported")
+	}
+	v2 := (*unix.FscryptPolicyV2)(unsafe.Pointer(&policy[0]))
+	return bytes.Clone(v2.Master_key_identifier[:]), nil
+}
+
+func fscryptIoctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
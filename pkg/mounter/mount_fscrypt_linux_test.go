@@ -0,0 +1,57 @@
+//go:build linux
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mounter
+
+import (
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestSetupFscryptInvalidKey(t *testing.T) {
+	m := &NodeMounter{}
+	dir := filepath.Join(t.TempDir(), "data")
+	require.Error(t, m.SetupFscrypt(dir, make([]byte, 32)))
+	assert.NoDirExists(t, dir)
+}
+
+func TestRemoveFscryptKeyMissingDir(t *testing.T) {
+	m := &NodeMounter{}
+	require.NoError(t, m.RemoveFscryptKey(filepath.Join(t.TempDir(), "missing")))
+}
+
+func TestParseFscryptPolicy(t *testing.T) {
+	policy := unix.FscryptPolicyV2{Version: unix.FSCRYPT_POLICY_V2}
+	for i := range policy.Master_key_identifier {
+		policy.Master_key_identifier[i] = byte(i)
+	}
+	raw := unsafe.Slice((*byte)(unsafe.Pointer(&policy)), unsafe.Sizeof(policy))
+
+	identifier, err := parseFscryptPolicy(raw)
+	require.NoError(t, err)
+	assert.Equal(t, policy.Master_key_identifier[:], identifier)
+
+	raw[0] = unix.FSCRYPT_POLICY_V1
+	_, err = parseFscryptPolicy(raw)
+	require.Error(t, err)
+}
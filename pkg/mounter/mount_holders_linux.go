@@ -0,0 +1,120 @@
+//go:build linux
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mounter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// podUIDPattern matches the pod UID in the cgroup of pod processes, with dashes (cgroupfs) or underscores (systemd).
+var podUIDPattern = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+
+// MountHolders returns the processes that use the filesystem mounted at path, like `fuser -m`. Processes are found
+// in the /proc of the host when the host root filesystem is available, and otherwise only among the processes of the
+// driver container. Files are matched by device number, which unlike paths does not depend on the mount namespace
+// of the process.
+func (m *NodeMounter) MountHolders(path string) ([]MountHolder, error) {
+	procRoot := "/proc"
+	if m.hostRootfsPath != "" {
+		if _, err := os.Stat(filepath.Join(m.hostRootfsPath, "proc", "1")); err == nil {
+			procRoot = filepath.Join(m.hostRootfsPath, "proc")
+		}
+	}
+	return findMountHolders(procRoot, path)
+}
+
+func findMountHolders(procRoot, path string) ([]MountHolder, error) {
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return nil, fmt.Errorf("could not stat %s: %w", path, err)
+	}
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("could not list processes in %s: %w", procRoot, err)
+	}
+
+	var holders []MountHolder
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		// Processes that exit or cannot be inspected while scanning are skipped
+		dir := filepath.Join(procRoot, entry.Name())
+		uses := processUses(dir, st.Dev)
+		if len(uses) == 0 {
+			continue
+		}
+		comm, _ := os.ReadFile(filepath.Join(dir, "comm"))
+		holder := MountHolder{PID: pid, Command: strings.TrimSpace(string(comm)), Uses: uses}
+		if cgroup, err := os.ReadFile(filepath.Join(dir, "cgroup")); err == nil {
+			if match := podUIDPattern.FindSubmatch(cgroup); match != nil {
+				holder.PodUID = strings.ReplaceAll(string(match[1]), "_", "-")
+			}
+		}
+		holders = append(holders, holder)
+	}
+	return holders, nil
+}
+
+// processUses returns how the process whose /proc directory is dir uses files of the device dev.
+func processUses(dir string, dev uint64) []string {
+	var uses []string
+	for _, link := range []string{"cwd", "root", "exe"} {
+		if onDevice(filepath.Join(dir, link), dev) {
+			uses = append(uses, link)
+		}
+	}
+
+	fds, _ := os.ReadDir(filepath.Join(dir, "fd"))
+	for _, fd := range fds {
+		if onDevice(filepath.Join(dir, "fd", fd.Name()), dev) {
+			uses = append(uses, "fd")
+			break
+		}
+	}
+
+	if maps, err := os.Open(filepath.Join(dir, "maps")); err == nil {
+		defer maps.Close()
+		device := fmt.Sprintf("%02x:%02x", unix.Major(dev), unix.Minor(dev))
+		scanner := bufio.NewScanner(maps)
+		for scanner.Scan() {
+			// address perms offset dev inode path
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 5 && fields[3] == device && fields[4] != "0" {
+				uses = append(uses, "mmap")
+				break
+			}
+		}
+	}
+	return uses
+}
+
+func onDevice(path string, dev uint64) bool {
+	var st unix.Stat_t
+	return unix.Stat(path, &st) == nil && st.Dev == dev
+}
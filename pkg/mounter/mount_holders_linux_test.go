@@ -0,0 +1,68 @@
+//go:build linux
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mounter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestFindMountHolders(t *testing.T) {
+	target := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(target, "data"), nil, 0o600))
+	var st unix.Stat_t
+	require.NoError(t, unix.Stat(target, &st))
+
+	procRoot := t.TempDir()
+	process := func(pid, comm, cgroup, maps string, links map[string]string) {
+		dir := filepath.Join(procRoot, pid)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "fd"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "comm"), []byte(comm+"\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "cgroup"), []byte(cgroup), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "maps"), []byte(maps), 0o600))
+		for link, dest := range links {
+			require.NoError(t, os.Symlink(dest, filepath.Join(dir, link)))
+		}
+	}
+	device := fmt.Sprintf("%02x:%02x", unix.Major(st.Dev), unix.Minor(st.Dev))
+
+	process("10", "bash", "0::/user.slice/session-1.scope\n", "", map[string]string{"cwd": target, "fd/0": "/dev/null"})
+	process("20", "python", "0::/kubepods.slice/kubepods-pod3f2a8c1e_0b4d_4e5f_9a6b_7c8d9e0f1a2b.slice/cri-containerd-1.scope\n",
+		"7f0000000000-7f0000001000 r--p 00000000 "+device+" 12 "+filepath.Join(target, "data")+"\n",
+		map[string]string{"fd/3": filepath.Join(target, "data")})
+	process("30", "sleep", "", "7f0000000000-7f0000001000 rw-p 00000000 "+device+" 0\n", map[string]string{"cwd": "/proc"})
+	require.NoError(t, os.MkdirAll(filepath.Join(procRoot, "self"), 0o755))
+
+	holders, err := findMountHolders(procRoot, target)
+	require.NoError(t, err)
+	assert.Equal(t, []MountHolder{
+		{PID: 10, Command: "bash", Uses: []string{"cwd"}},
+		{PID: 20, Command: "python", PodUID: "3f2a8c1e-0b4d-4e5f-9a6b-7c8d9e0f1a2b", Uses: []string{"fd", "mmap"}},
+	}, holders)
+	assert.Equal(t, "pid 20 (python in pod 3f2a8c1e-0b4d-4e5f-9a6b-7c8d9e0f1a2b: fd, mmap)", holders[1].String())
+
+	_, err = findMountHolders(procRoot, filepath.Join(target, "missing"))
+	require.Error(t, err)
+}
@@ -0,0 +1,221 @@
+//go:build linux
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mounter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+	mountutils "k8s.io/mount-utils"
+	utilexec "k8s.io/utils/exec"
+	"k8s.io/utils/nsenter"
+)
+
+const (
+	defaultKubeletPath  = "/var/lib/kubelet"
+	selfMountNsPath     = "/proc/self/ns/mnt"
+	selfMountInfoPath   = "/proc/self/mountinfo"
+	hostMountNsPath     = "/proc/1/ns/mnt"
+	hostMountsPath      = "/proc/1/mounts"
+	hostMountInfoPath   = "/proc/1/mountinfo"
+	sharedPeerGroupFlag = "shared:"
+)
+
+// NewHostNamespaceSafeMounter returns a SafeFormatAndMount that runs mount, format, and resize commands in the
+// mount namespace of the host, through nsenter and the host root filesystem mounted at hostRootfsPath.
+func NewHostNamespaceSafeMounter(hostRootfsPath string) (*mountutils.SafeFormatAndMount, error) {
+	ne, err := nsenter.NewNsenter(hostRootfsPath, utilexec.New())
+	if err != nil {
+		return nil, fmt.Errorf("could not use host mount namespace through %s: %w", hostRootfsPath, err)
+	}
+	return &mountutils.SafeFormatAndMount{
+		Interface: &hostNamespaceMounter{ne: ne, hostRootfsPath: hostRootfsPath},
+		Exec:      ne,
+	}, nil
+}
+
+// useHostMountNamespace returns whether NodeMounter should run in the host mount namespace.
+func useHostMountNamespace(opts NodeMounterOptions) (bool, error) {
+	switch opts.MountNamespace {
+	case "", MountNamespaceContainer:
+		return false, nil
+	case MountNamespaceHost:
+		return true, nil
+	case MountNamespaceAuto:
+		kubeletPath := opts.KubeletPath
+		if kubeletPath == "" {
+			kubeletPath = defaultKubeletPath
+		}
+		host, reason := detectHostMountNamespace(filepath.Join(opts.HostRootfsPath, hostMountNsPath), selfMountNsPath, selfMountInfoPath, kubeletPath)
+		klog.InfoS("Selected mount namespace", "hostMountNamespace", host, "reason", reason)
+		return host, nil
+	default:
+		return false, fmt.Errorf("unknown mount namespace %q", opts.MountNamespace)
+	}
+}
+
+// detectHostMountNamespace returns whether mounts must be made in the host mount namespace, and why. This is the
+// case when the host mount namespace is reachable, the driver is not already running in it, and the mount
+// containing kubeletPath is not shared with the host, so mounts made in the container would not propagate.
+func detectHostMountNamespace(hostNsPath, selfNsPath, selfMountInfoPath, kubeletPath string) (bool, string) {
+	hostNs, err := os.Readlink(hostNsPath)
+	if err != nil {
+		return false, "host mount namespace not available"
+	}
+	if selfNs, err := os.Readlink(selfNsPath); err == nil && selfNs == hostNs {
+		return false, "already running in host mount namespace"
+	}
+
+	mountInfos, err := mountutils.ParseMountInfo(selfMountInfoPath)
+	if err != nil {
+		return false, fmt.Sprintf("could not read mount info: %v", err)
+	}
+	var kubeletMount *mountutils.MountInfo
+	for i := range mountInfos {
+		mi := &mountInfos[i]
+		if !isPathUnder(kubeletPath, mi.MountPoint) {
+			continue
+		}
+		if kubeletMount == nil || len(mi.MountPoint) >= len(kubeletMount.MountPoint) {
+			kubeletMount = mi
+		}
+	}
+	if kubeletMount == nil {
+		return false, kubeletPath + " is not mounted"
+	}
+	for _, field := range kubeletMount.OptionalFields {
+		if strings.HasPrefix(field, sharedPeerGroupFlag) {
+			return false, kubeletMount.MountPoint + " propagates mounts to the host"
+		}
+	}
+	return true, kubeletMount.MountPoint + " does not propagate mounts to the host"
+}
+
+func isPathUnder(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// hostNamespaceMounter implements mountutils.Interface by running mount and umount in the host mount namespace
+// and reading the mount table of the host. Mount targets must have the same path in the container and on the host.
+type hostNamespaceMounter struct {
+	ne             *nsenter.NSEnter
+	hostRootfsPath string
+}
+
+var _ mountutils.Interface = &hostNamespaceMounter{}
+
+func (m *hostNamespaceMounter) Mount(source string, target string, fstype string, options []string) error {
+	return m.MountSensitive(source, target, fstype, options, nil)
+}
+
+// MountSensitive mounts in a transient systemd scope when the host runs systemd, so that the mount survives
+// restarts of the driver container like mounts made by the host mount binary would.
+func (m *hostNamespaceMounter) MountSensitive(source string, target string, fstype string, options []string, sensitiveOptions []string) error {
+	args, logStr := mountutils.MakeMountArgsSensitiveWithMountFlags(source, target, fstype, options, sensitiveOptions, nil)
+	cmd := m.ne.AbsHostPath("mount")
+	if systemdRunPath, ok := m.ne.SupportsSystemd(); ok {
+		cmd, args, logStr = mountutils.AddSystemdScopeSensitive(systemdRunPath, target, cmd, args, logStr)
+	}
+	return m.run(cmd, args, logStr)
+}
+
+func (m *hostNamespaceMounter) MountSensitiveWithoutSystemd(source string, target string, fstype string, options []string, sensitiveOptions []string) error {
+	return m.MountSensitiveWithoutSystemdWithMountFlags(source, target, fstype, options, sensitiveOptions, nil)
+}
+
+func (m *hostNamespaceMounter) MountSensitiveWithoutSystemdWithMountFlags(source string, target string, fstype string, options []string, sensitiveOptions []string, mountFlags []string) error {
+	args, logStr := mountutils.MakeMountArgsSensitiveWithMountFlags(source, target, fstype, options, sensitiveOptions, mountFlags)
+	return m.run(m.ne.AbsHostPath("mount"), args, logStr)
+}
+
+func (m *hostNamespaceMounter) run(cmd string, args []string, logStr string) error {
+	klog.V(4).InfoS("Mounting in host mount namespace", "command", cmd, "args", logStr)
+	output, err := m.ne.Command(cmd, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("mount failed: %w\nMounting command: %s\nMounting arguments: %s\nOutput: %s", err, cmd, logStr, string(output))
+	}
+	return nil
+}
+
+func (m *hostNamespaceMounter) Unmount(target string) error {
+	klog.V(4).InfoS("Unmounting in host mount namespace", "target", target)
+	output, err := m.ne.Command(m.ne.AbsHostPath("umount"), target).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("unmount failed: %w\nUnmounting arguments: %s\nOutput: %s", err, target, string(output))
+	}
+	return nil
+}
+
+func (m *hostNamespaceMounter) List() ([]mountutils.MountPoint, error) {
+	return mountutils.ListProcMounts(filepath.Join(m.hostRootfsPath, hostMountsPath))
+}
+
+// IsLikelyNotMountPoint checks the mount table of the host, because the mount points of the container may differ
+// from it when propagation is broken.
+func (m *hostNamespaceMounter) IsLikelyNotMountPoint(file string) (bool, error) {
+	if _, err := os.Stat(file); err != nil {
+		return true, err
+	}
+	mountPoints, err := m.List()
+	if err != nil {
+		return true, err
+	}
+	file = filepath.Clean(file)
+	for _, mp := range mountPoints {
+		if filepath.Clean(mp.Path) == file {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (m *hostNamespaceMounter) CanSafelySkipMountPointCheck() bool {
+	return false
+}
+
+func (m *hostNamespaceMounter) IsMountPoint(file string) (bool, error) {
+	notMnt, err := m.IsLikelyNotMountPoint(file)
+	if err != nil && errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return !notMnt, err
+}
+
+func (m *hostNamespaceMounter) GetMountRefs(pathname string) ([]string, error) {
+	pathExists, pathErr := mountutils.PathExists(pathname)
+	switch {
+	case !pathExists:
+		return []string{}, nil
+	case mountutils.IsCorruptedMnt(pathErr):
+		klog.InfoS("GetMountRefs found corrupted mount, treating as unmounted path", "path", pathname)
+		return []string{}, nil
+	case pathErr != nil:
+		return nil, fmt.Errorf("error checking path %s: %w", pathname, pathErr)
+	}
+	realpath, err := filepath.EvalSymlinks(pathname)
+	if err != nil {
+		return nil, err
+	}
+	return mountutils.SearchMountPoints(realpath, filepath.Join(m.hostRootfsPath, hostMountInfoPath))
+}
@@ -0,0 +1,178 @@
+//go:build linux
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mounter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	mountutils "k8s.io/mount-utils"
+	utilexec "k8s.io/utils/exec"
+	fakeexec "k8s.io/utils/exec/testing"
+	"k8s.io/utils/nsenter"
+)
+
+func TestDetectHostMountNamespace(t *testing.T) {
+	const (
+		sharedKubeletMount  = "36 35 98:0 / /var/lib/kubelet rw,noatime shared:1 - ext4 /dev/root rw\n"
+		privateKubeletMount = "36 35 98:0 / /var/lib/kubelet rw,noatime - ext4 /dev/root rw\n"
+		rootMount           = "35 1 98:0 / / rw,noatime shared:2 - overlay overlay rw\n"
+	)
+	testCases := []struct {
+		name         string
+		hostNs       string
+		selfNs       string
+		mountInfo    string
+		expectedHost bool
+	}{
+		{
+			name:      "container: host namespace not available",
+			selfNs:    "mnt:[2]",
+			mountInfo: rootMount + privateKubeletMount,
+		},
+		{
+			name:      "container: already in host namespace",
+			hostNs:    "mnt:[1]",
+			selfNs:    "mnt:[1]",
+			mountInfo: rootMount + privateKubeletMount,
+		},
+		{
+			name:      "container: kubelet directory is shared",
+			hostNs:    "mnt:[1]",
+			selfNs:    "mnt:[2]",
+			mountInfo: rootMount + sharedKubeletMount,
+		},
+		{
+			name:         "host: kubelet directory is private",
+			hostNs:       "mnt:[1]",
+			selfNs:       "mnt:[2]",
+			mountInfo:    privateKubeletMount + rootMount,
+			expectedHost: true,
+		},
+		{
+			name:      "container: kubelet directory is not mounted",
+			hostNs:    "mnt:[1]",
+			selfNs:    "mnt:[2]",
+			mountInfo: "36 35 98:0 / /var/lib/kube rw,noatime - ext4 /dev/root rw\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			hostNsPath := filepath.Join(dir, "host-ns")
+			if tc.hostNs != "" {
+				require.NoError(t, os.Symlink(tc.hostNs, hostNsPath))
+			}
+			selfNsPath := filepath.Join(dir, "self-ns")
+			require.NoError(t, os.Symlink(tc.selfNs, selfNsPath))
+			mountInfoPath := filepath.Join(dir, "mountinfo")
+			require.NoError(t, os.WriteFile(mountInfoPath, []byte(tc.mountInfo), 0o600))
+
+			host, reason := detectHostMountNamespace(hostNsPath, selfNsPath, mountInfoPath, "/var/lib/kubelet/plugins/kubernetes.io/csi/ebs.csi.aws.com/")
+			assert.Equal(t, tc.expectedHost, host, reason)
+		})
+	}
+}
+
+func TestUseHostMountNamespace(t *testing.T) {
+	host, err := useHostMountNamespace(NodeMounterOptions{})
+	require.NoError(t, err)
+	assert.False(t, host)
+
+	host, err = useHostMountNamespace(NodeMounterOptions{MountNamespace: MountNamespaceHost})
+	require.NoError(t, err)
+	assert.True(t, host)
+
+	host, err = useHostMountNamespace(NodeMounterOptions{MountNamespace: MountNamespaceAuto, HostRootfsPath: t.TempDir()})
+	require.NoError(t, err)
+	assert.False(t, host)
+
+	_, err = useHostMountNamespace(NodeMounterOptions{MountNamespace: "shared"})
+	require.Error(t, err)
+}
+
+// newFakeHostRootfs returns a host root filesystem with the binaries required by nsenter and the given mount table.
+func newFakeHostRootfs(t *testing.T, mounts string) string {
+	t.Helper()
+	rootfs := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(rootfs, "bin"), 0o755))
+	for _, binary := range []string{"mount", "findmnt", "umount", "stat", "touch", "mkdir", "sh", "chmod", "realpath"} {
+		require.NoError(t, os.WriteFile(filepath.Join(rootfs, "bin", binary), nil, 0o600))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(rootfs, "proc", "1"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootfs, hostMountsPath), []byte(mounts), 0o600))
+	return rootfs
+}
+
+func TestHostNamespaceMounter(t *testing.T) {
+	target := t.TempDir()
+	rootfs := newFakeHostRootfs(t, "/dev/nvme1n1 "+target+" ext4 rw,relatime 0 0\n")
+
+	var commands [][]string
+	fcmd := fakeexec.FakeCmd{
+		CombinedOutputScript: []fakeexec.FakeAction{
+			func() ([]byte, []byte, error) { return nil, nil, nil },
+			func() ([]byte, []byte, error) { return nil, nil, nil },
+		},
+	}
+	fexec := &fakeexec.FakeExec{
+		CommandScript: []fakeexec.FakeCommandAction{
+			func(cmd string, args ...string) utilexec.Cmd {
+				commands = append(commands, append([]string{cmd}, args...))
+				return fakeexec.InitFakeCmd(&fcmd, cmd, args...)
+			},
+			func(cmd string, args ...string) utilexec.Cmd {
+				commands = append(commands, append([]string{cmd}, args...))
+				return fakeexec.InitFakeCmd(&fcmd, cmd, args...)
+			},
+		},
+	}
+	ne, err := nsenter.NewNsenter(rootfs, fexec)
+	require.NoError(t, err)
+	m := &hostNamespaceMounter{ne: ne, hostRootfsPath: rootfs}
+
+	require.NoError(t, m.Mount("/dev/nvme1n1", target, "ext4", []string{"defaults"}))
+	require.NoError(t, m.Unmount(target))
+	hostNs := "--mount=" + filepath.Join(rootfs, hostMountNsPath)
+	assert.Equal(t, [][]string{
+		{"nsenter", hostNs, "--", "/bin/mount", "-t", "ext4", "-o", "defaults", "/dev/nvme1n1", target},
+		{"nsenter", hostNs, "--", "/bin/umount", target},
+	}, commands)
+
+	notMnt, err := m.IsLikelyNotMountPoint(target)
+	require.NoError(t, err)
+	assert.False(t, notMnt)
+
+	notMnt, err = m.IsLikelyNotMountPoint(t.TempDir())
+	require.NoError(t, err)
+	assert.True(t, notMnt)
+
+	isMnt, err := m.IsMountPoint(filepath.Join(target, "missing"))
+	require.NoError(t, err)
+	assert.False(t, isMnt)
+}
+
+func TestSELinuxEnabledHostNamespace(t *testing.T) {
+	m := &NodeMounter{SafeFormatAndMount: &mountutils.SafeFormatAndMount{Interface: &hostNamespaceMounter{}}}
+	assert.True(t, m.SELinuxEnabled())
+}
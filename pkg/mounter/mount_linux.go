@@ -19,6 +19,7 @@ limitations under the License.
 package mounter
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
@@ -27,9 +28,12 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/metrics"
 	"golang.org/x/sys/unix"
 	"k8s.io/klog/v2"
 	mountutils "k8s.io/mount-utils"
@@ -39,8 +43,17 @@ import (
 const (
 	nvmeDiskPartitionSuffix = "p"
 	diskPartitionSuffix     = ""
+
+	// selinuxEnforcePath exists when selinuxfs is mounted, which is how libselinux detects that SELinux is enabled.
+	selinuxEnforcePath = "/sys/fs/selinux/enforce"
+
+	// xfsSuperMagic is the f_type reported by statfs(2) for XFS filesystems.
+	xfsSuperMagic = 0x58465342
 )
 
+// Matches the output of `xfs_io -c lsproj`, e.g. "projid = 42".
+var xfsProjIDRegex = regexp.MustCompile(`projid\s*=\s*(\d+)`)
+
 func NewSafeMounter() (*mountutils.SafeFormatAndMount, error) {
 	return &mountutils.SafeFormatAndMount{
 		Interface: mountutils.New(""),
@@ -55,44 +68,43 @@ func NewSafeMounterV2() (*mountutils.SafeFormatAndMount, error) {
 // FindDevicePath finds path of device and verifies its existence
 // if the device is not nvme, return the path directly
 // if the device is nvme, finds and returns the nvme device path eg. /dev/nvme1n1.
+// The device may take a while to show up after attachment on busy nodes, so it is looked up again until it is found
+// or the udev settle timeout expires.
 func (m *NodeMounter) FindDevicePath(devicePath, volumeID, partition, region string) (string, error) {
 	strippedVolumeName := strings.ReplaceAll(volumeID, "-", "")
-	canonicalDevicePath := ""
-
-	// If the given path exists, the device MAY be nvme. Further, it MAY be a
-	// symlink to the nvme device path like:
-	// | $ stat /dev/xvdba
-	// | File: ‘/dev/xvdba’ -> ‘nvme1n1’
-	// Since these are maybes, not guarantees, the search for the nvme device
-	// path below must happen and must rely on volume ID
-	exists, err := m.PathExists(devicePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to check if path %q exists: %w", devicePath, err)
-	}
-
-	if exists {
-		stat, lstatErr := os.Lstat(devicePath)
-		if lstatErr != nil {
-			return "", fmt.Errorf("failed to lstat %q: %w", devicePath, lstatErr)
+	start := time.Now()
+	deadline := start.Add(m.deviceResolution.Timeout)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		var canonicalDevicePath, method string
+		canonicalDevicePath, method, err = m.resolveDevicePath(devicePath, strippedVolumeName)
+		if canonicalDevicePath != "" {
+			observeDeviceResolution(method, start)
+			klog.V(4).InfoS("Found device", "devicePath", devicePath, "volumeID", volumeID, "canonicalDevicePath", canonicalDevicePath, "method", method, "attempts", attempt, "duration", time.Since(start))
+			return m.appendPartition(canonicalDevicePath, partition), nil
 		}
-
-		if stat.Mode()&os.ModeSymlink == os.ModeSymlink {
-			canonicalDevicePath, err = filepath.EvalSymlinks(devicePath)
-			if err != nil {
-				return "", fmt.Errorf("failed to evaluate symlink %q: %w", devicePath, err)
-			}
-		} else {
-			canonicalDevicePath = devicePath
+		if m.deviceResolution.UdevSettleStrategy == UdevSettleStrategyNone || !time.Now().Before(deadline) {
+			break
 		}
+		klog.V(5).InfoS("[Debug] Device not found, waiting for udev", "devicePath", devicePath, "volumeID", volumeID, "attempt", attempt, "err", err)
+		m.waitForUdev(filepath.Join(nvmeByIDPath, nvmeByIDPrefix+strippedVolumeName), deadline)
+	}
 
-		klog.V(5).InfoS("[Debug] The canonical device path was resolved", "devicePath", devicePath, "cacanonicalDevicePath", canonicalDevicePath)
-		if err = verifyVolumeSerialMatch(canonicalDevicePath, strippedVolumeName, execRunner); err != nil {
-			return "", err
-		}
-		return m.appendPartition(canonicalDevicePath, partition), nil
+	observeDeviceResolution(deviceResolutionNotFound, start)
+	if m.deviceResolution.Timeout > 0 {
+		metrics.Recorder().IncreaseCount(metrics.DeviceResolutionTimeouts, metrics.DeviceResolutionTimeoutsHelpText, map[string]string{"strategy": string(m.deviceResolution.UdevSettleStrategy)})
 	}
+	if err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no device path for device %q volume %q found", devicePath, volumeID)
+}
 
-	klog.V(5).InfoS("[Debug] Falling back to nvme volume ID lookup", "devicePath", devicePath)
+// resolveDevicePath looks up the device of a volume once, and returns its canonical path and the method that found
+// it, or "" and the errors of each method if it was not found.
+func (m *NodeMounter) resolveDevicePath(devicePath, strippedVolumeName string) (string, string, error) {
+	var errs []error
 
 	// AWS recommends identifying devices by volume ID
 	// (https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/nvme-ebs-volumes.html),
@@ -100,32 +112,54 @@ func (m *NodeMounter) FindDevicePath(devicePath, volumeID, partition, region str
 	// which AWS presents NVME devices under /dev/disk/by-id/. For example,
 	// vol-0fab1d5e3f72a5e23 creates a symlink at
 	// /dev/disk/by-id/nvme-Amazon_Elastic_Block_Store_vol0fab1d5e3f72a5e23
-	nvmeName := "nvme-Amazon_Elastic_Block_Store_" + strippedVolumeName
+	nvmeName := nvmeByIDPrefix + strippedVolumeName
 	nvmeDevicePath, err := findNvmeVolume(nvmeName)
-
 	if err == nil {
 		klog.V(5).InfoS("[Debug] successfully resolved", "nvmeName", nvmeName, "nvmeDevicePath", nvmeDevicePath)
-		canonicalDevicePath = nvmeDevicePath
-		if err = verifyVolumeSerialMatch(canonicalDevicePath, strippedVolumeName, execRunner); err != nil {
-			return "", err
+		if err = verifyVolumeSerialMatch(nvmeDevicePath, strippedVolumeName, execRunner); err == nil {
+			return nvmeDevicePath, deviceResolutionByID, nil
 		}
-		return m.appendPartition(canonicalDevicePath, partition), nil
 	} else {
 		klog.V(5).InfoS("[Debug] error searching for nvme path", "nvmeName", nvmeName, "err", err)
 	}
+	errs = append(errs, err)
 
-	if canonicalDevicePath == "" {
-		return "", fmt.Errorf("no device path for device %q volume %q found", devicePath, volumeID)
+	// If the given path exists, the device MAY be nvme. Further, it MAY be a
+	// symlink to the nvme device path like:
+	// | $ stat /dev/xvdba
+	// | File: ‘/dev/xvdba’ -> ‘nvme1n1’
+	// On instances without nvme, it is the device itself.
+	exists, err := m.PathExists(devicePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to check if path %q exists: %w", devicePath, err)
+	}
+	if exists {
+		canonicalDevicePath, err := filepath.EvalSymlinks(devicePath)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to evaluate symlink %q: %w", devicePath, err)
+		}
+		klog.V(5).InfoS("[Debug] The canonical device path was resolved", "devicePath", devicePath, "canonicalDevicePath", canonicalDevicePath)
+		if err = verifyVolumeSerialMatch(canonicalDevicePath, strippedVolumeName, execRunner); err == nil {
+			return canonicalDevicePath, deviceResolutionDevicePath, nil
+		}
+		errs = append(errs, err)
 	}
 
-	canonicalDevicePath = m.appendPartition(canonicalDevicePath, partition)
-	return canonicalDevicePath, nil
+	// Finally, ask each nvme controller for its serial number, which is the volume ID for EBS volumes. This finds
+	// the device even when udev has not created its symlinks yet, or did not run the EBS rules at all.
+	nvmeDevicePath, err = findNvmeDeviceBySerial(nvmeDeviceGlob, strippedVolumeName, nvmeIdentifySerial)
+	if err != nil {
+		errs = append(errs, err)
+	} else if nvmeDevicePath != "" {
+		return nvmeDevicePath, deviceResolutionNvmeIdentify, nil
+	}
+	return "", "", errors.Join(errs...)
 }
 
 // findNvmeVolume looks for the nvme volume with the specified name
 // It follows the symlink (if it exists) and returns the absolute path to the device.
 func findNvmeVolume(findName string) (device string, err error) {
-	p := filepath.Join("/dev/disk/by-id/", findName)
+	p := filepath.Join(nvmeByIDPath, findName)
 	stat, err := os.Lstat(p)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -201,6 +235,11 @@ func (m *NodeMounter) PreparePublishTarget(target string) error {
 	return nil
 }
 
+// BlockDevicePath returns devicePath, which raw block volumes are bind mounted from as is on Linux.
+func (m *NodeMounter) BlockDevicePath(devicePath string) (string, error) {
+	return devicePath, nil
+}
+
 // IsBlockDevice checks if the given path is a block device.
 func (m *NodeMounter) IsBlockDevice(fullPath string) (bool, error) {
 	var st unix.Stat_t
@@ -282,13 +321,28 @@ func (m *NodeMounter) PathExists(path string) (bool, error) {
 	return mountutils.PathExists(path)
 }
 
-// Resize resizes the filesystem of the given devicePath.
+// Resize resizes the filesystem of the given devicePath. It does nothing if the filesystem is known to fill the
+// device already.
 func (m *NodeMounter) Resize(devicePath, deviceMountPath string) (bool, error) {
-	return mountutils.NewResizeFs(m.Exec).Resize(devicePath, deviceMountPath)
+	if fills, _ := m.geometryCache.fillsDevice(devicePath, "Resize"); fills {
+		klog.V(4).InfoS("Filesystem already fills the device, skipping resize", "devicePath", devicePath)
+		return false, nil
+	}
+	m.geometryCache.invalidate(devicePath)
+	resized, err := mountutils.NewResizeFs(m.Exec).Resize(devicePath, deviceMountPath)
+	if err == nil {
+		m.geometryCache.store(devicePath, filesystemGeometry{})
+	}
+	return resized, err
 }
 
-// NeedResize checks if the filesystem of the given devicePath needs to be resized.
+// NeedResize checks if the filesystem of the given devicePath needs to be resized. The check is skipped if the
+// filesystem is known to fill the device already. Otherwise, ext3, ext4, and XFS filesystems always need a resize,
+// which does nothing if the filesystem already fills the device.
 func (m *NodeMounter) NeedResize(devicePath string, deviceMountPath string) (bool, error) {
+	if fills, _ := m.geometryCache.fillsDevice(devicePath, "NeedResize"); fills {
+		return false, nil
+	}
 	return mountutils.NewResizeFs(m.Exec).NeedResize(devicePath, deviceMountPath)
 }
 
@@ -313,6 +367,17 @@ func (m *NodeMounter) Unstage(path string) error {
 	}
 }
 
+// SELinuxEnabled returns whether mounts made by NodeMounter honor SELinux context mount options. The mount command
+// silently drops them when selinuxfs is not mounted in its mount namespace, which is the case in containers that do
+// not mount /sys/fs/selinux from the host. Mounts made in the host mount namespace always see the SELinux state of the host.
+func (m *NodeMounter) SELinuxEnabled() bool {
+	if _, ok := m.Interface.(*hostNamespaceMounter); ok {
+		return true
+	}
+	_, err := os.Stat(selinuxEnforcePath)
+	return err == nil
+}
+
 // GetVolumeStats acquires byte and inode statistics of filesystem at volumePath.
 func (m *NodeMounter) GetVolumeStats(volumePath string) (VolumeStats, error) {
 	stats := VolumeStats{}
@@ -380,5 +445,122 @@ func (m *NodeMounter) GetVolumeStats(volumePath string) (VolumeStats, error) {
 	}
 	stats.UsedInodes = stats.TotalInodes - stats.AvailableInodes
 
+	if statfs.Type == xfsSuperMagic && m.hasXFSProjectQuota(volumePath) {
+		limit, used, err := m.getXFSProjectQuota(volumePath)
+		if err != nil {
+			// Quota reporting is best-effort, statfs results are still accurate for the filesystem
+			klog.V(4).InfoS("GetVolumeStats: could not read XFS project quota", "volumePath", volumePath, "err", err)
+		} else {
+			stats.ProjectQuotaLimitBytes = limit
+			stats.ProjectQuotaUsedBytes = used
+		}
+	}
+
 	return stats, nil
 }
+
+// hasXFSProjectQuota returns whether the filesystem mounted at path is mounted with project quotas, which is only
+// the case of the volumes staged with the project quota parameter. GetVolumeStats checks it to skip running xfs_io
+// and xfs_quota for every other XFS volume.
+func (m *NodeMounter) hasXFSProjectQuota(path string) bool {
+	mountInfoPath := selfMountInfoPath
+	if _, ok := m.Interface.(*hostNamespaceMounter); ok {
+		mountInfoPath = filepath.Join(m.hostRootfsPath, hostMountInfoPath)
+	}
+	opts, err := mountInfoOptions(mountInfoPath, path)
+	if err != nil {
+		klog.V(4).InfoS("GetVolumeStats: could not read mount options", "volumePath", path, "err", err)
+		return false
+	}
+	for _, opt := range opts {
+		switch opt {
+		case "prjquota", "pquota", "pqnoenforce":
+			return true
+		}
+	}
+	return false
+}
+
+// mountInfoOptions returns the per-mount and superblock options of the filesystem mounted at mountPoint, read from
+// the mountinfo file at mountInfoPath. It returns no options if nothing is mounted at mountPoint.
+func mountInfoOptions(mountInfoPath, mountPoint string) ([]string, error) {
+	f, err := os.Open(mountInfoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var opts []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// https://man7.org/linux/man-pages/man5/proc_pid_mountinfo.5.html
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[4] != mountPoint {
+			continue
+		}
+		// The last entry wins, it is the mount on top of the others at the same mount point
+		opts = strings.Split(fields[5], ",")
+		if sep := slices.Index(fields, "-"); sep >= 0 && sep+3 < len(fields) {
+			opts = append(opts, strings.Split(fields[sep+3], ",")...)
+		}
+	}
+	return opts, scanner.Err()
+}
+
+// getXFSProjectQuota returns the block limit and usage in bytes of the XFS project that owns path.
+// It returns zeros if path does not belong to a project or the project has no block limit.
+func (m *NodeMounter) getXFSProjectQuota(path string) (limit int64, used int64, err error) {
+	output, err := m.Exec.Command("xfs_io", "-c", "lsproj", path).CombinedOutput()
+	if err != nil {
+		return 0, 0, fmt.Errorf("xfs_io lsproj failed: %w: %s", err, string(output))
+	}
+	projID, err := parseXFSProjectID(string(output))
+	if err != nil || projID == 0 {
+		return 0, 0, err
+	}
+
+	output, err = m.Exec.Command("xfs_quota", "-x", "-c", fmt.Sprintf("quota -p -N -b -n %d", projID), path).CombinedOutput()
+	if err != nil {
+		return 0, 0, fmt.Errorf("xfs_quota failed: %w: %s", err, string(output))
+	}
+	return parseXFSQuotaReport(string(output))
+}
+
+// parseXFSProjectID parses the project ID out of `xfs_io -c lsproj` output.
+func parseXFSProjectID(output string) (uint32, error) {
+	matches := xfsProjIDRegex.FindStringSubmatch(output)
+	if len(matches) < 2 {
+		return 0, fmt.Errorf("could not parse project ID from %q", output)
+	}
+	id, err := strconv.ParseUint(matches[1], 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(id), nil
+}
+
+// parseXFSQuotaReport parses a single line of `xfs_quota -c "quota -p -N -b"` output, which
+// looks like "<device> <used> <soft> <hard> <warns> [<grace>]" with sizes in KiB.
+// The hard limit is preferred, falling back to the soft limit if no hard limit is set.
+func parseXFSQuotaReport(output string) (limit int64, used int64, err error) {
+	fields := strings.Fields(strings.TrimSpace(output))
+	if len(fields) < 4 {
+		return 0, 0, fmt.Errorf("could not parse quota report %q", output)
+	}
+	var values [3]int64
+	for i := range values {
+		values[i], err = strconv.ParseInt(fields[i+1], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("could not parse quota report %q: %w", output, err)
+		}
+	}
+	usedKiB, softKiB, hardKiB := values[0], values[1], values[2]
+	limitKiB := hardKiB
+	if limitKiB == 0 {
+		limitKiB = softKiB
+	}
+	if limitKiB == 0 {
+		return 0, 0, nil
+	}
+	return limitKiB * 1024, usedKiB * 1024, nil
+}
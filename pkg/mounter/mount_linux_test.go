@@ -20,6 +20,7 @@ package mounter
 
 import (
 	"errors"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -69,7 +70,7 @@ func TestNeedResize(t *testing.T) {
 				Interface: mount.New(""),
 				Exec:      &fexec,
 			}
-			fakeMounter := NodeMounter{&safe}
+			fakeMounter := NodeMounter{SafeFormatAndMount: &safe}
 
 			needResize, err := fakeMounter.NeedResize(test.devicePath, test.deviceMountPath)
 			if needResize != test.expectResult {
@@ -88,7 +89,7 @@ func TestMakeDir(t *testing.T) {
 
 	targetPath := filepath.Join(dir, "targetdir")
 
-	mountObj, err := NewNodeMounter(false)
+	mountObj, err := NewNodeMounter(false, NodeMounterOptions{})
 	if err != nil {
 		t.Fatalf("error creating mounter %v", err)
 	}
@@ -112,7 +113,7 @@ func TestMakeFile(t *testing.T) {
 
 	targetPath := filepath.Join(dir, "targetfile")
 
-	mountObj, err := NewNodeMounter(false)
+	mountObj, err := NewNodeMounter(false, NodeMounterOptions{})
 	if err != nil {
 		t.Fatalf("error creating mounter %v", err)
 	}
@@ -136,7 +137,7 @@ func TestPathExists(t *testing.T) {
 
 	targetPath := filepath.Join(dir, "notafile")
 
-	mountObj, err := NewNodeMounter(false)
+	mountObj, err := NewNodeMounter(false, NodeMounterOptions{})
 	if err != nil {
 		t.Fatalf("error creating mounter %v", err)
 	}
@@ -158,7 +159,7 @@ func TestGetDeviceName(t *testing.T) {
 
 	targetPath := filepath.Join(dir, "notafile")
 
-	mountObj, err := NewNodeMounter(false)
+	mountObj, err := NewNodeMounter(false, NodeMounterOptions{})
 	if err != nil {
 		t.Fatalf("error creating mounter %v", err)
 	}
@@ -245,3 +246,113 @@ func TestVerifyVolumeSerialMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestParseXFSProjectID(t *testing.T) {
+	testcases := []struct {
+		name        string
+		output      string
+		expectedID  uint32
+		expectError bool
+	}{
+		{
+			name:       "project ID",
+			output:     "projid = 42\n",
+			expectedID: 42,
+		},
+		{
+			name:       "default project",
+			output:     "projid = 0\n",
+			expectedID: 0,
+		},
+		{
+			name:        "unexpected output",
+			output:      "foo: Inappropriate ioctl for device\n",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := parseXFSProjectID(tc.output)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedID, id)
+		})
+	}
+}
+
+func TestParseXFSQuotaReport(t *testing.T) {
+	testcases := []struct {
+		name          string
+		output        string
+		expectedLimit int64
+		expectedUsed  int64
+		expectError   bool
+	}{
+		{
+			name:          "hard limit",
+			output:        "/dev/nvme1n1 1024 2048 4096 00 [--------]\n",
+			expectedLimit: 4096 * 1024,
+			expectedUsed:  1024 * 1024,
+		},
+		{
+			name:          "soft limit only",
+			output:        "/dev/nvme1n1 1024 2048 0 00 [--------]\n",
+			expectedLimit: 2048 * 1024,
+			expectedUsed:  1024 * 1024,
+		},
+		{
+			name:   "no limit",
+			output: "/dev/nvme1n1 1024 0 0 00 [--------]\n",
+		},
+		{
+			name:        "truncated output",
+			output:      "/dev/nvme1n1 1024\n",
+			expectError: true,
+		},
+		{
+			name:        "non-numeric output",
+			output:      "/dev/nvme1n1 used soft hard 00 [--------]\n",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			limit, used, err := parseXFSQuotaReport(tc.output)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedLimit, limit)
+			assert.Equal(t, tc.expectedUsed, used)
+		})
+	}
+}
+
+func TestMountInfoOptions(t *testing.T) {
+	mountInfoPath := filepath.Join(t.TempDir(), "mountinfo")
+	mountInfo := `22 1 259:1 / / rw,noatime shared:1 - xfs /dev/nvme0n1p1 rw,attr2,inode64,prjquota
+310 22 259:3 / /var/lib/kubelet/plugins/kubernetes.io/csi/ebs.csi.aws.com/quota/globalmount rw,relatime shared:150 - xfs /dev/nvme1n1 rw,attr2,inode64,logbufs=8,logbsize=32k,prjquota
+320 22 259:3 / /var/lib/kubelet/pods/pod-1/volumes/kubernetes.io~csi/pvc-quota/mount rw,relatime shared:150 - xfs /dev/nvme1n1 rw,attr2,inode64,logbufs=8,logbsize=32k,prjquota
+330 22 259:5 / /var/lib/kubelet/pods/pod-1/volumes/kubernetes.io~csi/pvc-plain/mount rw,relatime shared:160 - xfs /dev/nvme2n1 rw,attr2,inode64,logbufs=8,logbsize=32k,noquota
+`
+	require.NoError(t, os.WriteFile(mountInfoPath, []byte(mountInfo), 0o600))
+
+	opts, err := mountInfoOptions(mountInfoPath, "/var/lib/kubelet/pods/pod-1/volumes/kubernetes.io~csi/pvc-quota/mount")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rw", "relatime", "rw", "attr2", "inode64", "logbufs=8", "logbsize=32k", "prjquota"}, opts)
+
+	// The root filesystem of the node must not be mistaken for the volume
+	opts, err = mountInfoOptions(mountInfoPath, "/var/lib/kubelet/pods/pod-1/volumes/kubernetes.io~csi/pvc-plain/mount")
+	require.NoError(t, err)
+	assert.NotContains(t, opts, "prjquota")
+
+	opts, err = mountInfoOptions(mountInfoPath, "/var/lib/kubelet/pods/pod-1/volumes/kubernetes.io~csi/pvc-missing/mount")
+	require.NoError(t, err)
+	assert.Empty(t, opts)
+}
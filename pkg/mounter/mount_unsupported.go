@@ -49,6 +49,10 @@ func (m *NodeMounter) PreparePublishTarget(target string) error {
 	return errors.New(stubMessage)
 }
 
+func (m *NodeMounter) BlockDevicePath(devicePath string) (string, error) {
+	return stubMessage, errors.New(stubMessage)
+}
+
 func (m *NodeMounter) IsBlockDevice(fullPath string) (bool, error) {
 	return false, errors.New(stubMessage)
 }
@@ -96,3 +100,35 @@ func (m *NodeMounter) Unstage(path string) error {
 func (m *NodeMounter) GetVolumeStats(volumePath string) (VolumeStats, error) {
 	return VolumeStats{}, errors.New(stubMessage)
 }
+
+func (m *NodeMounter) SELinuxEnabled() bool {
+	return false
+}
+
+func (m *NodeMounter) SetupFscrypt(dir string, key []byte) error {
+	return errors.New(stubMessage)
+}
+
+func (m *NodeMounter) RemoveFscryptKey(dir string) error {
+	return nil
+}
+
+func (m *NodeMounter) MountHolders(path string) ([]MountHolder, error) {
+	return nil, errors.New(stubMessage)
+}
+
+func (m *NodeMounter) AttachedVolumes() (map[string]string, error) {
+	return nil, errors.New(stubMessage)
+}
+
+func NewHostNamespaceSafeMounter(_ string) (*mountutils.SafeFormatAndMount, error) {
+	return nil, errors.New("NewHostNamespaceSafeMounter is not supported on this platform")
+}
+
+func useHostMountNamespace(_ NodeMounterOptions) (bool, error) {
+	return false, nil
+}
+
+func readFilesystemGeometry(devicePath string) (filesystemGeometry, error) {
+	return filesystemGeometry{}, errors.New(stubMessage)
+}
@@ -21,7 +21,9 @@ package mounter
 import (
 	"errors"
 	"fmt"
+	"os"
 	"regexp"
+	"strconv"
 
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
 	"golang.org/x/sys/windows"
@@ -34,6 +36,18 @@ var (
 	ErrUnsupportedMounter = errors.New("unsupported mounter type")
 )
 
+// NewHostNamespaceSafeMounter is not supported on Windows, see --windows-host-process instead.
+func NewHostNamespaceSafeMounter(_ string) (*mountutils.SafeFormatAndMount, error) {
+	return nil, errors.New("NewHostNamespaceSafeMounter is not supported on this platform")
+}
+
+func useHostMountNamespace(opts NodeMounterOptions) (bool, error) {
+	if opts.MountNamespace == MountNamespaceHost {
+		return false, fmt.Errorf("mount namespace %q is not supported on Windows", opts.MountNamespace)
+	}
+	return false, nil
+}
+
 func (m *NodeMounter) FindDevicePath(devicePath, volumeID, _, _ string) (string, error) {
 	switch proxyMounter := m.SafeFormatAndMount.Interface.(type) {
 	case *CSIProxyMounterV2:
@@ -77,13 +91,25 @@ func (m *NodeMounter) PreparePublishTarget(target string) error {
 	return nil
 }
 
-// IsBlockDevice checks if the given path is a block device
+// IsBlockDevice checks if the given path is a raw block volume publish target, that is a link to a disk.
 func (m *NodeMounter) IsBlockDevice(fullPath string) (bool, error) {
-	return false, nil
+	if _, ok := m.SafeFormatAndMount.Interface.(*CSIProxyMounterV2); !ok {
+		return false, nil
+	}
+	target, err := blockDeviceTarget(fullPath)
+	return target != "", err
 }
 
-// getBlockSizeBytes gets the size of the disk in bytes
+// getBlockSizeBytes gets the size of the disk in bytes. devicePath is either a disk number or a raw block volume
+// publish target.
 func (m *NodeMounter) GetBlockSizeBytes(devicePath string) (int64, error) {
+	if target, err := blockDeviceTarget(devicePath); err == nil && target != "" {
+		diskNumber, err := diskNumberOf(target)
+		if err != nil {
+			return -1, err
+		}
+		devicePath = strconv.FormatUint(uint64(diskNumber), 10)
+	}
 	switch proxyMounter := m.SafeFormatAndMount.Interface.(type) {
 	case *CSIProxyMounterV2:
 		sizeInBytes, err := proxyMounter.GetDeviceSize(devicePath)
@@ -165,15 +191,21 @@ func (m *NodeMounter) IsCorruptedMnt(err error) bool {
 	return mountutils.IsCorruptedMnt(err)
 }
 
+// MakeFile prepares path to be the publish target of a raw block volume. Those are published as links to disks
+// rather than bind mounts on Windows, so rather than creating a file it removes whatever is at path, unless it already
+// is such a link.
 func (m *NodeMounter) MakeFile(path string) error {
-	switch proxyMounter := m.SafeFormatAndMount.Interface.(type) {
-	case *CSIProxyMounterV2:
-		return proxyMounter.MakeFile(path)
-	case *CSIProxyMounter:
-		return proxyMounter.MakeFile(path)
-	default:
-		return ErrUnsupportedMounter
+	notMnt, err := m.IsLikelyNotMountPoint(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
 	}
+	if err != nil {
+		return err
+	}
+	if !notMnt {
+		return nil
+	}
+	return m.Unpublish(path)
 }
 
 func (m *NodeMounter) MakeDir(path string) error {
@@ -296,6 +328,31 @@ func (m *NodeMounter) Unstage(target string) error {
 	return nil
 }
 
+// SELinuxEnabled always returns false, SELinux does not exist on Windows.
+func (m *NodeMounter) SELinuxEnabled() bool {
+	return false
+}
+
+// SetupFscrypt is not supported on Windows, fscrypt is specific to Linux filesystems.
+func (m *NodeMounter) SetupFscrypt(_ string, _ []byte) error {
+	return errors.New("fscrypt is not supported on Windows")
+}
+
+// RemoveFscryptKey does nothing on Windows, where volumes are never encrypted with fscrypt.
+func (m *NodeMounter) RemoveFscryptKey(_ string) error {
+	return nil
+}
+
+// MountHolders is not supported on Windows.
+func (m *NodeMounter) MountHolders(_ string) ([]MountHolder, error) {
+	return nil, errors.New("looking up the processes holding a mount is not supported on Windows")
+}
+
+// AttachedVolumes is not supported on Windows.
+func (m *NodeMounter) AttachedVolumes() (map[string]string, error) {
+	return nil, errors.New("listing the attached volumes is not supported on Windows")
+}
+
 // GetVolumeStats acquires byte statistics of filesystem at volumePath.
 func (m *NodeMounter) GetVolumeStats(volumePath string) (VolumeStats, error) {
 	stats := VolumeStats{}
@@ -320,3 +377,8 @@ func (m *NodeMounter) GetVolumeStats(volumePath string) (VolumeStats, error) {
 
 	return stats, nil
 }
+
+// readFilesystemGeometry is not implemented on Windows, where NeedResize and Resize go through CSI Proxy.
+func readFilesystemGeometry(_ string) (filesystemGeometry, error) {
+	return filesystemGeometry{}, errors.New("reading filesystem geometry is not supported on Windows")
+}
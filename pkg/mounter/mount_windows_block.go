@@ -0,0 +1,128 @@
+//go:build windows
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mounter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"unsafe"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"golang.org/x/sys/windows"
+	"k8s.io/klog/v2"
+)
+
+// ioctlStorageGetDeviceNumber is IOCTL_STORAGE_GET_DEVICE_NUMBER from <ntddstor.h>.
+const ioctlStorageGetDeviceNumber = 0x2D1080
+
+// diskInterfaceClass is GUID_DEVINTERFACE_DISK from <ntddstor.h>. Every disk has a device interface of this class,
+// with a path like \\?\scsi#disk&ven_nvme&prod_amazon_elastic_b#...#{53f56307-b6bf-11d0-94f2-00a0c91efb8b} that,
+// unlike its disk number and \\.\PhysicalDriveN path, does not change when disks are attached, detached or the
+// node reboots. Raw disks have no volume, hence no \\?\Volume{GUID}\ path, so this is the stable path they are
+// published from.
+var diskInterfaceClass = windows.GUID{
+	Data1: 0x53f56307,
+	Data2: 0xb6bf,
+	Data3: 0x11d0,
+	Data4: [8]byte{0x94, 0xf2, 0x00, 0xa0, 0xc9, 0x1e, 0xfb, 0x8b},
+}
+
+// storageDeviceNumber is STORAGE_DEVICE_NUMBER from <ntddstor.h>.
+type storageDeviceNumber struct {
+	DeviceType      uint32
+	DeviceNumber    uint32
+	PartitionNumber uint32
+}
+
+// BlockDevicePath returns the disk device interface path of the disk numbered devicePath, as returned by
+// FindDevicePath, which raw block volumes are published from. The driver has to run as a HostProcess container to
+// open disks, so this fails with csi-proxy v1.
+func (m *NodeMounter) BlockDevicePath(devicePath string) (string, error) {
+	if _, ok := m.SafeFormatAndMount.Interface.(*CSIProxyMounterV2); !ok {
+		return "", errors.New("raw block volumes on Windows require the node plugin to run as a HostProcess container (--windows-host-process)")
+	}
+	diskNumber, err := strconv.ParseUint(devicePath, 10, 32)
+	if err != nil {
+		return "", fmt.Errorf("invalid disk number %q: %w", devicePath, err)
+	}
+	return diskInterfacePath(uint32(diskNumber))
+}
+
+// diskInterfacePath returns the device interface path of the disk with the given number.
+func diskInterfacePath(diskNumber uint32) (string, error) {
+	paths, err := windows.CM_Get_Device_Interface_List("", &diskInterfaceClass, windows.CM_GET_DEVICE_INTERFACE_LIST_PRESENT)
+	if err != nil {
+		return "", fmt.Errorf("could not list disk device interfaces: %w", err)
+	}
+	for _, path := range paths {
+		number, err := diskNumberOf(path)
+		if err != nil {
+			klog.V(5).InfoS("[Debug] could not get disk number", "path", path, "err", err)
+			continue
+		}
+		if number == diskNumber {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no device interface found for disk %d", diskNumber)
+}
+
+// diskNumberOf returns the number of the disk at path, which may be a device interface path.
+func diskNumberOf(path string) (uint32, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	// Querying the device number needs no access rights, so this works while the disk is in use.
+	h, err := windows.CreateFile(name, 0, windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE, nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer windows.CloseHandle(h)
+
+	var number storageDeviceNumber
+	var returned uint32
+	if err := windows.DeviceIoControl(h, ioctlStorageGetDeviceNumber, nil, 0, (*byte)(unsafe.Pointer(&number)), uint32(unsafe.Sizeof(number)), &returned, nil); err != nil {
+		return 0, fmt.Errorf("could not get device number: %w", err)
+	}
+	return number.DeviceNumber, nil
+}
+
+// blockDeviceTarget returns the device path that path, a raw block volume publish target, links to, or "" if path
+// is not one.
+func blockDeviceTarget(path string) (string, error) {
+	longPath := util.LongWindowsPath(path)
+	stat, err := os.Lstat(longPath)
+	if err != nil {
+		return "", err
+	}
+	if stat.Mode()&os.ModeSymlink == 0 {
+		return "", nil
+	}
+	target, err := os.Readlink(longPath)
+	if err != nil {
+		return "", err
+	}
+	if !util.IsWindowsDevicePath(target) {
+		return "", nil
+	}
+	return target, nil
+}
@@ -72,6 +72,11 @@ func NewSafeMounterV2() (*mountutils.SafeFormatAndMount, error) {
 func (mounter *CSIProxyMounterV2) Mount(source string, target string, fstype string, options []string) error {
 	// Mount is called after the format is done.
 	// TODO: Confirm that fstype is empty.
+	if util.IsWindowsDevicePath(source) || util.IsLongWindowsPath(source) || util.IsLongWindowsPath(target) {
+		// csi-proxy rejects device paths, that raw block volumes are published from, and long paths. The driver runs
+		// in a HostProcess container with csi-proxy v2, so it can create the link itself.
+		return os.Symlink(util.LongWindowsPath(source), util.LongWindowsPath(target))
+	}
 	linkRequest := &fsv2.CreateSymlinkRequest{
 		SourcePath: util.NormalizeWindowsPath(source),
 		TargetPath: util.NormalizeWindowsPath(target),
@@ -133,6 +138,9 @@ func (mounter *CSIProxyMounterV2) Unmount(target string) error {
 
 // Rmdir - delete the given directory
 func (mounter *CSIProxyMounterV2) Rmdir(path string) error {
+	if util.IsLongWindowsPath(path) {
+		return os.RemoveAll(util.LongWindowsPath(path))
+	}
 	rmdirRequest := &fsv2.RmdirRequest{
 		Path:  util.NormalizeWindowsPath(path),
 		Force: true,
@@ -190,6 +198,13 @@ func (mounter *CSIProxyMounterV2) IsLikelyNotMountPoint(path string) (bool, erro
 		return true, os.ErrNotExist
 	}
 
+	// Raw block volumes are published as links to disks, whose existence csi-proxy cannot check.
+	if target, err := blockDeviceTarget(path); err != nil {
+		return false, err
+	} else if target != "" {
+		return false, nil
+	}
+
 	response, err := mounter.FsClient.IsSymlink(context.Background(),
 		&fsv2.IsSymlinkRequest{
 			Path: util.NormalizeWindowsPath(path),
@@ -239,6 +254,9 @@ func (mounter *CSIProxyMounterV2) MakeFile(pathname string) error {
 // Currently the make dir is only used from the staging code path, hence we call it
 // with Plugin context..
 func (mounter *CSIProxyMounterV2) MakeDir(pathname string) error {
+	if util.IsLongWindowsPath(pathname) {
+		return os.MkdirAll(util.LongWindowsPath(pathname), 0o755)
+	}
 	mkdirReq := &fsv2.MkdirRequest{
 		Path: util.NormalizeWindowsPath(pathname),
 	}
@@ -253,6 +271,13 @@ func (mounter *CSIProxyMounterV2) MakeDir(pathname string) error {
 
 // ExistsPath - Checks if a path exists. Unlike util ExistsPath, this call does not perform follow link.
 func (mounter *CSIProxyMounterV2) ExistsPath(path string) (bool, error) {
+	if util.IsLongWindowsPath(path) {
+		_, err := os.Lstat(util.LongWindowsPath(path))
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return err == nil, err
+	}
 	isExistsResponse, err := mounter.FsClient.PathExists(context.Background(),
 		&fsv2.PathExistsRequest{
 			Path: util.NormalizeWindowsPath(path),
@@ -0,0 +1,92 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nodeapi defines the versioned HTTP API on which the node plugin serves the EBS volumes attached to its
+// node, and a client for it. The API is consumed by the metadata labeler and can be used by other tooling instead of
+// mapping devices to volumes from sysfs.
+package nodeapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// VolumesPath is the path on which the volumes of the node are served, as a VolumeList.
+	VolumesPath = "/v1/volumes"
+
+	// DefaultPort is the conventional port of the node API, on which the Helm chart serves it when enabled.
+	DefaultPort = 3310
+)
+
+// Volume is an EBS volume attached to the node.
+type Volume struct {
+	VolumeID string `json:"volumeID"`
+	// DevicePath is the device of the volume. Volumes attached as nvme devices are found by the serial number of their
+	// controller, other volumes only once staged.
+	DevicePath string `json:"devicePath,omitempty"`
+	// StagingTargetPath, FsType and StagedAt are only set for the volumes staged since the node plugin started.
+	StagingTargetPath string     `json:"stagingTargetPath,omitempty"`
+	FsType            string     `json:"fsType,omitempty"`
+	StagedAt          *time.Time `json:"stagedAt,omitempty"`
+}
+
+// VolumeList is the response of VolumesPath.
+type VolumeList struct {
+	// AttachedVolumesListed is false when the attached volumes could not be listed, for example on Windows or on
+	// instances that do not attach volumes as nvme devices. Volumes then only holds the volumes staged by the node
+	// plugin.
+	AttachedVolumesListed bool     `json:"attachedVolumesListed"`
+	Volumes               []Volume `json:"volumes"`
+}
+
+// Client gets the volumes of nodes from the node plugin running on them.
+type Client struct {
+	http *http.Client
+	port int
+}
+
+// NewClient returns a Client for node plugins serving the API on port.
+func NewClient(port int, timeout time.Duration) *Client {
+	return &Client{http: &http.Client{Timeout: timeout}, port: port}
+}
+
+// Volumes returns the volumes of the node whose node plugin Pod has the IP address podIP.
+func (c *Client) Volumes(ctx context.Context, podIP string) (*VolumeList, error) {
+	url := "http://" + net.JoinHostPort(podIP, strconv.Itoa(c.port)) + VolumesPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	var list VolumeList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("could not decode the response of %s: %w", url, err)
+	}
+	return &list, nil
+}
@@ -122,6 +122,9 @@ func (b *ec2ClientBase) DeleteTags(ctx context.Context, params *ec2.DeleteTagsIn
 func (b *ec2ClientBase) EnableFastSnapshotRestores(ctx context.Context, params *ec2.EnableFastSnapshotRestoresInput, optFns ...func(*ec2.Options)) (*ec2.EnableFastSnapshotRestoresOutput, error) {
 	return b.client.EnableFastSnapshotRestores(ctx, params, optFns...)
 }
+func (b *ec2ClientBase) EnableVolumeIO(ctx context.Context, params *ec2.EnableVolumeIOInput, optFns ...func(*ec2.Options)) (*ec2.EnableVolumeIOOutput, error) {
+	return b.client.EnableVolumeIO(ctx, params, optFns...)
+}
 func (b *ec2ClientBase) LockSnapshot(ctx context.Context, params *ec2.LockSnapshotInput, optFns ...func(*ec2.Options)) (*ec2.LockSnapshotOutput, error) {
 	return b.client.LockSnapshot(ctx, params, optFns...)
 }
@@ -0,0 +1,32 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import "context"
+
+type apiBudgetClassKey struct{}
+
+// WithAPIBudgetClass returns a copy of ctx carrying the EC2 API budget class its EC2 calls are drawn from.
+func WithAPIBudgetClass(ctx context.Context, class string) context.Context {
+	return context.WithValue(ctx, apiBudgetClassKey{}, class)
+}
+
+// APIBudgetClassFromContext returns the EC2 API budget class carried by ctx, or an empty string if there is none.
+func APIBudgetClassFromContext(ctx context.Context) string {
+	class, _ := ctx.Value(apiBudgetClassKey{}).(string)
+	return class
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type correlationIDsKey struct{}
+
+// NewCorrelationID returns a new random ID used to trace a CSI RPC through batched and coalesced EC2 calls.
+func NewCorrelationID() string {
+	return uuid.NewString()
+}
+
+// WithCorrelationIDs returns a copy of ctx carrying the given correlation IDs.
+// A context used for a single RPC carries one ID; a context used for a merged EC2 call carries the IDs
+// of every RPC merged into it.
+func WithCorrelationIDs(ctx context.Context, ids ...string) context.Context {
+	return context.WithValue(ctx, correlationIDsKey{}, ids)
+}
+
+// CorrelationIDs returns the correlation IDs carried by ctx, if any.
+func CorrelationIDs(ctx context.Context) []string {
+	ids, _ := ctx.Value(correlationIDsKey{}).([]string)
+	return ids
+}
+
+// MergeCorrelationIDs returns the correlation IDs carried by all of the given contexts, without duplicates.
+func MergeCorrelationIDs(ctxs ...context.Context) []string {
+	var merged []string
+	seen := make(map[string]struct{})
+	for _, ctx := range ctxs {
+		for _, id := range CorrelationIDs(ctx) {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				merged = append(merged, id)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"sync"
+)
+
+// OperationStep is the step an in-flight RPC is currently at, such as the EC2 call or the wait it is blocked on.
+type OperationStep struct {
+	mu   sync.Mutex
+	step string
+}
+
+// Get returns the current step.
+func (s *OperationStep) Get() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.step
+}
+
+type operationStepKey struct{}
+
+// WithOperationStep returns a copy of ctx carrying step, which is updated by SetOperationStep.
+func WithOperationStep(ctx context.Context, step *OperationStep) context.Context {
+	return context.WithValue(ctx, operationStepKey{}, step)
+}
+
+// SetOperationStep sets the step of the RPC of ctx and returns a function that restores the previous step, to be
+// called when the step is over. It does nothing if ctx carries no step.
+func SetOperationStep(ctx context.Context, step string) func() {
+	s, _ := ctx.Value(operationStepKey{}).(*OperationStep)
+	if s == nil {
+		return func() {}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.step
+	s.step = step
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.step = previous
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import "context"
+
+// Subsystem identifies the part of the driver that made an EC2 call, so that API usage can be attributed to it.
+type Subsystem string
+
+const (
+	SubsystemProvision Subsystem = "provision"
+	SubsystemAttach    Subsystem = "attach"
+	SubsystemSnapshot  Subsystem = "snapshot"
+	SubsystemModify    Subsystem = "modify"
+	// SubsystemShared is used for batched EC2 calls that serve requests from more than one subsystem.
+	SubsystemShared Subsystem = "shared"
+)
+
+type subsystemKey struct{}
+
+// WithSubsystem returns a copy of ctx carrying the given subsystem.
+func WithSubsystem(ctx context.Context, subsystem Subsystem) context.Context {
+	return context.WithValue(ctx, subsystemKey{}, subsystem)
+}
+
+// SubsystemFromContext returns the subsystem carried by ctx, or an empty string if there is none.
+func SubsystemFromContext(ctx context.Context) Subsystem {
+	subsystem, _ := ctx.Value(subsystemKey{}).(Subsystem)
+	return subsystem
+}
+
+// MergeSubsystems returns the subsystem carried by all of the given contexts that carry one, or SubsystemShared
+// if they carry different subsystems.
+func MergeSubsystems(ctxs ...context.Context) Subsystem {
+	var merged Subsystem
+	for _, ctx := range ctxs {
+		subsystem := SubsystemFromContext(ctx)
+		switch {
+		case subsystem == "":
+		case merged == "":
+			merged = subsystem
+		case merged != subsystem:
+			return SubsystemShared
+		}
+	}
+	return merged
+}
@@ -70,6 +70,12 @@ func lastIndex(arg1, arg2 string) int {
 	return strings.LastIndex(arg2, arg1)
 }
 
+// get returns the value of key in m, or "" if m has no key. Unlike field access, it works with keys that are not
+// identifiers, such as the "app.kubernetes.io/part-of" label.
+func get(key string, m map[string]string) string {
+	return m[key]
+}
+
 func newFuncMap() template.FuncMap {
 	return template.FuncMap{
 		"html":      html,
@@ -77,6 +83,7 @@ func newFuncMap() template.FuncMap {
 		"call":      call,
 		"urlquery":  urlquery,
 		"contains":  contains,
+		"get":       get,
 		"toUpper":   strings.ToUpper,
 		"toLower":   strings.ToLower,
 		"substring": substring,
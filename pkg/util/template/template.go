@@ -26,6 +26,9 @@ type PVProps struct {
 	PVCName      string
 	PVCNamespace string
 	PVName       string
+	// PVCLabels and PVCAnnotations are only read from the PVC when a template references them.
+	PVCLabels      map[string]string
+	PVCAnnotations map[string]string
 }
 
 type VolumeSnapshotProps struct {
@@ -60,7 +63,8 @@ func Evaluate(tm []string, props any, warnOnly bool) (map[string]string, error)
 }
 
 func execTemplate(value string, props any, t *template.Template) (string, error) {
-	tmpl, err := t.Parse(value)
+	// Labels and annotations missing from the PVC are read as "" rather than "<no value>"
+	tmpl, err := t.Option("missingkey=zero").Parse(value)
 	if err != nil {
 		return "", err
 	}
@@ -73,3 +77,14 @@ func execTemplate(value string, props any, t *template.Template) (string, error)
 
 	return b.String(), nil
 }
+
+// Execute interpolates a single template value with props.
+func Execute(value string, props any) (string, error) {
+	return execTemplate(value, props, template.New("tmpl").Funcs(newFuncMap()))
+}
+
+// Parse returns an error if value is not a valid template.
+func Parse(value string) error {
+	_, err := template.New("tmpl").Funcs(newFuncMap()).Parse(value)
+	return err
+}
@@ -365,3 +365,34 @@ func TestEvaluateVolumeSnapshotTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluatePVCMetadata(t *testing.T) {
+	props := &PVProps{
+		PVCName:        "ebs-claim",
+		PVCNamespace:   "default",
+		PVCLabels:      map[string]string{"team": "storage", "cost-center": "cc-1234"},
+		PVCAnnotations: map[string]string{"example.com/owner": "alice"},
+	}
+	input := []string{
+		"team={{ .PVCLabels.team }}",
+		"costCenter={{ .PVCLabels | get \"cost-center\" | toUpper }}",
+		"owner={{ get \"example.com/owner\" .PVCAnnotations }}",
+		"missing={{ .PVCLabels.missing }}",
+		"missingGet={{ .PVCLabels | get \"missing\" }}",
+	}
+
+	tags, err := Evaluate(input, props, false)
+	if err != nil {
+		t.Fatalf("Evaluate() unexpected error = %v", err)
+	}
+	expectedTags := map[string]string{
+		"team":       "storage",
+		"costCenter": "CC-1234",
+		"owner":      "alice",
+		"missing":    "",
+		"missingGet": "",
+	}
+	if diff := cmp.Diff(expectedTags, tags); diff != "" {
+		t.Errorf("Evaluate() tags mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -124,6 +125,10 @@ func ParseEndpoint(endpoint string, hostprocess bool) (string, string, error) {
 		scheme := strings.ToLower(parts[0])
 		addr := parts[1]
 
+		if scheme == "tcp" {
+			return parseTCPEndpoint(endpoint, strings.TrimSuffix(addr, "/"))
+		}
+
 		// Remove the socket file if it already exists
 		if scheme == "unix" {
 			if _, err := os.Stat(addr); err == nil {
@@ -145,6 +150,10 @@ func ParseEndpoint(endpoint string, hostprocess bool) (string, string, error) {
 	scheme := strings.ToLower(u.Scheme)
 	switch scheme {
 	case "tcp":
+		if u.Path != "" && u.Path != "/" {
+			return "", "", fmt.Errorf("invalid tcp endpoint %q: expected tcp://<host>:<port>", endpoint)
+		}
+		return parseTCPEndpoint(endpoint, u.Host)
 	case "unix":
 		addr = filepath.Join("/", addr)
 		if err := os.Remove(addr); err != nil && !os.IsNotExist(err) { // #nosec G703 -- addr is derived from a parsed URL path, not direct user input
@@ -157,6 +166,28 @@ func ParseEndpoint(endpoint string, hostprocess bool) (string, string, error) {
 	return scheme, addr, nil
 }
 
+// parseTCPEndpoint checks that addr, the address of the TCP endpoint, has a host and a port.
+func parseTCPEndpoint(endpoint, addr string) (string, string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host == "" || port == "" {
+		return "", "", fmt.Errorf("invalid tcp endpoint %q: expected tcp://<host>:<port>", endpoint)
+	}
+	return "tcp", addr, nil
+}
+
+// IsLoopbackAddress returns true if the host of addr, a host:port address, is localhost or a loopback IP.
+func IsLoopbackAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 func roundUpSize(volumeSizeBytes int64, allocationUnitBytes int64) int64 {
 	if allocationUnitBytes == 0 {
 		return 0 // Avoid division by zero
@@ -185,8 +216,16 @@ func CountMACAddresses(s string) int {
 	return len(matches)
 }
 
-// NormalizeWindowsPath normalizes a Windows path.
+// MaxWindowsPathLength is MAX_PATH, the length from which Windows paths need the \\?\ prefix. csi-proxy rejects
+// longer paths.
+const MaxWindowsPathLength = 260
+
+// NormalizeWindowsPath normalizes a Windows path. Device paths like \\?\Volume{GUID}\ and \\.\PhysicalDrive1 are
+// left as is.
 func NormalizeWindowsPath(path string) string {
+	if IsWindowsDevicePath(path) {
+		return path
+	}
 	normalizedPath := strings.ReplaceAll(path, "/", "\\")
 	if strings.HasPrefix(normalizedPath, "\\") {
 		normalizedPath = "c:" + normalizedPath
@@ -194,21 +233,51 @@ func NormalizeWindowsPath(path string) string {
 	return normalizedPath
 }
 
-// SanitizeRequest takes a request object and returns a copy of the request with
-// the "Secrets" field cleared.
-func SanitizeRequest(req any) any {
-	v := reflect.ValueOf(&req).Elem()
-	e := reflect.New(v.Elem().Type()).Elem()
+// IsWindowsDevicePath returns whether path is in the Win32 device namespace, that is starts with \\?\ or \\.\.
+func IsWindowsDevicePath(path string) bool {
+	return strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\\.\`)
+}
 
-	e.Set(v.Elem())
+// IsLongWindowsPath returns whether the normalized path is too long to be used without the \\?\ prefix.
+func IsLongWindowsPath(path string) bool {
+	return !IsWindowsDevicePath(path) && len(NormalizeWindowsPath(path)) >= MaxWindowsPathLength
+}
+
+// LongWindowsPath returns the normalized path, with the \\?\ prefix if it is too long to be used without.
+func LongWindowsPath(path string) string {
+	if IsLongWindowsPath(path) {
+		return `\\?\` + NormalizeWindowsPath(path)
+	}
+	return NormalizeWindowsPath(path)
+}
 
-	f := reflect.Indirect(e).FieldByName("Secrets")
+// SanitizeRequest takes a request object and returns a copy of the request with
+// the "Secrets" field cleared. The request itself is left untouched, also when it
+// is a pointer, because the caller still needs the secrets.
+func SanitizeRequest(req any) any {
+	v := reflect.ValueOf(req)
+	isPtr := v.Kind() == reflect.Pointer
+	if isPtr {
+		if v.IsNil() {
+			return req
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return req
+	}
+	f := v.FieldByName("Secrets")
+	if !f.IsValid() || f.Kind() != reflect.Map {
+		return req
+	}
 
-	if f.IsValid() && f.CanSet() && f.Kind() == reflect.Map {
-		f.Set(reflect.MakeMap(f.Type()))
-		v.Set(e)
+	e := reflect.New(v.Type()).Elem()
+	e.Set(v)
+	e.FieldByName("Secrets").Set(reflect.MakeMap(f.Type()))
+	if isPtr {
+		return e.Addr().Interface()
 	}
-	return req
+	return e.Interface()
 }
 
 // WaitUntilTimeOrContext returns once time wakeup has elapsed or ctx is done.
@@ -21,7 +21,9 @@ package util
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -94,15 +96,31 @@ func TestParseEndpoint(t *testing.T) {
 		},
 		{
 			name:      "valid tcp endpoint",
-			endpoint:  "tcp:///127.0.0.1/",
+			endpoint:  "tcp://127.0.0.1:10000",
 			expScheme: "tcp",
-			expAddr:   "/127.0.0.1",
+			expAddr:   "127.0.0.1:10000",
 		},
 		{
-			name:      "valid tcp endpoint",
-			endpoint:  "tcp:///127.0.0.1",
+			name:      "valid tcp endpoint with trailing slash",
+			endpoint:  "tcp://127.0.0.1:10000/",
+			expScheme: "tcp",
+			expAddr:   "127.0.0.1:10000",
+		},
+		{
+			name:      "valid tcp endpoint ipv6",
+			endpoint:  "tcp://[::1]:10000",
 			expScheme: "tcp",
-			expAddr:   "/127.0.0.1",
+			expAddr:   "[::1]:10000",
+		},
+		{
+			name:     "invalid tcp endpoint without port",
+			endpoint: "tcp:///127.0.0.1",
+			expErr:   errors.New(`invalid tcp endpoint "tcp:///127.0.0.1": expected tcp://<host>:<port>`),
+		},
+		{
+			name:     "invalid tcp endpoint without host",
+			endpoint: "tcp://:10000",
+			expErr:   errors.New(`invalid tcp endpoint "tcp://:10000": expected tcp://<host>:<port>`),
 		},
 		{
 			name:     "invalid endpoint",
@@ -135,6 +153,31 @@ func TestParseEndpoint(t *testing.T) {
 	}
 }
 
+func TestIsLoopbackAddress(t *testing.T) {
+	testCases := []struct {
+		addr        string
+		expLoopback bool
+	}{
+		{addr: "127.0.0.1:10000", expLoopback: true},
+		{addr: "127.0.0.2:10000", expLoopback: true},
+		{addr: "[::1]:10000", expLoopback: true},
+		{addr: "localhost:10000", expLoopback: true},
+		{addr: "0.0.0.0:10000", expLoopback: false},
+		{addr: "[::]:10000", expLoopback: false},
+		{addr: "10.0.0.1:10000", expLoopback: false},
+		{addr: "example.com:10000", expLoopback: false},
+		{addr: "127.0.0.1", expLoopback: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.addr, func(t *testing.T) {
+			if got := IsLoopbackAddress(tc.addr); got != tc.expLoopback {
+				t.Fatalf("IsLoopbackAddress(%q) = %t, expected %t", tc.addr, got, tc.expLoopback)
+			}
+		})
+	}
+}
+
 func TestGetAccessModes(t *testing.T) {
 	testVolCap := []*csi.VolumeCapability{
 		{
@@ -248,14 +291,34 @@ func TestSanitizeRequest(t *testing.T) {
 				Secrets: map[string]string{},
 			},
 		},
+		{
+			name: "Request value with Secrets",
+			req: TestRequest{
+				Name:    "Test",
+				Secrets: map[string]string{"key1": "value1"},
+			},
+			expected: TestRequest{
+				Name:    "Test",
+				Secrets: map[string]string{},
+			},
+		},
+		{
+			name:     "Request without Secrets",
+			req:      &struct{ Name string }{Name: "Test"},
+			expected: &struct{ Name string }{Name: "Test"},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			original := fmt.Sprintf("%+v", tt.req)
 			result := SanitizeRequest(tt.req)
 			if !reflect.DeepEqual(result, tt.expected) {
 				t.Errorf("SanitizeRequest() = %v, expected %v", result, tt.expected)
 			}
+			if modified := fmt.Sprintf("%+v", tt.req); modified != original {
+				t.Errorf("SanitizeRequest() modified request to %v, expected %v", modified, original)
+			}
 		})
 	}
 }
@@ -350,3 +413,84 @@ func TestIsHyperPodNode(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeCorrelationIDs(t *testing.T) {
+	ctx1 := WithCorrelationIDs(t.Context(), "id-1")
+	ctx2 := WithCorrelationIDs(t.Context(), "id-2", "id-1")
+
+	assert.Nil(t, CorrelationIDs(t.Context()))
+	assert.Equal(t, []string{"id-1"}, CorrelationIDs(ctx1))
+	assert.Equal(t, []string{"id-1", "id-2"}, MergeCorrelationIDs(ctx1, t.Context(), ctx2))
+	assert.NotEqual(t, NewCorrelationID(), NewCorrelationID())
+}
+
+func TestMergeSubsystems(t *testing.T) {
+	attach := WithSubsystem(t.Context(), SubsystemAttach)
+	provision := WithSubsystem(t.Context(), SubsystemProvision)
+
+	assert.Equal(t, Subsystem(""), SubsystemFromContext(t.Context()))
+	assert.Equal(t, Subsystem(""), MergeSubsystems(t.Context()))
+	assert.Equal(t, SubsystemAttach, MergeSubsystems(t.Context(), attach, attach))
+	assert.Equal(t, SubsystemShared, MergeSubsystems(attach, t.Context(), provision))
+}
+
+func TestSetOperationStep(t *testing.T) {
+	SetOperationStep(t.Context(), "ignored")()
+
+	step := &OperationStep{}
+	ctx := WithOperationStep(t.Context(), step)
+	restoreWaiting := SetOperationStep(ctx, "waiting")
+	restoreEC2 := SetOperationStep(ctx, "EC2 AttachVolume")
+	assert.Equal(t, "EC2 AttachVolume", step.Get())
+	restoreEC2()
+	assert.Equal(t, "waiting", step.Get())
+	restoreWaiting()
+	assert.Empty(t, step.Get())
+}
+
+func TestLongWindowsPath(t *testing.T) {
+	longTarget := `c:\var\lib\kubelet\pods\` + strings.Repeat("a", 250) + `\volumeDevices\kubernetes.io~csi\pvc`
+	testCases := []struct {
+		name       string
+		path       string
+		normalized string
+		long       string
+	}{
+		{
+			name:       "rooted path",
+			path:       "/var/lib/kubelet/plugins/ebs.csi.aws.com",
+			normalized: `c:\var\lib\kubelet\plugins\ebs.csi.aws.com`,
+			long:       `c:\var\lib\kubelet\plugins\ebs.csi.aws.com`,
+		},
+		{
+			name:       "path longer than MAX_PATH",
+			path:       longTarget,
+			normalized: longTarget,
+			long:       `\\?\` + longTarget,
+		},
+		{
+			name:       "volume GUID path",
+			path:       `\\?\Volume{6a0f4bf2-8b3e-4d6a-9c1b-0f1e2d3c4b5a}\`,
+			normalized: `\\?\Volume{6a0f4bf2-8b3e-4d6a-9c1b-0f1e2d3c4b5a}\`,
+			long:       `\\?\Volume{6a0f4bf2-8b3e-4d6a-9c1b-0f1e2d3c4b5a}\`,
+		},
+		{
+			name:       "disk device interface path",
+			path:       `\\?\scsi#disk&ven_nvme&prod_amazon_elastic_b#1&2afd7d61&0&000100#{53f56307-b6bf-11d0-94f2-00a0c91efb8b}`,
+			normalized: `\\?\scsi#disk&ven_nvme&prod_amazon_elastic_b#1&2afd7d61&0&000100#{53f56307-b6bf-11d0-94f2-00a0c91efb8b}`,
+			long:       `\\?\scsi#disk&ven_nvme&prod_amazon_elastic_b#1&2afd7d61&0&000100#{53f56307-b6bf-11d0-94f2-00a0c91efb8b}`,
+		},
+		{
+			name:       "physical drive path",
+			path:       `\\.\PhysicalDrive1`,
+			normalized: `\\.\PhysicalDrive1`,
+			long:       `\\.\PhysicalDrive1`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.normalized, NormalizeWindowsPath(tc.path))
+			assert.Equal(t, tc.long, LongWindowsPath(tc.path))
+		})
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+   http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/driver"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/testsuites"
+	. "github.com/onsi/ginkgo/v2"
+	v1 "k8s.io/api/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	k8srestclient "k8s.io/client-go/rest"
+	"k8s.io/kubernetes/test/e2e/framework"
+	admissionapi "k8s.io/pod-security-admission/api"
+)
+
+const (
+	// EC2 documents that archiving a snapshot and restoring it from the archive tier can each take up to 72 hours.
+	snapshotTieringTimeout      = 72 * time.Hour
+	snapshotTieringPollInterval = 5 * time.Minute
+	snapshotCompletedTimeout    = 30 * time.Minute
+)
+
+// waitForSnapshotTiering waits until the last tiering operation of a snapshot reaches expectedStatus.
+func waitForSnapshotTiering(ctx context.Context, ec2Client *ec2.Client, snapshotID string, expectedStatus types.TieringOperationStatus) types.SnapshotTierStatus {
+	var status types.SnapshotTierStatus
+	deadline := time.Now().Add(snapshotTieringTimeout)
+	for {
+		result, err := ec2Client.DescribeSnapshotTierStatus(ctx, &ec2.DescribeSnapshotTierStatusInput{
+			Filters: []types.Filter{
+				{
+					Name:   aws.String("snapshot-id"),
+					Values: []string{snapshotID},
+				},
+			},
+		})
+		framework.ExpectNoError(err, "failed to describe tier status of snapshot %s", snapshotID)
+		if len(result.SnapshotTierStatuses) == 1 {
+			status = result.SnapshotTierStatuses[0]
+			if status.LastTieringOperationStatus == expectedStatus {
+				return status
+			}
+			if strings.HasSuffix(string(status.LastTieringOperationStatus), "-failed") {
+				Fail(fmt.Sprintf("tiering of snapshot %s failed: %s", snapshotID, aws.ToString(status.LastTieringOperationStatusDetail)))
+			}
+			framework.Logf("snapshot %s: tiering operation %s, progress %d%%", snapshotID, status.LastTieringOperationStatus, aws.ToInt32(status.LastTieringProgress))
+		}
+		if time.Now().After(deadline) {
+			Fail(fmt.Sprintf("timed out waiting for tiering of snapshot %s to reach %s, last status %q", snapshotID, expectedStatus, status.LastTieringOperationStatus))
+		}
+		select {
+		case <-ctx.Done():
+			Fail(fmt.Sprintf("context done waiting for tiering of snapshot %s: %v", snapshotID, ctx.Err()))
+		case <-time.After(snapshotTieringPollInterval):
+		}
+	}
+}
+
+// Requires env AWS_AVAILABILITY_ZONES a comma separated list of AZs to be set.
+var _ = Describe("[ebs-csi-e2e] [long-running] [requires-aws-api] Archived Snapshots", Label("long-running"), func() {
+	f := framework.NewDefaultFramework("ebs")
+	f.NamespacePodSecurityEnforceLevel = admissionapi.LevelPrivileged
+
+	var (
+		cs          clientset.Interface
+		ns          *v1.Namespace
+		ebsDriver   driver.PVTestDriver
+		snapshotrcs k8srestclient.Interface
+	)
+
+	BeforeEach(func() {
+		if os.Getenv(awsAvailabilityZonesEnv) == "" {
+			Skip(fmt.Sprintf("env %q not set", awsAvailabilityZonesEnv))
+		}
+		cs = f.ClientSet
+		ns = f.Namespace
+		ebsDriver = driver.InitEbsCSIDriver()
+		var err error
+		snapshotrcs, err = restClient(testsuites.SnapshotAPIGroup, testsuites.APIVersionv1)
+		if err != nil {
+			Fail(fmt.Sprintf("could not get rest clientset: %v", err))
+		}
+	})
+
+	// Tests that require that the e2e runner has access to the AWS API
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		Fail(fmt.Sprintf("failed to load AWS config: %v", err))
+	}
+	ec2Client := ec2.NewFromConfig(cfg)
+
+	It("should provision a volume from a snapshot restored temporarily from the archive tier", func(ctx context.Context) {
+		availabilityZones := strings.Split(os.Getenv(awsAvailabilityZonesEnv), ",")
+		availabilityZone := availabilityZones[rand.Intn(len(availabilityZones))]
+		tags := []types.Tag{{Key: aws.String(generateTagName()), Value: aws.String(testTagValue)}}
+
+		By("creating a volume and a snapshot of it")
+		volume, err := ec2Client.CreateVolume(ctx, &ec2.CreateVolumeInput{
+			AvailabilityZone:  aws.String(availabilityZone),
+			Size:              aws.Int32(defaultDiskSize),
+			VolumeType:        types.VolumeType(defaultVolumeType),
+			TagSpecifications: []types.TagSpecification{{ResourceType: types.ResourceTypeVolume, Tags: tags}},
+		})
+		framework.ExpectNoError(err, "failed to create volume")
+		volumeID := aws.ToString(volume.VolumeId)
+		DeferCleanup(func(ctx context.Context) {
+			_, err := ec2Client.DeleteVolume(ctx, &ec2.DeleteVolumeInput{VolumeId: aws.String(volumeID)})
+			framework.ExpectNoError(err, "failed to delete volume %s", volumeID)
+		})
+		err = ec2.NewVolumeAvailableWaiter(ec2Client).Wait(ctx, &ec2.DescribeVolumesInput{VolumeIds: []string{volumeID}}, snapshotCompletedTimeout)
+		framework.ExpectNoError(err, "volume %s did not become available", volumeID)
+
+		snapshot, err := ec2Client.CreateSnapshot(ctx, &ec2.CreateSnapshotInput{
+			VolumeId:          aws.String(volumeID),
+			TagSpecifications: []types.TagSpecification{{ResourceType: types.ResourceTypeSnapshot, Tags: tags}},
+		})
+		framework.ExpectNoError(err, "failed to create snapshot of volume %s", volumeID)
+		snapshotID := aws.ToString(snapshot.SnapshotId)
+		// The snapshot is deleted with its VolumeSnapshotContent, this only removes it if the spec fails before.
+		DeferCleanup(func(ctx context.Context) {
+			if _, err := ec2Client.DeleteSnapshot(ctx, &ec2.DeleteSnapshotInput{SnapshotId: aws.String(snapshotID)}); err != nil {
+				framework.Logf("failed to delete snapshot %s: %v", snapshotID, err)
+			}
+		})
+		err = ec2.NewSnapshotCompletedWaiter(ec2Client).Wait(ctx, &ec2.DescribeSnapshotsInput{SnapshotIds: []string{snapshotID}}, snapshotCompletedTimeout)
+		framework.ExpectNoError(err, "snapshot %s did not complete", snapshotID)
+
+		By("archiving the snapshot")
+		_, err = ec2Client.ModifySnapshotTier(ctx, &ec2.ModifySnapshotTierInput{
+			SnapshotId:  aws.String(snapshotID),
+			StorageTier: types.TargetStorageTierArchive,
+		})
+		framework.ExpectNoError(err, "failed to archive snapshot %s", snapshotID)
+		status := waitForSnapshotTiering(ctx, ec2Client, snapshotID, types.TieringOperationStatusArchivalCompleted)
+		if status.StorageTier != types.StorageTierArchive {
+			Fail(fmt.Sprintf("expected snapshot %s in storage tier %s, got %s", snapshotID, types.StorageTierArchive, status.StorageTier))
+		}
+
+		By("restoring the snapshot temporarily")
+		_, err = ec2Client.RestoreSnapshotTier(ctx, &ec2.RestoreSnapshotTierInput{
+			SnapshotId:           aws.String(snapshotID),
+			TemporaryRestoreDays: aws.Int32(1),
+		})
+		framework.ExpectNoError(err, "failed to restore snapshot %s", snapshotID)
+		status = waitForSnapshotTiering(ctx, ec2Client, snapshotID, types.TieringOperationStatusTemporaryRestoreCompleted)
+		if status.RestoreExpiryTime == nil {
+			Fail(fmt.Sprintf("expected snapshot %s to have a restore expiry time", snapshotID))
+		}
+
+		By("provisioning a volume from the restored snapshot")
+		pod := testsuites.PodDetails{
+			Cmd: testsuites.PodCmdWriteToVolume("/mnt/test-1"),
+			Volumes: []testsuites.VolumeDetails{
+				{
+					ClaimSize:   fmt.Sprintf("%dGi", defaultDiskSize),
+					VolumeMount: testsuites.DefaultGeneratedVolumeMount,
+				},
+			},
+		}
+		test := testsuites.PreProvisionedVolumeSnapshotTest{
+			CSIDriver: ebsDriver,
+			Pod:       pod,
+		}
+		test.Run(cs, snapshotrcs, ns, snapshotID)
+	})
+})
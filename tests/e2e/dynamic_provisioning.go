@@ -621,7 +621,7 @@ var _ = Describe("[ebs-csi-e2e] [single-az] Dynamic Provisioning", func() {
 		availabilityZones := strings.Split(os.Getenv(awsAvailabilityZonesEnv), ",")
 		availabilityZone := availabilityZones[rand.Intn(len(availabilityZones))]
 		region := availabilityZone[0 : len(availabilityZone)-1]
-		cloud := awscloud.NewCloud(region, false, "", true, false)
+		cloud := awscloud.NewCloud(region, awscloud.CloudOptions{Batching: true})
 
 		test := testsuites.DynamicallyProvisionedReclaimPolicyTest{
 			CSIDriver: ebsDriver,
@@ -749,6 +749,23 @@ var _ = Describe("[ebs-csi-e2e] [single-az] Snapshot", func() {
 		}
 		test.Run(cs, snapshotrcs, ns)
 	})
+
+	It("should snapshot a raw block volume and restore it into raw block and filesystem volumes with its data intact", func() {
+		allowVolumeExpansion := true
+		test := testsuites.DynamicallyProvisionedBlockVolumeSnapshotTest{
+			CSIDriver: ebsDriver,
+			Volume: testsuites.VolumeDetails{
+				CreateVolumeParameters: map[string]string{
+					ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3,
+					ebscsidriver.FSTypeKey:     ebscsidriver.FSTypeExt4,
+				},
+				ClaimSize:            driver.MinimumSizeForVolumeType(awscloud.VolumeTypeGP3),
+				VolumeMode:           testsuites.Block,
+				AllowVolumeExpansion: &allowVolumeExpansion,
+			},
+		}
+		test.Run(cs, snapshotrcs, ns)
+	})
 })
 
 var _ = Describe("[ebs-csi-e2e] [multi-az] Dynamic Provisioning", func() {
@@ -0,0 +1,240 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+   http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	awscloud "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	ebscsidriver "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/driver"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/testsuites"
+	. "github.com/onsi/ginkgo/v2"
+	v1 "k8s.io/api/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/test/e2e/framework"
+	admissionapi "k8s.io/pod-security-admission/api"
+)
+
+// ebsEncryptionContextKey is the encryption context key of the grants EBS creates for the volumes encrypted with a key.
+const ebsEncryptionContextKey = "aws:ebs:id"
+
+// crossAccountKeyPolicy returns a key policy that lets the principals of account use the key through EC2 only, like
+// the policy of a key shared with another account, so that the driver can use the key without an IAM policy for it.
+// EC2 can create grants for the volumes encrypted with the key. The account root keeps full access to administer
+// the key.
+func crossAccountKeyPolicy(partition, account, region string) (string, error) {
+	viaEC2 := map[string]any{
+		"kms:CallerAccount": account,
+		"kms:ViaService":    fmt.Sprintf("ec2.%s.amazonaws.com", region),
+	}
+	policy := map[string]any{
+		"Version": "2012-10-17",
+		"Statement": []map[string]any{
+			{
+				"Sid":       "KeyAdministration",
+				"Effect":    "Allow",
+				"Principal": map[string]any{"AWS": fmt.Sprintf("arn:%s:iam::%s:root", partition, account)},
+				"Action":    "kms:*",
+				"Resource":  "*",
+			},
+			{
+				"Sid":       "UseThroughEC2",
+				"Effect":    "Allow",
+				"Principal": map[string]any{"AWS": "*"},
+				"Action":    []string{"kms:Encrypt", "kms:Decrypt", "kms:ReEncrypt*", "kms:GenerateDataKey*", "kms:DescribeKey"},
+				"Resource":  "*",
+				"Condition": map[string]any{"StringEquals": viaEC2},
+			},
+			{
+				"Sid":       "GrantsForEBSVolumes",
+				"Effect":    "Allow",
+				"Principal": map[string]any{"AWS": "*"},
+				"Action":    "kms:CreateGrant",
+				"Resource":  "*",
+				"Condition": map[string]any{
+					"StringEquals": viaEC2,
+					"Bool":         map[string]any{"kms:GrantIsForAWSResource": "true"},
+				},
+			},
+		},
+	}
+	b, err := json.Marshal(policy)
+	return string(b), err
+}
+
+// createCustomerManagedKey creates a KMS key with crossAccountKeyPolicy and returns its ARN. The key is scheduled for
+// deletion, after revoking the grants left on it, when the spec ends.
+func createCustomerManagedKey(ctx context.Context, kmsClient *kms.Client, region string) string {
+	created, err := kmsClient.CreateKey(ctx, &kms.CreateKeyInput{
+		Description: aws.String("aws-ebs-csi-driver e2e customer managed key"),
+		Tags:        []kmstypes.Tag{{TagKey: aws.String(generateTagName()), TagValue: aws.String(testTagValue)}},
+	})
+	if err != nil {
+		Fail(fmt.Sprintf("failed to create KMS key: %v", err))
+	}
+	keyArn := aws.ToString(created.KeyMetadata.Arn)
+	DeferCleanup(func(ctx context.Context) {
+		for _, grant := range listGrants(ctx, kmsClient, keyArn) {
+			framework.Logf("revoking grant %s left on KMS key %s", aws.ToString(grant.GrantId), keyArn)
+			if _, err := kmsClient.RevokeGrant(ctx, &kms.RevokeGrantInput{KeyId: aws.String(keyArn), GrantId: grant.GrantId}); err != nil {
+				framework.Logf("failed to revoke grant %s: %v", aws.ToString(grant.GrantId), err)
+			}
+		}
+		_, err := kmsClient.ScheduleKeyDeletion(ctx, &kms.ScheduleKeyDeletionInput{KeyId: aws.String(keyArn), PendingWindowInDays: aws.Int32(7)})
+		framework.ExpectNoError(err, "failed to schedule deletion of KMS key %s", keyArn)
+	})
+
+	parsed, err := arn.Parse(keyArn)
+	framework.ExpectNoError(err)
+	policy, err := crossAccountKeyPolicy(parsed.Partition, aws.ToString(created.KeyMetadata.AWSAccountId), region)
+	framework.ExpectNoError(err)
+	if _, err := kmsClient.PutKeyPolicy(ctx, &kms.PutKeyPolicyInput{KeyId: aws.String(keyArn), Policy: aws.String(policy)}); err != nil {
+		Fail(fmt.Sprintf("failed to put policy of KMS key %s: %v", keyArn, err))
+	}
+	return keyArn
+}
+
+// listGrants returns the grants of a KMS key.
+func listGrants(ctx context.Context, kmsClient *kms.Client, keyArn string) []kmstypes.GrantListEntry {
+	var grants []kmstypes.GrantListEntry
+	paginator := kms.NewListGrantsPaginator(kmsClient, &kms.ListGrantsInput{KeyId: aws.String(keyArn)})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			Fail(fmt.Sprintf("failed to list grants of KMS key %s: %v", keyArn, err))
+		}
+		grants = append(grants, page.Grants...)
+	}
+	return grants
+}
+
+// volumeGrants returns the grants of a KMS key that EBS created for a volume.
+func volumeGrants(grants []kmstypes.GrantListEntry, volumeID string) []kmstypes.GrantListEntry {
+	var result []kmstypes.GrantListEntry
+	for _, grant := range grants {
+		if grant.Constraints != nil && grant.Constraints.EncryptionContextSubset[ebsEncryptionContextKey] == volumeID {
+			result = append(result, grant)
+		}
+	}
+	return result
+}
+
+var _ = Describe("[ebs-csi-e2e] [single-az] [requires-aws-api] Encryption with customer managed keys", func() {
+	f := framework.NewDefaultFramework("ebs")
+	f.NamespacePodSecurityEnforceLevel = admissionapi.LevelPrivileged
+
+	var (
+		cs        clientset.Interface
+		ns        *v1.Namespace
+		ebsDriver driver.PVTestDriver
+	)
+
+	BeforeEach(func() {
+		cs = f.ClientSet
+		ns = f.Namespace
+		ebsDriver = driver.InitEbsCSIDriver()
+	})
+
+	// Tests that require that the e2e runner has access to the AWS API
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		Fail(fmt.Sprintf("failed to load AWS config: %v", err))
+	}
+	ec2Client := ec2.NewFromConfig(cfg)
+	kmsClient := kms.NewFromConfig(cfg)
+
+	It("should provision volumes encrypted with a customer managed key shared through its key policy and clean up their grants", func(ctx context.Context) {
+		keyArn := createCustomerManagedKey(ctx, kmsClient, cfg.Region)
+		testTag := generateTagName()
+		volume := testsuites.VolumeDetails{
+			CreateVolumeParameters: map[string]string{
+				ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3,
+				ebscsidriver.FSTypeKey:     ebscsidriver.FSTypeExt4,
+				ebscsidriver.EncryptedKey:  "true",
+				ebscsidriver.KmsKeyIDKey:   keyArn,
+				ebscsidriver.TagKeyPrefix:  fmt.Sprintf("%s=%s", testTag, testTagValue),
+			},
+			ClaimSize:   driver.MinimumSizeForVolumeType(awscloud.VolumeTypeGP3),
+			VolumeMount: testsuites.DefaultGeneratedVolumeMount,
+		}
+		pods := []testsuites.PodDetails{
+			{
+				Cmd:     testsuites.PodCmdWriteToVolume("/mnt/test-1") + " && " + testsuites.PodCmdWriteToVolume("/mnt/test-2"),
+				Volumes: []testsuites.VolumeDetails{volume, volume},
+			},
+		}
+
+		var volumeIDs []string
+		test := testsuites.DynamicallyProvisionedCmdVolumeTest{
+			CSIDriver: ebsDriver,
+			Pods:      pods,
+			ValidateFunc: func() {
+				result, err := ec2Client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{
+					Filters: []types.Filter{
+						{
+							Name:   aws.String("tag:" + testTag),
+							Values: []string{testTagValue},
+						},
+					},
+				})
+				if err != nil {
+					Fail(fmt.Sprintf("failed to describe volumes: %v", err))
+				}
+				if len(result.Volumes) != 2 {
+					Fail(fmt.Sprintf("expected 2 volumes, got %d", len(result.Volumes)))
+				}
+
+				grants := listGrants(ctx, kmsClient, keyArn)
+				for _, v := range result.Volumes {
+					volumeID := aws.ToString(v.VolumeId)
+					volumeIDs = append(volumeIDs, volumeID)
+					if !aws.ToBool(v.Encrypted) {
+						Fail(fmt.Sprintf("expected volume %s to be encrypted", volumeID))
+					}
+					if aws.ToString(v.KmsKeyId) != keyArn {
+						Fail(fmt.Sprintf("expected volume %s to be encrypted with KMS key %s, got %s", volumeID, keyArn, aws.ToString(v.KmsKeyId)))
+					}
+					// EBS creates grants for the volume through the key policy, without them the pod could not
+					// have written to the volume.
+					if len(volumeGrants(grants, volumeID)) == 0 {
+						Fail(fmt.Sprintf("expected grants of KMS key %s for volume %s", keyArn, volumeID))
+					}
+				}
+			},
+		}
+		test.Run(cs, ns)
+
+		for _, volumeID := range volumeIDs {
+			result, err := ec2Client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{VolumeIds: []string{volumeID}})
+			if err == nil && len(result.Volumes) == 1 && result.Volumes[0].State != types.VolumeStateDeleting && result.Volumes[0].State != types.VolumeStateDeleted {
+				Fail(fmt.Sprintf("expected volume %s to be deleted, got state %s", volumeID, result.Volumes[0].State))
+			}
+			// Grants that EBS did not retire yet are revoked when the key is scheduled for deletion.
+			if grants := volumeGrants(listGrants(ctx, kmsClient, keyArn), volumeID); len(grants) > 0 {
+				framework.Logf("%d grants of KMS key %s for deleted volume %s are not retired yet", len(grants), keyArn, volumeID)
+			}
+		}
+	})
+})
@@ -0,0 +1,176 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	awscloud "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	ebscsidriver "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/driver"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/testsuites"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/test/e2e/framework"
+	admissionapi "k8s.io/pod-security-admission/api"
+)
+
+const (
+	// imdsEndpointModeEnv makes the AWS SDK reach IMDS at its IPv6 endpoint, [fd00:ec2::254].
+	imdsEndpointModeEnv = "AWS_EC2_METADATA_SERVICE_ENDPOINT_MODE"
+	// dualStackEndpointEnv makes the AWS SDK use the dual-stack endpoints of AWS services, such as ec2.<region>.api.aws.
+	dualStackEndpointEnv = "AWS_USE_DUALSTACK_ENDPOINT"
+	dualStackDomain      = ".api.aws"
+	nodeMetricsPort      = 3302
+)
+
+// These tests expect an IPv6-only cluster (IP_FAMILY=IPv6 with CLUSTER_TYPE=eksctl) and the driver installed the
+// way `make e2e/ipv6` does: the node plugin on the host network with metrics enabled and IMDS reached over IPv6, and
+// the controller using dual-stack endpoints with SDK debug logs.
+var _ = Describe("[ebs-csi-e2e] [ipv6] IPv6-only Cluster", Label("ipv6"), func() {
+	f := framework.NewDefaultFramework("ebs")
+	f.NamespacePodSecurityEnforceLevel = admissionapi.LevelPrivileged
+
+	var (
+		cs        clientset.Interface
+		ns        *v1.Namespace
+		ebsDriver driver.DynamicPVTestDriver
+	)
+
+	BeforeEach(func() {
+		cs = f.ClientSet
+		ns = f.Namespace
+		ebsDriver = driver.InitEbsCSIDriver()
+	})
+
+	It("should only give nodes IPv6 addresses", func() {
+		nodes, err := cs.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{LabelSelector: v1.LabelOSStable + "=" + string(v1.Linux)})
+		framework.ExpectNoError(err)
+		Expect(nodes.Items).NotTo(BeEmpty())
+		for _, node := range nodes.Items {
+			for _, address := range node.Status.Addresses {
+				if address.Type == v1.NodeInternalIP || address.Type == v1.NodeExternalIP {
+					Expect(isIPv6(address.Address)).To(BeTrue(), "node %s has %s %s, expected an IPv6-only cluster", node.Name, address.Type, address.Address)
+				}
+			}
+		}
+	})
+
+	It("should retrieve instance metadata from IMDS over IPv6", func() {
+		pods := driverPods(cs, string(nodeWorkload))
+		for _, pod := range pods {
+			Expect(containerEnvValue(pod, ebsPluginContainer, imdsEndpointModeEnv)).To(Equal("IPv6"), "pod %s does not reach IMDS over IPv6", pod.Name)
+			logs, err := cs.CoreV1().Pods(ebsNamespace).GetLogs(pod.Name, &v1.PodLogOptions{Container: ebsPluginContainer}).DoRaw(context.Background())
+			framework.ExpectNoError(err)
+			Expect(string(logs)).To(ContainSubstring("Retrieved metadata from IMDS"), "pod %s did not retrieve its metadata from IMDS", pod.Name)
+		}
+	})
+
+	It("should provision volumes through dual-stack AWS endpoints", func() {
+		for _, pod := range driverPods(cs, controllerLabel) {
+			Expect(containerEnvValue(pod, ebsPluginContainer, dualStackEndpointEnv)).To(Equal("true"), "pod %s does not use dual-stack endpoints", pod.Name)
+		}
+
+		since := time.Now()
+		test := testsuites.DynamicallyProvisionedCmdVolumeTest{
+			CSIDriver: ebsDriver,
+			Pods: []testsuites.PodDetails{
+				{
+					Cmd: testsuites.PodCmdWriteToVolume("/mnt/test-1"),
+					Volumes: []testsuites.VolumeDetails{
+						{
+							CreateVolumeParameters: map[string]string{
+								ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3,
+								ebscsidriver.FSTypeKey:     ebscsidriver.FSTypeExt4,
+							},
+							ClaimSize: driver.MinimumSizeForVolumeType(awscloud.VolumeTypeGP3),
+							VolumeMount: testsuites.VolumeMountDetails{
+								NameGenerate:      "test-volume-",
+								MountPathGenerate: "/mnt/test-",
+							},
+						},
+					},
+				},
+			},
+		}
+		test.Run(cs, ns)
+
+		By("checking that the controller called EC2 at its dual-stack endpoint")
+		// The SDK debug log dumps every request, including its Host header
+		err := testsuites.WaitForContainerLogs(cs, ebsNamespace, controllerLabel, ebsPluginContainer, since, func(lines []string) error {
+			for _, line := range lines {
+				if strings.Contains(line, "ec2.") && strings.Contains(line, dualStackDomain) {
+					return nil
+				}
+			}
+			return fmt.Errorf("no request to the dual-stack EC2 endpoint ec2.<region>%s was logged", dualStackDomain)
+		}, logWaitTimeout, logWaitInterval)
+		framework.ExpectNoError(err)
+	})
+
+	It("should serve node metrics on the host network over IPv6", func() {
+		pods := driverPods(cs, string(nodeWorkload))
+		pod := pods[0]
+		Expect(pod.Spec.HostNetwork).To(BeTrue(), "node plugin pod %s is not on the host network", pod.Name)
+		Expect(isIPv6(pod.Status.HostIP)).To(BeTrue(), "node plugin pod %s has host IP %s, expected IPv6", pod.Name, pod.Status.HostIP)
+
+		url := fmt.Sprintf("http://%s/metrics", net.JoinHostPort(pod.Status.HostIP, fmt.Sprint(nodeMetricsPort)))
+		By("scraping " + url + " from a pod")
+		tpod := testsuites.NewTestPod(cs, ns, "wget -qO- "+url)
+		tpod.Create()
+		defer tpod.Cleanup()
+		tpod.WaitForSuccess()
+		metrics, err := tpod.Logs()
+		framework.ExpectNoError(err)
+		Expect(string(metrics)).To(ContainSubstring("aws_ebs_csi_nvme_collector_scrapes_total"))
+	})
+})
+
+// driverPods returns the running driver pods matching selector.
+func driverPods(cs clientset.Interface, selector string) []v1.Pod {
+	pods, err := cs.CoreV1().Pods(ebsNamespace).List(context.Background(), metav1.ListOptions{LabelSelector: selector, FieldSelector: "status.phase=Running"})
+	framework.ExpectNoError(err)
+	Expect(pods.Items).NotTo(BeEmpty(), "no running pods match %s", selector)
+	return pods.Items
+}
+
+// containerEnvValue returns the value of the environment variable name of the container of pod, or "" if unset.
+func containerEnvValue(pod v1.Pod, container, name string) string {
+	for _, c := range pod.Spec.Containers {
+		if c.Name != container {
+			continue
+		}
+		for _, env := range c.Env {
+			if env.Name == name {
+				return env.Value
+			}
+		}
+	}
+	return ""
+}
+
+func isIPv6(address string) bool {
+	ip := net.ParseIP(address)
+	return ip != nil && ip.To4() == nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	awscloud "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	ebscsidriver "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/driver"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/testsuites"
+	. "github.com/onsi/ginkgo/v2"
+	"k8s.io/kubernetes/test/e2e/framework"
+	admissionapi "k8s.io/pod-security-admission/api"
+)
+
+// minStatefulSetZones is the number of zones the StatefulSet topology test spreads replicas across.
+const minStatefulSetZones = 3
+
+// Cordoning a zone affects every test scheduling pods at the same time, so these tests run serially.
+var _ = Describe("[ebs-csi-e2e] [multi-az] [Serial] StatefulSet Topology", Serial, func() {
+	f := framework.NewDefaultFramework("ebs")
+	f.NamespacePodSecurityEnforceLevel = admissionapi.LevelPrivileged
+
+	// Requires env AWS_AVAILABILITY_ZONES, a comma separated list of at least 3 AZs with worker nodes
+	It("[env] should create volumes in the zones of a StatefulSet's replicas and keep them there when a zone fails", func() {
+		var zones []string
+		for zone := range strings.SplitSeq(os.Getenv(awsAvailabilityZonesEnv), ",") {
+			if zone = strings.TrimSpace(zone); zone != "" {
+				zones = append(zones, zone)
+			}
+		}
+		if len(zones) < minStatefulSetZones {
+			Skip(fmt.Sprintf("env %q must list at least %d zones, got %v", awsAvailabilityZonesEnv, minStatefulSetZones, zones))
+		}
+
+		test := testsuites.DynamicallyProvisionedStatefulSetTopologyTest{
+			CSIDriver: driver.InitEbsCSIDriver(),
+			Zones:     zones[:minStatefulSetZones],
+			Volume: testsuites.VolumeDetails{
+				CreateVolumeParameters: map[string]string{
+					ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3,
+					ebscsidriver.FSTypeKey:     ebscsidriver.FSTypeExt4,
+				},
+				ClaimSize: driver.MinimumSizeForVolumeType(awscloud.VolumeTypeGP3),
+			},
+		}
+		test.Run(f.ClientSet, f.Namespace)
+	})
+})
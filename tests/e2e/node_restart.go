@@ -0,0 +1,142 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+   http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	awscloud "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	ebscsidriver "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/driver"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/testsuites"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	e2epodoutput "k8s.io/kubernetes/test/e2e/framework/pod/output"
+	admissionapi "k8s.io/pod-security-admission/api"
+)
+
+const (
+	nodePodReadyTimeout    = 5 * time.Minute
+	nodePodPollInterval    = 5 * time.Second
+	nodeRestartExecTimeout = 30 * time.Second
+)
+
+// waitForNodePodReady waits until the ebs-csi-node pod that replaced a deleted one on a node is ready.
+func waitForNodePodReady(ctx context.Context, cs clientset.Interface, nodeName string) {
+	var podName string
+	Eventually(func(ctx context.Context) (string, error) {
+		pods, err := cs.CoreV1().Pods(driverNamespace).List(ctx, metav1.ListOptions{FieldSelector: "spec.nodeName=" + nodeName})
+		if err != nil {
+			return "", err
+		}
+		for _, pod := range pods.Items {
+			if strings.HasPrefix(pod.Name, "ebs-csi-node-") && pod.DeletionTimestamp == nil {
+				podName = pod.Name
+				return podName, nil
+			}
+		}
+		return "", nil
+	}).WithContext(ctx).WithTimeout(nodePodReadyTimeout).WithPolling(nodePodPollInterval).ShouldNot(BeEmpty(), "ebs-csi-node pod was not recreated on node %s", nodeName)
+
+	err := e2epod.WaitTimeoutForPodReadyInNamespace(ctx, cs, podName, driverNamespace, nodePodReadyTimeout)
+	framework.ExpectNoError(err, "ebs-csi-node pod %s on node %s did not become ready", podName, nodeName)
+}
+
+// Restarting the node pod disrupts the other specs that mount volumes on the node.
+var _ = framework.Describe("[ebs-csi-e2e] [Disruptive] Node plugin restart", framework.WithDisruptive(), func() {
+	f := framework.NewDefaultFramework("ebs")
+	f.NamespacePodSecurityEnforceLevel = admissionapi.LevelPrivileged
+
+	var (
+		cs        clientset.Interface
+		ns        *v1.Namespace
+		ebsDriver driver.PVTestDriver
+	)
+
+	BeforeEach(func() {
+		cs = f.ClientSet
+		ns = f.Namespace
+		ebsDriver = driver.InitEbsCSIDriver()
+	})
+
+	volume := testsuites.VolumeDetails{
+		CreateVolumeParameters: map[string]string{
+			ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3,
+			ebscsidriver.FSTypeKey:     ebscsidriver.FSTypeExt4,
+		},
+		ClaimSize:   driver.MinimumSizeForVolumeType(awscloud.VolumeTypeGP3),
+		VolumeMount: testsuites.DefaultGeneratedVolumeMount,
+	}
+
+	It("should keep mounted volumes working and mount and unmount new volumes after the node pod restarts", func(ctx context.Context) {
+		By("deploying a pod that keeps writing to its volume")
+		existing := testsuites.PodDetails{
+			Cmd:     testsuites.PodCmdContinuousWrite("/mnt/test-1"),
+			Volumes: []testsuites.VolumeDetails{volume},
+		}
+		existingPod, cleanup := existing.SetupWithDynamicVolumes(cs, ns, ebsDriver)
+		for i := range cleanup {
+			defer cleanup[i]()
+		}
+		existingPod.Create()
+		defer existingPod.Cleanup()
+		existingPod.WaitForRunning()
+
+		pod, err := cs.CoreV1().Pods(ns.Name).Get(ctx, existingPod.GetName(), metav1.GetOptions{})
+		framework.ExpectNoError(err, "failed to get pod %s", existingPod.GetName())
+		nodeName := pod.Spec.NodeName
+		node, err := cs.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		framework.ExpectNoError(err, "failed to get node %s", nodeName)
+
+		By("restarting the ebs-csi-node pod on node " + nodeName)
+		deleteNodePod(nodeName, cs)
+		waitForNodePodReady(ctx, cs, nodeName)
+
+		By("checking that the existing pod kept running and can still write to its volume")
+		pod, err = cs.CoreV1().Pods(ns.Name).Get(ctx, existingPod.GetName(), metav1.GetOptions{})
+		framework.ExpectNoError(err, "failed to get pod %s", existingPod.GetName())
+		Expect(pod.Status.Phase).To(Equal(v1.PodRunning), "pod %s stopped running", pod.Name)
+		for _, status := range pod.Status.ContainerStatuses {
+			Expect(status.RestartCount).To(BeZero(), "container %s of pod %s restarted", status.Name, pod.Name)
+		}
+		_, err = e2epodoutput.LookForStringInPodExec(ns.Name, pod.Name, []string{"/bin/sh", "-c", "echo after-restart > /mnt/test-1/check && cat /mnt/test-1/check"}, "after-restart", nodeRestartExecTimeout)
+		framework.ExpectNoError(err, "pod %s cannot write to its volume after the node pod restarted", pod.Name)
+
+		By("mounting a new volume on the same node")
+		added := testsuites.PodDetails{
+			Cmd:     testsuites.PodCmdWriteToVolume("/mnt/test-1") + " && sleep 3600",
+			Volumes: []testsuites.VolumeDetails{volume},
+		}
+		addedPod, cleanup := added.SetupWithDynamicVolumes(cs, ns, ebsDriver)
+		for i := range cleanup {
+			defer cleanup[i]()
+		}
+		addedPod.SetNodeSelector(map[string]string{v1.LabelHostname: node.Labels[v1.LabelHostname]})
+		addedPod.Create()
+		defer addedPod.Cleanup()
+		addedPod.WaitForRunning()
+
+		By("unmounting the volume of the existing pod")
+		err = e2epod.DeletePodWithWaitByName(ctx, cs, existingPod.GetName(), ns.Name)
+		framework.ExpectNoError(err, "volume of pod %s/%s was not unmounted after the node pod restarted", ns.Name, existingPod.GetName())
+	})
+})
@@ -0,0 +1,366 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	awscloud "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	ebscsidriver "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/driver"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/testsuites"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/test/e2e/framework"
+	admissionapi "k8s.io/pod-security-admission/api"
+)
+
+// paramTest is a Helm parameter and the assertions that show it took effect in a live cluster. Each paramTest
+// becomes a Ginkgo spec named "[param:<Param>] should <Description>" with the label "param:<Param>", which the
+// parameter sets in hack/e2e/param-sets.sh focus on.
+//
+// Prefer assertions on behavior, such as containerLog, over containerArg: an argument can be passed and still
+// have no effect.
+type paramTest struct {
+	Param       string
+	Description string
+	Assertions  []paramAssertion
+}
+
+// paramAssertion checks one Kubernetes-side effect of a Helm parameter.
+type paramAssertion interface {
+	fmt.Stringer
+	assert(pc *paramContext)
+}
+
+// paramContext is what assertions run against. The EC2 client is only created when an assertion needs it.
+type paramContext struct {
+	cs        clientset.Interface
+	ns        *v1.Namespace
+	ec2Client *ec2.Client
+}
+
+func (pc *paramContext) ec2() *ec2.Client {
+	if pc.ec2Client == nil {
+		cfg, err := config.LoadDefaultConfig(context.Background())
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		pc.ec2Client = ec2.NewFromConfig(cfg)
+	}
+	return pc.ec2Client
+}
+
+// workload selects the pods of a driver component.
+type workload string
+
+const (
+	controllerWorkload workload = controllerLabel
+	nodeWorkload       workload = "app=ebs-csi-node"
+)
+
+// containers returns the containers named name of all pods of w. It fails if there are none.
+func (w workload) containers(pc *paramContext, name string) []v1.Container {
+	pods, err := pc.cs.CoreV1().Pods(ebsNamespace).List(context.Background(), metav1.ListOptions{LabelSelector: string(w)})
+	ExpectWithOffset(2, err).NotTo(HaveOccurred())
+	ExpectWithOffset(2, pods.Items).NotTo(BeEmpty(), "no pods match %s", w)
+	var containers []v1.Container
+	for _, pod := range pods.Items {
+		for _, c := range pod.Spec.Containers {
+			if c.Name == name {
+				containers = append(containers, c)
+			}
+		}
+	}
+	ExpectWithOffset(2, containers).NotTo(BeEmpty(), "pods matching %s have no %s container", w, name)
+	return containers
+}
+
+// containerArg asserts that every Container of Workload has Arg, either as is or as the prefix of an argument when
+// it ends with "=".
+type containerArg struct {
+	Workload  workload
+	Container string
+	Arg       string
+}
+
+func (a containerArg) String() string {
+	return fmt.Sprintf("%s container of %s has arg %s", a.Container, a.Workload, a.Arg)
+}
+
+func (a containerArg) assert(pc *paramContext) {
+	for _, c := range a.Workload.containers(pc, a.Container) {
+		found := slices.ContainsFunc(c.Args, func(arg string) bool {
+			return arg == a.Arg || (strings.HasSuffix(a.Arg, "=") && strings.HasPrefix(arg, a.Arg))
+		})
+		ExpectWithOffset(1, found).To(BeTrue(), "%s container args %v do not contain %s", a.Container, c.Args, a.Arg)
+	}
+}
+
+// containerEnv asserts that every Container of Workload has the environment variable Name set to Value.
+type containerEnv struct {
+	Workload  workload
+	Container string
+	Name      string
+	Value     string
+}
+
+func (a containerEnv) String() string {
+	return fmt.Sprintf("%s container of %s has env %s=%s", a.Container, a.Workload, a.Name, a.Value)
+}
+
+func (a containerEnv) assert(pc *paramContext) {
+	for _, c := range a.Workload.containers(pc, a.Container) {
+		env := map[string]string{}
+		for _, e := range c.Env {
+			env[e.Name] = e.Value
+		}
+		ExpectWithOffset(1, env).To(HaveKeyWithValue(a.Name, a.Value))
+	}
+}
+
+// nodeLabel asserts that at least one node has the label Key.
+type nodeLabel struct {
+	Key string
+}
+
+func (a nodeLabel) String() string {
+	return "a node has label " + a.Key
+}
+
+func (a nodeLabel) assert(pc *paramContext) {
+	nodes, err := pc.cs.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	found := slices.ContainsFunc(nodes.Items, func(node v1.Node) bool {
+		_, ok := node.Labels[a.Key]
+		return ok
+	})
+	ExpectWithOffset(1, found).To(BeTrue(), "no node has label %s", a.Key)
+}
+
+// resourceKind is a kind of driver resource that workloadResource assertions support.
+type resourceKind string
+
+const (
+	deploymentKind resourceKind = "Deployment"
+	daemonSetKind  resourceKind = "DaemonSet"
+)
+
+// workloadResource asserts that the Deployment or DaemonSet Name exists in the driver namespace and schedules pods,
+// or, with Absent, that it does not exist.
+type workloadResource struct {
+	Kind   resourceKind
+	Name   string
+	Absent bool
+}
+
+func (a workloadResource) String() string {
+	if a.Absent {
+		return fmt.Sprintf("%s %s does not exist", a.Kind, a.Name)
+	}
+	return fmt.Sprintf("%s %s schedules pods", a.Kind, a.Name)
+}
+
+func (a workloadResource) assert(pc *paramContext) {
+	var scheduled int32
+	var err error
+	switch a.Kind {
+	case deploymentKind:
+		var deployment *appsv1.Deployment
+		deployment, err = pc.cs.AppsV1().Deployments(ebsNamespace).Get(context.Background(), a.Name, metav1.GetOptions{})
+		if err == nil {
+			scheduled = deployment.Status.Replicas
+		}
+	case daemonSetKind:
+		var ds *appsv1.DaemonSet
+		ds, err = pc.cs.AppsV1().DaemonSets(ebsNamespace).Get(context.Background(), a.Name, metav1.GetOptions{})
+		if err == nil {
+			scheduled = ds.Status.DesiredNumberScheduled
+		}
+	default:
+		Fail("unsupported resource kind " + string(a.Kind))
+	}
+	if a.Absent {
+		ExpectWithOffset(1, apierrors.IsNotFound(err)).To(BeTrue(), "%s %s should not exist, but got error: %v", a.Kind, a.Name, err)
+		return
+	}
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	ExpectWithOffset(1, scheduled).To(BeNumerically(">", 0))
+}
+
+// podCommand asserts that Cmd succeeds in a pod with a volume provisioned with Parameters mounted at /mnt/test-1.
+// Image defaults to the test suite image.
+type podCommand struct {
+	Cmd        string
+	Image      string
+	Parameters map[string]string
+}
+
+func (a podCommand) String() string {
+	return fmt.Sprintf("command succeeds on a volume with parameters %v", a.Parameters)
+}
+
+func (a podCommand) assert(pc *paramContext) {
+	test := testsuites.DynamicallyProvisionedCmdVolumeTest{
+		CSIDriver: driver.InitEbsCSIDriver(),
+		Pods: []testsuites.PodDetails{{
+			Cmd:   a.Cmd,
+			Image: a.Image,
+			Volumes: []testsuites.VolumeDetails{{
+				CreateVolumeParameters: a.Parameters,
+				ClaimSize:              driver.MinimumSizeForVolumeType(awscloud.VolumeTypeGP3),
+				VolumeMount:            testsuites.DefaultGeneratedVolumeMount,
+			}},
+		}},
+	}
+	test.Run(pc.cs, pc.ns)
+}
+
+// volumeTags asserts that a gp3 volume provisioned in the test namespace is tagged with the tags returned by Tags.
+// Tags is called when the assertion runs, so that it can read values files or use the namespace.
+type volumeTags struct {
+	Tags func(ns *v1.Namespace) map[string]string
+}
+
+func (a volumeTags) String() string {
+	return "provisioned volume has tags"
+}
+
+func (a volumeTags) assert(pc *paramContext) {
+	tags := a.Tags(pc.ns)
+	test := testsuites.DynamicallyProvisionedCmdVolumeTest{
+		CSIDriver: driver.InitEbsCSIDriver(),
+		Pods: []testsuites.PodDetails{{
+			Cmd: testsuites.PodCmdWriteToVolume("/mnt/test-1"),
+			Volumes: []testsuites.VolumeDetails{{
+				CreateVolumeParameters: map[string]string{
+					ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3,
+				},
+				ClaimSize:   driver.MinimumSizeForVolumeType(awscloud.VolumeTypeGP3),
+				VolumeMount: testsuites.DefaultGeneratedVolumeMount,
+			}},
+		}},
+		ValidateFunc: func() {
+			for key, value := range tags {
+				result, err := pc.ec2().DescribeVolumes(context.Background(), &ec2.DescribeVolumesInput{
+					Filters: []types.Filter{{
+						Name:   aws.String("tag:" + key),
+						Values: []string{value},
+					}},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Volumes).NotTo(BeEmpty(), "Should find volume with tag %s=%s", key, value)
+			}
+		},
+	}
+	test.Run(pc.cs, pc.ns)
+}
+
+const (
+	logWaitTimeout  = 2 * time.Minute
+	logWaitInterval = 5 * time.Second
+)
+
+// containerLog asserts that the lines logged by the Container of the pods of Workload satisfy Match. Only lines logged
+// after the assertion starts are checked. With Provision, a volume is provisioned and used by a pod first, so that
+// the controller and node make AWS API calls, mount, and log about it.
+type containerLog struct {
+	Workload    workload
+	Container   string
+	Description string
+	Provision   bool
+	Match       func(lines []string) error
+}
+
+func (a containerLog) String() string {
+	return fmt.Sprintf("%s container of %s logs %s", a.Container, a.Workload, a.Description)
+}
+
+func (a containerLog) assert(pc *paramContext) {
+	// Pod log timestamps have second precision, and the node clock may be a little behind.
+	since := time.Now().Add(-time.Second)
+	if a.Provision {
+		podCommand{
+			Cmd:        testsuites.PodCmdWriteToVolume("/mnt/test-1"),
+			Parameters: map[string]string{ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3},
+		}.assert(pc)
+	}
+	err := testsuites.WaitForContainerLogs(pc.cs, ebsNamespace, string(a.Workload), a.Container, since, a.Match, logWaitTimeout, logWaitInterval)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+}
+
+// logContains returns a containerLog matcher that requires a line matching pattern.
+func logContains(pattern string) func(lines []string) error {
+	re := regexp.MustCompile(pattern)
+	return func(lines []string) error {
+		if slices.ContainsFunc(lines, re.MatchString) {
+			return nil
+		}
+		return fmt.Errorf("none of %d lines matches %q", len(lines), pattern)
+	}
+}
+
+// logIsJSON returns a containerLog matcher that requires at least one line, and every line to be a JSON object with a
+// message, as logged by klog with --logging-format=json.
+func logIsJSON() func(lines []string) error {
+	return func(lines []string) error {
+		if len(lines) == 0 {
+			return errors.New("no lines logged")
+		}
+		for _, line := range lines {
+			var entry struct {
+				Msg *string `json:"msg"`
+			}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Msg == nil {
+				return fmt.Errorf("line is not a JSON log entry: %s", line)
+			}
+		}
+		return nil
+	}
+}
+
+// describeParamTests generates a spec for each paramTest.
+func describeParamTests(tests []paramTest) bool {
+	return Describe("[ebs-csi-e2e] Parameter Tests", func() {
+		f := framework.NewDefaultFramework("ebs")
+		f.NamespacePodSecurityEnforceLevel = admissionapi.LevelPrivileged
+
+		for _, pt := range tests {
+			It(fmt.Sprintf("[param:%s] should %s", pt.Param, pt.Description), Label("param:"+pt.Param), func() {
+				pc := &paramContext{cs: f.ClientSet, ns: f.Namespace}
+				for _, a := range pt.Assertions {
+					By(a.String())
+					a.assert(pc)
+				}
+			})
+		}
+	})
+}
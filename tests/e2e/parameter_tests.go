@@ -17,27 +17,14 @@ limitations under the License.
 package e2e
 
 import (
-	"context"
 	"os"
 	"path/filepath"
 	"runtime"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
-	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	awscloud "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
 	ebscsidriver "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver"
-	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/driver"
-	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/testsuites"
-	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	clientset "k8s.io/client-go/kubernetes"
-	"k8s.io/kubernetes/test/e2e/framework"
-	admissionapi "k8s.io/pod-security-admission/api"
 	"sigs.k8s.io/yaml"
 )
 
@@ -46,6 +33,9 @@ import (
 //
 // Expected values are loaded from the shared values YAML files in tests/helm-template/testdata/
 // so that the same file drives both helm install (via param-sets.sh) and test assertions.
+//
+// To cover a parameter, add an entry to parameterTests and focus on it from a parameter set in
+// hack/e2e/param-sets.sh. The assertion types are in parameter_harness.go.
 
 const (
 	controllerLabel    = "app=ebs-csi-controller"
@@ -70,190 +60,10 @@ func loadValues(name string, out interface{}) {
 	ExpectWithOffset(1, yaml.Unmarshal(data, out)).NotTo(HaveOccurred(), "failed to parse values file %s", path)
 }
 
-func defaultGP3Pods() []testsuites.PodDetails {
-	return []testsuites.PodDetails{{
-		Cmd: testsuites.PodCmdWriteToVolume("/mnt/test-1"),
-		Volumes: []testsuites.VolumeDetails{{
-			CreateVolumeParameters: map[string]string{
-				ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3,
-				ebscsidriver.FSTypeKey:     ebscsidriver.FSTypeExt4,
-			},
-			ClaimSize:   driver.MinimumSizeForVolumeType(awscloud.VolumeTypeGP3),
-			VolumeMount: testsuites.DefaultGeneratedVolumeMount,
-		}},
-	}}
-}
-
-func defaultGP3PodsNoFsType() []testsuites.PodDetails {
-	return []testsuites.PodDetails{{
-		Cmd: testsuites.PodCmdWriteToVolume("/mnt/test-1"),
-		Volumes: []testsuites.VolumeDetails{{
-			CreateVolumeParameters: map[string]string{
-				ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3,
-			},
-			ClaimSize:   driver.MinimumSizeForVolumeType(awscloud.VolumeTypeGP3),
-			VolumeMount: testsuites.DefaultGeneratedVolumeMount,
-		}},
-	}}
-}
-
-var _ = Describe("[ebs-csi-e2e] [param:extraCreateMetadata]", func() {
-	f := framework.NewDefaultFramework("ebs")
-	f.NamespacePodSecurityEnforceLevel = admissionapi.LevelPrivileged
-
-	var (
-		cs        clientset.Interface
-		ns        *v1.Namespace
-		ebsDriver driver.PVTestDriver
-		ec2Client *ec2.Client
-	)
-
-	BeforeEach(func() {
-		cs = f.ClientSet
-		ns = f.Namespace
-		ebsDriver = driver.InitEbsCSIDriver()
-		cfg, err := config.LoadDefaultConfig(context.Background())
-		Expect(err).NotTo(HaveOccurred())
-		ec2Client = ec2.NewFromConfig(cfg)
-	})
-
-	It("should add PVC namespace tag to provisioned volume", func() {
-		test := testsuites.DynamicallyProvisionedCmdVolumeTest{
-			CSIDriver: ebsDriver,
-			Pods:      defaultGP3Pods(),
-			ValidateFunc: func() {
-				result, err := ec2Client.DescribeVolumes(context.Background(), &ec2.DescribeVolumesInput{
-					Filters: []types.Filter{{
-						Name:   aws.String("tag:kubernetes.io/created-for/pvc/namespace"),
-						Values: []string{ns.Name},
-					}},
-				})
-				Expect(err).NotTo(HaveOccurred())
-				Expect(result.Volumes).NotTo(BeEmpty(), "Should find volume with PVC namespace tag")
-			},
-		}
-		test.Run(cs, ns)
-	})
-})
-
-var _ = Describe("[ebs-csi-e2e] [param:k8sTagClusterId]", func() {
-	f := framework.NewDefaultFramework("ebs")
-	f.NamespacePodSecurityEnforceLevel = admissionapi.LevelPrivileged
-
-	var (
-		cs        clientset.Interface
-		ns        *v1.Namespace
-		ebsDriver driver.PVTestDriver
-		ec2Client *ec2.Client
-		vals      standardValues
-	)
-
-	BeforeEach(func() {
-		cs = f.ClientSet
-		ns = f.Namespace
-		ebsDriver = driver.InitEbsCSIDriver()
-		cfg, err := config.LoadDefaultConfig(context.Background())
-		Expect(err).NotTo(HaveOccurred())
-		ec2Client = ec2.NewFromConfig(cfg)
-		loadValues("e2e-standard", &vals)
-	})
-
-	It("should tag volume with cluster ID", func() {
-		test := testsuites.DynamicallyProvisionedCmdVolumeTest{
-			CSIDriver: ebsDriver,
-			Pods:      defaultGP3PodsNoFsType(),
-			ValidateFunc: func() {
-				result, err := ec2Client.DescribeVolumes(context.Background(), &ec2.DescribeVolumesInput{
-					Filters: []types.Filter{{
-						Name:   aws.String("tag:kubernetes.io/cluster/" + vals.Controller.K8sTagClusterId),
-						Values: []string{"owned"},
-					}},
-				})
-				Expect(err).NotTo(HaveOccurred())
-				Expect(result.Volumes).NotTo(BeEmpty(), "Should find volume with cluster ID tag")
-			},
-		}
-		test.Run(cs, ns)
-	})
-})
-
-var _ = Describe("[ebs-csi-e2e] [param:extraVolumeTags]", func() {
-	f := framework.NewDefaultFramework("ebs")
-	f.NamespacePodSecurityEnforceLevel = admissionapi.LevelPrivileged
-
-	var (
-		cs        clientset.Interface
-		ns        *v1.Namespace
-		ebsDriver driver.PVTestDriver
-		ec2Client *ec2.Client
-		vals      standardValues
-	)
-
-	BeforeEach(func() {
-		cs = f.ClientSet
-		ns = f.Namespace
-		ebsDriver = driver.InitEbsCSIDriver()
-		cfg, err := config.LoadDefaultConfig(context.Background())
-		Expect(err).NotTo(HaveOccurred())
-		ec2Client = ec2.NewFromConfig(cfg)
-		loadValues("e2e-standard", &vals)
-	})
-
-	It("should add extra volume tags from Helm values", func() {
-		for key, value := range vals.Controller.ExtraVolumeTags {
-			test := testsuites.DynamicallyProvisionedCmdVolumeTest{
-				CSIDriver: ebsDriver,
-				Pods:      defaultGP3PodsNoFsType(),
-				ValidateFunc: func() {
-					result, err := ec2Client.DescribeVolumes(context.Background(), &ec2.DescribeVolumesInput{
-						Filters: []types.Filter{{
-							Name:   aws.String("tag:" + key),
-							Values: []string{value},
-						}},
-					})
-					Expect(err).NotTo(HaveOccurred())
-					Expect(result.Volumes).NotTo(BeEmpty(), "Should find volume with extra tag %s=%s", key, value)
-				},
-			}
-			test.Run(cs, ns)
-		}
-	})
-})
-
-var _ = Describe("[ebs-csi-e2e] Live Cluster Parameter Tests", func() {
-	f := framework.NewDefaultFramework("ebs")
-	f.NamespacePodSecurityEnforceLevel = admissionapi.LevelPrivileged
-
-	var cs clientset.Interface
-
-	BeforeEach(func() {
-		cs = f.ClientSet
-	})
-
-	It("[param:defaultFsType] should use xfs as default filesystem when not specified in StorageClass", func() {
-		pods := []testsuites.PodDetails{{
-			Cmd: "mount | grep /mnt/test-1 | grep xfs",
-			Volumes: []testsuites.VolumeDetails{{
-				CreateVolumeParameters: map[string]string{
-					ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3,
-				},
-				ClaimSize:   driver.MinimumSizeForVolumeType(awscloud.VolumeTypeGP3),
-				VolumeMount: testsuites.DefaultGeneratedVolumeMount,
-			}},
-		}}
-		test := testsuites.DynamicallyProvisionedCmdVolumeTest{
-			CSIDriver: driver.InitEbsCSIDriver(),
-			Pods:      pods,
-		}
-		test.Run(cs, f.Namespace)
-	})
-
-	It("[param:legacyXFS] should format XFS volumes with reflink disabled when legacyXFS is enabled", func() {
-		// node.legacyXFS=true makes the driver pass `-m reflink=0` to
-		// mkfs.xfs. FICLONE returns EOPNOTSUPP on a filesystem formatted without reflink, so
-		// `cp --reflink=always` exits non-zero with "Operation not
-		// supported". busybox's cp has no --reflink, so use amazonlinux.
-		const reflinkProbe = `set -e
+// reflinkProbe fails unless cp --reflink=always fails with "Operation not supported" on /mnt/test-1.
+// node.legacyXFS=true makes the driver pass `-m reflink=0` to mkfs.xfs, and FICLONE returns EOPNOTSUPP on a
+// filesystem formatted without reflink. busybox's cp has no --reflink, so it runs in amazonlinux.
+const reflinkProbe = `set -e
 dd if=/dev/zero of=/mnt/test-1/src bs=4k count=4 status=none
 if cp --reflink=always /mnt/test-1/src /mnt/test-1/dst 2>/tmp/cp.err; then
   echo "FAIL: cp --reflink=always succeeded; expected reflink to be disabled" >&2
@@ -266,83 +76,123 @@ if ! grep -qi "not supported" /tmp/cp.err; then
 fi
 echo "PASS: reflink is disabled on /mnt/test-1"
 `
-		pods := []testsuites.PodDetails{{
-			Cmd:   reflinkProbe,
-			Image: "public.ecr.aws/amazonlinux/amazonlinux:2023",
-			Volumes: []testsuites.VolumeDetails{{
-				CreateVolumeParameters: map[string]string{
+
+var parameterTests = []paramTest{
+	{
+		Param:       "extraCreateMetadata",
+		Description: "add PVC namespace tag to provisioned volume",
+		Assertions: []paramAssertion{
+			volumeTags{Tags: func(ns *v1.Namespace) map[string]string {
+				return map[string]string{"kubernetes.io/created-for/pvc/namespace": ns.Name}
+			}},
+		},
+	},
+	{
+		Param:       "k8sTagClusterId",
+		Description: "tag volume with cluster ID",
+		Assertions: []paramAssertion{
+			volumeTags{Tags: func(*v1.Namespace) map[string]string {
+				var vals standardValues
+				loadValues("e2e-standard", &vals)
+				return map[string]string{"kubernetes.io/cluster/" + vals.Controller.K8sTagClusterId: "owned"}
+			}},
+		},
+	},
+	{
+		Param:       "extraVolumeTags",
+		Description: "add extra volume tags from Helm values",
+		Assertions: []paramAssertion{
+			volumeTags{Tags: func(*v1.Namespace) map[string]string {
+				var vals standardValues
+				loadValues("e2e-standard", &vals)
+				return vals.Controller.ExtraVolumeTags
+			}},
+		},
+	},
+	{
+		Param:       "defaultFsType",
+		Description: "use xfs as default filesystem when not specified in StorageClass",
+		Assertions: []paramAssertion{
+			podCommand{
+				Cmd:        "mount | grep /mnt/test-1 | grep xfs",
+				Parameters: map[string]string{ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3},
+			},
+		},
+	},
+	{
+		Param:       "legacyXFS",
+		Description: "format XFS volumes with reflink disabled when legacyXFS is enabled",
+		Assertions: []paramAssertion{
+			podCommand{
+				Cmd:   reflinkProbe,
+				Image: "public.ecr.aws/amazonlinux/amazonlinux:2023",
+				Parameters: map[string]string{
 					ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3,
 					ebscsidriver.FSTypeKey:     ebscsidriver.FSTypeXfs,
 				},
-				ClaimSize:   driver.MinimumSizeForVolumeType(awscloud.VolumeTypeGP3),
-				VolumeMount: testsuites.DefaultGeneratedVolumeMount,
-			}},
-		}}
-		test := testsuites.DynamicallyProvisionedCmdVolumeTest{
-			CSIDriver: driver.InitEbsCSIDriver(),
-			Pods:      pods,
-		}
-		test.Run(cs, f.Namespace)
-	})
-
-	It("[param:nodeComponentOnly] should deploy only node DaemonSet without controller", func() {
-		_, err := cs.AppsV1().Deployments(ebsNamespace).Get(context.Background(), "ebs-csi-controller", metav1.GetOptions{})
-		Expect(apierrors.IsNotFound(err)).To(BeTrue(), "Controller deployment should not exist, but got error: %v", err)
-
-		ds, err := cs.AppsV1().DaemonSets(ebsNamespace).Get(context.Background(), "ebs-csi-node", metav1.GetOptions{})
-		Expect(err).NotTo(HaveOccurred())
-		Expect(ds.Status.DesiredNumberScheduled).To(BeNumerically(">", 0))
-	})
-
-	It("[param:fips] should enable FIPS mode via container environment", func() {
-		// FIPS is a runtime toggle: the driver ships a single image built with
-		// GOFIPS140=certified, and fips=true activates the Go FIPS 140-3 module
-		// via GODEBUG=fips140=on plus AWS FIPS endpoints. There is no separate
+			},
+		},
+	},
+	{
+		Param:       "nodeComponentOnly",
+		Description: "deploy only node DaemonSet without controller",
+		Assertions: []paramAssertion{
+			workloadResource{Kind: deploymentKind, Name: "ebs-csi-controller", Absent: true},
+			workloadResource{Kind: daemonSetKind, Name: "ebs-csi-node"},
+		},
+	},
+	{
+		// FIPS is a runtime toggle: the driver ships a single image built with GOFIPS140=certified, and fips=true
+		// activates the Go FIPS 140-3 module via GODEBUG=fips140=on plus AWS FIPS endpoints. There is no separate
 		// -fips image to assert on.
-		pods, err := cs.CoreV1().Pods(ebsNamespace).List(context.Background(), metav1.ListOptions{
-			LabelSelector: controllerLabel,
-		})
-		Expect(err).NotTo(HaveOccurred())
-		Expect(pods.Items).NotTo(BeEmpty())
-		var foundPlugin bool
-		for _, pod := range pods.Items {
-			for _, c := range pod.Spec.Containers {
-				if c.Name != ebsPluginContainer {
-					continue
-				}
-				foundPlugin = true
-				env := map[string]string{}
-				for _, e := range c.Env {
-					env[e.Name] = e.Value
-				}
-				Expect(env).To(HaveKeyWithValue("GODEBUG", "fips140=on"), "ebs-plugin should run with GODEBUG=fips140=on to activate the FIPS 140-3 module")
-				Expect(env).To(HaveKeyWithValue("AWS_USE_FIPS_ENDPOINT", "true"), "ebs-plugin should use AWS FIPS endpoints")
-			}
-		}
-		Expect(foundPlugin).To(BeTrue(), "controller pod should have an ebs-plugin container")
-	})
-
-	It("[param:metadataLabeler] should label nodes with EBS volume and ENI counts", func() {
-		nodes, err := cs.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
-		Expect(err).NotTo(HaveOccurred())
-		Expect(nodes.Items).NotTo(BeEmpty())
-
-		var foundVolumeLabel, foundENILabel bool
-		for _, node := range nodes.Items {
-			if _, ok := node.Labels["ebs.csi.aws.com/non-csi-ebs-volumes-count"]; ok {
-				foundVolumeLabel = true
-			}
-			if _, ok := node.Labels["ebs.csi.aws.com/enis-count"]; ok {
-				foundENILabel = true
-			}
-		}
-		Expect(foundVolumeLabel).To(BeTrue(), "At least one node should have volume count label")
-		Expect(foundENILabel).To(BeTrue(), "At least one node should have ENI count label")
-	})
+		Param:       "fips",
+		Description: "enable FIPS mode via container environment",
+		Assertions: []paramAssertion{
+			containerEnv{Workload: controllerWorkload, Container: ebsPluginContainer, Name: "GODEBUG", Value: "fips140=on"},
+			containerEnv{Workload: controllerWorkload, Container: ebsPluginContainer, Name: "AWS_USE_FIPS_ENDPOINT", Value: "true"},
+		},
+	},
+	{
+		Param:       "metadataLabeler",
+		Description: "label nodes with EBS volume and ENI counts",
+		Assertions: []paramAssertion{
+			nodeLabel{Key: "ebs.csi.aws.com/non-csi-ebs-volumes-count"},
+			nodeLabel{Key: "ebs.csi.aws.com/enis-count"},
+		},
+	},
+	{
+		Param:       "sdkDebugLog",
+		Description: "log AWS SDK requests and responses",
+		Assertions: []paramAssertion{
+			containerLog{
+				Workload:    controllerWorkload,
+				Container:   ebsPluginContainer,
+				Description: "the CreateVolume request body",
+				Provision:   true,
+				Match:       logContains(`Action=CreateVolume`),
+			},
+		},
+	},
+	{
+		Param:       "loggingFormat",
+		Description: "log in JSON on the node when loggingFormat is json",
+		Assertions: []paramAssertion{
+			containerLog{
+				Workload:    nodeWorkload,
+				Container:   ebsPluginContainer,
+				Description: "only JSON entries",
+				Provision:   true,
+				Match:       logIsJSON(),
+			},
+		},
+	},
+	{
+		Param:       "additionalDaemonSets",
+		Description: "create additional node DaemonSet with scheduled pods",
+		Assertions: []paramAssertion{
+			workloadResource{Kind: daemonSetKind, Name: "ebs-csi-node-extra"},
+		},
+	},
+}
 
-	It("[param:additionalDaemonSets] should create additional node DaemonSet with scheduled pods", func() {
-		ds, err := cs.AppsV1().DaemonSets(ebsNamespace).Get(context.Background(), "ebs-csi-node-extra", metav1.GetOptions{})
-		Expect(err).NotTo(HaveOccurred())
-		Expect(ds.Status.DesiredNumberScheduled).To(BeNumerically(">", 0))
-	})
-})
+var _ = describeParamTests(parameterTests)
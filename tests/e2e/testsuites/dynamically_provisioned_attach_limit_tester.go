@@ -0,0 +1,225 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testsuites
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/driver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2enode "k8s.io/kubernetes/test/e2e/framework/node"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+)
+
+const (
+	// maxAttachLimitTestVolumes is the largest number of volumes the attach limit test creates to fill a node.
+	maxAttachLimitTestVolumes = 40
+	attachLimitTimeout        = 15 * time.Minute
+	attachLimitPoll           = 10 * time.Second
+	// volumeLimitSchedulingMessage is reported by the scheduler for the nodes where a pod would exceed the
+	// allocatable volume count of the CSINode.
+	volumeLimitSchedulingMessage = "exceed max volume count"
+)
+
+// DynamicallyProvisionedAttachLimitTest will provision a WaitForFirstConsumer StorageClass and fill a node up to the
+// allocatable volume count reported on its CSINode with a StatefulSet whose replicas must run on that node.
+// Validate that one more replica of the StatefulSet stays unschedulable because of the volume limit, and that a pod
+// that only prefers the full node is scheduled to another node and runs with its volume.
+type DynamicallyProvisionedAttachLimitTest struct {
+	CSIDriver driver.DynamicPVTestDriver
+	Volume    VolumeDetails
+}
+
+func (t *DynamicallyProvisionedAttachLimitTest) Run(client clientset.Interface, namespace *v1.Namespace) {
+	ctx := context.Background()
+	nodes, err := e2enode.GetReadySchedulableNodes(ctx, client)
+	framework.ExpectNoError(err)
+	if len(nodes.Items) < 2 {
+		Skip(fmt.Sprintf("the attach limit test requires at least 2 schedulable nodes, got %d", len(nodes.Items)))
+	}
+	node := nodes.Items[0]
+	hostname := node.Labels[v1.LabelHostname]
+
+	allocatable := csiNodeAllocatableCount(ctx, client, node.Name)
+	used := ebsVolumesInUseOnNode(ctx, client, node.Name)
+	free := allocatable - used
+	framework.Logf("node %s can attach %d volumes of the driver, %d are in use", node.Name, allocatable, used)
+	if free <= 0 || free > maxAttachLimitTestVolumes {
+		Skip(fmt.Sprintf("node %s can attach %d more volumes, the attach limit test supports between 1 and %d", node.Name, free, maxAttachLimitTestVolumes))
+	}
+
+	bindingMode := storagev1.VolumeBindingWaitForFirstConsumer
+	tsc := NewTestStorageClass(client, namespace, t.CSIDriver.GetDynamicProvisionStorageClass(t.Volume.CreateVolumeParameters, t.Volume.MountOptions, t.Volume.ReclaimPolicy, t.Volume.AllowVolumeExpansion, &bindingMode, nil, namespace.Name))
+	sc := tsc.Create()
+	defer tsc.Cleanup()
+
+	By(fmt.Sprintf("filling node %s with %d volumes", node.Name, free))
+	ss, err := client.AppsV1().StatefulSets(namespace.Name).Create(ctx, newNodeFillingStatefulSet(sc.Name, t.Volume.ClaimSize, hostname, int32(free)), metav1.CreateOptions{})
+	framework.ExpectNoError(err)
+	defer func() {
+		framework.ExpectNoError(client.AppsV1().StatefulSets(namespace.Name).Delete(ctx, ss.Name, metav1.DeleteOptions{}))
+	}()
+	Eventually(func() (int32, error) {
+		current, err := client.AppsV1().StatefulSets(ss.Namespace).Get(ctx, ss.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return current.Status.ReadyReplicas, nil
+	}, attachLimitTimeout, attachLimitPoll).Should(BeNumerically("==", free), "StatefulSet %s should fill node %s", ss.Name, node.Name)
+
+	By(fmt.Sprintf("scaling up the StatefulSet past the volume limit of node %s", node.Name))
+	patch := fmt.Appendf(nil, `{"spec":{"replicas":%d}}`, free+1)
+	_, err = client.AppsV1().StatefulSets(namespace.Name).Patch(ctx, ss.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	framework.ExpectNoError(err)
+	extraReplica := fmt.Sprintf("%s-%d", ss.Name, free)
+	Eventually(func() (string, error) {
+		pod, err := client.CoreV1().Pods(namespace.Name).Get(ctx, extraReplica, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		for _, c := range pod.Status.Conditions {
+			if c.Type == v1.PodScheduled && c.Status == v1.ConditionFalse {
+				return c.Message, nil
+			}
+		}
+		return string(pod.Status.Phase), nil
+	}, attachLimitTimeout, attachLimitPoll).Should(ContainSubstring(volumeLimitSchedulingMessage), "replica %s should not be scheduled to the full node", extraReplica)
+
+	By(fmt.Sprintf("deploying a pod that prefers the full node %s", node.Name))
+	tpvc := NewTestPersistentVolumeClaim(client, namespace, t.Volume.ClaimSize, t.Volume.VolumeMode, &sc, t.Volume.AccessMode)
+	tpvc.Create()
+	defer tpvc.Cleanup()
+	tpod := NewTestPod(client, namespace, PodCmdWriteToVolume("/mnt/test-1")+" && sleep 3600")
+	tpod.SetupVolume(tpvc.persistentVolumeClaim, "test-volume-1", "/mnt/test-1", false)
+	tpod.SetAffinity(&v1.Affinity{
+		NodeAffinity: &v1.NodeAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{{
+				Weight: 100,
+				Preference: v1.NodeSelectorTerm{
+					MatchExpressions: []v1.NodeSelectorRequirement{{
+						Key:      v1.LabelHostname,
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{hostname},
+					}},
+				},
+			}},
+		},
+	})
+	tpod.Create()
+	defer tpod.Cleanup()
+	framework.ExpectNoError(e2epod.WaitTimeoutForPodRunningInNamespace(ctx, client, tpod.GetName(), namespace.Name, attachLimitTimeout))
+	pod, err := client.CoreV1().Pods(namespace.Name).Get(ctx, tpod.GetName(), metav1.GetOptions{})
+	framework.ExpectNoError(err)
+	Expect(pod.Spec.NodeName).NotTo(Equal(node.Name), "pod should be scheduled to a node that is not full")
+}
+
+// csiNodeAllocatableCount returns the number of volumes of the driver that the CSINode of a node can attach.
+func csiNodeAllocatableCount(ctx context.Context, client clientset.Interface, nodeName string) int {
+	csiNode, err := client.StorageV1().CSINodes().Get(ctx, nodeName, metav1.GetOptions{})
+	framework.ExpectNoError(err)
+	for _, d := range csiNode.Spec.Drivers {
+		if d.Name == util.GetDriverName() {
+			Expect(d.Allocatable).NotTo(BeNil(), "CSINode %s should report the allocatable volumes of the driver", nodeName)
+			Expect(d.Allocatable.Count).NotTo(BeNil(), "CSINode %s should report the allocatable volumes of the driver", nodeName)
+			return int(*d.Allocatable.Count)
+		}
+	}
+	Fail(fmt.Sprintf("CSINode %s has no driver %s", nodeName, util.GetDriverName()))
+	return 0
+}
+
+// ebsVolumesInUseOnNode returns the number of volumes of the driver used by the pods on a node, which the
+// scheduler counts against the allocatable volume count of the CSINode.
+func ebsVolumesInUseOnNode(ctx context.Context, client clientset.Interface, nodeName string) int {
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: "spec.nodeName=" + nodeName})
+	framework.ExpectNoError(err)
+	volumes := map[string]struct{}{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			pvc, err := client.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, volume.PersistentVolumeClaim.ClaimName, metav1.GetOptions{})
+			if err != nil || pvc.Spec.VolumeName == "" {
+				continue
+			}
+			pv, err := client.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+			if err != nil || pv.Spec.CSI == nil || !strings.EqualFold(pv.Spec.CSI.Driver, util.GetDriverName()) {
+				continue
+			}
+			volumes[pv.Spec.CSI.VolumeHandle] = struct{}{}
+		}
+	}
+	return len(volumes)
+}
+
+func newNodeFillingStatefulSet(storageClassName, claimSize, hostname string, replicas int32) *apps.StatefulSet {
+	labels := map[string]string{"app": "ebs-attach-limit-tester"}
+	return &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "ebs-attach-limit-tester",
+		},
+		Spec: apps.StatefulSetSpec{
+			Replicas:            &replicas,
+			PodManagementPolicy: apps.ParallelPodManagement,
+			Selector:            &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					NodeSelector: map[string]string{v1.LabelHostname: hostname},
+					Containers: []v1.Container{{
+						Name:    "volume-tester",
+						Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+						Command: []string{"/bin/sh"},
+						Args:    []string{"-c", "while true; do sleep 5; done"},
+						VolumeMounts: []v1.VolumeMount{{
+							Name:      "data",
+							MountPath: "/mnt/data",
+						}},
+					}},
+				},
+			},
+			VolumeClaimTemplates: []v1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				Spec: v1.PersistentVolumeClaimSpec{
+					AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+					StorageClassName: &storageClassName,
+					Resources: v1.VolumeResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(claimSize)},
+					},
+				},
+			}},
+		},
+	}
+}
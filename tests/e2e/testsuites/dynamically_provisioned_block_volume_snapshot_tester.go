@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testsuites
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/driver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	restclientset "k8s.io/client-go/rest"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+const (
+	// blockDataMiB is how much random data is written to the start of the source block volume.
+	blockDataMiB = 64
+	blockDevice  = "/dev/xvda"
+)
+
+var checksumPattern = regexp.MustCompile(`checksum=([0-9a-f]{64})`)
+
+// blockChecksumCmd prints the checksum of the data at the start of the block device.
+var blockChecksumCmd = fmt.Sprintf("dd if=%s bs=1M count=%d 2>/dev/null | sha256sum | cut -d' ' -f1", blockDevice, blockDataMiB)
+
+// DynamicallyProvisionedBlockVolumeSnapshotTest will provision required StorageClass(es), VolumeSnapshotClass(es),
+// a raw block PVC and Pod(s)
+// Write random data to the block device with dd and record its checksum
+// Take a snapshot of the volume and restore it into a raw block PVC, validating the checksum of the restored data
+// Expand the restored PVC, validating the device grew and the checksum is unchanged
+// Restore the snapshot into a filesystem PVC, validating the volume is formatted and can be written to
+// And finally delete the snapshot.
+type DynamicallyProvisionedBlockVolumeSnapshotTest struct {
+	CSIDriver driver.PVTestDriver
+	// Volume is the source volume. Its VolumeMode must be Block and its StorageClass must allow volume expansion.
+	Volume VolumeDetails
+}
+
+func (t *DynamicallyProvisionedBlockVolumeSnapshotTest) Run(client clientset.Interface, restclient restclientset.Interface, namespace *v1.Namespace) {
+	Expect(t.Volume.VolumeMode).To(Equal(Block), "source volume must be a raw block volume")
+	tpvc, pvcCleanup := t.Volume.SetupDynamicPersistentVolumeClaim(client, namespace, t.CSIDriver)
+	for i := range pvcCleanup {
+		defer pvcCleanup[i]()
+	}
+
+	By("writing random data to the raw block volume")
+	// dd with conv=fsync flushes the data to the EBS volume before the snapshot is taken
+	writeCmd := fmt.Sprintf("dd if=/dev/urandom of=/tmp/data bs=1M count=%d && dd if=/tmp/data of=%s bs=1M conv=fsync && echo checksum=$(%s)", blockDataMiB, blockDevice, blockChecksumCmd)
+	logs := t.runBlockPod(client, namespace, tpvc, writeCmd)
+	match := checksumPattern.FindSubmatch(logs)
+	Expect(match).NotTo(BeNil(), "pod did not print the checksum of the data it wrote: %s", logs)
+	checksum := string(match[1])
+	framework.Logf("wrote %d MiB of data with checksum %s to %s", blockDataMiB, checksum, tpvc.persistentVolumeClaim.Name)
+
+	By("taking a snapshot of the raw block volume")
+	tvsc, cleanup := CreateVolumeSnapshotClass(restclient, namespace, t.CSIDriver, nil)
+	defer cleanup()
+	snapshot := tvsc.CreateSnapshot(tpvc.persistentVolumeClaim)
+	defer tvsc.DeleteSnapshot(snapshot)
+	tvsc.ReadyToUse(snapshot)
+
+	By("restoring the snapshot into a raw block volume")
+	restored := t.Volume
+	restored.DataSource = &DataSource{Name: snapshot.Name, Kind: VolumeSnapshotKind}
+	trpvc, rpvcCleanup := restored.SetupDynamicPersistentVolumeClaim(client, namespace, t.CSIDriver)
+	for i := range rpvcCleanup {
+		defer rpvcCleanup[i]()
+	}
+	verifyCmd := fmt.Sprintf(`[ "$(%s)" = "%s" ]`, blockChecksumCmd, checksum)
+	t.runBlockPod(client, namespace, trpvc, verifyCmd)
+
+	By("expanding the restored raw block volume")
+	size := ResizeTestPvc(client, namespace, trpvc, DefaultSizeIncreaseGi)
+	verifyCmd = fmt.Sprintf(`[ "$(blockdev --getsize64 %s)" -eq %d ] && %s`, blockDevice, size.Value(), verifyCmd)
+	t.runBlockPod(client, namespace, trpvc, verifyCmd)
+
+	By("restoring the snapshot into a filesystem volume")
+	// The snapshot holds no filesystem, so the driver formats the restored volume when it is first mounted
+	tvsc.AllowVolumeModeChange(snapshot)
+	restoredFS := restored
+	restoredFS.VolumeMode = FileSystem
+	tfspvc, fspvcCleanup := restoredFS.SetupDynamicPersistentVolumeClaim(client, namespace, t.CSIDriver)
+	for i := range fspvcCleanup {
+		defer fspvcCleanup[i]()
+	}
+	tfspod := NewTestPod(client, namespace, PodCmdWriteToVolume(DefaultMountPath))
+	tfspod.SetupVolume(tfspvc.persistentVolumeClaim, "test-volume-1", DefaultMountPath, false)
+	tfspod.Create()
+	defer tfspod.Cleanup()
+	By("checking that the filesystem volume can be written to")
+	tfspod.WaitForSuccess()
+}
+
+// runBlockPod runs cmd in a pod with tpvc attached as a raw block device, waits for it to succeed and returns its
+// logs. The pod is deleted before returning so that the next pod can attach the volume.
+func (t *DynamicallyProvisionedBlockVolumeSnapshotTest) runBlockPod(client clientset.Interface, namespace *v1.Namespace, tpvc *TestPersistentVolumeClaim, cmd string) []byte {
+	tpod := NewTestPod(client, namespace, cmd)
+	tpod.SetupRawBlockVolume(tpvc.persistentVolumeClaim, "test-block-volume-1", blockDevice)
+	By("deploying a pod with " + tpvc.persistentVolumeClaim.Name + " as a raw block device")
+	tpod.Create()
+	defer tpod.Cleanup()
+	By("checking that the pod's command exits with no error")
+	tpod.WaitForSuccess()
+	logs, err := tpod.Logs()
+	framework.ExpectNoError(err)
+	return logs
+}
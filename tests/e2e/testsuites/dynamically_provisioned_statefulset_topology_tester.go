@@ -0,0 +1,294 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testsuites
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/driver"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	e2epodoutput "k8s.io/kubernetes/test/e2e/framework/pod/output"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+)
+
+const (
+	statefulSetDataPath = "/mnt/data"
+	// statefulSetCmd records when the volume of a replica was first used, so that tests can tell it was kept.
+	statefulSetCmd = "[ -f " + statefulSetDataPath + "/marker ] || date +%s%N > " + statefulSetDataPath + "/marker; while true; do sleep 5; done"
+
+	zoneFailureTimeout = 5 * time.Minute
+	zoneFailurePoll    = 5 * time.Second
+)
+
+// DynamicallyProvisionedStatefulSetTopologyTest will provision a WaitForFirstConsumer StorageClass and a StatefulSet
+// with one replica per zone in Zones, spread across the zones, with a volume per replica from a claim template.
+// Validate each EBS volume was created in the zone of the node its replica was scheduled to.
+// Simulate the failure of the zone of the first replica by cordoning its nodes:
+// the replica must stay pending, since its volume cannot leave the zone, while a new replica gets a volume in one of
+// the remaining zones. Once the zone is uncordoned, the first replica must come back in the zone with its data.
+type DynamicallyProvisionedStatefulSetTopologyTest struct {
+	CSIDriver driver.DynamicPVTestDriver
+	Zones     []string
+	Volume    VolumeDetails
+}
+
+func (t *DynamicallyProvisionedStatefulSetTopologyTest) Run(client clientset.Interface, namespace *v1.Namespace) {
+	ctx := context.Background()
+	bindingMode := storagev1.VolumeBindingWaitForFirstConsumer
+	tsc := NewTestStorageClass(client, namespace, t.CSIDriver.GetDynamicProvisionStorageClass(t.Volume.CreateVolumeParameters, t.Volume.MountOptions, t.Volume.ReclaimPolicy, t.Volume.AllowVolumeExpansion, &bindingMode, nil, namespace.Name))
+	sc := tsc.Create()
+	defer tsc.Cleanup()
+
+	replicas := int32(len(t.Zones))
+	By(fmt.Sprintf("deploying a StatefulSet with one replica in each of the zones %v", t.Zones))
+	ss, err := client.AppsV1().StatefulSets(namespace.Name).Create(ctx, newZoneSpreadStatefulSet(sc.Name, t.Volume.ClaimSize, t.Zones, replicas), metav1.CreateOptions{})
+	framework.ExpectNoError(err)
+	defer func() {
+		framework.ExpectNoError(client.AppsV1().StatefulSets(namespace.Name).Delete(ctx, ss.Name, metav1.DeleteOptions{}))
+	}()
+	waitForStatefulSetReady(ctx, client, ss, replicas)
+
+	By("validating that every volume was created in the zone of its replica")
+	zones := validateStatefulSetVolumeZones(ctx, client, ss)
+	Expect(distinctValues(zones)).To(ConsistOf(t.Zones), "replicas should be spread across all zones")
+
+	failedPod := ss.Name + "-0"
+	failedZone := zones[failedPod]
+	marker := readMarker(namespace.Name, failedPod)
+
+	By(fmt.Sprintf("cordoning the nodes in zone %s", failedZone))
+	uncordon := cordonZone(ctx, client, failedZone)
+	defer uncordon()
+
+	By(fmt.Sprintf("deleting replica %s, whose volume is in the cordoned zone", failedPod))
+	framework.ExpectNoError(client.CoreV1().Pods(namespace.Name).Delete(ctx, failedPod, metav1.DeleteOptions{}))
+	Eventually(func() (string, error) {
+		pod, err := client.CoreV1().Pods(namespace.Name).Get(ctx, failedPod, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		for _, c := range pod.Status.Conditions {
+			if c.Type == v1.PodScheduled && c.Status == v1.ConditionFalse {
+				return c.Reason, nil
+			}
+		}
+		return string(pod.Status.Phase), nil
+	}, zoneFailureTimeout, zoneFailurePoll).Should(Equal(v1.PodReasonUnschedulable), "replica should not be scheduled out of the zone of its volume")
+
+	By("scaling up the StatefulSet while the zone is cordoned")
+	patch := fmt.Appendf(nil, `{"spec":{"replicas":%d}}`, replicas+1)
+	_, err = client.AppsV1().StatefulSets(namespace.Name).Patch(ctx, ss.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	framework.ExpectNoError(err)
+	newPod := fmt.Sprintf("%s-%d", ss.Name, replicas)
+	Eventually(func() error {
+		_, err := client.CoreV1().Pods(namespace.Name).Get(ctx, newPod, metav1.GetOptions{})
+		return err
+	}, zoneFailureTimeout, zoneFailurePoll).Should(Succeed(), "new replica should be created")
+	framework.ExpectNoError(e2epod.WaitTimeoutForPodReadyInNamespace(ctx, client, newPod, namespace.Name, zoneFailureTimeout))
+	zones = validateStatefulSetVolumeZones(ctx, client, ss)
+	Expect(zones[newPod]).NotTo(Equal(failedZone), "new replica should get a volume in a zone that is not cordoned")
+
+	By(fmt.Sprintf("uncordoning the nodes in zone %s", failedZone))
+	uncordon()
+	waitForStatefulSetReady(ctx, client, ss, replicas+1)
+	zones = validateStatefulSetVolumeZones(ctx, client, ss)
+	Expect(zones[failedPod]).To(Equal(failedZone), "replica should come back in the zone of its volume")
+	Expect(readMarker(namespace.Name, failedPod)).To(Equal(marker), "replica should come back with the data on its volume")
+}
+
+func newZoneSpreadStatefulSet(storageClassName, claimSize string, zones []string, replicas int32) *apps.StatefulSet {
+	labels := map[string]string{"app": "ebs-zone-spread-tester"}
+	honor := v1.NodeInclusionPolicyHonor
+	return &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "ebs-zone-spread-tester",
+		},
+		Spec: apps.StatefulSetSpec{
+			Replicas:            &replicas,
+			PodManagementPolicy: apps.ParallelPodManagement,
+			Selector:            &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: v1.PodSpec{
+					NodeSelector: map[string]string{v1.LabelOSStable: string(v1.Linux)},
+					Affinity: &v1.Affinity{
+						NodeAffinity: &v1.NodeAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+								NodeSelectorTerms: []v1.NodeSelectorTerm{{
+									MatchExpressions: []v1.NodeSelectorRequirement{{
+										Key:      v1.LabelTopologyZone,
+										Operator: v1.NodeSelectorOpIn,
+										Values:   zones,
+									}},
+								}},
+							},
+						},
+					},
+					// Cordoned nodes are tainted, so honoring taints takes a cordoned zone out of the spread.
+					TopologySpreadConstraints: []v1.TopologySpreadConstraint{{
+						MaxSkew:           1,
+						TopologyKey:       v1.LabelTopologyZone,
+						WhenUnsatisfiable: v1.DoNotSchedule,
+						LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+						NodeTaintsPolicy:  &honor,
+					}},
+					Containers: []v1.Container{{
+						Name:    "volume-tester",
+						Image:   imageutils.GetE2EImage(imageutils.BusyBox),
+						Command: []string{"/bin/sh"},
+						Args:    []string{"-c", statefulSetCmd},
+						VolumeMounts: []v1.VolumeMount{{
+							Name:      "data",
+							MountPath: statefulSetDataPath,
+						}},
+					}},
+				},
+			},
+			VolumeClaimTemplates: []v1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				Spec: v1.PersistentVolumeClaimSpec{
+					AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+					StorageClassName: &storageClassName,
+					Resources: v1.VolumeResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(claimSize)},
+					},
+				},
+			}},
+		},
+	}
+}
+
+// waitForStatefulSetReady waits until replicas replicas of ss are ready.
+func waitForStatefulSetReady(ctx context.Context, client clientset.Interface, ss *apps.StatefulSet, replicas int32) {
+	Eventually(func() (int32, error) {
+		current, err := client.AppsV1().StatefulSets(ss.Namespace).Get(ctx, ss.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+		return current.Status.ReadyReplicas, nil
+	}, zoneFailureTimeout, zoneFailurePoll).Should(Equal(replicas), "StatefulSet %s should have %d ready replicas", ss.Name, replicas)
+}
+
+// validateStatefulSetVolumeZones checks that the PV and EBS volume of every scheduled replica of ss are in the zone
+// of its node, and returns the zone of every scheduled replica by pod name.
+func validateStatefulSetVolumeZones(ctx context.Context, client clientset.Interface, ss *apps.StatefulSet) map[string]string {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	framework.ExpectNoError(err, "failed to load AWS config")
+	ec2Client := ec2.NewFromConfig(cfg)
+
+	selector, err := metav1.LabelSelectorAsSelector(ss.Spec.Selector)
+	framework.ExpectNoError(err)
+	pods, err := client.CoreV1().Pods(ss.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	framework.ExpectNoError(err)
+	zones := map[string]string{}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		node, err := client.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+		framework.ExpectNoError(err)
+		zone := node.Labels[v1.LabelTopologyZone]
+
+		pvc, err := client.CoreV1().PersistentVolumeClaims(ss.Namespace).Get(ctx, "data-"+pod.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err)
+		pv, err := client.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+		framework.ExpectNoError(err)
+		Expect(persistentVolumeZones(pv)).To(ContainElement(zone), "PV %s of %s should be restricted to zone %s", pv.Name, pod.Name, zone)
+
+		volumeID := pv.Spec.CSI.VolumeHandle
+		resp, err := ec2Client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{VolumeIds: []string{volumeID}})
+		framework.ExpectNoError(err, fmt.Sprintf("failed to describe volume %s", volumeID))
+		Expect(resp.Volumes).To(HaveLen(1))
+		Expect(*resp.Volumes[0].AvailabilityZone).To(Equal(zone), "volume %s of %s should be in zone %s", volumeID, pod.Name, zone)
+
+		framework.Logf("replica %s and its volume %s are in zone %s", pod.Name, volumeID, zone)
+		zones[pod.Name] = zone
+	}
+	return zones
+}
+
+// persistentVolumeZones returns the zones that the node affinity of pv allows.
+func persistentVolumeZones(pv *v1.PersistentVolume) []string {
+	var zones []string
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return zones
+	}
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Key == v1.LabelTopologyZone || expr.Key == "topology."+util.GetDriverName()+"/zone" {
+				zones = append(zones, expr.Values...)
+			}
+		}
+	}
+	return zones
+}
+
+// cordonZone marks every node in zone unschedulable, and returns a function that marks them schedulable again.
+func cordonZone(ctx context.Context, client clientset.Interface, zone string) func() {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: v1.LabelTopologyZone + "=" + zone})
+	framework.ExpectNoError(err)
+	Expect(nodes.Items).NotTo(BeEmpty(), "no nodes in zone %s", zone)
+	setUnschedulable := func(unschedulable bool) {
+		patch := fmt.Appendf(nil, `{"spec":{"unschedulable":%t}}`, unschedulable)
+		for _, node := range nodes.Items {
+			_, err := client.CoreV1().Nodes().Patch(ctx, node.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+			framework.ExpectNoError(err)
+		}
+	}
+	setUnschedulable(true)
+	uncordoned := false
+	return func() {
+		if !uncordoned {
+			uncordoned = true
+			setUnschedulable(false)
+		}
+	}
+}
+
+func readMarker(namespace, podName string) string {
+	marker, err := e2epodoutput.RunHostCmd(namespace, podName, "cat "+statefulSetDataPath+"/marker")
+	framework.ExpectNoError(err)
+	return strings.TrimSpace(marker)
+}
+
+func distinctValues(m map[string]string) []string {
+	seen := map[string]bool{}
+	var values []string
+	for _, v := range m {
+		if !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	return values
+}
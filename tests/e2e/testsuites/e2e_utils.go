@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -162,6 +163,46 @@ func WaitForVacToApplyToPv(c clientset.Interface, ns *v1.Namespace, pvName strin
 	return fmt.Errorf("gave up after waiting %v for pv %q to complete modifying via VAC", timeout, pvName)
 }
 
+// ContainerLogsSince returns the lines that the container of every pod matching selector logged since the given time.
+func ContainerLogsSince(c clientset.Interface, namespace, selector, container string, since time.Time) ([]string, error) {
+	pods, err := c.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, pod := range pods.Items {
+		logs, err := c.CoreV1().Pods(namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+			Container: container,
+			SinceTime: &metav1.Time{Time: since},
+		}).DoRaw(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("could not get logs of %s container of pod %s: %w", container, pod.Name, err)
+		}
+		for _, line := range strings.Split(string(logs), "\n") {
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines, nil
+}
+
+// WaitForContainerLogs waits until the lines logged by the container of the pods matching selector since the given
+// time satisfy match, which returns why they do not.
+func WaitForContainerLogs(c clientset.Interface, namespace, selector, container string, since time.Time, match func(lines []string) error, timeout time.Duration, interval time.Duration) error {
+	framework.Logf("waiting up to %v for logs of %s container of pods %q", timeout, container, selector)
+	var err error
+	for start := time.Now(); time.Since(start) < timeout; time.Sleep(interval) {
+		var lines []string
+		if lines, err = ContainerLogsSince(c, namespace, selector, container, since); err == nil {
+			if err = match(lines); err == nil {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("gave up after waiting %v for logs of %s container of pods %q: %w", timeout, container, selector, err)
+}
+
 func CreateVolumeDetails(createVolumeParameters map[string]string, volumeSize string) *VolumeDetails {
 	allowVolumeExpansion := true
 
@@ -61,6 +61,9 @@ const (
 	VolumeSnapshotContentKind = "VolumeSnapshotContent"
 	SnapshotAPIVersion        = "snapshot.storage.k8s.io/v1"
 	APIVersionv1              = "v1"
+
+	// AllowVolumeModeChangeAnnotation on a VolumeSnapshotContent allows restoring it into a PVC of another volume mode.
+	AllowVolumeModeChangeAnnotation = "snapshot.storage.kubernetes.io/allow-volume-mode-change"
 )
 
 var (
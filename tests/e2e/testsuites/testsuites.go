@@ -36,6 +36,7 @@ import (
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 	restclientset "k8s.io/client-go/rest"
@@ -205,6 +206,20 @@ func (t *TestVolumeSnapshotClass) ReadyToUse(snapshot *volumesnapshotv1.VolumeSn
 	framework.ExpectNoError(err)
 }
 
+// AllowVolumeModeChange annotates the VolumeSnapshotContent of snapshot so that it can be restored into a PVC of
+// another volume mode, which the CSI provisioner refuses by default. The snapshot must be ready to use.
+func (t *TestVolumeSnapshotClass) AllowVolumeModeChange(snapshot *volumesnapshotv1.VolumeSnapshot) {
+	By("allowing volume mode change for VolumeSnapshot " + snapshot.Name)
+	vs, err := snapshotclientset.New(t.client).SnapshotV1().VolumeSnapshots(t.namespace.Name).Get(context.Background(), snapshot.Name, metav1.GetOptions{})
+	framework.ExpectNoError(err)
+	if vs.Status == nil || vs.Status.BoundVolumeSnapshotContentName == nil {
+		framework.Failf("VolumeSnapshot %s is not bound to a VolumeSnapshotContent", snapshot.Name)
+	}
+	patch := fmt.Appendf(nil, `{"metadata":{"annotations":{%q:"true"}}}`, AllowVolumeModeChangeAnnotation)
+	_, err = snapshotclientset.New(t.client).SnapshotV1().VolumeSnapshotContents().Patch(context.Background(), *vs.Status.BoundVolumeSnapshotContentName, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	framework.ExpectNoError(err)
+}
+
 func (t *TestVolumeSnapshotClass) unlockSnapshot(vs *volumesnapshotv1.VolumeSnapshot) {
 	By("Unlocking Volume Snapshot " + vs.Name)
 	cfg, err := config.LoadDefaultConfig(context.Background())
@@ -765,6 +780,10 @@ func (t *TestPod) SetNodeSelector(nodeSelector map[string]string) {
 	t.pod.Spec.NodeSelector = nodeSelector
 }
 
+func (t *TestPod) SetAffinity(affinity *v1.Affinity) {
+	t.pod.Spec.Affinity = affinity
+}
+
 // SetImage overrides the default busybox image used by NewTestPod. This is
 // needed by tests whose command requires tools not present in busybox (for
 // example GNU coreutils' `cp --reflink`, or xfsprogs).
@@ -0,0 +1,188 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+   http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	awscloud "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	ebscsidriver "github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/driver"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/tests/e2e/testsuites"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	statsv1alpha1 "k8s.io/kubelet/pkg/apis/stats/v1alpha1"
+	"k8s.io/kubernetes/test/e2e/framework"
+	admissionapi "k8s.io/pod-security-admission/api"
+)
+
+const (
+	mib = 1 << 20
+	// volumeStatsWrittenMiB is the amount of data the pods write to their volume.
+	volumeStatsWrittenMiB = 200
+	// volumeStatsOverheadMiB is how much more than the written data a file system may report as used, for its
+	// metadata and log.
+	volumeStatsOverheadMiB = 100
+	// volumeStatsMinCapacityRatio is the smallest share of the volume size that a file system may report as its
+	// capacity.
+	volumeStatsMinCapacityRatio = 0.9
+	// Kubelet refreshes volume stats every minute by default.
+	volumeStatsTimeout      = 5 * time.Minute
+	volumeStatsPollInterval = 15 * time.Second
+)
+
+// getPVCVolumeStats returns the stats that kubelet of a node reports for a PVC, or nil if it reports none yet.
+func getPVCVolumeStats(ctx context.Context, cs clientset.Interface, nodeName, namespace, pvcName string) (*statsv1alpha1.VolumeStats, error) {
+	raw, err := cs.CoreV1().RESTClient().Get().Resource("nodes").Name(nodeName).SubResource("proxy").Suffix("stats/summary").DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats summary of node %s: %w", nodeName, err)
+	}
+	var summary statsv1alpha1.Summary
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		return nil, fmt.Errorf("failed to decode stats summary of node %s: %w", nodeName, err)
+	}
+	for _, pod := range summary.Pods {
+		for i, volume := range pod.VolumeStats {
+			if volume.PVCRef != nil && volume.PVCRef.Namespace == namespace && volume.PVCRef.Name == pvcName {
+				return &pod.VolumeStats[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+var _ = Describe("[ebs-csi-e2e] [single-az] Volume Stats", func() {
+	f := framework.NewDefaultFramework("ebs")
+	f.NamespacePodSecurityEnforceLevel = admissionapi.LevelPrivileged
+
+	var (
+		cs        clientset.Interface
+		ns        *v1.Namespace
+		ebsDriver driver.PVTestDriver
+	)
+
+	BeforeEach(func() {
+		cs = f.ClientSet
+		ns = f.Namespace
+		ebsDriver = driver.InitEbsCSIDriver()
+	})
+
+	claimSize := driver.MinimumSizeForVolumeType(awscloud.VolumeTypeGP3)
+	volumeSizeBytes := uint64(util.GiB)
+	writtenBytes := uint64(volumeStatsWrittenMiB * mib)
+
+	for _, fsType := range []string{ebscsidriver.FSTypeExt4, ebscsidriver.FSTypeXfs} {
+		It(fmt.Sprintf("should report the capacity and usage of a %s volume written with a known amount of data", fsType), func(ctx context.Context) {
+			pod := testsuites.PodDetails{
+				Cmd: fmt.Sprintf("dd if=/dev/urandom of=/mnt/test-1/data bs=1M count=%d && sync && sleep 3600", volumeStatsWrittenMiB),
+				Volumes: []testsuites.VolumeDetails{
+					{
+						CreateVolumeParameters: map[string]string{
+							ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3,
+							ebscsidriver.FSTypeKey:     fsType,
+						},
+						ClaimSize:   claimSize,
+						VolumeMount: testsuites.DefaultGeneratedVolumeMount,
+					},
+				},
+			}
+			stats := runVolumeStatsPod(ctx, cs, ns, ebsDriver, pod, func(stats *statsv1alpha1.VolumeStats) bool {
+				return stats.UsedBytes != nil && *stats.UsedBytes >= writtenBytes
+			})
+
+			Expect(stats.CapacityBytes).NotTo(BeNil(), "capacity not reported")
+			Expect(*stats.CapacityBytes).To(BeNumerically("<=", volumeSizeBytes), "capacity larger than the volume")
+			Expect(float64(*stats.CapacityBytes)).To(BeNumerically(">=", volumeStatsMinCapacityRatio*float64(volumeSizeBytes)), "capacity much smaller than the volume")
+			Expect(*stats.UsedBytes).To(BeNumerically("<=", writtenBytes+volumeStatsOverheadMiB*mib), "used bytes much larger than the written data")
+			Expect(stats.AvailableBytes).NotTo(BeNil(), "available bytes not reported")
+			Expect(*stats.AvailableBytes).To(BeNumerically("<=", *stats.CapacityBytes-*stats.UsedBytes), "available and used bytes exceed the capacity")
+			Expect(stats.Inodes).NotTo(BeNil(), "inodes not reported")
+			Expect(stats.InodesUsed).NotTo(BeNil(), "used inodes not reported")
+			Expect(*stats.InodesUsed).To(BeNumerically(">=", 1), "the written file is not counted in the used inodes")
+			Expect(*stats.InodesUsed).To(BeNumerically("<=", *stats.Inodes), "more inodes used than available")
+		})
+	}
+
+	It("should report the capacity of a block volume", func(ctx context.Context) {
+		pod := testsuites.PodDetails{
+			Cmd: fmt.Sprintf("dd if=/dev/zero of=/dev/xvda bs=1M count=%d && sync && sleep 3600", volumeStatsWrittenMiB),
+			Volumes: []testsuites.VolumeDetails{
+				{
+					CreateVolumeParameters: map[string]string{
+						ebscsidriver.VolumeTypeKey: awscloud.VolumeTypeGP3,
+					},
+					ClaimSize:  claimSize,
+					VolumeMode: testsuites.Block,
+					VolumeDevice: testsuites.VolumeDeviceDetails{
+						NameGenerate: "test-block-volume-",
+						DevicePath:   "/dev/xvda",
+					},
+				},
+			},
+		}
+		stats := runVolumeStatsPod(ctx, cs, ns, ebsDriver, pod, func(stats *statsv1alpha1.VolumeStats) bool {
+			return stats.CapacityBytes != nil
+		})
+
+		// The driver only reports the size of block devices, their usage is unknown.
+		Expect(*stats.CapacityBytes).To(Equal(volumeSizeBytes), "capacity is not the volume size")
+	})
+})
+
+// runVolumeStatsPod runs pod with a single dynamically provisioned volume and returns the stats kubelet reports for
+// the volume once ready returns true for them.
+func runVolumeStatsPod(ctx context.Context, cs clientset.Interface, ns *v1.Namespace, ebsDriver driver.PVTestDriver, pod testsuites.PodDetails, ready func(*statsv1alpha1.VolumeStats) bool) *statsv1alpha1.VolumeStats {
+	tpod, cleanup := pod.SetupWithDynamicVolumes(cs, ns, ebsDriver)
+	for i := range cleanup {
+		defer cleanup[i]()
+	}
+	By("deploying the pod")
+	tpod.Create()
+	defer tpod.Cleanup()
+	tpod.WaitForRunning()
+
+	p, err := cs.CoreV1().Pods(ns.Name).Get(ctx, tpod.GetName(), metav1.GetOptions{})
+	framework.ExpectNoError(err, "failed to get pod")
+	var pvcName string
+	for _, volume := range p.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			pvcName = volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+	Expect(pvcName).NotTo(BeEmpty(), "pod has no PVC")
+
+	By("checking the volume stats reported by kubelet")
+	var stats *statsv1alpha1.VolumeStats
+	Eventually(func(ctx context.Context) bool {
+		stats, err = getPVCVolumeStats(ctx, cs, p.Spec.NodeName, ns.Name, pvcName)
+		if err != nil {
+			framework.Logf("%v, retrying...", err)
+			return false
+		}
+		if stats == nil {
+			framework.Logf("no stats reported for PVC %s yet, retrying...", pvcName)
+			return false
+		}
+		return ready(stats)
+	}).WithContext(ctx).WithTimeout(volumeStatsTimeout).WithPolling(volumeStatsPollInterval).Should(BeTrue(), "kubelet did not report the expected stats of PVC %s", pvcName)
+	return stats
+}
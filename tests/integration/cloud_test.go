@@ -0,0 +1,144 @@
+//go:build integration
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package integration runs the cloud layer against a LocalStack-compatible EC2 emulator. Run it with
+// `make test/integration`, which starts LocalStack, or point AWS_EC2_ENDPOINT at a running emulator and run
+// `AWS_EC2_EMULATOR=true go test -tags integration ./tests/integration/...`.
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const defaultRegion = "us-east-1"
+
+func newEmulatorCloud(t *testing.T, batching bool) cloud.Cloud {
+	t.Helper()
+	if os.Getenv("AWS_EC2_ENDPOINT") == "" {
+		t.Skip("AWS_EC2_ENDPOINT is not set, run with `make test/integration`")
+	}
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = defaultRegion
+	}
+	return cloud.NewCloud(region, cloud.CloudOptions{UserAgentExtra: "integration", Batching: batching})
+}
+
+func TestVolumeLifecycle(t *testing.T) {
+	for _, batching := range []bool{false, true} {
+		t.Run(fmt.Sprintf("batching=%t", batching), func(t *testing.T) {
+			c := newEmulatorCloud(t, batching)
+			ctx := t.Context()
+
+			zones, err := c.AvailabilityZones(ctx)
+			require.NoError(t, err)
+			require.NotEmpty(t, zones)
+			var zone string
+			for z := range zones {
+				zone = z
+				break
+			}
+
+			name := fmt.Sprintf("pvc-integration-%d", time.Now().UnixNano())
+			disk, err := c.CreateDisk(ctx, name, &cloud.DiskOptions{
+				CapacityBytes:    util.GiBToBytes(1),
+				VolumeType:       cloud.VolumeTypeGP3,
+				AvailabilityZone: zone,
+				Tags:             map[string]string{cloud.VolumeNameTagKey: name, cloud.AwsEbsDriverTagKey: "true"},
+			})
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				// The volume is already deleted when the test passes
+				_, _ = c.DeleteDisk(t.Context(), disk.VolumeID)
+			})
+			assert.Equal(t, zone, disk.AvailabilityZone)
+			assert.Equal(t, int32(1), disk.CapacityGiB)
+
+			byID, err := c.GetDiskByID(ctx, disk.VolumeID)
+			require.NoError(t, err)
+			assert.Equal(t, disk.VolumeID, byID.VolumeID)
+
+			byName, err := c.GetDiskByName(ctx, name, util.GiBToBytes(1))
+			require.NoError(t, err)
+			assert.Equal(t, disk.VolumeID, byName.VolumeID)
+
+			err = c.ModifyTags(ctx, disk.VolumeID, cloud.ModifyTagsOptions{TagsToAdd: map[string]string{"integration": "true"}})
+			require.NoError(t, err)
+
+			snapshotName := "snapshot-" + name
+			snapshot, err := c.CreateSnapshot(ctx, disk.VolumeID, &cloud.SnapshotOptions{
+				Tags: map[string]string{cloud.SnapshotNameTagKey: snapshotName, cloud.AwsEbsDriverTagKey: "true"},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, disk.VolumeID, snapshot.SourceVolumeID)
+
+			byName2, err := c.GetSnapshotByName(ctx, snapshotName)
+			require.NoError(t, err)
+			assert.Equal(t, snapshot.SnapshotID, byName2.SnapshotID)
+
+			bySnapshotID, err := c.GetSnapshotByID(ctx, snapshot.SnapshotID)
+			require.NoError(t, err)
+			assert.Equal(t, snapshot.SnapshotID, bySnapshotID.SnapshotID)
+
+			list, err := c.ListSnapshots(ctx, disk.VolumeID, 0, "")
+			require.NoError(t, err)
+			ids := make([]string, 0, len(list.Snapshots))
+			for _, s := range list.Snapshots {
+				ids = append(ids, s.SnapshotID)
+			}
+			assert.Contains(t, ids, snapshot.SnapshotID)
+
+			deleted, err := c.DeleteSnapshot(ctx, snapshot.SnapshotID)
+			require.NoError(t, err)
+			assert.True(t, deleted)
+
+			deleted, err = c.DeleteDisk(ctx, disk.VolumeID)
+			require.NoError(t, err)
+			assert.True(t, deleted)
+
+			_, err = c.GetDiskByID(ctx, disk.VolumeID)
+			assert.True(t, errors.Is(err, cloud.ErrNotFound), "expected ErrNotFound after deletion, got %v", err)
+		})
+	}
+}
+
+func TestMissingResources(t *testing.T) {
+	c := newEmulatorCloud(t, false)
+	ctx := t.Context()
+
+	_, err := c.GetDiskByID(ctx, "vol-0123456789abcdef0")
+	require.ErrorIs(t, err, cloud.ErrNotFound)
+
+	_, err = c.DeleteDisk(ctx, "vol-0123456789abcdef0")
+	require.ErrorIs(t, err, cloud.ErrNotFound)
+
+	_, err = c.GetSnapshotByID(ctx, "snap-0123456789abcdef0")
+	require.ErrorIs(t, err, cloud.ErrNotFound)
+
+	_, err = c.DeleteSnapshot(ctx, "snap-0123456789abcdef0")
+	require.ErrorIs(t, err, cloud.ErrNotFound)
+}
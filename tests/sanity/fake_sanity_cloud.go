@@ -17,8 +17,11 @@ package sanity
 import (
 	"context"
 	"fmt"
+	"maps"
 	"math/rand"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -34,6 +37,15 @@ type fakeCloud struct {
 	disks            map[string]*cloud.Disk
 	snapshots        map[string]*cloud.Snapshot
 	snapshotNameToID map[string]string
+
+	// mu guards the fields below, which ModifyVolume calls running concurrently use.
+	mu sync.Mutex
+	// faults holds the errors the next calls to a method return instead of running it, by method name.
+	faults map[string][]error
+	// modifyOptions holds the properties each volume was last modified to.
+	modifyOptions map[string]cloud.ModifyDiskOptions
+	// modifications counts the modifications of each volume that changed its properties.
+	modifications map[string]int
 }
 
 func newFakeCloud(fmd *metadata.Metadata, mp string) *fakeCloud {
@@ -43,7 +55,34 @@ func newFakeCloud(fmd *metadata.Metadata, mp string) *fakeCloud {
 		disks:            make(map[string]*cloud.Disk),
 		snapshots:        make(map[string]*cloud.Snapshot),
 		snapshotNameToID: make(map[string]string),
+		faults:           make(map[string][]error),
+		modifyOptions:    make(map[string]cloud.ModifyDiskOptions),
+		modifications:    make(map[string]int),
+	}
+}
+
+// injectFault makes the next calls to method return errs, one per call, instead of running.
+func (d *fakeCloud) injectFault(method string, errs ...error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.faults[method] = append(d.faults[method], errs...)
+}
+
+// popFault returns the next error injected for method, if any. The caller must hold mu.
+func (d *fakeCloud) popFault(method string) error {
+	errs := d.faults[method]
+	if len(errs) == 0 {
+		return nil
 	}
+	d.faults[method] = errs[1:]
+	return errs[0]
+}
+
+// modificationCount returns how many modifications changed the properties of volumeID.
+func (d *fakeCloud) modificationCount(volumeID string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.modifications[volumeID]
 }
 
 func (d *fakeCloud) CreateDisk(ctx context.Context, volumeID string, diskOptions *cloud.DiskOptions) (*cloud.Disk, error) {
@@ -191,10 +230,23 @@ func (d *fakeCloud) DetachDisk(ctx context.Context, volumeID string, instanceID
 }
 
 func (d *fakeCloud) ResizeOrModifyDisk(ctx context.Context, volumeID string, newSizeBytes int64, modifyOptions *cloud.ModifyDiskOptions) (int32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.popFault("ResizeOrModifyDisk"); err != nil {
+		return 0, err
+	}
 	disk, exists := d.disks[volumeID]
 	if !exists {
 		return 0, cloud.ErrNotFound
 	}
+	if newSizeBytes == 0 && modifyOptions != nil {
+		// Like EC2, modifying a volume to the properties it already has is a no-op
+		if current, ok := d.modifyOptions[volumeID]; !ok || current != *modifyOptions {
+			d.modifyOptions[volumeID] = *modifyOptions
+			d.modifications[volumeID]++
+		}
+		return disk.CapacityGiB, nil
+	}
 	newSizeGiB := util.BytesToGiB(newSizeBytes)
 	disk.CapacityGiB = newSizeGiB
 	d.disks[volumeID] = disk
@@ -206,6 +258,30 @@ func (d *fakeCloud) AvailabilityZones(ctx context.Context) (map[string]struct{},
 	return map[string]struct{}{}, nil
 }
 
+func (d *fakeCloud) GetStorageUsage(ctx context.Context) (*cloud.StorageUsage, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	usage := &cloud.StorageUsage{SizeGiB: map[string]int64{}, Volumes: len(d.disks)}
+	for _, disk := range d.disks {
+		usage.SizeGiB[disk.VolumeType] += int64(disk.CapacityGiB)
+	}
+	return usage, nil
+}
+
+func (d *fakeCloud) GetZoneCapacity(ctx context.Context, volumeType string) (map[string]cloud.ZoneCapacity, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	capacities := map[string]cloud.ZoneCapacity{}
+	for _, disk := range d.disks {
+		if disk.VolumeType == volumeType {
+			capacity := capacities[disk.AvailabilityZone]
+			capacity.UsedGiB += int64(disk.CapacityGiB)
+			capacities[disk.AvailabilityZone] = capacity
+		}
+	}
+	return capacities, nil
+}
+
 func (d *fakeCloud) EnableFastSnapshotRestores(ctx context.Context, availabilityZones []string, snapshotID string) (*ec2.EnableFastSnapshotRestoresOutput, error) {
 	return &ec2.EnableFastSnapshotRestoresOutput{}, nil
 }
@@ -219,7 +295,9 @@ func (d *fakeCloud) GetDiskByName(ctx context.Context, name string, capacityByte
 }
 
 func (d *fakeCloud) ModifyTags(ctx context.Context, volumeID string, tagOptions cloud.ModifyTagsOptions) error {
-	return nil
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.popFault("ModifyTags")
 }
 
 func (d *fakeCloud) WaitForAttachmentState(ctx context.Context, expectedState types.VolumeAttachmentState, volumeID string, expectedInstance string, expectedDevice string, alreadyAssigned bool, expectedCardIndex *int32) (*types.VolumeAttachment, error) {
@@ -234,6 +312,96 @@ func (d *fakeCloud) DryRun(ctx context.Context) error {
 	return nil
 }
 
+func (d *fakeCloud) ResolveKMSKey(ctx context.Context, keyID string) (string, error) {
+	return keyID, nil
+}
+
+func (d *fakeCloud) SetClientTokenStore(ctx context.Context, store cloud.ClientTokenStore) error {
+	return nil
+}
+
 func (d *fakeCloud) GetVolumeIDByNodeAndDevice(ctx context.Context, nodeID, deviceName string) (string, error) {
 	return "", cloud.ErrNotFound
 }
+
+func (d *fakeCloud) ListPendingDeletionDisks(ctx context.Context, tags map[string]string) (map[string]time.Time, error) {
+	return map[string]time.Time{}, nil
+}
+
+func (d *fakeCloud) ListSnapshotsByTags(ctx context.Context, tags map[string]string) ([]*cloud.Snapshot, error) {
+	return []*cloud.Snapshot{}, nil
+}
+
+func (d *fakeCloud) CheckMultiAttachSupport(ctx context.Context, nodeID string) error {
+	return nil
+}
+
+func (d *fakeCloud) CheckBlockExpressSupport(ctx context.Context, nodeID string) error {
+	return nil
+}
+
+func (d *fakeCloud) CheckAttachmentZone(ctx context.Context, volumeID, nodeID string) error {
+	return nil
+}
+
+func (d *fakeCloud) ListDisks(ctx context.Context, volumeIDs []string, tags map[string]string) ([]*cloud.Disk, error) {
+	var disks []*cloud.Disk
+	for _, volumeID := range volumeIDs {
+		if disk, exists := d.disks[volumeID]; exists {
+			disks = append(disks, disk)
+		}
+	}
+	return disks, nil
+}
+
+func (d *fakeCloud) ListDisksPage(ctx context.Context, tags map[string]string, maxResults int32, nextToken string) (*cloud.ListDisksResponse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	start := 0
+	if nextToken != "" {
+		var err error
+		if start, err = strconv.Atoi(nextToken); err != nil || start < 0 {
+			return nil, cloud.ErrInvalidNextToken
+		}
+	}
+	volumeIDs := slices.Sorted(maps.Keys(d.disks))
+	start = min(start, len(volumeIDs))
+	end := len(volumeIDs)
+	if maxResults > 0 {
+		end = min(start+int(maxResults), end)
+	}
+	response := &cloud.ListDisksResponse{}
+	for _, volumeID := range volumeIDs[start:end] {
+		response.Disks = append(response.Disks, d.disks[volumeID])
+	}
+	if end < len(volumeIDs) {
+		response.NextToken = strconv.Itoa(end)
+	}
+	return response, nil
+}
+
+func (d *fakeCloud) BatchQueueLen() int {
+	return 0
+}
+
+func (d *fakeCloud) Throttles() int64 {
+	return 0
+}
+
+func (d *fakeCloud) GetEBSBandwidth(ctx context.Context, nodeID string) (*cloud.EBSBandwidth, error) {
+	return &cloud.EBSBandwidth{}, nil
+}
+
+func (d *fakeCloud) GetVolumeHealth(ctx context.Context, volumeIDs []string) (map[string]*cloud.VolumeHealth, error) {
+	health := map[string]*cloud.VolumeHealth{}
+	for _, volumeID := range volumeIDs {
+		if _, exists := d.disks[volumeID]; exists {
+			health[volumeID] = &cloud.VolumeHealth{Status: types.VolumeStatusInfoStatusOk, IOEnabled: true}
+		}
+	}
+	return health, nil
+}
+
+func (d *fakeCloud) EnableVolumeIO(ctx context.Context, volumeID string) error {
+	return nil
+}
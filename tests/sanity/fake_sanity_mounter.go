@@ -40,6 +40,10 @@ func (m *fakeMounter) FindDevicePath(devicePath, volumeID, partition, region str
 	return devicePath, nil
 }
 
+func (m *fakeMounter) BlockDevicePath(devicePath string) (string, error) {
+	return devicePath, nil
+}
+
 func (m *fakeMounter) PreparePublishTarget(target string) error {
 	if err := m.MakeDir(target); err != nil {
 		return fmt.Errorf("could not create dir %q: %w", target, err)
@@ -150,3 +154,23 @@ func (m *fakeMounter) Unmount(target string) error {
 func (m *fakeMounter) GetVolumeStats(volumePath string) (mounter.VolumeStats, error) {
 	return mounter.VolumeStats{}, nil
 }
+
+func (m *fakeMounter) SELinuxEnabled() bool {
+	return false
+}
+
+func (m *fakeMounter) SetupFscrypt(dir string, key []byte) error {
+	return nil
+}
+
+func (m *fakeMounter) RemoveFscryptKey(dir string) error {
+	return nil
+}
+
+func (m *fakeMounter) MountHolders(path string) ([]mounter.MountHolder, error) {
+	return nil, nil
+}
+
+func (m *fakeMounter) AttachedVolumes() (map[string]string, error) {
+	return map[string]string{}, nil
+}
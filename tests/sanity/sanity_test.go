@@ -17,18 +17,26 @@
 package sanity
 
 import (
+	"errors"
 	"fmt"
 	"path"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
-	"github.com/golang/mock/gomock"
+	"github.com/container-storage-interface/spec/lib/go/csi"
 	csisanity "github.com/kubernetes-csi/csi-test/v5/pkg/sanity"
+	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/cloud/metadata"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/driver"
 	"github.com/kubernetes-sigs/aws-ebs-csi-driver/pkg/util"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 func TestSanity(t *testing.T) {
@@ -38,21 +46,148 @@ func TestSanity(t *testing.T) {
 		}
 	}()
 
-	mockCtrl := gomock.NewController(t)
-	defer mockCtrl.Finish()
-
 	tmpDir := t.TempDir()
-
-	endpoint := fmt.Sprintf("unix:%s/csi.sock", tmpDir)
+	endpoint, _ := startFakeDriver(t, tmpDir, 60)
 	mountPath := path.Join(tmpDir, "mount")
 	stagePath := path.Join(tmpDir, "stage")
+
+	config := csisanity.TestConfig{
+		TargetPath:                  mountPath,
+		StagingPath:                 stagePath,
+		Address:                     endpoint,
+		DialOptions:                 []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+		IDGen:                       csisanity.DefaultIDGenerator{},
+		TestVolumeSize:              10 * util.GiB,
+		TestVolumeAccessType:        "mount",
+		TestVolumeMutableParameters: map[string]string{"iops": "3014", "throughput": "153"},
+		TestVolumeParameters:        map[string]string{"type": "gp3", "iops": "3000"},
+	}
+	csisanity.Test(t, config)
+}
+
+// TestModifyVolumeIdempotency runs scripted sequences of ControllerModifyVolume calls against the driver, with faults
+// injected into the fake cloud, and checks the codes they return and the modifications they make.
+func TestModifyVolumeIdempotency(t *testing.T) {
+	// Long enough for the concurrent calls of a step to be coalesced
+	endpoint, fc := startFakeDriver(t, t.TempDir(), 300*time.Millisecond)
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithDefaultCallOptions(grpc.WaitForReady(true)))
+	if err != nil {
+		t.Fatalf("Failed to connect to driver: %v", err)
+	}
+	defer conn.Close()
+	client := csi.NewControllerClient(conn)
+
+	type step struct {
+		// faults are injected into ResizeOrModifyDisk before the calls of the step
+		faults []error
+		// params holds the mutable parameters of each concurrent call
+		params []map[string]string
+		// expCodes holds the codes the calls must return, in any order
+		expCodes []codes.Code
+	}
+	iops := func(value string) map[string]string { return map[string]string{"iops": value} }
+	testCases := []struct {
+		name             string
+		steps            []step
+		expModifications int
+	}{
+		{
+			name: "repeated identical calls",
+			steps: []step{
+				{params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.OK}},
+				{params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.OK}},
+				{params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.OK}},
+			},
+			expModifications: 1,
+		},
+		{
+			name: "concurrent identical calls",
+			steps: []step{
+				{params: []map[string]string{iops("4000"), iops("4000"), iops("4000")}, expCodes: []codes.Code{codes.OK, codes.OK, codes.OK}},
+			},
+			expModifications: 1,
+		},
+		{
+			name: "concurrent conflicting calls",
+			steps: []step{
+				{params: []map[string]string{iops("4000"), iops("5000")}, expCodes: []codes.Code{codes.OK, codes.Aborted}},
+			},
+			expModifications: 1,
+		},
+		{
+			name: "conflicting calls one after the other",
+			steps: []step{
+				{params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.OK}},
+				{params: []map[string]string{iops("5000")}, expCodes: []codes.Code{codes.OK}},
+			},
+			expModifications: 2,
+		},
+		{
+			name: "retries after faults",
+			steps: []step{
+				{faults: []error{cloud.ErrLimitExceeded}, params: []map[string]string{iops("4000"), iops("4000")}, expCodes: []codes.Code{codes.ResourceExhausted, codes.ResourceExhausted}},
+				{faults: []error{errors.New("request timed out")}, params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.Internal}},
+				{faults: []error{cloud.ErrInvalidArgument}, params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.InvalidArgument}},
+				{params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.OK}},
+				{params: []map[string]string{iops("4000")}, expCodes: []codes.Code{codes.OK}},
+			},
+			expModifications: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			volume, err := client.CreateVolume(t.Context(), &csi.CreateVolumeRequest{
+				Name:               "pvc-" + strings.ReplaceAll(tc.name, " ", "-"),
+				CapacityRange:      &csi.CapacityRange{RequiredBytes: 10 * util.GiB},
+				VolumeCapabilities: []*csi.VolumeCapability{mountCapability()},
+				Parameters:         map[string]string{"type": "gp3"},
+			})
+			if err != nil {
+				t.Fatalf("Failed to create volume: %v", err)
+			}
+			volumeID := volume.GetVolume().GetVolumeId()
+
+			for i, step := range tc.steps {
+				fc.injectFault("ResizeOrModifyDisk", step.faults...)
+				results := make([]codes.Code, len(step.params))
+				var wg sync.WaitGroup
+				for j, params := range step.params {
+					wg.Go(func() {
+						_, err := client.ControllerModifyVolume(t.Context(), &csi.ControllerModifyVolumeRequest{
+							VolumeId:          volumeID,
+							MutableParameters: params,
+						})
+						results[j] = status.Code(err)
+					})
+				}
+				wg.Wait()
+				slices.Sort(results)
+				expCodes := slices.Sorted(slices.Values(step.expCodes))
+				if !slices.Equal(results, expCodes) {
+					t.Errorf("step %d: expected codes %v, got %v", i, expCodes, results)
+				}
+			}
+			if got := fc.modificationCount(volumeID); got != tc.expModifications {
+				t.Errorf("expected %d modifications of the volume, got %d", tc.expModifications, got)
+			}
+		})
+	}
+}
+
+// startFakeDriver runs the driver in all mode against the fake cloud and mounter, listening on a socket in dir, and
+// returns its endpoint and cloud.
+func startFakeDriver(t *testing.T, dir string, modifyVolumeTimeout time.Duration) (string, *fakeCloud) {
+	t.Helper()
+	endpoint := fmt.Sprintf("unix:%s/csi.sock", dir)
+	mountPath := path.Join(dir, "mount")
 	instanceID := "i-1234567890abcdef0"
 	region := "us-west-2"
 	availabilityZone := "us-west-2a"
 
 	driverOptions := &driver.Options{
 		Mode:                              driver.AllMode,
-		ModifyVolumeRequestHandlerTimeout: 60,
+		ModifyVolumeRequestHandlerTimeout: modifyVolumeTimeout,
 		Endpoint:                          endpoint,
 	}
 
@@ -69,7 +204,8 @@ func TestSanity(t *testing.T) {
 		Resource:  "op-1234567890abcdef0",
 	}
 
-	drv, err := driver.NewDriver(newFakeCloud(fakeMetadata, mountPath), driverOptions, newFakeMounter(), newFakeMetadataService(instanceID, region, availabilityZone, *outpostArn), nil)
+	fc := newFakeCloud(fakeMetadata, mountPath)
+	drv, err := driver.NewDriver(fc, driverOptions, newFakeMounter(), newFakeMetadataService(instanceID, region, availabilityZone, *outpostArn), nil)
 	if err != nil {
 		t.Fatalf("Failed to create fake driver: %v", err.Error())
 	}
@@ -78,17 +214,12 @@ func TestSanity(t *testing.T) {
 			panic(fmt.Sprintf("%v", err))
 		}
 	}()
+	return endpoint, fc
+}
 
-	config := csisanity.TestConfig{
-		TargetPath:                  mountPath,
-		StagingPath:                 stagePath,
-		Address:                     endpoint,
-		DialOptions:                 []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
-		IDGen:                       csisanity.DefaultIDGenerator{},
-		TestVolumeSize:              10 * util.GiB,
-		TestVolumeAccessType:        "mount",
-		TestVolumeMutableParameters: map[string]string{"iops": "3014", "throughput": "153"},
-		TestVolumeParameters:        map[string]string{"type": "gp3", "iops": "3000"},
+func mountCapability() *csi.VolumeCapability {
+	return &csi.VolumeCapability{
+		AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{}},
+		AccessMode: &csi.VolumeCapability_AccessMode{Mode: csi.VolumeCapability_AccessMode_SINGLE_NODE_WRITER},
 	}
-	csisanity.Test(t, config)
 }